@@ -0,0 +1,10 @@
+// Package api contiene la especificación OpenAPI 3.1 de la API HTTP.
+package api
+
+import _ "embed"
+
+// OpenAPI es el documento OpenAPI servido en /api/v1/openapi.json.
+// tests/openapi_test.go falla si sus rutas divergen de las registradas en internal/router.
+//
+//go:embed openapi.json
+var OpenAPI []byte
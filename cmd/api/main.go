@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/big"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,20 +19,74 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/gin-gonic/gin"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
 	appConfig "github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/eventos"
+	"github.com/edinfamous/blockchain-medisupply/internal/grpcserver"
 	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
 	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/internal/salud"
 	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+	"github.com/edinfamous/blockchain-medisupply/pkg/encryption"
+	"github.com/edinfamous/blockchain-medisupply/pkg/validation"
 )
 
 func main() {
-	// Cargar configuración
-	cfg, err := appConfig.LoadConfig()
+	archivoConfig := flag.String("config", "", "archivo de configuración YAML o TOML (por defecto CONFIG_FILE)")
+	imprimirConfig := flag.Bool("print-config", false, "imprime la configuración efectiva, con los secretos redactados, y termina")
+	flag.Parse()
+
+	// Cargar configuración: valores por defecto, archivo y variables de entorno
+	cfg, err := appConfig.Cargar(*archivoConfig)
+	if *imprimirConfig {
+		imprimirConfiguracion(cfg, err)
+		return
+	}
+	if err != nil {
+		fallar("error cargando configuración", err)
+	}
+	appConfig.AppConfig = cfg
+
+	// Enmascarar en logs y errores los secretos de la configuración y los datos personales de los eventos
+	if err := redaccion.Configurar(redaccion.Config{
+		Secretos:  redaccion.SecretosDe(cfg),
+		RutasJSON: cfg.LogRedactPaths,
+	}); err != nil {
+		fallar("error configurando la redacción de logs", err)
+	}
+
+	// Logs estructurados con el nivel y el formato de la configuración
+	if err := logging.Configurar(os.Stdout, cfg.LogLevel, cfg.LogFormat); err != nil {
+		fallar("error configurando logs", err)
+	}
+	slog.Info("configuración cargada", "nivel_log", cfg.LogLevel, "formato_log", cfg.LogFormat)
+
+	// Trazas distribuidas: la propagación W3C siempre está activa, la exportación depende del exportador
+	terminarTrazas, err := trazas.Configurar(context.Background(), trazas.Config{
+		Exportador:     cfg.TracingExporter,
+		EndpointOTLP:   cfg.TracingOTLPEndpoint,
+		NombreServicio: cfg.TracingServiceName,
+		Muestreo:       cfg.TracingSampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("Error cargando configuración: %v", err)
+		fallar("error configurando trazas", err)
 	}
+	slog.Info("trazas configuradas", "exportador", cfg.TracingExporter, "muestreo", cfg.TracingSampleRatio)
 
-	log.Println("✅ Configuración cargada correctamente")
+	// Esquemas JSON de DatosEvento por tipo de evento
+	if cfg.EventSchemasDir != "" {
+		if err := validation.CargarEsquemas(cfg.EventSchemasDir); err != nil {
+			fallar("error cargando esquemas de eventos", err)
+		}
+		slog.Info("esquemas de eventos cargados", "directorio", cfg.EventSchemasDir)
+	}
 
 	// Configurar Gin
 	if cfg.GinMode == "release" {
@@ -37,24 +94,30 @@ func main() {
 	}
 
 	// Inicializar servicios
-	log.Println("🔧 Inicializando servicios...")
+	slog.Info("inicializando servicios")
 
 	// 1. Inicializar IPFS Service
 	ipfsService := services.NewIPFSService(cfg.IPFSHost, cfg.IPFSPort)
+	ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+		Nodos:               cfg.IPFSNodes,
+		PinningServiceURL:   cfg.IPFSPinningServiceURL,
+		PinningServiceToken: cfg.IPFSPinningServiceToken,
+		FactorReplicacion:   cfg.IPFSReplicationFactor,
+		MinimoPinesRegistro: cfg.IPFSMinPins,
+	})
 	if err := ipfsService.VerificarConexion(context.Background()); err != nil {
-		log.Printf("⚠️  ADVERTENCIA: No se pudo conectar a IPFS: %v", err)
-		log.Println("   Asegúrese de que el nodo IPFS esté corriendo")
+		slog.Warn("no se pudo conectar a IPFS; asegúrese de que el nodo esté corriendo", "nodo", cfg.IPFSHost+":"+cfg.IPFSPort, "error", err)
 	} else {
-		log.Println("✅ Conectado a IPFS")
+		slog.Info("conectado a IPFS", "nodo", cfg.IPFSHost+":"+cfg.IPFSPort)
 	}
 
 	// 2. Inicializar DynamoDB Service
 	dynamoDBClient, err := initializeDynamoDB(cfg)
 	if err != nil {
-		log.Fatalf("Error inicializando DynamoDB: %v", err)
+		fallar("error inicializando DynamoDB", err)
 	}
-	dynamoDBService := services.NewDynamoDBService(dynamoDBClient, cfg.DynamoDBTableName)
-	log.Println("✅ Conectado a DynamoDB")
+	dynamoDBService := services.NewDynamoDBService(dynamoDBClient, cfg.DynamoDBTableName, cfg.DynamoDBControlTableName)
+	slog.Info("cliente de DynamoDB configurado", "tabla", cfg.DynamoDBTableName, "tabla_control", cfg.DynamoDBControlTableName)
 
 	// 3. Inicializar Blockchain Service
 	var blockchainService *services.BlockchainService
@@ -64,31 +127,30 @@ func main() {
 	if cfg.BlockchainRPCURL != "" {
 		// Usar URL personalizada si está configurada
 		rpcURL = cfg.BlockchainRPCURL
-		log.Printf("🔗 Usando RPC URL personalizada")
+		slog.Info("usando RPC URL personalizada")
 	} else if cfg.AlchemyAPIKey != "" {
 		// Construir URL de Alchemy basada en la red
 		rpcURL = fmt.Sprintf("https://eth-%s.g.alchemy.com/v2/%s", cfg.BlockchainNetwork, cfg.AlchemyAPIKey)
-		log.Printf("🔗 Usando Alchemy RPC para red %s", cfg.BlockchainNetwork)
+		slog.Info("usando Alchemy RPC", "red", cfg.BlockchainNetwork)
 	}
 
 	if rpcURL != "" {
 		// En producción, obtener la clave privada de AWS Secrets Manager
 		// Por ahora, usar una clave de prueba o desde variable de entorno
-		privateKey := os.Getenv("BLOCKCHAIN_PRIVATE_KEY")
+		privateKey := cfg.BlockchainPrivateKey
 		if privateKey == "" {
-			log.Println("ADVERTENCIA: BLOCKCHAIN_PRIVATE_KEY no configurada")
-			log.Println("   El servicio funcionará pero no podrá escribir en blockchain")
+			slog.Warn("BLOCKCHAIN_PRIVATE_KEY no configurada; el servicio funcionará pero no podrá escribir en blockchain")
 		} else {
 			// Obtener dirección del contrato (puede estar vacía para modo sin contrato)
 			contractAddress := cfg.ContractAddress
 			blockchainService, err = services.NewBlockchainService(rpcURL, privateKey, contractAddress)
 			if err != nil {
-				log.Printf("ADVERTENCIA: Error inicializando blockchain: %v", err)
+				slog.Warn("error inicializando blockchain", "error", err)
 			} else {
 				if contractAddress != "" {
-					log.Printf("✅ Conectado a Blockchain con Smart Contract: %s", contractAddress)
+					slog.Info("conectado a blockchain con smart contract", "contrato", contractAddress)
 				} else {
-					log.Println("✅ Conectado a Blockchain (modo sin contrato)")
+					slog.Info("conectado a blockchain (modo sin contrato)")
 				}
 			}
 		}
@@ -96,136 +158,362 @@ func main() {
 
 	// Si blockchain no está disponible, crear un servicio mock para no romper la aplicación
 	if blockchainService == nil {
-		log.Println("Usando modo sin blockchain (solo almacenamiento off-chain)")
+		slog.Warn("usando modo sin blockchain (solo almacenamiento off-chain)")
 	}
 
 	// 4. Inicializar servicios de negocio
 	transaccionService := services.NewTransaccionService(blockchainService, ipfsService, dynamoDBService)
+	transaccionService.ConfigurarAdjuntos(services.AdjuntosConfig{
+		TamanoMaximo:   cfg.AttachmentsMaxBytes,
+		MaximoArchivos: cfg.AttachmentsMaxFiles,
+		TiposMIME:      cfg.AttachmentsMimeTypes,
+		TiposEvento:    cfg.AttachmentsEventTypes,
+	})
+	transaccionService.ConfigurarLote(services.LoteConfig{
+		MaximoElementos: cfg.BatchMaxItems,
+		Concurrencia:    cfg.BatchConcurrency,
+	})
 	oracleService := services.NewOracleService(transaccionService, dynamoDBService)
 
+	// Bus de notificaciones de registro y anclaje para los streams SSE y WebSocket
+	busEventos := eventos.NewBus(eventos.Config{
+		Historial:          cfg.StreamHistorySize,
+		BufferSuscriptor:   cfg.StreamSubscriberBuffer,
+		MaximoSuscriptores: cfg.StreamMaxSubscribers,
+	})
+	transaccionService.ConfigurarEventos(busEventos)
+	oracleService.ConfigurarEventos(busEventos, dynamoDBService)
+
+	// Política de autorización por ruta, tipo de evento y propiedad de producto
+	motorPolitica, err := policy.NewMotor(cfg.PolicyFile)
+	if err != nil {
+		fallar("error cargando política de autorización", err)
+	}
+	transaccionService.ConfigurarPolitica(motorPolitica)
+
+	// Ciclo de reparación de CIDs subreplicados
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	replicacionService := services.NewReplicacionService(ipfsService, dynamoDBService, time.Duration(cfg.IPFSRepairInterval)*time.Second)
+	go replicacionService.Iniciar(backgroundCtx)
+	go motorPolitica.Vigilar(backgroundCtx, time.Duration(cfg.PolicyReloadInterval)*time.Second)
+
+	// Webhooks: cola durable de entregas firmadas; los secretos se guardan cifrados con ENCRYPTION_KEY
+	cifrado, err := encryption.NewAESEncryption(cfg.EncryptionKey)
+	if err != nil {
+		fallar("error configurando cifrado", err)
+	}
+	webhookService := services.NewWebhookService(dynamoDBService, cifrado, services.WebhookConfig{
+		MaximoIntentos:   cfg.WebhookMaxAttempts,
+		EsperaBase:       time.Duration(cfg.WebhookRetryBase) * time.Second,
+		EsperaMaxima:     time.Duration(cfg.WebhookRetryMax) * time.Second,
+		Timeout:          time.Duration(cfg.WebhookTimeout) * time.Second,
+		Concurrencia:     cfg.WebhookConcurrency,
+		Retencion:        time.Duration(cfg.WebhookRetentionDays) * 24 * time.Hour,
+		PermitirInseguro: cfg.WebhookAllowInsecure,
+	})
+	webhookService.ConfigurarOracle(oracleService)
+	if cfg.WebhookAllowInsecure {
+		slog.Warn("WEBHOOK_ALLOW_INSECURE=true; los webhooks aceptan http y destinos privados")
+	}
+	go webhookService.Iniciar(backgroundCtx, busEventos)
+
+	// Monitor de fondos de la cuenta firmante: alerta con saldo o autonomía bajos y pausa los anclajes
+	// mientras la cuenta no pueda pagar el gas
+	var monitorFondos *services.MonitorFondos
+	if blockchainService != nil {
+		fondos := services.FondosConfig{
+			Intervalo:       time.Duration(cfg.FundsCheckInterval) * time.Second,
+			AutonomiaMinima: time.Duration(cfg.FundsWarnRunwayHours) * time.Hour,
+			WebhookURL:      cfg.FundsAlertWebhookURL,
+			WebhookSecreto:  cfg.FundsAlertWebhookSecret,
+			Timeout:         time.Duration(cfg.WebhookTimeout) * time.Second,
+		}
+		if cfg.FundsWarnBalance != "" {
+			// Validado en LoadConfig
+			fondos.SaldoAlerta, _ = utils.ParsearEther(cfg.FundsWarnBalance)
+		}
+		monitorFondos = services.NewMonitorFondos(blockchainService, fondos)
+		transaccionService.ConfigurarFondos(monitorFondos)
+		go monitorFondos.Iniciar(backgroundCtx)
+
+		// Eventos que quedaron pendientes antes de reiniciar: pausados por fondos o sin terminar de anclar
+		go func() {
+			if err := transaccionService.ReanclarPendientes(backgroundCtx); err != nil {
+				slog.Error("error reanclando eventos pendientes", "error", err)
+			}
+		}()
+	}
+
 	// 5. Inicializar handlers
 	transaccionHandler := handlers.NewTransaccionHandler(transaccionService)
 	oracleHandler := handlers.NewOracleHandler(oracleService)
-	healthHandler := handlers.NewHealthHandler(ipfsService, blockchainService)
+	preparacion := handlers.PreparacionConfig{
+		Config: salud.Config{
+			Timeout:  time.Duration(cfg.ReadinessCheckTimeout) * time.Second,
+			TTL:      time.Duration(cfg.ReadinessCacheTTL) * time.Second,
+			Criticas: cfg.ReadinessCritical,
+		},
+		MaximoAnclajesPendientes: int64(cfg.ReadinessMaxAnchorQueue),
+	}
+	if cfg.BlockchainChainID > 0 {
+		preparacion.ChainIDEsperado = big.NewInt(int64(cfg.BlockchainChainID))
+	}
+	// Validado en LoadConfig
+	preparacion.BalanceMinimo, _ = utils.ParsearEther(cfg.ReadinessMinBalance)
+	healthHandler := handlers.NewHealthHandler(ipfsService, dynamoDBService, blockchainService, transaccionService, preparacion)
 	ipfsHandler := handlers.NewIPFSHandler(ipfsService)
 
+	// 6. Autenticación: API keys en la tabla de control y JWT (HMAC o JWKS)
+	apiKeyService := services.NewAPIKeyService(dynamoDBService)
+	authConfig := middleware.AuthConfig{
+		APIKeys:       apiKeyService,
+		ClaveArranque: cfg.AuthBootstrapAPIKey,
+	}
+	if cfg.AuthJWTSecret != "" || cfg.AuthJWKSFile != "" {
+		authConfig.JWT, err = auth.NewValidadorJWT(auth.ConfigJWT{
+			SecretoHMAC: cfg.AuthJWTSecret,
+			ArchivoJWKS: cfg.AuthJWKSFile,
+			Emisor:      cfg.AuthJWTIssuer,
+			Audiencia:   cfg.AuthJWTAudience,
+			ClaimActor:  cfg.AuthJWTActorClaim,
+			ClaimRoles:  cfg.AuthJWTRolesClaim,
+		})
+		if err != nil {
+			fallar("error configurando validación JWT", err)
+		}
+	}
+	// Identidades de certificados de cliente (mTLS), registradas en la tabla de control
+	certificadoService := services.NewCertificadoService(dynamoDBService)
+	if cfg.TLSClientCAFile != "" {
+		authConfig.Certificados = certificadoService
+	}
+	if !cfg.AuthEnabled {
+		slog.Warn("autenticación deshabilitada (AUTH_ENABLED=false); la API queda abierta")
+	}
+
+	// Idempotency-Key en el registro de transacciones, con las respuestas en la tabla de control
+	idempotenciaService := services.NewIdempotenciaService(dynamoDBService, services.IdempotenciaConfig{
+		TTL:    time.Duration(cfg.IdempotencyTTL) * time.Second,
+		Espera: time.Duration(cfg.IdempotencyWait) * time.Second,
+	})
+
+	// Un único cupo por llamador para la API REST y la gRPC; con RATE_LIMIT_STORE=dynamodb los
+	// contadores viven en la tabla de control y el cupo se comparte entre réplicas
+	cuotas, err := cfg.CuotasRateLimit()
+	if err != nil {
+		fallar("error configurando rate limiting", err)
+	}
+	var limitador ratelimit.Limitador = ratelimit.NewMemoria()
+	if cfg.RateLimitStore == appConfig.AlmacenRateLimitDynamoDB {
+		limitador = ratelimit.NewCompartido(dynamoDBService)
+	}
+	rateLimiter := middleware.NewRateLimiter(limitador, cuotas)
+
+	politicaCORS, err := middleware.NewCORS(router.ConfigCORS(cfg))
+	if err != nil {
+		fallar("error configurando CORS", err)
+	}
+
 	// Configurar router
-	router := setupRouter(cfg, transaccionHandler, oracleHandler, healthHandler, ipfsHandler)
+	engine := router.Configurar(cfg, router.Dependencias{
+		TransaccionHandler: transaccionHandler,
+		OracleHandler:      oracleHandler,
+		HealthHandler:      healthHandler,
+		IPFSHandler:        ipfsHandler,
+		APIKeyHandler:      handlers.NewAPIKeyHandler(apiKeyService),
+		CertificadoHandler: handlers.NewCertificadoHandler(certificadoService),
+		EventosHandler:     handlers.NewEventosHandler(busEventos, time.Duration(cfg.StreamHeartbeat)*time.Second),
+		WebhookHandler:     handlers.NewWebhookHandler(webhookService),
+		FondosHandler:      handlers.NewFondosHandler(monitorFondos),
+		AuthConfig:         authConfig,
+		Politica:           motorPolitica,
+		Idempotencia:       idempotenciaService,
+		RateLimiter:        rateLimiter,
+		CORS:               politicaCORS,
+	})
+
+	// Iniciar servidores con graceful shutdown: HTTP sin cifrar (desarrollo) y/o HTTPS con mTLS opcional
+	var servidores []*http.Server
 
-	// Iniciar servidor con graceful shutdown
-	srv := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
-		Handler: router,
+	if cfg.HTTPEnabled {
+		srv := &http.Server{
+			Addr:    ":" + cfg.ServerPort,
+			Handler: engine,
+		}
+		servidores = append(servidores, srv)
+		go func() {
+			slog.Info("servidor HTTP iniciado", "puerto", cfg.ServerPort)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fallar("error iniciando servidor", err)
+			}
+		}()
 	}
 
-	// Canal para señales del sistema
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	var recargadorTLS *auth.RecargadorTLS
+	if cfg.TLSCertFile != "" {
+		recargadorTLS, err = auth.NewRecargadorTLS(auth.ConfigTLS{
+			ArchivoCertificado: cfg.TLSCertFile,
+			ArchivoClave:       cfg.TLSKeyFile,
+			ArchivoCAClientes:  cfg.TLSClientCAFile,
+			ModoClientes:       cfg.TLSClientAuth,
+		})
+		if err != nil {
+			fallar("error configurando TLS", err)
+		}
+		srvTLS := &http.Server{
+			Addr:      ":" + cfg.TLSPort,
+			Handler:   engine,
+			TLSConfig: recargadorTLS.ConfigServidor(),
+		}
+		servidores = append(servidores, srvTLS)
+		go func() {
+			slog.Info("servidor HTTPS iniciado", "puerto", cfg.TLSPort, "certificados_cliente", cfg.TLSClientAuth)
+			if err := srvTLS.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				fallar("error iniciando servidor HTTPS", err)
+			}
+		}()
+	}
 
-	// Iniciar servidor en goroutine
+	// Servidor gRPC con los mismos servicios, autenticación, política y rate limiting
+	var servidorGRPC *grpcserver.Servidor
+	if cfg.GRPCEnabled {
+		depsGRPC := grpcserver.Dependencias{
+			Transacciones: transaccionService,
+			Oracle:        oracleService,
+			IPFS:          ipfsService,
+			AuthEnabled:   cfg.AuthEnabled,
+			AuthConfig:    authConfig,
+			Politica:      motorPolitica,
+			RateLimiter:   rateLimiter,
+			Preparacion:   healthHandler,
+			MaxMensaje:    cfg.BatchMaxItems * (64 << 10), // Mismo límite que el cuerpo de POST /lote
+		}
+		if recargadorTLS != nil {
+			depsGRPC.TLS = recargadorTLS.ConfigServidor()
+		}
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			fallar("error abriendo puerto gRPC", err)
+		}
+		servidorGRPC = grpcserver.NewServidor(depsGRPC)
+		go func() {
+			slog.Info("servidor gRPC iniciado", "puerto", cfg.GRPCPort, "tls", recargadorTLS != nil)
+			if err := servidorGRPC.Servir(lis); err != nil {
+				fallar("error iniciando servidor gRPC", err)
+			}
+		}()
+	}
+
+	// SIGHUP recarga la configuración (nivel de log, cuotas de rate limiting y política CORS), el
+	// certificado del servidor y las CAs de clientes sin cortar conexiones
+	recargar := make(chan os.Signal, 1)
+	signal.Notify(recargar, syscall.SIGHUP)
 	go func() {
-		log.Printf("🚀 Servidor iniciado en puerto %s", cfg.ServerPort)
-		log.Printf("📝 Documentación: http://localhost:%s/health", cfg.ServerPort)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Error iniciando servidor: %v", err)
+		cfgVigente := cfg
+		for range recargar {
+			cfgVigente = recargarConfiguracion(cfgVigente, *archivoConfig, rateLimiter, politicaCORS)
+			if recargadorTLS == nil {
+				continue
+			}
+			if err := recargadorTLS.Recargar(); err != nil {
+				slog.Error("TLS: se conservan los certificados vigentes", "error", err)
+				continue
+			}
+			slog.Info("TLS: certificados recargados")
 		}
 	}()
 
+	// Canal para señales del sistema
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
 	// Esperar señal de interrupción
 	<-quit
-	log.Println("🛑 Apagando servidor...")
+	slog.Info("apagando servidor")
 
 	// Graceful shutdown con timeout de 5 segundos
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Error en shutdown: %v", err)
+	// Cerrar los streams abiertos; Shutdown no interrumpe las conexiones SSE ni los WebSocket
+	busEventos.Cerrar()
+
+	for _, srv := range servidores {
+		if err := srv.Shutdown(ctx); err != nil {
+			fallar("error en shutdown", err)
+		}
+	}
+	if servidorGRPC != nil {
+		servidorGRPC.Detener(ctx)
 	}
 
+	// Detener tareas en segundo plano
+	stopBackground()
+
 	// Cerrar conexión blockchain si existe
 	if blockchainService != nil {
 		blockchainService.Close()
 	}
 
-	log.Println("✅ Servidor detenido correctamente")
-}
+	// Exportar los spans pendientes, incluidos los de los anclajes que terminaron durante el apagado
+	if err := terminarTrazas(ctx); err != nil {
+		slog.Warn("error exportando las trazas pendientes", "error", err)
+	}
 
-// setupRouter configura todas las rutas de la API
-func setupRouter(cfg *appConfig.Config, transaccionHandler *handlers.TransaccionHandler, oracleHandler *handlers.OracleHandler, healthHandler *handlers.HealthHandler, ipfsHandler *handlers.IPFSHandler) *gin.Engine {
-	router := gin.New()
-
-	// Middleware globales
-	router.Use(gin.Recovery())
-	router.Use(middleware.LoggerMiddleware())
-	router.Use(middleware.CORSMiddleware())
-	router.Use(middleware.RateLimitMiddleware(cfg.RateLimitRequests, cfg.RateLimitWindow))
-
-	// Health checks
-	router.GET("/health", healthHandler.HealthCheck)
-	router.GET("/ready", healthHandler.ReadinessCheck)
-
-	// API v1
-	v1 := router.Group("/api/v1")
-	{
-		// Rutas de transacciones
-		transacciones := v1.Group("/transaccion")
-		{
-			log.Println("📝 Registrando ruta: POST /api/v1/transaccion/registrar")
-			transacciones.POST("/registrar", transaccionHandler.RegistrarTransaccion)
-			transacciones.GET("/estado-blockchain/:id", transaccionHandler.ObtenerEstadoBlockchain)
-			transacciones.GET("/verificar/:id", transaccionHandler.VerificarTransaccion)
-			transacciones.GET("/:id", transaccionHandler.ObtenerTransaccion)
-			transacciones.GET("", transaccionHandler.ListarTransacciones)
-			transacciones.GET("/producto/:id", transaccionHandler.ObtenerTransaccionesPorProducto)
-		}
+	slog.Info("servidor detenido correctamente")
+}
 
-		// Rutas del Oracle (patrón Oracle)
-		oracle := v1.Group("/oracle")
-		{
-			oracle.GET("/datos/:id", oracleHandler.ObtenerDatosVerificados)
-			oracle.GET("/historial/:id", oracleHandler.ObtenerHistorialVerificado)
-			oracle.GET("/validar/:id", oracleHandler.ValidarCadenaSupply)
-		}
+// fallar registra un error de arranque o de apagado y termina el proceso
+func fallar(mensaje string, err error) {
+	slog.Error(mensaje, "error", err)
+	os.Exit(1)
+}
 
-		// Rutas de IPFS
-		ipfs := v1.Group("/ipfs")
-		{
-			ipfs.GET("/archivos", ipfsHandler.ListarArchivos)
-			ipfs.GET("/archivo/:cid", ipfsHandler.ObtenerArchivo)
-			ipfs.GET("/estadisticas", ipfsHandler.ObtenerEstadisticas)
+// imprimirConfiguracion escribe la configuración efectiva en stdout y los errores en stderr; termina con
+// código 1 si la configuración no es válida
+func imprimirConfiguracion(cfg *appConfig.Config, errCarga error) {
+	if cfg != nil {
+		if err := cfg.Imprimir(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
 	}
+	if errCarga != nil {
+		fmt.Fprintln(os.Stderr, errCarga)
+		os.Exit(1)
+	}
+}
 
-	// Ruta raíz con información de la API
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"service":     "Transacción Blockchain MediSupply",
-			"version":     "1.0.0",
-			"description": "Microservicio con patrones Oracle, Off-chain Storage e IPFS",
-			"endpoints": gin.H{
-				"health":        "/health",
-				"ready":         "/ready",
-				"api":           "/api/v1",
-				"transacciones": "/api/v1/transaccion",
-				"oracle":        "/api/v1/oracle",
-				"ipfs":          "/api/v1/ipfs",
-			},
-		})
-	})
-
-	// Handler para rutas no encontradas (útil para debugging)
-	router.NoRoute(func(c *gin.Context) {
-		log.Printf("⚠️  Ruta no encontrada: %s %s", c.Request.Method, c.Request.URL.Path)
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Ruta no encontrada",
-			"method":  c.Request.Method,
-			"path":    c.Request.URL.Path,
-			"message": "Verifica que la ruta y el método HTTP sean correctos",
-		})
-	})
+// recargarConfiguracion vuelve a cargar la configuración y aplica en caliente los valores recargables.
+// Si la nueva configuración no es válida se conserva la vigente; los cambios que requieren reiniciar el
+// proceso solo se advierten (y se vuelven a advertir en cada recarga hasta reiniciar). Retorna la
+// configuración que queda vigente.
+func recargarConfiguracion(vigente *appConfig.Config, archivo string, rateLimiter *middleware.RateLimiter, politicaCORS *middleware.CORS) *appConfig.Config {
+	nueva, err := appConfig.Cargar(archivo)
+	if err != nil {
+		slog.Error("configuración: se conserva la vigente", "error", err)
+		return vigente
+	}
+	cuotas, err := nueva.CuotasRateLimit()
+	if err != nil {
+		slog.Error("configuración: se conserva la vigente", "error", err)
+		return vigente
+	}
+	if err := politicaCORS.Actualizar(router.ConfigCORS(nueva)); err != nil {
+		slog.Error("configuración: se conserva la vigente", "error", err)
+		return vigente
+	}
+	// Validado en Cargar
+	_ = logging.FijarNivel(nueva.LogLevel)
+	rateLimiter.ActualizarCuotas(cuotas)
 
-	return router
+	recargables, requierenReinicio := vigente.Cambios(nueva)
+	if len(requierenReinicio) > 0 {
+		slog.Warn("configuración: estos cambios requieren reiniciar el proceso", "variables", requierenReinicio)
+	}
+	slog.Info("configuración recargada", "aplicados", recargables)
+	return vigente.ConRecargables(nueva)
 }
 
 // initializeDynamoDB inicializa el cliente de DynamoDB
@@ -256,5 +544,5 @@ func initializeDynamoDB(cfg *appConfig.Config) (*dynamodb.Client, error) {
 		return nil, fmt.Errorf("error cargando config de AWS: %w", err)
 	}
 
-	return dynamodb.NewFromConfig(awsCfg), nil
+	return dynamodb.NewFromConfig(awsCfg, services.InstrumentarDynamoDB), nil
 }
@@ -0,0 +1,22 @@
+// Package auth contiene la identidad autenticada de las peticiones, la validación de tokens JWT
+// y el material TLS del listener con certificados de cliente.
+package auth
+
+import (
+	"context"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+type clavePrincipal struct{}
+
+// ConPrincipal retorna un contexto que transporta el principal autenticado
+func ConPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, clavePrincipal{}, principal)
+}
+
+// PrincipalDesde obtiene el principal autenticado del contexto, o nil si la petición no fue autenticada
+func PrincipalDesde(ctx context.Context) *models.Principal {
+	principal, _ := ctx.Value(clavePrincipal{}).(*models.Principal)
+	return principal
+}
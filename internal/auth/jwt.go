@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// ErrTokenInvalido indica que el token no tiene firma válida, expiró o no cumple emisor/audiencia
+var ErrTokenInvalido = i18n.NuevoError("auth.token-invalido")
+
+// ConfigJWT define cómo se validan los tokens bearer
+type ConfigJWT struct {
+	SecretoHMAC string // Secreto compartido para HS256/HS384/HS512
+	ArchivoJWKS string // Archivo JWKS con las claves públicas RSA/EC
+	Emisor      string // Claim iss exigido (vacío = no se valida)
+	Audiencia   string // Claim aud exigido (vacío = no se valida)
+	ClaimActor  string // Claim con el actor de la cadena de suministro (por defecto "sub")
+	ClaimRoles  string // Claim con la lista de roles (por defecto "roles")
+}
+
+// ValidadorJWT valida tokens bearer contra un secreto HMAC o un conjunto de claves JWKS
+type ValidadorJWT struct {
+	secretoHMAC []byte
+	claves      map[string]crypto.PublicKey // Claves JWKS por kid
+	opciones    []jwt.ParserOption
+	claimActor  string
+	claimRoles  string
+}
+
+// NewValidadorJWT crea un validador; requiere un secreto HMAC, un archivo JWKS o ambos
+func NewValidadorJWT(cfg ConfigJWT) (*ValidadorJWT, error) {
+	if cfg.SecretoHMAC == "" && cfg.ArchivoJWKS == "" {
+		return nil, fmt.Errorf("se requiere un secreto HMAC o un archivo JWKS")
+	}
+
+	v := &ValidadorJWT{
+		claves:     make(map[string]crypto.PublicKey),
+		claimActor: cfg.ClaimActor,
+		claimRoles: cfg.ClaimRoles,
+	}
+	if v.claimActor == "" {
+		v.claimActor = "sub"
+	}
+	if v.claimRoles == "" {
+		v.claimRoles = "roles"
+	}
+
+	metodos := make([]string, 0)
+	if cfg.SecretoHMAC != "" {
+		v.secretoHMAC = []byte(cfg.SecretoHMAC)
+		metodos = append(metodos, "HS256", "HS384", "HS512")
+	}
+	if cfg.ArchivoJWKS != "" {
+		claves, err := cargarJWKS(cfg.ArchivoJWKS)
+		if err != nil {
+			return nil, err
+		}
+		v.claves = claves
+		metodos = append(metodos, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512")
+	}
+
+	v.opciones = []jwt.ParserOption{jwt.WithValidMethods(metodos), jwt.WithExpirationRequired()}
+	if cfg.Emisor != "" {
+		v.opciones = append(v.opciones, jwt.WithIssuer(cfg.Emisor))
+	}
+	if cfg.Audiencia != "" {
+		v.opciones = append(v.opciones, jwt.WithAudience(cfg.Audiencia))
+	}
+
+	return v, nil
+}
+
+// Validar verifica el token y construye el principal a partir de sus claims
+func (v *ValidadorJWT) Validar(token string) (*models.Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, v.claveDeVerificacion, v.opciones...); err != nil {
+		return nil, i18n.NuevoError("general.con-causa", ErrTokenInvalido, err)
+	}
+
+	sujeto, _ := claims.GetSubject()
+	actor, _ := claims[v.claimActor].(string)
+	if sujeto == "" || actor == "" {
+		return nil, i18n.NuevoError("auth.faltan-claims", ErrTokenInvalido, v.claimActor)
+	}
+
+	return &models.Principal{
+		ID:     sujeto,
+		Actor:  actor,
+		Roles:  rolesDesdeClaim(claims[v.claimRoles]),
+		Metodo: models.MetodoJWT,
+	}, nil
+}
+
+// claveDeVerificacion elige la clave según el algoritmo y el kid del token
+func (v *ValidadorJWT) claveDeVerificacion(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return v.secretoHMAC, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if clave, ok := v.claves[kid]; ok {
+		return clave, nil
+	}
+	// Sin kid solo se acepta si el JWKS tiene una única clave
+	if kid == "" && len(v.claves) == 1 {
+		for _, clave := range v.claves {
+			return clave, nil
+		}
+	}
+	return nil, fmt.Errorf("clave '%s' no encontrada en el JWKS", kid)
+}
+
+// rolesDesdeClaim acepta los roles como lista o como cadena separada por espacios
+func rolesDesdeClaim(valor interface{}) []string {
+	roles := make([]string, 0)
+	switch v := valor.(type) {
+	case []interface{}:
+		for _, rol := range v {
+			if s, ok := rol.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	case string:
+		roles = append(roles, strings.Fields(v)...)
+	}
+	return roles
+}
+
+// jwk es una clave pública en formato JSON Web Key (RFC 7517)
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// cargarJWKS lee un archivo JWKS y retorna sus claves RSA y EC indexadas por kid
+func cargarJWKS(archivo string) (map[string]crypto.PublicKey, error) {
+	contenido, err := os.ReadFile(archivo)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo JWKS %s: %w", archivo, err)
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(contenido, &jwks); err != nil {
+		return nil, fmt.Errorf("JWKS %s inválido: %w", archivo, err)
+	}
+
+	claves := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		clave, err := k.clavePublica()
+		if err != nil {
+			return nil, fmt.Errorf("clave '%s' del JWKS inválida: %w", k.Kid, err)
+		}
+		claves[k.Kid] = clave
+	}
+	if len(claves) == 0 {
+		return nil, fmt.Errorf("el JWKS %s no contiene claves", archivo)
+	}
+
+	return claves, nil
+}
+
+// clavePublica convierte el JWK en una clave pública de crypto
+func (k jwk) clavePublica() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := enteroBase64(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := enteroBase64(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curva elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curva = elliptic.P256()
+		case "P-384":
+			curva = elliptic.P384()
+		case "P-521":
+			curva = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curva '%s' no soportada", k.Crv)
+		}
+		x, err := enteroBase64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := enteroBase64(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curva, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("tipo de clave '%s' no soportado", k.Kty)
+	}
+}
+
+// enteroBase64 decodifica un entero big-endian codificado en base64url sin padding
+func enteroBase64(valor string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(valor)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
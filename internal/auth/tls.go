@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// Modos de verificación de certificados de cliente (TLS_CLIENT_AUTH)
+const (
+	ClientesNinguno    = "none"     // No se solicita certificado de cliente
+	ClientesOpcional   = "optional" // Se verifica contra la CA solo si el cliente lo presenta
+	ClientesRequeridos = "require"  // Todo cliente debe presentar un certificado válido
+)
+
+// ConfigTLS agrupa los archivos del listener HTTPS
+type ConfigTLS struct {
+	ArchivoCertificado string
+	ArchivoClave       string
+	ArchivoCAClientes  string // Bundle PEM de CAs de clientes; vacío = sin mTLS
+	ModoClientes       string
+}
+
+// materialTLS es el certificado y las CAs vigentes; se reemplaza completo en cada recarga
+type materialTLS struct {
+	certificado *tls.Certificate
+	casClientes *x509.CertPool
+}
+
+// RecargadorTLS sirve el certificado del servidor y las CAs de clientes vigentes.
+// Recargar solo afecta a los handshakes nuevos; las conexiones abiertas no se interrumpen.
+type RecargadorTLS struct {
+	cfg      ConfigTLS
+	material atomic.Pointer[materialTLS]
+}
+
+// NewRecargadorTLS carga el certificado del servidor y, si se configuró, el bundle de CAs de clientes
+func NewRecargadorTLS(cfg ConfigTLS) (*RecargadorTLS, error) {
+	if cfg.ModoClientes == "" {
+		cfg.ModoClientes = ClientesOpcional
+	}
+	if cfg.ModoClientes != ClientesNinguno && cfg.ModoClientes != ClientesOpcional && cfg.ModoClientes != ClientesRequeridos {
+		return nil, fmt.Errorf("modo de certificados de cliente desconocido: %s", cfg.ModoClientes)
+	}
+	if cfg.ModoClientes == ClientesRequeridos && cfg.ArchivoCAClientes == "" {
+		return nil, fmt.Errorf("el modo %s requiere un bundle de CAs de clientes", ClientesRequeridos)
+	}
+
+	r := &RecargadorTLS{cfg: cfg}
+	if err := r.Recargar(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Recargar vuelve a leer el certificado y las CAs. Si algún archivo es inválido se conserva el material vigente.
+func (r *RecargadorTLS) Recargar() error {
+	certificado, err := tls.LoadX509KeyPair(r.cfg.ArchivoCertificado, r.cfg.ArchivoClave)
+	if err != nil {
+		return fmt.Errorf("error cargando certificado del servidor: %w", err)
+	}
+
+	material := &materialTLS{certificado: &certificado}
+	if r.cfg.ArchivoCAClientes != "" {
+		pem, err := os.ReadFile(r.cfg.ArchivoCAClientes)
+		if err != nil {
+			return fmt.Errorf("error leyendo CAs de clientes: %w", err)
+		}
+		material.casClientes = x509.NewCertPool()
+		if !material.casClientes.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("el archivo %s no contiene certificados PEM", r.cfg.ArchivoCAClientes)
+		}
+	}
+
+	r.material.Store(material)
+	return nil
+}
+
+// ConfigServidor retorna la configuración TLS del listener. Cada handshake toma el material vigente.
+func (r *RecargadorTLS) ConfigServidor() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.material.Load().certificado, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			material := r.material.Load()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*material.certificado},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if material.casClientes != nil {
+				config.ClientCAs = material.casClientes
+				config.ClientAuth = modoClientes(r.cfg.ModoClientes)
+			}
+			return config, nil
+		},
+	}
+}
+
+// modoClientes traduce TLS_CLIENT_AUTH al tipo de verificación de crypto/tls
+func modoClientes(modo string) tls.ClientAuthType {
+	switch modo {
+	case ClientesRequeridos:
+		return tls.RequireAndVerifyClientCert
+	case ClientesOpcional:
+		return tls.VerifyClientCertIfGiven
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// CertificadoVerificado retorna el certificado de cliente verificado contra las CAs configuradas, o nil
+func CertificadoVerificado(estado *tls.ConnectionState) *x509.Certificate {
+	if estado == nil || len(estado.VerifiedChains) == 0 || len(estado.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return estado.VerifiedChains[0][0]
+}
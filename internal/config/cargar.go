@@ -0,0 +1,296 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+)
+
+// sufijoArchivo es el sufijo de las variables que leen un secreto de un archivo (Docker/Kubernetes secrets)
+const sufijoArchivo = "_FILE"
+
+// Cargar construye la configuración en capas: valores por defecto, el archivo de configuración (archivo o,
+// si está vacío, CONFIG_FILE; YAML o TOML según la extensión) y por último las variables de entorno, que
+// tienen prioridad. Los valores mal formados no se ignoran: el error reúne todos los problemas de formato y
+// de validación a la vez. Si el archivo se pudo leer, retorna la configuración aunque sea inválida, para
+// poder mostrarla con --print-config.
+func Cargar(archivo string) (*Config, error) {
+	// .env solo en desarrollo; no reemplaza variables ya definidas
+	_ = godotenv.Load()
+	if archivo == "" {
+		archivo = os.Getenv("CONFIG_FILE")
+	}
+
+	config := porDefecto()
+	var errs []error
+	if archivo != "" {
+		valores, err := leerArchivo(archivo)
+		if err != nil {
+			return nil, fmt.Errorf("archivo de configuración %s: %w", archivo, err)
+		}
+		errs = append(errs, config.aplicar(valores, true)...)
+	}
+	errs = append(errs, config.aplicar(variablesEntorno(), false)...)
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return config, fmt.Errorf("configuración inválida:\n%w", errors.Join(errs...))
+	}
+	return config, nil
+}
+
+// campoConfig es un campo de Config con su variable de entorno
+type campoConfig struct {
+	nombre     string
+	valor      reflect.Value
+	sensible   bool
+	recargable bool
+}
+
+// campos recorre los campos con etiqueta env en el orden en que se declaran
+func (c *Config) campos() []campoConfig {
+	valor := reflect.ValueOf(c).Elem()
+	var campos []campoConfig
+	for i := 0; i < valor.NumField(); i++ {
+		tipo := valor.Type().Field(i)
+		nombre := tipo.Tag.Get("env")
+		if nombre == "" {
+			continue
+		}
+		campos = append(campos, campoConfig{
+			nombre:     nombre,
+			valor:      valor.Field(i),
+			sensible:   tipo.Tag.Get("sensible") == "true",
+			recargable: tipo.Tag.Get("recargable") == "true",
+		})
+	}
+	return campos
+}
+
+// aplicar asigna los valores de una capa. En el archivo (estricto) una clave desconocida es un error; en
+// el entorno solo se consultan las variables conocidas.
+func (c *Config) aplicar(valores map[string]string, estricto bool) []error {
+	var errs []error
+	conocidas := make(map[string]bool)
+	for _, campo := range c.campos() {
+		conocidas[campo.nombre] = true
+		texto, hayValor := valores[campo.nombre]
+		ruta, hayArchivo := valores[campo.nombre+sufijoArchivo]
+		if hayArchivo {
+			conocidas[campo.nombre+sufijoArchivo] = true
+		}
+
+		switch {
+		case hayArchivo && !campo.sensible:
+			errs = append(errs, fmt.Errorf("%s%s: solo los secretos se pueden leer de un archivo", campo.nombre, sufijoArchivo))
+			continue
+		case hayArchivo && hayValor:
+			errs = append(errs, fmt.Errorf("%s y %s%s son excluyentes", campo.nombre, campo.nombre, sufijoArchivo))
+			continue
+		case hayArchivo:
+			contenido, err := os.ReadFile(ruta)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", campo.nombre, sufijoArchivo, err))
+				continue
+			}
+			texto = strings.TrimRight(string(contenido), "\r\n")
+		case !hayValor:
+			continue
+		}
+
+		if err := asignar(campo.valor, texto); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", campo.nombre, err))
+		}
+	}
+
+	if estricto {
+		var desconocidas []string
+		for clave := range valores {
+			if !conocidas[clave] {
+				desconocidas = append(desconocidas, strings.ToLower(clave))
+			}
+		}
+		sort.Strings(desconocidas)
+		for _, clave := range desconocidas {
+			errs = append(errs, fmt.Errorf("clave '%s' desconocida", clave))
+		}
+	}
+	return errs
+}
+
+// asignar interpreta el texto según el tipo del campo; las listas se separan por comas
+func asignar(destino reflect.Value, texto string) error {
+	texto = strings.TrimSpace(texto)
+	switch destino.Kind() {
+	case reflect.String:
+		destino.SetString(texto)
+	case reflect.Int, reflect.Int64:
+		numero, err := strconv.ParseInt(texto, 10, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' no es un número entero", texto)
+		}
+		destino.SetInt(numero)
+	case reflect.Float64:
+		numero, err := strconv.ParseFloat(texto, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' no es un número", texto)
+		}
+		destino.SetFloat(numero)
+	case reflect.Bool:
+		booleano, err := strconv.ParseBool(texto)
+		if err != nil {
+			return fmt.Errorf("'%s' no es true ni false", texto)
+		}
+		destino.SetBool(booleano)
+	case reflect.Slice:
+		lista := []string{}
+		for _, elemento := range strings.Split(texto, ",") {
+			if elemento = strings.TrimSpace(elemento); elemento != "" {
+				lista = append(lista, elemento)
+			}
+		}
+		destino.Set(reflect.ValueOf(lista))
+	default:
+		return fmt.Errorf("tipo %s no soportado", destino.Type())
+	}
+	return nil
+}
+
+// variablesEntorno retorna las variables de entorno definidas con nombre de campo (o nombre_FILE). Una
+// variable definida aunque vacía reemplaza el valor del archivo; una ruta nombre_FILE vacía se ignora, y
+// con nombre_FILE definida también la variable vacía (las plantillas .env dejan los secretos en blanco).
+func variablesEntorno() map[string]string {
+	valores := make(map[string]string)
+	for _, campo := range porDefecto().campos() {
+		archivo := os.Getenv(campo.nombre + sufijoArchivo)
+		if archivo != "" {
+			valores[campo.nombre+sufijoArchivo] = archivo
+		}
+		if valor, ok := os.LookupEnv(campo.nombre); ok && (valor != "" || archivo == "") {
+			valores[campo.nombre] = valor
+		}
+	}
+	return valores
+}
+
+// leerArchivo interpreta un archivo YAML o TOML. Las secciones se aplanan uniendo las claves con "_", así
+// que "rate_limit: {requests: 100}" equivale a "rate_limit_requests: 100" y a RATE_LIMIT_REQUESTS=100.
+func leerArchivo(archivo string) (map[string]string, error) {
+	contenido, err := os.ReadFile(archivo)
+	if err != nil {
+		return nil, err
+	}
+
+	arbol := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(archivo)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contenido, &arbol)
+	case ".toml":
+		err = toml.Unmarshal(contenido, &arbol)
+	default:
+		return nil, fmt.Errorf("extensión no soportada; use .yaml, .yml o .toml")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	valores := make(map[string]string)
+	if err := aplanar("", arbol, valores); err != nil {
+		return nil, err
+	}
+	return valores, nil
+}
+
+func aplanar(prefijo string, arbol map[string]any, valores map[string]string) error {
+	for clave, valor := range arbol {
+		nombre := strings.ToUpper(strings.ReplaceAll(prefijo+clave, "-", "_"))
+		switch v := valor.(type) {
+		case map[string]any:
+			if err := aplanar(nombre+"_", v, valores); err != nil {
+				return err
+			}
+		case []any:
+			elementos := make([]string, len(v))
+			for i, elemento := range v {
+				if _, compuesto := elemento.(map[string]any); compuesto {
+					return fmt.Errorf("clave '%s': las listas solo admiten valores simples", strings.ToLower(nombre))
+				}
+				elementos[i] = fmt.Sprint(elemento)
+			}
+			valores[nombre] = strings.Join(elementos, ",")
+		case nil:
+			valores[nombre] = ""
+		default:
+			valores[nombre] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// Imprimir escribe la configuración efectiva como YAML (válido como archivo de configuración) con los
+// secretos reemplazados por redaccion.Marcador
+func (c *Config) Imprimir(salida io.Writer) error {
+	documento := &yaml.Node{Kind: yaml.MappingNode}
+	for _, campo := range c.campos() {
+		var valor any = campo.valor.Interface()
+		if campo.sensible && campo.valor.String() != "" {
+			valor = redaccion.Marcador
+		}
+		nodo := &yaml.Node{}
+		if err := nodo.Encode(valor); err != nil {
+			return fmt.Errorf("%s: %w", campo.nombre, err)
+		}
+		documento.Content = append(documento.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: strings.ToLower(campo.nombre)}, nodo)
+	}
+
+	codificador := yaml.NewEncoder(salida)
+	codificador.SetIndent(2)
+	if err := codificador.Encode(documento); err != nil {
+		return err
+	}
+	return codificador.Close()
+}
+
+// Cambios compara con una configuración recargada y retorna las variables que cambiaron, separadas entre
+// las que se aplican en caliente y las que requieren reiniciar el proceso
+func (c *Config) Cambios(nueva *Config) (recargables, requierenReinicio []string) {
+	nuevos := nueva.campos()
+	for i, campo := range c.campos() {
+		if reflect.DeepEqual(campo.valor.Interface(), nuevos[i].valor.Interface()) {
+			continue
+		}
+		if campo.recargable {
+			recargables = append(recargables, campo.nombre)
+		} else {
+			requierenReinicio = append(requierenReinicio, campo.nombre)
+		}
+	}
+	return recargables, requierenReinicio
+}
+
+// ConRecargables retorna una copia de la configuración con los valores recargables de nueva: es la
+// configuración que queda vigente tras una recarga en caliente
+func (c *Config) ConRecargables(nueva *Config) *Config {
+	vigente := *c
+	nuevos := nueva.campos()
+	for i, campo := range vigente.campos() {
+		if campo.recargable {
+			campo.valor.Set(nuevos[i].valor)
+		}
+	}
+	return &vigente
+}
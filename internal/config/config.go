@@ -1,135 +1,442 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/salud"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
 )
 
-// Config representa la configuración de la aplicación
+// Config representa la configuración de la aplicación. La etiqueta env nombra la variable de entorno y,
+// en minúsculas, la clave del archivo de configuración; sensible marca los secretos, que se pueden leer
+// de un archivo con <VARIABLE>_FILE y nunca se imprimen; recargable marca lo que SIGHUP aplica en caliente.
 type Config struct {
 	// AWS
-	AWSRegion         string
-	AWSAccessKeyID    string
-	AWSSecretKey      string
-	DynamoDBTableName string
-	UseAWSSecrets     bool
+	AWSRegion                string `env:"AWS_REGION"`
+	AWSAccessKeyID           string `env:"AWS_ACCESS_KEY_ID"`
+	AWSSecretKey             string `env:"AWS_SECRET_ACCESS_KEY" sensible:"true"`
+	DynamoDBTableName        string `env:"DYNAMODB_TABLE_NAME"`
+	DynamoDBControlTableName string `env:"DYNAMODB_CONTROL_TABLE_NAME"` // Tabla auxiliar (clave "pk") con las cabezas de cadena por producto
+	UseAWSSecrets            bool   `env:"USE_AWS_SECRETS"`
 
 	// Blockchain
-	AlchemyAPIKey            string
-	BlockchainRPCURL         string // Full RPC URL (optional, constructed if not provided)
-	BlockchainNetwork        string
-	BlockchainPrivateKeyName string
-	ContractAddress          string
+	AlchemyAPIKey            string `env:"ALCHEMY_API_KEY" sensible:"true"`
+	BlockchainRPCURL         string `env:"BLOCKCHAIN_RPC_URL" sensible:"true"` // Full RPC URL (optional, constructed if not provided)
+	BlockchainNetwork        string `env:"BLOCKCHAIN_NETWORK"`
+	BlockchainPrivateKeyName string `env:"BLOCKCHAIN_PRIVATE_KEY_SECRET"`
+	BlockchainPrivateKey     string `env:"BLOCKCHAIN_PRIVATE_KEY" sensible:"true"` // Clave privada del firmante (hex)
+	ContractAddress          string `env:"CONTRACT_ADDRESS"`
 
 	// IPFS
-	IPFSHost        string
-	IPFSPort        string
-	IPFSGatewayPort string
+	IPFSHost        string `env:"IPFS_HOST"`
+	IPFSPort        string `env:"IPFS_PORT"`
+	IPFSGatewayPort string `env:"IPFS_GATEWAY_PORT"`
+
+	// IPFS - Replicación
+	IPFSNodes               []string `env:"IPFS_NODES"` // Nodos Kubo adicionales (host:puerto)
+	IPFSPinningServiceURL   string   `env:"IPFS_PINNING_SERVICE_URL"`
+	IPFSPinningServiceToken string   `env:"IPFS_PINNING_SERVICE_TOKEN" sensible:"true"`
+	IPFSReplicationFactor   int      `env:"IPFS_REPLICATION_FACTOR"`
+	IPFSMinPins             int      `env:"IPFS_MIN_PINS"`        // Pines exigidos para aceptar un registro
+	IPFSRepairInterval      int      `env:"IPFS_REPAIR_INTERVAL"` // Segundos entre ciclos de reparación (0 = deshabilitado)
+
+	// Adjuntos
+	AttachmentsMaxBytes   int64    `env:"ATTACHMENTS_MAX_BYTES"`   // Tamaño máximo por archivo
+	AttachmentsMaxFiles   int      `env:"ATTACHMENTS_MAX_FILES"`   // Archivos máximos por evento
+	AttachmentsMimeTypes  []string `env:"ATTACHMENTS_MIME_TYPES"`  // Tipos MIME permitidos
+	AttachmentsEventTypes []string `env:"ATTACHMENTS_EVENT_TYPES"` // Tipos de evento que admiten adjuntos
+
+	// Registro por lotes
+	BatchMaxItems    int `env:"BATCH_MAX_ITEMS"`   // Eventos máximos por lote
+	BatchConcurrency int `env:"BATCH_CONCURRENCY"` // Subidas a IPFS y cadenas de producto en paralelo
+
+	// Esquemas de eventos
+	EventSchemasDir string `env:"EVENT_SCHEMAS_DIR"` // Directorio con <tipoEvento>.json que reemplaza los esquemas embebidos
 
 	// Server
-	ServerPort string
-	GinMode    string
+	ServerPort  string `env:"SERVER_PORT"`
+	HTTPEnabled bool   `env:"HTTP_ENABLED"` // Listener HTTP sin cifrar en ServerPort (desarrollo local)
+	GinMode     string `env:"GIN_MODE"`
+
+	// Logs
+	LogLevel       string   `env:"LOG_LEVEL" recargable:"true"` // debug, info, warn o error
+	LogFormat      string   `env:"LOG_FORMAT"`                  // json o text
+	LogRedactPaths []string `env:"LOG_REDACT_PATHS"`            // Rutas JSON con datos personales que se enmascaran en DatosEvento
+
+	// Métricas
+	MetricsEnabled bool `env:"METRICS_ENABLED"` // Expone /metrics en formato Prometheus
+
+	// Readiness (GET /ready y health service de gRPC)
+	ReadinessCheckTimeout   int      `env:"READINESS_CHECK_TIMEOUT"`    // Segundos máximos de cada verificación
+	ReadinessCacheTTL       int      `env:"READINESS_CACHE_TTL"`        // Segundos durante los que se reutiliza el último resultado
+	ReadinessCritical       []string `env:"READINESS_CRITICAL"`         // Verificaciones cuyo fallo responde 503; las demás solo degradan
+	ReadinessMinBalance     string   `env:"READINESS_MIN_BALANCE_ETH"`  // Saldo mínimo de la cuenta firmante en ETH ("0.05")
+	ReadinessMaxAnchorQueue int      `env:"READINESS_MAX_ANCHOR_QUEUE"` // Máximo de anclajes pendientes; 0 = sin límite
+	BlockchainChainID       int      `env:"BLOCKCHAIN_CHAIN_ID"`        // Chain ID esperado del RPC; 0 = el obtenido al conectar
+
+	// Monitor de fondos de la cuenta firmante
+	FundsCheckInterval      int    `env:"FUNDS_CHECK_INTERVAL"`                       // Segundos entre consultas del saldo
+	FundsWarnBalance        string `env:"FUNDS_WARN_BALANCE_ETH"`                     // Saldo en ETH por debajo del cual se alerta ("0.05"); vacío = sin umbral
+	FundsWarnRunwayHours    int    `env:"FUNDS_WARN_RUNWAY_HOURS"`                    // Horas de autonomía proyectada por debajo de las cuales se alerta; 0 = sin umbral
+	FundsAlertWebhookURL    string `env:"FUNDS_ALERT_WEBHOOK_URL"`                    // Destino de las alertas de fondos; vacío = solo log y métricas
+	FundsAlertWebhookSecret string `env:"FUNDS_ALERT_WEBHOOK_SECRET" sensible:"true"` // Firma HMAC de las alertas de fondos
+
+	// Trazas (OpenTelemetry)
+	TracingExporter     string  `env:"TRACING_EXPORTER"`            // none, stdout u otlp
+	TracingOTLPEndpoint string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT"` // URL del colector OTLP/HTTP (p. ej. http://otel-collector:4318)
+	TracingServiceName  string  `env:"OTEL_SERVICE_NAME"`           // service.name de los spans
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO"`        // Fracción de trazas nuevas que se registran (0 a 1)
+
+	// TLS / mTLS
+	TLSPort         string `env:"TLS_PORT"`
+	TLSCertFile     string `env:"TLS_CERT_FILE"` // Certificado del servidor (PEM); vacío = sin listener HTTPS
+	TLSKeyFile      string `env:"TLS_KEY_FILE"`
+	TLSClientCAFile string `env:"TLS_CLIENT_CA_FILE"` // Bundle de CAs que firman los certificados de cliente
+	TLSClientAuth   string `env:"TLS_CLIENT_AUTH"`    // none, optional o require
+
+	// gRPC
+	GRPCEnabled bool   `env:"GRPC_ENABLED"` // Servidor gRPC en GRPCPort; usa el mismo TLS/mTLS que HTTPS si está configurado
+	GRPCPort    string `env:"GRPC_PORT"`
+
+	// Notificaciones en tiempo real (SSE / WebSocket)
+	StreamHistorySize      int `env:"STREAM_HISTORY_SIZE"`      // Notificaciones conservadas para reanudar con Last-Event-ID
+	StreamSubscriberBuffer int `env:"STREAM_SUBSCRIBER_BUFFER"` // Notificaciones pendientes por suscriptor antes de desconectarlo
+	StreamMaxSubscribers   int `env:"STREAM_MAX_SUBSCRIBERS"`   // Suscripciones simultáneas (0 = sin límite)
+	StreamHeartbeat        int `env:"STREAM_HEARTBEAT"`         // Segundos entre latidos de keep-alive
+
+	// Webhooks
+	WebhookMaxAttempts   int  `env:"WEBHOOK_MAX_ATTEMPTS"`   // Intentos de entrega antes de pasar a muertas
+	WebhookRetryBase     int  `env:"WEBHOOK_RETRY_BASE"`     // Segundos de espera tras el primer fallo; se duplica en cada intento
+	WebhookRetryMax      int  `env:"WEBHOOK_RETRY_MAX"`      // Tope en segundos de la espera entre intentos
+	WebhookTimeout       int  `env:"WEBHOOK_TIMEOUT"`        // Segundos máximos de cada POST al receptor
+	WebhookConcurrency   int  `env:"WEBHOOK_CONCURRENCY"`    // Entregas enviadas en paralelo
+	WebhookRetentionDays int  `env:"WEBHOOK_RETENTION_DAYS"` // Días que se conserva el log de entregas
+	WebhookAllowInsecure bool `env:"WEBHOOK_ALLOW_INSECURE"` // Permite URLs http y destinos privados (solo desarrollo)
 
 	// Security
-	EncryptionKey string
+	EncryptionKey string `env:"ENCRYPTION_KEY" sensible:"true"`
+
+	// Autenticación
+	AuthEnabled         bool   `env:"AUTH_ENABLED"`
+	AuthBootstrapAPIKey string `env:"AUTH_BOOTSTRAP_API_KEY" sensible:"true"` // API key estática con rol admin para emitir las primeras claves
+	AuthJWTSecret       string `env:"AUTH_JWT_HMAC_SECRET" sensible:"true"`   // Secreto HMAC para tokens HS256/384/512
+	AuthJWKSFile        string `env:"AUTH_JWKS_FILE"`                         // Archivo JWKS con claves públicas RSA/EC
+	AuthJWTIssuer       string `env:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience     string `env:"AUTH_JWT_AUDIENCE"`
+	AuthJWTActorClaim   string `env:"AUTH_JWT_ACTOR_CLAIM"` // Claim del JWT con el actor de la cadena de suministro
+	AuthJWTRolesClaim   string `env:"AUTH_JWT_ROLES_CLAIM"`
+
+	// Autorización
+	PolicyFile           string `env:"POLICY_FILE"`            // Archivo YAML de política; vacío = política embebida
+	PolicyReloadInterval int    `env:"POLICY_RELOAD_INTERVAL"` // Segundos entre comprobaciones de cambios del archivo (0 = sin recarga)
+
+	// Idempotencia
+	IdempotencyTTL  int `env:"IDEMPOTENCY_TTL"`  // Segundos que se conserva la respuesta de una Idempotency-Key
+	IdempotencyWait int `env:"IDEMPOTENCY_WAIT"` // Segundos que un duplicado concurrente espera antes de recibir 409
 
 	// Rate Limiting
-	RateLimitRequests int
-	RateLimitWindow   int
+	RateLimitRequests int    `env:"RATE_LIMIT_REQUESTS" recargable:"true"` // Cuota por defecto por llamador y ventana
+	RateLimitWindow   int    `env:"RATE_LIMIT_WINDOW" recargable:"true"`   // Segundos de la ventana
+	RateLimitStore    string `env:"RATE_LIMIT_STORE"`                      // memory (por réplica) o dynamodb (compartido entre réplicas)
+	RateLimitRoles    string `env:"RATE_LIMIT_ROLES" recargable:"true"`    // Cuotas por rol: "admin=1000,auditor=50"
+	RateLimitRoutes   string `env:"RATE_LIMIT_ROUTES" recargable:"true"`   // Cuotas adicionales por ruta: "POST /api/v1/transaccion/lote=10"
+
+	// CORS
+	CORSAllowedOrigins   []string `env:"CORS_ALLOWED_ORIGINS" recargable:"true"`   // Orígenes permitidos; "*" = cualquiera, "https://*.dominio" = sus subdominios
+	CORSAllowedMethods   []string `env:"CORS_ALLOWED_METHODS" recargable:"true"`   // Métodos permitidos en peticiones de otro origen
+	CORSAllowedHeaders   []string `env:"CORS_ALLOWED_HEADERS" recargable:"true"`   // Cabeceras que el navegador puede enviar
+	CORSExposedHeaders   []string `env:"CORS_EXPOSED_HEADERS" recargable:"true"`   // Cabeceras de la respuesta legibles desde el navegador
+	CORSAllowCredentials bool     `env:"CORS_ALLOW_CREDENTIALS" recargable:"true"` // Cookies y Authorization de otro origen; no se admite con "*"
+	CORSMaxAge           int      `env:"CORS_MAX_AGE" recargable:"true"`           // Segundos que el navegador guarda la respuesta preflight
 }
 
+// Almacenes de RATE_LIMIT_STORE
+const (
+	AlmacenRateLimitMemoria  = "memory"
+	AlmacenRateLimitDynamoDB = "dynamodb"
+)
+
 var AppConfig *Config
 
-// LoadConfig carga la configuración desde variables de entorno
-func LoadConfig() (*Config, error) {
-	// Intentar cargar .env solo en desarrollo
-	_ = godotenv.Load()
-
-	config := &Config{
-		AWSRegion:                getEnv("AWS_REGION", "us-east-1"),
-		AWSAccessKeyID:           getEnv("AWS_ACCESS_KEY_ID", ""),
-		AWSSecretKey:             getEnv("AWS_SECRET_ACCESS_KEY", ""),
-		DynamoDBTableName:        getEnv("DYNAMODB_TABLE_NAME", "transacciones-blockchain"),
-		UseAWSSecrets:            getEnvAsBool("USE_AWS_SECRETS", false),
-		AlchemyAPIKey:            getEnv("ALCHEMY_API_KEY", ""),
-		BlockchainRPCURL:         getEnv("BLOCKCHAIN_RPC_URL", ""),
-		BlockchainNetwork:        getEnv("BLOCKCHAIN_NETWORK", "sepolia"),
-		BlockchainPrivateKeyName: getEnv("BLOCKCHAIN_PRIVATE_KEY_SECRET", "blockchain-private-key"),
-		ContractAddress:          getEnv("CONTRACT_ADDRESS", ""),
-		IPFSHost:                 getEnv("IPFS_HOST", "localhost"),
-		IPFSPort:                 getEnv("IPFS_PORT", "5001"),
-		IPFSGatewayPort:          getEnv("IPFS_GATEWAY_PORT", "8081"),
-		ServerPort:               getEnv("SERVER_PORT", "8080"),
-		GinMode:                  getEnv("GIN_MODE", "debug"),
-		EncryptionKey:            getEnv("ENCRYPTION_KEY", ""),
-		RateLimitRequests:        getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
-		RateLimitWindow:          getEnvAsInt("RATE_LIMIT_WINDOW", 60),
-	}
-
-	// Validar configuración crítica
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("configuración inválida: %w", err)
+// porDefecto retorna la configuración antes de aplicar el archivo y las variables de entorno
+func porDefecto() *Config {
+	return &Config{
+		AWSRegion:                "us-east-1",
+		DynamoDBTableName:        "transacciones-blockchain",
+		DynamoDBControlTableName: "transacciones-blockchain-control",
+		BlockchainNetwork:        "sepolia",
+		BlockchainPrivateKeyName: "blockchain-private-key",
+		IPFSHost:                 "localhost",
+		IPFSPort:                 "5001",
+		IPFSGatewayPort:          "8081",
+		IPFSReplicationFactor:    1,
+		IPFSRepairInterval:       3600,
+		AttachmentsMaxBytes:      25 << 20,
+		AttachmentsMaxFiles:      10,
+		AttachmentsMimeTypes:     []string{"application/pdf", "image/png", "image/jpeg", "text/csv"},
+		AttachmentsEventTypes:    []string{"fabricacion", "distribucion"},
+		BatchMaxItems:            500,
+		BatchConcurrency:         8,
+		ServerPort:               "8080",
+		HTTPEnabled:              true,
+		TLSPort:                  "8443",
+		TLSClientAuth:            "optional",
+		GRPCEnabled:              true,
+		GRPCPort:                 "9090",
+		StreamHistorySize:        1000,
+		StreamSubscriberBuffer:   256,
+		StreamMaxSubscribers:     1000,
+		StreamHeartbeat:          15,
+		WebhookMaxAttempts:       8,
+		WebhookRetryBase:         10,
+		WebhookRetryMax:          3600,
+		WebhookTimeout:           10,
+		WebhookConcurrency:       4,
+		WebhookRetentionDays:     7,
+		GinMode:                  "debug",
+		LogLevel:                 "info",
+		LogFormat:                "json",
+		LogRedactPaths:           []string{"paciente", "**.email", "**.telefono", "**.documento"},
+		MetricsEnabled:           true,
+		ReadinessCheckTimeout:    2,
+		ReadinessCacheTTL:        5,
+		ReadinessCritical:        []string{salud.VerificacionDynamoDB, salud.VerificacionIPFS, salud.VerificacionBlockchain, salud.VerificacionContrato},
+		ReadinessMinBalance:      "0.01",
+		ReadinessMaxAnchorQueue:  1000,
+		FundsCheckInterval:       60,
+		FundsWarnBalance:         "0.05",
+		FundsWarnRunwayHours:     72,
+		TracingExporter:          trazas.ExportadorNinguno,
+		TracingServiceName:       "medisupply-api",
+		TracingSampleRatio:       1,
+		AuthEnabled:              true,
+		AuthJWTActorClaim:        "sub",
+		AuthJWTRolesClaim:        "roles",
+		PolicyReloadInterval:     30,
+		IdempotencyTTL:           86400,
+		IdempotencyWait:          10,
+		RateLimitRequests:        100,
+		RateLimitWindow:          60,
+		RateLimitStore:           AlmacenRateLimitMemoria,
+		CORSAllowedOrigins:       []string{"*"},
+		CORSAllowedMethods:       []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		CORSAllowedHeaders:       []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID", "Idempotency-Key", "Last-Event-ID", "traceparent", "tracestate"},
+		CORSExposedHeaders:       []string{"Content-Length", "X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Idempotent-Replayed"},
+		CORSAllowCredentials:     false,
+		CORSMaxAge:               43200,
 	}
+}
 
+// LoadConfig carga la configuración desde el archivo de CONFIG_FILE (si está definido) y las variables
+// de entorno
+func LoadConfig() (*Config, error) {
+	config, err := Cargar("")
+	if err != nil {
+		return nil, err
+	}
 	AppConfig = config
 	return config, nil
 }
 
-// Validate valida la configuración
+// Validate valida la configuración y reporta todos los problemas a la vez
 func (c *Config) Validate() error {
+	var errs []error
+
+	// ENCRYPTION_KEY cifra los secretos de firma de los webhooks guardados en la tabla de control
 	if c.EncryptionKey == "" {
-		return fmt.Errorf("ENCRYPTION_KEY es requerida")
+		errs = append(errs, fmt.Errorf("ENCRYPTION_KEY es requerida"))
+	} else if len(c.EncryptionKey) != 32 {
+		errs = append(errs, fmt.Errorf("ENCRYPTION_KEY debe tener exactamente 32 caracteres para AES-256"))
 	}
 
-	if len(c.EncryptionKey) != 32 {
-		return fmt.Errorf("ENCRYPTION_KEY debe tener exactamente 32 caracteres para AES-256")
+	if c.DynamoDBTableName == "" {
+		errs = append(errs, fmt.Errorf("DYNAMODB_TABLE_NAME es requerida"))
 	}
 
-	if c.DynamoDBTableName == "" {
-		return fmt.Errorf("DYNAMODB_TABLE_NAME es requerida")
+	if c.DynamoDBControlTableName == "" {
+		errs = append(errs, fmt.Errorf("DYNAMODB_CONTROL_TABLE_NAME es requerida"))
 	}
 
 	if c.IPFSHost == "" {
-		return fmt.Errorf("IPFS_HOST es requerido")
+		errs = append(errs, fmt.Errorf("IPFS_HOST es requerido"))
 	}
 
-	return nil
-}
+	if c.AuthJWTSecret != "" && len(c.AuthJWTSecret) < 32 {
+		errs = append(errs, fmt.Errorf("AUTH_JWT_HMAC_SECRET debe tener al menos 32 caracteres"))
+	}
 
-// getEnv obtiene una variable de entorno o retorna el valor por defecto
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+	if c.AuthBootstrapAPIKey != "" && len(c.AuthBootstrapAPIKey) < 32 {
+		errs = append(errs, fmt.Errorf("AUTH_BOOTSTRAP_API_KEY debe tener al menos 32 caracteres"))
 	}
-	return value
-}
 
-// getEnvAsInt obtiene una variable de entorno como entero
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+	if c.AttachmentsMaxBytes <= 0 || c.AttachmentsMaxFiles <= 0 {
+		errs = append(errs, fmt.Errorf("ATTACHMENTS_MAX_BYTES y ATTACHMENTS_MAX_FILES deben ser mayores que 0"))
 	}
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		return defaultValue
+
+	if c.BatchMaxItems <= 0 || c.BatchConcurrency <= 0 {
+		errs = append(errs, fmt.Errorf("BATCH_MAX_ITEMS y BATCH_CONCURRENCY deben ser mayores que 0"))
+	}
+
+	if c.IPFSMinPins > c.IPFSReplicationFactor {
+		errs = append(errs, fmt.Errorf("IPFS_MIN_PINS (%d) no puede ser mayor que IPFS_REPLICATION_FACTOR (%d)", c.IPFSMinPins, c.IPFSReplicationFactor))
+	}
+
+	if c.IPFSPinningServiceURL != "" && c.IPFSPinningServiceToken == "" {
+		errs = append(errs, fmt.Errorf("IPFS_PINNING_SERVICE_TOKEN es requerido cuando se configura IPFS_PINNING_SERVICE_URL"))
+	}
+
+	if c.IdempotencyTTL <= 0 || c.IdempotencyWait < 0 {
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_TTL debe ser mayor que 0 e IDEMPOTENCY_WAIT no puede ser negativo"))
+	}
+
+	if c.StreamHistorySize <= 0 || c.StreamSubscriberBuffer <= 0 || c.StreamHeartbeat <= 0 || c.StreamMaxSubscribers < 0 {
+		errs = append(errs, fmt.Errorf("STREAM_HISTORY_SIZE, STREAM_SUBSCRIBER_BUFFER y STREAM_HEARTBEAT deben ser mayores que 0 y STREAM_MAX_SUBSCRIBERS no puede ser negativo"))
+	}
+
+	if c.WebhookMaxAttempts <= 0 || c.WebhookRetryBase <= 0 || c.WebhookRetryMax < c.WebhookRetryBase ||
+		c.WebhookTimeout <= 0 || c.WebhookConcurrency <= 0 || c.WebhookRetentionDays <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS, WEBHOOK_RETRY_BASE, WEBHOOK_TIMEOUT, WEBHOOK_CONCURRENCY y WEBHOOK_RETENTION_DAYS deben ser mayores que 0 y WEBHOOK_RETRY_MAX no puede ser menor que WEBHOOK_RETRY_BASE"))
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, fmt.Errorf("TLS_CERT_FILE y TLS_KEY_FILE deben configurarse juntos"))
 	}
-	return value
+
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		errs = append(errs, fmt.Errorf("TLS_CLIENT_CA_FILE requiere TLS_CERT_FILE y TLS_KEY_FILE"))
+	}
+
+	switch c.TLSClientAuth {
+	case "none", "optional", "require":
+	default:
+		errs = append(errs, fmt.Errorf("TLS_CLIENT_AUTH debe ser none, optional o require"))
+	}
+
+	if !c.HTTPEnabled && c.TLSCertFile == "" {
+		errs = append(errs, fmt.Errorf("HTTP_ENABLED=false requiere configurar TLS_CERT_FILE y TLS_KEY_FILE"))
+	}
+
+	if _, err := logging.ParsearNivel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+	}
+
+	switch c.LogFormat {
+	case logging.FormatoJSON, logging.FormatoTexto:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT debe ser json o text"))
+	}
+
+	if _, err := redaccion.New(redaccion.Config{RutasJSON: c.LogRedactPaths}); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_REDACT_PATHS: %w", err))
+	}
+
+	if c.ReadinessCheckTimeout <= 0 || c.ReadinessCacheTTL < 0 || c.ReadinessMaxAnchorQueue < 0 || c.BlockchainChainID < 0 {
+		errs = append(errs, fmt.Errorf("READINESS_CHECK_TIMEOUT debe ser mayor que 0 y READINESS_CACHE_TTL, READINESS_MAX_ANCHOR_QUEUE y BLOCKCHAIN_CHAIN_ID no pueden ser negativos"))
+	}
+
+	for _, nombre := range c.ReadinessCritical {
+		if !slices.Contains(salud.Nombres, nombre) {
+			errs = append(errs, fmt.Errorf("READINESS_CRITICAL: verificación '%s' desconocida; use %s", nombre, strings.Join(salud.Nombres, ", ")))
+		}
+	}
+
+	if _, err := utils.ParsearEther(c.ReadinessMinBalance); err != nil {
+		errs = append(errs, fmt.Errorf("READINESS_MIN_BALANCE_ETH: %w", err))
+	}
+
+	if c.FundsCheckInterval <= 0 || c.FundsWarnRunwayHours < 0 {
+		errs = append(errs, fmt.Errorf("FUNDS_CHECK_INTERVAL debe ser mayor que 0 y FUNDS_WARN_RUNWAY_HOURS no puede ser negativo"))
+	}
+
+	if c.FundsWarnBalance != "" {
+		if _, err := utils.ParsearEther(c.FundsWarnBalance); err != nil {
+			errs = append(errs, fmt.Errorf("FUNDS_WARN_BALANCE_ETH: %w", err))
+		}
+	}
+
+	if c.FundsAlertWebhookURL != "" {
+		if destino, err := url.Parse(c.FundsAlertWebhookURL); err != nil || (destino.Scheme != "http" && destino.Scheme != "https") || destino.Host == "" {
+			errs = append(errs, fmt.Errorf("FUNDS_ALERT_WEBHOOK_URL debe ser una URL http o https absoluta"))
+		}
+	}
+
+	switch c.TracingExporter {
+	case trazas.ExportadorNinguno, trazas.ExportadorStdout, trazas.ExportadorOTLP, "":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER debe ser none, stdout u otlp"))
+	}
+
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO debe estar entre 0 y 1"))
+	}
+
+	if _, err := c.CuotasRateLimit(); err != nil {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_REQUESTS, RATE_LIMIT_WINDOW, RATE_LIMIT_ROLES o RATE_LIMIT_ROUTES: %w", err))
+	}
+
+	switch c.RateLimitStore {
+	case AlmacenRateLimitMemoria, AlmacenRateLimitDynamoDB, "":
+	default:
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_STORE debe ser memory o dynamodb"))
+	}
+
+	errs = append(errs, c.validarCORS()...)
+
+	if c.GRPCEnabled && ((c.HTTPEnabled && c.GRPCPort == c.ServerPort) || (c.TLSCertFile != "" && c.GRPCPort == c.TLSPort)) {
+		errs = append(errs, fmt.Errorf("GRPC_PORT debe ser distinto de SERVER_PORT y TLS_PORT"))
+	}
+
+	return errors.Join(errs...)
 }
 
-// getEnvAsBool obtiene una variable de entorno como booleano
-func getEnvAsBool(key string, defaultValue bool) bool {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+// metodosCORS son los valores admitidos en CORS_ALLOWED_METHODS
+var metodosCORS = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// validarCORS valida la política CORS. Un origen "*" con credenciales se rechaza: el navegador no acepta
+// "Access-Control-Allow-Origin: *" con credenciales y reflejar cualquier origen expondría las sesiones.
+func (c *Config) validarCORS() []error {
+	var errs []error
+	if len(c.CORSAllowedOrigins) == 0 {
+		errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS debe tener al menos un origen"))
 	}
-	value, err := strconv.ParseBool(valueStr)
-	if err != nil {
-		return defaultValue
+	for _, origen := range c.CORSAllowedOrigins {
+		if origen == "*" {
+			if c.CORSAllowCredentials {
+				errs = append(errs, fmt.Errorf("CORS_ALLOW_CREDENTIALS=true no se admite con CORS_ALLOWED_ORIGINS=*; indique los orígenes permitidos"))
+			}
+			continue
+		}
+		if _, err := utils.ParsearOrigenCORS(origen); err != nil {
+			errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS: %w", err))
+		}
 	}
-	return value
+
+	for _, metodo := range c.CORSAllowedMethods {
+		if !slices.Contains(metodosCORS, metodo) {
+			errs = append(errs, fmt.Errorf("CORS_ALLOWED_METHODS: método '%s' no soportado; use %s", metodo, strings.Join(metodosCORS, ", ")))
+		}
+	}
+	for _, cabecera := range append(slices.Clone(c.CORSAllowedHeaders), c.CORSExposedHeaders...) {
+		if strings.ContainsAny(cabecera, " \t:") {
+			errs = append(errs, fmt.Errorf("CORS_ALLOWED_HEADERS o CORS_EXPOSED_HEADERS: '%s' no es un nombre de cabecera válido", cabecera))
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("CORS_MAX_AGE no puede ser negativo"))
+	}
+	return errs
+}
+
+// CuotasRateLimit construye las cuotas de rate limiting a partir de RATE_LIMIT_*
+func (c *Config) CuotasRateLimit() (ratelimit.Cuotas, error) {
+	return ratelimit.NewCuotas(c.RateLimitRequests, time.Duration(c.RateLimitWindow)*time.Second, c.RateLimitRoles, c.RateLimitRoutes)
 }
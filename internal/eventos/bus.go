@@ -0,0 +1,248 @@
+// Package eventos reparte entre los suscriptores (SSE, WebSocket) las notificaciones de cambio de
+// estado de las transacciones y conserva un historial acotado para reanudar desde el último ID recibido.
+package eventos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+var (
+	// ErrSuscriptorLento indica que el suscriptor no consumió a tiempo y se desconectó para no frenar al bus
+	ErrSuscriptorLento = i18n.NuevoError("eventos.suscriptor-lento")
+	// ErrBusCerrado indica que el servidor se está apagando
+	ErrBusCerrado = i18n.NuevoError("eventos.bus-cerrado")
+	// ErrDemasiadosSuscriptores indica que se alcanzó el máximo de suscripciones simultáneas
+	ErrDemasiadosSuscriptores = i18n.NuevoError("eventos.demasiados-suscriptores")
+	// ErrUltimoIDInvalido indica que el Last-Event-ID no tiene el formato de los IDs del bus
+	ErrUltimoIDInvalido = i18n.NuevoError("ultimo-id-invalido")
+)
+
+// Config define el tamaño del historial y de las colas de los suscriptores
+type Config struct {
+	Historial          int // Notificaciones conservadas para reanudar
+	BufferSuscriptor   int // Notificaciones pendientes por suscriptor antes de desconectarlo
+	MaximoSuscriptores int // Suscripciones simultáneas (0 = sin límite)
+}
+
+func defaultConfig() Config {
+	return Config{Historial: 1000, BufferSuscriptor: 256}
+}
+
+// Filtro selecciona las notificaciones de una suscripción. Una notificación pasa si coincide con
+// alguno de los valores indicados; un filtro vacío recibe todas.
+type Filtro struct {
+	Transacciones []string
+	Productos     []string
+	Actores       []string
+}
+
+// Vacio indica si el filtro no restringe nada
+func (f Filtro) Vacio() bool {
+	return len(f.Transacciones) == 0 && len(f.Productos) == 0 && len(f.Actores) == 0
+}
+
+// Coincide indica si la notificación pasa el filtro
+func (f Filtro) Coincide(n *models.Notificacion) bool {
+	if f.Vacio() {
+		return true
+	}
+	return contiene(f.Transacciones, n.IDTransaction) || contiene(f.Productos, n.IDProducto) || contiene(f.Actores, n.ActorEmisor)
+}
+
+func contiene(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// Bus publica notificaciones sin bloquear a quien publica: cada suscriptor tiene una cola acotada y,
+// si se llena, se desconecta con ErrSuscriptorLento para que reanude desde el historial.
+type Bus struct {
+	mu           sync.Mutex
+	config       Config
+	epoca        string // Distingue los IDs de esta instancia de los de un proceso anterior
+	secuencia    uint64
+	historial    []models.Notificacion // Búfer circular; historial[inicio] es la más antigua
+	inicio       int
+	suscriptores map[*Suscripcion]struct{}
+	cerrado      bool
+}
+
+// NewBus crea un bus vacío; los valores de cfg no positivos usan los valores por defecto
+func NewBus(cfg Config) *Bus {
+	def := defaultConfig()
+	if cfg.Historial <= 0 {
+		cfg.Historial = def.Historial
+	}
+	if cfg.BufferSuscriptor <= 0 {
+		cfg.BufferSuscriptor = def.BufferSuscriptor
+	}
+	return &Bus{
+		config:       cfg,
+		epoca:        strconv.FormatInt(time.Now().UnixNano(), 36),
+		historial:    make([]models.Notificacion, 0, cfg.Historial),
+		suscriptores: make(map[*Suscripcion]struct{}),
+	}
+}
+
+// Publicar asigna ID y fecha a la notificación, la guarda en el historial y la entrega a los suscriptores
+// cuyo filtro coincide. Nunca bloquea; un bus nil o cerrado la descarta.
+func (b *Bus) Publicar(n models.Notificacion) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cerrado {
+		return
+	}
+
+	b.secuencia++
+	n.ID = b.epoca + "-" + strconv.FormatUint(b.secuencia, 10)
+	if n.Fecha.IsZero() {
+		n.Fecha = time.Now().UTC()
+	}
+	if len(b.historial) < b.config.Historial {
+		b.historial = append(b.historial, n)
+	} else {
+		b.historial[b.inicio] = n
+		b.inicio = (b.inicio + 1) % len(b.historial)
+	}
+
+	for s := range b.suscriptores {
+		if !s.filtro.Coincide(&n) {
+			continue
+		}
+		select {
+		case s.canal <- n:
+		default:
+			b.cerrarSuscripcion(s, ErrSuscriptorLento)
+		}
+	}
+}
+
+// Suscripcion recibe las notificaciones que coinciden con su filtro hasta que se cancela o el bus la cierra
+type Suscripcion struct {
+	// Pendientes son las notificaciones del historial posteriores al último ID indicado, a enviar antes que el canal
+	Pendientes []models.Notificacion
+	// Incompleta indica que el último ID es anterior al historial conservado o de otra instancia:
+	// pudieron perderse notificaciones y el cliente debe resincronizar por la API REST
+	Incompleta bool
+
+	canal  chan models.Notificacion
+	filtro Filtro
+	bus    *Bus
+	motivo error
+}
+
+// Suscribir registra una suscripción. Con ultimoID (Last-Event-ID) se incluyen en Pendientes las
+// notificaciones del historial posteriores a él, sin huecos ni duplicados respecto al canal.
+func (b *Bus) Suscribir(filtro Filtro, ultimoID string) (*Suscripcion, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cerrado {
+		return nil, ErrBusCerrado
+	}
+	if b.config.MaximoSuscriptores > 0 && len(b.suscriptores) >= b.config.MaximoSuscriptores {
+		return nil, ErrDemasiadosSuscriptores
+	}
+
+	s := &Suscripcion{
+		canal:  make(chan models.Notificacion, b.config.BufferSuscriptor),
+		filtro: filtro,
+		bus:    b,
+	}
+	if ultimoID != "" {
+		if err := b.reanudar(s, ultimoID); err != nil {
+			return nil, err
+		}
+	}
+	b.suscriptores[s] = struct{}{}
+	return s, nil
+}
+
+// reanudar copia en s.Pendientes el historial posterior a ultimoID. Debe llamarse con b.mu tomado.
+func (b *Bus) reanudar(s *Suscripcion, ultimoID string) error {
+	epoca, secuenciaTexto, ok := strings.Cut(ultimoID, "-")
+	secuencia, err := strconv.ParseUint(secuenciaTexto, 10, 64)
+	if !ok || epoca == "" || err != nil {
+		return fmt.Errorf("%w: %q", ErrUltimoIDInvalido, ultimoID)
+	}
+
+	// Los IDs se asignan en orden, así que el historial cubre (secuencia - len, secuencia]
+	primera := b.secuencia - uint64(len(b.historial)) + 1
+	desde := uint64(0)
+	switch {
+	case epoca != b.epoca || secuencia > b.secuencia:
+		s.Incompleta = true
+	case secuencia+1 < primera:
+		s.Incompleta = true
+	default:
+		desde = secuencia + 1
+	}
+
+	for i := range b.historial {
+		n := b.historial[(b.inicio+i)%len(b.historial)]
+		if primera+uint64(i) >= desde && s.filtro.Coincide(&n) {
+			s.Pendientes = append(s.Pendientes, n)
+		}
+	}
+	return nil
+}
+
+// Notificaciones retorna el canal de la suscripción; se cierra al cancelarla o cuando el bus la desconecta
+func (s *Suscripcion) Notificaciones() <-chan models.Notificacion {
+	return s.canal
+}
+
+// Err retorna por qué el bus cerró la suscripción (ErrSuscriptorLento, ErrBusCerrado) o nil si sigue abierta o se canceló
+func (s *Suscripcion) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.motivo
+}
+
+// Cancelar da de baja la suscripción; puede llamarse varias veces
+func (s *Suscripcion) Cancelar() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.cerrarSuscripcion(s, nil)
+}
+
+// cerrarSuscripcion quita la suscripción y cierra su canal. Debe llamarse con b.mu tomado.
+func (b *Bus) cerrarSuscripcion(s *Suscripcion, motivo error) {
+	if _, ok := b.suscriptores[s]; !ok {
+		return
+	}
+	delete(b.suscriptores, s)
+	s.motivo = motivo
+	close(s.canal)
+}
+
+// Suscriptores retorna el número de suscripciones abiertas
+func (b *Bus) Suscriptores() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.suscriptores)
+}
+
+// Cerrar desconecta a todos los suscriptores con ErrBusCerrado y descarta las publicaciones posteriores.
+// Se llama antes de apagar los servidores HTTP para que los streams abiertos terminen.
+func (b *Bus) Cerrar() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cerrado = true
+	for s := range b.suscriptores {
+		b.cerrarSuscripcion(s, ErrBusCerrado)
+	}
+}
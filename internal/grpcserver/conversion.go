@@ -0,0 +1,125 @@
+package grpcserver
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// marcaTiempo convierte una fecha; la fecha cero se omite
+func marcaTiempo(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func solicitudDesdePB(req *pb.TransaccionRequest) *models.TransaccionRequest {
+	return &models.TransaccionRequest{
+		TipoEvento:  req.GetTipoEvento(),
+		IDProducto:  req.GetIdProducto(),
+		DatosEvento: req.GetDatosEvento(),
+		ActorEmisor: req.GetActorEmisor(),
+	}
+}
+
+func transaccionPB(t *models.Transaccion) *pb.Transaccion {
+	adjuntos := make([]*pb.Adjunto, 0, len(t.Adjuntos))
+	for _, adjunto := range t.Adjuntos {
+		adjuntos = append(adjuntos, &pb.Adjunto{
+			Cid:        adjunto.CID,
+			Nombre:     adjunto.Nombre,
+			TipoMime:   adjunto.TipoMIME,
+			Tamano:     adjunto.Tamano,
+			HashSha256: adjunto.HashSHA256,
+			NodosIpfs:  adjunto.NodosIPFS,
+		})
+	}
+	return &pb.Transaccion{
+		IdTransaction:       t.IDTransaction,
+		TipoEvento:          t.TipoEvento,
+		IdProducto:          t.IDProducto,
+		FechaEvento:         marcaTiempo(t.FechaEvento),
+		DatosEvento:         t.DatosEvento,
+		HashEvento:          t.HashEvento,
+		HashVersion:         int32(t.HashVersion),
+		HashEventoAnterior:  t.HashEventoAnterior,
+		Secuencia:           t.Secuencia,
+		DirectionBlockchain: t.DirectionBlockchain,
+		EthereumTxHash:      t.EthereumTxHash,
+		IpfsCid:             t.IPFSCid,
+		NodosIpfs:           t.NodosIPFS,
+		ActorEmisor:         t.ActorEmisor,
+		Adjuntos:            adjuntos,
+		Estado:              t.Estado,
+		CreatedAt:           marcaTiempo(t.CreatedAt),
+		UpdatedAt:           marcaTiempo(t.UpdatedAt),
+	}
+}
+
+func listaTransaccionesPB(transacciones []*models.Transaccion) *pb.ListaTransacciones {
+	lista := &pb.ListaTransacciones{
+		Total:         int32(len(transacciones)),
+		Transacciones: make([]*pb.Transaccion, 0, len(transacciones)),
+	}
+	for _, transaccion := range transacciones {
+		lista.Transacciones = append(lista.Transacciones, transaccionPB(transaccion))
+	}
+	return lista
+}
+
+func resultadosLotePB(resultados []models.ResultadoLote) []*pb.ResultadoLote {
+	lista := make([]*pb.ResultadoLote, 0, len(resultados))
+	for _, r := range resultados {
+		lista = append(lista, &pb.ResultadoLote{
+			Indice:        int32(r.Indice),
+			Estado:        r.Estado,
+			IdTransaction: r.IDTransaction,
+			IdProducto:    r.IDProducto,
+			HashEvento:    r.HashEvento,
+			IpfsCid:       r.IPFSCid,
+			Secuencia:     r.Secuencia,
+			Error:         r.Error,
+		})
+	}
+	return lista
+}
+
+func eventoVerificadoPB(e models.EventoVerificado) *pb.EventoVerificado {
+	return &pb.EventoVerificado{
+		IdEvento:              e.IDEvento,
+		TipoEvento:            e.TipoEvento,
+		Fecha:                 marcaTiempo(e.Fecha),
+		ResultadoVerificacion: e.ResultadoVerificacion,
+		ReferenciaBlockchain:  e.ReferenciaBlockchain,
+		IpfsCid:               e.IPFSCid,
+		ActorEmisor:           e.ActorEmisor,
+		Secuencia:             e.Secuencia,
+		HashEventoAnterior:    e.HashEventoAnterior,
+		ErrorVerificacion:     e.ErrorVerificacion,
+	}
+}
+
+func verificacionCadenaPB(v *models.VerificacionCadena) *pb.VerificacionCadena {
+	if v == nil {
+		return nil
+	}
+	anomalias := make([]*pb.AnomaliaCadena, 0, len(v.Anomalias))
+	for _, a := range v.Anomalias {
+		anomalias = append(anomalias, &pb.AnomaliaCadena{
+			Tipo:      a.Tipo,
+			Secuencia: a.Secuencia,
+			IdEvento:  a.IDEvento,
+			Detalle:   a.Detalle,
+		})
+	}
+	return &pb.VerificacionCadena{
+		Integra:             v.Integra,
+		Longitud:            v.Longitud,
+		EventosSinEncadenar: int32(v.EventosSinEncadenar),
+		Anomalias:           anomalias,
+	}
+}
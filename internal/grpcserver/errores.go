@@ -0,0 +1,126 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// dominioErrores identifica los ErrorInfo de esta API
+const dominioErrores = "medisupply"
+
+// codigoPorTipo traduce la clasificación de los errores de dominio al código gRPC, el equivalente
+// del status HTTP que responde la API REST
+var codigoPorTipo = map[services.TipoError]codes.Code{
+	services.TipoNoEncontrado:    codes.NotFound,
+	services.TipoValidacion:      codes.InvalidArgument,
+	services.TipoConflicto:       codes.Aborted,
+	services.TipoNoProcesable:    codes.FailedPrecondition,
+	services.TipoDemasiadoGrande: codes.InvalidArgument,
+	services.TipoNoSoportado:     codes.InvalidArgument,
+	services.TipoNoDisponible:    codes.Unavailable,
+	services.TipoTimeout:         codes.DeadlineExceeded,
+}
+
+// errorGRPC traduce un error de los servicios a un status gRPC con el mensaje en el idioma de ctx. Los
+// errores sin clasificar usan codigo y el mensaje de la clave sin exponer el error interno, que queda en el log.
+func errorGRPC(ctx context.Context, err error, codigo codes.Code, clave string) error {
+	idioma := i18n.IdiomaDe(ctx)
+	mensaje := i18n.Traducir(idioma, clave)
+	var (
+		denegacion    *policy.Denegacion
+		errValidacion *services.ErrorValidacionLote
+		dominio       *services.ErrorDominio
+	)
+	switch {
+	case errors.As(err, &denegacion):
+		return errorDenegacion(ctx, denegacion)
+	case errors.As(err, &errValidacion):
+		st := status.New(codes.InvalidArgument, errValidacion.Traducir(idioma))
+		solicitud := &errdetails.BadRequest{}
+		for _, resultado := range errValidacion.Resultados {
+			if resultado.Error != "" {
+				solicitud.FieldViolations = append(solicitud.FieldViolations, &errdetails.BadRequest_FieldViolation{
+					Field:       fmt.Sprintf("eventos[%d]", resultado.Indice),
+					Description: redaccion.Texto(resultado.Error),
+				})
+			}
+		}
+		return conDetalles(st, solicitud)
+	case errors.As(err, &dominio):
+		codigoDominio, ok := codigoPorTipo[dominio.Tipo]
+		if !ok {
+			codigoDominio = codes.Internal
+		}
+		mensajeDominio := dominio.MensajeEn(idioma)
+		if dominio.EsErrorDelCliente() {
+			mensajeDominio = i18n.TraducirError(err, idioma)
+		} else {
+			slog.ErrorContext(ctx, "gRPC: "+i18n.Traducir(i18n.IdiomaPorDefecto, clave), "error", err)
+		}
+		return conDetalles(status.New(codigoDominio, mensajeDominio), &errdetails.ErrorInfo{
+			Reason: razonError(dominio.Codigo),
+			Domain: dominioErrores,
+		})
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	default:
+		slog.ErrorContext(ctx, "gRPC: "+i18n.Traducir(i18n.IdiomaPorDefecto, clave), "error", err)
+		return status.Error(codigo, mensaje)
+	}
+}
+
+// razonError convierte el código estable de la API REST (transaccion-no-encontrada) al formato
+// de ErrorInfo.Reason (TRANSACCION_NO_ENCONTRADA)
+func razonError(codigo string) string {
+	return strings.ToUpper(strings.ReplaceAll(codigo, "-", "_"))
+}
+
+// errorDenegacion responde PERMISSION_DENIED con la regla que denegó en un ErrorInfo
+func errorDenegacion(ctx context.Context, denegacion *policy.Denegacion) error {
+	idioma := i18n.IdiomaDe(ctx)
+	st := status.New(codes.PermissionDenied, i18n.Traducir(idioma, "general.con-causa", i18n.Titulo(idioma, "acceso-denegado"), denegacion.MotivoEn(idioma)))
+	return conDetalles(st, &errdetails.ErrorInfo{
+		Reason:   "ACCESO_DENEGADO",
+		Domain:   dominioErrores,
+		Metadata: map[string]string{"regla": denegacion.Regla},
+	})
+}
+
+// conDetalles agrega detalles al status; si no se pueden serializar se responde sin ellos
+func conDetalles(st *status.Status, detalles ...protoiface.MessageV1) error {
+	conDetalles, err := st.WithDetails(detalles...)
+	if err != nil {
+		return st.Err()
+	}
+	return conDetalles.Err()
+}
+
+// redactarEstado enmascara los secretos en el mensaje del status que se responde al cliente, la última
+// barrera para los errores que los handlers retornan sin pasar por errorGRPC
+func redactarEstado(err *error) {
+	if *err == nil {
+		return
+	}
+	st, ok := status.FromError(*err)
+	if !ok {
+		return
+	}
+	if mensaje := redaccion.Texto(st.Message()); mensaje != st.Message() {
+		proto := st.Proto()
+		proto.Message = mensaje
+		*err = status.FromProto(proto).Err()
+	}
+}
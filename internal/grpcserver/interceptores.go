@@ -0,0 +1,276 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// rutaREST es la ruta REST equivalente a un RPC; la política de rutas se evalúa contra ella
+type rutaREST struct {
+	metodo string
+	ruta   string
+}
+
+// rutasEquivalentes asocia cada RPC a su ruta REST. Un RPC que no figura aquí se deniega con la
+// regla por defecto, así que cada RPC nuevo debe agregarse junto con su definición en el .proto.
+var rutasEquivalentes = map[string]rutaREST{
+	pb.Transacciones_RegistrarTransaccion_FullMethodName:            {http.MethodPost, "/api/v1/transaccion/registrar"},
+	pb.Transacciones_RegistrarLote_FullMethodName:                   {http.MethodPost, "/api/v1/transaccion/lote"},
+	pb.Transacciones_ObtenerTransaccion_FullMethodName:              {http.MethodGet, "/api/v1/transaccion/:id"},
+	pb.Transacciones_ListarTransacciones_FullMethodName:             {http.MethodGet, "/api/v1/transaccion"},
+	pb.Transacciones_ObtenerTransaccionesPorProducto_FullMethodName: {http.MethodGet, "/api/v1/transaccion/producto/:id"},
+	pb.Verificacion_VerificarTransaccion_FullMethodName:             {http.MethodGet, "/api/v1/transaccion/verificar/:id"},
+	pb.Verificacion_ObtenerEstadoBlockchain_FullMethodName:          {http.MethodGet, "/api/v1/transaccion/estado-blockchain/:id"},
+	pb.Oracle_ObtenerDatosVerificados_FullMethodName:                {http.MethodGet, "/api/v1/oracle/datos/:id"},
+	pb.Oracle_ObtenerHistorialVerificado_FullMethodName:             {http.MethodGet, "/api/v1/oracle/historial/:id"},
+	pb.Oracle_ValidarCadenaSupply_FullMethodName:                    {http.MethodGet, "/api/v1/oracle/validar/:id"},
+	pb.IPFS_ObtenerArchivo_FullMethodName:                           {http.MethodGet, "/api/v1/ipfs/archivo/:cid"},
+	pb.IPFS_ObtenerEstadisticas_FullMethodName:                      {http.MethodGet, "/api/v1/ipfs/estadisticas"},
+}
+
+// RutaEquivalente retorna el método y la ruta REST con que se autoriza un RPC
+func RutaEquivalente(metodoCompleto string) (metodo, ruta string, ok bool) {
+	equivalente, ok := rutasEquivalentes[metodoCompleto]
+	return equivalente.metodo, equivalente.ruta, ok
+}
+
+// esPublico indica si el RPC no requiere credencial (health checks, igual que /health y /ready)
+func esPublico(metodoCompleto string) bool {
+	return strings.HasPrefix(metodoCompleto, "/"+grpc_health_v1.Health_ServiceDesc.ServiceName+"/")
+}
+
+// interceptor aplica rate limiting, autenticación y la política de rutas antes de cada RPC
+type interceptor struct {
+	deps        Dependencias
+	authEnabled bool
+}
+
+func (i *interceptor) unario(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	ctx = conRequestID(ctx)
+	ctx, span := iniciarSpanRPC(ctx, info.FullMethod)
+	defer terminarSpanRPC(span, &err)
+	defer registrarRPC(ctx, info.FullMethod, time.Now(), &err)
+	defer redactarEstado(&err)
+	ctx, err = i.admitir(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *interceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, span := iniciarSpanRPC(conRequestID(ss.Context()), info.FullMethod)
+	defer terminarSpanRPC(span, &err)
+	defer registrarRPC(ctx, info.FullMethod, time.Now(), &err)
+	defer redactarEstado(&err)
+	ctx, err = i.admitir(ctx, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &streamConContexto{ServerStream: ss, ctx: ctx})
+}
+
+// conRequestID asigna el request ID del RPC (la metadata x-request-id si es válida, o uno nuevo),
+// lo deja en el contexto y lo retorna en los headers de la respuesta, igual que LoggerMiddleware
+func conRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var requestID string
+	if valores := md.Get(metadataRequestID); len(valores) > 0 {
+		requestID = valores[0]
+	}
+	if !logging.RequestIDValido(requestID) {
+		requestID = logging.NuevoRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID))
+	return logging.ConRequestID(ctx, requestID)
+}
+
+// registrarRPC registra el método, el código de status y la latencia de un RPC al terminar
+func registrarRPC(ctx context.Context, metodoCompleto string, inicio time.Time, err *error) {
+	codigo := status.Code(*err)
+	nivel := slog.LevelInfo
+	switch codigo {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unavailable, codes.Unknown, codes.DataLoss:
+		nivel = slog.LevelError
+	default:
+		nivel = slog.LevelWarn
+	}
+	slog.Log(ctx, nivel, "petición gRPC",
+		"metodo", metodoCompleto,
+		"codigo", codigo.String(),
+		"latencia_ms", time.Since(inicio).Milliseconds(),
+		"ip", ipCliente(ctx),
+	)
+}
+
+// metadataRequestID es la clave de metadata equivalente a la cabecera X-Request-ID
+const metadataRequestID = "x-request-id"
+
+// admitir retorna el contexto con el principal autenticado o el status con que se rechaza el RPC
+func (i *interceptor) admitir(ctx context.Context, metodoCompleto string) (context.Context, error) {
+	ctx = i18n.ConIdioma(ctx, idiomaMetadata(ctx))
+	equivalente, ok := rutasEquivalentes[metodoCompleto]
+	if !i.authEnabled || esPublico(metodoCompleto) {
+		if err := i.limitar(ctx, nil, equivalente); err != nil {
+			return nil, err
+		}
+		return ctx, nil
+	}
+
+	// Igual que en REST: antes de autenticar solo se consulta el cupo de la IP, que se descuenta si
+	// la credencial es rechazada; el resto de las peticiones consume el cupo del principal
+	if i.deps.RateLimiter != nil {
+		if decision := i.deps.RateLimiter.ConsultarIP(ctx, ipCliente(ctx)); !decision.Permitida {
+			return nil, rechazarSinCupo(ctx, decision)
+		}
+	}
+	principal, err := i.autenticar(ctx)
+	if err != nil {
+		if i.deps.RateLimiter != nil && status.Code(err) == codes.Unauthenticated {
+			i.deps.RateLimiter.PenalizarIP(ctx, ipCliente(ctx))
+		}
+		return nil, err
+	}
+	if err := i.limitar(ctx, principal, equivalente); err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, errorDenegacion(ctx, policy.NuevaDenegacion(policy.ReglaPorDefecto, "politica.rpc-sin-ruta", metodoCompleto))
+	}
+	if err := i.deps.Politica.AutorizarRuta(principal, equivalente.metodo, equivalente.ruta); err != nil {
+		var denegacion *policy.Denegacion
+		if errors.As(err, &denegacion) {
+			return nil, errorDenegacion(ctx, denegacion)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return auth.ConPrincipal(ctx, principal), nil
+}
+
+// limitar consume el cupo del principal (o de la IP si es nil) y la cuota de la ruta REST equivalente
+func (i *interceptor) limitar(ctx context.Context, principal *models.Principal, equivalente rutaREST) error {
+	if i.deps.RateLimiter == nil {
+		return nil
+	}
+	return rechazarSinCupo(ctx, i.deps.RateLimiter.Admitir(ctx, ipCliente(ctx), principal, equivalente.metodo, equivalente.ruta))
+}
+
+// rechazarSinCupo envía las cabeceras RateLimit-* como metadata y retorna ResourceExhausted si la
+// decisión rechaza el RPC
+func rechazarSinCupo(ctx context.Context, decision ratelimit.Decision) error {
+	if cabeceras := middleware.CabecerasRateLimit(decision); cabeceras != nil {
+		_ = grpc.SetHeader(ctx, metadata.New(cabeceras))
+	}
+	if decision.Permitida {
+		return nil
+	}
+	metricas.RechazoRateLimit("grpc")
+	return status.Error(codes.ResourceExhausted, i18n.T(ctx, "general.con-causa", i18n.Titulo(i18n.IdiomaDe(ctx), "demasiadas-peticiones"), i18n.T(ctx, "general.intente-mas-tarde")))
+}
+
+// autenticar acepta las mismas credenciales que AuthMiddleware: x-api-key o authorization: Bearer
+// en metadata, o el certificado de cliente verificado por mTLS
+func (i *interceptor) autenticar(ctx context.Context) (*models.Principal, error) {
+	credencial, esBearer := credencialMetadata(ctx)
+	principal, err := i.deps.AuthConfig.Autenticar(ctx, credencial, esBearer, certificadoCliente(ctx))
+	switch {
+	case err == nil:
+		return principal, nil
+	case errors.Is(err, middleware.ErrSinCredenciales):
+		return nil, status.Error(codes.Unauthenticated, mensajeConCausa(ctx, middleware.CodigoNoAutenticado, err))
+	case middleware.EsCredencialInvalida(err):
+		return nil, status.Error(codes.Unauthenticated, mensajeConCausa(ctx, middleware.CodigoCredencialesInvalidas, err))
+	default:
+		slog.ErrorContext(ctx, "gRPC: error validando credenciales", "error", err)
+		return nil, status.Error(codes.Unavailable, mensajeConCausa(ctx, middleware.CodigoAutenticacionNoDisponible, err))
+	}
+}
+
+// mensajeConCausa forma "<mensaje del código>: <causa>" en el idioma de ctx
+func mensajeConCausa(ctx context.Context, codigo string, causa error) string {
+	idioma := i18n.IdiomaDe(ctx)
+	return i18n.Traducir(idioma, "general.con-causa", i18n.Titulo(idioma, codigo), causa)
+}
+
+// idiomaMetadata elige el idioma según la metadata accept-language, igual que Accept-Language en REST
+func idiomaMetadata(ctx context.Context) i18n.Idioma {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return i18n.DesdeAcceptLanguage(strings.Join(md.Get("accept-language"), ","))
+}
+
+// credencialMetadata obtiene la credencial de x-api-key o de authorization: Bearer
+func credencialMetadata(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if valores := md.Get("x-api-key"); len(valores) > 0 && strings.TrimSpace(valores[0]) != "" {
+		return strings.TrimSpace(valores[0]), false
+	}
+	if valores := md.Get("authorization"); len(valores) > 0 {
+		cabecera := valores[0]
+		if len(cabecera) > 7 && strings.EqualFold(cabecera[:7], "bearer ") {
+			return strings.TrimSpace(cabecera[7:]), true
+		}
+	}
+	return "", false
+}
+
+// certificadoCliente retorna el certificado de cliente verificado de la conexión, o nil
+func certificadoCliente(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return auth.CertificadoVerificado(&info.State)
+}
+
+// ipCliente es la IP del par; el rate limiting se aplica por IP como en la API REST
+func ipCliente(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// streamConContexto reemplaza el contexto de un stream por el que lleva el principal
+type streamConContexto struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *streamConContexto) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,235 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// Los RPC aplican los mismos plazos y las mismas validaciones que los handlers REST equivalentes.
+
+// transaccionesServer implementa pb.TransaccionesServer
+type transaccionesServer struct {
+	pb.UnimplementedTransaccionesServer
+	servicio *services.TransaccionService
+}
+
+func (s *transaccionesServer) RegistrarTransaccion(ctx context.Context, req *pb.TransaccionRequest) (*pb.Transaccion, error) {
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+
+	transaccion, err := s.servicio.RegistrarTransaccion(ctx, solicitudDesdePB(req))
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-registrando-transaccion")
+	}
+	return transaccionPB(transaccion), nil
+}
+
+func (s *transaccionesServer) RegistrarLote(ctx context.Context, req *pb.RegistrarLoteRequest) (*pb.LoteResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	reqs := make([]*models.TransaccionRequest, 0, len(req.GetEventos()))
+	for _, evento := range req.GetEventos() {
+		reqs = append(reqs, solicitudDesdePB(evento))
+	}
+	respuesta, err := s.servicio.RegistrarLote(ctx, reqs)
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-registrando-lote")
+	}
+	return &pb.LoteResponse{
+		Total:       int32(respuesta.Total),
+		Registradas: int32(respuesta.Registradas),
+		Fallidas:    int32(respuesta.Fallidas),
+		Resultados:  resultadosLotePB(respuesta.Resultados),
+	}, nil
+}
+
+func (s *transaccionesServer) ObtenerTransaccion(ctx context.Context, req *pb.ObtenerTransaccionRequest) (*pb.Transaccion, error) {
+	if req.GetIdTransaction() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "transaccion.id-requerido"))
+	}
+	transaccion, err := s.servicio.ObtenerTransaccion(ctx, req.GetIdTransaction())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-obteniendo-transaccion")
+	}
+	return transaccionPB(transaccion), nil
+}
+
+func (s *transaccionesServer) ListarTransacciones(ctx context.Context, req *pb.ListarTransaccionesRequest) (*pb.ListaTransacciones, error) {
+	limit := req.GetLimit()
+	if limit <= 0 {
+		limit = 50
+	}
+	transacciones, err := s.servicio.ListarTransacciones(ctx, limit)
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-listando-transacciones")
+	}
+	return listaTransaccionesPB(transacciones), nil
+}
+
+func (s *transaccionesServer) ObtenerTransaccionesPorProducto(ctx context.Context, req *pb.ProductoRequest) (*pb.ListaTransacciones, error) {
+	if req.GetIdProducto() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "oracle.id-producto-requerido"))
+	}
+	transacciones, err := s.servicio.ObtenerTransaccionesPorProducto(ctx, req.GetIdProducto())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-transacciones-producto")
+	}
+	return listaTransaccionesPB(transacciones), nil
+}
+
+// verificacionServer implementa pb.VerificacionServer
+type verificacionServer struct {
+	pb.UnimplementedVerificacionServer
+	servicio *services.TransaccionService
+}
+
+func (s *verificacionServer) VerificarTransaccion(ctx context.Context, req *pb.ObtenerTransaccionRequest) (*pb.VerificacionResponse, error) {
+	if req.GetIdTransaction() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "transaccion.id-requerido"))
+	}
+	v, err := s.servicio.VerificarIntegridad(ctx, req.GetIdTransaction())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-verificando-transaccion")
+	}
+	return &pb.VerificacionResponse{
+		IdTransaction:        v.IDTransaction,
+		Verificado:           v.Verificado,
+		HashLocal:            v.HashLocal,
+		HashBlockchain:       v.HashBlockchain,
+		DatosIpfsVerificados: v.DatosIPFSVerificados,
+		AdjuntosVerificados:  v.AdjuntosVerificados,
+		Mensaje:              v.Mensaje,
+	}, nil
+}
+
+func (s *verificacionServer) ObtenerEstadoBlockchain(ctx context.Context, req *pb.ObtenerTransaccionRequest) (*pb.EstadoBlockchain, error) {
+	if req.GetIdTransaction() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "transaccion.id-requerido"))
+	}
+	e, err := s.servicio.ObtenerEstadoBlockchain(ctx, req.GetIdTransaction())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-obteniendo-transaccion")
+	}
+	return &pb.EstadoBlockchain{
+		IdTransaction:          e.IDTransaction,
+		Estado:                 e.Estado,
+		RegistradoEnBlockchain: e.RegistradoEnBlockchain,
+		DirectionBlockchain:    e.DirectionBlockchain,
+		EthereumTxHash:         e.EthereumTxHash,
+		Mensaje:                e.Mensaje,
+		Timestamp:              e.Timestamp,
+	}, nil
+}
+
+// oracleServer implementa pb.OracleServer
+type oracleServer struct {
+	pb.UnimplementedOracleServer
+	servicio *services.OracleService
+}
+
+func (s *oracleServer) ObtenerDatosVerificados(ctx context.Context, req *pb.ProductoRequest) (*pb.DatosVerificados, error) {
+	if req.GetIdProducto() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "oracle.id-producto-requerido"))
+	}
+	datos, err := s.servicio.ObtenerDatosVerificados(ctx, req.GetIdProducto())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-datos-verificados")
+	}
+	historial := make([]*pb.EventoVerificado, 0, len(datos.Historial))
+	for _, evento := range datos.Historial {
+		historial = append(historial, eventoVerificadoPB(evento))
+	}
+	return &pb.DatosVerificados{
+		IdProducto:          datos.IDProducto,
+		Estado:              datos.Estado,
+		UltimaActualizacion: marcaTiempo(datos.UltimaActualizacion),
+		CadenaVerificada:    datos.CadenaVerificada,
+		Historial:           historial,
+		Metadata:            datos.Metadata,
+	}, nil
+}
+
+// ObtenerHistorialVerificado envía la verificación de la cadena y después cada evento en cuanto se verifica
+func (s *oracleServer) ObtenerHistorialVerificado(req *pb.ProductoRequest, stream pb.Oracle_ObtenerHistorialVerificadoServer) error {
+	if req.GetIdProducto() == "" {
+		return status.Error(codes.InvalidArgument, i18n.T(stream.Context(), "oracle.id-producto-requerido"))
+	}
+	err := s.servicio.RecorrerHistorialVerificado(stream.Context(), req.GetIdProducto(),
+		func(total int, cadena *models.VerificacionCadena) error {
+			return stream.Send(&pb.HistorialVerificado{Contenido: &pb.HistorialVerificado_Cabecera{Cabecera: &pb.CabeceraHistorial{
+				IdProducto:   req.GetIdProducto(),
+				TotalEventos: int32(total),
+				Cadena:       verificacionCadenaPB(cadena),
+			}}})
+		},
+		func(evento models.EventoVerificado) error {
+			return stream.Send(&pb.HistorialVerificado{Contenido: &pb.HistorialVerificado_Evento{Evento: eventoVerificadoPB(evento)}})
+		})
+	if err != nil {
+		if _, esStatus := status.FromError(err); esStatus {
+			return err
+		}
+		return errorGRPC(stream.Context(), err, codes.Internal, "grpc.error-historial")
+	}
+	return nil
+}
+
+func (s *oracleServer) ValidarCadenaSupply(ctx context.Context, req *pb.ProductoRequest) (*pb.ValidacionCadena, error) {
+	if req.GetIdProducto() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "oracle.id-producto-requerido"))
+	}
+	valido, errores, err := s.servicio.ValidarCadenaSupply(ctx, req.GetIdProducto())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-validando-cadena")
+	}
+	return &pb.ValidacionCadena{
+		IdProducto:        req.GetIdProducto(),
+		CadenaValida:      valido,
+		ErroresDetectados: errores,
+	}, nil
+}
+
+// ipfsServer implementa pb.IPFSServer
+type ipfsServer struct {
+	pb.UnimplementedIPFSServer
+	servicio *services.IPFSService
+}
+
+func (s *ipfsServer) ObtenerArchivo(ctx context.Context, req *pb.ObtenerArchivoRequest) (*pb.Archivo, error) {
+	if req.GetCid() == "" {
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "ipfs.cid-requerido"))
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.servicio.VerificarLectura(ctx); err != nil {
+		return nil, errorGRPC(ctx, err, codes.Unavailable, "ipfs-no-disponible")
+	}
+	datos, err := s.servicio.RecuperarJSON(ctx, req.GetCid())
+	if err != nil {
+		return nil, errorGRPC(ctx, err, codes.Internal, "grpc.error-recuperando-archivo")
+	}
+	return &pb.Archivo{Cid: req.GetCid(), Datos: datos}, nil
+}
+
+func (s *ipfsServer) ObtenerEstadisticas(ctx context.Context, _ *pb.ObtenerEstadisticasRequest) (*pb.EstadisticasIPFS, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.servicio.VerificarLectura(ctx); err != nil {
+		return nil, errorGRPC(ctx, err, codes.Unavailable, "ipfs-no-disponible")
+	}
+	return &pb.EstadisticasIPFS{
+		Disponible:        true,
+		FactorReplicacion: int32(s.servicio.GetFactorReplicacion()),
+	}, nil
+}
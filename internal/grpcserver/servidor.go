@@ -0,0 +1,161 @@
+// Package grpcserver expone la API gRPC de pkg/pb/medisupply/v1. Usa las mismas instancias de
+// servicios que la API REST y aplica la misma autenticación, política de rutas y rate limiting.
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// intervaloSalud es cada cuánto se reevalúa la preparación para los clientes de Health/Watch
+const intervaloSalud = 10 * time.Second
+
+// VerificadorPreparacion comprueba las dependencias del servicio; HealthHandler lo implementa
+type VerificadorPreparacion interface {
+	VerificarDependencias(ctx context.Context) (map[string]string, bool)
+}
+
+// Dependencias agrupa los servicios y políticas compartidos con la API REST
+type Dependencias struct {
+	Transacciones *services.TransaccionService
+	Oracle        *services.OracleService
+	IPFS          *services.IPFSService
+	AuthEnabled   bool
+	AuthConfig    middleware.AuthConfig
+	Politica      *policy.Motor
+	RateLimiter   *middleware.RateLimiter // nil = sin rate limiting
+	Preparacion   VerificadorPreparacion  // nil = siempre SERVING
+	TLS           *tls.Config             // nil = sin cifrar
+	MaxMensaje    int                     // Tamaño máximo de un mensaje recibido; 0 = 4 MiB
+}
+
+// Servidor es el servidor gRPC con los servicios de MediSupply y el health service estándar
+type Servidor struct {
+	grpc    *grpc.Server
+	salud   *servidorSalud
+	detener chan struct{}
+}
+
+// NewServidor crea el servidor gRPC
+func NewServidor(deps Dependencias) *Servidor {
+	intercepcion := &interceptor{deps: deps, authEnabled: deps.AuthEnabled}
+	opciones := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(intercepcion.unario),
+		grpc.ChainStreamInterceptor(intercepcion.stream),
+	}
+	if deps.TLS != nil {
+		opciones = append(opciones, grpc.Creds(credentials.NewTLS(deps.TLS)))
+	}
+	if deps.MaxMensaje > 0 {
+		opciones = append(opciones, grpc.MaxRecvMsgSize(deps.MaxMensaje))
+	}
+
+	s := &Servidor{
+		grpc:    grpc.NewServer(opciones...),
+		salud:   &servidorSalud{Server: health.NewServer(), preparacion: deps.Preparacion},
+		detener: make(chan struct{}),
+	}
+	pb.RegisterTransaccionesServer(s.grpc, &transaccionesServer{servicio: deps.Transacciones})
+	pb.RegisterVerificacionServer(s.grpc, &verificacionServer{servicio: deps.Transacciones})
+	pb.RegisterOracleServer(s.grpc, &oracleServer{servicio: deps.Oracle})
+	pb.RegisterIPFSServer(s.grpc, &ipfsServer{servicio: deps.IPFS})
+	grpc_health_v1.RegisterHealthServer(s.grpc, s.salud)
+	return s
+}
+
+// Servir atiende conexiones en lis hasta que se llame a Detener
+func (s *Servidor) Servir(lis net.Listener) error {
+	s.salud.evaluar(context.Background())
+	go s.vigilarSalud()
+	return s.grpc.Serve(lis)
+}
+
+// Detener marca el servicio NOT_SERVING y espera a que terminen los RPC en curso hasta que ctx expire
+func (s *Servidor) Detener(ctx context.Context) {
+	close(s.detener)
+	s.salud.Shutdown()
+
+	terminado := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(terminado)
+	}()
+	select {
+	case <-terminado:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
+
+// vigilarSalud reevalúa la preparación periódicamente para notificar a los clientes de Watch
+func (s *Servidor) vigilarSalud() {
+	ticker := time.NewTicker(intervaloSalud)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.salud.evaluar(context.Background())
+		case <-s.detener:
+			return
+		}
+	}
+}
+
+// serviciosSalud son los nombres que acepta Health/Check; "" es el estado global del servidor
+var serviciosSalud = []string{
+	"",
+	pb.Transacciones_ServiceDesc.ServiceName,
+	pb.Verificacion_ServiceDesc.ServiceName,
+	pb.Oracle_ServiceDesc.ServiceName,
+	pb.IPFS_ServiceDesc.ServiceName,
+}
+
+// servidorSalud es el health service estándar; Check evalúa la misma lógica que GET /ready
+type servidorSalud struct {
+	*health.Server
+	preparacion VerificadorPreparacion
+}
+
+// Check reevalúa las dependencias antes de responder, igual que GET /ready
+func (s *servidorSalud) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.evaluar(ctx)
+	return s.Server.Check(ctx, req)
+}
+
+func (s *servidorSalud) evaluar(ctx context.Context) {
+	estado := grpc_health_v1.HealthCheckResponse_SERVING
+	if s.preparacion != nil && !s.listo(ctx) {
+		estado = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	for _, servicio := range serviciosSalud {
+		s.SetServingStatus(servicio, estado)
+	}
+}
+
+// listo evalúa la preparación; un pánico en una verificación (igual que RecuperacionMiddleware en REST)
+// cuenta como no preparado en lugar de tumbar el servidor
+func (s *servidorSalud) listo(ctx context.Context) (listo bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "gRPC: pánico verificando dependencias", "panico", r)
+			listo = false
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, listo = s.preparacion.VerificarDependencias(ctx)
+	return listo
+}
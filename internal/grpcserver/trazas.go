@@ -0,0 +1,67 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
+)
+
+// portadorMetadata adapta la metadata entrante al TextMapCarrier de OpenTelemetry, para leer traceparent
+// y baggage igual que de los headers HTTP
+type portadorMetadata metadata.MD
+
+func (p portadorMetadata) Get(clave string) string {
+	if valores := metadata.MD(p).Get(clave); len(valores) > 0 {
+		return valores[0]
+	}
+	return ""
+}
+
+func (p portadorMetadata) Set(clave, valor string) {
+	metadata.MD(p).Set(clave, valor)
+}
+
+func (p portadorMetadata) Keys() []string {
+	claves := make([]string, 0, len(p))
+	for clave := range p {
+		claves = append(claves, clave)
+	}
+	return claves
+}
+
+// iniciarSpanRPC abre el span de servidor del RPC, continuando la traza del cliente si la metadata trae
+// traceparent; es el equivalente gRPC de TrazasMiddleware
+func iniciarSpanRPC(ctx context.Context, metodoCompleto string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, portadorMetadata(md))
+	servicio, metodo, _ := strings.Cut(strings.TrimPrefix(metodoCompleto, "/"), "/")
+	return trazas.Iniciar(ctx, strings.TrimPrefix(metodoCompleto, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCService(servicio),
+			semconv.RPCMethod(metodo),
+			semconv.ClientAddress(ipCliente(ctx)),
+		),
+	)
+}
+
+// terminarSpanRPC registra el código de status y cierra el span. Como en registrarRPC, solo los códigos
+// que indican una falla del servidor marcan el span como error; los rechazos del cliente no.
+func terminarSpanRPC(span trace.Span, err *error) {
+	codigo := status.Code(*err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(codigo)))
+	switch codigo {
+	case codes.Internal, codes.Unavailable, codes.Unknown, codes.DataLoss:
+		trazas.RegistrarError(span, *err)
+	}
+	span.End()
+}
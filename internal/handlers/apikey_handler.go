@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/pkg/validation"
+)
+
+// APIKeyHandler maneja la administración de API keys
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyHandler crea una nueva instancia de APIKeyHandler
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CrearAPIKey maneja POST /admin/api-keys
+// La clave en texto plano solo se incluye en esta respuesta
+func (h *APIKeyHandler) CrearAPIKey(c *gin.Context) {
+	var req models.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(err))
+		return
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(err))
+		return
+	}
+
+	clave, apiKey, err := h.apiKeyService.CrearAPIKey(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c.Request.Context(), "apikey.creada"),
+		"data": models.APIKeyCreadaResponse{
+			Clave:  clave,
+			APIKey: apiKey,
+		},
+	})
+}
+
+// ListarAPIKeys maneja GET /admin/api-keys
+func (h *APIKeyHandler) ListarAPIKeys(c *gin.Context) {
+	apiKeys, err := h.apiKeyService.ListarAPIKeys(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total": len(apiKeys),
+		"data":  apiKeys,
+	})
+}
+
+// RevocarAPIKey maneja DELETE /admin/api-keys/:id
+func (h *APIKeyHandler) RevocarAPIKey(c *gin.Context) {
+	apiKey, err := h.apiKeyService.RevocarAPIKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.Request.Context(), "apikey.revocada"),
+		"data":    apiKey,
+	})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/pkg/validation"
+)
+
+// CertificadoHandler maneja el registro de identidades de certificados de cliente
+type CertificadoHandler struct {
+	certificadoService *services.CertificadoService
+}
+
+// NewCertificadoHandler crea una nueva instancia de CertificadoHandler
+func NewCertificadoHandler(certificadoService *services.CertificadoService) *CertificadoHandler {
+	return &CertificadoHandler{
+		certificadoService: certificadoService,
+	}
+}
+
+// RegistrarIdentidad maneja POST /admin/certificados
+func (h *CertificadoHandler) RegistrarIdentidad(c *gin.Context) {
+	var req models.IdentidadCertificadoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(err))
+		return
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(err))
+		return
+	}
+
+	identidad, err := h.certificadoService.RegistrarIdentidad(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c.Request.Context(), "certificado.registrada"),
+		"data":    identidad,
+	})
+}
+
+// ListarIdentidades maneja GET /admin/certificados
+func (h *CertificadoHandler) ListarIdentidades(c *gin.Context) {
+	identidades, err := h.certificadoService.ListarIdentidades(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total": len(identidades),
+		"data":  identidades,
+	})
+}
+
+// RevocarIdentidad maneja DELETE /admin/certificados?identidad=...
+// La identidad va en la query porque los SAN URI contienen "/"
+func (h *CertificadoHandler) RevocarIdentidad(c *gin.Context) {
+	valor := c.Query("identidad")
+	if valor == "" {
+		c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("certificado.identidad-requerida")))
+		return
+	}
+
+	identidad, err := h.certificadoService.RevocarIdentidad(c.Request.Context(), valor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.Request.Context(), "certificado.revocada"),
+		"data":    identidad,
+	})
+}
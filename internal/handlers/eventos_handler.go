@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/eventos"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// Mensajes de control del stream; las notificaciones usan su propio tipo (registrada, anclada, ...)
+const (
+	StreamHistorialIncompleto = "historial-incompleto" // El Last-Event-ID ya no está en el historial: resincronizar por REST
+	StreamDesconectado        = "desconectado"         // El servidor cierra el stream (cliente lento o apagado)
+)
+
+// Errores al abrir una suscripción; el bus no conoce los errores de dominio de services
+var (
+	errUltimoIDInvalido        = services.NuevoErrorDominio(services.TipoValidacion, "ultimo-id-invalido", "Last-Event-ID inválido")
+	errSuscripcionNoDisponible = services.NuevoErrorDominio(services.TipoNoDisponible, "suscripcion-no-disponible", "no se pudo abrir la suscripción")
+)
+
+// mensajeControl es el cuerpo de los mensajes de control del stream
+type mensajeControl struct {
+	Tipo    string `json:"tipo"`
+	Mensaje string `json:"mensaje"`
+}
+
+// EventosHandler expone el bus de eventos por Server-Sent Events y WebSocket
+type EventosHandler struct {
+	bus      *eventos.Bus
+	latido   time.Duration
+	upgrader websocket.Upgrader
+}
+
+// NewEventosHandler crea una nueva instancia de EventosHandler; latido es el intervalo de keep-alive
+func NewEventosHandler(bus *eventos.Bus, latido time.Duration) *EventosHandler {
+	if latido <= 0 {
+		latido = 15 * time.Second
+	}
+	return &EventosHandler{
+		bus:    bus,
+		latido: latido,
+		// CheckOrigin por defecto: los navegadores solo pueden conectarse desde el mismo origen
+		upgrader: websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096},
+	}
+}
+
+// suscribir lee el filtro y el último ID de la petición y registra la suscripción; responde el error si falla
+func (h *EventosHandler) suscribir(c *gin.Context) *eventos.Suscripcion {
+	filtro := eventos.Filtro{
+		Transacciones: c.QueryArray("transaccion"),
+		Productos:     c.QueryArray("producto"),
+		Actores:       c.QueryArray("actor"),
+	}
+	ultimoID := c.GetHeader("Last-Event-ID")
+	if ultimoID == "" {
+		ultimoID = c.Query("ultimoId")
+	}
+
+	suscripcion, err := h.bus.Suscribir(filtro, ultimoID)
+	switch {
+	case err == nil:
+		return suscripcion
+	case errors.Is(err, eventos.ErrUltimoIDInvalido):
+		c.Error(errUltimoIDInvalido.Con(err))
+	default:
+		c.Error(errSuscripcionNoDisponible.Con(err))
+	}
+	return nil
+}
+
+// motivoCierre describe por qué el bus cerró la suscripción
+func motivoCierre(ctx context.Context, suscripcion *eventos.Suscripcion) string {
+	if err := suscripcion.Err(); err != nil {
+		return i18n.TraducirError(err, i18n.IdiomaDe(ctx))
+	}
+	return i18n.T(ctx, "eventos.suscripcion-cerrada")
+}
+
+// StreamSSE maneja GET /eventos
+// Envía las notificaciones como Server-Sent Events: el campo id permite que EventSource reanude con Last-Event-ID
+func (h *EventosHandler) StreamSSE(c *gin.Context) {
+	suscripcion := h.suscribir(c)
+	if suscripcion == nil {
+		return
+	}
+	defer suscripcion.Cancelar()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Evita que un proxy nginx acumule el stream
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: 3000\n\n")
+
+	if suscripcion.Incompleta {
+		escribirSSE(c, "", StreamHistorialIncompleto, mensajeControl{
+			Tipo:    StreamHistorialIncompleto,
+			Mensaje: i18n.T(c.Request.Context(), "eventos.historial-incompleto"),
+		})
+	}
+	for _, notificacion := range suscripcion.Pendientes {
+		escribirSSE(c, notificacion.ID, notificacion.Tipo, notificacion)
+	}
+	c.Writer.Flush()
+
+	latido := time.NewTicker(h.latido)
+	defer latido.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case notificacion, ok := <-suscripcion.Notificaciones():
+			if !ok {
+				escribirSSE(c, "", StreamDesconectado, mensajeControl{Tipo: StreamDesconectado, Mensaje: motivoCierre(c.Request.Context(), suscripcion)})
+				c.Writer.Flush()
+				return
+			}
+			escribirSSE(c, notificacion.ID, notificacion.Tipo, notificacion)
+		case <-latido.C:
+			fmt.Fprintf(c.Writer, ": latido\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// escribirSSE escribe un evento SSE; sin id, EventSource conserva el último ID recibido
+func escribirSSE(c *gin.Context, id, evento string, datos any) {
+	contenido, err := json.Marshal(datos)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "eventos: error serializando", "evento", evento, "error", err)
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", evento, contenido)
+}
+
+// tiempoEscrituraWS limita cada escritura al socket para no retener la goroutine con un cliente bloqueado
+const tiempoEscrituraWS = 10 * time.Second
+
+// StreamWebSocket maneja GET /eventos/ws
+// Cada mensaje es un JSON con el campo tipo; el cliente reanuda enviando ultimoId en la URL de reconexión
+func (h *EventosHandler) StreamWebSocket(c *gin.Context) {
+	suscripcion := h.suscribir(c)
+	if suscripcion == nil {
+		return
+	}
+	defer suscripcion.Cancelar()
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade ya respondió el error al cliente
+		slog.WarnContext(c.Request.Context(), "eventos: no se pudo abrir el WebSocket", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	// El cliente no envía datos: solo se leen los pong y el cierre
+	terminado := make(chan struct{})
+	go func() {
+		defer close(terminado)
+		conn.SetReadLimit(512)
+		_ = conn.SetReadDeadline(time.Now().Add(2 * h.latido))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * h.latido))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	escribir := func(mensaje any) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(tiempoEscrituraWS))
+		return conn.WriteJSON(mensaje) == nil
+	}
+	cerrar := func(codigo int, motivo string) {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(codigo, motivo), time.Now().Add(tiempoEscrituraWS))
+	}
+
+	if suscripcion.Incompleta && !escribir(mensajeControl{
+		Tipo:    StreamHistorialIncompleto,
+		Mensaje: i18n.T(c.Request.Context(), "eventos.historial-incompleto"),
+	}) {
+		return
+	}
+	for _, notificacion := range suscripcion.Pendientes {
+		if !escribir(notificacion) {
+			return
+		}
+	}
+
+	latido := time.NewTicker(h.latido)
+	defer latido.Stop()
+	for {
+		select {
+		case <-terminado:
+			return
+		case notificacion, ok := <-suscripcion.Notificaciones():
+			if !ok {
+				codigo := websocket.CloseGoingAway
+				if errors.Is(suscripcion.Err(), eventos.ErrSuscriptorLento) {
+					codigo = websocket.CloseTryAgainLater
+				}
+				cerrar(codigo, motivoCierre(c.Request.Context(), suscripcion))
+				return
+			}
+			if !escribir(notificacion) {
+				return
+			}
+		case <-latido.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(tiempoEscrituraWS)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// FondosHandler expone el saldo de la cuenta firmante y su proyección de gasto
+type FondosHandler struct {
+	monitor *services.MonitorFondos
+}
+
+// NewFondosHandler crea una nueva instancia de FondosHandler; monitor es nil en modo sin blockchain
+func NewFondosHandler(monitor *services.MonitorFondos) *FondosHandler {
+	return &FondosHandler{
+		monitor: monitor,
+	}
+}
+
+// ObtenerFondos maneja GET /admin/fondos
+func (h *FondosHandler) ObtenerFondos(c *gin.Context) {
+	if h == nil || h.monitor == nil {
+		c.Error(services.ErrorDependencia(services.DependenciaBlockchain, errors.New("modo sin blockchain: no hay cuenta firmante")))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.monitor.Estado(),
+	})
+}
@@ -2,26 +2,81 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/salud"
 	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
 )
 
+// PreparacionConfig define los tiempos, la clasificación y los umbrales de las verificaciones de GET /ready
+type PreparacionConfig struct {
+	salud.Config
+	ChainIDEsperado          *big.Int // nil = el chain ID obtenido al conectar
+	BalanceMinimo            *big.Int // Saldo mínimo de la cuenta firmante, en wei
+	MaximoAnclajesPendientes int64    // 0 = sin límite
+}
+
 // HealthHandler maneja las peticiones de health check
 type HealthHandler struct {
-	ipfsService       *services.IPFSService
-	blockchainService *services.BlockchainService
+	verificador *salud.Verificador
 }
 
-// NewHealthHandler crea una nueva instancia de HealthHandler
-func NewHealthHandler(ipfs *services.IPFSService, blockchain *services.BlockchainService) *HealthHandler {
-	return &HealthHandler{
-		ipfsService:       ipfs,
-		blockchainService: blockchain,
+// NewHealthHandler crea una nueva instancia de HealthHandler. blockchain puede ser nil (modo sin
+// blockchain): sus verificaciones se reportan como deshabilitadas.
+func NewHealthHandler(ipfs *services.IPFSService, dynamo *services.DynamoDBService, blockchain *services.BlockchainService, transacciones *services.TransaccionService, cfg PreparacionConfig) *HealthHandler {
+	verificaciones := []salud.Verificacion{
+		{Nombre: salud.VerificacionDynamoDB, Verificar: dynamo.VerificarTablas},
+		{Nombre: salud.VerificacionIPFS, Verificar: func(ctx context.Context) error {
+			// Con el nodo principal caído y una réplica sana las lecturas siguen funcionando
+			err := ipfs.VerificarConexion(ctx)
+			if errors.Is(err, services.ErrIPFSDegradado) {
+				return fmt.Errorf("%w: %w", salud.ErrDegradada, err)
+			}
+			return err
+		}},
+		{Nombre: salud.VerificacionBlockchain, Verificar: func(ctx context.Context) error {
+			if blockchain == nil {
+				return fmt.Errorf("modo sin blockchain: %w", salud.ErrDeshabilitada)
+			}
+			return blockchain.VerificarRed(ctx, cfg.ChainIDEsperado)
+		}},
+		{Nombre: salud.VerificacionContrato, Verificar: func(ctx context.Context) error {
+			if blockchain == nil || !blockchain.TieneContrato() {
+				return fmt.Errorf("sin CONTRACT_ADDRESS: %w", salud.ErrDeshabilitada)
+			}
+			return blockchain.VerificarContrato(ctx)
+		}},
+		{Nombre: salud.VerificacionBalance, Verificar: func(ctx context.Context) error {
+			if blockchain == nil {
+				return fmt.Errorf("modo sin blockchain: %w", salud.ErrDeshabilitada)
+			}
+			balance, err := blockchain.ObtenerBalance(ctx)
+			if err != nil {
+				return err
+			}
+			if cfg.BalanceMinimo != nil && balance.Cmp(cfg.BalanceMinimo) < 0 {
+				return fmt.Errorf("saldo de %s ETH por debajo del mínimo de %s ETH", utils.FormatearEther(balance), utils.FormatearEther(cfg.BalanceMinimo))
+			}
+			return nil
+		}},
+		{Nombre: salud.VerificacionAnclajes, Verificar: func(context.Context) error {
+			if cfg.MaximoAnclajesPendientes <= 0 {
+				return fmt.Errorf("sin máximo de anclajes pendientes: %w", salud.ErrDeshabilitada)
+			}
+			if pendientes := transacciones.AnclajesPendientes(); pendientes > cfg.MaximoAnclajesPendientes {
+				return fmt.Errorf("%d anclajes pendientes; el máximo es %d", pendientes, cfg.MaximoAnclajesPendientes)
+			}
+			return nil
+		}},
 	}
+	return &HealthHandler{verificador: salud.NewVerificador(cfg.Config, verificaciones...)}
 }
 
 // HealthCheck maneja GET /health
@@ -33,41 +88,27 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 	})
 }
 
-// ReadinessCheck maneja GET /ready
+// ReadinessCheck maneja GET /ready. Responde 503 solo si falla una verificación crítica; si falla
+// una degradada responde 200 con status "degraded".
 func (h *HealthHandler) ReadinessCheck(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
-
-	checks := make(map[string]string)
-	allHealthy := true
-
-	// Verificar IPFS
-	if err := h.ipfsService.VerificarConexion(ctx); err != nil {
-		checks["ipfs"] = "unhealthy: " + err.Error()
-		allHealthy = false
-	} else {
-		checks["ipfs"] = "healthy"
-	}
-
-	// Verificar Blockchain
-	if err := h.blockchainService.VerificarConexion(ctx); err != nil {
-		checks["blockchain"] = "unhealthy: " + err.Error()
-		allHealthy = false
-	} else {
-		checks["blockchain"] = "healthy"
-	}
-
-	// DynamoDB se verifica automáticamente en cada llamada
-	checks["dynamodb"] = "healthy"
+	informe := h.verificador.Verificar(c.Request.Context())
 
 	status := http.StatusOK
-	if !allHealthy {
+	if !informe.Listo {
 		status = http.StatusServiceUnavailable
 	}
 
 	c.JSON(status, gin.H{
-		"status":    map[bool]string{true: "ready", false: "not_ready"}[allHealthy],
-		"checks":    checks,
-		"timestamp": time.Now().Format(time.RFC3339),
+		"status":        informe.Estado(),
+		"checks":        informe.Checks(),
+		"detalles":      informe.Resultados,
+		"verificado_en": informe.VerificadoEn.Format(time.RFC3339),
+		"timestamp":     time.Now().Format(time.RFC3339),
 	})
 }
+
+// VerificarDependencias evalúa las verificaciones de GET /ready; también la usa el health service de gRPC
+func (h *HealthHandler) VerificarDependencias(ctx context.Context) (map[string]string, bool) {
+	informe := h.verificador.Verificar(ctx)
+	return informe.Checks(), informe.Listo
+}
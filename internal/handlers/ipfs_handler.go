@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
 	"github.com/edinfamous/blockchain-medisupply/internal/services"
 )
 
@@ -47,11 +48,8 @@ func (h *IPFSHandler) ListarArchivos(c *gin.Context) {
 	defer cancel()
 
 	// Verificar conexión
-	if err := h.ipfsService.VerificarConexion(ctx); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":   "IPFS no está disponible",
-			"details": err.Error(),
-		})
+	if err := h.ipfsService.VerificarLectura(ctx); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -61,14 +59,14 @@ func (h *IPFSHandler) ListarArchivos(c *gin.Context) {
 	
 	// Por ahora, retornamos información sobre cómo acceder
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Usa IPFS Companion o la API directa para ver archivos",
+		"message": i18n.T(c.Request.Context(), "ipfs.como-ver-archivos"),
 		"ipfs_api": "http://localhost:5001/api/v0/pin/ls",
 		"ipfs_webui": "http://localhost:5001/webui",
 		"gateway": "http://localhost:8081/ipfs/",
 		"instructions": gin.H{
-			"method1": "Instalar IPFS Companion extension",
-			"method2": "Usar curl: curl -X POST http://localhost:5001/api/v0/pin/ls",
-			"method3": "Usar script: ./scripts/verify_ipfs.sh",
+			"method1": i18n.T(c.Request.Context(), "ipfs.instalar-companion"),
+			"method2": i18n.T(c.Request.Context(), "ipfs.usar-curl", "curl -X POST http://localhost:5001/api/v0/pin/ls"),
+			"method3": i18n.T(c.Request.Context(), "ipfs.usar-script", "./scripts/verify_ipfs.sh"),
 		},
 	})
 }
@@ -77,9 +75,7 @@ func (h *IPFSHandler) ListarArchivos(c *gin.Context) {
 func (h *IPFSHandler) ObtenerArchivo(c *gin.Context) {
 	cid := c.Param("cid")
 	if cid == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "CID es requerido",
-		})
+		c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("ipfs.cid-requerido")))
 		return
 	}
 
@@ -87,21 +83,15 @@ func (h *IPFSHandler) ObtenerArchivo(c *gin.Context) {
 	defer cancel()
 
 	// Verificar conexión
-	if err := h.ipfsService.VerificarConexion(ctx); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":   "IPFS no está disponible",
-			"details": err.Error(),
-		})
+	if err := h.ipfsService.VerificarLectura(ctx); err != nil {
+		c.Error(err)
 		return
 	}
 
 	// Intentar recuperar los datos
 	data, err := h.ipfsService.RecuperarJSON(ctx, cid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "No se pudo recuperar el archivo",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -119,23 +109,20 @@ func (h *IPFSHandler) ObtenerEstadisticas(c *gin.Context) {
 	defer cancel()
 
 	// Verificar conexión
-	if err := h.ipfsService.VerificarConexion(ctx); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":   "IPFS no está disponible",
-			"details": err.Error(),
-		})
+	if err := h.ipfsService.VerificarLectura(ctx); err != nil {
+		c.Error(err)
 		return
 	}
 
 	// Retornar información de acceso
 	c.JSON(http.StatusOK, gin.H{
-		"status": "IPFS está disponible",
+		"status": i18n.T(c.Request.Context(), "ipfs.disponible"),
 		"endpoints": gin.H{
 			"api":     "http://localhost:5001/api/v0",
 			"gateway": "http://localhost:8081/ipfs/",
 			"webui":   "http://localhost:5001/webui",
 		},
-		"instructions": "Para ver archivos, usa IPFS Companion o consulta la API directamente",
+		"instructions": i18n.T(c.Request.Context(), "ipfs.instrucciones"),
 	})
 }
 
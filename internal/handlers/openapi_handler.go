@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/api"
+)
+
+// ServirOpenAPI maneja GET /api/v1/openapi.json
+func ServirOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", api.OpenAPI)
+}
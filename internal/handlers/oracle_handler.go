@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
 	"github.com/edinfamous/blockchain-medisupply/internal/services"
 )
 
@@ -13,6 +14,9 @@ type OracleHandler struct {
 	oracleService *services.OracleService
 }
 
+// errIDProductoRequerido se registra si la ruta llega sin ID de producto
+var errIDProductoRequerido = services.ErrSolicitudInvalida.Con(i18n.NuevoError("oracle.id-producto-requerido"))
+
 // NewOracleHandler crea una nueva instancia de OracleHandler
 func NewOracleHandler(oracleService *services.OracleService) *OracleHandler {
 	return &OracleHandler{
@@ -26,23 +30,18 @@ func (h *OracleHandler) ObtenerDatosVerificados(c *gin.Context) {
 	idProducto := c.Param("id")
 
 	if idProducto == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de producto requerido",
-		})
+		c.Error(errIDProductoRequerido)
 		return
 	}
 
 	datos, err := h.oracleService.ObtenerDatosVerificados(c.Request.Context(), idProducto)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "No se pudieron obtener datos verificados",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Datos verificados del Oracle",
+		"message": i18n.T(c.Request.Context(), "oracle.datos-verificados"),
 		"data":    datos,
 	})
 }
@@ -52,23 +51,18 @@ func (h *OracleHandler) ObtenerHistorialVerificado(c *gin.Context) {
 	idProducto := c.Param("id")
 
 	if idProducto == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de producto requerido",
-		})
+		c.Error(errIDProductoRequerido)
 		return
 	}
 
 	historial, err := h.oracleService.ObtenerHistorialVerificado(c.Request.Context(), idProducto)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "No se pudo obtener historial",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Historial verificado",
+		"message": i18n.T(c.Request.Context(), "oracle.historial-verificado"),
 		"data":    historial,
 	})
 }
@@ -78,18 +72,13 @@ func (h *OracleHandler) ValidarCadenaSupply(c *gin.Context) {
 	idProducto := c.Param("id")
 
 	if idProducto == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de producto requerido",
-		})
+		c.Error(errIDProductoRequerido)
 		return
 	}
 
 	valido, errores, err := h.oracleService.ValidarCadenaSupply(c.Request.Context(), idProducto)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error validando cadena de suministro",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
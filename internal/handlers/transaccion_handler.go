@@ -2,13 +2,15 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
 	"github.com/edinfamous/blockchain-medisupply/internal/models"
 	"github.com/edinfamous/blockchain-medisupply/internal/services"
 )
@@ -25,59 +27,241 @@ func NewTransaccionHandler(transaccionService *services.TransaccionService) *Tra
 	}
 }
 
+// errIDTransaccionRequerido se registra si la ruta llega sin ID de transacción
+var errIDTransaccionRequerido = services.ErrSolicitudInvalida.Con(i18n.NuevoError("transaccion.id-requerido"))
+
 // RegistrarTransaccion maneja POST /transaccion/registrar
+// Los reintentos con la misma cabecera Idempotency-Key los resuelve middleware.IdempotenciaMiddleware
 func (h *TransaccionHandler) RegistrarTransaccion(c *gin.Context) {
-	fmt.Println("🔵 Handler: RegistrarTransaccion - INICIADO")
-	fmt.Printf("🔵 Handler: Método HTTP: %s\n", c.Request.Method)
-	fmt.Printf("🔵 Handler: Path: %s\n", c.Request.URL.Path)
-	
 	var req models.TransaccionRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		fmt.Printf("🔴 Handler: Error binding JSON: %v\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Datos inválidos",
-			"details": err.Error(),
-		})
+		c.Error(services.ErrSolicitudInvalida.Con(err))
 		return
 	}
 
-	fmt.Printf("🔵 Handler: JSON binded correctamente. TipoEvento: %s, IDProducto: %s\n", req.TipoEvento, req.IDProducto)
-	fmt.Println("🔵 Handler: Llamando a transaccionService.RegistrarTransaccion...")
-	
 	// Crear contexto con timeout más largo (90 segundos para IPFS + DynamoDB)
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
 	defer cancel()
 	
 	transaccion, err := h.transaccionService.RegistrarTransaccion(ctx, &req)
 	if err != nil {
-		fmt.Printf("🔴 Handler: Error del servicio: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error registrando transacción",
-			"details": err.Error(),
-		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c.Request.Context(), "transaccion.registrada"),
+		"data":    nuevaTransaccionResponse(transaccion),
+	})
+}
+
+// RegistrarTransaccionConAdjuntos maneja POST /transaccion/registrar-con-adjuntos
+// Recibe multipart/form-data con los campos del evento (tipoEvento, idProducto, datosEvento,
+// actorEmisor) seguidos de uno o más archivos en el campo "adjuntos". Los campos deben
+// enviarse antes que los archivos: cada archivo se transmite a IPFS a medida que se lee.
+func (h *TransaccionHandler) RegistrarTransaccionConAdjuntos(c *gin.Context) {
+	limites := h.transaccionService.GetLimitesAdjuntos()
+
+	// Acotar el cuerpo completo: archivos más un margen para los campos del formulario
+	maximoCuerpo := limites.TamanoMaximo*int64(limites.MaximoArchivos) + maximoCampoFormulario*4
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maximoCuerpo)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("transaccion.se-esperaba-multipart", err)))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	var req models.TransaccionRequest
+	var adjuntos []models.Adjunto
+	solicitudValidada := false
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("transaccion.error-formulario", err)))
+			return
+		}
+
+		// Campos del evento
+		if part.FileName() == "" {
+			valor, err := io.ReadAll(io.LimitReader(part, maximoCampoFormulario))
+			part.Close()
+			if err != nil {
+				c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("transaccion.error-campo-formulario", err)))
+				return
+			}
+			asignarCampoSolicitud(&req, part.FormName(), string(valor))
+			continue
+		}
+
+		if part.FormName() != "adjuntos" {
+			part.Close()
+			continue
+		}
+
+		// Validar el evento antes de subir el primer archivo
+		if !solicitudValidada {
+			if err := h.transaccionService.ValidarSolicitudConAdjuntos(c.Request.Context(), &req); err != nil {
+				part.Close()
+				c.Error(err)
+				return
+			}
+			solicitudValidada = true
+		}
+
+		if len(adjuntos) >= limites.MaximoArchivos {
+			part.Close()
+			c.Error(services.ErrDemasiadosAdjuntos.Con(i18n.NuevoError("adjunto.maximo-archivos", limites.MaximoArchivos)))
+			return
+		}
+
+		adjunto, err := h.transaccionService.AlmacenarAdjunto(ctx, part.FileName(), part.Header.Get("Content-Type"), part)
+		part.Close()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		adjuntos = append(adjuntos, *adjunto)
+	}
+
+	if len(adjuntos) == 0 {
+		c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("transaccion.sin-adjuntos")))
+		return
+	}
+
+	transaccion, err := h.transaccionService.RegistrarTransaccionConAdjuntos(ctx, &req, adjuntos)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c.Request.Context(), "transaccion.registrada"),
+		"data":    nuevaTransaccionResponse(transaccion),
+	})
+}
+
+// RegistrarLote maneja POST /transaccion/lote
+// Acepta un arreglo JSON de TransaccionRequest o un flujo NDJSON (Content-Type: application/x-ndjson,
+// un evento por línea). Responde 201 si se registraron todos y 207 con el resultado de cada elemento
+// si alguno falló; si alguno es inválido responde 400 sin registrar ninguno.
+func (h *TransaccionHandler) RegistrarLote(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaximoCuerpoLote())
+
+	reqs, err := leerLote(c.Request.Body, c.ContentType(), h.transaccionService.GetLimitesLote().MaximoElementos)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	respuesta, err := h.transaccionService.RegistrarLote(ctx, reqs)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	fmt.Printf("🟢 Handler: Transacción registrada exitosamente. ID: %s\n", transaccion.IDTransaction)
 
-	// Convertir a response
-	response := &models.TransaccionResponse{
+	status := http.StatusCreated
+	if respuesta.Fallidas > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"message": i18n.T(c.Request.Context(), "lote.procesado", respuesta.Registradas, respuesta.Fallidas),
+		"data":    respuesta,
+	})
+}
+
+// maximoElementoLote acota el tamaño medio de cada evento de un lote
+const maximoElementoLote = 64 << 10
+
+// MaximoCuerpoRegistro es el tamaño máximo del cuerpo de POST /transaccion/registrar: un evento
+// individual admite lo mismo que un elemento de lote
+func (h *TransaccionHandler) MaximoCuerpoRegistro() int64 {
+	return maximoElementoLote
+}
+
+// MaximoCuerpoLote es el tamaño máximo del cuerpo de POST /transaccion/lote
+func (h *TransaccionHandler) MaximoCuerpoLote() int64 {
+	return int64(h.transaccionService.GetLimitesLote().MaximoElementos) * maximoElementoLote
+}
+
+// leerLote decodifica un arreglo JSON o un flujo NDJSON de TransaccionRequest
+func leerLote(cuerpo io.Reader, tipoContenido string, maximo int) ([]*models.TransaccionRequest, error) {
+	decoder := json.NewDecoder(cuerpo)
+	var reqs []*models.TransaccionRequest
+
+	if tipoContenido == "application/x-ndjson" || tipoContenido == "application/jsonl" {
+		for {
+			var req models.TransaccionRequest
+			err := decoder.Decode(&req)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, services.ErrSolicitudInvalida.Con(i18n.NuevoError("lote.evento-invalido", len(reqs), err))
+			}
+			if len(reqs) == maximo {
+				return nil, services.ErrLoteDemasiadoGrande.Con(i18n.NuevoError("lote.maximo", maximo))
+			}
+			reqs = append(reqs, &req)
+		}
+		return reqs, nil
+	}
+
+	if err := decoder.Decode(&reqs); err != nil {
+		return nil, services.ErrSolicitudInvalida.Con(i18n.NuevoError("lote.se-esperaba-arreglo", err))
+	}
+	if len(reqs) > maximo {
+		return nil, services.ErrLoteDemasiadoGrande.Con(i18n.NuevoError("lote.maximo", maximo))
+	}
+	return reqs, nil
+}
+
+// maximoCampoFormulario limita el tamaño de cada campo de texto del formulario multipart
+const maximoCampoFormulario = 1 << 20
+
+// asignarCampoSolicitud copia un campo del formulario multipart a la solicitud
+func asignarCampoSolicitud(req *models.TransaccionRequest, campo, valor string) {
+	switch campo {
+	case "tipoEvento":
+		req.TipoEvento = valor
+	case "idProducto":
+		req.IDProducto = valor
+	case "datosEvento":
+		req.DatosEvento = valor
+	case "actorEmisor":
+		req.ActorEmisor = valor
+	}
+}
+
+// nuevaTransaccionResponse convierte una transacción registrada en su respuesta pública
+func nuevaTransaccionResponse(transaccion *models.Transaccion) *models.TransaccionResponse {
+	return &models.TransaccionResponse{
 		IDTransaction:       transaccion.IDTransaction,
 		TipoEvento:          transaccion.TipoEvento,
 		IDProducto:          transaccion.IDProducto,
 		FechaEvento:         transaccion.FechaEvento,
 		HashEvento:          transaccion.HashEvento,
+		HashVersion:         transaccion.HashVersion,
+		HashEventoAnterior:  transaccion.HashEventoAnterior,
+		Secuencia:           transaccion.Secuencia,
 		DirectionBlockchain: transaccion.DirectionBlockchain,
 		IPFSCid:             transaccion.IPFSCid,
 		ActorEmisor:         transaccion.ActorEmisor,
+		Adjuntos:            transaccion.Adjuntos,
 		Estado:              transaccion.Estado,
 		CreatedAt:           transaccion.CreatedAt,
 	}
-
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Transacción registrada exitosamente",
-		"data":    response,
-	})
 }
 
 // ObtenerTransaccion maneja GET /transaccion/:id
@@ -85,18 +269,13 @@ func (h *TransaccionHandler) ObtenerTransaccion(c *gin.Context) {
 	id := c.Param("id")
 
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de transacción requerido",
-		})
+		c.Error(errIDTransaccionRequerido)
 		return
 	}
 
 	transaccion, err := h.transaccionService.ObtenerTransaccion(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Transacción no encontrada",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -110,18 +289,13 @@ func (h *TransaccionHandler) VerificarTransaccion(c *gin.Context) {
 	id := c.Param("id")
 
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de transacción requerido",
-		})
+		c.Error(errIDTransaccionRequerido)
 		return
 	}
 
 	verificacion, err := h.transaccionService.VerificarIntegridad(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error verificando transacción",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -140,10 +314,7 @@ func (h *TransaccionHandler) ListarTransacciones(c *gin.Context) {
 
 	transacciones, err := h.transaccionService.ListarTransacciones(c.Request.Context(), int32(limit))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error listando transacciones",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -158,18 +329,13 @@ func (h *TransaccionHandler) ObtenerTransaccionesPorProducto(c *gin.Context) {
 	idProducto := c.Param("id")
 
 	if idProducto == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de producto requerido",
-		})
+		c.Error(errIDProductoRequerido)
 		return
 	}
 
 	transacciones, err := h.transaccionService.ObtenerTransaccionesPorProducto(c.Request.Context(), idProducto)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error obteniendo transacciones del producto",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -186,20 +352,13 @@ func (h *TransaccionHandler) ObtenerEstadoBlockchain(c *gin.Context) {
 	id := c.Param("id")
 
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID de transacción requerido",
-		})
+		c.Error(errIDTransaccionRequerido)
 		return
 	}
 
-	fmt.Printf("🔵 Handler: Consultando estado de blockchain para transacción %s\n", id)
-	
 	estado, err := h.transaccionService.ObtenerEstadoBlockchain(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Transacción no encontrada",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
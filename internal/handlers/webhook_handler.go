@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/pkg/validation"
+)
+
+// WebhookHandler maneja las suscripciones de webhooks y su log de entregas
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler crea una nueva instancia de WebhookHandler
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// alcanceWebhooks retorna el actor cuyos webhooks puede ver el llamador; vacío = todos (admin o autenticación deshabilitada)
+func alcanceWebhooks(c *gin.Context) string {
+	principal := middleware.PrincipalDesdeContexto(c)
+	if principal == nil || principal.TieneRol(middleware.RolAdmin) {
+		return ""
+	}
+	return principal.Actor
+}
+
+// leerWebhookRequest lee y valida el cuerpo; si es inválido registra el error y retorna false
+func leerWebhookRequest(c *gin.Context) (*models.WebhookRequest, bool) {
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(err))
+		return nil, false
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		c.Error(services.ErrSolicitudInvalida.Con(err))
+		return nil, false
+	}
+	return &req, true
+}
+
+// CrearWebhook maneja POST /webhooks
+// El secreto de firma solo se incluye en esta respuesta
+func (h *WebhookHandler) CrearWebhook(c *gin.Context) {
+	req, ok := leerWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	propietario := ""
+	if principal := middleware.PrincipalDesdeContexto(c); principal != nil {
+		propietario = principal.Actor
+	}
+	secreto, webhook, err := h.webhookService.CrearWebhook(c.Request.Context(), propietario, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c.Request.Context(), "webhook.creado"),
+		"data": models.WebhookCreadoResponse{
+			Secreto: secreto,
+			Webhook: webhook,
+		},
+	})
+}
+
+// ListarWebhooks maneja GET /webhooks
+func (h *WebhookHandler) ListarWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.ListarWebhooks(c.Request.Context(), alcanceWebhooks(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total": len(webhooks),
+		"data":  webhooks,
+	})
+}
+
+// ObtenerWebhook maneja GET /webhooks/:id
+func (h *WebhookHandler) ObtenerWebhook(c *gin.Context) {
+	webhook, err := h.webhookService.ObtenerWebhook(c.Request.Context(), alcanceWebhooks(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.Request.Context(), "webhook.encontrado"),
+		"data":    webhook,
+	})
+}
+
+// ActualizarWebhook maneja PUT /webhooks/:id
+func (h *WebhookHandler) ActualizarWebhook(c *gin.Context) {
+	req, ok := leerWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.ActualizarWebhook(c.Request.Context(), alcanceWebhooks(c), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.Request.Context(), "webhook.actualizado"),
+		"data":    webhook,
+	})
+}
+
+// EliminarWebhook maneja DELETE /webhooks/:id
+func (h *WebhookHandler) EliminarWebhook(c *gin.Context) {
+	if err := h.webhookService.EliminarWebhook(c.Request.Context(), alcanceWebhooks(c), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.Request.Context(), "webhook.eliminado"),
+	})
+}
+
+// ListarEntregas maneja GET /webhooks/:id/entregas
+// El query param estado filtra por pendiente, entregada o muerta
+func (h *WebhookHandler) ListarEntregas(c *gin.Context) {
+	estado := c.Query("estado")
+	switch estado {
+	case "", models.EntregaPendiente, models.EntregaEntregada, models.EntregaMuerta:
+	default:
+		c.Error(services.ErrSolicitudInvalida.Con(i18n.NuevoError("webhook.estado-invalido")))
+		return
+	}
+
+	entregas, err := h.webhookService.ListarEntregas(c.Request.Context(), alcanceWebhooks(c), c.Param("id"), estado)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total": len(entregas),
+		"data":  entregas,
+	})
+}
+
+// ReenviarEntrega maneja POST /webhooks/:id/entregas/:idEntrega/reenviar
+// Devuelve a la cola una entrega muerta con un nuevo ciclo de reintentos
+func (h *WebhookHandler) ReenviarEntrega(c *gin.Context) {
+	entrega, err := h.webhookService.ReenviarEntrega(c.Request.Context(), alcanceWebhooks(c), c.Param("id"), c.Param("idEntrega"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c.Request.Context(), "webhook.entrega-en-cola"),
+		"data":    entrega,
+	})
+}
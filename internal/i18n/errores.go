@@ -0,0 +1,54 @@
+package i18n
+
+import "errors"
+
+// Traducible lo implementan los errores que saben mostrarse en otro idioma
+type Traducible interface {
+	Traducir(idioma Idioma) string
+}
+
+// Error es un error cuyo mensaje proviene del catálogo. Error() lo formatea en el idioma por
+// defecto, así que puede reemplazar a errors.New/fmt.Errorf sin cambiar los logs.
+type Error struct {
+	Clave string
+	Args  []any
+}
+
+// NuevoError crea un error con el mensaje de la clave y sus argumentos
+func NuevoError(clave string, args ...any) *Error {
+	return &Error{Clave: clave, Args: args}
+}
+
+func (e *Error) Error() string {
+	return Traducir(IdiomaPorDefecto, e.Clave, e.Args...)
+}
+
+// Traducir formatea el mensaje en el idioma indicado
+func (e *Error) Traducir(idioma Idioma) string {
+	return Traducir(idioma, e.Clave, e.Args...)
+}
+
+// Unwrap expone los argumentos que son errores para que errors.Is/As sigan funcionando
+func (e *Error) Unwrap() []error {
+	var causas []error
+	for _, arg := range e.Args {
+		if err, ok := arg.(error); ok {
+			causas = append(causas, err)
+		}
+	}
+	return causas
+}
+
+// TraducirError muestra err en el idioma. En el idioma por defecto es err.Error(), con todo el contexto
+// que agregaron los fmt.Errorf intermedios; en otro idioma se traduce el primer error Traducible de la
+// cadena y, si no hay ninguno, se retorna err.Error() sin traducir.
+func TraducirError(err error, idioma Idioma) string {
+	if idioma == IdiomaPorDefecto {
+		return err.Error()
+	}
+	var traducible Traducible
+	if errors.As(err, &traducible) {
+		return traducible.Traducir(idioma)
+	}
+	return err.Error()
+}
@@ -0,0 +1,146 @@
+// Package i18n contiene el catálogo de mensajes de la API en español e inglés. Cada mensaje tiene una
+// clave estable (para los errores, su código); el idioma de la petición se elige con Accept-Language y
+// viaja en el contexto hasta los servicios. El español es el idioma por defecto.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Idioma identifica un idioma soportado por el catálogo
+type Idioma string
+
+const (
+	Espanol Idioma = "es"
+	Ingles  Idioma = "en"
+
+	// IdiomaPorDefecto se usa si la petición no indica un idioma soportado
+	IdiomaPorDefecto = Espanol
+)
+
+// Idiomas lista los idiomas soportados; el primero es el idioma por defecto
+var Idiomas = []Idioma{Espanol, Ingles}
+
+// mensajesEmbebidos contiene un catálogo por idioma (<idioma>.json) con las claves y sus formatos
+//
+//go:embed mensajes/*.json
+var mensajesEmbebidos embed.FS
+
+var (
+	catalogos  map[Idioma]map[string]string
+	comparador = language.NewMatcher([]language.Tag{language.Spanish, language.English})
+)
+
+func init() {
+	catalogos = make(map[Idioma]map[string]string, len(Idiomas))
+	for _, idioma := range Idiomas {
+		contenido, err := mensajesEmbebidos.ReadFile("mensajes/" + string(idioma) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("catálogo de mensajes '%s' no encontrado: %v", idioma, err))
+		}
+		var mensajes map[string]string
+		if err := json.Unmarshal(contenido, &mensajes); err != nil {
+			panic(fmt.Sprintf("catálogo de mensajes '%s' inválido: %v", idioma, err))
+		}
+		catalogos[idioma] = mensajes
+	}
+}
+
+// Buscar retorna el formato de la clave en el idioma, o en el idioma por defecto si no está traducida
+func Buscar(idioma Idioma, clave string) (string, bool) {
+	if formato, ok := catalogos[idioma][clave]; ok {
+		return formato, true
+	}
+	formato, ok := catalogos[IdiomaPorDefecto][clave]
+	return formato, ok
+}
+
+// Claves retorna las claves del catálogo de un idioma
+func Claves(idioma Idioma) []string {
+	claves := make([]string, 0, len(catalogos[idioma]))
+	for clave := range catalogos[idioma] {
+		claves = append(claves, clave)
+	}
+	return claves
+}
+
+// Traducir formatea el mensaje de la clave en el idioma. Los argumentos que son errores se traducen
+// con TraducirError. Una clave desconocida se retorna tal cual para que el fallo sea visible.
+func Traducir(idioma Idioma, clave string, args ...any) string {
+	formato, ok := Buscar(idioma, clave)
+	if !ok {
+		return clave
+	}
+	if len(args) == 0 {
+		return formato
+	}
+	traducidos := make([]any, len(args))
+	for i, arg := range args {
+		if err, ok := arg.(error); ok {
+			traducidos[i] = TraducirError(err, idioma)
+		} else {
+			traducidos[i] = arg
+		}
+	}
+	return fmt.Sprintf(formato, traducidos...)
+}
+
+// Titulo traduce la clave y pone en mayúscula la primera letra; los títulos de los problemas
+// reutilizan así los mensajes de los errores
+func Titulo(idioma Idioma, clave string) string {
+	return Capitalizar(Traducir(idioma, clave))
+}
+
+// Capitalizar pone en mayúscula la primera letra del texto
+func Capitalizar(texto string) string {
+	primera, tamano := utf8.DecodeRuneInString(texto)
+	if primera == utf8.RuneError {
+		return texto
+	}
+	return string(unicode.ToUpper(primera)) + texto[tamano:]
+}
+
+// T traduce la clave al idioma de la petición guardado en ctx
+func T(ctx context.Context, clave string, args ...any) string {
+	return Traducir(IdiomaDe(ctx), clave, args...)
+}
+
+type claveIdioma struct{}
+
+// ConIdioma retorna un contexto que lleva el idioma de la petición
+func ConIdioma(ctx context.Context, idioma Idioma) context.Context {
+	return context.WithValue(ctx, claveIdioma{}, idioma)
+}
+
+// IdiomaDe retorna el idioma guardado en ctx, o el idioma por defecto (procesos en segundo plano)
+func IdiomaDe(ctx context.Context) Idioma {
+	if idioma, ok := ctx.Value(claveIdioma{}).(Idioma); ok {
+		return idioma
+	}
+	return IdiomaPorDefecto
+}
+
+// DesdeAcceptLanguage elige el idioma soportado que mejor coincide con una cabecera Accept-Language
+// (respetando los pesos q). Sin cabecera, con una inválida o sin coincidencias se usa el idioma por defecto.
+func DesdeAcceptLanguage(cabecera string) Idioma {
+	if strings.TrimSpace(cabecera) == "" {
+		return IdiomaPorDefecto
+	}
+	preferidos, _, err := language.ParseAcceptLanguage(cabecera)
+	if err != nil || len(preferidos) == 0 {
+		return IdiomaPorDefecto
+	}
+	_, indice, confianza := comparador.Match(preferidos...)
+	if confianza == language.No {
+		return IdiomaPorDefecto
+	}
+	return Idiomas[indice]
+}
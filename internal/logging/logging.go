@@ -0,0 +1,194 @@
+// Package logging configura el logger estructurado (log/slog) de la aplicación. Cada registro que se
+// emite con un contexto incluye el request ID de la petición que lo originó, también en las goroutines
+// que continúan el trabajo después de responder (anclaje en blockchain).
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+)
+
+// Formatos de salida soportados
+const (
+	FormatoJSON  = "json"
+	FormatoTexto = "text"
+)
+
+// Atributos con los que se correlaciona cada registro con su petición y su traza
+const (
+	ClaveRequestID = "request_id"
+	ClaveTraceID   = "trace_id"
+	ClaveSpanID    = "span_id"
+)
+
+// longitudMaximaRequestID limita el request ID aceptado del cliente
+const longitudMaximaRequestID = 128
+
+// nivel es el nivel del logger por defecto; es un LevelVar para poder cambiarlo en caliente
+var nivel = new(slog.LevelVar)
+
+// ParsearNivel interpreta debug, info, warn o error (sin distinguir mayúsculas)
+func ParsearNivel(texto string) (slog.Level, error) {
+	var resultado slog.Level
+	switch strings.ToLower(strings.TrimSpace(texto)) {
+	case "debug":
+		resultado = slog.LevelDebug
+	case "info":
+		resultado = slog.LevelInfo
+	case "warn", "warning":
+		resultado = slog.LevelWarn
+	case "error":
+		resultado = slog.LevelError
+	default:
+		return 0, fmt.Errorf("nivel de log '%s' inválido; use debug, info, warn o error", texto)
+	}
+	return resultado, nil
+}
+
+// Nuevo crea un logger que escribe en salida con el formato indicado (json o text), agrega el
+// request ID del contexto a cada registro y enmascara los datos sensibles (ver redaccion)
+func Nuevo(salida io.Writer, formato string, nivelMinimo slog.Leveler) (*slog.Logger, error) {
+	opciones := &slog.HandlerOptions{Level: nivelMinimo}
+	var manejador slog.Handler
+	switch strings.ToLower(formato) {
+	case FormatoJSON:
+		manejador = slog.NewJSONHandler(salida, opciones)
+	case FormatoTexto:
+		manejador = slog.NewTextHandler(salida, opciones)
+	default:
+		return nil, fmt.Errorf("formato de log '%s' inválido; use json o text", formato)
+	}
+	return slog.New(manejadorContexto{manejadorRedaccion{manejador}}), nil
+}
+
+// Configurar reemplaza el logger por defecto de slog (y del paquete log) por uno con el nivel y el
+// formato de la configuración
+func Configurar(salida io.Writer, textoNivel, formato string) error {
+	nivelMinimo, err := ParsearNivel(textoNivel)
+	if err != nil {
+		return err
+	}
+	logger, err := Nuevo(salida, formato, nivel)
+	if err != nil {
+		return err
+	}
+	nivel.Set(nivelMinimo)
+	slog.SetDefault(logger)
+	return nil
+}
+
+// FijarNivel cambia en caliente el nivel del logger configurado con Configurar (recarga con SIGHUP)
+func FijarNivel(textoNivel string) error {
+	nivelMinimo, err := ParsearNivel(textoNivel)
+	if err != nil {
+		return err
+	}
+	nivel.Set(nivelMinimo)
+	return nil
+}
+
+// manejadorContexto agrega el request ID y el span activo del contexto a los registros del manejador envuelto
+type manejadorContexto struct {
+	slog.Handler
+}
+
+func (m manejadorContexto) Handle(ctx context.Context, registro slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		registro.AddAttrs(slog.String(ClaveRequestID, id))
+	}
+	if ctx != nil {
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			registro.AddAttrs(slog.String(ClaveTraceID, span.TraceID().String()), slog.String(ClaveSpanID, span.SpanID().String()))
+		}
+	}
+	return m.Handler.Handle(ctx, registro)
+}
+
+func (m manejadorContexto) WithAttrs(atributos []slog.Attr) slog.Handler {
+	return manejadorContexto{m.Handler.WithAttrs(atributos)}
+}
+
+func (m manejadorContexto) WithGroup(nombre string) slog.Handler {
+	return manejadorContexto{m.Handler.WithGroup(nombre)}
+}
+
+// manejadorRedaccion enmascara el mensaje y los atributos de cada registro con el Redactor del proceso
+// antes de escribirlo; por aquí pasan también los logs del paquete log estándar
+type manejadorRedaccion struct {
+	slog.Handler
+}
+
+func (m manejadorRedaccion) Handle(ctx context.Context, registro slog.Record) error {
+	redactor := redaccion.Actual()
+	enmascarado := slog.NewRecord(registro.Time, registro.Level, redactor.Texto(registro.Message), registro.PC)
+	registro.Attrs(func(atributo slog.Attr) bool {
+		enmascarado.AddAttrs(redactor.Atributo(atributo))
+		return true
+	})
+	return m.Handler.Handle(ctx, enmascarado)
+}
+
+func (m manejadorRedaccion) WithAttrs(atributos []slog.Attr) slog.Handler {
+	redactor := redaccion.Actual()
+	enmascarados := make([]slog.Attr, len(atributos))
+	for i, atributo := range atributos {
+		enmascarados[i] = redactor.Atributo(atributo)
+	}
+	return manejadorRedaccion{m.Handler.WithAttrs(enmascarados)}
+}
+
+func (m manejadorRedaccion) WithGroup(nombre string) slog.Handler {
+	return manejadorRedaccion{m.Handler.WithGroup(nombre)}
+}
+
+type claveRequestID struct{}
+
+// ConRequestID retorna un contexto que lleva el request ID
+func ConRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, claveRequestID{}, id)
+}
+
+// RequestID retorna el request ID guardado en ctx, o "" fuera de una petición
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(claveRequestID{}).(string)
+	return id
+}
+
+// NuevoRequestID genera un request ID para las peticiones que no traen uno
+func NuevoRequestID() string {
+	return uuid.New().String()
+}
+
+// RequestIDValido indica si un request ID enviado por el cliente se puede reutilizar: hasta 128
+// caracteres entre letras, dígitos y . _ : -, para que no inyecte texto arbitrario en los logs
+func RequestIDValido(id string) bool {
+	if id == "" || len(id) > longitudMaximaRequestID {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == ':', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Desacoplar retorna un contexto para continuar el trabajo de una petición en segundo plano: conserva
+// sus valores (request ID, idioma, principal) pero no se cancela cuando la petición termina
+func Desacoplar(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
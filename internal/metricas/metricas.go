@@ -0,0 +1,279 @@
+// Package metricas define las métricas Prometheus de la API y las expone en /metrics. Los nombres y las
+// etiquetas de cada métrica son estables: los paneles y alertas dependen de ellos, así que cambiar uno
+// equivale a romper la compatibilidad. Las etiquetas solo toman valores acotados (rutas con sus
+// parámetros, tipos de evento validados, nombres de operación), nunca IDs ni valores de la petición.
+package metricas
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// espacio es el prefijo de todas las métricas de la API
+const espacio = "medisupply"
+
+// Valores de la etiqueta resultado
+const (
+	ResultadoOK    = "ok"
+	ResultadoError = "error"
+)
+
+// Resultados de una verificación de integridad (etiqueta resultado de medisupply_verificaciones_total)
+const (
+	VerificacionVerificada   = "verificada"
+	VerificacionDiscrepancia = "discrepancia"
+	VerificacionPendiente    = "pendiente"
+	VerificacionError        = "error"
+)
+
+// Operaciones de IPFS (etiqueta operacion de las métricas medisupply_ipfs_*)
+const (
+	IPFSAdd = "add"
+	IPFSCat = "cat"
+	IPFSPin = "pin"
+)
+
+// RutaSinCoincidencia es la etiqueta ruta de las peticiones que no coinciden con ninguna ruta
+// registrada; agruparlas evita una serie por cada URL inventada
+const RutaSinCoincidencia = "sin_ruta"
+
+// Registro contiene todas las métricas de la API más las del runtime de Go y del proceso
+var Registro = prometheus.NewRegistry()
+
+var fabrica = promauto.With(Registro)
+
+var (
+	// medisupply_http_peticiones_total{metodo, ruta, estado}: peticiones HTTP atendidas. ruta es la
+	// plantilla de la ruta (/api/v1/transaccion/:id) y estado el código HTTP de la respuesta.
+	peticionesHTTP = fabrica.NewCounterVec(prometheus.CounterOpts{
+		Namespace: espacio,
+		Name:      "http_peticiones_total",
+		Help:      "Peticiones HTTP atendidas por método, plantilla de ruta y código de estado.",
+	}, []string{"metodo", "ruta", "estado"})
+
+	// medisupply_http_duracion_segundos{metodo, ruta}: latencia de las peticiones HTTP
+	duracionHTTP = fabrica.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: espacio,
+		Name:      "http_duracion_segundos",
+		Help:      "Latencia de las peticiones HTTP por método y plantilla de ruta.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"metodo", "ruta"})
+
+	// medisupply_transacciones_registradas_total{tipo_evento}: eventos guardados en IPFS y DynamoDB,
+	// individualmente o dentro de un lote
+	transaccionesRegistradas = fabrica.NewCounterVec(prometheus.CounterOpts{
+		Namespace: espacio,
+		Name:      "transacciones_registradas_total",
+		Help:      "Eventos de la cadena de suministro registrados por tipo de evento.",
+	}, []string{"tipo_evento"})
+
+	// medisupply_ipfs_duracion_segundos{operacion}: latencia de add, cat y pin contra IPFS
+	duracionIPFS = fabrica.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: espacio,
+		Name:      "ipfs_duracion_segundos",
+		Help:      "Latencia de las operaciones contra IPFS (add, cat, pin).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operacion"})
+
+	// medisupply_ipfs_errores_total{operacion}: operaciones contra IPFS que fallaron
+	erroresIPFS = fabrica.NewCounterVec(prometheus.CounterOpts{
+		Namespace: espacio,
+		Name:      "ipfs_errores_total",
+		Help:      "Operaciones contra IPFS (add, cat, pin) que fallaron.",
+	}, []string{"operacion"})
+
+	// medisupply_dynamodb_duracion_segundos{operacion, resultado}: latencia de cada llamada a la API de
+	// DynamoDB (PutItem, Query, TransactWriteItems...), reintentos del SDK incluidos
+	duracionDynamoDB = fabrica.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: espacio,
+		Name:      "dynamodb_duracion_segundos",
+		Help:      "Latencia de las operaciones de DynamoDB por operación y resultado (ok, error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operacion", "resultado"})
+
+	// medisupply_blockchain_envio_duracion_segundos{modo, resultado}: tiempo desde que se firma la
+	// transacción hasta que se mina (contrato) o se acepta en el mempool (transaccion-simple)
+	duracionBlockchain = fabrica.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: espacio,
+		Name:      "blockchain_envio_duracion_segundos",
+		Help:      "Latencia del anclaje en blockchain por modo (contrato, transaccion-simple) y resultado.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 15, 30, 60, 120, 300},
+	}, []string{"modo", "resultado"})
+
+	// medisupply_blockchain_gas_usado: gas consumido por cada anclaje confirmado. Solo en modo contrato,
+	// el único que espera el recibo; la suma (_sum) es el gas total gastado.
+	gasUsado = fabrica.NewHistogram(prometheus.HistogramOpts{
+		Namespace: espacio,
+		Name:      "blockchain_gas_usado",
+		Help:      "Gas consumido por cada anclaje confirmado en blockchain.",
+		Buckets:   prometheus.ExponentialBuckets(25000, 2, 8),
+	})
+
+	// medisupply_anclajes_pendientes: eventos registrados cuyo anclaje en blockchain todavía no termina
+	anclajesPendientes = fabrica.NewGauge(prometheus.GaugeOpts{
+		Namespace: espacio,
+		Name:      "anclajes_pendientes",
+		Help:      "Eventos registrados que esperan su anclaje en blockchain.",
+	})
+
+	// medisupply_blockchain_saldo_eth: saldo de la cuenta firmante según la última consulta del monitor de fondos
+	saldoFirmante = fabrica.NewGauge(prometheus.GaugeOpts{
+		Namespace: espacio,
+		Name:      "blockchain_saldo_eth",
+		Help:      "Saldo en ETH de la cuenta que firma los anclajes.",
+	})
+
+	// medisupply_blockchain_gasto_diario_eth: gas pagado por los anclajes de las últimas 24 horas
+	gastoDiario = fabrica.NewGauge(prometheus.GaugeOpts{
+		Namespace: espacio,
+		Name:      "blockchain_gasto_diario_eth",
+		Help:      "ETH gastado en gas por los anclajes de las últimas 24 horas.",
+	})
+
+	// medisupply_blockchain_anclajes_restantes: anclajes que paga el saldo al costo medio reciente
+	anclajesRestantes = fabrica.NewGauge(prometheus.GaugeOpts{
+		Namespace: espacio,
+		Name:      "blockchain_anclajes_restantes",
+		Help:      "Anclajes que puede pagar el saldo de la cuenta firmante al costo medio reciente.",
+	})
+
+	// medisupply_blockchain_autonomia_horas: horas hasta agotar el saldo al ritmo de gasto de las últimas
+	// 24 horas; +Inf si no hubo gasto
+	autonomia = fabrica.NewGauge(prometheus.GaugeOpts{
+		Namespace: espacio,
+		Name:      "blockchain_autonomia_horas",
+		Help:      "Horas hasta agotar el saldo de la cuenta firmante al ritmo de gasto reciente.",
+	})
+
+	// medisupply_anclaje_pausado: 1 mientras los anclajes esperan a que se recargue la cuenta firmante
+	anclajePausado = fabrica.NewGauge(prometheus.GaugeOpts{
+		Namespace: espacio,
+		Name:      "anclaje_pausado",
+		Help:      "1 si el anclaje está pausado por fondos insuficientes en la cuenta firmante.",
+	})
+
+	// medisupply_blockchain_alertas_fondos_total{nivel}: cambios del nivel de fondos (ok, bajo, insuficiente)
+	alertasFondos = fabrica.NewCounterVec(prometheus.CounterOpts{
+		Namespace: espacio,
+		Name:      "blockchain_alertas_fondos_total",
+		Help:      "Alertas de fondos de la cuenta firmante por nivel alcanzado (ok, bajo, insuficiente).",
+	}, []string{"nivel"})
+
+	// medisupply_verificaciones_total{resultado}: verificaciones de integridad por resultado
+	// (verificada, discrepancia, pendiente, error)
+	verificaciones = fabrica.NewCounterVec(prometheus.CounterOpts{
+		Namespace: espacio,
+		Name:      "verificaciones_total",
+		Help:      "Verificaciones de integridad por resultado (verificada, discrepancia, pendiente, error).",
+	}, []string{"resultado"})
+
+	// medisupply_rate_limit_rechazos_total{protocolo}: peticiones rechazadas por el rate limiting
+	// (protocolo http o grpc)
+	rechazosRateLimit = fabrica.NewCounterVec(prometheus.CounterOpts{
+		Namespace: espacio,
+		Name:      "rate_limit_rechazos_total",
+		Help:      "Peticiones rechazadas por el rate limiting por protocolo (http, grpc).",
+	}, []string{"protocolo"})
+)
+
+func init() {
+	Registro.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler sirve las métricas en el formato de exposición de Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registro, promhttp.HandlerOpts{})
+}
+
+// ObservarHTTP registra una petición HTTP atendida; ruta es la plantilla de la ruta, vacía si no coincidió
+func ObservarHTTP(metodo, ruta string, estado int, duracion time.Duration) {
+	if ruta == "" {
+		ruta = RutaSinCoincidencia
+	}
+	peticionesHTTP.WithLabelValues(metodo, ruta, strconv.Itoa(estado)).Inc()
+	duracionHTTP.WithLabelValues(metodo, ruta).Observe(duracion.Seconds())
+}
+
+// TransaccionRegistrada cuenta un evento registrado
+func TransaccionRegistrada(tipoEvento string) {
+	transaccionesRegistradas.WithLabelValues(tipoEvento).Inc()
+}
+
+// ObservarIPFS registra la latencia de una operación contra IPFS iniciada en inicio y cuenta el error si falló
+func ObservarIPFS(operacion string, inicio time.Time, err error) {
+	duracionIPFS.WithLabelValues(operacion).Observe(time.Since(inicio).Seconds())
+	if err != nil {
+		erroresIPFS.WithLabelValues(operacion).Inc()
+	}
+}
+
+// ObservarDynamoDB registra la latencia de una operación de DynamoDB iniciada en inicio
+func ObservarDynamoDB(operacion string, inicio time.Time, err error) {
+	duracionDynamoDB.WithLabelValues(operacion, resultado(err)).Observe(time.Since(inicio).Seconds())
+}
+
+// ObservarEnvioBlockchain registra la latencia de un anclaje iniciado en inicio y, si se conoce, el gas usado
+func ObservarEnvioBlockchain(modo string, inicio time.Time, gas uint64, err error) {
+	duracionBlockchain.WithLabelValues(modo, resultado(err)).Observe(time.Since(inicio).Seconds())
+	if err == nil && gas > 0 {
+		gasUsado.Observe(float64(gas))
+	}
+}
+
+// AnclajesEncolados suma n eventos a la cola de anclaje
+func AnclajesEncolados(n int) {
+	anclajesPendientes.Add(float64(n))
+}
+
+// AnclajeTerminado descuenta un evento de la cola de anclaje, se haya anclado o no
+func AnclajeTerminado() {
+	anclajesPendientes.Dec()
+}
+
+// ObservarFondos publica el saldo de la cuenta firmante y su proyección; autonomiaHoras es +Inf sin gasto
+func ObservarFondos(saldoEth, gastoDiarioEth float64, restantes int64, autonomiaHoras float64) {
+	saldoFirmante.Set(saldoEth)
+	gastoDiario.Set(gastoDiarioEth)
+	anclajesRestantes.Set(float64(restantes))
+	autonomia.Set(autonomiaHoras)
+}
+
+// AnclajePausado indica si el anclaje está pausado por fondos insuficientes
+func AnclajePausado(pausado bool) {
+	if pausado {
+		anclajePausado.Set(1)
+	} else {
+		anclajePausado.Set(0)
+	}
+}
+
+// AlertaFondos cuenta un cambio del nivel de fondos de la cuenta firmante
+func AlertaFondos(nivel string) {
+	alertasFondos.WithLabelValues(nivel).Inc()
+}
+
+// Verificacion cuenta una verificación de integridad con su resultado
+func Verificacion(resultado string) {
+	verificaciones.WithLabelValues(resultado).Inc()
+}
+
+// RechazoRateLimit cuenta una petición rechazada por el rate limiting
+func RechazoRateLimit(protocolo string) {
+	rechazosRateLimit.WithLabelValues(protocolo).Inc()
+}
+
+func resultado(err error) string {
+	if err != nil {
+		return ResultadoError
+	}
+	return ResultadoOK
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// ClavePrincipal es la clave del principal autenticado en el contexto de Gin
+const ClavePrincipal = "principal"
+
+// RolAdmin es el rol que permite administrar API keys
+const RolAdmin = "admin"
+
+// AuthConfig agrupa los mecanismos de autenticación aceptados
+type AuthConfig struct {
+	APIKeys       *services.APIKeyService      // API keys emitidas por el endpoint de administración
+	JWT           *auth.ValidadorJWT           // nil si no hay secreto HMAC ni JWKS configurado
+	ClaveArranque string                       // API key estática con rol admin para crear las primeras claves
+	Certificados  *services.CertificadoService // nil si el listener no verifica certificados de cliente
+}
+
+// AuthMiddleware exige una API key (cabecera X-API-Key o Authorization: Bearer msk_...), un JWT
+// (Authorization: Bearer) o un certificado de cliente verificado por mTLS, y deja el principal
+// autenticado en el contexto de Gin y del request. Una credencial en cabecera tiene prioridad sobre el certificado.
+func AuthMiddleware(cfg AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credencial, esBearer := extraerCredencial(c.Request)
+		principal, err := cfg.Autenticar(c.Request.Context(), credencial, esBearer, auth.CertificadoVerificado(c.Request.TLS))
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrSinCredenciales):
+				rechazarNoAutenticado(c, CodigoNoAutenticado, err)
+			case EsCredencialInvalida(err):
+				rechazarNoAutenticado(c, CodigoCredencialesInvalidas, err)
+			default:
+				slog.ErrorContext(c.Request.Context(), "auth: error validando credenciales", "error", err)
+				ResponderProblema(c, NuevoProblema(c, http.StatusServiceUnavailable, CodigoAutenticacionNoDisponible, ""))
+			}
+			return
+		}
+
+		c.Set(ClavePrincipal, principal)
+		c.Request = c.Request.WithContext(auth.ConPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// ErrSinCredenciales indica que la petición no trae credencial en cabecera ni un certificado aceptado
+var ErrSinCredenciales = i18n.NuevoError("auth.sin-credenciales")
+
+// Autenticar resuelve el principal de una credencial (esBearer si llegó en Authorization) o, si no hay
+// credencial, del certificado de cliente verificado. La usan AuthMiddleware y el servidor gRPC.
+func (cfg AuthConfig) Autenticar(ctx context.Context, credencial string, esBearer bool, certificado *x509.Certificate) (*models.Principal, error) {
+	switch {
+	case credencial == "" && (certificado == nil || cfg.Certificados == nil):
+		return nil, ErrSinCredenciales
+	case credencial == "":
+		return cfg.Certificados.Autenticar(ctx, certificado)
+	case cfg.ClaveArranque != "" && esClaveArranque(credencial, cfg.ClaveArranque):
+		return &models.Principal{ID: "arranque", Actor: "admin", Roles: []string{RolAdmin}, Metodo: models.MetodoAPIKey}, nil
+	case !esBearer || services.EsAPIKey(credencial):
+		return cfg.APIKeys.Autenticar(ctx, credencial)
+	case cfg.JWT != nil:
+		return cfg.JWT.Validar(credencial)
+	default:
+		return nil, auth.ErrTokenInvalido
+	}
+}
+
+// EsCredencialInvalida indica si err se debe a una credencial rechazada y no a un fallo al validarla
+func EsCredencialInvalida(err error) bool {
+	return errors.Is(err, services.ErrCredencialesInvalidas) || errors.Is(err, auth.ErrTokenInvalido)
+}
+
+// RequerirRol permite continuar solo si el principal tiene alguno de los roles
+func RequerirRol(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalDesdeContexto(c)
+		if principal == nil || !principal.TieneRol(roles...) {
+			ResponderProblema(c, NuevoProblema(c, http.StatusForbidden, CodigoPermisosInsuficientes,
+				i18n.T(c.Request.Context(), "auth.roles-requeridos", strings.Join(roles, ", "))))
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalDesdeContexto retorna el principal autenticado, o nil si la ruta no exige autenticación
+func PrincipalDesdeContexto(c *gin.Context) *models.Principal {
+	valor, ok := c.Get(ClavePrincipal)
+	if !ok {
+		return nil
+	}
+	principal, _ := valor.(*models.Principal)
+	return principal
+}
+
+// extraerCredencial obtiene la credencial de X-API-Key o de Authorization: Bearer
+func extraerCredencial(r *http.Request) (string, bool) {
+	if clave := strings.TrimSpace(r.Header.Get("X-API-Key")); clave != "" {
+		return clave, false
+	}
+	cabecera := r.Header.Get("Authorization")
+	if len(cabecera) > 7 && strings.EqualFold(cabecera[:7], "bearer ") {
+		return strings.TrimSpace(cabecera[7:]), true
+	}
+	return "", false
+}
+
+// esClaveArranque compara la credencial con la clave de arranque en tiempo constante
+func esClaveArranque(credencial, claveArranque string) bool {
+	hash := sha256.Sum256([]byte(credencial))
+	hashArranque := sha256.Sum256([]byte(claveArranque))
+	return subtle.ConstantTimeCompare(hash[:], hashArranque[:]) == 1
+}
+
+// rechazarNoAutenticado responde 401 con el desafío Bearer; el motivo es el detalle del problema
+func rechazarNoAutenticado(c *gin.Context, codigo string, motivo error) {
+	c.Header("WWW-Authenticate", `Bearer realm="medisupply"`)
+	ResponderProblema(c, NuevoProblema(c, http.StatusUnauthorized, codigo, i18n.TraducirError(motivo, i18n.IdiomaDe(c.Request.Context()))))
+}
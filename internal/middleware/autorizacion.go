@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+)
+
+// AutorizacionMiddleware evalúa la política de rutas para el principal autenticado.
+// Debe registrarse después de AuthMiddleware; sin principal (autenticación deshabilitada) no aplica.
+func AutorizacionMiddleware(motor *policy.Motor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalDesdeContexto(c)
+		if principal == nil {
+			c.Next()
+			return
+		}
+
+		ruta := c.FullPath()
+		if ruta == "" {
+			// Ruta no registrada: la responde NoRoute
+			c.Next()
+			return
+		}
+
+		if err := motor.AutorizarRuta(principal, c.Request.Method, ruta); err != nil {
+			// Una denegación responde 403 con la regla que la causó; cualquier otro error también
+			// detiene la petición: nunca se deja pasar sin autorizar
+			ResponderError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,20 +1,90 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
 )
 
-// CORSMiddleware configura CORS para la API
-func CORSMiddleware() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // En producción, especificar orígenes permitidos
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	})
+// ConfigCORS es la política CORS de la API
+type ConfigCORS struct {
+	Origenes           []string // "*", orígenes exactos (https://app.medisupply.com) o con comodín de subdominio (https://*.medisupply.com)
+	Metodos            []string
+	Cabeceras          []string // Cabeceras que el navegador puede enviar
+	CabecerasExpuestas []string // Cabeceras de la respuesta que el navegador deja leer (X-Request-ID, RateLimit-*)
+	Credenciales       bool     // Cookies y Authorization en peticiones de otro origen; no se admite con "*"
+	MaxAge             time.Duration
+}
+
+// CORS aplica la política CORS de la API. La política se puede reemplazar en caliente (recarga de
+// configuración con SIGHUP) sin reconstruir el router.
+type CORS struct {
+	manejador atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewCORS crea el middleware CORS con la política dada
+func NewCORS(config ConfigCORS) (*CORS, error) {
+	c := &CORS{}
+	if err := c.Actualizar(config); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Actualizar reemplaza la política; si no es válida conserva la anterior
+func (c *CORS) Actualizar(config ConfigCORS) error {
+	configCORS := cors.Config{
+		AllowMethods:     config.Metodos,
+		AllowHeaders:     config.Cabeceras,
+		ExposeHeaders:    config.CabecerasExpuestas,
+		AllowCredentials: config.Credenciales,
+		MaxAge:           config.MaxAge,
+	}
+
+	switch {
+	case len(config.Origenes) == 0:
+		return errors.New("CORS: se requiere al menos un origen permitido")
+	case slices.Contains(config.Origenes, "*"):
+		// Con credenciales el navegador rechaza "Access-Control-Allow-Origin: *", y reflejar cualquier
+		// origen expondría las sesiones a todos los sitios
+		if config.Credenciales {
+			return errors.New("CORS: no se pueden permitir credenciales con el origen *")
+		}
+		configCORS.AllowAllOrigins = true
+	default:
+		origenes := make([]utils.OrigenCORS, len(config.Origenes))
+		for i, origen := range config.Origenes {
+			var err error
+			if origenes[i], err = utils.ParsearOrigenCORS(origen); err != nil {
+				return fmt.Errorf("CORS: %w", err)
+			}
+		}
+		configCORS.AllowOriginFunc = func(origen string) bool {
+			return slices.ContainsFunc(origenes, func(permitido utils.OrigenCORS) bool {
+				return permitido.Permite(origen)
+			})
+		}
+	}
+
+	if err := configCORS.Validate(); err != nil {
+		return fmt.Errorf("CORS: %w", err)
+	}
+	manejador := cors.New(configCORS)
+	c.manejador.Store(&manejador)
+	return nil
+}
+
+// Middleware retorna el handler de Gin; cada petición usa la política vigente. Las peticiones de un
+// origen no permitido reciben 403.
+func (c *CORS) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		(*c.manejador.Load())(ctx)
+	}
 }
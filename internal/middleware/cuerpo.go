@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimiteCuerpoMiddleware acota el cuerpo de la petición a maximo() bytes antes de que lo lea cualquier
+// otro middleware (IdempotenciaMiddleware lo lee completo para calcular su huella). Quien lea más allá
+// del límite recibe *http.MaxBytesError, que ResponderError convierte en 413.
+func LimiteCuerpoMiddleware(maximo func() int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maximo())
+		c.Next()
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// Códigos de los problemas que no provienen de un services.ErrorDominio; son también la clave de su
+// título en el catálogo de i18n
+const (
+	CodigoNoAutenticado             = "no-autenticado"
+	CodigoCredencialesInvalidas     = "credenciales-invalidas"
+	CodigoAutenticacionNoDisponible = "autenticacion-no-disponible"
+	CodigoAccesoDenegado            = "acceso-denegado"
+	CodigoPermisosInsuficientes     = "permisos-insuficientes"
+	CodigoDemasiadasPeticiones      = "demasiadas-peticiones"
+	CodigoIdempotencyKeyInvalida    = "idempotency-key-invalida"
+	CodigoLoteInvalido              = "lote-invalido"
+	CodigoCuerpoDemasiadoGrande     = "cuerpo-demasiado-grande"
+	CodigoRutaNoEncontrada          = "ruta-no-encontrada"
+	CodigoTimeout                   = "timeout"
+	CodigoErrorInterno              = "error-interno"
+)
+
+// statusPorTipo traduce la clasificación de los errores de dominio a su status HTTP
+var statusPorTipo = map[services.TipoError]int{
+	services.TipoNoEncontrado:    http.StatusNotFound,
+	services.TipoValidacion:      http.StatusBadRequest,
+	services.TipoConflicto:       http.StatusConflict,
+	services.TipoNoProcesable:    http.StatusUnprocessableEntity,
+	services.TipoDemasiadoGrande: http.StatusRequestEntityTooLarge,
+	services.TipoNoSoportado:     http.StatusUnsupportedMediaType,
+	services.TipoNoDisponible:    http.StatusServiceUnavailable,
+	services.TipoTimeout:         http.StatusGatewayTimeout,
+}
+
+// ErroresMiddleware traduce el error que un handler registró con c.Error en una respuesta
+// application/problem+json. Debe registrarse antes que los demás middlewares para ver todos los errores.
+func ErroresMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		ResponderErrorPendiente(c)
+	}
+}
+
+// ResponderErrorPendiente responde el último error registrado si todavía no se escribió respuesta.
+// Los middlewares que inspeccionan la respuesta (idempotencia) lo llaman tras c.Next().
+func ResponderErrorPendiente(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	ResponderError(c, c.Errors.Last().Err)
+}
+
+// ResponderError responde el problema que corresponde a err, en el idioma de la petición, y aborta la cadena de handlers
+func ResponderError(c *gin.Context, err error) {
+	problema := ProblemaDesdeError(err, i18n.IdiomaDe(c.Request.Context()))
+	if problema.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "error del servidor", "metodo", c.Request.Method, "ruta", c.Request.URL.Path, "codigo", problema.Codigo, "error", err)
+	}
+	ResponderProblema(c, problema)
+}
+
+// ResponderProblema escribe el problema con el media type de RFC 7807 y aborta la cadena de handlers
+func ResponderProblema(c *gin.Context, problema *models.Problema) {
+	if problema.Instancia == "" {
+		problema.Instancia = c.Request.URL.Path
+	}
+	// Los detalles pueden citar valores recibidos o mensajes de dependencias: nunca deben exponer secretos
+	problema.Detalle = redaccion.Texto(problema.Detalle)
+	if len(problema.Resultados) > 0 {
+		resultados := make([]models.ResultadoLote, len(problema.Resultados))
+		for i, resultado := range problema.Resultados {
+			resultado.Error = redaccion.Texto(resultado.Error)
+			resultados[i] = resultado
+		}
+		problema.Resultados = resultados
+	}
+	c.Header("Content-Type", models.TipoContenidoProblema)
+	c.AbortWithStatusJSON(problema.Status, problema)
+}
+
+// NuevoProblema crea un problema con el título del código en el idioma de la petición
+func NuevoProblema(c *gin.Context, status int, codigo, detalle string) *models.Problema {
+	return models.NuevoProblema(status, codigo, i18n.Titulo(i18n.IdiomaDe(c.Request.Context()), codigo), detalle)
+}
+
+// ProblemaDesdeError clasifica err y redacta el problema en el idioma indicado. Solo los errores del cliente
+// (4xx) exponen su detalle; en los del servidor el detalle interno (hosts, mensajes de AWS, etc.) queda en
+// el log. Un cuerpo que supera el límite de http.MaxBytesReader es 413 aunque el error llegue envuelto
+// como fallo de IPFS o de validación.
+func ProblemaDesdeError(err error, idioma i18n.Idioma) *models.Problema {
+	var (
+		denegacion *policy.Denegacion
+		lote       *services.ErrorValidacionLote
+		dominio    *services.ErrorDominio
+		maxBytes   *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &denegacion):
+		problema := models.NuevoProblema(http.StatusForbidden, CodigoAccesoDenegado, i18n.Titulo(idioma, CodigoAccesoDenegado), denegacion.MotivoEn(idioma))
+		problema.Regla = denegacion.Regla
+		return problema
+	case errors.As(err, &lote):
+		problema := models.NuevoProblema(http.StatusBadRequest, CodigoLoteInvalido, i18n.Titulo(idioma, CodigoLoteInvalido), lote.Traducir(idioma))
+		problema.Resultados = lote.Resultados
+		return problema
+	case errors.As(err, &maxBytes):
+		return models.NuevoProblema(http.StatusRequestEntityTooLarge, CodigoCuerpoDemasiadoGrande, i18n.Titulo(idioma, CodigoCuerpoDemasiadoGrande),
+			i18n.Traducir(idioma, "cuerpo.supera-bytes", maxBytes.Limit))
+	case errors.As(err, &dominio):
+		status, ok := statusPorTipo[dominio.Tipo]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		mensaje := dominio.MensajeEn(idioma)
+		detalle := ""
+		if dominio.EsErrorDelCliente() {
+			if texto := i18n.TraducirError(err, idioma); texto != mensaje {
+				detalle = texto
+			}
+		}
+		return models.NuevoProblema(status, dominio.Codigo, i18n.Capitalizar(mensaje), detalle)
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.NuevoProblema(http.StatusGatewayTimeout, CodigoTimeout, i18n.Titulo(idioma, CodigoTimeout), "")
+	default:
+		return models.NuevoProblema(http.StatusInternalServerError, CodigoErrorInterno, i18n.Titulo(idioma, CodigoErrorInterno), "")
+	}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// CabeceraIdempotencia es la cabecera con la clave de idempotencia elegida por el cliente
+const CabeceraIdempotencia = "Idempotency-Key"
+
+// longitudMaximaIdempotencia acota la clave enviada por el cliente
+const longitudMaximaIdempotencia = 255
+
+// IdempotenciaMiddleware hace que los reintentos con la misma Idempotency-Key devuelvan la respuesta
+// original en lugar de repetir la operación. La clave se aplica por llamador autenticado, así que debe
+// ir después de AuthMiddleware. Lee el cuerpo completo, así que debe ir después de LimiteCuerpoMiddleware.
+// Sin cabecera la petición sigue sin cambios.
+func IdempotenciaMiddleware(servicio *services.IdempotenciaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clave := strings.TrimSpace(c.GetHeader(CabeceraIdempotencia))
+		if clave == "" {
+			c.Next()
+			return
+		}
+		if len(clave) > longitudMaximaIdempotencia {
+			ResponderProblema(c, NuevoProblema(c, http.StatusBadRequest, CodigoIdempotencyKeyInvalida,
+				i18n.T(c.Request.Context(), "idempotencia.clave-demasiado-larga", longitudMaximaIdempotencia)))
+			return
+		}
+
+		cuerpo, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			ResponderError(c, services.ErrSolicitudInvalida.Con(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(cuerpo))
+
+		claveAlcance := services.ClaveIdempotencia(alcanceIdempotencia(c), clave)
+		huella := huellaPeticion(c.Request.Method, c.FullPath(), cuerpo)
+
+		registro, err := servicio.Iniciar(c.Request.Context(), claveAlcance, huella)
+		switch {
+		case errors.Is(err, services.ErrIdempotenciaEnCurso):
+			c.Header("Retry-After", "5")
+			ResponderError(c, err)
+			return
+		case err != nil:
+			ResponderError(c, services.ErrorDependencia(services.DependenciaDynamoDB, err))
+			return
+		case registro != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(registro.StatusCode, registro.TipoContenido, registro.Respuesta)
+			c.Abort()
+			return
+		}
+
+		// La operación termina aunque el cliente abandone la conexión: su reintento recibirá el resultado
+		ctxOriginal := c.Request.Context()
+		c.Request = c.Request.WithContext(context.WithoutCancel(ctxOriginal))
+		captura := &escritorCaptura{ResponseWriter: c.Writer}
+		c.Writer = captura
+
+		c.Next()
+		// El error del handler se responde aquí y no en ErroresMiddleware para que quede en la captura
+		ResponderErrorPendiente(c)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctxOriginal), 10*time.Second)
+		defer cancel()
+
+		// Los errores del servidor no se guardan: el reintento debe poder ejecutar la operación
+		status := captura.Status()
+		if status >= http.StatusInternalServerError {
+			if err := servicio.Liberar(ctx, claveAlcance); err != nil {
+				slog.ErrorContext(ctx, "idempotencia: error liberando clave", "error", err)
+			}
+			return
+		}
+		if err := servicio.Completar(ctx, claveAlcance, huella, status, captura.Header().Get("Content-Type"), captura.cuerpo.Bytes()); err != nil {
+			slog.ErrorContext(ctx, "idempotencia: error guardando respuesta", "error", err)
+		}
+	}
+}
+
+// alcanceIdempotencia identifica al llamador; sin autenticación todas las peticiones comparten alcance
+func alcanceIdempotencia(c *gin.Context) string {
+	principal := PrincipalDesdeContexto(c)
+	if principal == nil {
+		return "anonimo"
+	}
+	return principal.Metodo + ":" + principal.ID
+}
+
+// huellaPeticion identifica el contenido de la petición para detectar claves reutilizadas
+func huellaPeticion(metodo, ruta string, cuerpo []byte) string {
+	h := sha256.New()
+	h.Write([]byte(metodo + " " + ruta + "\n"))
+	h.Write(cuerpo)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// escritorCaptura conserva una copia del cuerpo de la respuesta
+type escritorCaptura struct {
+	gin.ResponseWriter
+	cuerpo bytes.Buffer
+}
+
+func (w *escritorCaptura) Write(datos []byte) (int, error) {
+	w.cuerpo.Write(datos)
+	return w.ResponseWriter.Write(datos)
+}
+
+func (w *escritorCaptura) WriteString(datos string) (int, error) {
+	w.cuerpo.WriteString(datos)
+	return w.ResponseWriter.WriteString(datos)
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+)
+
+// IdiomaMiddleware elige el idioma de la respuesta según Accept-Language y lo deja en el contexto del
+// request, de donde lo toman los handlers, los servicios y el middleware de errores. Debe registrarse
+// antes que ErroresMiddleware.
+func IdiomaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idioma := i18n.DesdeAcceptLanguage(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.ConIdioma(c.Request.Context(), idioma))
+		c.Header("Content-Language", string(idioma))
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -1,33 +1,56 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
 )
 
-// LoggerMiddleware es un middleware personalizado para logging
+// CabeceraRequestID es la cabecera con la que el cliente envía y recibe el request ID
+const CabeceraRequestID = "X-Request-ID"
+
+// LoggerMiddleware asigna un request ID a cada petición (el X-Request-ID del cliente si es válido,
+// o uno nuevo), lo deja en el contexto para los logs de handlers y servicios, lo retorna en la
+// respuesta y registra método, ruta, estado y latencia al terminar, en el log y en las métricas HTTP.
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
-		path := c.Request.URL.Path
-		method := c.Request.Method
+
+		requestID := c.GetHeader(CabeceraRequestID)
+		if !logging.RequestIDValido(requestID) {
+			requestID = logging.NuevoRequestID()
+		}
+		ctx := logging.ConRequestID(c.Request.Context(), requestID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(CabeceraRequestID, requestID)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(logging.ClaveRequestID, requestID))
 
 		// Procesar request
 		c.Next()
 
-		// Calcular latencia
-		latency := time.Since(startTime)
 		statusCode := c.Writer.Status()
+		latencia := time.Since(startTime)
+		metricas.ObservarHTTP(c.Request.Method, c.FullPath(), statusCode, latencia)
 
-		// Log
-		log.Printf("[%s] %s %s - Status: %d - Latency: %v",
-			method,
-			path,
-			c.ClientIP(),
-			statusCode,
-			latency,
+		nivel := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			nivel = slog.LevelError
+		case statusCode >= 400:
+			nivel = slog.LevelWarn
+		}
+		slog.Log(ctx, nivel, "petición HTTP",
+			"metodo", c.Request.Method,
+			"ruta", c.Request.URL.Path,
+			"estado", statusCode,
+			"latencia_ms", latencia.Milliseconds(),
+			"ip", c.ClientIP(),
 		)
 	}
 }
@@ -1,92 +1,154 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
 )
 
-// IPRateLimiter gestiona limitadores de tasa por IP
-type IPRateLimiter struct {
-	ips             map[string]*rate.Limiter
-	mu              *sync.RWMutex
-	r               rate.Limit
-	b               int
-	cleanupInterval time.Duration
-}
+// Cabeceras de rate limiting (draft-ietf-httpapi-ratelimit-headers); Reset y Retry-After en segundos
+const (
+	CabeceraRateLimitLimit     = "RateLimit-Limit"
+	CabeceraRateLimitRemaining = "RateLimit-Remaining"
+	CabeceraRateLimitReset     = "RateLimit-Reset"
+	CabeceraRateLimitPolicy    = "RateLimit-Policy"
+	CabeceraRetryAfter         = "Retry-After"
+)
 
-// NewIPRateLimiter crea un nuevo limitador de tasa por IP
-func NewIPRateLimiter(requestsPerWindow int, windowSeconds int) *IPRateLimiter {
-	limiter := &IPRateLimiter{
-		ips:             make(map[string]*rate.Limiter),
-		mu:              &sync.RWMutex{},
-		r:               rate.Limit(float64(requestsPerWindow) / float64(windowSeconds)),
-		b:               requestsPerWindow,
-		cleanupInterval: time.Minute * 5,
-	}
+// RateLimiter aplica las cuotas de peticiones a la API REST y a la gRPC. Compartirlo entre ambas hace
+// que un mismo llamador tenga un único cupo.
+type RateLimiter struct {
+	limitador ratelimit.Limitador
+	cuotas    atomic.Pointer[ratelimit.Cuotas]
+}
 
-	// Iniciar limpieza periódica
-	go limiter.cleanupRoutine()
+// NewRateLimiter crea un RateLimiter sobre un limitador en memoria o compartido
+func NewRateLimiter(limitador ratelimit.Limitador, cuotas ratelimit.Cuotas) *RateLimiter {
+	r := &RateLimiter{limitador: limitador}
+	r.ActualizarCuotas(cuotas)
+	return r
+}
 
-	return limiter
+// ActualizarCuotas reemplaza las cuotas en caliente; las peticiones ya contadas en la ventana actual
+// se conservan si la ventana no cambia
+func (r *RateLimiter) ActualizarCuotas(cuotas ratelimit.Cuotas) {
+	r.cuotas.Store(&cuotas)
 }
 
-// AddIP crea un nuevo limitador para una IP si no existe
-func (i *IPRateLimiter) AddIP(ip string) *rate.Limiter {
-	i.mu.Lock()
-	defer i.mu.Unlock()
+// Admitir consume las cuotas de una petición: la del principal (según sus roles) o, sin principal, la
+// de la IP; y además la de la ruta si RATE_LIMIT_ROUTES le asigna una. Retorna la decisión más restrictiva.
+func (r *RateLimiter) Admitir(ctx context.Context, ip string, principal *models.Principal, metodo, ruta string) ratelimit.Decision {
+	cuotas := r.cuotas.Load()
+	clave, cuota := "ip:"+ip, cuotas.PorDefecto
+	if principal != nil {
+		clave, cuota = "principal:"+principal.Metodo+":"+principal.ID, cuotas.DeRoles(principal.Roles)
+	}
 
-	limiter := rate.NewLimiter(i.r, i.b)
-	i.ips[ip] = limiter
+	decision := r.consumir(ctx, clave, cuota, 1)
+	if cuotaRuta, ok := cuotas.DeRuta(metodo, ruta); ok {
+		decision = ratelimit.MasRestrictiva(decision, r.consumir(ctx, clave+"|"+metodo+" "+ruta, cuotaRuta, 1))
+	}
+	return decision
+}
 
-	return limiter
+// ConsultarIP indica, sin consumirlo, si la IP tiene cupo. Antes de autenticar solo se consulta: el cupo
+// de una petición autenticada se descuenta al principal, no a la IP compartida tras un NAT o un proxy.
+func (r *RateLimiter) ConsultarIP(ctx context.Context, ip string) ratelimit.Decision {
+	return r.consumir(ctx, "ip:"+ip, r.cuotas.Load().PorDefecto, 0)
 }
 
-// GetLimiter retorna el limitador para una IP
-func (i *IPRateLimiter) GetLimiter(ip string) *rate.Limiter {
-	i.mu.RLock()
-	limiter, exists := i.ips[ip]
-	i.mu.RUnlock()
+// PenalizarIP descuenta una petición del cupo de la IP; se usa cuando la autenticación falla, de modo
+// que probar credenciales también tiene límite
+func (r *RateLimiter) PenalizarIP(ctx context.Context, ip string) {
+	r.consumir(ctx, "ip:"+ip, r.cuotas.Load().PorDefecto, 1)
+}
 
-	if !exists {
-		return i.AddIP(ip)
+// consumir aplica el limitador; si falla se admite la petición para no dejar la API caída por el rate limiting
+func (r *RateLimiter) consumir(ctx context.Context, clave string, cuota ratelimit.Cuota, n int) ratelimit.Decision {
+	decision, err := r.limitador.Consumir(ctx, clave, cuota, n)
+	if err != nil {
+		slog.WarnContext(ctx, "rate limiting no disponible, se admite la petición", "error", err)
+		return ratelimit.Decision{Permitida: true}
 	}
+	return decision
+}
 
-	return limiter
+// CabecerasRateLimit retorna las cabeceras RateLimit-* de una decisión (y Retry-After si fue rechazada)
+func CabecerasRateLimit(decision ratelimit.Decision) map[string]string {
+	if decision.Limite == 0 {
+		return nil
+	}
+	reinicio := segundos(decision.Reinicio)
+	cabeceras := map[string]string{
+		CabeceraRateLimitLimit:     strconv.Itoa(decision.Limite),
+		CabeceraRateLimitRemaining: strconv.Itoa(decision.Restantes),
+		CabeceraRateLimitReset:     reinicio,
+		CabeceraRateLimitPolicy:    strconv.Itoa(decision.Limite) + ";w=" + segundos(decision.Ventana),
+	}
+	if !decision.Permitida {
+		cabeceras[CabeceraRetryAfter] = reinicio
+	}
+	return cabeceras
 }
 
-// cleanupRoutine limpia limitadores no utilizados
-func (i *IPRateLimiter) cleanupRoutine() {
-	ticker := time.NewTicker(i.cleanupInterval)
-	defer ticker.Stop()
+// segundos redondea hacia arriba para no anunciar un reinicio anterior al real
+func segundos(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
 
-	for range ticker.C {
-		i.mu.Lock()
-		// En una implementación de producción, mantendríamos track del último uso
-		// Por ahora, mantenemos todos los limitadores
-		i.mu.Unlock()
+// Middleware limita cada petición por el principal autenticado o, si no hay, por la IP, y agrega las
+// cabeceras RateLimit-* a la respuesta
+func (r *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision := r.Admitir(c.Request.Context(), c.ClientIP(), PrincipalDesdeContexto(c), c.Request.Method, c.FullPath())
+		if !responderDecision(c, decision) {
+			return
+		}
+		c.Next()
 	}
 }
 
-// RateLimitMiddleware crea un middleware de rate limiting
-func RateLimitMiddleware(requestsPerWindow, windowSeconds int) gin.HandlerFunc {
-	limiter := NewIPRateLimiter(requestsPerWindow, windowSeconds)
-
+// ProtegerAutenticacion va antes de AuthMiddleware: rechaza las IPs sin cupo y les descuenta cada
+// petición que termine en 401
+func (r *RateLimiter) ProtegerAutenticacion() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
-		limiter := limiter.GetLimiter(ip)
-
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Demasiadas peticiones. Por favor, intente más tarde.",
-			})
-			c.Abort()
+		if !responderDecision(c, r.ConsultarIP(c.Request.Context(), ip)) {
 			return
 		}
-
 		c.Next()
+		if c.Writer.Status() == http.StatusUnauthorized {
+			r.PenalizarIP(c.Request.Context(), ip)
+		}
 	}
 }
+
+// responderDecision agrega las cabeceras y, si la decisión rechaza la petición, responde 429
+func responderDecision(c *gin.Context, decision ratelimit.Decision) bool {
+	for nombre, valor := range CabecerasRateLimit(decision) {
+		c.Header(nombre, valor)
+	}
+	if decision.Permitida {
+		return true
+	}
+	metricas.RechazoRateLimit("http")
+	ResponderProblema(c, NuevoProblema(c, http.StatusTooManyRequests, CodigoDemasiadasPeticiones, i18n.T(c.Request.Context(), "general.intente-mas-tarde")))
+	return false
+}
+
+// RateLimitMiddleware crea un middleware con un limitador en memoria y una única cuota por llamador
+func RateLimitMiddleware(requestsPerWindow, windowSeconds int) gin.HandlerFunc {
+	cuotas := ratelimit.Cuotas{PorDefecto: ratelimit.Cuota{Limite: requestsPerWindow, Ventana: time.Duration(windowSeconds) * time.Second}}
+	return NewRateLimiter(ratelimit.NewMemoria(), cuotas).Middleware()
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecuperacionMiddleware reemplaza a gin.Recovery: convierte un pánico en un 500 application/problem+json
+// y lo registra con slog (pasando por la redacción) en lugar de volcar las cabeceras de la petición,
+// que incluyen X-API-Key y otras credenciales.
+func RecuperacionMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recuperado any) {
+		slog.ErrorContext(c.Request.Context(), "pánico atendiendo la petición",
+			"error", fmt.Sprint(recuperado),
+			"metodo", c.Request.Method,
+			"ruta", c.Request.URL.Path,
+			"pila", string(debug.Stack()),
+		)
+		ResponderProblema(c, NuevoProblema(c, http.StatusInternalServerError, CodigoErrorInterno, ""))
+	})
+}
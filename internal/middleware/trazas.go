@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
+)
+
+// TrazasMiddleware abre el span de servidor de cada petición. Si el cliente envía traceparent (W3C Trace
+// Context) el span continúa su traza; los spans de IPFS, DynamoDB y blockchain de la petición cuelgan de él.
+// Va antes de LoggerMiddleware para que el log de la petición lleve el trace ID.
+func TrazasMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ruta := c.FullPath()
+		if ruta == "" {
+			ruta = metricas.RutaSinCoincidencia
+		}
+		ctx, span := trazas.Iniciar(ctx, c.Request.Method+" "+ruta,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(ruta),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		estado := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(estado))
+		if estado >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(estado))
+			if err := c.Errors.Last(); err != nil {
+				trazas.RegistrarError(span, err.Err)
+			}
+		}
+	}
+}
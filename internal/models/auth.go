@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Métodos de autenticación de un Principal
+const (
+	MetodoAPIKey      = "api_key"
+	MetodoJWT         = "jwt"
+	MetodoCertificado = "certificado"
+)
+
+// Principal es la identidad autenticada que realiza la petición
+type Principal struct {
+	ID     string   `json:"id"`     // ID de la API key, subject del JWT o identidad del certificado
+	Actor  string   `json:"actor"`  // Actor de la cadena de suministro; reemplaza a actorEmisor
+	Roles  []string `json:"roles"`  // Roles para autorización
+	Metodo string   `json:"metodo"` // api_key, jwt o certificado
+}
+
+// TieneRol indica si el principal tiene alguno de los roles
+func (p *Principal) TieneRol(roles ...string) bool {
+	for _, propio := range p.Roles {
+		for _, rol := range roles {
+			if propio == rol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// APIKey representa una clave de API. Solo se almacena el hash del secreto.
+type APIKey struct {
+	ID         string     `json:"id" dynamodbav:"id"`
+	Nombre     string     `json:"nombre" dynamodbav:"nombre"`
+	HashClave  string     `json:"-" dynamodbav:"hashClave"` // SHA-256 del secreto, en hex
+	Actor      string     `json:"actor" dynamodbav:"actor"`
+	Roles      []string   `json:"roles" dynamodbav:"roles"`
+	CreadaEn   time.Time  `json:"creadaEn" dynamodbav:"creadaEn"`
+	RevocadaEn *time.Time `json:"revocadaEn,omitempty" dynamodbav:"revocadaEn,omitempty"`
+}
+
+// APIKeyRequest representa el payload de creación de una API key
+type APIKeyRequest struct {
+	Nombre string   `json:"nombre" validate:"required"`
+	Actor  string   `json:"actor" validate:"required"`
+	Roles  []string `json:"roles"`
+}
+
+// APIKeyCreadaResponse incluye la clave en texto plano; solo se muestra al crearla
+type APIKeyCreadaResponse struct {
+	Clave  string  `json:"clave"`
+	APIKey *APIKey `json:"apiKey"`
+}
+
+// IdentidadCertificado asocia la identidad de un certificado de cliente (SAN o subject) a un actor
+type IdentidadCertificado struct {
+	Identidad  string     `json:"identidad" dynamodbav:"identidad"` // URI/DNS/email del SAN o DN del subject
+	Nombre     string     `json:"nombre" dynamodbav:"nombre"`
+	Actor      string     `json:"actor" dynamodbav:"actor"`
+	Roles      []string   `json:"roles" dynamodbav:"roles"`
+	CreadaEn   time.Time  `json:"creadaEn" dynamodbav:"creadaEn"`
+	RevocadaEn *time.Time `json:"revocadaEn,omitempty" dynamodbav:"revocadaEn,omitempty"`
+}
+
+// IdentidadCertificadoRequest representa el payload de registro de una identidad de certificado
+type IdentidadCertificadoRequest struct {
+	Identidad string   `json:"identidad" validate:"required"`
+	Nombre    string   `json:"nombre" validate:"required"`
+	Actor     string   `json:"actor" validate:"required"`
+	Roles     []string `json:"roles"`
+}
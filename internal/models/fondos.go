@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// EstadoFondos es el saldo de la cuenta firmante y su proyección según el gasto reciente en gas.
+// Las cantidades van en wei (texto, sin pérdida de precisión) y en ETH (texto decimal).
+type EstadoFondos struct {
+	Cuenta            string    `json:"cuenta"`
+	Nivel             string    `json:"nivel"`          // ok, bajo o insuficiente
+	AnclajePausado    bool      `json:"anclajePausado"` // Los anclajes esperan a que se recargue la cuenta
+	SaldoWei          string    `json:"saldoWei"`
+	SaldoEth          string    `json:"saldoEth"`
+	GastoDiarioWei    string    `json:"gastoDiarioWei"` // Gasto en gas de las últimas 24 horas
+	GastoDiarioEth    string    `json:"gastoDiarioEth"`
+	AnclajesUltimoDia int       `json:"anclajesUltimoDia"`
+	CostoAnclajeWei   string    `json:"costoAnclajeWei"` // Costo medio reciente; sin anclajes recientes, el máximo al gas price actual
+	CostoAnclajeEth   string    `json:"costoAnclajeEth"`
+	AnclajesRestantes int64     `json:"anclajesRestantes"` // Anclajes que paga el saldo al costo medio
+	AutonomiaHoras    *float64  `json:"autonomiaHoras"`    // Horas hasta agotar el saldo al ritmo de gasto actual; null sin gasto
+	Error             string    `json:"error,omitempty"`   // Error de la última consulta; los demás campos son de la anterior
+	ActualizadoEn     time.Time `json:"actualizadoEn"`
+}
+
+// AlertaFondos es el cuerpo del webhook de alertas que se envía cuando cambia el nivel de fondos
+type AlertaFondos struct {
+	Nivel         string       `json:"nivel"`
+	NivelAnterior string       `json:"nivelAnterior"`
+	Motivo        string       `json:"motivo"`
+	Fondos        EstadoFondos `json:"fondos"`
+	Fecha         time.Time    `json:"fecha"`
+}
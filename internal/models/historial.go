@@ -4,12 +4,13 @@ import "time"
 
 // HistorialVerificado representa el historial completo verificado de un producto
 type HistorialVerificado struct {
-	IDProducto    string             `json:"idProducto"`
-	TotalEventos  int                `json:"totalEventos"`
-	Verificados   int                `json:"verificados"`
-	NoVerificados int                `json:"noVerificados"`
-	Eventos       []EventoVerificado `json:"eventos"`
-	FechaConsulta time.Time          `json:"fechaConsulta"`
+	IDProducto    string              `json:"idProducto"`
+	TotalEventos  int                 `json:"totalEventos"`
+	Verificados   int                 `json:"verificados"`
+	NoVerificados int                 `json:"noVerificados"`
+	Eventos       []EventoVerificado  `json:"eventos"`
+	Cadena        *VerificacionCadena `json:"cadena"`
+	FechaConsulta time.Time           `json:"fechaConsulta"`
 }
 
 // EventoVerificado representa un evento individual verificado
@@ -21,9 +22,44 @@ type EventoVerificado struct {
 	ReferenciaBlockchain  string    `json:"referenciaBlockchain"`
 	IPFSCid               string    `json:"ipfsCid"`
 	ActorEmisor           string    `json:"actorEmisor"`
+	Secuencia             int64     `json:"secuencia,omitempty"`
+	HashEventoAnterior    string    `json:"hashEventoAnterior,omitempty"`
 	ErrorVerificacion     string    `json:"errorVerificacion,omitempty"`
 }
 
+// Tipos de anomalía detectados al verificar la cadena de eventos de un producto
+const (
+	AnomaliaHueco       = "hueco"       // Falta un número de secuencia intermedio
+	AnomaliaBifurcacion = "bifurcacion" // Dos eventos ocupan la misma posición o enlazan al mismo anterior
+	AnomaliaEliminacion = "eliminacion" // La cabeza registrada apunta más allá del último evento encontrado
+	AnomaliaRuptura     = "ruptura"     // El enlace al evento anterior no coincide con su hash
+)
+
+// CabezaCadena es el último eslabón registrado de la cadena de un producto
+type CabezaCadena struct {
+	IDProducto    string `json:"idProducto" dynamodbav:"idProducto"`
+	Secuencia     int64  `json:"secuencia" dynamodbav:"secuencia"`
+	HashEvento    string `json:"hashEvento" dynamodbav:"hashEvento"`
+	IDTransaction string `json:"idTransaction" dynamodbav:"idTransaction"`
+	Propietario   string `json:"propietario,omitempty" dynamodbav:"propietario,omitempty"` // Actor del primer evento encadenado
+}
+
+// AnomaliaCadena describe una inconsistencia encontrada en la cadena de un producto
+type AnomaliaCadena struct {
+	Tipo      string `json:"tipo"`
+	Secuencia int64  `json:"secuencia"`
+	IDEvento  string `json:"idEvento,omitempty"`
+	Detalle   string `json:"detalle"`
+}
+
+// VerificacionCadena es el resultado de verificar la cadena de hashes de un producto
+type VerificacionCadena struct {
+	Integra             bool             `json:"integra"`
+	Longitud            int64            `json:"longitud"`
+	EventosSinEncadenar int              `json:"eventosSinEncadenar"` // Eventos registrados antes del encadenamiento
+	Anomalias           []AnomaliaCadena `json:"anomalias"`
+}
+
 // OracleDataResponse representa los datos expuestos por el Oracle
 type OracleDataResponse struct {
 	IDProducto          string             `json:"idProducto"`
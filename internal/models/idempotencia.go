@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Estados de un registro de idempotencia
+const (
+	IdempotenciaEnCurso    = "en_curso"
+	IdempotenciaCompletada = "completada"
+)
+
+// RegistroIdempotencia guarda el resultado de la primera petición con una Idempotency-Key
+type RegistroIdempotencia struct {
+	Clave         string    `dynamodbav:"clave"`  // Hash del alcance (llamador) y la Idempotency-Key
+	Huella        string    `dynamodbav:"huella"` // SHA-256 de método, ruta y cuerpo de la petición original
+	Estado        string    `dynamodbav:"estado"`
+	StatusCode    int       `dynamodbav:"statusCode,omitempty"`
+	TipoContenido string    `dynamodbav:"tipoContenido,omitempty"`
+	Respuesta     []byte    `dynamodbav:"respuesta,omitempty"`
+	CreadoEn      time.Time `dynamodbav:"creadoEn"`
+	BloqueoHasta  int64     `dynamodbav:"bloqueoHasta"` // Epoch en ms; pasado este instante una petición en curso se considera abandonada
+	ExpiraEn      int64     `dynamodbav:"expiraEn"`     // Epoch en segundos; atributo TTL de DynamoDB
+}
+
+// Expirado indica si el registro ya no debe usarse (DynamoDB elimina los registros TTL con retraso)
+func (r *RegistroIdempotencia) Expirado(ahora time.Time) bool {
+	return r.ExpiraEn <= ahora.Unix()
+}
+
+// Abandonado indica si la petición original sigue en curso pasado su bloqueo
+func (r *RegistroIdempotencia) Abandonado(ahora time.Time) bool {
+	return r.Estado == IdempotenciaEnCurso && ahora.UnixMilli() > r.BloqueoHasta
+}
@@ -0,0 +1,28 @@
+package models
+
+// Estados de cada elemento de un lote
+const (
+	LoteRegistrada = "registrada"
+	LoteInvalida   = "invalida"
+	LoteFallida    = "fallida"
+)
+
+// ResultadoLote es el resultado de un elemento del lote, identificado por su posición en la petición
+type ResultadoLote struct {
+	Indice        int    `json:"indice"`
+	Estado        string `json:"estado"`
+	IDTransaction string `json:"idTransaction,omitempty"`
+	IDProducto    string `json:"idProducto,omitempty"`
+	HashEvento    string `json:"hashEvento,omitempty"`
+	IPFSCid       string `json:"ipfsCid,omitempty"`
+	Secuencia     int64  `json:"secuencia,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// LoteResponse resume un registro por lotes; los fallos son por elemento
+type LoteResponse struct {
+	Total       int             `json:"total"`
+	Registradas int             `json:"registradas"`
+	Fallidas    int             `json:"fallidas"`
+	Resultados  []ResultadoLote `json:"resultados"`
+}
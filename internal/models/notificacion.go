@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Tipos de notificación del stream de transacciones, en el orden en que los recorre una transacción
+const (
+	NotificacionRegistrada = "registrada" // Guardada en DynamoDB e IPFS, pendiente de anclaje
+	NotificacionAnclada    = "anclada"    // El hash quedó minado en blockchain
+	NotificacionConfirmada = "confirmada" // Estado "confirmado" persistido con los hashes de blockchain
+	NotificacionFallida    = "fallida"    // El anclaje en blockchain falló
+
+	// NotificacionEstadoOracle indica que cambió el estado verificado del producto en el Oracle
+	NotificacionEstadoOracle = "estado-oracle"
+)
+
+// Notificacion es un cambio de estado de una transacción publicado en el bus de eventos.
+// El ID es opaco y creciente dentro de una instancia; los clientes lo devuelven en Last-Event-ID para reanudar.
+type Notificacion struct {
+	ID                   string    `json:"id"`
+	Tipo                 string    `json:"tipo"`
+	IDTransaction        string    `json:"idTransaction"`
+	IDProducto           string    `json:"idProducto"`
+	TipoEvento           string    `json:"tipoEvento"`
+	ActorEmisor          string    `json:"actorEmisor"`
+	Estado               string    `json:"estado"`
+	Secuencia            int64     `json:"secuencia,omitempty"`
+	HashEvento           string    `json:"hashEvento,omitempty"`
+	IPFSCid              string    `json:"ipfsCid,omitempty"`
+	DirectionBlockchain  string    `json:"directionBlockchain,omitempty"`
+	EthereumTxHash       string    `json:"ethereumTxHash,omitempty"`
+	Mensaje              string    `json:"mensaje,omitempty"`
+	EstadoOracle         string    `json:"estadoOracle,omitempty"`         // Solo en estado-oracle: verificado o no_verificado
+	EstadoOracleAnterior string    `json:"estadoOracleAnterior,omitempty"` // Solo en estado-oracle; vacío si es el primer cálculo
+	Fecha                time.Time `json:"fecha"`
+}
+
+// NuevaNotificacion crea una notificación del tipo indicado con el estado actual de la transacción
+func NuevaNotificacion(tipo string, transaccion *Transaccion) Notificacion {
+	return Notificacion{
+		Tipo:                tipo,
+		IDTransaction:       transaccion.IDTransaction,
+		IDProducto:          transaccion.IDProducto,
+		TipoEvento:          transaccion.TipoEvento,
+		ActorEmisor:         transaccion.ActorEmisor,
+		Estado:              transaccion.Estado,
+		Secuencia:           transaccion.Secuencia,
+		HashEvento:          transaccion.HashEvento,
+		IPFSCid:             transaccion.IPFSCid,
+		DirectionBlockchain: transaccion.DirectionBlockchain,
+		EthereumTxHash:      transaccion.EthereumTxHash,
+	}
+}
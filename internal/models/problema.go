@@ -0,0 +1,31 @@
+package models
+
+// TipoContenidoProblema es el media type de las respuestas de error (RFC 7807)
+const TipoContenidoProblema = "application/problem+json"
+
+// prefijoTipoProblema forma el URI que identifica cada tipo de problema a partir de su código
+const prefijoTipoProblema = "urn:medisupply:problema:"
+
+// Problema es el cuerpo de las respuestas de error según RFC 7807. Codigo es estable y es el campo en que
+// deben basarse los clientes; Titulo y Detalle son texto para personas y pueden cambiar.
+type Problema struct {
+	Tipo       string          `json:"type"`
+	Titulo     string          `json:"title"`
+	Status     int             `json:"status"`
+	Detalle    string          `json:"detail,omitempty"`
+	Instancia  string          `json:"instance,omitempty"`
+	Codigo     string          `json:"codigo"`
+	Regla      string          `json:"regla,omitempty"`      // Regla de la política que denegó la petición (403)
+	Resultados []ResultadoLote `json:"resultados,omitempty"` // Resultados por elemento cuando se rechaza un lote (400)
+}
+
+// NuevoProblema crea un problema cuyo tipo se deriva del código
+func NuevoProblema(status int, codigo, titulo, detalle string) *Problema {
+	return &Problema{
+		Tipo:    prefijoTipoProblema + codigo,
+		Titulo:  titulo,
+		Status:  status,
+		Detalle: detalle,
+		Codigo:  codigo,
+	}
+}
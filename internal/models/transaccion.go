@@ -10,16 +10,33 @@ type Transaccion struct {
 	FechaEvento         time.Time `json:"fechaEvento" dynamodbav:"fechaEvento" validate:"required"`
 	DatosEvento         string    `json:"datosEvento" dynamodbav:"datosEvento" validate:"required"` // JSON string con datos completos
 	HashEvento          string    `json:"hashEvento" dynamodbav:"hashEvento"`
+	HashVersion         int       `json:"hashVersion" dynamodbav:"hashVersion,omitempty"` // Algoritmo usado para HashEvento (0 = registro anterior al versionado)
+	HashEventoAnterior  string    `json:"hashEventoAnterior,omitempty" dynamodbav:"hashEventoAnterior,omitempty"` // HashEvento del evento previo del mismo producto
+	Secuencia           int64     `json:"secuencia,omitempty" dynamodbav:"secuencia,omitempty"` // Posición en la cadena del producto (0 = evento sin encadenar)
 	DirectionBlockchain string    `json:"directionBlockchain" dynamodbav:"directionBlockchain"` // Hash lógico usado como clave en el contrato
 	EthereumTxHash      string    `json:"ethereumTxHash" dynamodbav:"ethereumTxHash"`           // Hash de la transacción de Ethereum para Etherscan
+	AnclajeTxHash       string    `json:"-" dynamodbav:"anclajeTxHash,omitempty"`                                 // Transacción de anclaje firmada, guardada antes de difundirla
+	AnclajeHashLogico   string    `json:"-" dynamodbav:"anclajeHashLogico,omitempty"`                             // Hash lógico de AnclajeTxHash
 	IPFSCid             string    `json:"ipfsCid" dynamodbav:"ipfsCid"` // CID de IPFS para off-chain storage
+	NodosIPFS           []string  `json:"nodosIPFS,omitempty" dynamodbav:"nodosIPFS,omitempty"` // Destinos IPFS donde está pineado el CID
 	ActorEmisor         string    `json:"actorEmisor" dynamodbav:"actorEmisor" validate:"required"`
+	Adjuntos            []Adjunto `json:"adjuntos,omitempty" dynamodbav:"adjuntos,omitempty"` // Documentos binarios almacenados en IPFS
 	Estado              string    `json:"estado" dynamodbav:"estado" validate:"required,oneof=pendiente confirmado fallido"`
 	FirmaDigital        string    `json:"firmaDigital" dynamodbav:"firmaDigital"`
 	CreatedAt           time.Time `json:"createdAt" dynamodbav:"createdAt"`
 	UpdatedAt           time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
 }
 
+// Adjunto representa un documento binario (certificado, reporte de laboratorio) asociado a un evento
+type Adjunto struct {
+	CID        string   `json:"cid" dynamodbav:"cid"`
+	Nombre     string   `json:"nombre" dynamodbav:"nombre"`
+	TipoMIME   string   `json:"tipoMime" dynamodbav:"tipoMime"`
+	Tamano     int64    `json:"tamano" dynamodbav:"tamano"`
+	HashSHA256 string   `json:"hashSha256" dynamodbav:"hashSha256"` // Hash del contenido, incluido en el hash de integridad
+	NodosIPFS  []string `json:"nodosIPFS,omitempty" dynamodbav:"nodosIPFS,omitempty"`
+}
+
 // TransaccionRequest representa el payload de creación de transacción
 type TransaccionRequest struct {
 	TipoEvento  string `json:"tipoEvento" validate:"required,oneof=fabricacion distribucion recepcion verificacion"`
@@ -35,10 +52,14 @@ type TransaccionResponse struct {
 	IDProducto          string    `json:"idProducto"`
 	FechaEvento         time.Time `json:"fechaEvento"`
 	HashEvento          string    `json:"hashEvento"`
+	HashVersion         int       `json:"hashVersion"`
+	HashEventoAnterior  string    `json:"hashEventoAnterior,omitempty"`
+	Secuencia           int64     `json:"secuencia,omitempty"`
 	DirectionBlockchain string    `json:"directionBlockchain"`
 	EthereumTxHash      string    `json:"ethereumTxHash"`
 	IPFSCid             string    `json:"ipfsCid"`
 	ActorEmisor         string    `json:"actorEmisor"`
+	Adjuntos            []Adjunto `json:"adjuntos,omitempty"`
 	Estado              string    `json:"estado"`
 	CreatedAt           time.Time `json:"createdAt"`
 }
@@ -50,6 +71,7 @@ type VerificacionResponse struct {
 	HashLocal            string `json:"hashLocal"`
 	HashBlockchain       string `json:"hashBlockchain"`
 	DatosIPFSVerificados bool   `json:"datosIPFSVerificados"`
+	AdjuntosVerificados  bool   `json:"adjuntosVerificados"`
 	Mensaje              string `json:"mensaje"`
 }
 
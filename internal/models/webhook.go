@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook es una suscripción de un sistema externo a las notificaciones de transacciones.
+// Los filtros de distinto tipo se combinan con Y; dentro de un filtro basta con un valor. Vacío = cualquiera.
+type Webhook struct {
+	ID             string    `json:"id" dynamodbav:"id"`
+	URL            string    `json:"url" dynamodbav:"url"`
+	Descripcion    string    `json:"descripcion,omitempty" dynamodbav:"descripcion,omitempty"`
+	Productos      []string  `json:"productos" dynamodbav:"productos"`
+	Actores        []string  `json:"actores" dynamodbav:"actores"`
+	TiposEvento    []string  `json:"tiposEvento" dynamodbav:"tiposEvento"`
+	Estados        []string  `json:"estados" dynamodbav:"estados"` // Tipos de notificación: registrada, anclada, confirmada, fallida, estado-oracle
+	Activo         bool      `json:"activo" dynamodbav:"activo"`
+	Propietario    string    `json:"propietario" dynamodbav:"propietario"` // Actor que lo creó; solo él y los admin lo ven
+	SecretoCifrado string    `json:"-" dynamodbav:"secreto"`               // Secreto HMAC cifrado con ENCRYPTION_KEY
+	CreadoEn       time.Time `json:"creadoEn" dynamodbav:"creadoEn"`
+	ActualizadoEn  time.Time `json:"actualizadoEn" dynamodbav:"actualizadoEn"`
+}
+
+// Coincide indica si la notificación pasa los filtros del webhook
+func (w *Webhook) Coincide(n *Notificacion) bool {
+	return coincideFiltro(w.Productos, n.IDProducto) &&
+		coincideFiltro(w.Actores, n.ActorEmisor) &&
+		coincideFiltro(w.TiposEvento, n.TipoEvento) &&
+		coincideFiltro(w.Estados, n.Tipo)
+}
+
+func coincideFiltro(valores []string, valor string) bool {
+	if len(valores) == 0 {
+		return true
+	}
+	for _, v := range valores {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookRequest es el payload para crear o reemplazar un webhook
+type WebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Descripcion string   `json:"descripcion"`
+	Productos   []string `json:"productos"`
+	Actores     []string `json:"actores"`
+	TiposEvento []string `json:"tiposEvento" validate:"dive,oneof=fabricacion distribucion recepcion verificacion"`
+	Estados     []string `json:"estados" validate:"dive,oneof=registrada anclada confirmada fallida estado-oracle"`
+	Activo      *bool    `json:"activo"` // nil = activo
+}
+
+// WebhookCreadoResponse incluye el secreto de firma; solo se muestra al crear el webhook
+type WebhookCreadoResponse struct {
+	Secreto string   `json:"secreto"`
+	Webhook *Webhook `json:"webhook"`
+}
+
+// Estados de una entrega de webhook
+const (
+	EntregaPendiente = "pendiente" // En cola, a la espera de su próximo intento
+	EntregaEntregada = "entregada" // El receptor respondió 2xx
+	EntregaMuerta    = "muerta"    // Agotó los reintentos (dead-letter); se puede reenviar
+)
+
+// IntentoEntrega es un intento de envío registrado en el log de entregas
+type IntentoEntrega struct {
+	Numero     int       `json:"numero" dynamodbav:"numero"`
+	Fecha      time.Time `json:"fecha" dynamodbav:"fecha"`
+	StatusCode int       `json:"statusCode,omitempty" dynamodbav:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	DuracionMs int64     `json:"duracionMs" dynamodbav:"duracionMs"`
+}
+
+// EntregaWebhook es una notificación en la cola de entrega de un webhook
+type EntregaWebhook struct {
+	ID             string           `json:"id" dynamodbav:"id"`
+	WebhookID      string           `json:"webhookId" dynamodbav:"webhookId"`
+	Tipo           string           `json:"tipo" dynamodbav:"tipo"`
+	IDNotificacion string           `json:"idNotificacion" dynamodbav:"idNotificacion"`
+	Estado         string           `json:"estado" dynamodbav:"estado"`
+	Intento        int              `json:"intento" dynamodbav:"intento"` // Intentos desde el encolado o el último reenvío
+	Intentos       []IntentoEntrega `json:"intentos" dynamodbav:"intentos"`
+	ProximoIntento time.Time        `json:"proximoIntento" dynamodbav:"proximoIntento,unixtime"`
+	Payload        json.RawMessage  `json:"payload" dynamodbav:"payload"`
+	CreadaEn       time.Time        `json:"creadaEn" dynamodbav:"creadaEn"`
+	ActualizadaEn  time.Time        `json:"actualizadaEn" dynamodbav:"actualizadaEn"`
+	BloqueoHasta   int64            `json:"-" dynamodbav:"bloqueoHasta"` // Epoch en ms; una réplica la está enviando hasta este instante
+	ExpiraEn       int64            `json:"-" dynamodbav:"expiraEn"`     // Epoch en segundos; atributo TTL de DynamoDB
+}
+
+// EventoWebhook es el cuerpo firmado que recibe el receptor
+type EventoWebhook struct {
+	ID        string       `json:"id"` // ID de la entrega: igual en todos los reintentos, sirve para deduplicar
+	WebhookID string       `json:"webhookId"`
+	Tipo      string       `json:"tipo"`
+	Fecha     time.Time    `json:"fecha"`
+	Datos     Notificacion `json:"datos"`
+}
@@ -0,0 +1,288 @@
+// Package policy evalúa la política de autorización declarativa: qué roles pueden usar cada
+// ruta, emitir cada tipo de evento y sobre qué productos.
+package policy
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// politicaEmbebida se usa cuando no se configura POLICY_FILE
+//
+//go:embed politica.yaml
+var politicaEmbebida []byte
+
+// ReglaPorDefecto es el nombre reportado cuando ninguna regla cubre la petición
+const ReglaPorDefecto = "denegar-por-defecto"
+
+// ReglaRuta indica qué roles pueden invocar un conjunto de rutas
+type ReglaRuta struct {
+	Nombre  string   `yaml:"nombre"`
+	Metodos []string `yaml:"metodos"` // "*" = cualquier método
+	Rutas   []string `yaml:"rutas"`   // Patrón de Gin; "/x/*" cubre /x y todo lo que cuelga de /x
+	Roles   []string `yaml:"roles"`
+}
+
+// ReglaEvento indica qué roles pueden emitir un conjunto de tipos de evento
+type ReglaEvento struct {
+	Nombre      string   `yaml:"nombre"`
+	TiposEvento []string `yaml:"tiposEvento"`
+	Roles       []string `yaml:"roles"`
+}
+
+// ReglaPropiedad obliga a los roles listados a ser propietarios del producto para emitir los eventos
+type ReglaPropiedad struct {
+	Nombre      string   `yaml:"nombre"`
+	TiposEvento []string `yaml:"tiposEvento"`
+	Roles       []string `yaml:"roles"`
+}
+
+// Politica es el contenido del archivo de política
+type Politica struct {
+	Rutas     []ReglaRuta      `yaml:"rutas"`
+	Eventos   []ReglaEvento    `yaml:"eventos"`
+	Propiedad []ReglaPropiedad `yaml:"propiedad"`
+}
+
+// Denegacion es el error retornado cuando una regla no autoriza la petición
+type Denegacion struct {
+	Regla  string
+	Motivo string
+	motivo *i18n.Error // Motivo en el catálogo; nil si la denegación se creó con un Motivo literal
+}
+
+// NuevaDenegacion crea una denegación cuyo motivo es el mensaje de la clave del catálogo
+func NuevaDenegacion(regla, clave string, args ...any) *Denegacion {
+	motivo := i18n.NuevoError(clave, args...)
+	return &Denegacion{Regla: regla, Motivo: motivo.Error(), motivo: motivo}
+}
+
+func (d *Denegacion) Error() string {
+	return d.Traducir(i18n.IdiomaPorDefecto)
+}
+
+// MotivoEn retorna el motivo en el idioma indicado
+func (d *Denegacion) MotivoEn(idioma i18n.Idioma) string {
+	if d.motivo == nil {
+		return d.Motivo
+	}
+	return d.motivo.Traducir(idioma)
+}
+
+// Traducir muestra la denegación en el idioma indicado
+func (d *Denegacion) Traducir(idioma i18n.Idioma) string {
+	return i18n.Traducir(idioma, "politica.denegacion", d.Regla, d.MotivoEn(idioma))
+}
+
+// Motor evalúa la política vigente; la política se puede recargar sin reiniciar el servicio
+type Motor struct {
+	archivo    string
+	politica   atomic.Pointer[Politica]
+	mu         sync.Mutex // Serializa las recargas
+	modificado time.Time
+}
+
+// NewMotor carga la política del archivo, o la política embebida si archivo está vacío
+func NewMotor(archivo string) (*Motor, error) {
+	m := &Motor{archivo: archivo}
+	if err := m.Recargar(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Recargar vuelve a leer el archivo de política. Si el archivo nuevo es inválido se conserva la política vigente.
+func (m *Motor) Recargar() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	contenido := politicaEmbebida
+	if m.archivo != "" {
+		info, err := os.Stat(m.archivo)
+		if err != nil {
+			return fmt.Errorf("error leyendo política %s: %w", m.archivo, err)
+		}
+		contenido, err = os.ReadFile(m.archivo)
+		if err != nil {
+			return fmt.Errorf("error leyendo política %s: %w", m.archivo, err)
+		}
+		m.modificado = info.ModTime()
+	}
+
+	politica, err := ParsearPolitica(contenido)
+	if err != nil {
+		return err
+	}
+	m.politica.Store(politica)
+	return nil
+}
+
+// Vigilar recarga la política cada vez que cambia la fecha de modificación del archivo,
+// hasta que el contexto se cancele
+func (m *Motor) Vigilar(ctx context.Context, intervalo time.Duration) {
+	if m.archivo == "" || intervalo <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(m.archivo)
+			if err != nil {
+				slog.Warn("política: no se pudo leer el archivo", "archivo", m.archivo, "error", err)
+				continue
+			}
+			m.mu.Lock()
+			cambio := !info.ModTime().Equal(m.modificado)
+			m.mu.Unlock()
+			if !cambio {
+				continue
+			}
+			if err := m.Recargar(); err != nil {
+				slog.Error("política: se conserva la política vigente", "archivo", m.archivo, "error", err)
+				continue
+			}
+			slog.Info("política recargada", "archivo", m.archivo)
+		}
+	}
+}
+
+// ParsearPolitica decodifica y valida un documento de política YAML
+func ParsearPolitica(contenido []byte) (*Politica, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(contenido))
+	decoder.KnownFields(true)
+
+	var politica Politica
+	if err := decoder.Decode(&politica); err != nil {
+		return nil, fmt.Errorf("política inválida: %w", err)
+	}
+
+	nombres := make(map[string]bool)
+	validarNombre := func(nombre string, roles []string) error {
+		if nombre == "" {
+			return fmt.Errorf("política inválida: hay una regla sin nombre")
+		}
+		if nombres[nombre] {
+			return fmt.Errorf("política inválida: la regla '%s' está duplicada", nombre)
+		}
+		nombres[nombre] = true
+		if len(roles) == 0 {
+			return fmt.Errorf("política inválida: la regla '%s' no tiene roles", nombre)
+		}
+		return nil
+	}
+
+	for _, regla := range politica.Rutas {
+		if err := validarNombre(regla.Nombre, regla.Roles); err != nil {
+			return nil, err
+		}
+		if len(regla.Metodos) == 0 || len(regla.Rutas) == 0 {
+			return nil, fmt.Errorf("política inválida: la regla '%s' requiere métodos y rutas", regla.Nombre)
+		}
+	}
+	for _, regla := range politica.Eventos {
+		if err := validarNombre(regla.Nombre, regla.Roles); err != nil {
+			return nil, err
+		}
+		if len(regla.TiposEvento) == 0 {
+			return nil, fmt.Errorf("política inválida: la regla '%s' requiere tiposEvento", regla.Nombre)
+		}
+	}
+	for _, regla := range politica.Propiedad {
+		if err := validarNombre(regla.Nombre, regla.Roles); err != nil {
+			return nil, err
+		}
+	}
+
+	return &politica, nil
+}
+
+// AutorizarRuta decide si el principal puede invocar el método sobre la ruta (patrón de Gin)
+func (m *Motor) AutorizarRuta(principal *models.Principal, metodo, ruta string) error {
+	for _, regla := range m.politica.Load().Rutas {
+		if !coincide(regla.Metodos, metodo) || !coincideRuta(regla.Rutas, ruta) {
+			continue
+		}
+		if principal.TieneRol(regla.Roles...) {
+			return nil
+		}
+		return NuevaDenegacion(regla.Nombre, "politica.ruta-requiere-roles", metodo, ruta, strings.Join(regla.Roles, ", "))
+	}
+	return NuevaDenegacion(ReglaPorDefecto, "politica.ruta-sin-regla", metodo, ruta)
+}
+
+// AutorizarEvento decide si el principal puede emitir el tipo de evento
+func (m *Motor) AutorizarEvento(principal *models.Principal, tipoEvento string) error {
+	for _, regla := range m.politica.Load().Eventos {
+		if !coincide(regla.TiposEvento, tipoEvento) {
+			continue
+		}
+		if principal.TieneRol(regla.Roles...) {
+			return nil
+		}
+		return NuevaDenegacion(regla.Nombre, "politica.evento-requiere-roles", tipoEvento, strings.Join(regla.Roles, ", "))
+	}
+	return NuevaDenegacion(ReglaPorDefecto, "politica.evento-sin-regla", tipoEvento)
+}
+
+// ReglaPropiedadAplicable retorna la regla de propiedad que aplica al principal y al tipo de evento, o nil
+func (m *Motor) ReglaPropiedadAplicable(principal *models.Principal, tipoEvento string) *ReglaPropiedad {
+	for _, regla := range m.politica.Load().Propiedad {
+		if coincide(regla.TiposEvento, tipoEvento) && principal.TieneRol(regla.Roles...) {
+			regla := regla
+			return &regla
+		}
+	}
+	return nil
+}
+
+// AutorizarPropiedad decide si el principal puede emitir el evento sobre un producto cuyo propietario
+// es propietario. Un producto sin propietario (sin eventos encadenados) puede ser reclamado por cualquiera.
+func (m *Motor) AutorizarPropiedad(principal *models.Principal, tipoEvento, idProducto, propietario string) error {
+	regla := m.ReglaPropiedadAplicable(principal, tipoEvento)
+	if regla == nil || propietario == "" || propietario == principal.Actor {
+		return nil
+	}
+	return NuevaDenegacion(regla.Nombre, "politica.producto-de-otro-actor", idProducto)
+}
+
+// coincide indica si el valor está en la lista o la lista contiene "*"
+func coincide(lista []string, valor string) bool {
+	for _, elemento := range lista {
+		if elemento == "*" || strings.EqualFold(elemento, valor) {
+			return true
+		}
+	}
+	return false
+}
+
+// coincideRuta compara la ruta con los patrones; "/x/*" cubre /x y sus subrutas
+func coincideRuta(patrones []string, ruta string) bool {
+	for _, patron := range patrones {
+		if patron == "*" || patron == ruta {
+			return true
+		}
+		if base, ok := strings.CutSuffix(patron, "/*"); ok && (ruta == base || strings.HasPrefix(ruta, base+"/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Contador es un almacén de contadores atómicos compartido entre réplicas. DynamoDBService lo
+// implementa sobre la tabla de control.
+type Contador interface {
+	// IncrementarContador suma n al contador de la clave (creándolo en 0 si no existe) y retorna el
+	// valor resultante. El almacén puede descartar el contador a partir de expira.
+	IncrementarContador(ctx context.Context, clave string, n int, expira time.Time) (int64, error)
+}
+
+// intervaloAviso limita los avisos de almacén no disponible a uno por minuto
+const intervaloAviso = time.Minute
+
+// Compartido es un Limitador cuyas cuentas viven en un Contador compartido, de modo que el cupo de un
+// cliente es el mismo sin importar qué réplica atienda cada petición. Si el almacén falla, la petición
+// se cuenta en un limitador en memoria: el cupo pasa a ser por réplica en vez de dejar la API sin límite
+// o rechazar todo el tráfico.
+type Compartido struct {
+	contador Contador
+	local    *Memoria
+
+	mu             sync.Mutex
+	ultimoAviso    time.Time
+	avisosOmitidos int
+}
+
+// NewCompartido crea un limitador sobre un almacén de contadores compartido
+func NewCompartido(contador Contador) *Compartido {
+	return &Compartido{contador: contador, local: NewMemoria()}
+}
+
+// Consumir implementa Limitador
+func (s *Compartido) Consumir(ctx context.Context, clave string, cuota Cuota, n int) (Decision, error) {
+	ahora := time.Now()
+	inicio, fin := ventanaActual(ahora, cuota.Ventana)
+
+	cuenta, err := s.contador.IncrementarContador(ctx, fmt.Sprintf("%s#%d", clave, inicio.Unix()), n, fin)
+	if err != nil {
+		s.avisar(ctx, err)
+		return s.local.Consumir(ctx, clave, cuota, n)
+	}
+	return decidir(cuota, cuenta, n, ahora, fin), nil
+}
+
+// avisar registra la caída del almacén como mucho una vez por intervaloAviso
+func (s *Compartido) avisar(ctx context.Context, err error) {
+	s.mu.Lock()
+	if time.Since(s.ultimoAviso) < intervaloAviso {
+		s.avisosOmitidos++
+		s.mu.Unlock()
+		return
+	}
+	omitidos := s.avisosOmitidos
+	s.ultimoAviso = time.Now()
+	s.avisosOmitidos = 0
+	s.mu.Unlock()
+
+	slog.WarnContext(ctx, "rate limiting: almacén compartido no disponible, se limita por réplica", "error", err, "fallos_omitidos", omitidos)
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cuotas define cuántas peticiones admite cada llamador por ventana. Todas comparten la ventana de
+// PorDefecto.
+type Cuotas struct {
+	PorDefecto Cuota
+	Roles      map[string]int // Límite de los principales con el rol; con varios roles se aplica el mayor
+	Rutas      map[string]int // Límite adicional por "MÉTODO /ruta" (ruta de Gin, p. ej. /api/v1/transaccion/:id)
+}
+
+// NewCuotas construye las cuotas a partir de la configuración: roles con el formato "admin=1000,auditor=50"
+// y rutas con el formato "POST /api/v1/transaccion/lote=10,GET /api/v1/oracle/validar/:id=30"
+func NewCuotas(limite int, ventana time.Duration, roles, rutas string) (Cuotas, error) {
+	if limite <= 0 || ventana <= 0 {
+		return Cuotas{}, fmt.Errorf("el límite y la ventana deben ser mayores que 0")
+	}
+	cuotas := Cuotas{PorDefecto: Cuota{Limite: limite, Ventana: ventana}}
+
+	var err error
+	if cuotas.Roles, err = parsearLimites(roles, validarRol); err != nil {
+		return Cuotas{}, err
+	}
+	if cuotas.Rutas, err = parsearLimites(rutas, validarRuta); err != nil {
+		return Cuotas{}, err
+	}
+	return cuotas, nil
+}
+
+// DeRoles retorna la cuota de un principal: la mayor de las configuradas para sus roles, o la cuota
+// por defecto si ninguno tiene una propia
+func (c Cuotas) DeRoles(roles []string) Cuota {
+	limite := 0
+	for _, rol := range roles {
+		if propio, ok := c.Roles[rol]; ok && propio > limite {
+			limite = propio
+		}
+	}
+	if limite == 0 {
+		return c.PorDefecto
+	}
+	return Cuota{Limite: limite, Ventana: c.PorDefecto.Ventana}
+}
+
+// DeRuta retorna la cuota adicional de una ruta, si tiene una
+func (c Cuotas) DeRuta(metodo, ruta string) (Cuota, bool) {
+	limite, ok := c.Rutas[metodo+" "+ruta]
+	if !ok {
+		return Cuota{}, false
+	}
+	return Cuota{Limite: limite, Ventana: c.PorDefecto.Ventana}, true
+}
+
+// parsearLimites interpreta una lista "nombre=límite,..." validando cada nombre
+func parsearLimites(valor string, validar func(string) (string, error)) (map[string]int, error) {
+	limites := make(map[string]int)
+	for _, entrada := range strings.Split(valor, ",") {
+		entrada = strings.TrimSpace(entrada)
+		if entrada == "" {
+			continue
+		}
+		separador := strings.LastIndex(entrada, "=")
+		if separador < 0 {
+			return nil, fmt.Errorf("'%s' no tiene el formato nombre=límite", entrada)
+		}
+		nombre, err := validar(strings.TrimSpace(entrada[:separador]))
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %w", entrada, err)
+		}
+		limite, err := strconv.Atoi(strings.TrimSpace(entrada[separador+1:]))
+		if err != nil || limite <= 0 {
+			return nil, fmt.Errorf("'%s': el límite debe ser un entero mayor que 0", entrada)
+		}
+		if _, repetido := limites[nombre]; repetido {
+			return nil, fmt.Errorf("'%s' está repetido", nombre)
+		}
+		limites[nombre] = limite
+	}
+	return limites, nil
+}
+
+func validarRol(rol string) (string, error) {
+	if rol == "" || strings.ContainsAny(rol, " \t") {
+		return "", fmt.Errorf("rol inválido")
+	}
+	return rol, nil
+}
+
+// validarRuta normaliza "post  /ruta" a "POST /ruta"
+func validarRuta(ruta string) (string, error) {
+	campos := strings.Fields(ruta)
+	if len(campos) != 2 || !strings.HasPrefix(campos[1], "/") {
+		return "", fmt.Errorf("la ruta debe tener el formato 'MÉTODO /ruta'")
+	}
+	return strings.ToUpper(campos[0]) + " " + campos[1], nil
+}
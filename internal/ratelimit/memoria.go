@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// intervaloLimpieza es cada cuánto Consumir descarta las ventanas vencidas
+const intervaloLimpieza = time.Minute
+
+// ventanaMemoria es la cuenta de una clave en su ventana actual
+type ventanaMemoria struct {
+	cuenta int64
+	fin    time.Time
+}
+
+// Memoria es un Limitador en memoria, válido para una sola réplica. Las claves sin peticiones en su
+// ventana actual se descartan, así que la memoria usada es proporcional a los clientes activos.
+type Memoria struct {
+	mu             sync.Mutex
+	ventanas       map[string]*ventanaMemoria
+	ultimaLimpieza time.Time
+}
+
+// NewMemoria crea un limitador en memoria
+func NewMemoria() *Memoria {
+	return &Memoria{ventanas: make(map[string]*ventanaMemoria), ultimaLimpieza: time.Now()}
+}
+
+// Consumir implementa Limitador
+func (m *Memoria) Consumir(_ context.Context, clave string, cuota Cuota, n int) (Decision, error) {
+	ahora := time.Now()
+	_, fin := ventanaActual(ahora, cuota.Ventana)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ahora.Sub(m.ultimaLimpieza) >= intervaloLimpieza {
+		m.limpiar(ahora)
+	}
+
+	ventana, ok := m.ventanas[clave]
+	if !ok || !ventana.fin.Equal(fin) {
+		ventana = &ventanaMemoria{fin: fin}
+		m.ventanas[clave] = ventana
+	}
+	ventana.cuenta += int64(n)
+	return decidir(cuota, ventana.cuenta, n, ahora, fin), nil
+}
+
+// Limpiar descarta las ventanas vencidas y retorna cuántas descartó. Consumir la invoca como mucho
+// una vez por minuto, así que no hace falta una goroutine de limpieza.
+func (m *Memoria) Limpiar() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limpiar(time.Now())
+}
+
+func (m *Memoria) limpiar(ahora time.Time) int {
+	descartadas := 0
+	for clave, ventana := range m.ventanas {
+		if !ahora.Before(ventana.fin) {
+			delete(m.ventanas, clave)
+			descartadas++
+		}
+	}
+	m.ultimaLimpieza = ahora
+	return descartadas
+}
+
+// Entradas retorna el número de claves con ventana en memoria
+func (m *Memoria) Entradas() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.ventanas)
+}
@@ -0,0 +1,72 @@
+// Package ratelimit implementa los limitadores de tasa de la API: cupos de peticiones por ventana fija,
+// en memoria (una réplica) o compartidos entre réplicas a través de un almacén de contadores.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Cuota es el número de peticiones admitidas por ventana
+type Cuota struct {
+	Limite  int
+	Ventana time.Duration
+}
+
+// Decision es el resultado de consumir una cuota; alimenta las cabeceras RateLimit-*
+type Decision struct {
+	Permitida bool
+	Limite    int
+	Restantes int
+	Reinicio  time.Duration // Tiempo hasta que empieza la siguiente ventana
+	Ventana   time.Duration
+}
+
+// Limitador cuenta peticiones por clave en ventanas fijas alineadas a la época Unix, de modo que todas
+// las réplicas que comparten almacén usan las mismas ventanas.
+type Limitador interface {
+	// Consumir suma n peticiones a la ventana actual de la clave. Con n=0 solo consulta: la decisión
+	// indica si queda cupo para una petición más, sin consumirlo.
+	Consumir(ctx context.Context, clave string, cuota Cuota, n int) (Decision, error)
+}
+
+// ventanaActual retorna el inicio y el fin de la ventana que contiene ahora
+func ventanaActual(ahora time.Time, ventana time.Duration) (time.Time, time.Time) {
+	inicio := time.Unix(0, ahora.UnixNano()-ahora.UnixNano()%int64(ventana))
+	return inicio, inicio.Add(ventana)
+}
+
+// decidir calcula la decisión a partir de la cuenta de la ventana después de sumar n
+func decidir(cuota Cuota, cuenta int64, n int, ahora, fin time.Time) Decision {
+	permitida := cuenta <= int64(cuota.Limite)
+	if n == 0 {
+		permitida = cuenta < int64(cuota.Limite)
+	}
+	restantes := int64(cuota.Limite) - cuenta
+	if restantes < 0 {
+		restantes = 0
+	}
+	return Decision{
+		Permitida: permitida,
+		Limite:    cuota.Limite,
+		Restantes: int(restantes),
+		Reinicio:  fin.Sub(ahora),
+		Ventana:   cuota.Ventana,
+	}
+}
+
+// MasRestrictiva retorna la decisión que debe informarse cuando una petición consume varias cuotas:
+// la que la rechaza o, si todas la permiten, la que deja menos peticiones restantes
+func MasRestrictiva(a, b Decision) Decision {
+	switch {
+	case a.Permitida != b.Permitida:
+		if !a.Permitida {
+			return a
+		}
+		return b
+	case b.Restantes < a.Restantes:
+		return b
+	default:
+		return a
+	}
+}
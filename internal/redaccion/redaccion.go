@@ -0,0 +1,285 @@
+// Package redaccion enmascara datos sensibles antes de que lleguen a los logs o a los errores que se
+// responden a los clientes: los secretos configurados en el proceso (clave privada, claves de cifrado y
+// de firma, tokens), las credenciales emitidas por la API (API keys, JWT) y los datos personales dentro
+// de DatosEvento, ubicados con rutas JSON configurables.
+package redaccion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Marcador reemplaza cada valor enmascarado
+const Marcador = "[REDACTADO]"
+
+// longitudMinimaSecreto evita enmascarar valores tan cortos que aparecerían en cualquier texto
+const longitudMinimaSecreto = 8
+
+// patronesCredenciales reconocen credenciales emitidas por la API aunque no estén configuradas:
+// API keys (msk_<id>_<secreto>, se conserva el id), secretos de webhooks (whsec_...) y JWT
+var patronesCredenciales = []struct {
+	patron     *regexp.Regexp
+	reemplazar string
+}{
+	{regexp.MustCompile(`msk_([0-9a-f]+)_[A-Za-z0-9_-]+`), "msk_${1}_" + Marcador},
+	{regexp.MustCompile(`whsec_[A-Za-z0-9_-]+`), "whsec_" + Marcador},
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Marcador},
+}
+
+// Config indica qué enmascarar además de las credenciales con formato conocido
+type Config struct {
+	Secretos  []string // Valores literales que nunca deben aparecer (ver SecretosDe)
+	RutasJSON []string // Rutas con datos personales dentro de DatosEvento, p. ej. paciente.nombre o **.email
+}
+
+// Redactor enmascara textos y documentos JSON. Es inmutable y seguro para uso concurrente.
+type Redactor struct {
+	secretos []string
+	rutas    [][]string
+}
+
+// New crea un Redactor. Las rutas se separan por puntos; * coincide con una propiedad cualquiera y
+// ** con cero o más niveles. Los arreglos son transparentes: items.lote coincide con items[i].lote.
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{}
+	vistos := make(map[string]bool)
+	for _, secreto := range cfg.Secretos {
+		for _, variante := range []string{secreto, strings.TrimPrefix(secreto, "0x")} {
+			if len(variante) >= longitudMinimaSecreto && !vistos[variante] {
+				vistos[variante] = true
+				r.secretos = append(r.secretos, variante)
+			}
+		}
+	}
+	// Los más largos primero: un secreto que contiene a otro se enmascara completo
+	sort.Slice(r.secretos, func(i, j int) bool { return len(r.secretos[i]) > len(r.secretos[j]) })
+
+	for _, ruta := range cfg.RutasJSON {
+		ruta = strings.TrimPrefix(strings.TrimSpace(ruta), "$.")
+		if ruta == "" {
+			continue
+		}
+		segmentos := strings.Split(ruta, ".")
+		for _, segmento := range segmentos {
+			if segmento == "" {
+				return nil, fmt.Errorf("ruta de redacción '%s' inválida: segmento vacío", ruta)
+			}
+		}
+		r.rutas = append(r.rutas, segmentos)
+	}
+	return r, nil
+}
+
+// Texto enmascara los secretos configurados y las credenciales con formato conocido dentro de texto
+func (r *Redactor) Texto(texto string) string {
+	for _, secreto := range r.secretos {
+		texto = strings.ReplaceAll(texto, secreto, Marcador)
+	}
+	for _, credencial := range patronesCredenciales {
+		texto = credencial.patron.ReplaceAllString(texto, credencial.reemplazar)
+	}
+	return texto
+}
+
+// JSON enmascara los valores de las rutas configuradas dentro de un documento JSON y aplica Texto al
+// resto. Si el documento no es JSON válido no se pueden ubicar los datos personales y se enmascara completo.
+func (r *Redactor) JSON(documento string) string {
+	decoder := json.NewDecoder(strings.NewReader(documento))
+	decoder.UseNumber()
+	var valor any
+	if err := decoder.Decode(&valor); err != nil {
+		return Marcador
+	}
+	var salida bytes.Buffer
+	encoder := json.NewEncoder(&salida)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.recorrer(valor, nil)); err != nil {
+		return Marcador
+	}
+	return strings.TrimSuffix(salida.String(), "\n")
+}
+
+// recorrer enmascara los valores cuya ruta coincide con alguna de las configuradas
+func (r *Redactor) recorrer(valor any, ruta []string) any {
+	switch v := valor.(type) {
+	case map[string]any:
+		for clave, hijo := range v {
+			rutaHijo := append(ruta[:len(ruta):len(ruta)], clave)
+			if r.rutaSensible(rutaHijo) {
+				v[clave] = Marcador
+			} else {
+				v[clave] = r.recorrer(hijo, rutaHijo)
+			}
+		}
+		return v
+	case []any:
+		for i, elemento := range v {
+			v[i] = r.recorrer(elemento, ruta)
+		}
+		return v
+	case string:
+		return r.Texto(v)
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) rutaSensible(ruta []string) bool {
+	for _, patron := range r.rutas {
+		if coincide(patron, ruta) {
+			return true
+		}
+	}
+	return false
+}
+
+// coincide compara una ruta con un patrón de segmentos con comodines * y **
+func coincide(patron, ruta []string) bool {
+	if len(patron) == 0 {
+		return len(ruta) == 0
+	}
+	if patron[0] == "**" {
+		for i := 0; i <= len(ruta); i++ {
+			if coincide(patron[1:], ruta[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(ruta) == 0 || (patron[0] != "*" && !strings.EqualFold(patron[0], ruta[0])) {
+		return false
+	}
+	return coincide(patron[1:], ruta[1:])
+}
+
+// SecretosDe retorna los valores no vacíos de los campos string de una estructura (o puntero a
+// estructura) marcados con la etiqueta `sensible:"true"`
+func SecretosDe(estructura any) []string {
+	valor := reflect.Indirect(reflect.ValueOf(estructura))
+	if valor.Kind() != reflect.Struct {
+		return nil
+	}
+	var secretos []string
+	for i := 0; i < valor.NumField(); i++ {
+		campo := valor.Type().Field(i)
+		if campo.Tag.Get("sensible") != "true" || campo.Type.Kind() != reflect.String {
+			continue
+		}
+		if secreto := valor.Field(i).String(); secreto != "" {
+			secretos = append(secretos, secreto)
+		}
+	}
+	return secretos
+}
+
+// actual es el Redactor del proceso; sin Configurar solo enmascara credenciales con formato conocido
+var actual atomic.Pointer[Redactor]
+
+func init() {
+	actual.Store(&Redactor{})
+}
+
+// Configurar reemplaza el Redactor del proceso
+func Configurar(cfg Config) error {
+	r, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	actual.Store(r)
+	return nil
+}
+
+// Actual retorna el Redactor del proceso
+func Actual() *Redactor {
+	return actual.Load()
+}
+
+// Texto enmascara texto con el Redactor del proceso
+func Texto(texto string) string {
+	return Actual().Texto(texto)
+}
+
+// JSON enmascara un documento JSON con el Redactor del proceso
+func JSON(documento string) string {
+	return Actual().JSON(documento)
+}
+
+// clavesSensibles son atributos de log cuyo valor se enmascara completo
+var clavesSensibles = map[string]bool{
+	"clave_privada": true, "private_key": true, "secreto": true, "secret": true, "token": true,
+	"password": true, "contrasena": true, "api_key": true, "authorization": true, "x-api-key": true,
+	"encryption_key": true,
+}
+
+// clavesPayload son atributos de log con documentos JSON (DatosEvento) a los que se aplican las rutas
+var clavesPayload = map[string]bool{
+	"datos_evento": true, "datos_ipfs": true, "datos_dynamodb": true, "payload": true,
+}
+
+// Atributo enmascara un atributo de slog: completo si su clave es sensible, por rutas JSON si es un
+// payload, y por secretos y credenciales en cualquier otro texto. Recorre los grupos.
+func (r *Redactor) Atributo(atributo slog.Attr) slog.Attr {
+	valor := atributo.Value.Resolve()
+	clave := strings.ToLower(atributo.Key)
+	switch {
+	case valor.Kind() == slog.KindGroup:
+		grupo := valor.Group()
+		enmascarados := make([]slog.Attr, len(grupo))
+		for i, hijo := range grupo {
+			enmascarados[i] = r.Atributo(hijo)
+		}
+		return slog.Attr{Key: atributo.Key, Value: slog.GroupValue(enmascarados...)}
+	case clavesSensibles[clave]:
+		return slog.String(atributo.Key, Marcador)
+	case clavesPayload[clave]:
+		return slog.String(atributo.Key, r.JSON(textoPayload(valor)))
+	case valor.Kind() == slog.KindString:
+		return slog.String(atributo.Key, r.Texto(valor.String()))
+	case valor.Kind() == slog.KindAny:
+		if bytes, ok := valor.Any().([]byte); ok {
+			return slog.String(atributo.Key, r.Texto(string(bytes)))
+		}
+		texto := fmt.Sprint(valor.Any())
+		if enmascarado := r.Texto(texto); enmascarado != texto {
+			return slog.String(atributo.Key, enmascarado)
+		}
+	}
+	return slog.Attr{Key: atributo.Key, Value: valor}
+}
+
+// textoPayload obtiene el documento JSON de un payload registrado como texto, bytes o valor Go
+func textoPayload(valor slog.Value) string {
+	if valor.Kind() != slog.KindAny {
+		return valor.String()
+	}
+	switch v := valor.Any().(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case json.RawMessage:
+		return string(v)
+	default:
+		documento, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(documento)
+	}
+}
+
+// Secreto es un texto que nunca se imprime: en logs y con fmt se muestra como Marcador
+type Secreto string
+
+// LogValue implementa slog.LogValuer
+func (Secreto) LogValue() slog.Value { return slog.StringValue(Marcador) }
+
+// String implementa fmt.Stringer
+func (Secreto) String() string { return Marcador }
@@ -0,0 +1,202 @@
+// Package router define las rutas HTTP de la API y sus middlewares.
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// Dependencias agrupa los handlers y middlewares que necesita Configurar
+type Dependencias struct {
+	TransaccionHandler *handlers.TransaccionHandler
+	OracleHandler      *handlers.OracleHandler
+	HealthHandler      *handlers.HealthHandler
+	IPFSHandler        *handlers.IPFSHandler
+	APIKeyHandler      *handlers.APIKeyHandler
+	CertificadoHandler *handlers.CertificadoHandler
+	EventosHandler     *handlers.EventosHandler
+	WebhookHandler     *handlers.WebhookHandler
+	FondosHandler      *handlers.FondosHandler
+	AuthConfig         middleware.AuthConfig
+	Politica           *policy.Motor
+	Idempotencia       *services.IdempotenciaService
+	RateLimiter        *middleware.RateLimiter // nil = limitador en memoria con las cuotas RATE_LIMIT_*
+	CORS               *middleware.CORS        // nil = CORS con la política CORS_* (ConfigCORS)
+}
+
+// ConfigCORS construye la política CORS a partir de CORS_*; sin orígenes configurados se permite
+// cualquiera, sin credenciales
+func ConfigCORS(cfg *config.Config) middleware.ConfigCORS {
+	origenes := cfg.CORSAllowedOrigins
+	if len(origenes) == 0 {
+		origenes = []string{"*"}
+	}
+	return middleware.ConfigCORS{
+		Origenes:           origenes,
+		Metodos:            cfg.CORSAllowedMethods,
+		Cabeceras:          cfg.CORSAllowedHeaders,
+		CabecerasExpuestas: cfg.CORSExposedHeaders,
+		Credenciales:       cfg.CORSAllowCredentials,
+		MaxAge:             time.Duration(cfg.CORSMaxAge) * time.Second,
+	}
+}
+
+// Configurar crea el router de Gin con todas las rutas de la API
+func Configurar(cfg *config.Config, deps Dependencias) *gin.Engine {
+	router := gin.New()
+
+	// Middleware globales
+	router.Use(middleware.RecuperacionMiddleware())
+	router.Use(middleware.TrazasMiddleware())
+	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.IdiomaMiddleware())
+	router.Use(middleware.ErroresMiddleware())
+	politicaCORS := deps.CORS
+	if politicaCORS == nil {
+		var err error
+		if politicaCORS, err = middleware.NewCORS(ConfigCORS(cfg)); err != nil {
+			panic("router: configuración de CORS inválida: " + err.Error())
+		}
+	}
+	router.Use(politicaCORS.Middleware())
+
+	// Rate limiting: las rutas públicas se limitan por IP; las de /api/v1, por principal una vez autenticado
+	limitador := deps.RateLimiter
+	if limitador == nil {
+		cuotas, err := cfg.CuotasRateLimit()
+		if err != nil {
+			panic("router: configuración de rate limiting inválida: " + err.Error())
+		}
+		limitador = middleware.NewRateLimiter(ratelimit.NewMemoria(), cuotas)
+	}
+	publico := router.Group("", limitador.Middleware())
+
+	// Health checks
+	publico.GET("/health", deps.HealthHandler.HealthCheck)
+	publico.GET("/ready", deps.HealthHandler.ReadinessCheck)
+
+	// Métricas Prometheus (públicas como los health checks: exponer solo en la red interna)
+	if cfg.MetricsEnabled {
+		publico.GET("/metrics", gin.WrapH(metricas.Handler()))
+	}
+
+	// Especificación OpenAPI (pública, igual que los health checks)
+	publico.GET("/api/v1/openapi.json", handlers.ServirOpenAPI)
+
+	// API v1 (requiere autenticación y autorización salvo que AUTH_ENABLED=false)
+	v1 := router.Group("/api/v1")
+	if cfg.AuthEnabled {
+		v1.Use(limitador.ProtegerAutenticacion())
+		v1.Use(middleware.AuthMiddleware(deps.AuthConfig))
+		v1.Use(limitador.Middleware())
+		v1.Use(middleware.AutorizacionMiddleware(deps.Politica))
+	} else {
+		v1.Use(limitador.Middleware())
+	}
+	{
+		// Rutas de transacciones
+		transacciones := v1.Group("/transaccion")
+		{
+			// El límite del cuerpo va antes de IdempotenciaMiddleware, que lee el cuerpo completo
+			transacciones.POST("/registrar", middleware.LimiteCuerpoMiddleware(deps.TransaccionHandler.MaximoCuerpoRegistro),
+				middleware.IdempotenciaMiddleware(deps.Idempotencia), deps.TransaccionHandler.RegistrarTransaccion)
+			transacciones.POST("/registrar-con-adjuntos", deps.TransaccionHandler.RegistrarTransaccionConAdjuntos)
+			transacciones.POST("/lote", middleware.LimiteCuerpoMiddleware(deps.TransaccionHandler.MaximoCuerpoLote),
+				middleware.IdempotenciaMiddleware(deps.Idempotencia), deps.TransaccionHandler.RegistrarLote)
+			transacciones.GET("/estado-blockchain/:id", deps.TransaccionHandler.ObtenerEstadoBlockchain)
+			transacciones.GET("/verificar/:id", deps.TransaccionHandler.VerificarTransaccion)
+			transacciones.GET("/:id", deps.TransaccionHandler.ObtenerTransaccion)
+			transacciones.GET("", deps.TransaccionHandler.ListarTransacciones)
+			transacciones.GET("/producto/:id", deps.TransaccionHandler.ObtenerTransaccionesPorProducto)
+		}
+
+		// Rutas del Oracle (patrón Oracle)
+		oracle := v1.Group("/oracle")
+		{
+			oracle.GET("/datos/:id", deps.OracleHandler.ObtenerDatosVerificados)
+			oracle.GET("/historial/:id", deps.OracleHandler.ObtenerHistorialVerificado)
+			oracle.GET("/validar/:id", deps.OracleHandler.ValidarCadenaSupply)
+		}
+
+		// Notificaciones en tiempo real de registro y anclaje (SSE y WebSocket)
+		streams := v1.Group("/eventos")
+		{
+			streams.GET("", deps.EventosHandler.StreamSSE)
+			streams.GET("/ws", deps.EventosHandler.StreamWebSocket)
+		}
+
+		// Webhooks: notificaciones firmadas para sistemas que no mantienen un stream abierto
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.POST("", deps.WebhookHandler.CrearWebhook)
+			webhooks.GET("", deps.WebhookHandler.ListarWebhooks)
+			webhooks.GET("/:id", deps.WebhookHandler.ObtenerWebhook)
+			webhooks.PUT("/:id", deps.WebhookHandler.ActualizarWebhook)
+			webhooks.DELETE("/:id", deps.WebhookHandler.EliminarWebhook)
+			webhooks.GET("/:id/entregas", deps.WebhookHandler.ListarEntregas)
+			webhooks.POST("/:id/entregas/:idEntrega/reenviar", deps.WebhookHandler.ReenviarEntrega)
+		}
+
+		// Rutas de IPFS
+		ipfs := v1.Group("/ipfs")
+		{
+			ipfs.GET("/archivos", deps.IPFSHandler.ListarArchivos)
+			ipfs.GET("/archivo/:cid", deps.IPFSHandler.ObtenerArchivo)
+			ipfs.GET("/estadisticas", deps.IPFSHandler.ObtenerEstadisticas)
+		}
+
+		// Administración de API keys, identidades de certificado y fondos de la cuenta firmante (solo con
+		// autenticación habilitada)
+		if cfg.AuthEnabled {
+			admin := v1.Group("/admin", middleware.RequerirRol(middleware.RolAdmin))
+			{
+				admin.POST("/api-keys", deps.APIKeyHandler.CrearAPIKey)
+				admin.GET("/api-keys", deps.APIKeyHandler.ListarAPIKeys)
+				admin.DELETE("/api-keys/:id", deps.APIKeyHandler.RevocarAPIKey)
+				admin.POST("/certificados", deps.CertificadoHandler.RegistrarIdentidad)
+				admin.GET("/certificados", deps.CertificadoHandler.ListarIdentidades)
+				admin.DELETE("/certificados", deps.CertificadoHandler.RevocarIdentidad)
+				admin.GET("/fondos", deps.FondosHandler.ObtenerFondos)
+			}
+		}
+	}
+
+	// Ruta raíz con información de la API
+	publico.GET("/", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"service":     "Transacción Blockchain MediSupply",
+			"version":     "1.0.0",
+			"description": "Microservicio con patrones Oracle, Off-chain Storage e IPFS",
+			"endpoints": gin.H{
+				"health":        "/health",
+				"ready":         "/ready",
+				"api":           "/api/v1",
+				"openapi":       "/api/v1/openapi.json",
+				"transacciones": "/api/v1/transaccion",
+				"oracle":        "/api/v1/oracle",
+				"ipfs":          "/api/v1/ipfs",
+				"eventos":       "/api/v1/eventos",
+				"webhooks":      "/api/v1/webhooks",
+			},
+		})
+	})
+
+	// Handler para rutas no encontradas; LoggerMiddleware registra el 404 con método y ruta
+	router.NoRoute(limitador.Middleware(), func(c *gin.Context) {
+		middleware.ResponderProblema(c, middleware.NuevoProblema(c, http.StatusNotFound, middleware.CodigoRutaNoEncontrada,
+			i18n.T(c.Request.Context(), "ruta.verificar", c.Request.Method)))
+	})
+
+	return router
+}
@@ -0,0 +1,199 @@
+// Package salud evalúa la preparación (readiness) del servicio: ejecuta en paralelo las verificaciones de
+// cada dependencia con un timeout propio, clasifica cada una como crítica o degradada y guarda el
+// resultado unos segundos para que los sondeos de Kubernetes, el balanceador y el health service de gRPC
+// no multipliquen las llamadas a DynamoDB, IPFS y al RPC de Ethereum.
+package salud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+)
+
+// Nombres de las verificaciones; son las claves de "checks" en GET /ready y los valores de READINESS_CRITICAL
+const (
+	VerificacionDynamoDB   = "dynamodb"   // DescribeTable de las tablas de transacciones y de control
+	VerificacionIPFS       = "ipfs"       // Versión del nodo IPFS principal; degradada si solo responden las réplicas
+	VerificacionBlockchain = "blockchain" // El RPC responde y su chain ID es el esperado
+	VerificacionContrato   = "contrato"   // La dirección del contrato tiene código desplegado
+	VerificacionBalance    = "balance"    // El saldo de la cuenta firmante alcanza el mínimo
+	VerificacionAnclajes   = "anclajes"   // La cola de anclajes pendientes no supera el máximo
+)
+
+// Nombres lista todas las verificaciones conocidas
+var Nombres = []string{
+	VerificacionDynamoDB, VerificacionIPFS, VerificacionBlockchain,
+	VerificacionContrato, VerificacionBalance, VerificacionAnclajes,
+}
+
+// Estados de una verificación (valor de "checks" en GET /ready)
+const (
+	EstadoOK            = "healthy"
+	EstadoDegradado     = "degraded"  // Falló una verificación no crítica: el servicio sigue listo
+	EstadoFallido       = "unhealthy" // Falló una verificación crítica: el servicio no está listo
+	EstadoDeshabilitado = "disabled"  // La dependencia no está configurada (p. ej. modo sin blockchain)
+)
+
+var (
+	// ErrDeshabilitada indica que la dependencia no está configurada; la verificación no cuenta como fallida
+	ErrDeshabilitada = errors.New("dependencia no configurada")
+	// ErrDegradada indica que la dependencia funciona con capacidad reducida; la verificación cuenta como
+	// degradada aunque sea crítica
+	ErrDegradada = errors.New("dependencia degradada")
+)
+
+// Verificacion comprueba una dependencia; retorna nil si está sana
+type Verificacion struct {
+	Nombre    string
+	Verificar func(ctx context.Context) error
+}
+
+// Config define los tiempos y la clasificación de las verificaciones
+type Config struct {
+	Timeout  time.Duration // Tiempo máximo de cada verificación; 0 = sin límite
+	TTL      time.Duration // Tiempo durante el que se reutiliza el último resultado; 0 = sin caché
+	Criticas []string      // Verificaciones cuyo fallo deja al servicio no listo; las demás lo degradan
+}
+
+// Resultado es el estado de una verificación
+type Resultado struct {
+	Estado     string `json:"estado"`
+	Critica    bool   `json:"critica"`
+	Mensaje    string `json:"mensaje,omitempty"`
+	LatenciaMs int64  `json:"latencia_ms"`
+}
+
+// Informe es el resultado de evaluar todas las verificaciones
+type Informe struct {
+	Listo        bool                 // Ninguna verificación crítica falló
+	Degradado    bool                 // Falló al menos una verificación no crítica
+	Resultados   map[string]Resultado // Por nombre de verificación
+	VerificadoEn time.Time
+}
+
+// Estado resume el informe: ready, degraded o not_ready
+func (i Informe) Estado() string {
+	switch {
+	case !i.Listo:
+		return "not_ready"
+	case i.Degradado:
+		return "degraded"
+	default:
+		return "ready"
+	}
+}
+
+// Checks retorna el estado de cada verificación como texto ("healthy", "unhealthy: <causa>"...)
+func (i Informe) Checks() map[string]string {
+	checks := make(map[string]string, len(i.Resultados))
+	for nombre, resultado := range i.Resultados {
+		checks[nombre] = resultado.Estado
+		if resultado.Mensaje != "" && resultado.Estado != EstadoOK {
+			checks[nombre] += ": " + resultado.Mensaje
+		}
+	}
+	return checks
+}
+
+// Verificador ejecuta las verificaciones y guarda el último informe durante Config.TTL
+type Verificador struct {
+	config         Config
+	verificaciones []Verificacion
+	criticas       map[string]bool
+
+	mu     sync.Mutex
+	ultimo *Informe
+}
+
+// NewVerificador crea un verificador con las verificaciones dadas
+func NewVerificador(cfg Config, verificaciones ...Verificacion) *Verificador {
+	criticas := make(map[string]bool, len(cfg.Criticas))
+	for _, nombre := range cfg.Criticas {
+		criticas[nombre] = true
+	}
+	return &Verificador{config: cfg, verificaciones: verificaciones, criticas: criticas}
+}
+
+// Verificar retorna el último informe si tiene menos de Config.TTL; si no, vuelve a ejecutar las
+// verificaciones. Las peticiones concurrentes esperan a la misma evaluación en lugar de lanzar otra.
+func (v *Verificador) Verificar(ctx context.Context) Informe {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.ultimo != nil && time.Since(v.ultimo.VerificadoEn) < v.config.TTL {
+		return *v.ultimo
+	}
+	informe := v.evaluar(ctx)
+	v.ultimo = &informe
+	return informe
+}
+
+// evaluar ejecuta todas las verificaciones en paralelo. El informe se comparte entre peticiones, así que
+// no se cancela si el cliente que lo provocó se desconecta: solo lo limita el timeout de cada verificación.
+func (v *Verificador) evaluar(ctx context.Context) Informe {
+	ctx = context.WithoutCancel(ctx)
+	resultados := make([]Resultado, len(v.verificaciones))
+	var wg sync.WaitGroup
+	for i, verificacion := range v.verificaciones {
+		wg.Add(1)
+		go func(i int, verificacion Verificacion) {
+			defer wg.Done()
+			resultados[i] = v.ejecutar(ctx, verificacion)
+		}(i, verificacion)
+	}
+	wg.Wait()
+
+	informe := Informe{Listo: true, Resultados: make(map[string]Resultado, len(resultados)), VerificadoEn: time.Now()}
+	for i, resultado := range resultados {
+		informe.Resultados[v.verificaciones[i].Nombre] = resultado
+		switch resultado.Estado {
+		case EstadoFallido:
+			informe.Listo = false
+		case EstadoDegradado:
+			informe.Degradado = true
+		}
+	}
+	return informe
+}
+
+// ejecutar corre una verificación con su timeout; un pánico cuenta como fallo en lugar de tumbar el servidor
+func (v *Verificador) ejecutar(ctx context.Context, verificacion Verificacion) (resultado Resultado) {
+	resultado.Critica = v.criticas[verificacion.Nombre]
+	inicio := time.Now()
+	if v.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.config.Timeout)
+		defer cancel()
+	}
+
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pánico: %v", r)
+		}
+		resultado.LatenciaMs = time.Since(inicio).Milliseconds()
+		switch {
+		case err == nil:
+			resultado.Estado = EstadoOK
+			return
+		case errors.Is(err, ErrDeshabilitada):
+			resultado.Estado = EstadoDeshabilitado
+		case errors.Is(err, ErrDegradada):
+			resultado.Estado = EstadoDegradado
+		case resultado.Critica:
+			resultado.Estado = EstadoFallido
+		default:
+			resultado.Estado = EstadoDegradado
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timeout (%s): %w", v.config.Timeout, err)
+		}
+		// La causa puede incluir la URL del RPC con la API key
+		resultado.Mensaje = redaccion.Texto(err.Error())
+	}()
+	err = verificacion.Verificar(ctx)
+	return resultado
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// AdjuntosConfig define los límites aplicados a los documentos adjuntos
+type AdjuntosConfig struct {
+	TamanoMaximo   int64    // Bytes máximos por archivo
+	MaximoArchivos int      // Archivos máximos por evento
+	TiposMIME      []string // Tipos MIME aceptados
+	TiposEvento    []string // Tipos de evento que admiten adjuntos
+}
+
+var (
+	// ErrAdjuntoDemasiadoGrande indica que un archivo supera TamanoMaximo
+	ErrAdjuntoDemasiadoGrande = NuevoErrorDominio(TipoDemasiadoGrande, "adjunto-demasiado-grande", "el adjunto excede el tamaño máximo permitido")
+	// ErrAdjuntoNoPermitido indica que el tipo de archivo o de evento no admite adjuntos
+	ErrAdjuntoNoPermitido = NuevoErrorDominio(TipoNoSoportado, "adjunto-no-permitido", "adjunto no permitido")
+	// ErrDemasiadosAdjuntos indica que el evento trae más archivos que MaximoArchivos
+	ErrDemasiadosAdjuntos = NuevoErrorDominio(TipoDemasiadoGrande, "demasiados-adjuntos", "se superó el máximo de adjuntos por evento")
+)
+
+// defaultAdjuntosConfig son los límites usados si no se configuran explícitamente
+func defaultAdjuntosConfig() AdjuntosConfig {
+	return AdjuntosConfig{
+		TamanoMaximo:   25 << 20,
+		MaximoArchivos: 10,
+		TiposMIME:      []string{"application/pdf", "image/png", "image/jpeg", "text/csv"},
+		TiposEvento:    []string{"fabricacion", "distribucion"},
+	}
+}
+
+// ConfigurarAdjuntos establece los límites de tamaño y tipo de los adjuntos
+func (s *TransaccionService) ConfigurarAdjuntos(cfg AdjuntosConfig) {
+	s.adjuntosConfig = cfg
+}
+
+// GetLimitesAdjuntos retorna los límites configurados para adjuntos
+func (s *TransaccionService) GetLimitesAdjuntos() AdjuntosConfig {
+	return s.adjuntosConfig
+}
+
+// ValidarSolicitudConAdjuntos valida los campos del evento y su autorización antes de empezar a recibir archivos
+func (s *TransaccionService) ValidarSolicitudConAdjuntos(ctx context.Context, req *models.TransaccionRequest) error {
+	aplicarActorAutenticado(ctx, req)
+	if err := validarSolicitud(req); err != nil {
+		return err
+	}
+	if !contiene(s.adjuntosConfig.TiposEvento, req.TipoEvento) {
+		return ErrAdjuntoNoPermitido.Con(i18n.NuevoError("adjunto.tipo-evento-sin-adjuntos", req.TipoEvento))
+	}
+	return s.autorizarEvento(ctx, req)
+}
+
+// AlmacenarAdjunto transmite un archivo a IPFS aplicando los límites configurados.
+// El contenido se lee en streaming: se detecta el tipo con los primeros bytes, se calcula
+// el SHA-256 y se corta la subida si se supera el tamaño máximo.
+func (s *TransaccionService) AlmacenarAdjunto(ctx context.Context, nombre, tipoDeclarado string, contenido io.Reader) (*models.Adjunto, error) {
+	lector := bufio.NewReaderSize(contenido, 512)
+	cabecera, err := lector.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("error leyendo adjunto %s: %w", nombre, err)
+	}
+
+	tipoMIME := detectarTipoMIME(cabecera, tipoDeclarado)
+	if !contiene(s.adjuntosConfig.TiposMIME, tipoMIME) {
+		return nil, ErrAdjuntoNoPermitido.Con(i18n.NuevoError("adjunto.tipo-mime-no-permitido", tipoMIME, nombre))
+	}
+
+	limitado := &lectorLimitado{r: lector, maximo: s.adjuntosConfig.TamanoMaximo}
+	hasher := sha256.New()
+
+	slog.DebugContext(ctx, "almacenando adjunto en IPFS", "nombre", nombre, "tipo_mime", tipoMIME)
+	cid, nodos, err := s.ipfsService.Almacenar(ctx, nombre, io.TeeReader(limitado, hasher))
+	if err != nil {
+		if errors.Is(err, ErrAdjuntoDemasiadoGrande) {
+			return nil, ErrAdjuntoDemasiadoGrande.Con(i18n.NuevoError("adjunto.supera-bytes", nombre, s.adjuntosConfig.TamanoMaximo))
+		}
+		return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("error almacenando adjunto %s en IPFS: %w", nombre, err))
+	}
+
+	return &models.Adjunto{
+		CID:        cid,
+		Nombre:     nombre,
+		TipoMIME:   tipoMIME,
+		Tamano:     limitado.leidos,
+		HashSHA256: hex.EncodeToString(hasher.Sum(nil)),
+		NodosIPFS:  nodos,
+	}, nil
+}
+
+// RegistrarTransaccionConAdjuntos registra un evento cuyos adjuntos ya fueron almacenados en IPFS
+func (s *TransaccionService) RegistrarTransaccionConAdjuntos(ctx context.Context, req *models.TransaccionRequest, adjuntos []models.Adjunto) (*models.Transaccion, error) {
+	if err := s.ValidarSolicitudConAdjuntos(ctx, req); err != nil {
+		return nil, err
+	}
+	if len(adjuntos) > s.adjuntosConfig.MaximoArchivos {
+		return nil, ErrDemasiadosAdjuntos.Con(i18n.NuevoError("adjunto.maximo-archivos", s.adjuntosConfig.MaximoArchivos))
+	}
+	return s.registrar(ctx, req, adjuntos)
+}
+
+// verificarAdjuntos recupera cada adjunto de IPFS y compara su hash y tamaño con lo registrado
+func (s *TransaccionService) verificarAdjuntos(ctx context.Context, adjuntos []models.Adjunto) error {
+	for _, adjunto := range adjuntos {
+		stream, err := s.ipfsService.RecuperarStream(ctx, adjunto.CID)
+		if err != nil {
+			return fmt.Errorf("error recuperando adjunto %s: %w", adjunto.Nombre, err)
+		}
+
+		hasher := sha256.New()
+		tamano, err := io.Copy(hasher, stream)
+		stream.Close()
+		if err != nil {
+			return fmt.Errorf("error leyendo adjunto %s: %w", adjunto.Nombre, err)
+		}
+
+		if tamano != adjunto.Tamano || hex.EncodeToString(hasher.Sum(nil)) != adjunto.HashSHA256 {
+			return fmt.Errorf("el contenido del adjunto %s no coincide con el registrado", adjunto.Nombre)
+		}
+	}
+	return nil
+}
+
+// detectarTipoMIME determina el tipo real del archivo a partir de sus primeros bytes.
+// Para contenido de texto (CSV, JSON) la detección no distingue formatos, así que se
+// acepta el tipo declarado si también es de texto.
+func detectarTipoMIME(cabecera []byte, tipoDeclarado string) string {
+	detectado, _, _ := mime.ParseMediaType(http.DetectContentType(cabecera))
+	declarado, _, _ := mime.ParseMediaType(tipoDeclarado)
+
+	if detectado == "text/plain" && (strings.HasPrefix(declarado, "text/") || declarado == "application/json") {
+		return declarado
+	}
+	return detectado
+}
+
+// lectorLimitado falla con ErrAdjuntoDemasiadoGrande en cuanto se leen más de maximo bytes
+type lectorLimitado struct {
+	r      io.Reader
+	maximo int64
+	leidos int64
+}
+
+func (l *lectorLimitado) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.leidos += int64(n)
+	if l.leidos > l.maximo {
+		return n, ErrAdjuntoDemasiadoGrande
+	}
+	return n, err
+}
+
+// contiene indica si el valor está en la lista
+func contiene(lista []string, valor string) bool {
+	for _, elemento := range lista {
+		if elemento == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// prefijoAPIKey identifica las claves de este servicio: msk_<id>_<secreto>
+const prefijoAPIKey = "msk_"
+
+var (
+	// ErrCredencialesInvalidas indica una API key inexistente, revocada o con secreto incorrecto
+	ErrCredencialesInvalidas = i18n.NuevoError("credenciales-invalidas")
+	// ErrAPIKeyNoEncontrada indica que no existe una API key con el ID solicitado
+	ErrAPIKeyNoEncontrada = NuevoErrorDominio(TipoNoEncontrado, "api-key-no-encontrada", "API key no encontrada")
+)
+
+// AlmacenAPIKeys persiste las API keys. DynamoDBService lo implementa sobre la tabla de control.
+type AlmacenAPIKeys interface {
+	GuardarAPIKey(ctx context.Context, apiKey *models.APIKey) error
+	ObtenerAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	ListarAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+}
+
+// APIKeyService emite, valida y revoca API keys
+type APIKeyService struct {
+	almacen AlmacenAPIKeys
+}
+
+// NewAPIKeyService crea una nueva instancia de APIKeyService
+func NewAPIKeyService(almacen AlmacenAPIKeys) *APIKeyService {
+	return &APIKeyService{almacen: almacen}
+}
+
+// CrearAPIKey genera una nueva API key. La clave en texto plano solo se retorna aquí;
+// el almacén guarda únicamente su hash.
+func (s *APIKeyService) CrearAPIKey(ctx context.Context, req *models.APIKeyRequest) (string, *models.APIKey, error) {
+	id, err := aleatorioHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secreto := make([]byte, 32)
+	if _, err := rand.Read(secreto); err != nil {
+		return "", nil, fmt.Errorf("error generando secreto: %w", err)
+	}
+	clave := prefijoAPIKey + id + "_" + base64.RawURLEncoding.EncodeToString(secreto)
+
+	apiKey := &models.APIKey{
+		ID:        id,
+		Nombre:    req.Nombre,
+		HashClave: hashClave(clave),
+		Actor:     req.Actor,
+		Roles:     req.Roles,
+		CreadaEn:  time.Now().UTC(),
+	}
+	if apiKey.Roles == nil {
+		apiKey.Roles = []string{}
+	}
+
+	if err := s.almacen.GuardarAPIKey(ctx, apiKey); err != nil {
+		return "", nil, fmt.Errorf("error guardando API key: %w", err)
+	}
+
+	return clave, apiKey, nil
+}
+
+// Autenticar valida una API key y retorna el principal asociado
+func (s *APIKeyService) Autenticar(ctx context.Context, clave string) (*models.Principal, error) {
+	id, ok := idDeClave(clave)
+	if !ok {
+		return nil, ErrCredencialesInvalidas
+	}
+
+	apiKey, err := s.almacen.ObtenerAPIKey(ctx, id)
+	if errors.Is(err, ErrAPIKeyNoEncontrada) {
+		return nil, ErrCredencialesInvalidas
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo API key: %w", err)
+	}
+
+	if apiKey.RevocadaEn != nil || subtle.ConstantTimeCompare([]byte(hashClave(clave)), []byte(apiKey.HashClave)) != 1 {
+		return nil, ErrCredencialesInvalidas
+	}
+
+	return &models.Principal{
+		ID:     apiKey.ID,
+		Actor:  apiKey.Actor,
+		Roles:  apiKey.Roles,
+		Metodo: models.MetodoAPIKey,
+	}, nil
+}
+
+// ListarAPIKeys lista las API keys registradas (sin sus secretos)
+func (s *APIKeyService) ListarAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.almacen.ListarAPIKeys(ctx)
+}
+
+// RevocarAPIKey marca una API key como revocada; deja de autenticar de inmediato
+func (s *APIKeyService) RevocarAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	apiKey, err := s.almacen.ObtenerAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevocadaEn == nil {
+		ahora := time.Now().UTC()
+		apiKey.RevocadaEn = &ahora
+		if err := s.almacen.GuardarAPIKey(ctx, apiKey); err != nil {
+			return nil, fmt.Errorf("error revocando API key: %w", err)
+		}
+	}
+	return apiKey, nil
+}
+
+// EsAPIKey indica si la credencial tiene el formato de una API key de este servicio
+func EsAPIKey(credencial string) bool {
+	return strings.HasPrefix(credencial, prefijoAPIKey)
+}
+
+// idDeClave extrae el ID de una clave msk_<id>_<secreto>
+func idDeClave(clave string) (string, bool) {
+	if !EsAPIKey(clave) {
+		return "", false
+	}
+	partes := strings.SplitN(strings.TrimPrefix(clave, prefijoAPIKey), "_", 2)
+	if len(partes) != 2 || partes[0] == "" || partes[1] == "" {
+		return "", false
+	}
+	return partes[0], true
+}
+
+// hashClave calcula el SHA-256 de la clave completa. Las claves tienen 256 bits de entropía,
+// así que no requieren un hash lento.
+func hashClave(clave string) string {
+	suma := sha256.Sum256([]byte(clave))
+	return hex.EncodeToString(suma[:])
+}
+
+// aleatorioHex genera n bytes aleatorios codificados en hex
+func aleatorioHex(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("error generando identificador: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// AlmacenAPIKeysMemoria es un AlmacenAPIKeys en memoria, útil para desarrollo y tests
+type AlmacenAPIKeysMemoria struct {
+	mu     sync.RWMutex
+	claves map[string]models.APIKey
+}
+
+// NewAlmacenAPIKeysMemoria crea un almacén de API keys en memoria
+func NewAlmacenAPIKeysMemoria() *AlmacenAPIKeysMemoria {
+	return &AlmacenAPIKeysMemoria{claves: make(map[string]models.APIKey)}
+}
+
+// GuardarAPIKey guarda o reemplaza una API key
+func (a *AlmacenAPIKeysMemoria) GuardarAPIKey(ctx context.Context, apiKey *models.APIKey) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.claves[apiKey.ID] = *apiKey
+	return nil
+}
+
+// ObtenerAPIKey obtiene una API key por ID
+func (a *AlmacenAPIKeysMemoria) ObtenerAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	apiKey, ok := a.claves[id]
+	if !ok {
+		return nil, ErrAPIKeyNoEncontrada
+	}
+	return &apiKey, nil
+}
+
+// ListarAPIKeys lista las API keys ordenadas por fecha de creación
+func (a *AlmacenAPIKeysMemoria) ListarAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	lista := make([]*models.APIKey, 0, len(a.claves))
+	for _, apiKey := range a.claves {
+		copia := apiKey
+		lista = append(lista, &copia)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].CreadaEn.Before(lista[j].CreadaEn) })
+	return lista, nil
+}
@@ -4,19 +4,53 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
 	"github.com/edinfamous/blockchain-medisupply/pkg/contracts"
 )
 
+// Límites de gas de cada anclaje; multiplicados por el gas price son el costo máximo de un anclaje
+const (
+	gasLimiteContrato = uint64(300000)
+	gasLimiteSimple   = uint64(100000)
+)
+
+// ErrFondosInsuficientes indica que el nodo rechazó el anclaje porque la cuenta firmante no puede pagar el gas
+var ErrFondosInsuficientes = errors.New("fondos insuficientes en la cuenta firmante")
+
+// EnvioAnclaje identifica una transacción de anclaje ya firmada
+type EnvioAnclaje struct {
+	HashLogico string // Clave del registro en el contrato; en modo transacción simple, el hash de la tx
+	TxHash     string
+}
+
+// EstadoEnvio es lo que sabe el nodo de una transacción de anclaje firmada en un intento anterior
+type EstadoEnvio int
+
+const (
+	EnvioDesconocido EstadoEnvio = iota // El nodo no conoce la transacción: no llegó a difundirse o se descartó
+	EnvioPendiente                      // En el mempool; en modo transacción simple basta para darla por anclada
+	EnvioConfirmado                     // Minada con éxito
+	EnvioRevertido                      // Minada, pero la ejecución falló
+)
+
 // BlockchainService maneja las operaciones con la blockchain
 type BlockchainService struct {
 	client          *ethclient.Client
@@ -24,6 +58,9 @@ type BlockchainService struct {
 	chainID         *big.Int
 	contractAddress common.Address
 	contract        *contracts.MediSupplyRegistry
+
+	mu            sync.RWMutex
+	observarGasto func(costo *big.Int) // Recibe el costo en wei de cada anclaje enviado; nil = sin seguimiento
 }
 
 // NewBlockchainService crea una nueva instancia de BlockchainService
@@ -35,10 +72,8 @@ func NewBlockchainService(rpcURL string, privateKeyHex string, contractAddress s
 	if err != nil {
 		return nil, fmt.Errorf("error conectando a Ethereum: %w", err)
 	}
-	fmt.Println("Conectado a Ethereum")
-	fmt.Println("rpcURL:", rpcURL)
-	fmt.Println("privateKeyHex:", privateKeyHex)
-	fmt.Println("contractAddress:", contractAddress)
+	// Ni la URL del RPC (puede llevar la API key de Alchemy) ni la clave privada se registran
+	slog.Debug("conectado a Ethereum", "contrato", contractAddress)
 
 	// Normalizar y validar private key
 	privateKeyHex = normalizePrivateKey(privateKeyHex)
@@ -89,30 +124,92 @@ func NewBlockchainService(rpcURL string, privateKeyHex string, contractAddress s
 // Si el contrato está configurado, usa el contrato. Si no, usa transacciones simples.
 // Devuelve (hash lógico, hash de transacción de Ethereum, error)
 func (s *BlockchainService) RegistrarEnBlockchain(ctx context.Context, hash, cid string) (string, string, error) {
+	return s.RegistrarEnBlockchainConAviso(ctx, hash, cid, nil)
+}
+
+// RegistrarEnBlockchainConAviso es RegistrarEnBlockchain, pero llama a antesDeEnviar con la transacción ya
+// firmada y antes de difundirla, para que quien llama guarde su hash; si antesDeEnviar falla, no se envía
+func (s *BlockchainService) RegistrarEnBlockchainConAviso(ctx context.Context, hash, cid string, antesDeEnviar func(context.Context, EnvioAnclaje) error) (logicalHash, txHash string, err error) {
+	modo := "transaccion-simple"
+	if s.contract != nil {
+		modo = "contrato"
+	}
+	ctx, span := trazas.Iniciar(ctx, "blockchain.registrar", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("modo", modo), attribute.String("hash", hash), attribute.String("cid", cid)))
+	defer trazas.Terminar(span, &err)
+	inicio := time.Now()
+
 	// Si tenemos un contrato configurado, usarlo
 	if s.contract != nil {
-		return s.registrarConContrato(ctx, hash, cid)
+		var recibo *types.Receipt
+		logicalHash, txHash, recibo, err = s.registrarConContrato(ctx, hash, cid, antesDeEnviar)
+		var gas uint64
+		if recibo != nil {
+			gas = recibo.GasUsed
+			// Los nodos anteriores a London no informan el gas price efectivo
+			if recibo.EffectiveGasPrice != nil {
+				s.registrarGasto(new(big.Int).Mul(new(big.Int).SetUint64(gas), recibo.EffectiveGasPrice))
+			}
+		}
+		metricas.ObservarEnvioBlockchain(modo, inicio, gas, err)
+		span.SetAttributes(attribute.String("tx_hash", txHash), attribute.Int64("gas_usado", int64(gas)))
+		return logicalHash, txHash, err
+	}
+
+	// Fallback: usar transacción simple (modo compatible hacia atrás); no espera el recibo, así que no
+	// se conoce el gas usado y el costo registrado es el máximo (límite de gas × gas price)
+	var costo *big.Int
+	logicalHash, txHash, costo, err = s.registrarConTransaccionSimple(ctx, hash, cid, antesDeEnviar)
+	if costo != nil {
+		s.registrarGasto(costo)
 	}
+	metricas.ObservarEnvioBlockchain(modo, inicio, 0, err)
+	span.SetAttributes(attribute.String("tx_hash", txHash))
+	return logicalHash, txHash, err
+}
 
-	// Fallback: usar transacción simple (modo compatible hacia atrás)
-	return s.registrarConTransaccionSimple(ctx, hash, cid)
+// ConfigurarGasto establece la función que recibe el costo en wei de cada anclaje enviado
+func (s *BlockchainService) ConfigurarGasto(observar func(costo *big.Int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observarGasto = observar
 }
 
-// registrarConContrato registra un hash usando el smart contract
-func (s *BlockchainService) registrarConContrato(ctx context.Context, hash, cid string) (string, string, error) {
-	// Preparar opciones de transacción
+func (s *BlockchainService) registrarGasto(costo *big.Int) {
+	s.mu.RLock()
+	observar := s.observarGasto
+	s.mu.RUnlock()
+	if observar != nil && costo.Sign() > 0 {
+		observar(costo)
+	}
+}
+
+// errorEnvio clasifica el rechazo del nodo por falta de saldo como ErrFondosInsuficientes. El RPC solo
+// devuelve el texto del error ("insufficient funds for gas * price + value"), no un código.
+func errorEnvio(err error) error {
+	if strings.Contains(strings.ToLower(err.Error()), "insufficient funds") {
+		return fmt.Errorf("%w: %w", ErrFondosInsuficientes, err)
+	}
+	return err
+}
+
+// registrarConContrato registra un hash usando el smart contract y retorna también el recibo, con el gas usado.
+// El recibo se retorna aunque la transacción haya revertido: su gas también se pagó.
+func (s *BlockchainService) registrarConContrato(ctx context.Context, hash, cid string, antesDeEnviar func(context.Context, EnvioAnclaje) error) (string, string, *types.Receipt, error) {
+	// Preparar opciones de transacción; se firma sin enviar para avisar antes de difundirla
 	opts, err := s.GetTransactionOpts(ctx)
 	if err != nil {
-		return "", "", fmt.Errorf("error obteniendo opciones de transacción: %w", err)
+		return "", "", nil, fmt.Errorf("error obteniendo opciones de transacción: %w", err)
 	}
+	opts.NoSend = true
 
 	// Convertir hash string a bytes32
 	hashBytes, err := hex.DecodeString(hash)
 	if err != nil {
-		return "", "", fmt.Errorf("error decodificando hash: %w", err)
+		return "", "", nil, fmt.Errorf("error decodificando hash: %w", err)
 	}
 	if len(hashBytes) != 32 {
-		return "", "", fmt.Errorf("hash debe tener 32 bytes, tiene %d", len(hashBytes))
+		return "", "", nil, fmt.Errorf("hash debe tener 32 bytes, tiene %d", len(hashBytes))
 	}
 	var hashBytes32 [32]byte
 	copy(hashBytes32[:], hashBytes)
@@ -125,30 +222,38 @@ func (s *BlockchainService) registrarConContrato(ctx context.Context, hash, cid
 	// Llamar al contrato
 	tx, err := s.contract.RegistrarHash(opts, hashTransaccion, hashBytes32, cid)
 	if err != nil {
-		return "", "", fmt.Errorf("error registrando en contrato: %w", err)
+		return "", "", nil, fmt.Errorf("error registrando en contrato: %w", errorEnvio(err))
+	}
+	if antesDeEnviar != nil {
+		if err := antesDeEnviar(ctx, EnvioAnclaje{HashLogico: hex.EncodeToString(hashTransaccion[:]), TxHash: tx.Hash().Hex()}); err != nil {
+			return "", "", nil, err
+		}
+	}
+	if err := s.client.SendTransaction(ctx, tx); err != nil {
+		return "", "", nil, fmt.Errorf("error registrando en contrato: %w", errorEnvio(err))
 	}
 
 	// Esperar confirmación
 	receipt, err := bind.WaitMined(ctx, s.client, tx)
 	if err != nil {
-		return "", "", fmt.Errorf("error esperando confirmación: %w", err)
+		return "", "", nil, fmt.Errorf("error esperando confirmación: %w", err)
 	}
 
 	if receipt.Status != types.ReceiptStatusSuccessful {
-		return "", "", fmt.Errorf("transacción falló en blockchain")
+		return "", "", receipt, fmt.Errorf("transacción falló en blockchain")
 	}
 
 	// Devolver el hash de transacción lógico que se usó como clave en el contrato
 	// Y el hash de la transacción de Ethereum para trazabilidad
-	return hex.EncodeToString(hashTransaccion[:]), tx.Hash().Hex(), nil
+	return hex.EncodeToString(hashTransaccion[:]), tx.Hash().Hex(), receipt, nil
 }
 
-// registrarConTransaccionSimple registra usando una transacción simple (fallback)
-func (s *BlockchainService) registrarConTransaccionSimple(ctx context.Context, hash, cid string) (string, string, error) {
+// registrarConTransaccionSimple registra usando una transacción simple (fallback); retorna también su costo máximo
+func (s *BlockchainService) registrarConTransaccionSimple(ctx context.Context, hash, cid string, antesDeEnviar func(context.Context, EnvioAnclaje) error) (string, string, *big.Int, error) {
 	publicKey := s.privateKey.Public()
 	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
-		return "", "", fmt.Errorf("error casting public key")
+		return "", "", nil, fmt.Errorf("error casting public key")
 	}
 
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
@@ -156,13 +261,13 @@ func (s *BlockchainService) registrarConTransaccionSimple(ctx context.Context, h
 	// Obtener nonce
 	nonce, err := s.client.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
-		return "", "", fmt.Errorf("error obteniendo nonce: %w", err)
+		return "", "", nil, fmt.Errorf("error obteniendo nonce: %w", err)
 	}
 
 	// Obtener gas price
 	gasPrice, err := s.client.SuggestGasPrice(ctx)
 	if err != nil {
-		return "", "", fmt.Errorf("error obteniendo gas price: %w", err)
+		return "", "", nil, fmt.Errorf("error obteniendo gas price: %w", err)
 	}
 
 	// Preparar datos: hash + CID concatenados
@@ -173,7 +278,7 @@ func (s *BlockchainService) registrarConTransaccionSimple(ctx context.Context, h
 		nonce,
 		common.HexToAddress("0x0000000000000000000000000000000000000000"),
 		big.NewInt(0),
-		uint64(100000),
+		gasLimiteSimple,
 		gasPrice,
 		data,
 	)
@@ -181,42 +286,78 @@ func (s *BlockchainService) registrarConTransaccionSimple(ctx context.Context, h
 	// Firmar transacción
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(s.chainID), s.privateKey)
 	if err != nil {
-		return "", "", fmt.Errorf("error firmando transacción: %w", err)
+		return "", "", nil, fmt.Errorf("error firmando transacción: %w", err)
+	}
+
+	if antesDeEnviar != nil {
+		txHash := signedTx.Hash().Hex()
+		if err := antesDeEnviar(ctx, EnvioAnclaje{HashLogico: txHash, TxHash: txHash}); err != nil {
+			return "", "", nil, err
+		}
 	}
 
 	// Enviar transacción
 	err = s.client.SendTransaction(ctx, signedTx)
 	if err != nil {
-		return "", "", fmt.Errorf("error enviando transacción: %w", err)
+		return "", "", nil, fmt.Errorf("error enviando transacción: %w", errorEnvio(err))
 	}
 
 	// En modo simple, el hash lógico y el de la tx son el mismo
 	txHash := signedTx.Hash().Hex()
-	return txHash, txHash, nil
+	return txHash, txHash, signedTx.Cost(), nil
+}
+
+// ConsultarEnvio consulta al nodo una transacción de anclaje firmada en un intento anterior. Con contrato,
+// si sigue en el mempool espera a que se mine, igual que RegistrarEnBlockchain.
+func (s *BlockchainService) ConsultarEnvio(ctx context.Context, txHash string) (EstadoEnvio, error) {
+	tx, pendiente, err := s.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return EnvioDesconocido, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error consultando transacción %s: %w", txHash, err)
+	}
+
+	var recibo *types.Receipt
+	switch {
+	case pendiente && s.contract == nil:
+		return EnvioPendiente, nil
+	case pendiente:
+		recibo, err = bind.WaitMined(ctx, s.client, tx)
+	default:
+		recibo, err = s.client.TransactionReceipt(ctx, tx.Hash())
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo recibo de %s: %w", txHash, err)
+	}
+	if recibo.Status != types.ReceiptStatusSuccessful {
+		return EnvioRevertido, nil
+	}
+	return EnvioConfirmado, nil
 }
 
 // VerificarEnBlockchain verifica un hash contra la blockchain usando el smart contract
-func (s *BlockchainService) VerificarEnBlockchain(ctx context.Context, txHash, hashEsperado string) (bool, error) {
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY: Iniciando verificación para TxHash: %s\n", txHash)
-	// Si tenemos un contrato, usar el método del contrato
+func (s *BlockchainService) VerificarEnBlockchain(ctx context.Context, txHash, hashEsperado string) (valido bool, err error) {
+	// Si tenemos un contrato, usar el método del contrato; si no, verificar en transacción simple
+	modo, verificar := "transaccion-simple", s.verificarConTransaccionSimple
 	if s.contract != nil {
-		fmt.Println("⛓️ BLOCKCHAIN_VERIFY: Usando modo 'verificarConContrato'")
-		return s.verificarConContrato(ctx, txHash, hashEsperado)
+		modo, verificar = "contrato", s.verificarConContrato
 	}
+	ctx, span := trazas.Iniciar(ctx, "blockchain.verificar", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("modo", modo), attribute.String("tx_hash", txHash)))
+	defer trazas.Terminar(span, &err)
 
-	// Fallback: verificar en transacción simple
-	fmt.Println("⛓️ BLOCKCHAIN_VERIFY: Usando modo 'verificarConTransaccionSimple'")
-	return s.verificarConTransaccionSimple(ctx, txHash, hashEsperado)
+	valido, err = verificar(ctx, txHash, hashEsperado)
+	span.SetAttributes(attribute.Bool("valido", valido))
+	slog.DebugContext(ctx, "hash verificado en blockchain", "modo", modo, "tx_hash", txHash, "valido", valido)
+	return valido, err
 }
 
 // verificarConContrato verifica usando el smart contract
 func (s *BlockchainService) verificarConContrato(ctx context.Context, txHash, hashEsperado string) (bool, error) {
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Contrato): Verificando TxHash: %s, HashEsperado: %s\n", txHash, hashEsperado)
-
 	// Convertir hash esperado a bytes32
 	hashBytes, err := hex.DecodeString(hashEsperado)
 	if err != nil {
-		fmt.Printf("🔴 BLOCKCHAIN_VERIFY (Contrato): Error decodificando hashEsperado: %v\n", err)
 		return false, fmt.Errorf("error decodificando hash: %w", err)
 	}
 	if len(hashBytes) != 32 {
@@ -224,64 +365,49 @@ func (s *BlockchainService) verificarConContrato(ctx context.Context, txHash, ha
 	}
 	var hashBytes32 [32]byte
 	copy(hashBytes32[:], hashBytes)
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Contrato): HashEsperado convertido a bytes32: %x\n", hashBytes32)
 
 	// El txHash es el hashTransaccion que usamos en el registro
 	var hashTransaccion [32]byte
 	copy(hashTransaccion[:], common.HexToHash(txHash).Bytes())
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Contrato): TxHash convertido a hashTransaccion: %x\n", hashTransaccion)
 
 	// Llamar al contrato
-	fmt.Println("⛓️ BLOCKCHAIN_VERIFY (Contrato): Llamando a s.contract.VerificarHash...")
 	valido, err := s.contract.VerificarHash(&bind.CallOpts{Context: ctx}, hashTransaccion, hashBytes32)
 	if err != nil {
-		fmt.Printf("🔴 BLOCKCHAIN_VERIFY (Contrato): Error en la llamada al contrato: %v\n", err)
 		return false, fmt.Errorf("error verificando en contrato: %w", err)
 	}
 
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Contrato): Resultado de la llamada al contrato: %t\n", valido)
 	return valido, nil
 }
 
 // verificarConTransaccionSimple verifica en una transacción simple (fallback)
 func (s *BlockchainService) verificarConTransaccionSimple(ctx context.Context, txHash, hashEsperado string) (bool, error) {
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Simple): Verificando TxHash: %s, HashEsperado: %s\n", txHash, hashEsperado)
-
 	// Obtener la transacción
 	tx, isPending, err := s.client.TransactionByHash(ctx, common.HexToHash(txHash))
 	if err != nil {
-		fmt.Printf("🔴 BLOCKCHAIN_VERIFY (Simple): Error obteniendo transacción: %v\n", err)
 		return false, fmt.Errorf("error obteniendo transacción: %w", err)
 	}
 
 	if isPending {
-		fmt.Println("🔴 BLOCKCHAIN_VERIFY (Simple): La transacción aún está pendiente")
 		return false, fmt.Errorf("transacción aún pendiente")
 	}
 
 	// Verificar que la transacción fue exitosa
 	receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(txHash))
 	if err != nil {
-		fmt.Printf("🔴 BLOCKCHAIN_VERIFY (Simple): Error obteniendo receipt: %v\n", err)
 		return false, fmt.Errorf("error obteniendo receipt: %w", err)
 	}
 
 	if receipt.Status != types.ReceiptStatusSuccessful {
-		fmt.Printf("🔴 BLOCKCHAIN_VERIFY (Simple): La transacción falló en blockchain (Status: %d)\n", receipt.Status)
 		return false, fmt.Errorf("transacción falló en blockchain")
 	}
-	fmt.Println("⛓️ BLOCKCHAIN_VERIFY (Simple): El receipt de la transacción es exitoso (Status: 1)")
 
 	// Extraer datos de la transacción
 	data := tx.Data()
 	dataStr := string(data)
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Simple): Datos extraídos de la transacción: %s\n", dataStr)
 
 	// Verificar si el hash está en los datos
 	// Formato: "hash:cid"
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Simple): Comparando datos extraídos ('%s') con hash esperado ('%s')\n", dataStr, hashEsperado)
 	resultado := len(dataStr) > 0 && strings.HasPrefix(dataStr, hashEsperado)
-	fmt.Printf("⛓️ BLOCKCHAIN_VERIFY (Simple): Resultado de la comparación: %t\n", resultado)
 
 	return resultado, nil
 }
@@ -303,6 +429,57 @@ func (s *BlockchainService) ObtenerBalance(ctx context.Context) (*big.Int, error
 	return balance, nil
 }
 
+// Cuenta retorna la dirección de la cuenta firmante que paga el gas de los anclajes
+func (s *BlockchainService) Cuenta() common.Address {
+	return crypto.PubkeyToAddress(s.privateKey.PublicKey)
+}
+
+// EstimarCostoAnclaje estima el costo máximo en wei de un anclaje con el gas price actual de la red
+func (s *BlockchainService) EstimarCostoAnclaje(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := s.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo gas price: %w", err)
+	}
+	limite := gasLimiteSimple
+	if s.contract != nil {
+		limite = gasLimiteContrato
+	}
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(limite)), nil
+}
+
+// TieneContrato indica si el servicio ancla con el smart contract (false = modo transacción simple)
+func (s *BlockchainService) TieneContrato() bool {
+	return s.contract != nil
+}
+
+// VerificarRed comprueba que el RPC responde y que su chain ID es el esperado; con esperado nil lo
+// compara con el obtenido al conectar, para detectar que el endpoint pasó a apuntar a otra red
+func (s *BlockchainService) VerificarRed(ctx context.Context, esperado *big.Int) error {
+	chainID, err := s.client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("error obteniendo chainID: %w", err)
+	}
+	if esperado == nil {
+		esperado = s.chainID
+	}
+	if chainID.Cmp(esperado) != 0 {
+		return fmt.Errorf("el RPC está en la red con chain ID %s; se esperaba %s", chainID, esperado)
+	}
+	return nil
+}
+
+// VerificarContrato comprueba que la dirección del contrato tiene código desplegado en la red del RPC
+func (s *BlockchainService) VerificarContrato(ctx context.Context) error {
+	codigo, err := s.client.CodeAt(ctx, s.contractAddress, nil)
+	if err != nil {
+		return fmt.Errorf("error obteniendo el código del contrato: %w", err)
+	}
+	if len(codigo) == 0 {
+		return fmt.Errorf("la dirección %s no tiene un contrato desplegado", s.contractAddress.Hex())
+	}
+	return nil
+}
+
 // VerificarConexion verifica la conexión con la blockchain
 func (s *BlockchainService) VerificarConexion(ctx context.Context) error {
 	_, err := s.client.BlockNumber(ctx)
@@ -339,7 +516,7 @@ func (s *BlockchainService) GetTransactionOpts(ctx context.Context) (*bind.Trans
 
 	auth.Nonce = big.NewInt(int64(nonce))
 	auth.Value = big.NewInt(0)
-	auth.GasLimit = uint64(300000)
+	auth.GasLimit = gasLimiteContrato
 	auth.GasPrice = gasPrice
 
 	return auth, nil
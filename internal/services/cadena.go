@@ -0,0 +1,112 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+)
+
+// VerificarCadenaProducto recorre la cadena de hashes de un producto y reporta huecos, bifurcaciones,
+// eventos eliminados y enlaces rotos. Los eventos registrados antes del encadenamiento (Secuencia 0)
+// solo se cuentan, ya que no tienen enlace que verificar.
+func VerificarCadenaProducto(transacciones []*models.Transaccion, cabeza *models.CabezaCadena) *models.VerificacionCadena {
+	return VerificarCadenaProductoEn(i18n.IdiomaPorDefecto, transacciones, cabeza)
+}
+
+// VerificarCadenaProductoEn es VerificarCadenaProducto con el detalle de las anomalías en el idioma indicado
+func VerificarCadenaProductoEn(idioma i18n.Idioma, transacciones []*models.Transaccion, cabeza *models.CabezaCadena) *models.VerificacionCadena {
+	resultado := &models.VerificacionCadena{
+		Anomalias: make([]models.AnomaliaCadena, 0),
+	}
+
+	porSecuencia := make(map[int64][]*models.Transaccion)
+	var ultima int64
+	for _, transaccion := range transacciones {
+		if transaccion.Secuencia <= 0 {
+			resultado.EventosSinEncadenar++
+			continue
+		}
+		porSecuencia[transaccion.Secuencia] = append(porSecuencia[transaccion.Secuencia], transaccion)
+		if transaccion.Secuencia > ultima {
+			ultima = transaccion.Secuencia
+		}
+	}
+
+	anomalia := func(tipo string, secuencia int64, idEvento, clave string, args ...interface{}) {
+		resultado.Anomalias = append(resultado.Anomalias, models.AnomaliaCadena{
+			Tipo:      tipo,
+			Secuencia: secuencia,
+			IDEvento:  idEvento,
+			Detalle:   i18n.Traducir(idioma, clave, args...),
+		})
+	}
+
+	// hashesAnteriores contiene los hashes de la posición previa; vacío si la posición previa falta
+	hashesAnteriores := make(map[string]bool)
+	for secuencia := int64(1); secuencia <= ultima; secuencia++ {
+		eventos := porSecuencia[secuencia]
+		if len(eventos) == 0 {
+			anomalia(models.AnomaliaHueco, secuencia, "", "cadena.hueco", secuencia)
+			hashesAnteriores = map[string]bool{}
+			continue
+		}
+
+		if len(eventos) > 1 {
+			ids := make([]string, 0, len(eventos))
+			for _, evento := range eventos {
+				ids = append(ids, evento.IDTransaction)
+			}
+			anomalia(models.AnomaliaBifurcacion, secuencia, "", "cadena.bifurcacion", len(eventos), secuencia, strings.Join(ids, ", "))
+		}
+
+		hashesActuales := make(map[string]bool, len(eventos))
+		for _, evento := range eventos {
+			if utils.CalcularHashTransaccion(evento) != evento.HashEvento {
+				anomalia(models.AnomaliaRuptura, secuencia, evento.IDTransaction, "cadena.hash-no-corresponde")
+			}
+
+			switch {
+			case secuencia == 1 && evento.HashEventoAnterior != "":
+				anomalia(models.AnomaliaRuptura, secuencia, evento.IDTransaction, "cadena.primer-evento-enlazado")
+			case secuencia > 1 && len(hashesAnteriores) > 0 && !hashesAnteriores[evento.HashEventoAnterior]:
+				anomalia(models.AnomaliaRuptura, secuencia, evento.IDTransaction, "cadena.enlace-roto", secuencia-1)
+			}
+
+			hashesActuales[evento.HashEvento] = true
+		}
+		hashesAnteriores = hashesActuales
+	}
+
+	// La cabeza registrada permite detectar eventos eliminados al final de la cadena
+	resultado.Longitud = ultima
+	switch {
+	case cabeza == nil && ultima > 0:
+		anomalia(models.AnomaliaEliminacion, ultima, "", "cadena.sin-cabeza")
+	case cabeza == nil:
+	case cabeza.Secuencia > ultima:
+		resultado.Longitud = cabeza.Secuencia
+		anomalia(models.AnomaliaEliminacion, cabeza.Secuencia, cabeza.IDTransaction, "cadena.eventos-eliminados", ultima+1, cabeza.Secuencia, cabeza.IDTransaction)
+	case cabeza.Secuencia < ultima:
+		anomalia(models.AnomaliaBifurcacion, ultima, "", "cadena.eventos-posteriores-a-cabeza", cabeza.Secuencia)
+	case !hashesAnteriores[cabeza.HashEvento]:
+		anomalia(models.AnomaliaRuptura, cabeza.Secuencia, cabeza.IDTransaction, "cadena.cabeza-no-coincide")
+	}
+
+	resultado.Integra = len(resultado.Anomalias) == 0
+	return resultado
+}
+
+// ordenarPorCadena ordena los eventos por su posición en la cadena. Los eventos sin encadenar
+// (anteriores al encadenamiento) van primero, por fecha.
+func ordenarPorCadena(transacciones []*models.Transaccion) {
+	sort.SliceStable(transacciones, func(i, j int) bool {
+		a, b := transacciones[i], transacciones[j]
+		if a.Secuencia != b.Secuencia {
+			return a.Secuencia < b.Secuencia
+		}
+		return a.FechaEvento.Before(b.FechaEvento)
+	})
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// ErrIdentidadCertificadoNoEncontrada indica que no hay una identidad de certificado registrada con ese valor
+var ErrIdentidadCertificadoNoEncontrada = NuevoErrorDominio(TipoNoEncontrado, "identidad-certificado-no-encontrada", "identidad de certificado no encontrada")
+
+// AlmacenCertificados persiste el registro de identidades de certificados de cliente.
+// DynamoDBService lo implementa sobre la tabla de control.
+type AlmacenCertificados interface {
+	GuardarIdentidadCertificado(ctx context.Context, identidad *models.IdentidadCertificado) error
+	ObtenerIdentidadCertificado(ctx context.Context, identidad string) (*models.IdentidadCertificado, error)
+	ListarIdentidadesCertificado(ctx context.Context) ([]*models.IdentidadCertificado, error)
+}
+
+// CertificadoService asocia certificados de cliente verificados por mTLS a actores registrados
+type CertificadoService struct {
+	almacen AlmacenCertificados
+}
+
+// NewCertificadoService crea una nueva instancia de CertificadoService
+func NewCertificadoService(almacen AlmacenCertificados) *CertificadoService {
+	return &CertificadoService{almacen: almacen}
+}
+
+// RegistrarIdentidad asocia una identidad de certificado a un actor; reemplaza un registro previo
+func (s *CertificadoService) RegistrarIdentidad(ctx context.Context, req *models.IdentidadCertificadoRequest) (*models.IdentidadCertificado, error) {
+	identidad := &models.IdentidadCertificado{
+		Identidad: req.Identidad,
+		Nombre:    req.Nombre,
+		Actor:     req.Actor,
+		Roles:     req.Roles,
+		CreadaEn:  time.Now().UTC(),
+	}
+	if identidad.Roles == nil {
+		identidad.Roles = []string{}
+	}
+
+	if err := s.almacen.GuardarIdentidadCertificado(ctx, identidad); err != nil {
+		return nil, fmt.Errorf("error guardando identidad de certificado: %w", err)
+	}
+	return identidad, nil
+}
+
+// Autenticar busca el actor del certificado. Se prueban en orden los SAN (URI, DNS, email)
+// y después el DN del subject; decide la primera identidad registrada: si está revocada, el
+// certificado se rechaza aunque otra identidad posterior siga activa.
+func (s *CertificadoService) Autenticar(ctx context.Context, certificado *x509.Certificate) (*models.Principal, error) {
+	for _, candidata := range IdentidadesDeCertificado(certificado) {
+		identidad, err := s.almacen.ObtenerIdentidadCertificado(ctx, candidata)
+		if errors.Is(err, ErrIdentidadCertificadoNoEncontrada) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo identidad de certificado: %w", err)
+		}
+		if identidad.RevocadaEn != nil {
+			return nil, ErrCredencialesInvalidas
+		}
+
+		return &models.Principal{
+			ID:     identidad.Identidad,
+			Actor:  identidad.Actor,
+			Roles:  identidad.Roles,
+			Metodo: models.MetodoCertificado,
+		}, nil
+	}
+	return nil, ErrCredencialesInvalidas
+}
+
+// ListarIdentidades lista las identidades de certificado registradas
+func (s *CertificadoService) ListarIdentidades(ctx context.Context) ([]*models.IdentidadCertificado, error) {
+	return s.almacen.ListarIdentidadesCertificado(ctx)
+}
+
+// RevocarIdentidad marca una identidad como revocada; el certificado deja de autenticar de inmediato
+func (s *CertificadoService) RevocarIdentidad(ctx context.Context, valor string) (*models.IdentidadCertificado, error) {
+	identidad, err := s.almacen.ObtenerIdentidadCertificado(ctx, valor)
+	if err != nil {
+		return nil, err
+	}
+	if identidad.RevocadaEn == nil {
+		ahora := time.Now().UTC()
+		identidad.RevocadaEn = &ahora
+		if err := s.almacen.GuardarIdentidadCertificado(ctx, identidad); err != nil {
+			return nil, fmt.Errorf("error revocando identidad de certificado: %w", err)
+		}
+	}
+	return identidad, nil
+}
+
+// IdentidadesDeCertificado lista las identidades candidatas de un certificado en orden de preferencia
+func IdentidadesDeCertificado(certificado *x509.Certificate) []string {
+	identidades := make([]string, 0, len(certificado.URIs)+len(certificado.DNSNames)+len(certificado.EmailAddresses)+1)
+	for _, uri := range certificado.URIs {
+		identidades = append(identidades, uri.String())
+	}
+	identidades = append(identidades, certificado.DNSNames...)
+	identidades = append(identidades, certificado.EmailAddresses...)
+	if subject := certificado.Subject.String(); subject != "" {
+		identidades = append(identidades, subject)
+	}
+	return identidades
+}
+
+// AlmacenCertificadosMemoria es un AlmacenCertificados en memoria, útil para desarrollo y tests
+type AlmacenCertificadosMemoria struct {
+	mu          sync.RWMutex
+	identidades map[string]models.IdentidadCertificado
+}
+
+// NewAlmacenCertificadosMemoria crea un almacén de identidades de certificado en memoria
+func NewAlmacenCertificadosMemoria() *AlmacenCertificadosMemoria {
+	return &AlmacenCertificadosMemoria{identidades: make(map[string]models.IdentidadCertificado)}
+}
+
+// GuardarIdentidadCertificado guarda o reemplaza una identidad
+func (a *AlmacenCertificadosMemoria) GuardarIdentidadCertificado(ctx context.Context, identidad *models.IdentidadCertificado) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.identidades[identidad.Identidad] = *identidad
+	return nil
+}
+
+// ObtenerIdentidadCertificado obtiene una identidad por su valor
+func (a *AlmacenCertificadosMemoria) ObtenerIdentidadCertificado(ctx context.Context, valor string) (*models.IdentidadCertificado, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	identidad, ok := a.identidades[valor]
+	if !ok {
+		return nil, ErrIdentidadCertificadoNoEncontrada
+	}
+	return &identidad, nil
+}
+
+// ListarIdentidadesCertificado lista las identidades ordenadas por fecha de registro
+func (a *AlmacenCertificadosMemoria) ListarIdentidadesCertificado(ctx context.Context) ([]*models.IdentidadCertificado, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	lista := make([]*models.IdentidadCertificado, 0, len(a.identidades))
+	for _, identidad := range a.identidades {
+		copia := identidad
+		lista = append(lista, &copia)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].CreadaEn.Before(lista[j].CreadaEn) })
+	return lista, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
+)
+
+// InstrumentarDynamoDB agrega al cliente de DynamoDB un middleware que abre un span por operación
+// (dynamodb.<Operacion>) y mide su latencia (medisupply_dynamodb_duracion_segundos). Se pasa como
+// opción a dynamodb.NewFromConfig.
+func InstrumentarDynamoDB(opciones *dynamodb.Options) {
+	opciones.APIOptions = append(opciones.APIOptions, func(pila *middleware.Stack) error {
+		// Al final de Initialize el nombre de la operación ya está en el contexto y la medición
+		// incluye la serialización, la firma y los reintentos del SDK
+		return pila.Initialize.Add(middleware.InitializeMiddlewareFunc("InstrumentacionDynamoDB",
+			func(ctx context.Context, entrada middleware.InitializeInput, siguiente middleware.InitializeHandler) (salida middleware.InitializeOutput, metadatos middleware.Metadata, err error) {
+				operacion := awsmiddleware.GetOperationName(ctx)
+				ctx, span := trazas.Iniciar(ctx, "dynamodb."+operacion,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(semconv.DBSystemDynamoDB, semconv.DBOperation(operacion)),
+				)
+				defer trazas.Terminar(span, &err)
+
+				inicio := time.Now()
+				salida, metadatos, err = siguiente.HandleInitialize(ctx, entrada)
+				metricas.ObservarDynamoDB(operacion, inicio, err)
+				return salida, metadatos, err
+			}), middleware.After)
+	})
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,15 +18,36 @@ import (
 
 // DynamoDBService maneja las operaciones con DynamoDB
 type DynamoDBService struct {
-	client    *dynamodb.Client
-	tableName string
+	client           *dynamodb.Client
+	tableName        string
+	controlTableName string // Tabla auxiliar (clave "pk") para las cabezas de cadena
 }
 
+// ErrConflictoCadena indica que otro registro avanzó la cadena del producto antes que esta escritura
+var ErrConflictoCadena = NuevoErrorDominio(TipoConflicto, "conflicto-cadena", "la cadena del producto cambió durante el registro")
+
+// Prefijos de clave de los registros de la tabla de control
+const (
+	prefijoCadena         = "CADENA#"
+	prefijoRegistroAPIKey = "APIKEY#"
+	prefijoCertificado    = "CERT#"
+	prefijoIdempotencia   = "IDEMP#"
+	prefijoEstadoOracle   = "ORACLE#"
+	prefijoWebhook        = "WEBHOOK#"
+	prefijoEntregaWebhook = "WHENTREGA#"
+	prefijoRateLimit      = "RATELIMIT#"
+)
+
+// indiceEntregasPendientes es el GSI disperso de la tabla de control con las entregas de webhook
+// pendientes: clave colaEntrega (solo la tienen las pendientes) y orden proximoIntento
+const indiceEntregasPendientes = "entregas-pendientes"
+
 // NewDynamoDBService crea una nueva instancia de DynamoDBService
-func NewDynamoDBService(client *dynamodb.Client, tableName string) *DynamoDBService {
+func NewDynamoDBService(client *dynamodb.Client, tableName, controlTableName string) *DynamoDBService {
 	return &DynamoDBService{
-		client:    client,
-		tableName: tableName,
+		client:           client,
+		tableName:        tableName,
+		controlTableName: controlTableName,
 	}
 }
 
@@ -36,7 +60,6 @@ func (s *DynamoDBService) GuardarTransaccion(ctx context.Context, transaccion *m
 
 	// Convertir a attributevalue
 	item, err := attributevalue.MarshalMap(transaccion)
-	fmt.Println("Guardando transacción en DynamoDB con ID:", transaccion.IDTransaction)
 	if err != nil {
 		return fmt.Errorf("error marshaling transacción: %w", err)
 	}
@@ -46,14 +69,365 @@ func (s *DynamoDBService) GuardarTransaccion(ctx context.Context, transaccion *m
 		TableName: aws.String(s.tableName),
 		Item:      item,
 	})
-	fmt.Println("Transacción guardada en DynamoDB con ID:", transaccion.IDTransaction)
 	if err != nil {
 		return fmt.Errorf("error guardando en DynamoDB: %w", err)
 	}
+	slog.DebugContext(ctx, "transacción guardada en DynamoDB", "id_transaccion", transaccion.IDTransaction)
 
 	return nil
 }
 
+// ObtenerCabezaCadena obtiene el último eslabón registrado de la cadena de un producto.
+// Retorna nil si el producto aún no tiene eventos encadenados.
+func (s *DynamoDBService) ObtenerCabezaCadena(ctx context.Context, idProducto string) (*models.CabezaCadena, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoCadena + idProducto},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo cabeza de cadena: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var cabeza models.CabezaCadena
+	if err := attributevalue.UnmarshalMap(result.Item, &cabeza); err != nil {
+		return nil, fmt.Errorf("error unmarshaling cabeza de cadena: %w", err)
+	}
+
+	return &cabeza, nil
+}
+
+// GuardarTransaccionEncadenada guarda la transacción y avanza la cabeza de cadena de su producto en
+// una sola transacción de DynamoDB. La escritura solo se aplica si la cabeza sigue siendo anterior
+// (nil si el producto no tenía cabeza); si otro registro la avanzó primero retorna ErrConflictoCadena.
+// El propietario del producto es el actor del primer evento encadenado y se conserva en cada avance.
+func (s *DynamoDBService) GuardarTransaccionEncadenada(ctx context.Context, transaccion *models.Transaccion, anterior *models.CabezaCadena) error {
+	return s.GuardarTransaccionesEncadenadas(ctx, []*models.Transaccion{transaccion}, anterior)
+}
+
+// MaxEventosEncadenados es el máximo de eventos por GuardarTransaccionesEncadenadas: TransactWriteItems
+// admite 100 elementos y uno es la cabeza de cadena
+const MaxEventosEncadenados = 99
+
+// GuardarTransaccionesEncadenadas guarda eventos consecutivos de un mismo producto, ya encadenados a
+// partir de anterior, junto con el avance de la cabeza hasta el último, en una sola TransactWriteItems.
+// Si otro registro avanzó la cabeza no se escribe ninguno y retorna ErrConflictoCadena, así la cadena
+// nunca se bifurca ni quedan eventos huérfanos.
+func (s *DynamoDBService) GuardarTransaccionesEncadenadas(ctx context.Context, transacciones []*models.Transaccion, anterior *models.CabezaCadena) error {
+	if len(transacciones) == 0 || len(transacciones) > MaxEventosEncadenados {
+		return fmt.Errorf("se pueden encadenar de 1 a %d eventos por transacción, se recibieron %d", MaxEventosEncadenados, len(transacciones))
+	}
+
+	now := time.Now()
+	elementos := make([]types.TransactWriteItem, 0, len(transacciones)+1)
+	for _, transaccion := range transacciones {
+		transaccion.CreatedAt = now
+		transaccion.UpdatedAt = now
+		item, err := attributevalue.MarshalMap(transaccion)
+		if err != nil {
+			return fmt.Errorf("error marshaling transacción %s: %w", transaccion.IDTransaction, err)
+		}
+		elementos = append(elementos, types.TransactWriteItem{Put: &types.Put{
+			TableName:           aws.String(s.tableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(idTransaction)"),
+		}})
+	}
+
+	putCabeza, err := s.putCabezaCadena(transacciones[len(transacciones)-1], anterior)
+	if err != nil {
+		return err
+	}
+	elementos = append(elementos, types.TransactWriteItem{Put: putCabeza})
+
+	_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: elementos})
+	if err != nil {
+		var cancelada *types.TransactionCanceledException
+		if errors.As(err, &cancelada) {
+			for _, razon := range cancelada.CancellationReasons {
+				if aws.ToString(razon.Code) == "ConditionalCheckFailed" {
+					return ErrConflictoCadena
+				}
+			}
+		}
+		return fmt.Errorf("error guardando transacción encadenada: %w", err)
+	}
+
+	return nil
+}
+
+// GuardarAPIKey guarda o reemplaza una API key en la tabla de control
+func (s *DynamoDBService) GuardarAPIKey(ctx context.Context, apiKey *models.APIKey) error {
+	item, err := attributevalue.MarshalMap(apiKey)
+	if err != nil {
+		return fmt.Errorf("error marshaling API key: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: prefijoRegistroAPIKey + apiKey.ID}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.controlTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando API key: %w", err)
+	}
+
+	return nil
+}
+
+// ObtenerAPIKey obtiene una API key por ID; retorna ErrAPIKeyNoEncontrada si no existe
+func (s *DynamoDBService) ObtenerAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoRegistroAPIKey + id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo API key: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrAPIKeyNoEncontrada
+	}
+
+	var apiKey models.APIKey
+	if err := attributevalue.UnmarshalMap(result.Item, &apiKey); err != nil {
+		return nil, fmt.Errorf("error unmarshaling API key: %w", err)
+	}
+
+	return &apiKey, nil
+}
+
+// ListarAPIKeys lista todas las API keys de la tabla de control
+func (s *DynamoDBService) ListarAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:        aws.String(s.controlTableName),
+		FilterExpression: aws.String("begins_with(pk, :prefijo)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefijo": &types.AttributeValueMemberS{Value: prefijoRegistroAPIKey},
+		},
+	})
+
+	apiKeys := make([]*models.APIKey, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listando API keys: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var apiKey models.APIKey
+			if err := attributevalue.UnmarshalMap(item, &apiKey); err != nil {
+				continue
+			}
+			apiKeys = append(apiKeys, &apiKey)
+		}
+	}
+
+	return apiKeys, nil
+}
+
+// GuardarIdentidadCertificado guarda o reemplaza una identidad de certificado en la tabla de control
+func (s *DynamoDBService) GuardarIdentidadCertificado(ctx context.Context, identidad *models.IdentidadCertificado) error {
+	item, err := attributevalue.MarshalMap(identidad)
+	if err != nil {
+		return fmt.Errorf("error marshaling identidad de certificado: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: prefijoCertificado + identidad.Identidad}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.controlTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando identidad de certificado: %w", err)
+	}
+
+	return nil
+}
+
+// ObtenerIdentidadCertificado obtiene una identidad de certificado; retorna ErrIdentidadCertificadoNoEncontrada si no existe
+func (s *DynamoDBService) ObtenerIdentidadCertificado(ctx context.Context, valor string) (*models.IdentidadCertificado, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoCertificado + valor},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo identidad de certificado: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrIdentidadCertificadoNoEncontrada
+	}
+
+	var identidad models.IdentidadCertificado
+	if err := attributevalue.UnmarshalMap(result.Item, &identidad); err != nil {
+		return nil, fmt.Errorf("error unmarshaling identidad de certificado: %w", err)
+	}
+
+	return &identidad, nil
+}
+
+// ListarIdentidadesCertificado lista las identidades de certificado de la tabla de control
+func (s *DynamoDBService) ListarIdentidadesCertificado(ctx context.Context) ([]*models.IdentidadCertificado, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:        aws.String(s.controlTableName),
+		FilterExpression: aws.String("begins_with(pk, :prefijo)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefijo": &types.AttributeValueMemberS{Value: prefijoCertificado},
+		},
+	})
+
+	identidades := make([]*models.IdentidadCertificado, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listando identidades de certificado: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var identidad models.IdentidadCertificado
+			if err := attributevalue.UnmarshalMap(item, &identidad); err != nil {
+				continue
+			}
+			identidades = append(identidades, &identidad)
+		}
+	}
+
+	return identidades, nil
+}
+
+// ReservarIdempotencia crea el registro de idempotencia con una escritura condicional. Si la clave
+// ya está tomada por un registro vigente, lo retorna (lectura consistente).
+func (s *DynamoDBService) ReservarIdempotencia(ctx context.Context, registro *models.RegistroIdempotencia) (*models.RegistroIdempotencia, error) {
+	item, err := attributevalue.MarshalMap(registro)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling registro de idempotencia: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: prefijoIdempotencia + registro.Clave}
+
+	// Pocos intentos: solo se repite si el registro desaparece entre la escritura fallida y la lectura
+	for intento := 0; intento < 3; intento++ {
+		ahora := time.Now()
+		_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(s.controlTableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(pk) OR expiraEn <= :ahora OR (estado = :enCurso AND bloqueoHasta < :ahoraMs)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":ahora":   &types.AttributeValueMemberN{Value: strconv.FormatInt(ahora.Unix(), 10)},
+				":ahoraMs": &types.AttributeValueMemberN{Value: strconv.FormatInt(ahora.UnixMilli(), 10)},
+				":enCurso": &types.AttributeValueMemberS{Value: models.IdempotenciaEnCurso},
+			},
+		})
+		if err == nil {
+			return nil, nil
+		}
+		var condicion *types.ConditionalCheckFailedException
+		if !errors.As(err, &condicion) {
+			return nil, fmt.Errorf("error reservando registro de idempotencia: %w", err)
+		}
+
+		result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(s.controlTableName),
+			ConsistentRead: aws.Bool(true),
+			Key: map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: prefijoIdempotencia + registro.Clave},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo registro de idempotencia: %w", err)
+		}
+		if result.Item == nil {
+			continue
+		}
+
+		var existente models.RegistroIdempotencia
+		if err := attributevalue.UnmarshalMap(result.Item, &existente); err != nil {
+			return nil, fmt.Errorf("error unmarshaling registro de idempotencia: %w", err)
+		}
+		return &existente, nil
+	}
+
+	return nil, fmt.Errorf("error reservando registro de idempotencia: la clave cambió durante la reserva")
+}
+
+// GuardarIdempotencia guarda o reemplaza un registro de idempotencia
+func (s *DynamoDBService) GuardarIdempotencia(ctx context.Context, registro *models.RegistroIdempotencia) error {
+	item, err := attributevalue.MarshalMap(registro)
+	if err != nil {
+		return fmt.Errorf("error marshaling registro de idempotencia: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: prefijoIdempotencia + registro.Clave}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.controlTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando registro de idempotencia: %w", err)
+	}
+
+	return nil
+}
+
+// EliminarIdempotencia elimina un registro de idempotencia
+func (s *DynamoDBService) EliminarIdempotencia(ctx context.Context, clave string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoIdempotencia + clave},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error eliminando registro de idempotencia: %w", err)
+	}
+
+	return nil
+}
+
+// putCabezaCadena construye la escritura condicional que avanza la cabeza de cadena hasta la transacción.
+// Solo se aplica si la cabeza sigue siendo anterior (nil si el producto no tenía cabeza).
+func (s *DynamoDBService) putCabezaCadena(transaccion *models.Transaccion, anterior *models.CabezaCadena) (*types.Put, error) {
+	propietario := transaccion.ActorEmisor
+	if anterior != nil && anterior.Propietario != "" {
+		propietario = anterior.Propietario
+	}
+
+	cabeza, err := attributevalue.MarshalMap(models.CabezaCadena{
+		IDProducto:    transaccion.IDProducto,
+		Secuencia:     transaccion.Secuencia,
+		HashEvento:    transaccion.HashEvento,
+		IDTransaction: transaccion.IDTransaction,
+		Propietario:   propietario,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling cabeza de cadena: %w", err)
+	}
+	cabeza["pk"] = &types.AttributeValueMemberS{Value: prefijoCadena + transaccion.IDProducto}
+
+	putCabeza := &types.Put{
+		TableName:           aws.String(s.controlTableName),
+		Item:                cabeza,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	}
+	if anterior != nil {
+		putCabeza.ConditionExpression = aws.String("secuencia = :anterior")
+		putCabeza.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":anterior": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", anterior.Secuencia)},
+		}
+	}
+	return putCabeza, nil
+}
+
 // ObtenerTransaccion obtiene una transacción por ID
 func (s *DynamoDBService) ObtenerTransaccion(ctx context.Context, idTransaccion string) (*models.Transaccion, error) {
 	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
@@ -67,7 +441,7 @@ func (s *DynamoDBService) ObtenerTransaccion(ctx context.Context, idTransaccion
 	}
 
 	if result.Item == nil {
-		return nil, fmt.Errorf("transacción no encontrada")
+		return nil, ErrTransaccionNoEncontrada
 	}
 
 	var transaccion models.Transaccion
@@ -79,7 +453,8 @@ func (s *DynamoDBService) ObtenerTransaccion(ctx context.Context, idTransaccion
 	return &transaccion, nil
 }
 
-// ObtenerTransaccionesPorProducto obtiene todas las transacciones de un producto
+// ObtenerTransaccionesPorProducto obtiene todas las transacciones de un producto.
+// Recorre todas las páginas del scan: un historial truncado se confundiría con eventos eliminados.
 func (s *DynamoDBService) ObtenerTransaccionesPorProducto(ctx context.Context, idProducto string) ([]*models.Transaccion, error) {
 	// Usar GSI (Global Secondary Index) si está configurado, o hacer scan
 	input := &dynamodb.ScanInput{
@@ -90,18 +465,21 @@ func (s *DynamoDBService) ObtenerTransaccionesPorProducto(ctx context.Context, i
 		},
 	}
 
-	result, err := s.client.Scan(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("error scanning DynamoDB: %w", err)
-	}
-
 	var transacciones []*models.Transaccion
-	for _, item := range result.Items {
-		var transaccion models.Transaccion
-		if err := attributevalue.UnmarshalMap(item, &transaccion); err != nil {
-			continue // Skip items que no se pueden unmarshal
+	paginator := dynamodb.NewScanPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning DynamoDB: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var transaccion models.Transaccion
+			if err := attributevalue.UnmarshalMap(item, &transaccion); err != nil {
+				continue // Skip items que no se pueden unmarshal
+			}
+			transacciones = append(transacciones, &transaccion)
 		}
-		transacciones = append(transacciones, &transaccion)
 	}
 
 	return transacciones, nil
@@ -129,6 +507,100 @@ func (s *DynamoDBService) ActualizarHashesBlockchain(ctx context.Context, idTran
 	return nil
 }
 
+// ActualizarNodosIPFS actualiza los destinos IPFS en los que está pineado el CID de una transacción
+func (s *DynamoDBService) ActualizarNodosIPFS(ctx context.Context, idTransaccion string, nodos []string) error {
+	nodosAV, err := attributevalue.Marshal(nodos)
+	if err != nil {
+		return fmt.Errorf("error marshaling nodos IPFS: %w", err)
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"idTransaction": &types.AttributeValueMemberS{Value: idTransaccion},
+		},
+		UpdateExpression: aws.String("SET nodosIPFS = :nodos, updatedAt = :updatedAt"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":nodos":     nodosAV,
+			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error actualizando nodos IPFS: %w", err)
+	}
+
+	return nil
+}
+
+// ActualizarNodosAdjunto actualiza los destinos IPFS en los que está pineado un adjunto de la transacción
+func (s *DynamoDBService) ActualizarNodosAdjunto(ctx context.Context, idTransaccion string, indice int, nodos []string) error {
+	nodosAV, err := attributevalue.Marshal(nodos)
+	if err != nil {
+		return fmt.Errorf("error marshaling nodos IPFS: %w", err)
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"idTransaction": &types.AttributeValueMemberS{Value: idTransaccion},
+		},
+		UpdateExpression: aws.String(fmt.Sprintf("SET adjuntos[%d].nodosIPFS = :nodos, updatedAt = :updatedAt", indice)),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":nodos":     nodosAV,
+			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error actualizando nodos IPFS del adjunto: %w", err)
+	}
+
+	return nil
+}
+
+// RecorrerTransacciones recorre todas las transacciones de la tabla página por página
+// y ejecuta fn sobre cada una. Se detiene en el primer error retornado por fn.
+func (s *DynamoDBService) RecorrerTransacciones(ctx context.Context, fn func(*models.Transaccion) error) error {
+	return s.recorrer(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(s.tableName),
+	}, fn)
+}
+
+// RecorrerTransaccionesPendientes recorre las transacciones que siguen en estado pendiente, es decir,
+// las que aún no se anclaron en blockchain
+func (s *DynamoDBService) RecorrerTransaccionesPendientes(ctx context.Context, fn func(*models.Transaccion) error) error {
+	return s.recorrer(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(s.tableName),
+		FilterExpression: aws.String("estado = :pendiente"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pendiente": &types.AttributeValueMemberS{Value: "pendiente"},
+		},
+	}, fn)
+}
+
+// recorrer pagina el Scan de entrada y ejecuta fn sobre cada transacción
+func (s *DynamoDBService) recorrer(ctx context.Context, entrada *dynamodb.ScanInput, fn func(*models.Transaccion) error) error {
+	paginator := dynamodb.NewScanPaginator(s.client, entrada)
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error scanning DynamoDB: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var transaccion models.Transaccion
+			if err := attributevalue.UnmarshalMap(item, &transaccion); err != nil {
+				continue
+			}
+			if err := fn(&transaccion); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // ActualizarEstado actualiza el estado de una transacción
 func (s *DynamoDBService) ActualizarEstado(ctx context.Context, idTransaccion, estado string) error {
 	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
@@ -175,3 +647,383 @@ func (s *DynamoDBService) ListarTransacciones(ctx context.Context, limit int32)
 
 	return transacciones, nil
 }
+
+// IntercambiarEstadoOracle guarda el estado del Oracle de un producto y retorna el anterior ("" si no tenía)
+func (s *DynamoDBService) IntercambiarEstadoOracle(ctx context.Context, idProducto, estado string) (string, error) {
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoEstadoOracle + idProducto},
+		},
+		UpdateExpression: aws.String("SET estado = :estado, actualizadoEn = :actualizadoEn"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":estado":        &types.AttributeValueMemberS{Value: estado},
+			":actualizadoEn": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+		ReturnValues: types.ReturnValueUpdatedOld,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error guardando estado del Oracle: %w", err)
+	}
+
+	anterior, _ := result.Attributes["estado"].(*types.AttributeValueMemberS)
+	if anterior == nil {
+		return "", nil
+	}
+	return anterior.Value, nil
+}
+
+// GuardarWebhook guarda o reemplaza un webhook en la tabla de control
+func (s *DynamoDBService) GuardarWebhook(ctx context.Context, webhook *models.Webhook) error {
+	item, err := attributevalue.MarshalMap(webhook)
+	if err != nil {
+		return fmt.Errorf("error marshaling webhook: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: prefijoWebhook + webhook.ID}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.controlTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ObtenerWebhook obtiene un webhook por ID; retorna ErrWebhookNoEncontrado si no existe
+func (s *DynamoDBService) ObtenerWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoWebhook + id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo webhook: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrWebhookNoEncontrado
+	}
+
+	var webhook models.Webhook
+	if err := attributevalue.UnmarshalMap(result.Item, &webhook); err != nil {
+		return nil, fmt.Errorf("error unmarshaling webhook: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// ListarWebhooks lista todos los webhooks de la tabla de control
+func (s *DynamoDBService) ListarWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:        aws.String(s.controlTableName),
+		FilterExpression: aws.String("begins_with(pk, :prefijo)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefijo": &types.AttributeValueMemberS{Value: prefijoWebhook},
+		},
+	})
+
+	webhooks := make([]*models.Webhook, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listando webhooks: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var webhook models.Webhook
+			if err := attributevalue.UnmarshalMap(item, &webhook); err != nil {
+				continue
+			}
+			webhooks = append(webhooks, &webhook)
+		}
+	}
+
+	return webhooks, nil
+}
+
+// EliminarWebhook elimina un webhook; sus entregas se conservan hasta que expire su TTL
+func (s *DynamoDBService) EliminarWebhook(ctx context.Context, id string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoWebhook + id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error eliminando webhook: %w", err)
+	}
+
+	return nil
+}
+
+// GuardarEntrega guarda o reemplaza una entrega de webhook
+func (s *DynamoDBService) GuardarEntrega(ctx context.Context, entrega *models.EntregaWebhook) error {
+	item, err := attributevalue.MarshalMap(entrega)
+	if err != nil {
+		return fmt.Errorf("error marshaling entrega: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: prefijoEntregaWebhook + entrega.ID}
+	if entrega.Estado == models.EntregaPendiente {
+		// Solo las pendientes entran al índice: al reemplazar el item con otro estado salen de la cola
+		item["colaEntrega"] = &types.AttributeValueMemberS{Value: models.EntregaPendiente}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.controlTableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando entrega: %w", err)
+	}
+
+	return nil
+}
+
+// ObtenerEntrega obtiene una entrega por ID; retorna ErrEntregaNoEncontrada si no existe
+func (s *DynamoDBService) ObtenerEntrega(ctx context.Context, id string) (*models.EntregaWebhook, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.controlTableName),
+		ConsistentRead: aws.Bool(true),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoEntregaWebhook + id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo entrega: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrEntregaNoEncontrada
+	}
+
+	var entrega models.EntregaWebhook
+	if err := attributevalue.UnmarshalMap(result.Item, &entrega); err != nil {
+		return nil, fmt.Errorf("error unmarshaling entrega: %w", err)
+	}
+
+	return &entrega, nil
+}
+
+// ListarEntregas lista las entregas de un webhook
+func (s *DynamoDBService) ListarEntregas(ctx context.Context, webhookID string) ([]*models.EntregaWebhook, error) {
+	return s.buscarEntregas(ctx, "begins_with(pk, :prefijo) AND webhookId = :webhookId", map[string]types.AttributeValue{
+		":prefijo":   &types.AttributeValueMemberS{Value: prefijoEntregaWebhook},
+		":webhookId": &types.AttributeValueMemberS{Value: webhookID},
+	})
+}
+
+// EntregasVencidas retorna hasta limite entregas pendientes, vencidas y sin bloqueo vigente. Consulta el
+// índice de pendientes en lugar de recorrer la tabla de control, que también guarda cabezas, claves de
+// idempotencia y contadores de rate limit. El índice es eventualmente consistente: ReclamarEntrega vuelve
+// a comprobar el estado antes de enviar.
+func (s *DynamoDBService) EntregasVencidas(ctx context.Context, ahora time.Time, limite int) ([]*models.EntregaWebhook, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.controlTableName),
+		IndexName:              aws.String(indiceEntregasPendientes),
+		KeyConditionExpression: aws.String("colaEntrega = :pendiente AND proximoIntento <= :ahora"),
+		FilterExpression:       aws.String("bloqueoHasta < :ahoraMs"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pendiente": &types.AttributeValueMemberS{Value: models.EntregaPendiente},
+			":ahora":     &types.AttributeValueMemberN{Value: strconv.FormatInt(ahora.Unix(), 10)},
+			":ahoraMs":   &types.AttributeValueMemberN{Value: strconv.FormatInt(ahora.UnixMilli(), 10)},
+		},
+	})
+
+	entregas := make([]*models.EntregaWebhook, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error consultando entregas vencidas: %w", err)
+		}
+
+		var completo bool
+		if entregas, completo = agregarEntregas(entregas, page.Items, limite); completo {
+			break
+		}
+	}
+
+	return entregas, nil
+}
+
+// buscarEntregas recorre las entregas de la tabla de control que cumplen el filtro
+func (s *DynamoDBService) buscarEntregas(ctx context.Context, filtro string, valores map[string]types.AttributeValue) ([]*models.EntregaWebhook, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:                 aws.String(s.controlTableName),
+		FilterExpression:          aws.String(filtro),
+		ExpressionAttributeValues: valores,
+	})
+
+	entregas := make([]*models.EntregaWebhook, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listando entregas: %w", err)
+		}
+		entregas, _ = agregarEntregas(entregas, page.Items, 0)
+	}
+
+	return entregas, nil
+}
+
+// agregarEntregas decodifica los items y los agrega a entregas; retorna true al alcanzar limite (0 = sin límite)
+func agregarEntregas(entregas []*models.EntregaWebhook, items []map[string]types.AttributeValue, limite int) ([]*models.EntregaWebhook, bool) {
+	for _, item := range items {
+		var entrega models.EntregaWebhook
+		if err := attributevalue.UnmarshalMap(item, &entrega); err != nil {
+			continue
+		}
+		entregas = append(entregas, &entrega)
+		if limite > 0 && len(entregas) >= limite {
+			return entregas, true
+		}
+	}
+	return entregas, false
+}
+
+// ReclamarEntrega bloquea la entrega con una escritura condicional; retorna false si ya no está pendiente o tiene un bloqueo vigente
+func (s *DynamoDBService) ReclamarEntrega(ctx context.Context, id string, ahora, hasta time.Time) (bool, error) {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoEntregaWebhook + id},
+		},
+		UpdateExpression:    aws.String("SET bloqueoHasta = :hasta"),
+		ConditionExpression: aws.String("estado = :pendiente AND bloqueoHasta < :ahoraMs"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hasta":     &types.AttributeValueMemberN{Value: strconv.FormatInt(hasta.UnixMilli(), 10)},
+			":ahoraMs":   &types.AttributeValueMemberN{Value: strconv.FormatInt(ahora.UnixMilli(), 10)},
+			":pendiente": &types.AttributeValueMemberS{Value: models.EntregaPendiente},
+		},
+	})
+	if err != nil {
+		var condicion *types.ConditionalCheckFailedException
+		if errors.As(err, &condicion) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error reclamando entrega: %w", err)
+	}
+
+	return true, nil
+}
+
+// ReclamarAnclaje reserva hasta el instante hasta el anclaje de una transacción pendiente, para que otra
+// réplica o un reanclaje de pendientes no la envíe dos veces a blockchain. Retorna la transacción tal como
+// quedó, con el envío de un intento anterior si lo hubo, o nil si ya no está pendiente o si otro proceso
+// tiene una reserva vigente.
+func (s *DynamoDBService) ReclamarAnclaje(ctx context.Context, idTransaccion string, ahora, hasta time.Time) (*models.Transaccion, error) {
+	salida, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"idTransaction": &types.AttributeValueMemberS{Value: idTransaccion},
+		},
+		UpdateExpression:    aws.String("SET anclajeHasta = :hasta"),
+		ConditionExpression: aws.String("estado = :pendiente AND (attribute_not_exists(anclajeHasta) OR anclajeHasta < :ahoraMs)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hasta":     &types.AttributeValueMemberN{Value: strconv.FormatInt(hasta.UnixMilli(), 10)},
+			":ahoraMs":   &types.AttributeValueMemberN{Value: strconv.FormatInt(ahora.UnixMilli(), 10)},
+			":pendiente": &types.AttributeValueMemberS{Value: "pendiente"},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var condicion *types.ConditionalCheckFailedException
+		if errors.As(err, &condicion) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reclamando anclaje: %w", err)
+	}
+
+	var transaccion models.Transaccion
+	if err := attributevalue.UnmarshalMap(salida.Attributes, &transaccion); err != nil {
+		return nil, fmt.Errorf("error deserializando transacción: %w", err)
+	}
+	return &transaccion, nil
+}
+
+// RegistrarEnvioAnclaje guarda la transacción de anclaje firmada antes de difundirla: si el proceso cae o
+// no logra confirmar el anclaje, el siguiente intento la consulta en lugar de enviar otra
+func (s *DynamoDBService) RegistrarEnvioAnclaje(ctx context.Context, idTransaccion, hashLogico, txHash string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"idTransaction": &types.AttributeValueMemberS{Value: idTransaccion},
+		},
+		UpdateExpression: aws.String("SET anclajeTxHash = :txHash, anclajeHashLogico = :hashLogico"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":txHash":     &types.AttributeValueMemberS{Value: txHash},
+			":hashLogico": &types.AttributeValueMemberS{Value: hashLogico},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error registrando envío del anclaje: %w", err)
+	}
+
+	return nil
+}
+
+// LiberarAnclaje quita la reserva de ReclamarAnclaje de una transacción que sigue pendiente, para que
+// el próximo reanclaje la tome sin esperar a que venza
+func (s *DynamoDBService) LiberarAnclaje(ctx context.Context, idTransaccion string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"idTransaction": &types.AttributeValueMemberS{Value: idTransaccion},
+		},
+		UpdateExpression: aws.String("REMOVE anclajeHasta"),
+	})
+	if err != nil {
+		return fmt.Errorf("error liberando anclaje: %w", err)
+	}
+
+	return nil
+}
+
+// VerificarTablas comprueba con DescribeTable que las tablas de transacciones y de control existen y
+// aceptan escrituras; lo usa GET /ready
+func (s *DynamoDBService) VerificarTablas(ctx context.Context) error {
+	for _, tabla := range []string{s.tableName, s.controlTableName} {
+		salida, err := s.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tabla)})
+		if err != nil {
+			return fmt.Errorf("tabla %s: %w", tabla, err)
+		}
+		if salida.Table == nil {
+			return fmt.Errorf("tabla %s: DescribeTable no retornó la tabla", tabla)
+		}
+		switch salida.Table.TableStatus {
+		case types.TableStatusActive, types.TableStatusUpdating:
+		default:
+			return fmt.Errorf("tabla %s en estado %s", tabla, salida.Table.TableStatus)
+		}
+	}
+	return nil
+}
+
+// IncrementarContador suma n al contador de rate limiting de la clave con un UpdateItem ADD atómico,
+// así que réplicas concurrentes nunca pierden incrementos. El contador expira por TTL (expiraEn).
+func (s *DynamoDBService) IncrementarContador(ctx context.Context, clave string, n int, expira time.Time) (int64, error) {
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.controlTableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: prefijoRateLimit + clave},
+		},
+		UpdateExpression: aws.String("ADD cuenta :n SET expiraEn = if_not_exists(expiraEn, :expiraEn)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":n":        &types.AttributeValueMemberN{Value: strconv.Itoa(n)},
+			":expiraEn": &types.AttributeValueMemberN{Value: strconv.FormatInt(expira.Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error incrementando contador de rate limiting: %w", err)
+	}
+
+	cuenta, ok := result.Attributes["cuenta"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("error incrementando contador de rate limiting: respuesta sin cuenta")
+	}
+	return strconv.ParseInt(cuenta.Value, 10, 64)
+}
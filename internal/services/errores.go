@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+)
+
+// TipoError clasifica un ErrorDominio; el middleware de errores lo traduce al status HTTP
+type TipoError string
+
+const (
+	TipoNoEncontrado    TipoError = "no-encontrado"             // 404
+	TipoValidacion      TipoError = "validacion"                // 400
+	TipoConflicto       TipoError = "conflicto"                 // 409
+	TipoNoProcesable    TipoError = "no-procesable"             // 422
+	TipoDemasiadoGrande TipoError = "demasiado-grande"          // 413
+	TipoNoSoportado     TipoError = "no-soportado"              // 415
+	TipoNoDisponible    TipoError = "dependencia-no-disponible" // 503
+	TipoTimeout         TipoError = "timeout"                   // 504
+)
+
+// ErrorDominio es un error de los servicios con un código estable que los clientes pueden usar en sus
+// decisiones. Mensaje es apto para mostrarse; Causa es el detalle y solo se expone en errores del cliente.
+// El código es también la clave del mensaje en el catálogo de i18n; Mensaje es su texto en español.
+type ErrorDominio struct {
+	Tipo    TipoError
+	Codigo  string
+	Mensaje string
+	Causa   error
+}
+
+// NuevoErrorDominio crea un error de dominio sin causa, pensado para declararse como centinela
+func NuevoErrorDominio(tipo TipoError, codigo, mensaje string) *ErrorDominio {
+	return &ErrorDominio{Tipo: tipo, Codigo: codigo, Mensaje: mensaje}
+}
+
+func (e *ErrorDominio) Error() string {
+	if e.Causa == nil {
+		return e.Mensaje
+	}
+	return e.Mensaje + ": " + e.Causa.Error()
+}
+
+func (e *ErrorDominio) Unwrap() error {
+	return e.Causa
+}
+
+// Is hace que una copia con causa (Con) siga coincidiendo con su centinela en errors.Is
+func (e *ErrorDominio) Is(target error) bool {
+	objetivo, ok := target.(*ErrorDominio)
+	return ok && objetivo.Tipo == e.Tipo && objetivo.Codigo == e.Codigo
+}
+
+// Con retorna una copia del error con la causa indicada
+func (e *ErrorDominio) Con(causa error) *ErrorDominio {
+	copia := *e
+	copia.Causa = causa
+	return &copia
+}
+
+// MensajeEn retorna el mensaje del código en el idioma, sin la causa
+func (e *ErrorDominio) MensajeEn(idioma i18n.Idioma) string {
+	if mensaje, ok := i18n.Buscar(idioma, e.Codigo); ok {
+		return mensaje
+	}
+	return e.Mensaje
+}
+
+// Traducir muestra el error en el idioma, con la causa traducida si lo permite
+func (e *ErrorDominio) Traducir(idioma i18n.Idioma) string {
+	if e.Causa == nil {
+		return e.MensajeEn(idioma)
+	}
+	return e.MensajeEn(idioma) + ": " + i18n.TraducirError(e.Causa, idioma)
+}
+
+// EsErrorDelCliente indica si el error se debe a la petición (4xx) y su detalle se puede mostrar
+func (e *ErrorDominio) EsErrorDelCliente() bool {
+	return e.Tipo != TipoNoDisponible && e.Tipo != TipoTimeout
+}
+
+// Dependencias externas reconocidas por ErrorDependencia
+const (
+	DependenciaIPFS       = "IPFS"
+	DependenciaDynamoDB   = "DynamoDB"
+	DependenciaBlockchain = "Blockchain"
+)
+
+// ErrorDependencia clasifica el fallo de una dependencia externa: timeout si venció el plazo y
+// dependencia no disponible en otro caso. Los errores ya clasificados (no encontrado, conflicto,
+// denegación de la política) se retornan sin cambios para no ocultar su causa real.
+func ErrorDependencia(dependencia string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		dominio    *ErrorDominio
+		denegacion *policy.Denegacion
+	)
+	if errors.As(err, &dominio) || errors.As(err, &denegacion) {
+		return err
+	}
+	codigo := strings.ToLower(dependencia)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &ErrorDominio{Tipo: TipoTimeout, Codigo: codigo + "-timeout", Mensaje: dependencia + " no respondió a tiempo", Causa: err}
+	}
+	return &ErrorDominio{Tipo: TipoNoDisponible, Codigo: codigo + "-no-disponible", Mensaje: dependencia + " no está disponible", Causa: err}
+}
+
+var (
+	// ErrTransaccionNoEncontrada indica que no existe una transacción con el ID solicitado
+	ErrTransaccionNoEncontrada = NuevoErrorDominio(TipoNoEncontrado, "transaccion-no-encontrada", "transacción no encontrada")
+	// ErrProductoSinEventos indica que el producto no tiene eventos registrados
+	ErrProductoSinEventos = NuevoErrorDominio(TipoNoEncontrado, "producto-sin-eventos", "no se encontraron transacciones para el producto")
+	// ErrCIDNoEncontrado indica que IPFS no tiene el contenido solicitado o el CID no es válido
+	ErrCIDNoEncontrado = NuevoErrorDominio(TipoNoEncontrado, "cid-no-encontrado", "contenido no encontrado en IPFS")
+)
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+)
+
+// Niveles de fondos de la cuenta firmante (campo nivel de GET /api/v1/admin/fondos y de las alertas)
+const (
+	NivelFondosOK           = "ok"
+	NivelFondosBajo         = "bajo"         // Saldo o autonomía por debajo del umbral de alerta
+	NivelFondosInsuficiente = "insuficiente" // El saldo no paga un anclaje: los anclajes se pausan
+)
+
+// FondosConfig define los umbrales del monitor de fondos y el destino de sus alertas
+type FondosConfig struct {
+	Intervalo       time.Duration // Intervalo entre consultas del saldo
+	Ventana         time.Duration // Periodo de gasto reciente con el que se proyecta la autonomía; 0 = 24 horas
+	SaldoAlerta     *big.Int      // Saldo en wei por debajo del cual se alerta; nil = sin umbral
+	AutonomiaMinima time.Duration // Autonomía proyectada por debajo de la cual se alerta; 0 = sin umbral
+	WebhookURL      string        // Destino de las alertas; vacío = solo log y métricas
+	WebhookSecreto  string        // Firma las alertas igual que las notificaciones de webhooks; vacío = sin firma
+	Timeout         time.Duration // Tiempo máximo del POST de cada alerta
+}
+
+// gastoAnclaje es el costo en wei de un anclaje enviado
+type gastoAnclaje struct {
+	fecha time.Time
+	costo *big.Int
+}
+
+// MonitorFondos consulta periódicamente el saldo de la cuenta firmante, proyecta cuánto dura según el gas
+// gastado por los anclajes recientes y alerta (log, webhook y métricas) cuando cambia de nivel. Con el
+// saldo por debajo del costo de un anclaje pausa los anclajes hasta que se recargue la cuenta, en lugar
+// de dejar que cada registro termine como fallido: los eventos quedan pendientes y se anclan al reanudar.
+type MonitorFondos struct {
+	blockchain *BlockchainService
+	config     FondosConfig
+	cliente    *http.Client
+
+	mu         sync.Mutex
+	gastos     []gastoAnclaje // En orden de llegada; solo los de la ventana
+	estado     models.EstadoFondos
+	pausado    bool
+	alReanudar []func() // Se ejecutan al recargar la cuenta, cada una en su propia goroutine
+}
+
+// NewMonitorFondos crea el monitor y lo suscribe al costo de cada anclaje enviado por blockchain
+func NewMonitorFondos(blockchain *BlockchainService, cfg FondosConfig) *MonitorFondos {
+	if cfg.Intervalo <= 0 {
+		cfg.Intervalo = time.Minute
+	}
+	if cfg.Ventana <= 0 {
+		cfg.Ventana = 24 * time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	m := &MonitorFondos{
+		blockchain: blockchain,
+		config:     cfg,
+		cliente:    &http.Client{Timeout: cfg.Timeout},
+		estado:     models.EstadoFondos{Cuenta: blockchain.Cuenta().Hex(), Nivel: NivelFondosOK},
+	}
+	blockchain.ConfigurarGasto(m.registrarGasto)
+	return m
+}
+
+// Iniciar consulta el saldo cada Config.Intervalo hasta que se cancele ctx
+func (m *MonitorFondos) Iniciar(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Intervalo)
+	defer ticker.Stop()
+	for {
+		_ = m.Actualizar(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Actualizar consulta el saldo y el gas price, recalcula la proyección y alerta si cambió el nivel
+func (m *MonitorFondos) Actualizar(ctx context.Context) error {
+	saldo, err := m.blockchain.ObtenerBalance(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "fondos: no se pudo consultar el saldo de la cuenta firmante", "error", err)
+		m.mu.Lock()
+		m.estado.Error = redaccion.Texto(err.Error())
+		m.mu.Unlock()
+		return err
+	}
+	// Sin gas price no se sabe cuánto cuesta el próximo anclaje; la proyección usa solo el gasto reciente
+	costoMaximo, err := m.blockchain.EstimarCostoAnclaje(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "fondos: no se pudo estimar el costo de un anclaje", "error", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ahora := time.Now()
+	m.podar(ahora)
+
+	gasto := new(big.Int)
+	for _, g := range m.gastos {
+		gasto.Add(gasto, g.costo)
+	}
+	// Gasto proyectado a 24 horas, para que la ventana configurada no cambie el significado del campo
+	gastoDiario := new(big.Int).Div(new(big.Int).Mul(gasto, big.NewInt(int64(24*time.Hour))), big.NewInt(int64(m.config.Ventana)))
+
+	costoMedio := costoMaximo
+	if len(m.gastos) > 0 {
+		costoMedio = new(big.Int).Div(gasto, big.NewInt(int64(len(m.gastos))))
+	}
+
+	estado := models.EstadoFondos{
+		Cuenta:            m.estado.Cuenta,
+		SaldoWei:          saldo.String(),
+		SaldoEth:          utils.FormatearEther(saldo),
+		GastoDiarioWei:    gastoDiario.String(),
+		GastoDiarioEth:    utils.FormatearEther(gastoDiario),
+		AnclajesUltimoDia: len(m.gastos),
+		ActualizadoEn:     ahora.UTC(),
+	}
+	if costoMedio != nil && costoMedio.Sign() > 0 {
+		estado.CostoAnclajeWei = costoMedio.String()
+		estado.CostoAnclajeEth = utils.FormatearEther(costoMedio)
+		restantes := new(big.Int).Div(saldo, costoMedio)
+		estado.AnclajesRestantes = math.MaxInt64
+		if restantes.IsInt64() {
+			estado.AnclajesRestantes = restantes.Int64()
+		}
+	}
+	autonomiaHoras := math.Inf(1)
+	if gastoDiario.Sign() > 0 {
+		autonomiaHoras = eth(saldo) / eth(gastoDiario) * 24
+		estado.AutonomiaHoras = &autonomiaHoras
+	}
+
+	// El nodo exige saldo para el límite de gas completo, no para el gas que se acaba usando: el umbral
+	// de pausa es el costo máximo, no el medio
+	nivel, motivo := NivelFondosOK, "fondos recuperados"
+	switch {
+	case costoMaximo != nil && saldo.Cmp(costoMaximo) < 0 || costoMaximo == nil && saldo.Sign() == 0:
+		nivel = NivelFondosInsuficiente
+		motivo = fmt.Sprintf("el saldo de %s ETH no alcanza para un anclaje", estado.SaldoEth)
+		if costoMaximo != nil {
+			motivo += fmt.Sprintf(" (hasta %s ETH al gas price actual)", utils.FormatearEther(costoMaximo))
+		}
+		estado.AnclajesRestantes = 0
+	case m.config.SaldoAlerta != nil && saldo.Cmp(m.config.SaldoAlerta) < 0:
+		nivel = NivelFondosBajo
+		motivo = fmt.Sprintf("saldo de %s ETH por debajo del umbral de %s ETH", estado.SaldoEth, utils.FormatearEther(m.config.SaldoAlerta))
+	case m.config.AutonomiaMinima > 0 && autonomiaHoras < m.config.AutonomiaMinima.Hours():
+		nivel = NivelFondosBajo
+		motivo = fmt.Sprintf("autonomía de %.1f horas al ritmo de gasto actual; el mínimo es %.0f", autonomiaHoras, m.config.AutonomiaMinima.Hours())
+	}
+	estado.Nivel = nivel
+
+	anterior := m.estado.Nivel
+	m.estado = estado
+	m.pausar(nivel == NivelFondosInsuficiente)
+	m.estado.AnclajePausado = m.pausado
+	metricas.ObservarFondos(eth(saldo), eth(gastoDiario), estado.AnclajesRestantes, autonomiaHoras)
+	if nivel != anterior {
+		m.alertar(ctx, anterior, motivo)
+	}
+	return nil
+}
+
+// SinFondos pausa los anclajes porque el nodo rechazó uno por falta de saldo, aunque la última consulta
+// indicara lo contrario (p. ej. subió el gas price). Se reanudan cuando una consulta encuentre saldo.
+func (m *MonitorFondos) SinFondos(ctx context.Context, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pausado {
+		return
+	}
+	anterior := m.estado.Nivel
+	m.estado.Nivel = NivelFondosInsuficiente
+	m.estado.AnclajesRestantes = 0
+	m.pausar(true)
+	m.estado.AnclajePausado = true
+	m.alertar(ctx, anterior, "el nodo rechazó un anclaje: "+redaccion.Texto(err.Error()))
+}
+
+// AlReanudar registra fn para que se ejecute, en su propia goroutine, cada vez que se reanudan los anclajes
+func (m *MonitorFondos) AlReanudar(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alReanudar = append(m.alReanudar, fn)
+}
+
+// Pausado indica si los anclajes esperan a que se recargue la cuenta firmante. Un monitor nil (modo sin
+// blockchain o sin monitor) nunca pausa.
+func (m *MonitorFondos) Pausado() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pausado
+}
+
+// Estado retorna el saldo y la proyección de la última consulta
+func (m *MonitorFondos) Estado() models.EstadoFondos {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	estado := m.estado
+	if estado.AutonomiaHoras != nil {
+		horas := *estado.AutonomiaHoras
+		estado.AutonomiaHoras = &horas
+	}
+	return estado
+}
+
+// registrarGasto suma el costo de un anclaje enviado al gasto de la ventana
+func (m *MonitorFondos) registrarGasto(costo *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ahora := time.Now()
+	m.gastos = append(m.gastos, gastoAnclaje{fecha: ahora, costo: new(big.Int).Set(costo)})
+	m.podar(ahora)
+}
+
+// podar descarta los gastos anteriores a la ventana; requiere m.mu
+func (m *MonitorFondos) podar(ahora time.Time) {
+	limite := ahora.Add(-m.config.Ventana)
+	i := 0
+	for i < len(m.gastos) && m.gastos[i].fecha.Before(limite) {
+		i++
+	}
+	m.gastos = m.gastos[i:]
+}
+
+// pausar pausa o reanuda los anclajes; al reanudar ejecuta las funciones de AlReanudar. Requiere m.mu
+func (m *MonitorFondos) pausar(pausado bool) {
+	if m.pausado && !pausado {
+		for _, fn := range m.alReanudar {
+			go fn()
+		}
+	}
+	m.pausado = pausado
+	metricas.AnclajePausado(pausado)
+}
+
+// alertar registra el cambio de nivel en el log y las métricas y lo envía al webhook de alertas; requiere m.mu
+func (m *MonitorFondos) alertar(ctx context.Context, anterior, motivo string) {
+	estado := m.estado
+	atributos := []any{"nivel", estado.Nivel, "nivel_anterior", anterior, "motivo", motivo, "saldo_eth", estado.SaldoEth, "anclajes_restantes", estado.AnclajesRestantes}
+	switch estado.Nivel {
+	case NivelFondosInsuficiente:
+		slog.ErrorContext(ctx, "fondos: saldo insuficiente en la cuenta firmante; anclajes pausados", atributos...)
+	case NivelFondosBajo:
+		slog.WarnContext(ctx, "fondos: saldo bajo en la cuenta firmante", atributos...)
+	default:
+		slog.InfoContext(ctx, "fondos: saldo de la cuenta firmante recuperado", atributos...)
+	}
+	metricas.AlertaFondos(estado.Nivel)
+
+	if m.config.WebhookURL == "" {
+		return
+	}
+	alerta := models.AlertaFondos{Nivel: estado.Nivel, NivelAnterior: anterior, Motivo: motivo, Fondos: estado, Fecha: time.Now().UTC()}
+	go m.enviarAlerta(logging.Desacoplar(ctx), alerta)
+}
+
+// enviarAlerta hace el POST de la alerta, firmado como las notificaciones de webhooks; no reintenta
+func (m *MonitorFondos) enviarAlerta(ctx context.Context, alerta models.AlertaFondos) {
+	cuerpo, err := json.Marshal(alerta)
+	if err != nil {
+		slog.ErrorContext(ctx, "fondos: error serializando la alerta", "error", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.config.WebhookURL, bytes.NewReader(cuerpo))
+	if err != nil {
+		slog.ErrorContext(ctx, "fondos: URL del webhook de alertas inválida", "error", err)
+		return
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MediSupply-Alertas/1.0")
+	req.Header.Set(CabeceraWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	if m.config.WebhookSecreto != "" {
+		req.Header.Set(CabeceraWebhookFirma, "v1="+FirmarWebhook(m.config.WebhookSecreto, timestamp, cuerpo))
+	}
+
+	resp, err := m.cliente.Do(req)
+	if err != nil {
+		slog.WarnContext(ctx, "fondos: no se pudo enviar la alerta", "nivel", alerta.Nivel, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		slog.WarnContext(ctx, "fondos: el webhook de alertas respondió con error", "nivel", alerta.Nivel, "status", resp.StatusCode)
+	}
+}
+
+// eth convierte wei a ETH en coma flotante, para métricas y proyecciones
+func eth(wei *big.Int) float64 {
+	valor, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return valor
+}
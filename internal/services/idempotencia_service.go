@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+var (
+	// ErrIdempotenciaEnCurso indica que la petición original con la misma clave todavía no termina
+	ErrIdempotenciaEnCurso = NuevoErrorDominio(TipoConflicto, "idempotencia-en-curso", "una petición con la misma Idempotency-Key está en curso")
+	// ErrIdempotenciaCuerpoDistinto indica que la clave ya se usó con otra petición
+	ErrIdempotenciaCuerpoDistinto = NuevoErrorDominio(TipoNoProcesable, "idempotencia-cuerpo-distinto", "la Idempotency-Key ya se usó con una petición distinta")
+)
+
+// intervaloEsperaIdempotencia es cada cuánto se consulta si terminó la petición original
+const intervaloEsperaIdempotencia = 200 * time.Millisecond
+
+// AlmacenIdempotencia persiste los registros de idempotencia. DynamoDBService lo implementa sobre la tabla de control.
+type AlmacenIdempotencia interface {
+	// ReservarIdempotencia crea el registro si no existe (o si el existente expiró o fue abandonado).
+	// Retorna nil si lo reservó, o el registro vigente si otra petición ya lo tiene.
+	ReservarIdempotencia(ctx context.Context, registro *models.RegistroIdempotencia) (*models.RegistroIdempotencia, error)
+	GuardarIdempotencia(ctx context.Context, registro *models.RegistroIdempotencia) error
+	EliminarIdempotencia(ctx context.Context, clave string) error
+}
+
+// IdempotenciaConfig agrupa los tiempos de la idempotencia
+type IdempotenciaConfig struct {
+	TTL     time.Duration // Tiempo que se conserva la respuesta original
+	Espera  time.Duration // Tiempo que un duplicado concurrente espera antes de recibir 409
+	Bloqueo time.Duration // Tiempo tras el cual una petición en curso se considera abandonada
+}
+
+// IdempotenciaService evita que los reintentos de un cliente repitan un registro
+type IdempotenciaService struct {
+	almacen AlmacenIdempotencia
+	config  IdempotenciaConfig
+}
+
+// NewIdempotenciaService crea una nueva instancia de IdempotenciaService
+func NewIdempotenciaService(almacen AlmacenIdempotencia, config IdempotenciaConfig) *IdempotenciaService {
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.Bloqueo <= 0 {
+		config.Bloqueo = 2 * time.Minute
+	}
+	return &IdempotenciaService{almacen: almacen, config: config}
+}
+
+// ClaveIdempotencia combina el alcance del llamador con la Idempotency-Key; dos llamadores
+// distintos pueden usar la misma clave sin interferir
+func ClaveIdempotencia(alcance, clave string) string {
+	suma := sha256.Sum256([]byte(alcance + "\x00" + clave))
+	return hex.EncodeToString(suma[:])
+}
+
+// Iniciar reserva la clave para una petición nueva. Si la clave ya se completó retorna el registro
+// con la respuesta original; si está en curso espera hasta config.Espera y después retorna ErrIdempotenciaEnCurso.
+func (s *IdempotenciaService) Iniciar(ctx context.Context, clave, huella string) (*models.RegistroIdempotencia, error) {
+	limite := time.Now().Add(s.config.Espera)
+
+	for {
+		ahora := time.Now().UTC()
+		existente, err := s.almacen.ReservarIdempotencia(ctx, &models.RegistroIdempotencia{
+			Clave:        clave,
+			Huella:       huella,
+			Estado:       models.IdempotenciaEnCurso,
+			CreadoEn:     ahora,
+			BloqueoHasta: ahora.Add(s.config.Bloqueo).UnixMilli(),
+			ExpiraEn:     ahora.Add(s.config.TTL).Unix(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reservando Idempotency-Key: %w", err)
+		}
+		if existente == nil {
+			return nil, nil
+		}
+		if existente.Huella != huella {
+			return nil, ErrIdempotenciaCuerpoDistinto
+		}
+		if existente.Estado == models.IdempotenciaCompletada {
+			return existente, nil
+		}
+		if !time.Now().Before(limite) {
+			return nil, ErrIdempotenciaEnCurso
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrIdempotenciaEnCurso
+		case <-time.After(intervaloEsperaIdempotencia):
+		}
+	}
+}
+
+// Completar guarda la respuesta de la petición original para devolverla en los reintentos
+func (s *IdempotenciaService) Completar(ctx context.Context, clave, huella string, statusCode int, tipoContenido string, respuesta []byte) error {
+	ahora := time.Now().UTC()
+	return s.almacen.GuardarIdempotencia(ctx, &models.RegistroIdempotencia{
+		Clave:         clave,
+		Huella:        huella,
+		Estado:        models.IdempotenciaCompletada,
+		StatusCode:    statusCode,
+		TipoContenido: tipoContenido,
+		Respuesta:     respuesta,
+		CreadoEn:      ahora,
+		BloqueoHasta:  ahora.UnixMilli(),
+		ExpiraEn:      ahora.Add(s.config.TTL).Unix(),
+	})
+}
+
+// Liberar elimina la reserva para que un reintento pueda ejecutar la petición de nuevo
+func (s *IdempotenciaService) Liberar(ctx context.Context, clave string) error {
+	return s.almacen.EliminarIdempotencia(ctx, clave)
+}
+
+// AlmacenIdempotenciaMemoria es un AlmacenIdempotencia en memoria, útil para desarrollo y tests
+type AlmacenIdempotenciaMemoria struct {
+	mu        sync.Mutex
+	registros map[string]models.RegistroIdempotencia
+}
+
+// NewAlmacenIdempotenciaMemoria crea un almacén de idempotencia en memoria
+func NewAlmacenIdempotenciaMemoria() *AlmacenIdempotenciaMemoria {
+	return &AlmacenIdempotenciaMemoria{registros: make(map[string]models.RegistroIdempotencia)}
+}
+
+// ReservarIdempotencia reserva la clave de forma atómica
+func (a *AlmacenIdempotenciaMemoria) ReservarIdempotencia(ctx context.Context, registro *models.RegistroIdempotencia) (*models.RegistroIdempotencia, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ahora := time.Now()
+	if existente, ok := a.registros[registro.Clave]; ok && !existente.Expirado(ahora) && !existente.Abandonado(ahora) {
+		return &existente, nil
+	}
+	a.registros[registro.Clave] = *registro
+	return nil, nil
+}
+
+// GuardarIdempotencia guarda o reemplaza un registro
+func (a *AlmacenIdempotenciaMemoria) GuardarIdempotencia(ctx context.Context, registro *models.RegistroIdempotencia) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.registros[registro.Clave] = *registro
+	return nil
+}
+
+// EliminarIdempotencia elimina un registro
+func (a *AlmacenIdempotenciaMemoria) EliminarIdempotencia(ctx context.Context, clave string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.registros, clave)
+	return nil
+}
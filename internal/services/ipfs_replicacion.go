@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ReplicacionConfig define en qué nodos se pinean los CIDs y cuántas copias se exigen
+type ReplicacionConfig struct {
+	Nodos               []string // Nodos Kubo adicionales en formato host:puerto
+	PinningServiceURL   string   // Endpoint de un servicio compatible con la IPFS Pinning Service API
+	PinningServiceToken string   // Token Bearer del servicio de pinning
+	FactorReplicacion   int      // Número de destinos en los que se intenta mantener cada CID
+	MinimoPinesRegistro int      // Pines exitosos exigidos para que un registro tenga éxito (0 = no exigir)
+}
+
+// destinoPin es un lugar donde se puede pinear un CID (nodo Kubo o servicio de pinning)
+type destinoPin interface {
+	nombre() string
+	pin(ctx context.Context, cid string) error
+	tienePin(ctx context.Context, cid string) (bool, error)
+}
+
+// nodoKubo pinea usando la API HTTP /api/v0 de un nodo Kubo
+type nodoKubo struct {
+	host       string
+	port       string
+	httpClient *http.Client
+}
+
+func (n *nodoKubo) nombre() string {
+	return fmt.Sprintf("kubo:%s:%s", n.host, n.port)
+}
+
+func (n *nodoKubo) pin(ctx context.Context, cid string) error {
+	endpoint := fmt.Sprintf("http://%s:%s/api/v0/pin/add?arg=%s", n.host, n.port, url.QueryEscape(cid))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("error creando request de pineo: %w", err)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error ejecutando request de pineo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("IPFS retornó status %d en pineo: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+func (n *nodoKubo) tienePin(ctx context.Context, cid string) (bool, error) {
+	endpoint := fmt.Sprintf("http://%s:%s/api/v0/pin/ls?arg=%s&type=recursive", n.host, n.port, url.QueryEscape(cid))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creando request de pin/ls: %w", err)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error consultando pines: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Kubo responde 500 con "is not pinned" cuando el CID no está pineado
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if strings.Contains(string(bodyBytes), "not pinned") {
+		return false, nil
+	}
+	return false, fmt.Errorf("IPFS retornó status %d en pin/ls: %s", resp.StatusCode, string(bodyBytes))
+}
+
+// servicioPinning implementa la IPFS Pinning Service API (https://ipfs.github.io/pinning-services-api-spec/)
+type servicioPinning struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+// pinStatusResponse es la respuesta de POST /pins
+type pinStatusResponse struct {
+	RequestID string `json:"requestid"`
+	Status    string `json:"status"`
+}
+
+// pinResultsResponse es la respuesta de GET /pins
+type pinResultsResponse struct {
+	Count   int                 `json:"count"`
+	Results []pinStatusResponse `json:"results"`
+}
+
+func (p *servicioPinning) nombre() string {
+	return "psa:" + p.endpoint
+}
+
+func (p *servicioPinning) pin(ctx context.Context, cid string) error {
+	body, err := json.Marshal(map[string]string{"cid": cid, "name": "medisupply-" + cid})
+	if err != nil {
+		return fmt.Errorf("error serializando pin: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint+"/pins", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creando request de pinning service: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error ejecutando request de pinning service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pinning service retornó status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var status pinStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return fmt.Errorf("error decodificando respuesta del pinning service: %w", err)
+	}
+	if status.Status == "failed" {
+		return fmt.Errorf("pinning service rechazó el pin de %s", cid)
+	}
+
+	return nil
+}
+
+func (p *servicioPinning) tienePin(ctx context.Context, cid string) (bool, error) {
+	query := url.Values{}
+	query.Set("cid", cid)
+	// Un pin en cola o en proceso ya es responsabilidad del servicio
+	query.Set("status", "queued,pinning,pinned")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.endpoint+"/pins?"+query.Encode(), nil)
+	if err != nil {
+		return false, fmt.Errorf("error creando request de pinning service: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error consultando pinning service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("pinning service retornó status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var resultados pinResultsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&resultados); err != nil {
+		return false, fmt.Errorf("error decodificando respuesta del pinning service: %w", err)
+	}
+
+	return resultados.Count > 0, nil
+}
@@ -1,14 +1,23 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
 )
 
 // IPFSService maneja las operaciones con IPFS
@@ -16,6 +25,12 @@ type IPFSService struct {
 	host       string
 	port       string
 	httpClient *http.Client
+
+	// Replicación: el primer destino siempre es el nodo primario (host:port)
+	destinos          []destinoPin
+	factorReplicacion int
+	minimoPines       int
+	mu                sync.RWMutex
 }
 
 // IPFSAddResponse representa la respuesta de IPFS al agregar un archivo
@@ -27,13 +42,58 @@ type IPFSAddResponse struct {
 
 // NewIPFSService crea una nueva instancia de IPFSService
 func NewIPFSService(host, port string) *IPFSService {
+	httpClient := &http.Client{
+		Timeout: 60 * time.Second, // Aumentado a 60 segundos para evitar timeouts
+	}
 	return &IPFSService{
-		host: host,
-		port: port,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Aumentado a 60 segundos para evitar timeouts
+		host:       host,
+		port:       port,
+		httpClient: httpClient,
+		destinos: []destinoPin{
+			&nodoKubo{host: host, port: port, httpClient: httpClient},
 		},
+		factorReplicacion: 1,
+	}
+}
+
+// ConfigurarReplicacion agrega nodos y servicios de pinning adicionales al nodo primario
+func (s *IPFSService) ConfigurarReplicacion(cfg ReplicacionConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.destinos = s.destinos[:1]
+	for _, nodo := range cfg.Nodos {
+		host, port, ok := strings.Cut(strings.TrimSpace(nodo), ":")
+		if !ok || host == "" || port == "" {
+			slog.Warn("IPFS: nodo inválido, se esperaba host:puerto", "nodo", nodo)
+			continue
+		}
+		s.destinos = append(s.destinos, &nodoKubo{host: host, port: port, httpClient: s.httpClient})
+	}
+
+	if cfg.PinningServiceURL != "" {
+		s.destinos = append(s.destinos, &servicioPinning{
+			endpoint:   strings.TrimSuffix(cfg.PinningServiceURL, "/"),
+			token:      cfg.PinningServiceToken,
+			httpClient: s.httpClient,
+		})
+	}
+
+	s.factorReplicacion = cfg.FactorReplicacion
+	if s.factorReplicacion < 1 {
+		s.factorReplicacion = 1
 	}
+	if s.factorReplicacion > len(s.destinos) {
+		slog.Warn("IPFS: factor de replicación mayor que los destinos disponibles", "factor", s.factorReplicacion, "destinos", len(s.destinos))
+	}
+	s.minimoPines = cfg.MinimoPinesRegistro
+}
+
+// GetFactorReplicacion retorna el número de copias que se intenta mantener por CID
+func (s *IPFSService) GetFactorReplicacion() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.factorReplicacion
 }
 
 // GetHost retorna el host de IPFS
@@ -46,35 +106,50 @@ func (s *IPFSService) GetPort() string {
 	return s.port
 }
 
-// AlmacenarJSON almacena datos JSON en IPFS y retorna el CID
-func (s *IPFSService) AlmacenarJSON(ctx context.Context, data string) (string, error) {
-	fmt.Println("Almacenando datos en IPFS...", s.host, s.port)
-	fmt.Println("Datos a almacenar:", data)
-	return s.Almacenar(ctx, []byte(data))
+// AlmacenarJSON almacena datos JSON en IPFS y retorna el CID y los destinos donde quedó pineado
+func (s *IPFSService) AlmacenarJSON(ctx context.Context, data string) (string, []string, error) {
+	// Los datos del evento no se registran: solo su tamaño
+	slog.DebugContext(ctx, "almacenando JSON en IPFS", "bytes", len(data))
+	return s.Almacenar(ctx, "data.json", strings.NewReader(data))
 }
 
-// Almacenar almacena datos en IPFS y retorna el CID
-func (s *IPFSService) Almacenar(ctx context.Context, data []byte) (string, error) {
-	fmt.Printf("🟡 IPFS: Iniciando almacenamiento en %s:%s\n", s.host, s.port)
-	url := fmt.Sprintf("http://%s:%s/api/v0/add", s.host, s.port)
-	fmt.Printf("🟡 IPFS: URL de almacenamiento: %s\n", url)
-	// Crear multipart form data
-	body := &bytes.Buffer{}
-	fmt.Println("Body:", body)
-	writer := multipart.NewWriter(body)
-	fmt.Println("Writer:", writer)
-	part, err := writer.CreateFormFile("file", "data.json")
+// Almacenar envía el contenido del reader a IPFS, lo replica y retorna el CID y los destinos donde
+// quedó pineado, que el llamador guarda junto con la transacción. El contenido se transmite en
+// streaming hacia el nodo, sin cargarlo completo en memoria.
+func (s *IPFSService) Almacenar(ctx context.Context, nombre string, data io.Reader) (string, []string, error) {
+	ctxAdd, span := s.iniciarSpan(ctx, "ipfs.add", attribute.String("nombre", nombre))
+	inicio := time.Now()
+	cid, err := s.agregar(ctxAdd, nombre, data)
+	metricas.ObservarIPFS(metricas.IPFSAdd, inicio, err)
+	span.SetAttributes(attribute.String("cid", cid))
+	trazas.Terminar(span, &err)
 	if err != nil {
-		return "", fmt.Errorf("error creando form file: %w", err)
+		return "", nil, err
 	}
 
-	if _, err := part.Write(data); err != nil {
-		return "", fmt.Errorf("error escribiendo datos: %w", err)
+	// Pinear el CID en los destinos configurados para asegurar su persistencia
+	nodos, err := s.Replicar(ctx, cid)
+	if err != nil {
+		return "", nil, err
 	}
 
-	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("error cerrando writer: %w", err)
-	}
+	return cid, nodos, nil
+}
+
+// agregar sube el contenido al nodo principal con /api/v0/add y retorna el CID
+func (s *IPFSService) agregar(ctx context.Context, nombre string, data io.Reader) (string, error) {
+	url := fmt.Sprintf("http://%s:%s/api/v0/add", s.host, s.port)
+
+	// Crear multipart form data sobre un pipe para no bufferizar el contenido
+	body, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+	errEscritura := make(chan error, 1)
+	go func() {
+		err := escribirMultipart(writer, nombre, data)
+		pipeWriter.CloseWithError(err)
+		errEscritura <- err
+	}()
+	defer body.Close()
 
 	// Crear request
 	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
@@ -84,20 +159,23 @@ func (s *IPFSService) Almacenar(ctx context.Context, data []byte) (string, error
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Ejecutar request
-	fmt.Printf("🟡 IPFS: Enviando request (timeout: 60s)...\n")
 	startTime := time.Now()
 	resp, err := s.httpClient.Do(req)
 	elapsed := time.Since(startTime)
-	fmt.Printf("🟡 IPFS: Request completado en %v\n", elapsed)
 
 	if err != nil {
+		// Un error leyendo el contenido (por ejemplo, límite de tamaño excedido) tiene prioridad
+		body.Close()
+		if errLectura := <-errEscritura; errLectura != nil && !errors.Is(errLectura, io.ErrClosedPipe) {
+			return "", errLectura
+		}
 		// Verificar si es timeout
 		if ctx.Err() == context.DeadlineExceeded {
 			return "", fmt.Errorf("timeout al conectar con IPFS después de %v: verifique que IPFS esté corriendo en %s:%s", elapsed, s.host, s.port)
 		}
 		return "", fmt.Errorf("error ejecutando request a IPFS después de %v: %w", elapsed, err)
 	}
-	fmt.Printf("🟡 IPFS: Response recibido - Status: %d\n", resp.StatusCode)
+	slog.DebugContext(ctx, "IPFS: respuesta de add", "nodo", s.host+":"+s.port, "estado", resp.StatusCode, "latencia_ms", elapsed.Milliseconds())
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
@@ -105,6 +183,10 @@ func (s *IPFSService) Almacenar(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("IPFS retornó status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
+	if err := <-errEscritura; err != nil {
+		return "", err
+	}
+
 	// Parsear respuesta
 	var result IPFSAddResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -115,40 +197,105 @@ func (s *IPFSService) Almacenar(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("no se pudo obtener CID de la respuesta IPFS")
 	}
 
-	// Pinear el CID para asegurar su persistencia
-	if err := s.pinCID(ctx, result.Hash); err != nil {
-		// No retornar error fatal, pero sí loguearlo. El archivo fue añadido.
-		fmt.Printf("⚠️ IPFS: ADVERTENCIA: no se pudo pinear el CID %s: %v\n", result.Hash, err)
-	}
-
 	return result.Hash, nil
 }
 
-// pinCID pinea un CID en el nodo IPFS para asegurar su persistencia
-func (s *IPFSService) pinCID(ctx context.Context, cid string) error {
-	fmt.Printf("🟡 IPFS: Pineando CID %s...\n", cid)
-	url := fmt.Sprintf("http://%s:%s/api/v0/pin/add?arg=%s", s.host, s.port, cid)
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+// escribirMultipart copia el contenido como parte "file" y cierra el formulario
+func escribirMultipart(writer *multipart.Writer, nombre string, data io.Reader) error {
+	part, err := writer.CreateFormFile("file", nombre)
 	if err != nil {
-		return fmt.Errorf("error creando request de pineo: %w", err)
+		return fmt.Errorf("error creando form file: %w", err)
 	}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error ejecutando request de pineo: %w", err)
+	if _, err := io.Copy(part, data); err != nil {
+		return fmt.Errorf("error escribiendo datos: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("IPFS retornó status %d en pineo: %s", resp.StatusCode, string(bodyBytes))
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error cerrando writer: %w", err)
 	}
 
-	fmt.Printf("✅ IPFS: CID %s pineado correctamente\n", cid)
 	return nil
 }
 
+// Replicar asegura que el CID esté pineado en tantos destinos como indique el factor de replicación.
+// Primero consulta qué destinos ya lo tienen y solo pinea en los que faltan.
+// Retorna los destinos que tienen el CID y error si no se alcanza el mínimo de pines configurado.
+func (s *IPFSService) Replicar(ctx context.Context, cid string) ([]string, error) {
+	conPin, _, err := s.replicar(ctx, cid)
+	return conPin, err
+}
+
+// replicar implementa Replicar y además retorna los destinos que respondieron que no tienen el CID y en
+// los que no se volvió a pinear. Un destino cuya consulta falló no figura en ninguna de las dos listas.
+func (s *IPFSService) replicar(ctx context.Context, cid string) (conPin, sinPin []string, err error) {
+	ctx, span := s.iniciarSpan(ctx, "ipfs.replicar", attribute.String("cid", cid))
+	defer trazas.Terminar(span, &err)
+
+	s.mu.RLock()
+	destinos := s.destinos
+	factor := s.factorReplicacion
+	minimo := s.minimoPines
+	s.mu.RUnlock()
+
+	conPin = make([]string, 0, factor)
+	pendientes := make([]destinoPin, 0, len(destinos))
+	ausentes := make(map[string]bool, len(destinos))
+
+	for _, destino := range destinos {
+		if len(conPin) >= factor {
+			break
+		}
+		ok, err := destino.tienePin(ctx, cid)
+		if err != nil {
+			slog.WarnContext(ctx, "IPFS: no se pudo consultar el pin", "cid", cid, "destino", destino.nombre(), "error", err)
+		} else if !ok {
+			ausentes[destino.nombre()] = true
+		}
+		if ok {
+			conPin = append(conPin, destino.nombre())
+		} else {
+			pendientes = append(pendientes, destino)
+		}
+	}
+
+	for _, destino := range pendientes {
+		if len(conPin) >= factor {
+			break
+		}
+		ctxPin, spanPin := s.iniciarSpan(ctx, "ipfs.pin", attribute.String("cid", cid), attribute.String("destino", destino.nombre()))
+		inicio := time.Now()
+		err := destino.pin(ctxPin, cid)
+		metricas.ObservarIPFS(metricas.IPFSPin, inicio, err)
+		trazas.Terminar(spanPin, &err)
+		if err != nil {
+			slog.WarnContext(ctx, "IPFS: no se pudo pinear el CID", "cid", cid, "destino", destino.nombre(), "error", err)
+			continue
+		}
+		conPin = append(conPin, destino.nombre())
+		delete(ausentes, destino.nombre())
+	}
+	for _, destino := range destinos {
+		if ausentes[destino.nombre()] {
+			sinPin = append(sinPin, destino.nombre())
+		}
+	}
+
+	span.SetAttributes(attribute.Int("copias", len(conPin)))
+
+	if len(conPin) < factor {
+		slog.WarnContext(ctx, "IPFS: CID subreplicado", "cid", cid, "copias", len(conPin), "factor", factor)
+	} else {
+		slog.DebugContext(ctx, "IPFS: CID pineado", "cid", cid, "destinos", conPin)
+	}
+
+	if len(conPin) < minimo {
+		return conPin, sinPin, fmt.Errorf("CID %s pineado en %d destinos, se requieren al menos %d", cid, len(conPin), minimo)
+	}
+
+	return conPin, sinPin, nil
+}
+
 // RecuperarJSON recupera datos JSON de IPFS usando el CID
 func (s *IPFSService) RecuperarJSON(ctx context.Context, cid string) (string, error) {
 	data, err := s.Recuperar(ctx, cid)
@@ -160,35 +307,118 @@ func (s *IPFSService) RecuperarJSON(ctx context.Context, cid string) (string, er
 
 // Recuperar recupera datos de IPFS usando el CID
 func (s *IPFSService) Recuperar(ctx context.Context, cid string) ([]byte, error) {
-	url := fmt.Sprintf("http://%s:%s/api/v0/cat?arg=%s", s.host, s.port, cid)
+	stream, err := s.RecuperarStream(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	content, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo respuesta IPFS: %w", err)
+	}
+
+	return content, nil
+}
+
+// RecuperarStream abre el contenido de un CID para leerlo en streaming. Si el nodo principal no
+// responde o no tiene el CID, prueba los demás nodos Kubo configurados (IPFS_NODES).
+// El llamador debe cerrar el reader retornado.
+func (s *IPFSService) RecuperarStream(ctx context.Context, cid string) (_ io.ReadCloser, err error) {
+	ctx, span := s.iniciarSpan(ctx, "ipfs.cat", attribute.String("cid", cid))
+	defer trazas.Terminar(span, &err)
+
+	var noEncontrado error
+	for _, nodo := range s.nodosKubo() {
+		stream, errNodo := s.cat(ctx, nodo, cid)
+		if errNodo == nil {
+			span.SetAttributes(attribute.String("nodo_lectura", nodo.nombre()))
+			return stream, nil
+		}
+		if errors.Is(errNodo, ErrCIDNoEncontrado) {
+			noEncontrado = errNodo
+		} else {
+			err = errNodo
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		slog.WarnContext(ctx, "IPFS: no se pudo leer el CID del nodo, se prueba el siguiente", "cid", cid, "nodo", nodo.nombre(), "error", errNodo)
+	}
+	// Si algún nodo respondió que no tiene el CID, el contenido no existe en los nodos disponibles
+	if noEncontrado != nil {
+		err = noEncontrado
+	}
+	return nil, err
+}
+
+// cat abre el contenido de un CID en un nodo Kubo
+func (s *IPFSService) cat(ctx context.Context, nodo *nodoKubo, cid string) (io.ReadCloser, error) {
+	url := fmt.Sprintf("http://%s:%s/api/v0/cat?arg=%s", nodo.host, nodo.port, cid)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creando request: %w", err)
 	}
 
+	// La latencia de cat se mide hasta recibir la respuesta; un CID inexistente no es un fallo de IPFS
+	inicio := time.Now()
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error recuperando de IPFS: %w", err)
+		metricas.ObservarIPFS(metricas.IPFSCat, inicio, err)
+		return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("error recuperando de IPFS: %w", err))
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("IPFS retornó status %d: %s", resp.StatusCode, string(bodyBytes))
+		err := fmt.Errorf("IPFS retornó status %d: %s", resp.StatusCode, string(bodyBytes))
+		if cidNoEncontrado(string(bodyBytes)) {
+			metricas.ObservarIPFS(metricas.IPFSCat, inicio, nil)
+			return nil, ErrCIDNoEncontrado.Con(err)
+		}
+		metricas.ObservarIPFS(metricas.IPFSCat, inicio, err)
+		return nil, ErrorDependencia(DependenciaIPFS, err)
 	}
 
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error leyendo respuesta IPFS: %w", err)
+	metricas.ObservarIPFS(metricas.IPFSCat, inicio, nil)
+	return resp.Body, nil
+}
+
+// ErrIPFSDegradado indica que el nodo principal no responde pero alguna réplica sí: las lecturas siguen
+// disponibles, los registros (que suben el contenido al principal) no
+var ErrIPFSDegradado = errors.New("nodo IPFS principal no disponible; las lecturas se sirven desde réplicas")
+
+// VerificarConexion verifica si el nodo IPFS principal está disponible. Si no responde pero alguna
+// réplica Kubo sí, retorna un error que envuelve ErrIPFSDegradado.
+func (s *IPFSService) VerificarConexion(ctx context.Context) (err error) {
+	ctx, span := s.iniciarSpan(ctx, "ipfs.version")
+	defer trazas.Terminar(span, &err)
+
+	nodos := s.nodosKubo()
+	errPrincipal := s.version(ctx, nodos[0])
+	if errPrincipal == nil {
+		return nil
+	}
+	for _, replica := range nodos[1:] {
+		if s.version(ctx, replica) == nil {
+			return fmt.Errorf("%w (%s responde): %v", ErrIPFSDegradado, replica.nombre(), errPrincipal)
+		}
 	}
+	return errPrincipal
+}
 
-	return content, nil
+// VerificarLectura verifica que algún nodo Kubo, el principal o una réplica, pueda servir lecturas
+func (s *IPFSService) VerificarLectura(ctx context.Context) error {
+	if err := s.VerificarConexion(ctx); err != nil && !errors.Is(err, ErrIPFSDegradado) {
+		return err
+	}
+	return nil
 }
 
-// VerificarConexion verifica si el nodo IPFS está disponible
-func (s *IPFSService) VerificarConexion(ctx context.Context) error {
-	url := fmt.Sprintf("http://%s:%s/api/v0/version", s.host, s.port)
+// version consulta la versión de un nodo Kubo
+func (s *IPFSService) version(ctx context.Context, nodo *nodoKubo) error {
+	url := fmt.Sprintf("http://%s:%s/api/v0/version", nodo.host, nodo.port)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
@@ -197,13 +427,40 @@ func (s *IPFSService) VerificarConexion(ctx context.Context) error {
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error conectando a IPFS: %w", err)
+		return ErrorDependencia(DependenciaIPFS, fmt.Errorf("error conectando a IPFS: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("IPFS no está disponible, status: %d", resp.StatusCode)
+		return ErrorDependencia(DependenciaIPFS, fmt.Errorf("IPFS no está disponible, status: %d", resp.StatusCode))
 	}
 
 	return nil
 }
+
+// nodosKubo retorna los nodos Kubo configurados; el primero es siempre el principal
+func (s *IPFSService) nodosKubo() []*nodoKubo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	nodos := make([]*nodoKubo, 0, len(s.destinos))
+	for _, destino := range s.destinos {
+		if nodo, ok := destino.(*nodoKubo); ok {
+			nodos = append(nodos, nodo)
+		}
+	}
+	return nodos
+}
+
+// iniciarSpan abre un span de cliente para una llamada al nodo IPFS
+func (s *IPFSService) iniciarSpan(ctx context.Context, nombre string, atributos ...attribute.KeyValue) (context.Context, trace.Span) {
+	atributos = append(atributos, attribute.String("nodo", s.host+":"+s.port))
+	return trazas.Iniciar(ctx, nombre, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(atributos...))
+}
+
+// cidNoEncontrado reconoce en la respuesta de error de Kubo un CID mal formado o sin contenido
+// disponible, que es un error de la petición y no una caída del nodo
+func cidNoEncontrado(respuesta string) bool {
+	respuesta = strings.ToLower(respuesta)
+	return strings.Contains(respuesta, "invalid path") || strings.Contains(respuesta, "invalid cid") ||
+		strings.Contains(respuesta, "not found")
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+	"github.com/edinfamous/blockchain-medisupply/pkg/canonical"
+)
+
+// LoteConfig define los límites del registro por lotes
+type LoteConfig struct {
+	MaximoElementos int // Eventos máximos por lote
+	Concurrencia    int // Subidas a IPFS y cadenas de producto procesadas en paralelo
+}
+
+var (
+	// ErrLoteVacio indica un lote sin elementos
+	ErrLoteVacio = NuevoErrorDominio(TipoValidacion, "lote-vacio", "el lote no contiene eventos")
+	// ErrLoteDemasiadoGrande indica un lote con más elementos de los permitidos
+	ErrLoteDemasiadoGrande = NuevoErrorDominio(TipoValidacion, "lote-demasiado-grande", "el lote supera el máximo de eventos")
+)
+
+// ErrorValidacionLote indica que algún elemento del lote no pasó la validación; no se registró ninguno
+type ErrorValidacionLote struct {
+	Resultados []models.ResultadoLote // Un resultado por elemento; los válidos sin error
+	Invalidos  int
+}
+
+func (e *ErrorValidacionLote) Error() string {
+	return e.Traducir(i18n.IdiomaPorDefecto)
+}
+
+// Traducir muestra el resumen de la validación en el idioma indicado
+func (e *ErrorValidacionLote) Traducir(idioma i18n.Idioma) string {
+	return i18n.Traducir(idioma, "lote.invalidos", e.Invalidos, len(e.Resultados))
+}
+
+// defaultLoteConfig son los límites usados si no se configuran explícitamente
+func defaultLoteConfig() LoteConfig {
+	return LoteConfig{
+		MaximoElementos: 500,
+		Concurrencia:    8,
+	}
+}
+
+// ConfigurarLote establece los límites del registro por lotes
+func (s *TransaccionService) ConfigurarLote(cfg LoteConfig) {
+	if cfg.Concurrencia <= 0 {
+		cfg.Concurrencia = 1
+	}
+	s.loteConfig = cfg
+}
+
+// GetLimitesLote retorna los límites configurados para lotes
+func (s *TransaccionService) GetLimitesLote() LoteConfig {
+	return s.loteConfig
+}
+
+// RegistrarLote registra varios eventos en una sola llamada. Todos los elementos se validan y autorizan
+// antes de empezar; si alguno es inválido retorna *ErrorValidacionLote sin registrar nada. Después cada
+// elemento puede fallar por separado (IPFS, DynamoDB) sin afectar a los demás, salvo los eventos del
+// mismo producto, que se encadenan juntos y se registran o fallan por tramos de MaxEventosEncadenados.
+func (s *TransaccionService) RegistrarLote(ctx context.Context, reqs []*models.TransaccionRequest) (*models.LoteResponse, error) {
+	if len(reqs) == 0 {
+		return nil, ErrLoteVacio
+	}
+	if len(reqs) > s.loteConfig.MaximoElementos {
+		return nil, ErrLoteDemasiadoGrande.Con(i18n.NuevoError("lote.eventos-maximo", len(reqs), s.loteConfig.MaximoElementos))
+	}
+	slog.DebugContext(ctx, "registrando lote", "eventos", len(reqs))
+
+	// 1. Validar y autorizar todo el lote antes de tocar IPFS o DynamoDB
+	transacciones, err := s.validarLote(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	resultados := make([]models.ResultadoLote, len(reqs))
+	for i, transaccion := range transacciones {
+		resultados[i] = models.ResultadoLote{Indice: i, IDProducto: transaccion.IDProducto}
+	}
+	fallar := func(i int, err error) {
+		resultados[i].Estado = models.LoteFallida
+		resultados[i].Error = i18n.TraducirError(err, i18n.IdiomaDe(ctx))
+	}
+
+	// 2. Subir DatosEvento a IPFS con concurrencia acotada
+	checkCtx, checkCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer checkCancel()
+	if err := s.ipfsService.VerificarConexion(checkCtx); err != nil {
+		return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("IPFS no está disponible en %s:%s: %w", s.ipfsService.GetHost(), s.ipfsService.GetPort(), err))
+	}
+
+	s.procesarConcurrente(len(transacciones), func(i int) {
+		ipfsCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		cid, nodos, err := s.ipfsService.AlmacenarJSON(ipfsCtx, transacciones[i].DatosEvento)
+		if err != nil {
+			fallar(i, fmt.Errorf("error almacenando en IPFS: %w", err))
+			return
+		}
+		transacciones[i].IPFSCid = cid
+		transacciones[i].NodosIPFS = nodos
+	})
+
+	// 3. Encadenar y guardar por producto, conservando el orden del lote dentro de cada producto
+	var productos []string
+	grupos := make(map[string][]int)
+	for i, transaccion := range transacciones {
+		if resultados[i].Estado == models.LoteFallida {
+			continue
+		}
+		if _, ok := grupos[transaccion.IDProducto]; !ok {
+			productos = append(productos, transaccion.IDProducto)
+		}
+		grupos[transaccion.IDProducto] = append(grupos[transaccion.IDProducto], i)
+	}
+
+	s.procesarConcurrente(len(productos), func(p int) {
+		indices := grupos[productos[p]]
+		grupo := make([]*models.Transaccion, len(indices))
+		for j, i := range indices {
+			grupo[j] = transacciones[i]
+		}
+
+		dynamoCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		guardados, err := s.guardarLoteEncadenado(dynamoCtx, grupo)
+		for _, i := range indices[guardados:] {
+			fallar(i, fmt.Errorf("error guardando en DynamoDB: %w", err))
+		}
+		for _, i := range indices[:guardados] {
+			resultados[i].Estado = models.LoteRegistrada
+			resultados[i].IDTransaction = transacciones[i].IDTransaction
+			resultados[i].HashEvento = transacciones[i].HashEvento
+			resultados[i].IPFSCid = transacciones[i].IPFSCid
+			resultados[i].Secuencia = transacciones[i].Secuencia
+		}
+	})
+
+	// 4. Anclar en blockchain. El contrato no tiene registro por lotes, así que los hashes se anclan
+	// uno a uno en una sola goroutine para no competir por el nonce de la cuenta.
+	respuesta := &models.LoteResponse{Total: len(reqs), Resultados: resultados}
+	var anclar []*models.Transaccion
+	for i, resultado := range resultados {
+		if resultado.Estado == models.LoteRegistrada {
+			respuesta.Registradas++
+			anclar = append(anclar, transacciones[i])
+			metricas.TransaccionRegistrada(transacciones[i].TipoEvento)
+			s.eventos.Publicar(models.NuevaNotificacion(models.NotificacionRegistrada, transacciones[i]))
+		} else {
+			respuesta.Fallidas++
+		}
+	}
+	s.encolarAnclajes(len(anclar))
+	go s.anclarLoteAsync(logging.Desacoplar(ctx), copiarTransacciones(anclar))
+
+	slog.InfoContext(ctx, "lote procesado", "total", respuesta.Total, "registradas", respuesta.Registradas, "fallidas", respuesta.Fallidas)
+	return respuesta, nil
+}
+
+// validarLote valida, canonicaliza y autoriza cada elemento, y arma sus transacciones
+func (s *TransaccionService) validarLote(ctx context.Context, reqs []*models.TransaccionRequest) ([]*models.Transaccion, error) {
+	principal := auth.PrincipalDesde(ctx)
+	propiedad := make(map[string]error) // Propiedad verificada por producto y tipo de evento
+
+	transacciones := make([]*models.Transaccion, len(reqs))
+	errValidacion := &ErrorValidacionLote{Resultados: make([]models.ResultadoLote, len(reqs))}
+
+	for i, req := range reqs {
+		resultado := &errValidacion.Resultados[i]
+		resultado.Indice = i
+		resultado.IDProducto = req.IDProducto
+
+		err := s.validarElementoLote(ctx, req, principal, propiedad)
+		if err != nil {
+			resultado.Estado = models.LoteInvalida
+			resultado.Error = i18n.TraducirError(err, i18n.IdiomaDe(ctx))
+			errValidacion.Invalidos++
+			continue
+		}
+
+		datosCanonicos, _ := canonical.CanonicalizarString(req.DatosEvento)
+		transacciones[i] = &models.Transaccion{
+			IDTransaction: uuid.New().String(),
+			TipoEvento:    req.TipoEvento,
+			IDProducto:    req.IDProducto,
+			FechaEvento:   time.Now(),
+			DatosEvento:   datosCanonicos,
+			ActorEmisor:   req.ActorEmisor,
+			HashVersion:   utils.HashVersionActual,
+			Estado:        "pendiente",
+		}
+	}
+
+	if errValidacion.Invalidos > 0 {
+		return nil, errValidacion
+	}
+	return transacciones, nil
+}
+
+// validarElementoLote valida un elemento; la propiedad del producto se consulta una vez por producto y tipo de evento
+func (s *TransaccionService) validarElementoLote(ctx context.Context, req *models.TransaccionRequest, principal *models.Principal, propiedad map[string]error) error {
+	if req == nil {
+		return ErrSolicitudInvalida.Con(i18n.NuevoError("lote.elemento-vacio"))
+	}
+	aplicarActorAutenticado(ctx, req)
+	if err := validarSolicitud(req); err != nil {
+		return err
+	}
+	if _, err := canonical.CanonicalizarString(req.DatosEvento); err != nil {
+		return ErrSolicitudInvalida.Con(err)
+	}
+	if s.politica == nil || principal == nil {
+		return nil
+	}
+
+	if err := s.politica.AutorizarEvento(principal, req.TipoEvento); err != nil {
+		return err
+	}
+	clave := req.TipoEvento + "\x00" + req.IDProducto
+	if err, ok := propiedad[clave]; ok {
+		return err
+	}
+	err := s.autorizarEvento(ctx, req)
+	propiedad[clave] = err
+	return err
+}
+
+// guardarLoteEncadenado encadena y guarda los eventos de un mismo producto en tramos de hasta
+// MaxEventosEncadenados. Cada tramo se escribe junto con el avance de la cabeza en una sola transacción,
+// de modo que un registro concurrente nunca puede tomar la misma secuencia. Retorna cuántos eventos
+// (desde el inicio del grupo) quedaron guardados; los tramos siguientes a uno fallido no se intentan.
+func (s *TransaccionService) guardarLoteEncadenado(ctx context.Context, grupo []*models.Transaccion) (int, error) {
+	for inicio := 0; inicio < len(grupo); inicio += MaxEventosEncadenados {
+		if err := s.guardarTramoEncadenado(ctx, grupo[inicio:min(inicio+MaxEventosEncadenados, len(grupo))]); err != nil {
+			return inicio, err
+		}
+	}
+	return len(grupo), nil
+}
+
+// guardarTramoEncadenado encadena el tramo a partir de la cabeza actual y lo guarda con escritura
+// condicional. Si otro registro avanzó la cadena primero no se escribió nada: relee la cabeza y reintenta.
+func (s *TransaccionService) guardarTramoEncadenado(ctx context.Context, tramo []*models.Transaccion) error {
+	idProducto := tramo[0].IDProducto
+
+	for intento := 1; ; intento++ {
+		cabeza, err := s.dynamoDBService.ObtenerCabezaCadena(ctx, idProducto)
+		if err != nil {
+			return err
+		}
+		for _, transaccion := range tramo {
+			if err := s.autorizarPropiedad(ctx, transaccion.TipoEvento, idProducto, cabeza); err != nil {
+				return err
+			}
+		}
+
+		var secuencia int64
+		hashAnterior := ""
+		if cabeza != nil {
+			secuencia = cabeza.Secuencia
+			hashAnterior = cabeza.HashEvento
+		}
+		for _, transaccion := range tramo {
+			secuencia++
+			transaccion.Secuencia = secuencia
+			transaccion.HashEventoAnterior = hashAnterior
+			transaccion.HashEvento = utils.CalcularHashTransaccion(transaccion)
+			hashAnterior = transaccion.HashEvento
+		}
+
+		err = s.dynamoDBService.GuardarTransaccionesEncadenadas(ctx, tramo, cabeza)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrConflictoCadena) || intento >= maxIntentosCadena {
+			return err
+		}
+		slog.WarnContext(ctx, "cadena avanzada por otro registro, reintentando lote", "id_producto", idProducto, "intento", intento, "maximo", maxIntentosCadena)
+	}
+}
+
+// procesarConcurrente ejecuta fn(0..n-1) con a lo sumo loteConfig.Concurrencia ejecuciones simultáneas
+func (s *TransaccionService) procesarConcurrente(n int, fn func(i int)) {
+	semaforo := make(chan struct{}, s.loteConfig.Concurrencia)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		semaforo <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaforo }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// anclarLoteAsync ancla en blockchain los eventos registrados de un lote, uno tras otro
+func (s *TransaccionService) anclarLoteAsync(ctx context.Context, transacciones []models.Transaccion) {
+	for _, transaccion := range transacciones {
+		s.registrarEnBlockchainAsync(ctx, transaccion)
+	}
+}
+
+// copiarTransacciones copia las transacciones que se anclan en segundo plano
+func copiarTransacciones(transacciones []*models.Transaccion) []models.Transaccion {
+	copias := make([]models.Transaccion, len(transacciones))
+	for i, transaccion := range transacciones {
+		copias[i] = *transaccion
+	}
+	return copias
+}
@@ -3,8 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/edinfamous/blockchain-medisupply/internal/eventos"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
 	"github.com/edinfamous/blockchain-medisupply/internal/models"
 )
 
@@ -12,6 +16,14 @@ import (
 type OracleService struct {
 	transaccionService *TransaccionService
 	dynamoDBService    *DynamoDBService
+	eventos            *eventos.Bus        // Opcional: publica los cambios de estado del producto
+	estados            AlmacenEstadoOracle // Último estado conocido de cada producto
+}
+
+// AlmacenEstadoOracle guarda el último estado del Oracle por producto. DynamoDBService lo implementa sobre la tabla de control.
+type AlmacenEstadoOracle interface {
+	// IntercambiarEstadoOracle guarda el estado y retorna el anterior ("" si el producto no tenía)
+	IntercambiarEstadoOracle(ctx context.Context, idProducto, estado string) (string, error)
 }
 
 // NewOracleService crea una nueva instancia de OracleService
@@ -22,19 +34,33 @@ func NewOracleService(transaccion *TransaccionService, dynamo *DynamoDBService)
 	}
 }
 
+// ConfigurarEventos publica en el bus una notificación estado-oracle cada vez que cambia el estado de un producto
+func (s *OracleService) ConfigurarEventos(bus *eventos.Bus, estados AlmacenEstadoOracle) {
+	s.eventos = bus
+	s.estados = estados
+}
+
 // ObtenerDatosVerificados obtiene y verifica el historial completo de un producto (patrón Oracle)
 func (s *OracleService) ObtenerDatosVerificados(ctx context.Context, idProducto string) (*models.OracleDataResponse, error) {
 	// 1. Consultar transacciones relacionadas en DynamoDB
 	transacciones, err := s.dynamoDBService.ObtenerTransaccionesPorProducto(ctx, idProducto)
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+		return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo transacciones: %w", err))
 	}
 
-	if len(transacciones) == 0 {
-		return nil, fmt.Errorf("no se encontraron transacciones para el producto: %s", idProducto)
+	ordenarPorCadena(transacciones)
+
+	// 2. Verificar la cadena de hashes del producto (huecos, bifurcaciones, eliminaciones). Se lee la
+	// cabeza antes de responder 404: un producto sin eventos pero con cabeza perdió su historial
+	cadena, err := s.verificarCadena(ctx, idProducto, transacciones)
+	if err != nil {
+		return nil, err
+	}
+	if len(transacciones) == 0 && cadena.Longitud == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrProductoSinEventos, idProducto)
 	}
 
-	// 2. Construir respuesta del Oracle
+	// 3. Construir respuesta del Oracle
 	response := &models.OracleDataResponse{
 		IDProducto:          idProducto,
 		UltimaActualizacion: time.Now(),
@@ -42,8 +68,8 @@ func (s *OracleService) ObtenerDatosVerificados(ctx context.Context, idProducto
 		Metadata:            make(map[string]string),
 	}
 
-	// 3. Validar cada transacción contra blockchain
-	cadenaVerificada := true
+	// 4. Validar cada transacción contra blockchain
+	cadenaVerificada := cadena.Integra
 	var ultimaFecha time.Time
 
 	for _, transaccion := range transacciones {
@@ -57,11 +83,13 @@ func (s *OracleService) ObtenerDatosVerificados(ctx context.Context, idProducto
 			ReferenciaBlockchain:  transaccion.DirectionBlockchain,
 			IPFSCid:               transaccion.IPFSCid,
 			ActorEmisor:           transaccion.ActorEmisor,
+			Secuencia:             transaccion.Secuencia,
+			HashEventoAnterior:    transaccion.HashEventoAnterior,
 			ResultadoVerificacion: false,
 		}
 
 		if err != nil {
-			evento.ErrorVerificacion = err.Error()
+			evento.ErrorVerificacion = i18n.TraducirError(err, i18n.IdiomaDe(ctx))
 			cadenaVerificada = false
 		} else {
 			evento.ResultadoVerificacion = verificacion.Verificado
@@ -79,7 +107,7 @@ func (s *OracleService) ObtenerDatosVerificados(ctx context.Context, idProducto
 		}
 	}
 
-	// 4. Establecer estado basado en verificaciones
+	// 5. Establecer estado basado en verificaciones
 	response.CadenaVerificada = cadenaVerificada
 	if cadenaVerificada {
 		response.Estado = "verificado"
@@ -87,34 +115,101 @@ func (s *OracleService) ObtenerDatosVerificados(ctx context.Context, idProducto
 		response.Estado = "no_verificado"
 	}
 
-	// 5. Agregar metadata útil
+	// 6. Agregar metadata útil
 	response.Metadata["total_eventos"] = fmt.Sprintf("%d", len(transacciones))
+	response.Metadata["longitud_cadena"] = fmt.Sprintf("%d", cadena.Longitud)
+	response.Metadata["anomalias_cadena"] = fmt.Sprintf("%d", len(cadena.Anomalias))
+	if len(transacciones) == 0 {
+		// Historial eliminado: no queda evento del que tomar los datos de la notificación
+		s.notificarEstado(ctx, response.Estado, &models.Transaccion{IDProducto: idProducto, Secuencia: cadena.Longitud})
+		return response, nil
+	}
+	ultima := transacciones[len(transacciones)-1]
 	response.Metadata["ultima_actualizacion"] = ultimaFecha.Format(time.RFC3339)
-	response.Metadata["tipo_ultimo_evento"] = transacciones[len(transacciones)-1].TipoEvento
+	response.Metadata["tipo_ultimo_evento"] = ultima.TipoEvento
+
+	s.notificarEstado(ctx, response.Estado, ultima)
 
 	return response, nil
 }
 
+// notificarEstado publica estado-oracle si el estado calculado difiere del último guardado para el producto.
+// Un error del almacén no afecta la respuesta del Oracle: solo se pierde la notificación.
+func (s *OracleService) notificarEstado(ctx context.Context, estado string, ultima *models.Transaccion) {
+	if s.eventos == nil || s.estados == nil {
+		return
+	}
+	anterior, err := s.estados.IntercambiarEstadoOracle(ctx, ultima.IDProducto, estado)
+	if err != nil {
+		slog.WarnContext(ctx, "oracle: no se pudo guardar el estado", "id_producto", ultima.IDProducto, "error", err)
+		return
+	}
+	if anterior == estado {
+		return
+	}
+
+	notificacion := models.NuevaNotificacion(models.NotificacionEstadoOracle, ultima)
+	notificacion.EstadoOracle = estado
+	notificacion.EstadoOracleAnterior = anterior
+	s.eventos.Publicar(notificacion)
+}
+
 // ObtenerHistorialVerificado obtiene el historial verificado de un producto
 func (s *OracleService) ObtenerHistorialVerificado(ctx context.Context, idProducto string) (*models.HistorialVerificado, error) {
+	var historial *models.HistorialVerificado
+	err := s.RecorrerHistorialVerificado(ctx, idProducto,
+		func(total int, cadena *models.VerificacionCadena) error {
+			historial = &models.HistorialVerificado{
+				IDProducto:    idProducto,
+				TotalEventos:  total,
+				Verificados:   0,
+				NoVerificados: 0,
+				Eventos:       make([]models.EventoVerificado, 0, total),
+				Cadena:        cadena,
+				FechaConsulta: time.Now(),
+			}
+			return nil
+		},
+		func(evento models.EventoVerificado) error {
+			if evento.ResultadoVerificacion {
+				historial.Verificados++
+			} else {
+				historial.NoVerificados++
+			}
+			historial.Eventos = append(historial.Eventos, evento)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return historial, nil
+}
+
+// RecorrerHistorialVerificado verifica los eventos de un producto en orden de cadena. alIniciar recibe el
+// total de eventos y la verificación de la cadena; después emitir recibe cada evento en cuanto se verifica.
+// Un error de cualquiera de las dos funciones detiene el recorrido y se retorna tal cual.
+func (s *OracleService) RecorrerHistorialVerificado(ctx context.Context, idProducto string, alIniciar func(total int, cadena *models.VerificacionCadena) error, emitir func(models.EventoVerificado) error) error {
 	// 1. Consultar transacciones relacionadas en DynamoDB
 	transacciones, err := s.dynamoDBService.ObtenerTransaccionesPorProducto(ctx, idProducto)
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+		return ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo transacciones: %w", err))
 	}
+	ordenarPorCadena(transacciones)
 
-	// 2. Construir historial
-	historial := &models.HistorialVerificado{
-		IDProducto:    idProducto,
-		TotalEventos:  len(transacciones),
-		Verificados:   0,
-		NoVerificados: 0,
-		Eventos:       make([]models.EventoVerificado, 0),
-		FechaConsulta: time.Now(),
+	// 2. Verificar la cadena de hashes del producto
+	cadena, err := s.verificarCadena(ctx, idProducto, transacciones)
+	if err != nil {
+		return err
+	}
+	if err := alIniciar(len(transacciones), cadena); err != nil {
+		return err
 	}
 
-	// 3. Validar cada una contra blockchain y construir historial
+	// 3. Validar cada una contra blockchain
 	for _, transaccion := range transacciones {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		verificacion, err := s.transaccionService.VerificarIntegridad(ctx, transaccion.IDTransaction)
 
 		eventoVerificado := models.EventoVerificado{
@@ -124,33 +219,47 @@ func (s *OracleService) ObtenerHistorialVerificado(ctx context.Context, idProduc
 			ReferenciaBlockchain: transaccion.DirectionBlockchain,
 			IPFSCid:              transaccion.IPFSCid,
 			ActorEmisor:          transaccion.ActorEmisor,
+			Secuencia:            transaccion.Secuencia,
+			HashEventoAnterior:   transaccion.HashEventoAnterior,
 		}
 
 		if err != nil {
 			eventoVerificado.ResultadoVerificacion = false
-			eventoVerificado.ErrorVerificacion = err.Error()
-			historial.NoVerificados++
+			eventoVerificado.ErrorVerificacion = i18n.TraducirError(err, i18n.IdiomaDe(ctx))
 		} else {
 			eventoVerificado.ResultadoVerificacion = verificacion.Verificado
-			if verificacion.Verificado {
-				historial.Verificados++
-			} else {
-				historial.NoVerificados++
+			if !verificacion.Verificado {
 				eventoVerificado.ErrorVerificacion = verificacion.Mensaje
 			}
 		}
 
-		historial.Eventos = append(historial.Eventos, eventoVerificado)
+		if err := emitir(eventoVerificado); err != nil {
+			return err
+		}
 	}
 
-	return historial, nil
+	return nil
+}
+
+// verificarCadena verifica la cadena de hashes del producto contra su cabeza registrada
+func (s *OracleService) verificarCadena(ctx context.Context, idProducto string, transacciones []*models.Transaccion) (*models.VerificacionCadena, error) {
+	cabeza, err := s.dynamoDBService.ObtenerCabezaCadena(ctx, idProducto)
+	if err != nil {
+		return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo cabeza de cadena: %w", err))
+	}
+
+	cadena := VerificarCadenaProductoEn(i18n.IdiomaDe(ctx), transacciones, cabeza)
+	if !cadena.Integra {
+		slog.WarnContext(ctx, "oracle: cadena del producto con anomalías", "id_producto", idProducto, "anomalias", len(cadena.Anomalias))
+	}
+	return cadena, nil
 }
 
 // ValidarCadenaSupply valida que la cadena de suministro sea coherente
 func (s *OracleService) ValidarCadenaSupply(ctx context.Context, idProducto string) (bool, []string, error) {
 	transacciones, err := s.dynamoDBService.ObtenerTransaccionesPorProducto(ctx, idProducto)
 	if err != nil {
-		return false, nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+		return false, nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo transacciones: %w", err))
 	}
 
 	// Validar orden lógico de eventos
@@ -168,17 +277,37 @@ func (s *OracleService) ValidarCadenaSupply(ctx context.Context, idProducto stri
 	}
 
 	if !tieneFabricacion {
-		errores = append(errores, "Falta evento de fabricación")
+		errores = append(errores, i18n.T(ctx, "cadena.falta-fabricacion"))
 	}
 
 	// Validar orden cronológico
 	var ultimaFecha time.Time
 	for i, tx := range transacciones {
 		if i > 0 && tx.FechaEvento.Before(ultimaFecha) {
-			errores = append(errores, fmt.Sprintf("Evento %s tiene fecha anterior al evento previo", tx.IDTransaction))
+			errores = append(errores, i18n.T(ctx, "cadena.fecha-anterior", tx.IDTransaction))
 		}
 		ultimaFecha = tx.FechaEvento
 	}
 
 	return len(errores) == 0, errores, nil
 }
+
+// AlmacenEstadoOracleMemoria es un AlmacenEstadoOracle en memoria, útil para desarrollo y tests
+type AlmacenEstadoOracleMemoria struct {
+	mu      sync.Mutex
+	estados map[string]string
+}
+
+// NewAlmacenEstadoOracleMemoria crea un almacén de estados del Oracle en memoria
+func NewAlmacenEstadoOracleMemoria() *AlmacenEstadoOracleMemoria {
+	return &AlmacenEstadoOracleMemoria{estados: make(map[string]string)}
+}
+
+// IntercambiarEstadoOracle guarda el estado y retorna el anterior
+func (a *AlmacenEstadoOracleMemoria) IntercambiarEstadoOracle(ctx context.Context, idProducto, estado string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	anterior := a.estados[idProducto]
+	a.estados[idProducto] = estado
+	return anterior, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// ReplicacionService repara periódicamente los CIDs que quedaron subreplicados
+type ReplicacionService struct {
+	ipfsService     *IPFSService
+	dynamoDBService *DynamoDBService
+	intervalo       time.Duration
+}
+
+// NewReplicacionService crea una nueva instancia de ReplicacionService
+func NewReplicacionService(ipfs *IPFSService, dynamo *DynamoDBService, intervalo time.Duration) *ReplicacionService {
+	return &ReplicacionService{
+		ipfsService:     ipfs,
+		dynamoDBService: dynamo,
+		intervalo:       intervalo,
+	}
+}
+
+// Iniciar ejecuta el ciclo de reparación hasta que el contexto se cancele
+func (s *ReplicacionService) Iniciar(ctx context.Context) {
+	if s.intervalo <= 0 {
+		slog.Warn("replicación: ciclo de reparación deshabilitado")
+		return
+	}
+
+	ticker := time.NewTicker(s.intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reparados, err := s.Reparar(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "replicación: error en ciclo de reparación", "error", err)
+				continue
+			}
+			if reparados > 0 {
+				slog.InfoContext(ctx, "replicación: CIDs re-pineados", "reparados", reparados)
+			}
+		}
+	}
+}
+
+// Reparar revisa todas las transacciones y vuelve a pinear los CIDs con menos copias que el factor de replicación.
+// Retorna el número de CIDs (eventos y adjuntos) cuyo registro de nodos cambió.
+func (s *ReplicacionService) Reparar(ctx context.Context) (int, error) {
+	reparados := 0
+
+	err := s.dynamoDBService.RecorrerTransacciones(ctx, func(transaccion *models.Transaccion) error {
+		if transaccion.IPFSCid == "" {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if nodos, ok := s.revisar(ctx, transaccion.IPFSCid, transaccion.NodosIPFS, "id_transaccion", transaccion.IDTransaction); ok {
+			if err := s.dynamoDBService.ActualizarNodosIPFS(ctx, transaccion.IDTransaction, nodos); err != nil {
+				return fmt.Errorf("error registrando nodos de %s: %w", transaccion.IDTransaction, err)
+			}
+			reparados++
+		}
+
+		// Los adjuntos tienen su propio CID y su propio registro de nodos
+		for i, adjunto := range transaccion.Adjuntos {
+			nodos, ok := s.revisar(ctx, adjunto.CID, adjunto.NodosIPFS, "id_transaccion", transaccion.IDTransaction, "adjunto", adjunto.Nombre)
+			if !ok {
+				continue
+			}
+			if err := s.dynamoDBService.ActualizarNodosAdjunto(ctx, transaccion.IDTransaction, i, nodos); err != nil {
+				return fmt.Errorf("error registrando nodos del adjunto %s de %s: %w", adjunto.Nombre, transaccion.IDTransaction, err)
+			}
+			reparados++
+		}
+
+		return nil
+	})
+
+	return reparados, err
+}
+
+// revisar re-pinea el CID y retorna el nuevo registro de nodos y si cambió. Si la replicación falla, el
+// registro se conserva tal cual: el CID sigue subreplicado y se reintentará en el próximo ciclo. Un nodo
+// solo sale del registro si respondió que ya no tiene el CID; si su consulta falló, se conserva.
+func (s *ReplicacionService) revisar(ctx context.Context, cid string, registrados []string, atributos ...any) ([]string, bool) {
+	conPin, sinPin, err := s.ipfsService.replicar(ctx, cid)
+	if err != nil {
+		slog.WarnContext(ctx, "replicación: CID subreplicado", append(atributos, "cid", cid, "error", err)...)
+		return nil, false
+	}
+
+	nodos := slices.Clone(conPin)
+	for _, nodo := range registrados {
+		if !slices.Contains(nodos, nodo) && !slices.Contains(sinPin, nodo) {
+			nodos = append(nodos, nodo)
+		}
+	}
+	if mismosNodos(nodos, registrados) {
+		return nil, false
+	}
+	return nodos, true
+}
+
+// mismosNodos compara dos listas de nodos sin importar el orden
+func mismosNodos(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	conjunto := make(map[string]bool, len(a))
+	for _, nodo := range a {
+		conjunto[nodo] = true
+	}
+	for _, nodo := range b {
+		if !conjunto[nodo] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,13 +2,26 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
-
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/eventos"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
 	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/trazas"
 	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+	"github.com/edinfamous/blockchain-medisupply/pkg/canonical"
 	"github.com/edinfamous/blockchain-medisupply/pkg/validation"
 )
 
@@ -17,6 +30,13 @@ type TransaccionService struct {
 	blockchainService *BlockchainService
 	ipfsService       *IPFSService
 	dynamoDBService   *DynamoDBService
+	adjuntosConfig    AdjuntosConfig
+	loteConfig        LoteConfig
+	politica          *policy.Motor  // nil = sin autorización por tipo de evento ni propiedad
+	eventos           *eventos.Bus   // nil = sin notificaciones en tiempo real
+	fondos            *MonitorFondos // nil = los anclajes sin fondos terminan como fallidos
+
+	anclajesPendientes atomic.Int64 // Eventos registrados cuyo anclaje en blockchain no terminó
 }
 
 // NewTransaccionService crea una nueva instancia de TransaccionService
@@ -25,21 +45,62 @@ func NewTransaccionService(blockchain *BlockchainService, ipfs *IPFSService, dyn
 		blockchainService: blockchain,
 		ipfsService:       ipfs,
 		dynamoDBService:   dynamo,
+		adjuntosConfig:    defaultAdjuntosConfig(),
+		loteConfig:        defaultLoteConfig(),
 	}
 }
 
+// ConfigurarPolitica establece el motor de políticas que autoriza tipos de evento y propiedad de productos
+func (s *TransaccionService) ConfigurarPolitica(motor *policy.Motor) {
+	s.politica = motor
+}
+
+// ConfigurarFondos establece el monitor de fondos que pausa los anclajes mientras la cuenta firmante no
+// pueda pagar el gas; al reanudarse, los eventos que quedaron pendientes se vuelven a encolar
+func (s *TransaccionService) ConfigurarFondos(monitor *MonitorFondos) {
+	s.fondos = monitor
+	if monitor == nil {
+		return
+	}
+	monitor.AlReanudar(func() {
+		if err := s.ReanclarPendientes(context.Background()); err != nil {
+			slog.Error("error reanclando eventos pendientes", "error", err)
+		}
+	})
+}
+
+// ConfigurarEventos establece el bus al que se publican los registros y los resultados del anclaje
+func (s *TransaccionService) ConfigurarEventos(bus *eventos.Bus) {
+	s.eventos = bus
+}
+
 // RegistrarTransaccion registra una nueva transacción aplicando el patrón off-chain storage
 func (s *TransaccionService) RegistrarTransaccion(ctx context.Context, req *models.TransaccionRequest) (*models.Transaccion, error) {
-	fmt.Println("🟢 Service: RegistrarTransaccion - INICIADO")
-	fmt.Printf("🟢 Service: Request recibido - TipoEvento: %s, IDProducto: %s, ActorEmisor: %s\n", req.TipoEvento, req.IDProducto, req.ActorEmisor)
+	return s.registrar(ctx, req, nil)
+}
 
-	// 1. Validar datos de entrada
-	fmt.Println("🟢 Service: Validando datos de entrada...")
-	if err := validation.ValidateStruct(req); err != nil {
-		fmt.Printf("🔴 Service: Error en validación: %v\n", err)
-		return nil, fmt.Errorf("validación fallida: %w", err)
+// registrar ejecuta el flujo de registro; los adjuntos, si existen, ya deben estar en IPFS
+func (s *TransaccionService) registrar(ctx context.Context, req *models.TransaccionRequest, adjuntos []models.Adjunto) (*models.Transaccion, error) {
+	slog.DebugContext(ctx, "registrando transacción", "tipo_evento", req.TipoEvento, "id_producto", req.IDProducto, "actor_emisor", req.ActorEmisor)
+
+	// 1. Validar datos de entrada y el esquema del tipo de evento
+	aplicarActorAutenticado(ctx, req)
+	if err := validarSolicitud(req); err != nil {
+		slog.InfoContext(ctx, "solicitud de registro inválida", "error", err)
+		return nil, err
+	}
+
+	// Autorizar el tipo de evento y la propiedad del producto antes de subir nada a IPFS
+	if err := s.autorizarEvento(ctx, req); err != nil {
+		slog.InfoContext(ctx, "evento no autorizado", "tipo_evento", req.TipoEvento, "id_producto", req.IDProducto, "error", err)
+		return nil, err
+	}
+
+	// Canonicalizar (RFC 8785) para que JSON equivalente produzca el mismo hash y el mismo CID
+	datosCanonicos, err := canonical.CanonicalizarString(req.DatosEvento)
+	if err != nil {
+		return nil, ErrSolicitudInvalida.Con(err)
 	}
-	fmt.Println("🟢 Service: Validación exitosa")
 
 	// 2. Crear transacción
 	transaccion := &models.Transaccion{
@@ -47,11 +108,12 @@ func (s *TransaccionService) RegistrarTransaccion(ctx context.Context, req *mode
 		TipoEvento:    req.TipoEvento,
 		IDProducto:    req.IDProducto,
 		FechaEvento:   time.Now(),
-		DatosEvento:   req.DatosEvento,
+		DatosEvento:   datosCanonicos,
 		ActorEmisor:   req.ActorEmisor,
+		Adjuntos:      adjuntos,
+		HashVersion:   utils.HashVersionActual,
 		Estado:        "pendiente",
 	}
-	fmt.Println("Transacción creada con ID:", transaccion.IDTransaction)
 
 	// 3. Almacenar datos detallados en IPFS (off-chain storage)
 	// Crear contexto con timeout más largo para IPFS (60 segundos)
@@ -59,128 +121,394 @@ func (s *TransaccionService) RegistrarTransaccion(ctx context.Context, req *mode
 	defer ipfsCancel()
 
 	// Verificar conectividad con IPFS antes de intentar almacenar (con timeout corto de 5 segundos)
-	fmt.Println("🟢 Service: Verificando conectividad con IPFS...")
 	checkCtx, checkCancel := context.WithTimeout(ctx, 5*time.Second)
 	defer checkCancel()
 	if err := s.ipfsService.VerificarConexion(checkCtx); err != nil {
-		fmt.Printf("🔴 Service: IPFS no está disponible: %v\n", err)
+		slog.ErrorContext(ctx, "IPFS no está disponible", "id_transaccion", transaccion.IDTransaction, "error", err)
 		if checkCtx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("timeout al verificar IPFS (5s): verifique que IPFS esté corriendo y accesible en %s:%s", s.ipfsService.GetHost(), s.ipfsService.GetPort())
+			return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("timeout al verificar IPFS (5s) en %s:%s: %w", s.ipfsService.GetHost(), s.ipfsService.GetPort(), checkCtx.Err()))
 		}
-		return nil, fmt.Errorf("IPFS no está disponible: verifique que IPFS esté corriendo en %s:%s. Error: %w", s.ipfsService.GetHost(), s.ipfsService.GetPort(), err)
+		return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("IPFS no está disponible en %s:%s: %w", s.ipfsService.GetHost(), s.ipfsService.GetPort(), err))
 	}
-	fmt.Println("🟢 Service: IPFS está disponible, intentando almacenar...")
 
-	cid, err := s.ipfsService.AlmacenarJSON(ipfsCtx, transaccion.DatosEvento)
+	cid, nodos, err := s.ipfsService.AlmacenarJSON(ipfsCtx, transaccion.DatosEvento)
 	if err != nil {
 		// Verificar si es un timeout
 		if ipfsCtx.Err() == context.DeadlineExceeded {
-			fmt.Printf("🔴 Service: Timeout al almacenar en IPFS: %v\n", err)
-			return nil, fmt.Errorf("timeout al almacenar en IPFS (60s): verifique que IPFS esté corriendo y accesible en %s:%s", s.ipfsService.GetHost(), s.ipfsService.GetPort())
+			slog.ErrorContext(ctx, "timeout al almacenar en IPFS", "id_transaccion", transaccion.IDTransaction, "error", err)
+			return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("timeout al almacenar en IPFS (60s) en %s:%s: %w", s.ipfsService.GetHost(), s.ipfsService.GetPort(), ipfsCtx.Err()))
 		}
-		fmt.Printf("🔴 Service: Error almacenando en IPFS: %v\n", err)
-		return nil, fmt.Errorf("error almacenando en IPFS: %w", err)
+		slog.ErrorContext(ctx, "error almacenando en IPFS", "id_transaccion", transaccion.IDTransaction, "error", err)
+		return nil, ErrorDependencia(DependenciaIPFS, fmt.Errorf("error almacenando en IPFS: %w", err))
 	}
-	fmt.Printf("🟢 Service: Datos almacenados en IPFS con CID: %s\n", cid)
 	transaccion.IPFSCid = cid
-	fmt.Println("Transacción con IPFSCid:", transaccion.IPFSCid)
-	fmt.Println("Transacción con DatosEvento:", transaccion.DatosEvento)
-	fmt.Println("Transacción con ActorEmisor:", transaccion.ActorEmisor)
-	fmt.Println("Transacción con Estado:", transaccion.Estado)
-	fmt.Println("Transacción con CreatedAt:", transaccion.CreatedAt)
-	fmt.Println("Transacción con UpdatedAt:", transaccion.UpdatedAt)
-	fmt.Println("Transacción con TipoEvento:", transaccion.TipoEvento)
-	fmt.Println("Transacción con IDProducto:", transaccion.IDProducto)
-	fmt.Println("Transacción con FechaEvento:", transaccion.FechaEvento)
-	fmt.Println("Transacción con HashEvento:", transaccion.HashEvento)
-	fmt.Println("Transacción con DirectionBlockchain:", transaccion.DirectionBlockchain)
-	fmt.Println("Transacción con FirmaDigital:", transaccion.FirmaDigital)
-	fmt.Println("Transacción con IDTransaction:", transaccion.IDTransaction)
-
-	// 4. Calcular hash de integridad
-	hash := utils.CalcularHashTransaccion(transaccion)
-	fmt.Println("Hash de integridad calculado:", hash)
-	transaccion.HashEvento = hash
-
-	// 5. Registrar en DynamoDB primero
+	transaccion.NodosIPFS = nodos
+	slog.DebugContext(ctx, "datos del evento almacenados en IPFS", "id_transaccion", transaccion.IDTransaction, "cid", cid)
+
+	// 4-5. Encadenar con el último evento del producto, calcular hash y guardar en DynamoDB
 	// Crear contexto con timeout para DynamoDB (30 segundos)
 	dynamoCtx, dynamoCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer dynamoCancel()
 
-	fmt.Println("🟢 Service: Intentando guardar en DynamoDB...")
-	if err := s.dynamoDBService.GuardarTransaccion(dynamoCtx, transaccion); err != nil {
+	_, err = s.guardarEncadenada(dynamoCtx, transaccion)
+	if err != nil {
 		// Verificar si es un timeout
 		if dynamoCtx.Err() == context.DeadlineExceeded {
-			fmt.Printf("🔴 Service: Timeout al guardar en DynamoDB: %v\n", err)
-			return nil, fmt.Errorf("timeout al guardar en DynamoDB (30s): verifique la conexión con AWS DynamoDB")
+			slog.ErrorContext(ctx, "timeout al guardar en DynamoDB", "id_transaccion", transaccion.IDTransaction, "error", err)
+			return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("timeout al guardar en DynamoDB (30s): %w", dynamoCtx.Err()))
 		}
-		fmt.Printf("🔴 Service: Error guardando en DynamoDB: %v\n", err)
-		return nil, fmt.Errorf("error guardando en DynamoDB: %w", err)
+		slog.ErrorContext(ctx, "error guardando en DynamoDB", "id_transaccion", transaccion.IDTransaction, "error", err)
+		return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error guardando en DynamoDB: %w", err))
 	}
-	fmt.Println("🟢 Service: Transacción guardada exitosamente en DynamoDB")
+	slog.InfoContext(ctx, "transacción registrada",
+		"id_transaccion", transaccion.IDTransaction,
+		"tipo_evento", transaccion.TipoEvento,
+		"id_producto", transaccion.IDProducto,
+		"secuencia", transaccion.Secuencia,
+		"cid", transaccion.IPFSCid,
+	)
+
+	metricas.TransaccionRegistrada(transaccion.TipoEvento)
+	s.eventos.Publicar(models.NuevaNotificacion(models.NotificacionRegistrada, transaccion))
 
 	// 6. Enviar transacción a blockchain (solo hash + CID) - asíncrono
-	go s.registrarEnBlockchainAsync(transaccion.IDTransaction, hash, cid)
+	s.encolarAnclajes(1)
+	go s.registrarEnBlockchainAsync(logging.Desacoplar(ctx), *transaccion)
 
 	return transaccion, nil
 }
 
-// registrarEnBlockchainAsync registra la transacción en blockchain de forma asíncrona
-// Esta función se ejecuta en un goroutine separado para no bloquear la respuesta HTTP
-func (s *TransaccionService) registrarEnBlockchainAsync(idTransaccion, hash, cid string) {
-	ctxBg := context.Background()
+// maxIntentosCadena limita los reintentos cuando registros concurrentes compiten por la misma cadena
+const maxIntentosCadena = 5
+
+// guardarEncadenada enlaza la transacción con la cabeza de cadena de su producto, calcula el hash
+// de integridad y la guarda con escritura condicional. Si otro registro concurrente avanzó la
+// cadena primero, relee la cabeza y recalcula el hash para no bifurcar la cadena.
+func (s *TransaccionService) guardarEncadenada(ctx context.Context, transaccion *models.Transaccion) (string, error) {
+	for intento := 1; ; intento++ {
+		cabeza, err := s.dynamoDBService.ObtenerCabezaCadena(ctx, transaccion.IDProducto)
+		if err != nil {
+			return "", err
+		}
 
-	fmt.Printf("🟡 Blockchain: Iniciando registro asíncrono para transacción %s\n", idTransaccion)
-	fmt.Printf("🟡 Blockchain: Hash: %s, CID: %s\n", hash, cid)
+		// Un registro concurrente pudo reclamar el producto desde la autorización inicial
+		if err := s.autorizarPropiedad(ctx, transaccion.TipoEvento, transaccion.IDProducto, cabeza); err != nil {
+			return "", err
+		}
+
+		var secuenciaAnterior int64
+		transaccion.HashEventoAnterior = ""
+		if cabeza != nil {
+			secuenciaAnterior = cabeza.Secuencia
+			transaccion.HashEventoAnterior = cabeza.HashEvento
+		}
+		transaccion.Secuencia = secuenciaAnterior + 1
+
+		hash := utils.CalcularHashTransaccion(transaccion)
+		transaccion.HashEvento = hash
+		slog.DebugContext(ctx, "hash de integridad calculado", "id_transaccion", transaccion.IDTransaction, "hash", hash, "secuencia", transaccion.Secuencia)
+
+		err = s.dynamoDBService.GuardarTransaccionEncadenada(ctx, transaccion, cabeza)
+		if err == nil {
+			return hash, nil
+		}
+		if !errors.Is(err, ErrConflictoCadena) || intento >= maxIntentosCadena {
+			return "", err
+		}
+		slog.WarnContext(ctx, "cadena avanzada por otro registro, reintentando", "id_producto", transaccion.IDProducto, "intento", intento, "maximo", maxIntentosCadena)
+	}
+}
+
+// aplicarActorAutenticado reemplaza el actorEmisor enviado por el cliente por el actor del
+// principal autenticado. Sin principal (autenticación deshabilitada) se conserva el del cliente.
+func aplicarActorAutenticado(ctx context.Context, req *models.TransaccionRequest) {
+	principal := auth.PrincipalDesde(ctx)
+	if principal == nil {
+		return
+	}
+	if req.ActorEmisor != "" && req.ActorEmisor != principal.Actor {
+		slog.WarnContext(ctx, "actorEmisor ignorado; se usa el actor autenticado", "actor_emisor", req.ActorEmisor, "actor", principal.Actor)
+	}
+	req.ActorEmisor = principal.Actor
+}
+
+// autorizarEvento aplica la política al tipo de evento y a la propiedad del producto.
+// Sin principal (autenticación deshabilitada) o sin política configurada no aplica restricciones.
+func (s *TransaccionService) autorizarEvento(ctx context.Context, req *models.TransaccionRequest) error {
+	principal := auth.PrincipalDesde(ctx)
+	if s.politica == nil || principal == nil {
+		return nil
+	}
+
+	if err := s.politica.AutorizarEvento(principal, req.TipoEvento); err != nil {
+		return err
+	}
+	if s.politica.ReglaPropiedadAplicable(principal, req.TipoEvento) == nil {
+		return nil
+	}
+
+	cabeza, err := s.dynamoDBService.ObtenerCabezaCadena(ctx, req.IDProducto)
+	if err != nil {
+		return ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error verificando propiedad del producto: %w", err))
+	}
+	return s.autorizarPropiedad(ctx, req.TipoEvento, req.IDProducto, cabeza)
+}
+
+// autorizarPropiedad verifica que el principal sea propietario del producto si la política lo exige
+func (s *TransaccionService) autorizarPropiedad(ctx context.Context, tipoEvento, idProducto string, cabeza *models.CabezaCadena) error {
+	principal := auth.PrincipalDesde(ctx)
+	if s.politica == nil || principal == nil || cabeza == nil {
+		return nil
+	}
+	return s.politica.AutorizarPropiedad(principal, tipoEvento, idProducto, cabeza.Propietario)
+}
+
+// ErrSolicitudInvalida indica que el evento no supera la validación de estructura o de esquema
+var ErrSolicitudInvalida = NuevoErrorDominio(TipoValidacion, "solicitud-invalida", "validación fallida")
+
+// validarSolicitud valida la estructura de la solicitud y DatosEvento contra el esquema de su tipo de evento
+func validarSolicitud(req *models.TransaccionRequest) error {
+	if err := validation.ValidateStruct(req); err != nil {
+		return ErrSolicitudInvalida.Con(err)
+	}
+	if err := validation.ValidarDatosEvento(req.TipoEvento, req.DatosEvento); err != nil {
+		return ErrSolicitudInvalida.Con(err)
+	}
+	return nil
+}
+
+const (
+	// duracionReclamoAnclaje acota cuánto reserva un proceso el anclaje de un evento: si cae a mitad
+	// del envío, otro proceso puede reanclar el evento cuando vence la reserva
+	duracionReclamoAnclaje = 10 * time.Minute
+	// concurrenciaReanclaje limita los anclajes simultáneos al reencolar eventos pendientes
+	concurrenciaReanclaje = 4
+	// intentosConfirmacion y esperaConfirmacion acotan los reintentos de guardar un anclaje ya enviado
+	intentosConfirmacion = 3
+	esperaConfirmacion   = 500 * time.Millisecond
+)
+
+var (
+	// errAnclajePausado detiene el recorrido de ReanclarPendientes cuando el anclaje vuelve a pausarse
+	errAnclajePausado = errors.New("anclaje pausado por falta de fondos")
+	// errEnvioIncierto indica que no se pudo guardar o consultar el envío del anclaje: el evento no se
+	// marca como fallido porque la transacción pudo haber llegado a la red
+	errEnvioIncierto = errors.New("envío del anclaje sin registrar o sin consultar")
+)
+
+// AnclajesPendientes retorna cuántos eventos registrados esperan su anclaje en blockchain
+func (s *TransaccionService) AnclajesPendientes() int64 {
+	return s.anclajesPendientes.Load()
+}
+
+// encolarAnclajes cuenta n anclajes que van a lanzarse; cada uno termina con terminarAnclaje
+func (s *TransaccionService) encolarAnclajes(n int) {
+	s.anclajesPendientes.Add(int64(n))
+	metricas.AnclajesEncolados(n)
+}
+
+func (s *TransaccionService) terminarAnclaje() {
+	s.anclajesPendientes.Add(-1)
+	metricas.AnclajeTerminado()
+}
+
+// registrarEnBlockchainAsync registra la transacción en blockchain de forma asíncrona
+// Esta función se ejecuta en un goroutine separado para no bloquear la respuesta HTTP.
+// Recibe una copia de la transacción para no compartirla con el handler que la serializa, y el
+// contexto desacoplado de la petición para que sus logs lleven el mismo request ID.
+func (s *TransaccionService) registrarEnBlockchainAsync(ctx context.Context, transaccion models.Transaccion) {
+	defer s.terminarAnclaje()
+	idTransaccion, hash, cid := transaccion.IDTransaction, transaccion.HashEvento, transaccion.IPFSCid
+	logger := slog.With("id_transaccion", idTransaccion)
+
+	// El anclaje termina después de responder: abre su propia traza, enlazada al span de la petición
+	// que registró el evento, en lugar de alargar la traza de la petición
+	ctx, span := trazas.Iniciar(ctx, "blockchain.anclar",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(attribute.String("id_transaccion", idTransaccion), attribute.String("tipo_evento", transaccion.TipoEvento)),
+	)
+	defer span.End()
 
 	// Solo proceder si el servicio de blockchain está disponible
 	if s.blockchainService == nil {
-		fmt.Println("⚠️  Blockchain: Servicio de blockchain no disponible, saltando registro")
+		logger.WarnContext(ctx, "blockchain no disponible; la transacción queda sin anclar")
+		span.AddEvent("blockchain no disponible")
+		return
+	}
+
+	// Sin fondos el evento queda pendiente en lugar de fallar o de esperar en esta goroutine:
+	// ReanclarPendientes lo vuelve a encolar cuando se recarga la cuenta
+	if s.fondos.Pausado() {
+		logger.WarnContext(ctx, "anclaje pausado por falta de fondos; el evento queda pendiente")
+		span.AddEvent("anclaje pausado")
 		return
 	}
 
-	logicalHash, ethereumTxHash, err := s.blockchainService.RegistrarEnBlockchain(ctxBg, hash, cid)
+	// Otra réplica, o un reanclaje de pendientes, puede estar anclando el mismo evento
+	ahora := time.Now()
+	reclamada, err := s.dynamoDBService.ReclamarAnclaje(ctx, idTransaccion, ahora, ahora.Add(duracionReclamoAnclaje))
+	if err != nil {
+		logger.ErrorContext(ctx, "error reclamando el anclaje; el evento queda pendiente", "error", err)
+		trazas.RegistrarError(span, err)
+		return
+	}
+	if reclamada == nil {
+		logger.DebugContext(ctx, "el anclaje ya terminó o está en curso en otro proceso")
+		span.AddEvent("anclaje reclamado por otro proceso")
+		return
+	}
+	logger.DebugContext(ctx, "anclando transacción en blockchain", "hash", hash, "cid", cid)
+
+	// La reserva trae el envío que haya guardado un intento anterior
+	transaccion.AnclajeTxHash, transaccion.AnclajeHashLogico = reclamada.AnclajeTxHash, reclamada.AnclajeHashLogico
+	logicalHash, ethereumTxHash, err := s.anclar(ctx, &transaccion)
+	if s.fondos != nil && errors.Is(err, ErrFondosInsuficientes) {
+		s.fondos.SinFondos(ctx, err)
+		logger.WarnContext(ctx, "anclaje rechazado por falta de fondos; el evento queda pendiente", "error", err)
+		span.AddEvent("anclaje pausado")
+		if err := s.dynamoDBService.LiberarAnclaje(ctx, idTransaccion); err != nil {
+			logger.ErrorContext(ctx, "error liberando el anclaje en DynamoDB", "error", err)
+		}
+		return
+	}
+	if errors.Is(err, errEnvioIncierto) {
+		// No se sabe si la transacción llegó a la red: el evento sigue pendiente y, al vencer la reserva,
+		// el siguiente intento consulta el envío guardado antes de firmar otra
+		logger.ErrorContext(ctx, "error anclando transacción en blockchain; el evento queda pendiente", "error", err)
+		trazas.RegistrarError(span, err)
+		return
+	}
 	if err != nil {
 		// Log error y actualizar estado
-		fmt.Printf("🔴 Blockchain: Error registrando transacción %s en blockchain: %v\n", idTransaccion, err)
-		if updateErr := s.dynamoDBService.ActualizarEstado(ctxBg, idTransaccion, "fallido"); updateErr != nil {
-			fmt.Printf("🔴 Blockchain: Error actualizando estado en DynamoDB: %v\n", updateErr)
+		logger.ErrorContext(ctx, "error anclando transacción en blockchain", "error", err)
+		trazas.RegistrarError(span, err)
+		if updateErr := s.dynamoDBService.ActualizarEstado(ctx, idTransaccion, "fallido"); updateErr != nil {
+			logger.ErrorContext(ctx, "error actualizando estado en DynamoDB", "error", updateErr)
 		}
+		// El detalle del error puede incluir la URL del RPC: a los suscriptores solo llega el mensaje genérico,
+		// en el idioma por defecto porque la notificación llega a todos los suscriptores
+		transaccion.Estado = "fallido"
+		fallida := models.NuevaNotificacion(models.NotificacionFallida, &transaccion)
+		fallida.Mensaje = i18n.Traducir(i18n.IdiomaPorDefecto, "estado.fallido")
+		s.eventos.Publicar(fallida)
 		return
 	}
 
-	fmt.Printf("🟢 Blockchain: Transacción %s registrada en blockchain con hash lógico: %s, TxHash Ethereum: %s\n", idTransaccion, logicalHash, ethereumTxHash)
-
-	// Actualizar con hash lógico y hash de transacción de Ethereum (esto también actualiza el estado a "confirmado")
-	if err := s.dynamoDBService.ActualizarHashesBlockchain(ctxBg, idTransaccion, logicalHash, ethereumTxHash); err != nil {
-		fmt.Printf("🔴 Blockchain: Error actualizando hashes de blockchain en DynamoDB: %v\n", err)
+	logger.InfoContext(ctx, "transacción anclada en blockchain", "hash_logico", logicalHash, "tx_hash", ethereumTxHash)
+	transaccion.DirectionBlockchain = logicalHash
+	transaccion.EthereumTxHash = ethereumTxHash
+	s.eventos.Publicar(models.NuevaNotificacion(models.NotificacionAnclada, &transaccion))
+
+	// Actualizar con hash lógico y hash de transacción de Ethereum (esto también actualiza el estado a "confirmado").
+	// Si no se logra, la reserva se conserva hasta que vence y el siguiente intento solo confirma: el envío
+	// quedó guardado y no se vuelve a anclar.
+	if err := s.confirmarAnclaje(ctx, idTransaccion, logicalHash, ethereumTxHash); err != nil {
+		logger.ErrorContext(ctx, "error actualizando hashes de blockchain en DynamoDB", "error", err)
+		trazas.RegistrarError(span, err)
 		return
 	}
 
-	fmt.Printf("🟢 Blockchain: Hashes de blockchain actualizados en DynamoDB para transacción %s (estado: confirmado)\n", idTransaccion)
+	logger.DebugContext(ctx, "transacción confirmada en DynamoDB")
+	transaccion.Estado = "confirmado"
+	s.eventos.Publicar(models.NuevaNotificacion(models.NotificacionConfirmada, &transaccion))
+}
+
+// anclar envía el anclaje de la transacción. Cada transacción firmada se guarda antes de
+// difundirla; si un intento anterior ya guardó una, se consulta al nodo y solo se firma otra si la red
+// no la conoce.
+func (s *TransaccionService) anclar(ctx context.Context, transaccion *models.Transaccion) (string, string, error) {
+	if transaccion.AnclajeTxHash != "" {
+		estado, err := s.blockchainService.ConsultarEnvio(ctx, transaccion.AnclajeTxHash)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %w", errEnvioIncierto, err)
+		}
+		switch estado {
+		case EnvioPendiente, EnvioConfirmado:
+			return transaccion.AnclajeHashLogico, transaccion.AnclajeTxHash, nil
+		case EnvioRevertido:
+			return "", "", fmt.Errorf("transacción falló en blockchain")
+		}
+		slog.WarnContext(ctx, "el nodo no conoce la transacción de anclaje guardada; se envía otra", "id_transaccion", transaccion.IDTransaction, "tx_hash", transaccion.AnclajeTxHash)
+	}
+
+	return s.blockchainService.RegistrarEnBlockchainConAviso(ctx, transaccion.HashEvento, transaccion.IPFSCid, func(ctx context.Context, envio EnvioAnclaje) error {
+		if err := s.dynamoDBService.RegistrarEnvioAnclaje(ctx, transaccion.IDTransaction, envio.HashLogico, envio.TxHash); err != nil {
+			return fmt.Errorf("%w: %w", errEnvioIncierto, err)
+		}
+		return nil
+	})
+}
+
+// confirmarAnclaje guarda los hashes del anclaje y marca la transacción como confirmada, reintentando
+// solo la escritura en DynamoDB
+func (s *TransaccionService) confirmarAnclaje(ctx context.Context, idTransaccion, logicalHash, ethereumTxHash string) error {
+	var err error
+	for intento := 1; intento <= intentosConfirmacion; intento++ {
+		if err = s.dynamoDBService.ActualizarHashesBlockchain(ctx, idTransaccion, logicalHash, ethereumTxHash); err == nil {
+			return nil
+		}
+		if intento < intentosConfirmacion {
+			time.Sleep(time.Duration(intento) * esperaConfirmacion)
+		}
+	}
+	return err
+}
+
+// ReanclarPendientes encola el anclaje de las transacciones que siguen pendientes: las que quedaron en
+// pausa por falta de fondos o las que no terminaron antes de reiniciar el proceso. Ancla hasta
+// concurrenciaReanclaje eventos a la vez y se detiene si el anclaje vuelve a pausarse.
+func (s *TransaccionService) ReanclarPendientes(ctx context.Context) error {
+	if s.blockchainService == nil {
+		return nil
+	}
+
+	turnos := make(chan struct{}, concurrenciaReanclaje)
+	var encolados int
+	err := s.dynamoDBService.RecorrerTransaccionesPendientes(ctx, func(transaccion *models.Transaccion) error {
+		if s.fondos.Pausado() {
+			return errAnclajePausado
+		}
+		select {
+		case turnos <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.encolarAnclajes(1)
+		encolados++
+		go func(transaccion models.Transaccion) {
+			defer func() { <-turnos }()
+			s.registrarEnBlockchainAsync(ctx, transaccion)
+		}(*transaccion)
+		return nil
+	})
+	if encolados > 0 {
+		slog.Info("eventos pendientes encolados para anclaje", "eventos", encolados)
+	}
+	if errors.Is(err, errAnclajePausado) {
+		return nil
+	}
+	return err
 }
 
 // ObtenerTransaccion obtiene una transacción por ID
 func (s *TransaccionService) ObtenerTransaccion(ctx context.Context, idTransaccion string) (*models.Transaccion, error) {
 	transaccion, err := s.dynamoDBService.ObtenerTransaccion(ctx, idTransaccion)
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo transacción: %w", err)
+		return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo transacción: %w", err))
 	}
 	return transaccion, nil
 }
 
 // VerificarIntegridad verifica la integridad de una transacción contra blockchain e IPFS
 func (s *TransaccionService) VerificarIntegridad(ctx context.Context, idTransaccion string) (*models.VerificacionResponse, error) {
-	fmt.Printf("🔍 VERIFICAR: Iniciando verificación de integridad para ID: %s\n", idTransaccion)
-
 	// 1. Obtener datos de DynamoDB
 	transaccion, err := s.dynamoDBService.ObtenerTransaccion(ctx, idTransaccion)
 	if err != nil {
-		fmt.Printf("🔴 VERIFICAR: Error obteniendo transacción %s de DynamoDB: %v\n", idTransaccion, err)
-		return nil, fmt.Errorf("error obteniendo transacción: %w", err)
+		metricas.Verificacion(metricas.VerificacionError)
+		return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo transacción: %w", err))
 	}
-	fmt.Printf("🔍 VERIFICAR: Transacción obtenida de DynamoDB: ID=%s, CID=%s, HashEvento=%s, DatosEvento=%s, DirectionBlockchain=%s\n",
-		transaccion.IDTransaction, transaccion.IPFSCid, transaccion.HashEvento, transaccion.DatosEvento, transaccion.DirectionBlockchain)
+	logger := slog.With("id_transaccion", idTransaccion)
 
 	response := &models.VerificacionResponse{
 		IDTransaction: idTransaccion,
@@ -189,59 +517,89 @@ func (s *TransaccionService) VerificarIntegridad(ctx context.Context, idTransacc
 
 	// 2. Verificar que tenga hash de blockchain
 	if transaccion.DirectionBlockchain == "" {
-		response.Mensaje = "Transacción aún no confirmada en blockchain"
-		fmt.Printf("🔍 VERIFICAR: Transacción %s aún no confirmada en blockchain. DirectionBlockchain está vacío.\n", idTransaccion)
+		response.Mensaje = i18n.T(ctx, "verificacion.no-confirmada")
+		logger.DebugContext(ctx, "verificación: transacción aún no confirmada en blockchain")
+		metricas.Verificacion(metricas.VerificacionPendiente)
 		return response, nil
 	}
 
-	// 3. Calcular hash local
+	// 3. Calcular hash local con el algoritmo de la versión registrada
+	if !utils.HashVersionSoportada(transaccion.HashVersion) {
+		response.Mensaje = i18n.T(ctx, "verificacion.version-hash-no-soportada", transaccion.HashVersion)
+		metricas.Verificacion(metricas.VerificacionError)
+		return response, nil
+	}
 	hashLocal := utils.CalcularHashTransaccion(transaccion)
 	response.HashLocal = hashLocal
-	fmt.Printf("🔍 VERIFICAR: Hash local calculado: %s\n", hashLocal)
 
 	// 4. Verificar hash contra registro blockchain
-	fmt.Printf("🔍 VERIFICAR: Verificando hash %s contra blockchain con DirectionBlockchain: %s\n", hashLocal, transaccion.DirectionBlockchain)
 	verificadoBlockchain, err := s.blockchainService.VerificarEnBlockchain(ctx, transaccion.DirectionBlockchain, hashLocal)
 	if err != nil {
-		fmt.Printf("🔴 VERIFICAR: Error verificando en blockchain para %s: %v\n", idTransaccion, err)
-		response.Mensaje = fmt.Sprintf("Error verificando blockchain: %v", err)
+		// El detalle del error puede incluir la URL del RPC: queda en el log y la respuesta lleva el mensaje genérico
+		logger.ErrorContext(ctx, "verificación: error consultando blockchain", "error", err)
+		response.Mensaje = i18n.T(ctx, "verificacion.error-blockchain")
+		metricas.Verificacion(metricas.VerificacionError)
 		return response, nil
 	}
-	fmt.Printf("🔍 VERIFICAR: Resultado verificación blockchain: %t\n", verificadoBlockchain)
 
 	// 5. Recuperar datos de IPFS usando CID
-	fmt.Printf("🔍 VERIFICAR: Recuperando datos de IPFS con CID: %s\n", transaccion.IPFSCid)
 	datosIPFS, err := s.ipfsService.RecuperarJSON(ctx, transaccion.IPFSCid)
 	if err != nil {
-		fmt.Printf("🔴 VERIFICAR: Error recuperando de IPFS para %s (CID: %s): %v\n", idTransaccion, transaccion.IPFSCid, err)
-		response.Mensaje = fmt.Sprintf("Error recuperando de IPFS: %v", err)
+		logger.ErrorContext(ctx, "verificación: error recuperando de IPFS", "cid", transaccion.IPFSCid, "error", err)
+		response.Mensaje = i18n.T(ctx, "verificacion.error-ipfs")
+		metricas.Verificacion(metricas.VerificacionError)
 		return response, nil
 	}
-	fmt.Printf("🔍 VERIFICAR: Datos recuperados de IPFS (primeros 100 chars): %s...\n", datosIPFS[:min(100, len(datosIPFS))])
 
 	// 6. Verificar que los datos de IPFS coincidan
-	fmt.Printf("🔍 VERIFICAR: Comparando datos de IPFS con DatosEvento de DynamoDB.\n")
-	fmt.Printf("🔍 VERIFICAR: Datos IPFS: %s\n", datosIPFS)
-	fmt.Printf("🔍 VERIFICAR: Datos DynamoDB: %s\n", transaccion.DatosEvento)
-	datosIPFSVerificados := (datosIPFS == transaccion.DatosEvento)
+	datosIPFSVerificados := datosEquivalentes(transaccion.HashVersion, datosIPFS, transaccion.DatosEvento)
 	response.DatosIPFSVerificados = datosIPFSVerificados
 	response.HashBlockchain = transaccion.HashEvento
-	fmt.Printf("🔍 VERIFICAR: Coincidencia de datos IPFS y DynamoDB: %t\n", datosIPFSVerificados)
+	if !datosIPFSVerificados {
+		// Los payloads se enmascaran por clave (rutas de LOG_REDACT_PATHS); solo se emiten en debug
+		logger.DebugContext(ctx, "verificación: datos de IPFS y DynamoDB difieren",
+			"datos_ipfs", datosIPFS,
+			"datos_dynamodb", transaccion.DatosEvento,
+		)
+	}
+
+	// 7. Verificar el contenido de los adjuntos
+	response.AdjuntosVerificados = true
+	if len(transaccion.Adjuntos) > 0 {
+		if err := s.verificarAdjuntos(ctx, transaccion.Adjuntos); err != nil {
+			logger.WarnContext(ctx, "verificación: adjuntos no verificados", "error", err)
+			response.AdjuntosVerificados = false
+		}
+	}
 
-	// 7. Resultado final
-	response.Verificado = verificadoBlockchain && datosIPFSVerificados
-	fmt.Printf("🔍 VERIFICAR: Resultado final de verificación (Blockchain && IPFS): %t\n", response.Verificado)
+	// 8. Resultado final
+	response.Verificado = verificadoBlockchain && datosIPFSVerificados && response.AdjuntosVerificados
 
 	if response.Verificado {
-		response.Mensaje = "Transacción verificada exitosamente"
+		response.Mensaje = i18n.T(ctx, "verificacion.exitosa")
+		metricas.Verificacion(metricas.VerificacionVerificada)
 	} else {
-		response.Mensaje = "Transacción NO verificada: discrepancia detectada"
-		fmt.Printf("🔴 VERIFICAR: Discrepancia detectada para transacción %s. Blockchain: %t, IPFS: %t\n", idTransaccion, verificadoBlockchain, datosIPFSVerificados)
+		response.Mensaje = i18n.T(ctx, "verificacion.discrepancia")
+		metricas.Verificacion(metricas.VerificacionDiscrepancia)
+		logger.WarnContext(ctx, "verificación: discrepancia detectada",
+			"blockchain", verificadoBlockchain,
+			"ipfs", datosIPFSVerificados,
+			"adjuntos", response.AdjuntosVerificados,
+		)
 	}
 
 	return response, nil
 }
 
+// datosEquivalentes compara los datos de IPFS con los de DynamoDB. Desde HashVersionJCS la
+// comparación es semántica (ambos lados canonicalizados); los registros anteriores se comparan byte a byte.
+func datosEquivalentes(hashVersion int, datosIPFS, datosDynamo string) bool {
+	if hashVersion >= utils.HashVersionJCS {
+		return utils.DatosCanonicos(datosIPFS) == utils.DatosCanonicos(datosDynamo)
+	}
+	return datosIPFS == datosDynamo
+}
+
 // min es una función auxiliar para obtener el mínimo de dos enteros
 func min(a, b int) int {
 	if a < b {
@@ -252,12 +610,14 @@ func min(a, b int) int {
 
 // ListarTransacciones lista todas las transacciones
 func (s *TransaccionService) ListarTransacciones(ctx context.Context, limit int32) ([]*models.Transaccion, error) {
-	return s.dynamoDBService.ListarTransacciones(ctx, limit)
+	transacciones, err := s.dynamoDBService.ListarTransacciones(ctx, limit)
+	return transacciones, ErrorDependencia(DependenciaDynamoDB, err)
 }
 
 // ObtenerTransaccionesPorProducto obtiene todas las transacciones de un producto
 func (s *TransaccionService) ObtenerTransaccionesPorProducto(ctx context.Context, idProducto string) ([]*models.Transaccion, error) {
-	return s.dynamoDBService.ObtenerTransaccionesPorProducto(ctx, idProducto)
+	transacciones, err := s.dynamoDBService.ObtenerTransaccionesPorProducto(ctx, idProducto)
+	return transacciones, ErrorDependencia(DependenciaDynamoDB, err)
 }
 
 // ObtenerEstadoBlockchain obtiene el estado del registro en blockchain de una transacción
@@ -266,7 +626,7 @@ func (s *TransaccionService) ObtenerEstadoBlockchain(ctx context.Context, idTran
 	// Obtener la transacción desde DynamoDB
 	transaccion, err := s.dynamoDBService.ObtenerTransaccion(ctx, idTransaccion)
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo transacción: %w", err)
+		return nil, ErrorDependencia(DependenciaDynamoDB, fmt.Errorf("error obteniendo transacción: %w", err))
 	}
 
 	response := &models.EstadoBlockchainResponse{
@@ -282,20 +642,20 @@ func (s *TransaccionService) ObtenerEstadoBlockchain(ctx context.Context, idTran
 	switch transaccion.Estado {
 	case "confirmado":
 		if transaccion.DirectionBlockchain != "" {
-			response.Mensaje = fmt.Sprintf("Transacción registrada exitosamente en blockchain. Hash Lógico: %s, TxHash Ethereum: %s", transaccion.DirectionBlockchain, transaccion.EthereumTxHash)
+			response.Mensaje = i18n.T(ctx, "estado.confirmado", transaccion.DirectionBlockchain, transaccion.EthereumTxHash)
 		} else {
-			response.Mensaje = "Transacción confirmada pero sin hash de blockchain (puede estar en proceso)"
+			response.Mensaje = i18n.T(ctx, "estado.confirmado-sin-hash")
 		}
 	case "fallido":
-		response.Mensaje = "Error al registrar la transacción en blockchain"
+		response.Mensaje = i18n.T(ctx, "estado.fallido")
 	case "pendiente":
 		if transaccion.DirectionBlockchain != "" {
-			response.Mensaje = "Transacción registrada en blockchain pero aún en estado pendiente"
+			response.Mensaje = i18n.T(ctx, "estado.pendiente-registrado")
 		} else {
-			response.Mensaje = "Transacción pendiente de registro en blockchain"
+			response.Mensaje = i18n.T(ctx, "estado.pendiente")
 		}
 	default:
-		response.Mensaje = fmt.Sprintf("Estado desconocido: %s", transaccion.Estado)
+		response.Mensaje = i18n.T(ctx, "estado.desconocido", transaccion.Estado)
 	}
 
 	return response, nil
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/eventos"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+)
+
+// Cabeceras de las entregas de webhooks
+const (
+	CabeceraWebhookID        = "X-MediSupply-Webhook-Id"
+	CabeceraWebhookEntrega   = "X-MediSupply-Entrega"
+	CabeceraWebhookTimestamp = "X-MediSupply-Timestamp" // Segundos Unix del envío; forma parte del contenido firmado
+	CabeceraWebhookFirma     = "X-MediSupply-Firma"     // v1=<HMAC-SHA256 hexadecimal>
+)
+
+// loteEntregasVencidas es el máximo de entregas que se leen de la cola en cada sondeo
+const loteEntregasVencidas = 100
+
+// Iniciar escucha el bus de eventos y procesa la cola de entregas hasta que el contexto se cancele
+func (s *WebhookService) Iniciar(ctx context.Context, bus *eventos.Bus) {
+	go s.escuchar(ctx, bus)
+	s.procesarCola(ctx)
+}
+
+// escuchar encola una entrega por cada webhook que coincide con cada notificación del bus.
+// Si el bus corta la suscripción por lentitud, reanuda desde la última notificación procesada.
+func (s *WebhookService) escuchar(ctx context.Context, bus *eventos.Bus) {
+	ultimoID := ""
+	for ctx.Err() == nil {
+		suscripcion, err := bus.Suscribir(eventos.Filtro{}, ultimoID)
+		if errors.Is(err, eventos.ErrBusCerrado) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "webhooks: no se pudo suscribir al bus", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if suscripcion.Incompleta {
+			slog.WarnContext(ctx, "webhooks: el historial del bus no alcanzó; pudieron perderse notificaciones", "ultimo_id", ultimoID)
+		}
+		for _, notificacion := range suscripcion.Pendientes {
+			s.encolar(ctx, notificacion)
+			ultimoID = notificacion.ID
+		}
+
+		for abierta := true; abierta; {
+			select {
+			case <-ctx.Done():
+				suscripcion.Cancelar()
+				return
+			case notificacion, ok := <-suscripcion.Notificaciones():
+				if !ok {
+					if errors.Is(suscripcion.Err(), eventos.ErrBusCerrado) {
+						return
+					}
+					slog.WarnContext(ctx, "webhooks: suscripción al bus cerrada; reanudando", "error", suscripcion.Err())
+					abierta = false
+					continue
+				}
+				s.encolar(ctx, notificacion)
+				ultimoID = notificacion.ID
+			}
+		}
+	}
+}
+
+// encolar guarda una entrega pendiente para cada webhook activo que coincide con la notificación
+func (s *WebhookService) encolar(ctx context.Context, notificacion models.Notificacion) {
+	webhooks, err := s.webhooksActivos(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: error leyendo webhooks", "notificacion", notificacion.ID, "error", err)
+		return
+	}
+
+	encoladas := 0
+	for _, webhook := range webhooks {
+		if !webhook.Coincide(&notificacion) {
+			continue
+		}
+		if err := s.guardarNuevaEntrega(ctx, webhook, notificacion); err != nil {
+			slog.ErrorContext(ctx, "webhooks: error encolando entrega", "notificacion", notificacion.ID, "webhook", webhook.ID, "error", err)
+			continue
+		}
+		encoladas++
+	}
+	if encoladas > 0 {
+		s.avisarCola()
+	}
+
+	// El anclaje puede cambiar el estado verificado del producto: si alguien espera estado-oracle, recalcularlo
+	if notificacion.Tipo == models.NotificacionConfirmada || notificacion.Tipo == models.NotificacionFallida {
+		oracle := notificacion
+		oracle.Tipo = models.NotificacionEstadoOracle
+		for _, webhook := range webhooks {
+			if webhook.Coincide(&oracle) {
+				s.recalcularOracle(notificacion.IDProducto)
+				break
+			}
+		}
+	}
+}
+
+// guardarNuevaEntrega serializa el cuerpo que se firmará y lo guarda en la cola
+func (s *WebhookService) guardarNuevaEntrega(ctx context.Context, webhook *models.Webhook, notificacion models.Notificacion) error {
+	ahora := time.Now().UTC()
+	entrega := &models.EntregaWebhook{
+		ID:             uuid.New().String(),
+		WebhookID:      webhook.ID,
+		Tipo:           notificacion.Tipo,
+		IDNotificacion: notificacion.ID,
+		Estado:         models.EntregaPendiente,
+		Intentos:       []models.IntentoEntrega{},
+		ProximoIntento: ahora,
+		CreadaEn:       ahora,
+		ActualizadaEn:  ahora,
+		ExpiraEn:       ahora.Add(s.config.Retencion).Unix(),
+	}
+	payload, err := json.Marshal(models.EventoWebhook{
+		ID:        entrega.ID,
+		WebhookID: webhook.ID,
+		Tipo:      notificacion.Tipo,
+		Fecha:     ahora,
+		Datos:     notificacion,
+	})
+	if err != nil {
+		return fmt.Errorf("error serializando payload: %w", err)
+	}
+	entrega.Payload = payload
+
+	return s.almacen.GuardarEntrega(ctx, entrega)
+}
+
+// recalcularOracle recalcula en segundo plano el estado del producto; OracleService publica estado-oracle si cambió.
+// Un recálculo en curso absorbe los anclajes del mismo producto que llegan mientras tanto.
+func (s *WebhookService) recalcularOracle(idProducto string) {
+	if s.oracle == nil || idProducto == "" {
+		return
+	}
+	s.mu.Lock()
+	if s.recalculando[idProducto] {
+		s.mu.Unlock()
+		return
+	}
+	s.recalculando[idProducto] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.recalculando, idProducto)
+			s.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if _, err := s.oracle.ObtenerDatosVerificados(ctx, idProducto); err != nil {
+			slog.WarnContext(ctx, "webhooks: no se pudo recalcular el Oracle", "id_producto", idProducto, "error", err)
+		}
+	}()
+}
+
+// avisarCola despierta la cola sin esperar al siguiente sondeo
+func (s *WebhookService) avisarCola() {
+	select {
+	case s.despertar <- struct{}{}:
+	default:
+	}
+}
+
+// procesarCola envía las entregas vencidas en cada sondeo o aviso, con hasta config.Concurrencia envíos en paralelo
+func (s *WebhookService) procesarCola(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Sondeo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.despertar:
+		}
+		s.procesarVencidas(ctx)
+	}
+}
+
+// procesarVencidas reclama y envía las entregas vencidas; el bloqueo evita que dos réplicas envíen la misma
+func (s *WebhookService) procesarVencidas(ctx context.Context) {
+	vencidas, err := s.almacen.EntregasVencidas(ctx, time.Now(), loteEntregasVencidas)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: error leyendo la cola de entregas", "error", err)
+		return
+	}
+
+	cupos := make(chan struct{}, s.config.Concurrencia)
+	var wg sync.WaitGroup
+	for _, entrega := range vencidas {
+		// Se reclama al obtener cupo para que el bloqueo cubra el envío y no la espera
+		cupos <- struct{}{}
+		reclamo := time.Now()
+		reclamada, err := s.almacen.ReclamarEntrega(ctx, entrega.ID, reclamo, reclamo.Add(s.config.Timeout+30*time.Second))
+		if err != nil || !reclamada {
+			if err != nil {
+				slog.ErrorContext(ctx, "webhooks: error reclamando la entrega", "entrega", entrega.ID, "error", err)
+			}
+			<-cupos
+			continue
+		}
+
+		wg.Add(1)
+		go func(entrega *models.EntregaWebhook) {
+			defer func() { <-cupos; wg.Done() }()
+			s.entregar(ctx, entrega)
+		}(entrega)
+	}
+	wg.Wait()
+}
+
+// entregar hace un intento de envío y guarda el resultado: entregada, reprogramada con backoff o muerta
+func (s *WebhookService) entregar(ctx context.Context, entrega *models.EntregaWebhook) {
+	// Releer tras el reclamo: la copia de la cola pudo quedar vieja si otra réplica la procesó entretanto
+	actual, err := s.almacen.ObtenerEntrega(ctx, entrega.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: error leyendo la entrega", "entrega", entrega.ID, "error", err)
+		return
+	}
+	entrega = actual
+
+	webhook, err := s.almacen.ObtenerWebhook(ctx, entrega.WebhookID)
+	switch {
+	case errors.Is(err, ErrWebhookNoEncontrado):
+		s.finalizar(ctx, entrega, models.EntregaMuerta, "webhook eliminado")
+		return
+	case err != nil:
+		// Sin liberar el bloqueo: la entrega vuelve a la cola cuando expire
+		slog.ErrorContext(ctx, "webhooks: error leyendo el webhook", "webhook", entrega.WebhookID, "error", err)
+		return
+	case !webhook.Activo:
+		s.finalizar(ctx, entrega, models.EntregaMuerta, "webhook desactivado")
+		return
+	}
+
+	secreto, err := s.cifrado.Decrypt(webhook.SecretoCifrado)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: no se pudo descifrar el secreto", "webhook", webhook.ID, "error", err)
+		s.finalizar(ctx, entrega, models.EntregaMuerta, "secreto de firma ilegible")
+		return
+	}
+
+	inicio := time.Now()
+	statusCode, reintentarEn, err := s.enviar(ctx, webhook, entrega, secreto)
+	if ctx.Err() != nil {
+		// Apagado: el intento no cuenta y la entrega se retoma cuando expire el bloqueo
+		return
+	}
+
+	entrega.Intento++
+	intento := models.IntentoEntrega{
+		Numero:     len(entrega.Intentos) + 1,
+		Fecha:      inicio.UTC(),
+		StatusCode: statusCode,
+		DuracionMs: time.Since(inicio).Milliseconds(),
+	}
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("el receptor respondió %d", statusCode)
+	}
+	if err != nil {
+		intento.Error = err.Error()
+	}
+	entrega.Intentos = append(entrega.Intentos, intento)
+
+	switch {
+	case err == nil:
+		s.finalizar(ctx, entrega, models.EntregaEntregada, "")
+	case entrega.Intento >= s.config.MaximoIntentos:
+		slog.WarnContext(ctx, "webhooks: entrega muerta", "entrega", entrega.ID, "webhook", entrega.WebhookID, "intentos", entrega.Intento, "error", err)
+		s.finalizar(ctx, entrega, models.EntregaMuerta, "")
+	default:
+		espera := s.espera(entrega.Intento)
+		if reintentarEn > espera {
+			espera = reintentarEn
+		}
+		if espera > s.config.EsperaMaxima {
+			espera = s.config.EsperaMaxima
+		}
+		entrega.ProximoIntento = time.Now().Add(espera).UTC()
+		s.finalizar(ctx, entrega, models.EntregaPendiente, "")
+	}
+}
+
+// enviar hace el POST firmado; retorna el status, el Retry-After del receptor (si lo envió) y el error de red
+func (s *WebhookService) enviar(ctx context.Context, webhook *models.Webhook, entrega *models.EntregaWebhook, secreto string) (int, time.Duration, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(entrega.Payload))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MediSupply-Webhooks/1.0")
+	req.Header.Set(CabeceraWebhookID, webhook.ID)
+	req.Header.Set(CabeceraWebhookEntrega, entrega.ID)
+	req.Header.Set(CabeceraWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(CabeceraWebhookFirma, "v1="+FirmarWebhook(secreto, timestamp, entrega.Payload))
+
+	resp, err := s.cliente.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	var reintentarEn time.Duration
+	if segundos, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && segundos > 0 {
+		reintentarEn = time.Duration(segundos) * time.Second
+	}
+	return resp.StatusCode, reintentarEn, nil
+}
+
+// espera calcula el backoff exponencial tras el intento n (1, 2, ...), con ±20% de variación aleatoria
+// para que los reintentos de muchas entregas no lleguen juntos al receptor
+func (s *WebhookService) espera(n int) time.Duration {
+	espera := s.config.EsperaBase
+	for i := 1; i < n && espera < s.config.EsperaMaxima; i++ {
+		espera *= 2
+	}
+	if espera > s.config.EsperaMaxima {
+		espera = s.config.EsperaMaxima
+	}
+	return time.Duration(float64(espera) * (0.8 + 0.4*rand.Float64()))
+}
+
+// finalizar guarda el nuevo estado de la entrega y libera su bloqueo
+func (s *WebhookService) finalizar(ctx context.Context, entrega *models.EntregaWebhook, estado, motivo string) {
+	ahora := time.Now().UTC()
+	if motivo != "" {
+		entrega.Intentos = append(entrega.Intentos, models.IntentoEntrega{
+			Numero: len(entrega.Intentos) + 1,
+			Fecha:  ahora,
+			Error:  motivo,
+		})
+	}
+	entrega.Estado = estado
+	entrega.ActualizadaEn = ahora
+	entrega.BloqueoHasta = 0
+	if err := s.almacen.GuardarEntrega(ctx, entrega); err != nil {
+		slog.ErrorContext(ctx, "webhooks: error guardando la entrega", "entrega", entrega.ID, "error", err)
+	}
+}
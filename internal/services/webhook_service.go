@@ -0,0 +1,530 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/pkg/encryption"
+)
+
+// prefijoSecretoWebhook identifica los secretos de firma de webhooks: whsec_<aleatorio>
+const prefijoSecretoWebhook = "whsec_"
+
+var (
+	// ErrWebhookNoEncontrado indica que no existe el webhook o que pertenece a otro actor
+	ErrWebhookNoEncontrado = NuevoErrorDominio(TipoNoEncontrado, "webhook-no-encontrado", "webhook no encontrado")
+	// ErrEntregaNoEncontrada indica que no existe la entrega en el webhook solicitado
+	ErrEntregaNoEncontrada = NuevoErrorDominio(TipoNoEncontrado, "entrega-no-encontrada", "entrega no encontrada")
+	// ErrEntregaNoReenviable indica que la entrega todavía no agotó sus reintentos
+	ErrEntregaNoReenviable = NuevoErrorDominio(TipoConflicto, "entrega-no-reenviable", "solo se pueden reenviar entregas muertas")
+	// ErrURLWebhookInvalida indica una URL de webhook no permitida
+	ErrURLWebhookInvalida = NuevoErrorDominio(TipoValidacion, "url-webhook-invalida", "URL de webhook inválida")
+	// ErrDestinoNoPermitido indica que la URL resolvió a una dirección privada, de loopback o link-local
+	ErrDestinoNoPermitido = NuevoErrorDominio(TipoValidacion, "destino-webhook-no-permitido", "el destino del webhook no es una dirección pública")
+)
+
+// AlmacenWebhooks persiste los webhooks y su cola de entregas. DynamoDBService lo implementa sobre la tabla de control.
+type AlmacenWebhooks interface {
+	GuardarWebhook(ctx context.Context, webhook *models.Webhook) error
+	ObtenerWebhook(ctx context.Context, id string) (*models.Webhook, error)
+	ListarWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	EliminarWebhook(ctx context.Context, id string) error
+
+	GuardarEntrega(ctx context.Context, entrega *models.EntregaWebhook) error
+	ObtenerEntrega(ctx context.Context, id string) (*models.EntregaWebhook, error)
+	ListarEntregas(ctx context.Context, webhookID string) ([]*models.EntregaWebhook, error)
+	// EntregasVencidas retorna hasta limite entregas pendientes cuyo próximo intento ya llegó y que nadie tiene bloqueadas
+	EntregasVencidas(ctx context.Context, ahora time.Time, limite int) ([]*models.EntregaWebhook, error)
+	// ReclamarEntrega bloquea la entrega hasta el instante indicado si sigue pendiente y libre; retorna false si otra réplica la tomó
+	ReclamarEntrega(ctx context.Context, id string, ahora, hasta time.Time) (bool, error)
+}
+
+// RecalculadorOracle recalcula el estado verificado de un producto; OracleService lo implementa
+type RecalculadorOracle interface {
+	ObtenerDatosVerificados(ctx context.Context, idProducto string) (*models.OracleDataResponse, error)
+}
+
+// WebhookConfig agrupa los parámetros de entrega de webhooks
+type WebhookConfig struct {
+	MaximoIntentos   int           // Intentos antes de pasar la entrega a muerta
+	EsperaBase       time.Duration // Espera tras el primer fallo; se duplica en cada intento
+	EsperaMaxima     time.Duration // Tope de la espera entre intentos
+	Timeout          time.Duration // Tiempo máximo de cada POST al receptor
+	Concurrencia     int           // Entregas enviadas en paralelo
+	Retencion        time.Duration // Tiempo que se conserva el log de una entrega
+	Sondeo           time.Duration // Intervalo de consulta de la cola
+	PermitirInseguro bool          // Permite http y destinos privados (solo desarrollo y tests)
+}
+
+// WebhookService administra las suscripciones de webhooks y entrega las notificaciones firmadas
+type WebhookService struct {
+	almacen AlmacenWebhooks
+	cifrado *encryption.AESEncryption
+	config  WebhookConfig
+	cliente *http.Client
+	oracle  RecalculadorOracle
+
+	mu           sync.Mutex
+	cache        []*models.Webhook // Webhooks activos; se invalida en cada cambio
+	cacheHasta   time.Time
+	recalculando map[string]bool // Productos con un recálculo del Oracle en curso
+
+	despertar chan struct{} // Avisa a la cola que hay entregas nuevas
+}
+
+// vigenciaCacheWebhooks es cada cuánto se relee la lista de webhooks (cambios hechos en otras réplicas)
+const vigenciaCacheWebhooks = 30 * time.Second
+
+// NewWebhookService crea una nueva instancia de WebhookService; cifrado protege los secretos de firma en el almacén
+func NewWebhookService(almacen AlmacenWebhooks, cifrado *encryption.AESEncryption, config WebhookConfig) *WebhookService {
+	if config.MaximoIntentos <= 0 {
+		config.MaximoIntentos = 8
+	}
+	if config.EsperaBase <= 0 {
+		config.EsperaBase = 10 * time.Second
+	}
+	if config.EsperaMaxima <= 0 {
+		config.EsperaMaxima = time.Hour
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Concurrencia <= 0 {
+		config.Concurrencia = 4
+	}
+	if config.Retencion <= 0 {
+		config.Retencion = 7 * 24 * time.Hour
+	}
+	if config.Sondeo <= 0 {
+		config.Sondeo = 2 * time.Second
+	}
+
+	s := &WebhookService{
+		almacen:      almacen,
+		cifrado:      cifrado,
+		config:       config,
+		recalculando: make(map[string]bool),
+		despertar:    make(chan struct{}, 1),
+	}
+	s.cliente = s.nuevoClienteHTTP()
+	return s
+}
+
+// ConfigurarOracle activa el recálculo del Oracle tras cada anclaje de un producto con webhooks de estado-oracle
+func (s *WebhookService) ConfigurarOracle(oracle RecalculadorOracle) {
+	s.oracle = oracle
+}
+
+// nuevoClienteHTTP crea el cliente de entregas. Salvo PermitirInseguro, el dialer rechaza las direcciones
+// no públicas después de resolver el DNS, así una URL pública no puede apuntar a la red interna.
+func (s *WebhookService) nuevoClienteHTTP() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !s.config.PermitirInseguro {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !esIPPublica(ip) {
+				return ErrDestinoNoPermitido
+			}
+			return nil
+		}
+	}
+	transporte := http.DefaultTransport.(*http.Transport).Clone()
+	transporte.Proxy = nil
+	transporte.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transporte,
+		Timeout:   s.config.Timeout,
+		// Una redirección cuenta como fallo: seguirla permitiría saltar la validación del destino
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// esIPPublica indica si la dirección es enrutable en Internet
+func esIPPublica(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	// 100.64.0.0/10: NAT de operador, usado también por redes internas de nubes
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
+
+// validarURL comprueba el esquema y el host de la URL del receptor
+func (s *WebhookService) validarURL(destino string) error {
+	u, err := url.Parse(destino)
+	if err != nil {
+		return ErrURLWebhookInvalida.Con(err)
+	}
+	switch {
+	case u.Scheme != "https" && !(u.Scheme == "http" && s.config.PermitirInseguro):
+		return ErrURLWebhookInvalida.Con(i18n.NuevoError("webhook.requiere-https"))
+	case u.Hostname() == "":
+		return ErrURLWebhookInvalida.Con(i18n.NuevoError("webhook.falta-host"))
+	case u.User != nil:
+		return ErrURLWebhookInvalida.Con(i18n.NuevoError("webhook.credenciales-en-url"))
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !s.config.PermitirInseguro && !esIPPublica(ip) {
+		return ErrURLWebhookInvalida.Con(ErrDestinoNoPermitido)
+	}
+	return nil
+}
+
+// FirmarWebhook calcula la firma v1 de un cuerpo: HMAC-SHA256 en hexadecimal de "<timestamp>.<cuerpo>"
+// con el secreto completo (incluido el prefijo whsec_) como clave
+func FirmarWebhook(secreto string, timestamp int64, cuerpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(secreto))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(cuerpo)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CrearWebhook registra un webhook para el actor propietario. El secreto de firma solo se retorna aquí.
+func (s *WebhookService) CrearWebhook(ctx context.Context, propietario string, req *models.WebhookRequest) (string, *models.Webhook, error) {
+	if err := s.validarURL(req.URL); err != nil {
+		return "", nil, err
+	}
+	aleatorio := make([]byte, 32)
+	if _, err := rand.Read(aleatorio); err != nil {
+		return "", nil, fmt.Errorf("error generando secreto: %w", err)
+	}
+	secreto := prefijoSecretoWebhook + base64.RawURLEncoding.EncodeToString(aleatorio)
+	cifrado, err := s.cifrado.Encrypt(secreto)
+	if err != nil {
+		return "", nil, fmt.Errorf("error cifrando secreto: %w", err)
+	}
+
+	ahora := time.Now().UTC()
+	webhook := &models.Webhook{
+		ID:             uuid.New().String(),
+		Propietario:    propietario,
+		SecretoCifrado: cifrado,
+		CreadoEn:       ahora,
+	}
+	aplicarWebhookRequest(webhook, req, ahora)
+
+	if err := s.almacen.GuardarWebhook(ctx, webhook); err != nil {
+		return "", nil, fmt.Errorf("error guardando webhook: %w", err)
+	}
+	s.invalidarCache()
+
+	return secreto, webhook, nil
+}
+
+// aplicarWebhookRequest copia los campos editables de la petición al webhook
+func aplicarWebhookRequest(webhook *models.Webhook, req *models.WebhookRequest, ahora time.Time) {
+	webhook.URL = req.URL
+	webhook.Descripcion = req.Descripcion
+	webhook.Productos = noNulo(req.Productos)
+	webhook.Actores = noNulo(req.Actores)
+	webhook.TiposEvento = noNulo(req.TiposEvento)
+	webhook.Estados = noNulo(req.Estados)
+	webhook.Activo = req.Activo == nil || *req.Activo
+	webhook.ActualizadoEn = ahora
+}
+
+func noNulo(valores []string) []string {
+	if valores == nil {
+		return []string{}
+	}
+	return valores
+}
+
+// ObtenerWebhook obtiene un webhook; propietario vacío permite ver los de cualquier actor (admin)
+func (s *WebhookService) ObtenerWebhook(ctx context.Context, propietario, id string) (*models.Webhook, error) {
+	webhook, err := s.almacen.ObtenerWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if propietario != "" && webhook.Propietario != propietario {
+		return nil, ErrWebhookNoEncontrado
+	}
+	return webhook, nil
+}
+
+// ListarWebhooks lista los webhooks del propietario (todos si está vacío), del más reciente al más antiguo
+func (s *WebhookService) ListarWebhooks(ctx context.Context, propietario string) ([]*models.Webhook, error) {
+	todos, err := s.almacen.ListarWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listando webhooks: %w", err)
+	}
+
+	webhooks := make([]*models.Webhook, 0, len(todos))
+	for _, webhook := range todos {
+		if propietario == "" || webhook.Propietario == propietario {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreadoEn.After(webhooks[j].CreadoEn)
+	})
+
+	return webhooks, nil
+}
+
+// ActualizarWebhook reemplaza la URL, los filtros y el estado de un webhook; el secreto se conserva
+func (s *WebhookService) ActualizarWebhook(ctx context.Context, propietario, id string, req *models.WebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.ObtenerWebhook(ctx, propietario, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validarURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	aplicarWebhookRequest(webhook, req, time.Now().UTC())
+	if err := s.almacen.GuardarWebhook(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("error guardando webhook: %w", err)
+	}
+	s.invalidarCache()
+
+	return webhook, nil
+}
+
+// EliminarWebhook elimina un webhook; sus entregas pendientes pasan a muertas en el siguiente intento
+func (s *WebhookService) EliminarWebhook(ctx context.Context, propietario, id string) error {
+	if _, err := s.ObtenerWebhook(ctx, propietario, id); err != nil {
+		return err
+	}
+	if err := s.almacen.EliminarWebhook(ctx, id); err != nil {
+		return fmt.Errorf("error eliminando webhook: %w", err)
+	}
+	s.invalidarCache()
+	return nil
+}
+
+// ListarEntregas retorna el log de entregas de un webhook, de la más reciente a la más antigua; estado vacío = todas
+func (s *WebhookService) ListarEntregas(ctx context.Context, propietario, webhookID, estado string) ([]*models.EntregaWebhook, error) {
+	if _, err := s.ObtenerWebhook(ctx, propietario, webhookID); err != nil {
+		return nil, err
+	}
+	todas, err := s.almacen.ListarEntregas(ctx, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("error listando entregas: %w", err)
+	}
+
+	entregas := make([]*models.EntregaWebhook, 0, len(todas))
+	for _, entrega := range todas {
+		if estado == "" || entrega.Estado == estado {
+			entregas = append(entregas, entrega)
+		}
+	}
+	sort.Slice(entregas, func(i, j int) bool {
+		return entregas[i].CreadaEn.After(entregas[j].CreadaEn)
+	})
+
+	return entregas, nil
+}
+
+// ReenviarEntrega devuelve a la cola una entrega muerta con un nuevo ciclo de reintentos
+func (s *WebhookService) ReenviarEntrega(ctx context.Context, propietario, webhookID, entregaID string) (*models.EntregaWebhook, error) {
+	if _, err := s.ObtenerWebhook(ctx, propietario, webhookID); err != nil {
+		return nil, err
+	}
+	entrega, err := s.almacen.ObtenerEntrega(ctx, entregaID)
+	if err != nil {
+		return nil, err
+	}
+	if entrega.WebhookID != webhookID {
+		return nil, ErrEntregaNoEncontrada
+	}
+	if entrega.Estado != models.EntregaMuerta {
+		return nil, ErrEntregaNoReenviable
+	}
+
+	ahora := time.Now().UTC()
+	entrega.Estado = models.EntregaPendiente
+	entrega.Intento = 0
+	entrega.ProximoIntento = ahora
+	entrega.ActualizadaEn = ahora
+	entrega.BloqueoHasta = 0
+	entrega.ExpiraEn = ahora.Add(s.config.Retencion).Unix()
+	if err := s.almacen.GuardarEntrega(ctx, entrega); err != nil {
+		return nil, fmt.Errorf("error guardando entrega: %w", err)
+	}
+	s.avisarCola()
+
+	return entrega, nil
+}
+
+// invalidarCache fuerza a releer los webhooks en la próxima notificación
+func (s *WebhookService) invalidarCache() {
+	s.mu.Lock()
+	s.cache = nil
+	s.cacheHasta = time.Time{}
+	s.mu.Unlock()
+}
+
+// webhooksActivos retorna los webhooks activos, leídos como máximo cada vigenciaCacheWebhooks
+func (s *WebhookService) webhooksActivos(ctx context.Context) ([]*models.Webhook, error) {
+	s.mu.Lock()
+	if time.Now().Before(s.cacheHasta) {
+		cache := s.cache
+		s.mu.Unlock()
+		return cache, nil
+	}
+	s.mu.Unlock()
+
+	todos, err := s.almacen.ListarWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	activos := make([]*models.Webhook, 0, len(todos))
+	for _, webhook := range todos {
+		if webhook.Activo {
+			activos = append(activos, webhook)
+		}
+	}
+
+	s.mu.Lock()
+	s.cache = activos
+	s.cacheHasta = time.Now().Add(vigenciaCacheWebhooks)
+	s.mu.Unlock()
+	return activos, nil
+}
+
+// AlmacenWebhooksMemoria es un AlmacenWebhooks en memoria, útil para desarrollo y tests
+type AlmacenWebhooksMemoria struct {
+	mu       sync.Mutex
+	webhooks map[string]models.Webhook
+	entregas map[string]models.EntregaWebhook
+}
+
+// NewAlmacenWebhooksMemoria crea un almacén de webhooks en memoria
+func NewAlmacenWebhooksMemoria() *AlmacenWebhooksMemoria {
+	return &AlmacenWebhooksMemoria{
+		webhooks: make(map[string]models.Webhook),
+		entregas: make(map[string]models.EntregaWebhook),
+	}
+}
+
+// GuardarWebhook guarda o reemplaza un webhook
+func (a *AlmacenWebhooksMemoria) GuardarWebhook(ctx context.Context, webhook *models.Webhook) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+// ObtenerWebhook obtiene un webhook por ID
+func (a *AlmacenWebhooksMemoria) ObtenerWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	webhook, ok := a.webhooks[id]
+	if !ok {
+		return nil, ErrWebhookNoEncontrado
+	}
+	return &webhook, nil
+}
+
+// ListarWebhooks lista todos los webhooks
+func (a *AlmacenWebhooksMemoria) ListarWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	webhooks := make([]*models.Webhook, 0, len(a.webhooks))
+	for _, webhook := range a.webhooks {
+		webhook := webhook
+		webhooks = append(webhooks, &webhook)
+	}
+	return webhooks, nil
+}
+
+// EliminarWebhook elimina un webhook
+func (a *AlmacenWebhooksMemoria) EliminarWebhook(ctx context.Context, id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.webhooks, id)
+	return nil
+}
+
+// GuardarEntrega guarda o reemplaza una entrega
+func (a *AlmacenWebhooksMemoria) GuardarEntrega(ctx context.Context, entrega *models.EntregaWebhook) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	copia := *entrega
+	copia.Intentos = append([]models.IntentoEntrega(nil), entrega.Intentos...)
+	a.entregas[entrega.ID] = copia
+	return nil
+}
+
+// ObtenerEntrega obtiene una entrega por ID
+func (a *AlmacenWebhooksMemoria) ObtenerEntrega(ctx context.Context, id string) (*models.EntregaWebhook, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entrega, ok := a.entregas[id]
+	if !ok {
+		return nil, ErrEntregaNoEncontrada
+	}
+	entrega.Intentos = append([]models.IntentoEntrega(nil), entrega.Intentos...)
+	return &entrega, nil
+}
+
+// ListarEntregas lista las entregas de un webhook
+func (a *AlmacenWebhooksMemoria) ListarEntregas(ctx context.Context, webhookID string) ([]*models.EntregaWebhook, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entregas := make([]*models.EntregaWebhook, 0)
+	for _, entrega := range a.entregas {
+		if entrega.WebhookID == webhookID {
+			entrega := entrega
+			entrega.Intentos = append([]models.IntentoEntrega(nil), entrega.Intentos...)
+			entregas = append(entregas, &entrega)
+		}
+	}
+	return entregas, nil
+}
+
+// EntregasVencidas retorna las entregas pendientes cuyo próximo intento ya llegó
+func (a *AlmacenWebhooksMemoria) EntregasVencidas(ctx context.Context, ahora time.Time, limite int) ([]*models.EntregaWebhook, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entregas := make([]*models.EntregaWebhook, 0)
+	for _, entrega := range a.entregas {
+		if len(entregas) >= limite {
+			break
+		}
+		if entrega.Estado == models.EntregaPendiente && !entrega.ProximoIntento.After(ahora) && entrega.BloqueoHasta < ahora.UnixMilli() {
+			entrega := entrega
+			entrega.Intentos = append([]models.IntentoEntrega(nil), entrega.Intentos...)
+			entregas = append(entregas, &entrega)
+		}
+	}
+	return entregas, nil
+}
+
+// ReclamarEntrega bloquea la entrega de forma atómica
+func (a *AlmacenWebhooksMemoria) ReclamarEntrega(ctx context.Context, id string, ahora, hasta time.Time) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entrega, ok := a.entregas[id]
+	if !ok || entrega.Estado != models.EntregaPendiente || entrega.BloqueoHasta >= ahora.UnixMilli() {
+		return false, nil
+	}
+	entrega.BloqueoHasta = hasta.UnixMilli()
+	a.entregas[id] = entrega
+	return true, nil
+}
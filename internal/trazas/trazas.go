@@ -0,0 +1,118 @@
+// Package trazas configura el tracing distribuido con OpenTelemetry: el proveedor de spans, su
+// exportación (OTLP o stdout) y la propagación W3C Trace Context. Con el exportador none los spans no
+// se registran, pero el contexto recibido de los clientes se sigue propagando.
+package trazas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+)
+
+// nombreInstrumentacion identifica a esta aplicación como origen de los spans
+const nombreInstrumentacion = "github.com/edinfamous/blockchain-medisupply"
+
+// Exportadores soportados
+const (
+	ExportadorNinguno = "none"
+	ExportadorStdout  = "stdout"
+	ExportadorOTLP    = "otlp"
+)
+
+// Config define cómo se muestrean y exportan los spans
+type Config struct {
+	Exportador     string    // none, stdout u otlp
+	EndpointOTLP   string    // URL del colector OTLP/HTTP; vacío = OTEL_EXPORTER_OTLP_ENDPOINT o localhost:4318
+	NombreServicio string    // Atributo service.name de los spans
+	Muestreo       float64   // Fracción de trazas nuevas que se registran (0 a 1); las recibidas respetan al padre
+	Salida         io.Writer // Destino del exportador stdout; nil = os.Stdout
+}
+
+func init() {
+	// La propagación no depende del exportador: el traceparent recibido se respeta aunque no se exporte
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Configurar instala el proveedor de spans global según cfg y retorna la función que exporta los spans
+// pendientes y lo detiene al apagar la aplicación
+func Configurar(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var exportador sdktrace.SpanExporter
+	switch cfg.Exportador {
+	case ExportadorNinguno, "":
+		return func(context.Context) error { return nil }, nil
+	case ExportadorStdout:
+		salida := cfg.Salida
+		if salida == nil {
+			salida = os.Stdout
+		}
+		var err error
+		if exportador, err = stdouttrace.New(stdouttrace.WithWriter(salida)); err != nil {
+			return nil, fmt.Errorf("error creando exportador stdout: %w", err)
+		}
+	case ExportadorOTLP:
+		var opciones []otlptracehttp.Option
+		if cfg.EndpointOTLP != "" {
+			opciones = append(opciones, otlptracehttp.WithEndpointURL(cfg.EndpointOTLP))
+		}
+		var err error
+		if exportador, err = otlptracehttp.New(ctx, opciones...); err != nil {
+			return nil, fmt.Errorf("error creando exportador OTLP: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("exportador de trazas '%s' inválido; use none, stdout u otlp", cfg.Exportador)
+	}
+
+	recurso, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.NombreServicio)))
+	if err != nil {
+		return nil, fmt.Errorf("error creando el recurso de trazas: %w", err)
+	}
+	proveedor := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exportador),
+		sdktrace.WithResource(recurso),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Muestreo))),
+	)
+	otel.SetTracerProvider(proveedor)
+	return proveedor.Shutdown, nil
+}
+
+// Tracer retorna el tracer de la aplicación sobre el proveedor global vigente
+func Tracer() trace.Tracer {
+	return otel.Tracer(nombreInstrumentacion)
+}
+
+// Iniciar abre un span hijo del span de ctx
+func Iniciar(ctx context.Context, nombre string, opciones ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, nombre, opciones...)
+}
+
+// RegistrarError marca el span como fallido. El mensaje se enmascara igual que en los logs, porque los
+// spans salen del proceso hacia el colector.
+func RegistrarError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	mensaje := redaccion.Texto(err.Error())
+	span.RecordError(errors.New(mensaje))
+	span.SetStatus(codes.Error, mensaje)
+}
+
+// Terminar cierra el span registrando el error retornado, si lo hubo; se usa con defer y el error
+// nombrado de la función: defer trazas.Terminar(span, &err)
+func Terminar(span trace.Span, err *error) {
+	RegistrarError(span, *err)
+	span.End()
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// weiPorEther es 10^18
+var weiPorEther = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// ParsearEther convierte una cantidad en ETH con hasta 18 decimales ("0.05") a wei, sin pérdida de precisión
+func ParsearEther(cantidad string) (*big.Int, error) {
+	cantidad = strings.TrimSpace(cantidad)
+	entero, decimales, _ := strings.Cut(cantidad, ".")
+	if entero == "" && decimales == "" || len(decimales) > 18 || strings.HasPrefix(entero, "-") || strings.HasPrefix(entero, "+") {
+		return nil, fmt.Errorf("cantidad de ETH inválida: %q", cantidad)
+	}
+	wei, ok := new(big.Int).SetString(entero+decimales+strings.Repeat("0", 18-len(decimales)), 10)
+	if !ok {
+		return nil, fmt.Errorf("cantidad de ETH inválida: %q", cantidad)
+	}
+	return wei, nil
+}
+
+// FormatearEther expresa una cantidad en wei como ETH con los decimales necesarios ("0.05", "1.5", "0")
+func FormatearEther(wei *big.Int) string {
+	signo := ""
+	if wei.Sign() < 0 {
+		signo = "-"
+	}
+	entero, resto := new(big.Int).QuoRem(new(big.Int).Abs(wei), weiPorEther, new(big.Int))
+	if resto.Sign() == 0 {
+		return signo + entero.String()
+	}
+	decimales := strings.TrimRight(fmt.Sprintf("%018s", resto.String()), "0")
+	return signo + entero.String() + "." + decimales
+}
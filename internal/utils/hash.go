@@ -2,15 +2,72 @@ package utils
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"strconv"
 	"time"
 
 	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/pkg/canonical"
 )
 
-// CalcularHashTransaccion calcula el hash SHA-256 de una transacción
+// Versiones del algoritmo de hash de integridad. Cada transacción guarda la versión con la
+// que se calculó su hash para que los registros anteriores sigan verificando.
+const (
+	// HashVersionLegacy concatena los campos con DatosEvento tal como se recibió
+	HashVersionLegacy = 1
+	// HashVersionJCS concatena los campos con DatosEvento canonicalizado (RFC 8785)
+	HashVersionJCS = 2
+	// HashVersionEstructurado codifica cada campo con prefijo de longitud bajo una etiqueta de dominio
+	// y cubre también ActorEmisor, IPFSCid y todos los metadatos de los adjuntos
+	HashVersionEstructurado = 3
+	// HashVersionCadena extiende HashVersionEstructurado con la secuencia y el hash del evento
+	// anterior del mismo producto, de modo que cada evento ancla también a su predecesor
+	HashVersionCadena = 4
+
+	// HashVersionActual es la versión usada para las transacciones nuevas
+	HashVersionActual = HashVersionCadena
+)
+
+// Etiquetas de dominio que separan los hashes de transacciones de cualquier otro uso de SHA-256
+const (
+	etiquetaDominioTransaccion       = "medisupply/transaccion/v3"
+	etiquetaDominioTransaccionCadena = "medisupply/transaccion/v4"
+)
+
+// HashVersionSoportada indica si existe un algoritmo para la versión (0 se trata como legacy)
+func HashVersionSoportada(version int) bool {
+	return version >= 0 && version <= HashVersionCadena
+}
+
+// CalcularHashTransaccion calcula el hash SHA-256 de una transacción con el algoritmo de su HashVersion.
+// Las transacciones sin versión (registradas antes del versionado) usan HashVersionLegacy.
 func CalcularHashTransaccion(transaccion *models.Transaccion) string {
+	switch transaccion.HashVersion {
+	case HashVersionCadena:
+		return calcularHashEstructurado(transaccion, etiquetaDominioTransaccionCadena)
+	case HashVersionEstructurado:
+		return calcularHashEstructurado(transaccion, etiquetaDominioTransaccion)
+	case HashVersionJCS:
+		return calcularHashConcatenado(transaccion, DatosCanonicos(transaccion.DatosEvento))
+	default:
+		return calcularHashConcatenado(transaccion, transaccion.DatosEvento)
+	}
+}
+
+// DatosCanonicos retorna la forma canónica JCS de los datos, o los datos originales si no son JSON válido
+func DatosCanonicos(datos string) string {
+	canonicos, err := canonical.CanonicalizarString(datos)
+	if err != nil {
+		return datos
+	}
+	return canonicos
+}
+
+// calcularHashConcatenado concatena los campos de la transacción y calcula su SHA-256
+func calcularHashConcatenado(transaccion *models.Transaccion, datosEvento string) string {
 	// Usar un formato de fecha explícito y consistente (RFC3339Nano) para evitar discrepancias
 	// al guardar y recuperar de la base de datos.
 	data := fmt.Sprintf("%s%s%s%s%s",
@@ -18,12 +75,56 @@ func CalcularHashTransaccion(transaccion *models.Transaccion) string {
 		transaccion.TipoEvento,
 		transaccion.IDProducto,
 		transaccion.FechaEvento.Format(time.RFC3339Nano),
-		transaccion.DatosEvento,
+		datosEvento,
 	)
+	// Los adjuntos se incluyen solo si existen, para que los registros previos sigan verificando
+	for _, adjunto := range transaccion.Adjuntos {
+		data += adjunto.CID + adjunto.HashSHA256
+	}
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
 
+// calcularHashEstructurado calcula el hash de HashVersionEstructurado y HashVersionCadena. Cada campo
+// se escribe como longitud (uint64 big-endian) seguida de sus bytes, así que mover caracteres entre
+// campos contiguos produce una entrada distinta y no puede colisionar.
+func calcularHashEstructurado(transaccion *models.Transaccion, etiqueta string) string {
+	h := sha256.New()
+	escribirCampo(h, etiqueta)
+	escribirCampo(h, transaccion.IDTransaction)
+	escribirCampo(h, transaccion.TipoEvento)
+	escribirCampo(h, transaccion.IDProducto)
+	escribirCampo(h, transaccion.FechaEvento.UTC().Format(time.RFC3339Nano))
+	escribirCampo(h, transaccion.ActorEmisor)
+	escribirCampo(h, transaccion.IPFSCid)
+	escribirCampo(h, DatosCanonicos(transaccion.DatosEvento))
+
+	// El número de adjuntos va antes de la lista para que no se confunda con campos posteriores
+	escribirCampo(h, strconv.Itoa(len(transaccion.Adjuntos)))
+	for _, adjunto := range transaccion.Adjuntos {
+		escribirCampo(h, adjunto.CID)
+		escribirCampo(h, adjunto.Nombre)
+		escribirCampo(h, adjunto.TipoMIME)
+		escribirCampo(h, strconv.FormatInt(adjunto.Tamano, 10))
+		escribirCampo(h, adjunto.HashSHA256)
+	}
+
+	if etiqueta == etiquetaDominioTransaccionCadena {
+		escribirCampo(h, strconv.FormatInt(transaccion.Secuencia, 10))
+		escribirCampo(h, transaccion.HashEventoAnterior)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// escribirCampo escribe un campo con prefijo de longitud
+func escribirCampo(h hash.Hash, valor string) {
+	var longitud [8]byte
+	binary.BigEndian.PutUint64(longitud[:], uint64(len(valor)))
+	h.Write(longitud[:])
+	h.Write([]byte(valor))
+}
+
 // CalcularHashDatos calcula el hash SHA-256 de datos arbitrarios
 func CalcularHashDatos(datos string) string {
 	hash := sha256.Sum256([]byte(datos))
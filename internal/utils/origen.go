@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// OrigenCORS es un origen permitido por la política CORS; con comodín, Dominio admite cualquier
+// subdominio pero no el propio dominio
+type OrigenCORS struct {
+	Esquema string
+	Dominio string
+	Puerto  string
+	Comodin bool
+}
+
+// ParsearOrigenCORS interpreta un origen permitido: esquema http o https, host (opcionalmente con "*."
+// para cualquier subdominio) y puerto, sin ruta. El origen "*" lo resuelve quien llama.
+func ParsearOrigenCORS(origen string) (OrigenCORS, error) {
+	invalido := fmt.Errorf("'%s' debe ser * o un origen http(s)://host[:puerto], con *. opcional para los subdominios", origen)
+	destino, err := url.Parse(origen)
+	if err != nil || (destino.Scheme != "http" && destino.Scheme != "https") || destino.Opaque != "" ||
+		destino.User != nil || destino.Path != "" || destino.RawQuery != "" || destino.Fragment != "" {
+		return OrigenCORS{}, invalido
+	}
+
+	host := strings.ToLower(destino.Hostname())
+	permitido := OrigenCORS{Esquema: destino.Scheme, Dominio: host, Puerto: destino.Port()}
+	if dominio, ok := strings.CutPrefix(host, "*."); ok {
+		permitido.Dominio, permitido.Comodin = dominio, true
+	}
+	if permitido.Dominio == "" || strings.Contains(permitido.Dominio, "*") {
+		return OrigenCORS{}, invalido
+	}
+	return permitido, nil
+}
+
+// Permite indica si la cabecera Origin recibida coincide con el origen permitido
+func (o OrigenCORS) Permite(origen string) bool {
+	recibido, err := url.Parse(origen)
+	if err != nil || recibido.Path != "" || recibido.Scheme != o.Esquema || recibido.Port() != o.Puerto {
+		return false
+	}
+	host := strings.ToLower(recibido.Hostname())
+	if o.Comodin {
+		return strings.HasSuffix(host, "."+o.Dominio)
+	}
+	return host == o.Dominio
+}
@@ -0,0 +1,254 @@
+package canonical
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Canonicalizar transforma un documento JSON a su forma canónica según RFC 8785
+// (JSON Canonicalization Scheme): sin espacios, propiedades ordenadas por unidades
+// UTF-16, números en formato ECMAScript y strings con el escapado mínimo.
+// Dos documentos semánticamente iguales producen exactamente los mismos bytes.
+func Canonicalizar(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if err := canonicalizarValor(dec, &buf); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("JSON inválido: datos adicionales después del valor")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// CanonicalizarString es un atajo de Canonicalizar para documentos en string
+func CanonicalizarString(data string) (string, error) {
+	canonico, err := Canonicalizar([]byte(data))
+	if err != nil {
+		return "", err
+	}
+	return string(canonico), nil
+}
+
+// miembro es una propiedad de objeto ya canonicalizada
+type miembro struct {
+	clave      string
+	claveUTF16 []uint16
+	valor      []byte
+}
+
+func canonicalizarValor(dec *json.Decoder, buf *bytes.Buffer) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+
+	switch valor := token.(type) {
+	case json.Delim:
+		switch valor {
+		case '{':
+			return canonicalizarObjeto(dec, buf)
+		case '[':
+			return canonicalizarArreglo(dec, buf)
+		default:
+			return fmt.Errorf("JSON inválido: delimitador inesperado %q", valor)
+		}
+	case string:
+		escribirString(buf, valor)
+	case json.Number:
+		numero, err := formatearNumero(valor)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(numero)
+	case bool:
+		buf.WriteString(strconv.FormatBool(valor))
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("JSON inválido: token inesperado %v", valor)
+	}
+
+	return nil
+}
+
+func canonicalizarObjeto(dec *json.Decoder, buf *bytes.Buffer) error {
+	miembros := make([]miembro, 0)
+	vistos := make(map[string]bool)
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("JSON inválido: %w", err)
+		}
+		clave, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("JSON inválido: se esperaba el nombre de una propiedad")
+		}
+		// RFC 8785 exige I-JSON: los nombres de propiedad no se pueden repetir
+		if vistos[clave] {
+			return fmt.Errorf("JSON inválido: propiedad duplicada %q", clave)
+		}
+		vistos[clave] = true
+
+		var valor bytes.Buffer
+		if err := canonicalizarValor(dec, &valor); err != nil {
+			return err
+		}
+		miembros = append(miembros, miembro{
+			clave:      clave,
+			claveUTF16: utf16.Encode([]rune(clave)),
+			valor:      valor.Bytes(),
+		})
+	}
+
+	// Consumir '}'
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+
+	sort.Slice(miembros, func(i, j int) bool {
+		return compararUTF16(miembros[i].claveUTF16, miembros[j].claveUTF16) < 0
+	})
+
+	buf.WriteByte('{')
+	for i, m := range miembros {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		escribirString(buf, m.clave)
+		buf.WriteByte(':')
+		buf.Write(m.valor)
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+func canonicalizarArreglo(dec *json.Decoder, buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := canonicalizarValor(dec, buf); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+
+	// Consumir ']'
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+
+	return nil
+}
+
+// compararUTF16 ordena claves por sus unidades de código UTF-16, como exige RFC 8785
+func compararUTF16(a, b []uint16) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// escribirString serializa un string con el escapado de JSON.stringify de ECMAScript
+func escribirString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatearNumero serializa un número como lo hace Number.prototype.toString de ECMAScript
+func formatearNumero(numero json.Number) (string, error) {
+	valor, err := strconv.ParseFloat(string(numero), 64)
+	if err != nil || math.IsInf(valor, 0) || math.IsNaN(valor) {
+		return "", fmt.Errorf("JSON inválido: número fuera de rango IEEE 754: %s", numero)
+	}
+
+	if valor == 0 {
+		// También cubre -0
+		return "0", nil
+	}
+
+	signo := ""
+	if valor < 0 {
+		signo = "-"
+		valor = -valor
+	}
+
+	// Representación más corta que identifica al double: d.ddddde±XX
+	cientifica := strconv.FormatFloat(valor, 'e', -1, 64)
+	mantisa, exponenteStr, _ := strings.Cut(cientifica, "e")
+	digitos := strings.Replace(mantisa, ".", "", 1)
+	exponente, _ := strconv.Atoi(exponenteStr)
+
+	k := len(digitos)
+	n := exponente + 1
+
+	var resultado string
+	switch {
+	case k <= n && n <= 21:
+		resultado = digitos + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		resultado = digitos[:n] + "." + digitos[n:]
+	case -6 < n && n <= 0:
+		resultado = "0." + strings.Repeat("0", -n) + digitos
+	default:
+		signoExponente := "+"
+		if n-1 < 0 {
+			signoExponente = "-"
+		}
+		exponenteAbs := n - 1
+		if exponenteAbs < 0 {
+			exponenteAbs = -exponenteAbs
+		}
+		resultado = digitos[:1]
+		if k > 1 {
+			resultado += "." + digitos[1:]
+		}
+		resultado += "e" + signoExponente + strconv.Itoa(exponenteAbs)
+	}
+
+	return signo + resultado, nil
+}
@@ -0,0 +1,435 @@
+// Package client es un cliente Go tipado de la API de MediSupply.
+// Sus métodos y tipos siguen la especificación servida en /api/v1/openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Error es una respuesta de error de la API (application/problem+json, RFC 7807). Decida según Codigo:
+// es estable, mientras que Titulo y Detalle son texto para personas y pueden cambiar.
+type Error struct {
+	StatusCode int
+	RequestID  string          // Cabecera X-Request-ID de la respuesta; identifica la petición en los logs del servidor
+	Tipo       string          `json:"type"`
+	Titulo     string          `json:"title"`
+	Detalle    string          `json:"detail,omitempty"`
+	Instancia  string          `json:"instance,omitempty"`
+	Codigo     string          `json:"codigo"`
+	Regla      string          `json:"regla,omitempty"`      // Regla de la política que denegó la petición (403)
+	Resultados []ResultadoLote `json:"resultados,omitempty"` // Resultados por elemento cuando se rechaza un lote (400)
+}
+
+func (e *Error) Error() string {
+	if e.Detalle != "" {
+		return fmt.Sprintf("%d %s (%s): %s", e.StatusCode, e.Titulo, e.Codigo, e.Detalle)
+	}
+	return fmt.Sprintf("%d %s (%s)", e.StatusCode, e.Titulo, e.Codigo)
+}
+
+// CodigoError retorna el código estable de un *Error de la API, o "" si err no es un error de la API
+func CodigoError(err error) string {
+	var errAPI *Error
+	if errors.As(err, &errAPI) {
+		return errAPI.Codigo
+	}
+	return ""
+}
+
+// leerError decodifica el problema de una respuesta fallida; si el cuerpo no es un problema usa el texto del status
+func leerError(resp *http.Response) *Error {
+	errAPI := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+	if err := json.NewDecoder(resp.Body).Decode(errAPI); err != nil || errAPI.Titulo == "" {
+		errAPI.Titulo = http.StatusText(resp.StatusCode)
+	}
+	return errAPI
+}
+
+// Client llama a la API HTTP. Es seguro para uso concurrente.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	apiKey      string
+	tokenBearer string
+	idioma      string
+}
+
+// Opcion configura un Client
+type Opcion func(*Client)
+
+// ConHTTPClient usa un http.Client propio (timeouts, transporte con certificado de cliente para mTLS, etc.)
+func ConHTTPClient(httpClient *http.Client) Opcion {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// ConAPIKey autentica cada petición con la cabecera X-API-Key
+func ConAPIKey(clave string) Opcion {
+	return func(c *Client) { c.apiKey = clave }
+}
+
+// ConToken autentica cada petición con Authorization: Bearer (JWT o API key)
+func ConToken(token string) Opcion {
+	return func(c *Client) { c.tokenBearer = token }
+}
+
+// ConIdioma pide los mensajes y los errores en el idioma indicado (cabecera Accept-Language, p. ej. "en")
+func ConIdioma(idioma string) Opcion {
+	return func(c *Client) { c.idioma = idioma }
+}
+
+// NewClient crea un cliente para la API en baseURL, p. ej. http://localhost:8080
+func NewClient(baseURL string, opciones ...Opcion) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opcion := range opciones {
+		opcion(c)
+	}
+	return c
+}
+
+// OpcionPeticion ajusta una petición concreta
+type OpcionPeticion func(*http.Request)
+
+// ConIdempotencyKey envía una Idempotency-Key; los reintentos con la misma clave devuelven la respuesta original
+func ConIdempotencyKey(clave string) OpcionPeticion {
+	return func(r *http.Request) { r.Header.Set("Idempotency-Key", clave) }
+}
+
+// ConRequestID envía el X-Request-ID con que el servidor registra la petición en sus logs
+func ConRequestID(id string) OpcionPeticion {
+	return func(r *http.Request) { r.Header.Set("X-Request-ID", id) }
+}
+
+// Archivo es un documento a adjuntar en RegistrarTransaccionConAdjuntos
+type Archivo struct {
+	Nombre    string
+	TipoMIME  string
+	Contenido io.Reader
+}
+
+// RegistrarTransaccion llama a POST /api/v1/transaccion/registrar
+func (c *Client) RegistrarTransaccion(ctx context.Context, req TransaccionRequest, opciones ...OpcionPeticion) (*TransaccionResponse, error) {
+	var respuesta struct {
+		Data TransaccionResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodPost, "/api/v1/transaccion/registrar", req, &respuesta, opciones...); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// RegistrarTransaccionConAdjuntos llama a POST /api/v1/transaccion/registrar-con-adjuntos.
+// Los archivos se transmiten mientras se leen, sin cargarlos en memoria.
+func (c *Client) RegistrarTransaccionConAdjuntos(ctx context.Context, req TransaccionRequest, archivos []Archivo) (*TransaccionResponse, error) {
+	lector, escritor := io.Pipe()
+	formulario := multipart.NewWriter(escritor)
+	go func() {
+		escritor.CloseWithError(escribirFormulario(formulario, req, archivos))
+	}()
+
+	peticion, err := c.nuevaPeticion(ctx, http.MethodPost, "/api/v1/transaccion/registrar-con-adjuntos", lector)
+	if err != nil {
+		lector.Close()
+		return nil, err
+	}
+	peticion.Header.Set("Content-Type", formulario.FormDataContentType())
+
+	var respuesta struct {
+		Data TransaccionResponse `json:"data"`
+	}
+	if err := c.enviar(peticion, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// escribirFormulario escribe los campos del evento antes que los archivos, como exige el servidor
+func escribirFormulario(formulario *multipart.Writer, req TransaccionRequest, archivos []Archivo) error {
+	campos := [][2]string{
+		{"tipoEvento", req.TipoEvento},
+		{"idProducto", req.IDProducto},
+		{"datosEvento", req.DatosEvento},
+		{"actorEmisor", req.ActorEmisor},
+	}
+	for _, campo := range campos {
+		if err := formulario.WriteField(campo[0], campo[1]); err != nil {
+			return err
+		}
+	}
+	for _, archivo := range archivos {
+		cabecera := make(map[string][]string)
+		cabecera["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="adjuntos"; filename=%q`, archivo.Nombre)}
+		cabecera["Content-Type"] = []string{archivo.TipoMIME}
+		parte, err := formulario.CreatePart(cabecera)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(parte, archivo.Contenido); err != nil {
+			return err
+		}
+	}
+	return formulario.Close()
+}
+
+// RegistrarLote llama a POST /api/v1/transaccion/lote. Un lote con fallos parciales (207) no es un error:
+// consulte Fallidas y Resultados. Si el lote se rechaza completo, *Error incluye los resultados por elemento.
+func (c *Client) RegistrarLote(ctx context.Context, eventos []TransaccionRequest, opciones ...OpcionPeticion) (*LoteResponse, error) {
+	var respuesta struct {
+		Data LoteResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodPost, "/api/v1/transaccion/lote", eventos, &respuesta, opciones...); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ObtenerEstadoBlockchain llama a GET /api/v1/transaccion/estado-blockchain/{id}
+func (c *Client) ObtenerEstadoBlockchain(ctx context.Context, id string) (*EstadoBlockchainResponse, error) {
+	var respuesta struct {
+		Data EstadoBlockchainResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/transaccion/estado-blockchain/"+url.PathEscape(id), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// VerificarTransaccion llama a GET /api/v1/transaccion/verificar/{id}
+func (c *Client) VerificarTransaccion(ctx context.Context, id string) (*VerificacionResponse, error) {
+	var respuesta struct {
+		Data VerificacionResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/transaccion/verificar/"+url.PathEscape(id), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ObtenerTransaccion llama a GET /api/v1/transaccion/{id}
+func (c *Client) ObtenerTransaccion(ctx context.Context, id string) (*Transaccion, error) {
+	var respuesta struct {
+		Data Transaccion `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/transaccion/"+url.PathEscape(id), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ListarTransacciones llama a GET /api/v1/transaccion; limit <= 0 usa el valor por defecto del servidor
+func (c *Client) ListarTransacciones(ctx context.Context, limit int) ([]Transaccion, error) {
+	ruta := "/api/v1/transaccion"
+	if limit > 0 {
+		ruta += "?limit=" + strconv.Itoa(limit)
+	}
+	var respuesta struct {
+		Data []Transaccion `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, ruta, nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return respuesta.Data, nil
+}
+
+// ObtenerTransaccionesPorProducto llama a GET /api/v1/transaccion/producto/{id}
+func (c *Client) ObtenerTransaccionesPorProducto(ctx context.Context, idProducto string) ([]Transaccion, error) {
+	var respuesta struct {
+		Data []Transaccion `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/transaccion/producto/"+url.PathEscape(idProducto), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return respuesta.Data, nil
+}
+
+// ObtenerDatosVerificados llama a GET /api/v1/oracle/datos/{id}
+func (c *Client) ObtenerDatosVerificados(ctx context.Context, idProducto string) (*OracleDataResponse, error) {
+	var respuesta struct {
+		Data OracleDataResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/oracle/datos/"+url.PathEscape(idProducto), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ObtenerHistorialVerificado llama a GET /api/v1/oracle/historial/{id}
+func (c *Client) ObtenerHistorialVerificado(ctx context.Context, idProducto string) (*HistorialVerificado, error) {
+	var respuesta struct {
+		Data HistorialVerificado `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/oracle/historial/"+url.PathEscape(idProducto), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ValidarCadenaSupply llama a GET /api/v1/oracle/validar/{id}. Una cadena inválida (422) no es un error:
+// se retorna con CadenaValida en false.
+func (c *Client) ValidarCadenaSupply(ctx context.Context, idProducto string) (*ValidacionCadenaResponse, error) {
+	peticion, err := c.nuevaPeticion(ctx, http.MethodGet, "/api/v1/oracle/validar/"+url.PathEscape(idProducto), nil)
+	if err != nil {
+		return nil, err
+	}
+	var respuesta ValidacionCadenaResponse
+	if err := c.enviar(peticion, &respuesta, http.StatusUnprocessableEntity); err != nil {
+		return nil, err
+	}
+	return &respuesta, nil
+}
+
+// CrearAPIKey llama a POST /api/v1/admin/api-keys
+func (c *Client) CrearAPIKey(ctx context.Context, req APIKeyRequest) (*APIKeyCreadaResponse, error) {
+	var respuesta struct {
+		Data APIKeyCreadaResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodPost, "/api/v1/admin/api-keys", req, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ListarAPIKeys llama a GET /api/v1/admin/api-keys
+func (c *Client) ListarAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var respuesta struct {
+		Data []APIKey `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/admin/api-keys", nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return respuesta.Data, nil
+}
+
+// RevocarAPIKey llama a DELETE /api/v1/admin/api-keys/{id}
+func (c *Client) RevocarAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	var respuesta struct {
+		Data APIKey `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodDelete, "/api/v1/admin/api-keys/"+url.PathEscape(id), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// RegistrarIdentidadCertificado llama a POST /api/v1/admin/certificados
+func (c *Client) RegistrarIdentidadCertificado(ctx context.Context, req IdentidadCertificadoRequest) (*IdentidadCertificado, error) {
+	var respuesta struct {
+		Data IdentidadCertificado `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodPost, "/api/v1/admin/certificados", req, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ListarIdentidadesCertificado llama a GET /api/v1/admin/certificados
+func (c *Client) ListarIdentidadesCertificado(ctx context.Context) ([]IdentidadCertificado, error) {
+	var respuesta struct {
+		Data []IdentidadCertificado `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/admin/certificados", nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return respuesta.Data, nil
+}
+
+// RevocarIdentidadCertificado llama a DELETE /api/v1/admin/certificados?identidad=
+func (c *Client) RevocarIdentidadCertificado(ctx context.Context, identidad string) (*IdentidadCertificado, error) {
+	var respuesta struct {
+		Data IdentidadCertificado `json:"data"`
+	}
+	ruta := "/api/v1/admin/certificados?identidad=" + url.QueryEscape(identidad)
+	if err := c.hacer(ctx, http.MethodDelete, ruta, nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ObtenerFondos llama a GET /api/v1/admin/fondos
+func (c *Client) ObtenerFondos(ctx context.Context) (*EstadoFondos, error) {
+	var respuesta struct {
+		Data EstadoFondos `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/admin/fondos", nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// hacer envía cuerpo como JSON (si no es nil) y decodifica la respuesta en destino
+func (c *Client) hacer(ctx context.Context, metodo, ruta string, cuerpo, destino any, opciones ...OpcionPeticion) error {
+	var lector io.Reader
+	if cuerpo != nil {
+		datos, err := json.Marshal(cuerpo)
+		if err != nil {
+			return fmt.Errorf("error serializando petición: %w", err)
+		}
+		lector = bytes.NewReader(datos)
+	}
+
+	peticion, err := c.nuevaPeticion(ctx, metodo, ruta, lector)
+	if err != nil {
+		return err
+	}
+	if cuerpo != nil {
+		peticion.Header.Set("Content-Type", "application/json")
+	}
+	for _, opcion := range opciones {
+		opcion(peticion)
+	}
+	return c.enviar(peticion, destino)
+}
+
+func (c *Client) nuevaPeticion(ctx context.Context, metodo, ruta string, cuerpo io.Reader) (*http.Request, error) {
+	peticion, err := http.NewRequestWithContext(ctx, metodo, c.baseURL+ruta, cuerpo)
+	if err != nil {
+		return nil, err
+	}
+	peticion.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		peticion.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.tokenBearer != "" {
+		peticion.Header.Set("Authorization", "Bearer "+c.tokenBearer)
+	}
+	if c.idioma != "" {
+		peticion.Header.Set("Accept-Language", c.idioma)
+	}
+	return peticion, nil
+}
+
+// enviar ejecuta la petición; los códigos 2xx y los indicados en aceptados se decodifican en destino
+func (c *Client) enviar(peticion *http.Request, destino any, aceptados ...int) error {
+	resp, err := c.httpClient.Do(peticion)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	exito := resp.StatusCode >= 200 && resp.StatusCode < 300
+	for _, codigo := range aceptados {
+		exito = exito || resp.StatusCode == codigo
+	}
+	if !exito {
+		return leerError(resp)
+	}
+
+	if destino != nil {
+		if err := json.NewDecoder(resp.Body).Decode(destino); err != nil {
+			return fmt.Errorf("error decodificando respuesta: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrHistorialIncompleto indica que el último ID ya no estaba en el historial del servidor y pudieron
+// perderse notificaciones. El stream sigue abierto: conviene resincronizar por la API REST y seguir leyendo.
+var ErrHistorialIncompleto = errors.New("historial incompleto: pudieron perderse notificaciones")
+
+// Desconexion indica que el servidor cerró el stream (cliente lento o apagado); se reanuda
+// llamando de nuevo a SuscribirEventos con UltimoID
+type Desconexion struct {
+	Mensaje string
+}
+
+func (e *Desconexion) Error() string {
+	return "stream cerrado por el servidor: " + e.Mensaje
+}
+
+// FiltroEventos selecciona las notificaciones a recibir; sin valores se reciben todas
+type FiltroEventos struct {
+	Transacciones []string
+	Productos     []string
+	Actores       []string
+	UltimoID      string // Reanuda después de este ID (Last-Event-ID)
+}
+
+// StreamEventos lee las notificaciones de GET /api/v1/eventos. No es seguro para uso concurrente.
+type StreamEventos struct {
+	cuerpo   io.ReadCloser
+	lector   *bufio.Reader
+	ultimoID string
+}
+
+// SuscribirEventos abre el stream Server-Sent Events de GET /api/v1/eventos.
+// El stream termina al cancelar ctx o llamar a Cerrar.
+func (c *Client) SuscribirEventos(ctx context.Context, filtro FiltroEventos) (*StreamEventos, error) {
+	consulta := url.Values{}
+	for _, id := range filtro.Transacciones {
+		consulta.Add("transaccion", id)
+	}
+	for _, id := range filtro.Productos {
+		consulta.Add("producto", id)
+	}
+	for _, actor := range filtro.Actores {
+		consulta.Add("actor", actor)
+	}
+	ruta := "/api/v1/eventos"
+	if len(consulta) > 0 {
+		ruta += "?" + consulta.Encode()
+	}
+
+	peticion, err := c.nuevaPeticion(ctx, http.MethodGet, ruta, nil)
+	if err != nil {
+		return nil, err
+	}
+	peticion.Header.Set("Accept", "text/event-stream")
+	if filtro.UltimoID != "" {
+		peticion.Header.Set("Last-Event-ID", filtro.UltimoID)
+	}
+
+	resp, err := c.httpClient.Do(peticion)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, leerError(resp)
+	}
+	return &StreamEventos{cuerpo: resp.Body, lector: bufio.NewReader(resp.Body), ultimoID: filtro.UltimoID}, nil
+}
+
+// Siguiente bloquea hasta la próxima notificación. Además de los errores de red retorna
+// ErrHistorialIncompleto (se puede seguir leyendo), *Desconexion o io.EOF (el stream terminó).
+func (s *StreamEventos) Siguiente() (*Notificacion, error) {
+	var id, evento string
+	var datos []string
+	for {
+		linea, err := s.lector.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		linea = strings.TrimRight(linea, "\r\n")
+
+		if linea != "" {
+			campo, valor, _ := strings.Cut(linea, ":")
+			valor = strings.TrimPrefix(valor, " ")
+			switch campo {
+			case "id":
+				id = valor
+			case "event":
+				evento = valor
+			case "data":
+				datos = append(datos, valor)
+			}
+			continue
+		}
+
+		// Línea vacía: fin del evento. Los comentarios (latidos) y retry no llevan data.
+		if len(datos) == 0 {
+			id, evento = "", ""
+			continue
+		}
+		contenido := strings.Join(datos, "\n")
+		switch evento {
+		case "historial-incompleto":
+			return nil, ErrHistorialIncompleto
+		case "desconectado":
+			var mensaje MensajeStream
+			_ = json.Unmarshal([]byte(contenido), &mensaje)
+			return nil, &Desconexion{Mensaje: mensaje.Mensaje}
+		}
+
+		var notificacion Notificacion
+		if err := json.Unmarshal([]byte(contenido), &notificacion); err != nil {
+			return nil, fmt.Errorf("error decodificando notificación: %w", err)
+		}
+		if id != "" {
+			s.ultimoID = id
+		}
+		return &notificacion, nil
+	}
+}
+
+// UltimoID retorna el ID de la última notificación leída, para reanudar con FiltroEventos.UltimoID
+func (s *StreamEventos) UltimoID() string {
+	return s.ultimoID
+}
+
+// Cerrar termina el stream
+func (s *StreamEventos) Cerrar() error {
+	return s.cuerpo.Close()
+}
@@ -0,0 +1,318 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Los tipos reflejan los esquemas de api/openapi.json; tests/openapi_test.go verifica que coincidan.
+
+// TransaccionRequest es el evento a registrar
+type TransaccionRequest struct {
+	TipoEvento  string `json:"tipoEvento"`
+	IDProducto  string `json:"idProducto"`
+	DatosEvento string `json:"datosEvento"` // Documento JSON serializado
+	ActorEmisor string `json:"actorEmisor"` // El servidor lo reemplaza por el actor autenticado
+}
+
+// Adjunto es un documento binario almacenado en IPFS y asociado a un evento
+type Adjunto struct {
+	CID        string   `json:"cid"`
+	Nombre     string   `json:"nombre"`
+	TipoMIME   string   `json:"tipoMime"`
+	Tamano     int64    `json:"tamano"`
+	HashSHA256 string   `json:"hashSha256"`
+	NodosIPFS  []string `json:"nodosIPFS,omitempty"`
+}
+
+// Transaccion es un evento registrado
+type Transaccion struct {
+	IDTransaction       string    `json:"idTransaction"`
+	TipoEvento          string    `json:"tipoEvento"`
+	IDProducto          string    `json:"idProducto"`
+	FechaEvento         time.Time `json:"fechaEvento"`
+	DatosEvento         string    `json:"datosEvento"`
+	HashEvento          string    `json:"hashEvento"`
+	HashVersion         int       `json:"hashVersion"`
+	HashEventoAnterior  string    `json:"hashEventoAnterior,omitempty"`
+	Secuencia           int64     `json:"secuencia,omitempty"`
+	DirectionBlockchain string    `json:"directionBlockchain"`
+	EthereumTxHash      string    `json:"ethereumTxHash"`
+	IPFSCid             string    `json:"ipfsCid"`
+	NodosIPFS           []string  `json:"nodosIPFS,omitempty"`
+	ActorEmisor         string    `json:"actorEmisor"`
+	Adjuntos            []Adjunto `json:"adjuntos,omitempty"`
+	Estado              string    `json:"estado"`
+	FirmaDigital        string    `json:"firmaDigital"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// TransaccionResponse es la respuesta al registrar un evento
+type TransaccionResponse struct {
+	IDTransaction       string    `json:"idTransaction"`
+	TipoEvento          string    `json:"tipoEvento"`
+	IDProducto          string    `json:"idProducto"`
+	FechaEvento         time.Time `json:"fechaEvento"`
+	HashEvento          string    `json:"hashEvento"`
+	HashVersion         int       `json:"hashVersion"`
+	HashEventoAnterior  string    `json:"hashEventoAnterior,omitempty"`
+	Secuencia           int64     `json:"secuencia,omitempty"`
+	DirectionBlockchain string    `json:"directionBlockchain"`
+	EthereumTxHash      string    `json:"ethereumTxHash"`
+	IPFSCid             string    `json:"ipfsCid"`
+	ActorEmisor         string    `json:"actorEmisor"`
+	Adjuntos            []Adjunto `json:"adjuntos,omitempty"`
+	Estado              string    `json:"estado"`
+	CreatedAt           time.Time `json:"createdAt"`
+}
+
+// ResultadoLote es el resultado de un elemento del lote
+type ResultadoLote struct {
+	Indice        int    `json:"indice"`
+	Estado        string `json:"estado"`
+	IDTransaction string `json:"idTransaction,omitempty"`
+	IDProducto    string `json:"idProducto,omitempty"`
+	HashEvento    string `json:"hashEvento,omitempty"`
+	IPFSCid       string `json:"ipfsCid,omitempty"`
+	Secuencia     int64  `json:"secuencia,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// LoteResponse resume un registro por lotes
+type LoteResponse struct {
+	Total       int             `json:"total"`
+	Registradas int             `json:"registradas"`
+	Fallidas    int             `json:"fallidas"`
+	Resultados  []ResultadoLote `json:"resultados"`
+}
+
+// VerificacionResponse es el resultado de verificar la integridad de una transacción
+type VerificacionResponse struct {
+	IDTransaction        string `json:"idTransaction"`
+	Verificado           bool   `json:"verificado"`
+	HashLocal            string `json:"hashLocal"`
+	HashBlockchain       string `json:"hashBlockchain"`
+	DatosIPFSVerificados bool   `json:"datosIPFSVerificados"`
+	AdjuntosVerificados  bool   `json:"adjuntosVerificados"`
+	Mensaje              string `json:"mensaje"`
+}
+
+// EstadoBlockchainResponse es el estado del anclaje de una transacción
+type EstadoBlockchainResponse struct {
+	IDTransaction          string `json:"idTransaction"`
+	Estado                 string `json:"estado"`
+	RegistradoEnBlockchain bool   `json:"registradoEnBlockchain"`
+	DirectionBlockchain    string `json:"directionBlockchain"`
+	EthereumTxHash         string `json:"ethereumTxHash,omitempty"`
+	Mensaje                string `json:"mensaje"`
+	Timestamp              string `json:"timestamp,omitempty"`
+}
+
+// EventoVerificado es un evento del historial con el resultado de su verificación
+type EventoVerificado struct {
+	IDEvento              string    `json:"idEvento"`
+	TipoEvento            string    `json:"tipoEvento"`
+	Fecha                 time.Time `json:"fecha"`
+	ResultadoVerificacion bool      `json:"resultadoVerificacion"`
+	ReferenciaBlockchain  string    `json:"referenciaBlockchain"`
+	IPFSCid               string    `json:"ipfsCid"`
+	ActorEmisor           string    `json:"actorEmisor"`
+	Secuencia             int64     `json:"secuencia,omitempty"`
+	HashEventoAnterior    string    `json:"hashEventoAnterior,omitempty"`
+	ErrorVerificacion     string    `json:"errorVerificacion,omitempty"`
+}
+
+// AnomaliaCadena es una inconsistencia en la cadena de eventos de un producto
+type AnomaliaCadena struct {
+	Tipo      string `json:"tipo"`
+	Secuencia int64  `json:"secuencia"`
+	IDEvento  string `json:"idEvento,omitempty"`
+	Detalle   string `json:"detalle"`
+}
+
+// VerificacionCadena es el resultado de verificar la cadena de hashes de un producto
+type VerificacionCadena struct {
+	Integra             bool             `json:"integra"`
+	Longitud            int64            `json:"longitud"`
+	EventosSinEncadenar int              `json:"eventosSinEncadenar"`
+	Anomalias           []AnomaliaCadena `json:"anomalias"`
+}
+
+// HistorialVerificado es el historial verificado de un producto
+type HistorialVerificado struct {
+	IDProducto    string              `json:"idProducto"`
+	TotalEventos  int                 `json:"totalEventos"`
+	Verificados   int                 `json:"verificados"`
+	NoVerificados int                 `json:"noVerificados"`
+	Eventos       []EventoVerificado  `json:"eventos"`
+	Cadena        *VerificacionCadena `json:"cadena"`
+	FechaConsulta time.Time           `json:"fechaConsulta"`
+}
+
+// OracleDataResponse son los datos verificados que expone el Oracle
+type OracleDataResponse struct {
+	IDProducto          string             `json:"idProducto"`
+	Estado              string             `json:"estado"`
+	UltimaActualizacion time.Time          `json:"ultimaActualizacion"`
+	CadenaVerificada    bool               `json:"cadenaVerificada"`
+	Historial           []EventoVerificado `json:"historial"`
+	Metadata            map[string]string  `json:"metadata"`
+}
+
+// ValidacionCadenaResponse es el resultado de validar la cadena de suministro de un producto
+type ValidacionCadenaResponse struct {
+	IDProducto        string   `json:"idProducto"`
+	CadenaValida      bool     `json:"cadenaValida"`
+	ErroresDetectados []string `json:"erroresDetectados"`
+}
+
+// APIKeyRequest es el payload para emitir una API key
+type APIKeyRequest struct {
+	Nombre string   `json:"nombre"`
+	Actor  string   `json:"actor"`
+	Roles  []string `json:"roles"`
+}
+
+// APIKey es una API key emitida; el secreto solo se devuelve al crearla
+type APIKey struct {
+	ID         string     `json:"id"`
+	Nombre     string     `json:"nombre"`
+	Actor      string     `json:"actor"`
+	Roles      []string   `json:"roles"`
+	CreadaEn   time.Time  `json:"creadaEn"`
+	RevocadaEn *time.Time `json:"revocadaEn,omitempty"`
+}
+
+// APIKeyCreadaResponse incluye la clave en texto plano
+type APIKeyCreadaResponse struct {
+	Clave  string  `json:"clave"`
+	APIKey *APIKey `json:"apiKey"`
+}
+
+// IdentidadCertificadoRequest es el payload para asociar un certificado de cliente a un actor
+type IdentidadCertificadoRequest struct {
+	Identidad string   `json:"identidad"`
+	Nombre    string   `json:"nombre"`
+	Actor     string   `json:"actor"`
+	Roles     []string `json:"roles"`
+}
+
+// IdentidadCertificado es una identidad de certificado registrada
+type IdentidadCertificado struct {
+	Identidad  string     `json:"identidad"`
+	Nombre     string     `json:"nombre"`
+	Actor      string     `json:"actor"`
+	Roles      []string   `json:"roles"`
+	CreadaEn   time.Time  `json:"creadaEn"`
+	RevocadaEn *time.Time `json:"revocadaEn,omitempty"`
+}
+
+// EstadoFondos es el saldo de la cuenta firmante y su proyección según el gasto reciente en gas
+type EstadoFondos struct {
+	Cuenta            string    `json:"cuenta"`
+	Nivel             string    `json:"nivel"` // ok, bajo o insuficiente
+	AnclajePausado    bool      `json:"anclajePausado"`
+	SaldoWei          string    `json:"saldoWei"`
+	SaldoEth          string    `json:"saldoEth"`
+	GastoDiarioWei    string    `json:"gastoDiarioWei"`
+	GastoDiarioEth    string    `json:"gastoDiarioEth"`
+	AnclajesUltimoDia int       `json:"anclajesUltimoDia"`
+	CostoAnclajeWei   string    `json:"costoAnclajeWei"`
+	CostoAnclajeEth   string    `json:"costoAnclajeEth"`
+	AnclajesRestantes int64     `json:"anclajesRestantes"`
+	AutonomiaHoras    *float64  `json:"autonomiaHoras"` // nil sin gasto reciente
+	Error             string    `json:"error,omitempty"`
+	ActualizadoEn     time.Time `json:"actualizadoEn"`
+}
+
+// Notificacion es un cambio de estado de una transacción recibido por SuscribirEventos
+type Notificacion struct {
+	ID                   string    `json:"id"`
+	Tipo                 string    `json:"tipo"` // registrada, anclada, confirmada, fallida o estado-oracle
+	IDTransaction        string    `json:"idTransaction"`
+	IDProducto           string    `json:"idProducto"`
+	TipoEvento           string    `json:"tipoEvento"`
+	ActorEmisor          string    `json:"actorEmisor"`
+	Estado               string    `json:"estado"`
+	Secuencia            int64     `json:"secuencia,omitempty"`
+	HashEvento           string    `json:"hashEvento,omitempty"`
+	IPFSCid              string    `json:"ipfsCid,omitempty"`
+	DirectionBlockchain  string    `json:"directionBlockchain,omitempty"`
+	EthereumTxHash       string    `json:"ethereumTxHash,omitempty"`
+	Mensaje              string    `json:"mensaje,omitempty"`
+	EstadoOracle         string    `json:"estadoOracle,omitempty"`
+	EstadoOracleAnterior string    `json:"estadoOracleAnterior,omitempty"`
+	Fecha                time.Time `json:"fecha"`
+}
+
+// MensajeStream es un mensaje de control del stream de eventos (historial-incompleto, desconectado)
+type MensajeStream struct {
+	Tipo    string `json:"tipo"`
+	Mensaje string `json:"mensaje"`
+}
+
+// WebhookRequest es el payload para crear o reemplazar un webhook; los filtros vacíos aceptan cualquier valor
+type WebhookRequest struct {
+	URL         string   `json:"url"`
+	Descripcion string   `json:"descripcion,omitempty"`
+	Productos   []string `json:"productos,omitempty"`
+	Actores     []string `json:"actores,omitempty"`
+	TiposEvento []string `json:"tiposEvento,omitempty"`
+	Estados     []string `json:"estados,omitempty"` // registrada, anclada, confirmada, fallida o estado-oracle
+	Activo      *bool    `json:"activo,omitempty"`
+}
+
+// Webhook es una suscripción registrada; el secreto solo se devuelve al crearla
+type Webhook struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Descripcion   string    `json:"descripcion,omitempty"`
+	Productos     []string  `json:"productos"`
+	Actores       []string  `json:"actores"`
+	TiposEvento   []string  `json:"tiposEvento"`
+	Estados       []string  `json:"estados"`
+	Activo        bool      `json:"activo"`
+	Propietario   string    `json:"propietario"`
+	CreadoEn      time.Time `json:"creadoEn"`
+	ActualizadoEn time.Time `json:"actualizadoEn"`
+}
+
+// WebhookCreadoResponse incluye el secreto con el que se firman las entregas
+type WebhookCreadoResponse struct {
+	Secreto string   `json:"secreto"`
+	Webhook *Webhook `json:"webhook"`
+}
+
+// IntentoEntrega es un intento de envío de una entrega
+type IntentoEntrega struct {
+	Numero     int       `json:"numero"`
+	Fecha      time.Time `json:"fecha"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DuracionMs int64     `json:"duracionMs"`
+}
+
+// EntregaWebhook es una entrada del log de entregas de un webhook
+type EntregaWebhook struct {
+	ID             string           `json:"id"`
+	WebhookID      string           `json:"webhookId"`
+	Tipo           string           `json:"tipo"`
+	IDNotificacion string           `json:"idNotificacion"`
+	Estado         string           `json:"estado"` // pendiente, entregada o muerta
+	Intento        int              `json:"intento"`
+	Intentos       []IntentoEntrega `json:"intentos"`
+	ProximoIntento time.Time        `json:"proximoIntento"`
+	Payload        json.RawMessage  `json:"payload"`
+	CreadaEn       time.Time        `json:"creadaEn"`
+	ActualizadaEn  time.Time        `json:"actualizadaEn"`
+}
+
+// EventoWebhook es el cuerpo que recibe el receptor de un webhook
+type EventoWebhook struct {
+	ID        string       `json:"id"` // Igual en todos los reintentos de la entrega
+	WebhookID string       `json:"webhookId"`
+	Tipo      string       `json:"tipo"`
+	Fecha     time.Time    `json:"fecha"`
+	Datos     Notificacion `json:"datos"`
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cabeceras que acompañan cada entrega de webhook
+const (
+	CabeceraWebhookID        = "X-MediSupply-Webhook-Id"
+	CabeceraWebhookEntrega   = "X-MediSupply-Entrega"
+	CabeceraWebhookTimestamp = "X-MediSupply-Timestamp"
+	CabeceraWebhookFirma     = "X-MediSupply-Firma"
+)
+
+var (
+	// ErrFirmaInvalida indica que la firma falta o no corresponde al cuerpo y al secreto
+	ErrFirmaInvalida = errors.New("firma de webhook inválida")
+	// ErrFirmaExpirada indica que el timestamp firmado está fuera de la tolerancia (posible repetición)
+	ErrFirmaExpirada = errors.New("timestamp de webhook fuera de tolerancia")
+)
+
+// VerificarFirmaWebhook comprueba la firma de una entrega recibida. cuerpo debe ser el cuerpo exacto,
+// sin volver a serializarlo. Con tolerancia > 0 también rechaza timestamps más viejos o más nuevos que ella.
+func VerificarFirmaWebhook(secreto string, cabeceras http.Header, cuerpo []byte, tolerancia time.Duration) error {
+	marca := cabeceras.Get(CabeceraWebhookTimestamp)
+	timestamp, err := strconv.ParseInt(marca, 10, 64)
+	if err != nil {
+		return ErrFirmaInvalida
+	}
+	if tolerancia > 0 {
+		desfase := time.Since(time.Unix(timestamp, 0))
+		if desfase > tolerancia || desfase < -tolerancia {
+			return ErrFirmaExpirada
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secreto))
+	mac.Write([]byte(marca + "."))
+	mac.Write(cuerpo)
+	esperada := mac.Sum(nil)
+
+	// La cabecera puede traer varias firmas separadas por coma (rotación de esquemas); basta una v1 válida
+	for _, firma := range strings.Split(cabeceras.Get(CabeceraWebhookFirma), ",") {
+		valor, ok := strings.CutPrefix(strings.TrimSpace(firma), "v1=")
+		if !ok {
+			continue
+		}
+		if recibida, err := hex.DecodeString(valor); err == nil && hmac.Equal(recibida, esperada) {
+			return nil
+		}
+	}
+	return ErrFirmaInvalida
+}
+
+// CrearWebhook llama a POST /api/v1/webhooks
+func (c *Client) CrearWebhook(ctx context.Context, req WebhookRequest) (*WebhookCreadoResponse, error) {
+	var respuesta struct {
+		Data WebhookCreadoResponse `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodPost, "/api/v1/webhooks", req, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ListarWebhooks llama a GET /api/v1/webhooks
+func (c *Client) ListarWebhooks(ctx context.Context) ([]Webhook, error) {
+	var respuesta struct {
+		Data []Webhook `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/webhooks", nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return respuesta.Data, nil
+}
+
+// ObtenerWebhook llama a GET /api/v1/webhooks/{id}
+func (c *Client) ObtenerWebhook(ctx context.Context, id string) (*Webhook, error) {
+	var respuesta struct {
+		Data Webhook `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodGet, "/api/v1/webhooks/"+url.PathEscape(id), nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// ActualizarWebhook llama a PUT /api/v1/webhooks/{id}
+func (c *Client) ActualizarWebhook(ctx context.Context, id string, req WebhookRequest) (*Webhook, error) {
+	var respuesta struct {
+		Data Webhook `json:"data"`
+	}
+	if err := c.hacer(ctx, http.MethodPut, "/api/v1/webhooks/"+url.PathEscape(id), req, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
+
+// EliminarWebhook llama a DELETE /api/v1/webhooks/{id}
+func (c *Client) EliminarWebhook(ctx context.Context, id string) error {
+	return c.hacer(ctx, http.MethodDelete, "/api/v1/webhooks/"+url.PathEscape(id), nil, nil)
+}
+
+// ListarEntregasWebhook llama a GET /api/v1/webhooks/{id}/entregas; estado vacío = todas
+func (c *Client) ListarEntregasWebhook(ctx context.Context, id, estado string) ([]EntregaWebhook, error) {
+	var respuesta struct {
+		Data []EntregaWebhook `json:"data"`
+	}
+	ruta := "/api/v1/webhooks/" + url.PathEscape(id) + "/entregas"
+	if estado != "" {
+		ruta += "?estado=" + url.QueryEscape(estado)
+	}
+	if err := c.hacer(ctx, http.MethodGet, ruta, nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return respuesta.Data, nil
+}
+
+// ReenviarEntregaWebhook llama a POST /api/v1/webhooks/{id}/entregas/{idEntrega}/reenviar
+func (c *Client) ReenviarEntregaWebhook(ctx context.Context, id, idEntrega string) (*EntregaWebhook, error) {
+	var respuesta struct {
+		Data EntregaWebhook `json:"data"`
+	}
+	ruta := "/api/v1/webhooks/" + url.PathEscape(id) + "/entregas/" + url.PathEscape(idEntrega) + "/reenviar"
+	if err := c.hacer(ctx, http.MethodPost, ruta, nil, &respuesta); err != nil {
+		return nil, err
+	}
+	return &respuesta.Data, nil
+}
@@ -0,0 +1,2481 @@
+// API gRPC de MediSupply. Comparte servicios, autenticación, política de autorización y rate limiting
+// con la API REST; cada RPC se autoriza con la regla de su ruta REST equivalente.
+//
+// Credenciales en metadata: "x-api-key: <clave>" o "authorization: Bearer <jwt | msk_...>",
+// o un certificado de cliente verificado por mTLS.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: medisupply/v1/medisupply.proto
+
+package medisupplyv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransaccionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TipoEvento string `protobuf:"bytes,1,opt,name=tipo_evento,json=tipoEvento,proto3" json:"tipo_evento,omitempty"`
+	IdProducto string `protobuf:"bytes,2,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+	// Documento JSON serializado; se valida contra el esquema del tipo de evento.
+	DatosEvento string `protobuf:"bytes,3,opt,name=datos_evento,json=datosEvento,proto3" json:"datos_evento,omitempty"`
+	// Se reemplaza por el actor autenticado.
+	ActorEmisor string `protobuf:"bytes,4,opt,name=actor_emisor,json=actorEmisor,proto3" json:"actor_emisor,omitempty"`
+}
+
+func (x *TransaccionRequest) Reset() {
+	*x = TransaccionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransaccionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransaccionRequest) ProtoMessage() {}
+
+func (x *TransaccionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransaccionRequest.ProtoReflect.Descriptor instead.
+func (*TransaccionRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TransaccionRequest) GetTipoEvento() string {
+	if x != nil {
+		return x.TipoEvento
+	}
+	return ""
+}
+
+func (x *TransaccionRequest) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+func (x *TransaccionRequest) GetDatosEvento() string {
+	if x != nil {
+		return x.DatosEvento
+	}
+	return ""
+}
+
+func (x *TransaccionRequest) GetActorEmisor() string {
+	if x != nil {
+		return x.ActorEmisor
+	}
+	return ""
+}
+
+type RegistrarLoteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Eventos []*TransaccionRequest `protobuf:"bytes,1,rep,name=eventos,proto3" json:"eventos,omitempty"`
+}
+
+func (x *RegistrarLoteRequest) Reset() {
+	*x = RegistrarLoteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegistrarLoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistrarLoteRequest) ProtoMessage() {}
+
+func (x *RegistrarLoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistrarLoteRequest.ProtoReflect.Descriptor instead.
+func (*RegistrarLoteRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegistrarLoteRequest) GetEventos() []*TransaccionRequest {
+	if x != nil {
+		return x.Eventos
+	}
+	return nil
+}
+
+type Adjunto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cid        string   `protobuf:"bytes,1,opt,name=cid,proto3" json:"cid,omitempty"`
+	Nombre     string   `protobuf:"bytes,2,opt,name=nombre,proto3" json:"nombre,omitempty"`
+	TipoMime   string   `protobuf:"bytes,3,opt,name=tipo_mime,json=tipoMime,proto3" json:"tipo_mime,omitempty"`
+	Tamano     int64    `protobuf:"varint,4,opt,name=tamano,proto3" json:"tamano,omitempty"`
+	HashSha256 string   `protobuf:"bytes,5,opt,name=hash_sha256,json=hashSha256,proto3" json:"hash_sha256,omitempty"`
+	NodosIpfs  []string `protobuf:"bytes,6,rep,name=nodos_ipfs,json=nodosIpfs,proto3" json:"nodos_ipfs,omitempty"`
+}
+
+func (x *Adjunto) Reset() {
+	*x = Adjunto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Adjunto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Adjunto) ProtoMessage() {}
+
+func (x *Adjunto) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Adjunto.ProtoReflect.Descriptor instead.
+func (*Adjunto) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Adjunto) GetCid() string {
+	if x != nil {
+		return x.Cid
+	}
+	return ""
+}
+
+func (x *Adjunto) GetNombre() string {
+	if x != nil {
+		return x.Nombre
+	}
+	return ""
+}
+
+func (x *Adjunto) GetTipoMime() string {
+	if x != nil {
+		return x.TipoMime
+	}
+	return ""
+}
+
+func (x *Adjunto) GetTamano() int64 {
+	if x != nil {
+		return x.Tamano
+	}
+	return 0
+}
+
+func (x *Adjunto) GetHashSha256() string {
+	if x != nil {
+		return x.HashSha256
+	}
+	return ""
+}
+
+func (x *Adjunto) GetNodosIpfs() []string {
+	if x != nil {
+		return x.NodosIpfs
+	}
+	return nil
+}
+
+type Transaccion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdTransaction       string                 `protobuf:"bytes,1,opt,name=id_transaction,json=idTransaction,proto3" json:"id_transaction,omitempty"`
+	TipoEvento          string                 `protobuf:"bytes,2,opt,name=tipo_evento,json=tipoEvento,proto3" json:"tipo_evento,omitempty"`
+	IdProducto          string                 `protobuf:"bytes,3,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+	FechaEvento         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=fecha_evento,json=fechaEvento,proto3" json:"fecha_evento,omitempty"`
+	DatosEvento         string                 `protobuf:"bytes,5,opt,name=datos_evento,json=datosEvento,proto3" json:"datos_evento,omitempty"`
+	HashEvento          string                 `protobuf:"bytes,6,opt,name=hash_evento,json=hashEvento,proto3" json:"hash_evento,omitempty"`
+	HashVersion         int32                  `protobuf:"varint,7,opt,name=hash_version,json=hashVersion,proto3" json:"hash_version,omitempty"`
+	HashEventoAnterior  string                 `protobuf:"bytes,8,opt,name=hash_evento_anterior,json=hashEventoAnterior,proto3" json:"hash_evento_anterior,omitempty"`
+	Secuencia           int64                  `protobuf:"varint,9,opt,name=secuencia,proto3" json:"secuencia,omitempty"`
+	DirectionBlockchain string                 `protobuf:"bytes,10,opt,name=direction_blockchain,json=directionBlockchain,proto3" json:"direction_blockchain,omitempty"`
+	EthereumTxHash      string                 `protobuf:"bytes,11,opt,name=ethereum_tx_hash,json=ethereumTxHash,proto3" json:"ethereum_tx_hash,omitempty"`
+	IpfsCid             string                 `protobuf:"bytes,12,opt,name=ipfs_cid,json=ipfsCid,proto3" json:"ipfs_cid,omitempty"`
+	NodosIpfs           []string               `protobuf:"bytes,13,rep,name=nodos_ipfs,json=nodosIpfs,proto3" json:"nodos_ipfs,omitempty"`
+	ActorEmisor         string                 `protobuf:"bytes,14,opt,name=actor_emisor,json=actorEmisor,proto3" json:"actor_emisor,omitempty"`
+	Adjuntos            []*Adjunto             `protobuf:"bytes,15,rep,name=adjuntos,proto3" json:"adjuntos,omitempty"`
+	Estado              string                 `protobuf:"bytes,16,opt,name=estado,proto3" json:"estado,omitempty"`
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt           *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *Transaccion) Reset() {
+	*x = Transaccion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transaccion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaccion) ProtoMessage() {}
+
+func (x *Transaccion) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaccion.ProtoReflect.Descriptor instead.
+func (*Transaccion) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Transaccion) GetIdTransaction() string {
+	if x != nil {
+		return x.IdTransaction
+	}
+	return ""
+}
+
+func (x *Transaccion) GetTipoEvento() string {
+	if x != nil {
+		return x.TipoEvento
+	}
+	return ""
+}
+
+func (x *Transaccion) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+func (x *Transaccion) GetFechaEvento() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FechaEvento
+	}
+	return nil
+}
+
+func (x *Transaccion) GetDatosEvento() string {
+	if x != nil {
+		return x.DatosEvento
+	}
+	return ""
+}
+
+func (x *Transaccion) GetHashEvento() string {
+	if x != nil {
+		return x.HashEvento
+	}
+	return ""
+}
+
+func (x *Transaccion) GetHashVersion() int32 {
+	if x != nil {
+		return x.HashVersion
+	}
+	return 0
+}
+
+func (x *Transaccion) GetHashEventoAnterior() string {
+	if x != nil {
+		return x.HashEventoAnterior
+	}
+	return ""
+}
+
+func (x *Transaccion) GetSecuencia() int64 {
+	if x != nil {
+		return x.Secuencia
+	}
+	return 0
+}
+
+func (x *Transaccion) GetDirectionBlockchain() string {
+	if x != nil {
+		return x.DirectionBlockchain
+	}
+	return ""
+}
+
+func (x *Transaccion) GetEthereumTxHash() string {
+	if x != nil {
+		return x.EthereumTxHash
+	}
+	return ""
+}
+
+func (x *Transaccion) GetIpfsCid() string {
+	if x != nil {
+		return x.IpfsCid
+	}
+	return ""
+}
+
+func (x *Transaccion) GetNodosIpfs() []string {
+	if x != nil {
+		return x.NodosIpfs
+	}
+	return nil
+}
+
+func (x *Transaccion) GetActorEmisor() string {
+	if x != nil {
+		return x.ActorEmisor
+	}
+	return ""
+}
+
+func (x *Transaccion) GetAdjuntos() []*Adjunto {
+	if x != nil {
+		return x.Adjuntos
+	}
+	return nil
+}
+
+func (x *Transaccion) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+func (x *Transaccion) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Transaccion) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ResultadoLote struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Indice        int32  `protobuf:"varint,1,opt,name=indice,proto3" json:"indice,omitempty"`
+	Estado        string `protobuf:"bytes,2,opt,name=estado,proto3" json:"estado,omitempty"`
+	IdTransaction string `protobuf:"bytes,3,opt,name=id_transaction,json=idTransaction,proto3" json:"id_transaction,omitempty"`
+	IdProducto    string `protobuf:"bytes,4,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+	HashEvento    string `protobuf:"bytes,5,opt,name=hash_evento,json=hashEvento,proto3" json:"hash_evento,omitempty"`
+	IpfsCid       string `protobuf:"bytes,6,opt,name=ipfs_cid,json=ipfsCid,proto3" json:"ipfs_cid,omitempty"`
+	Secuencia     int64  `protobuf:"varint,7,opt,name=secuencia,proto3" json:"secuencia,omitempty"`
+	Error         string `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *ResultadoLote) Reset() {
+	*x = ResultadoLote{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResultadoLote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultadoLote) ProtoMessage() {}
+
+func (x *ResultadoLote) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultadoLote.ProtoReflect.Descriptor instead.
+func (*ResultadoLote) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResultadoLote) GetIndice() int32 {
+	if x != nil {
+		return x.Indice
+	}
+	return 0
+}
+
+func (x *ResultadoLote) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+func (x *ResultadoLote) GetIdTransaction() string {
+	if x != nil {
+		return x.IdTransaction
+	}
+	return ""
+}
+
+func (x *ResultadoLote) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+func (x *ResultadoLote) GetHashEvento() string {
+	if x != nil {
+		return x.HashEvento
+	}
+	return ""
+}
+
+func (x *ResultadoLote) GetIpfsCid() string {
+	if x != nil {
+		return x.IpfsCid
+	}
+	return ""
+}
+
+func (x *ResultadoLote) GetSecuencia() int64 {
+	if x != nil {
+		return x.Secuencia
+	}
+	return 0
+}
+
+func (x *ResultadoLote) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type LoteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Total       int32            `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Registradas int32            `protobuf:"varint,2,opt,name=registradas,proto3" json:"registradas,omitempty"`
+	Fallidas    int32            `protobuf:"varint,3,opt,name=fallidas,proto3" json:"fallidas,omitempty"`
+	Resultados  []*ResultadoLote `protobuf:"bytes,4,rep,name=resultados,proto3" json:"resultados,omitempty"`
+}
+
+func (x *LoteResponse) Reset() {
+	*x = LoteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoteResponse) ProtoMessage() {}
+
+func (x *LoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoteResponse.ProtoReflect.Descriptor instead.
+func (*LoteResponse) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LoteResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *LoteResponse) GetRegistradas() int32 {
+	if x != nil {
+		return x.Registradas
+	}
+	return 0
+}
+
+func (x *LoteResponse) GetFallidas() int32 {
+	if x != nil {
+		return x.Fallidas
+	}
+	return 0
+}
+
+func (x *LoteResponse) GetResultados() []*ResultadoLote {
+	if x != nil {
+		return x.Resultados
+	}
+	return nil
+}
+
+type ObtenerTransaccionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdTransaction string `protobuf:"bytes,1,opt,name=id_transaction,json=idTransaction,proto3" json:"id_transaction,omitempty"`
+}
+
+func (x *ObtenerTransaccionRequest) Reset() {
+	*x = ObtenerTransaccionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObtenerTransaccionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObtenerTransaccionRequest) ProtoMessage() {}
+
+func (x *ObtenerTransaccionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObtenerTransaccionRequest.ProtoReflect.Descriptor instead.
+func (*ObtenerTransaccionRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ObtenerTransaccionRequest) GetIdTransaction() string {
+	if x != nil {
+		return x.IdTransaction
+	}
+	return ""
+}
+
+type ListarTransaccionesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 0 usa el valor por defecto (50).
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *ListarTransaccionesRequest) Reset() {
+	*x = ListarTransaccionesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListarTransaccionesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListarTransaccionesRequest) ProtoMessage() {}
+
+func (x *ListarTransaccionesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListarTransaccionesRequest.ProtoReflect.Descriptor instead.
+func (*ListarTransaccionesRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListarTransaccionesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ProductoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdProducto string `protobuf:"bytes,1,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+}
+
+func (x *ProductoRequest) Reset() {
+	*x = ProductoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProductoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductoRequest) ProtoMessage() {}
+
+func (x *ProductoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductoRequest.ProtoReflect.Descriptor instead.
+func (*ProductoRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ProductoRequest) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+type ListaTransacciones struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Total         int32          `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Transacciones []*Transaccion `protobuf:"bytes,2,rep,name=transacciones,proto3" json:"transacciones,omitempty"`
+}
+
+func (x *ListaTransacciones) Reset() {
+	*x = ListaTransacciones{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListaTransacciones) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListaTransacciones) ProtoMessage() {}
+
+func (x *ListaTransacciones) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListaTransacciones.ProtoReflect.Descriptor instead.
+func (*ListaTransacciones) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListaTransacciones) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListaTransacciones) GetTransacciones() []*Transaccion {
+	if x != nil {
+		return x.Transacciones
+	}
+	return nil
+}
+
+type VerificacionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdTransaction        string `protobuf:"bytes,1,opt,name=id_transaction,json=idTransaction,proto3" json:"id_transaction,omitempty"`
+	Verificado           bool   `protobuf:"varint,2,opt,name=verificado,proto3" json:"verificado,omitempty"`
+	HashLocal            string `protobuf:"bytes,3,opt,name=hash_local,json=hashLocal,proto3" json:"hash_local,omitempty"`
+	HashBlockchain       string `protobuf:"bytes,4,opt,name=hash_blockchain,json=hashBlockchain,proto3" json:"hash_blockchain,omitempty"`
+	DatosIpfsVerificados bool   `protobuf:"varint,5,opt,name=datos_ipfs_verificados,json=datosIpfsVerificados,proto3" json:"datos_ipfs_verificados,omitempty"`
+	AdjuntosVerificados  bool   `protobuf:"varint,6,opt,name=adjuntos_verificados,json=adjuntosVerificados,proto3" json:"adjuntos_verificados,omitempty"`
+	Mensaje              string `protobuf:"bytes,7,opt,name=mensaje,proto3" json:"mensaje,omitempty"`
+}
+
+func (x *VerificacionResponse) Reset() {
+	*x = VerificacionResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerificacionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificacionResponse) ProtoMessage() {}
+
+func (x *VerificacionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificacionResponse.ProtoReflect.Descriptor instead.
+func (*VerificacionResponse) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *VerificacionResponse) GetIdTransaction() string {
+	if x != nil {
+		return x.IdTransaction
+	}
+	return ""
+}
+
+func (x *VerificacionResponse) GetVerificado() bool {
+	if x != nil {
+		return x.Verificado
+	}
+	return false
+}
+
+func (x *VerificacionResponse) GetHashLocal() string {
+	if x != nil {
+		return x.HashLocal
+	}
+	return ""
+}
+
+func (x *VerificacionResponse) GetHashBlockchain() string {
+	if x != nil {
+		return x.HashBlockchain
+	}
+	return ""
+}
+
+func (x *VerificacionResponse) GetDatosIpfsVerificados() bool {
+	if x != nil {
+		return x.DatosIpfsVerificados
+	}
+	return false
+}
+
+func (x *VerificacionResponse) GetAdjuntosVerificados() bool {
+	if x != nil {
+		return x.AdjuntosVerificados
+	}
+	return false
+}
+
+func (x *VerificacionResponse) GetMensaje() string {
+	if x != nil {
+		return x.Mensaje
+	}
+	return ""
+}
+
+type EstadoBlockchain struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdTransaction          string `protobuf:"bytes,1,opt,name=id_transaction,json=idTransaction,proto3" json:"id_transaction,omitempty"`
+	Estado                 string `protobuf:"bytes,2,opt,name=estado,proto3" json:"estado,omitempty"`
+	RegistradoEnBlockchain bool   `protobuf:"varint,3,opt,name=registrado_en_blockchain,json=registradoEnBlockchain,proto3" json:"registrado_en_blockchain,omitempty"`
+	DirectionBlockchain    string `protobuf:"bytes,4,opt,name=direction_blockchain,json=directionBlockchain,proto3" json:"direction_blockchain,omitempty"`
+	EthereumTxHash         string `protobuf:"bytes,5,opt,name=ethereum_tx_hash,json=ethereumTxHash,proto3" json:"ethereum_tx_hash,omitempty"`
+	Mensaje                string `protobuf:"bytes,6,opt,name=mensaje,proto3" json:"mensaje,omitempty"`
+	Timestamp              string `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *EstadoBlockchain) Reset() {
+	*x = EstadoBlockchain{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EstadoBlockchain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstadoBlockchain) ProtoMessage() {}
+
+func (x *EstadoBlockchain) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstadoBlockchain.ProtoReflect.Descriptor instead.
+func (*EstadoBlockchain) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *EstadoBlockchain) GetIdTransaction() string {
+	if x != nil {
+		return x.IdTransaction
+	}
+	return ""
+}
+
+func (x *EstadoBlockchain) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+func (x *EstadoBlockchain) GetRegistradoEnBlockchain() bool {
+	if x != nil {
+		return x.RegistradoEnBlockchain
+	}
+	return false
+}
+
+func (x *EstadoBlockchain) GetDirectionBlockchain() string {
+	if x != nil {
+		return x.DirectionBlockchain
+	}
+	return ""
+}
+
+func (x *EstadoBlockchain) GetEthereumTxHash() string {
+	if x != nil {
+		return x.EthereumTxHash
+	}
+	return ""
+}
+
+func (x *EstadoBlockchain) GetMensaje() string {
+	if x != nil {
+		return x.Mensaje
+	}
+	return ""
+}
+
+func (x *EstadoBlockchain) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+type EventoVerificado struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdEvento              string                 `protobuf:"bytes,1,opt,name=id_evento,json=idEvento,proto3" json:"id_evento,omitempty"`
+	TipoEvento            string                 `protobuf:"bytes,2,opt,name=tipo_evento,json=tipoEvento,proto3" json:"tipo_evento,omitempty"`
+	Fecha                 *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=fecha,proto3" json:"fecha,omitempty"`
+	ResultadoVerificacion bool                   `protobuf:"varint,4,opt,name=resultado_verificacion,json=resultadoVerificacion,proto3" json:"resultado_verificacion,omitempty"`
+	ReferenciaBlockchain  string                 `protobuf:"bytes,5,opt,name=referencia_blockchain,json=referenciaBlockchain,proto3" json:"referencia_blockchain,omitempty"`
+	IpfsCid               string                 `protobuf:"bytes,6,opt,name=ipfs_cid,json=ipfsCid,proto3" json:"ipfs_cid,omitempty"`
+	ActorEmisor           string                 `protobuf:"bytes,7,opt,name=actor_emisor,json=actorEmisor,proto3" json:"actor_emisor,omitempty"`
+	Secuencia             int64                  `protobuf:"varint,8,opt,name=secuencia,proto3" json:"secuencia,omitempty"`
+	HashEventoAnterior    string                 `protobuf:"bytes,9,opt,name=hash_evento_anterior,json=hashEventoAnterior,proto3" json:"hash_evento_anterior,omitempty"`
+	ErrorVerificacion     string                 `protobuf:"bytes,10,opt,name=error_verificacion,json=errorVerificacion,proto3" json:"error_verificacion,omitempty"`
+}
+
+func (x *EventoVerificado) Reset() {
+	*x = EventoVerificado{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventoVerificado) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventoVerificado) ProtoMessage() {}
+
+func (x *EventoVerificado) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventoVerificado.ProtoReflect.Descriptor instead.
+func (*EventoVerificado) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *EventoVerificado) GetIdEvento() string {
+	if x != nil {
+		return x.IdEvento
+	}
+	return ""
+}
+
+func (x *EventoVerificado) GetTipoEvento() string {
+	if x != nil {
+		return x.TipoEvento
+	}
+	return ""
+}
+
+func (x *EventoVerificado) GetFecha() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Fecha
+	}
+	return nil
+}
+
+func (x *EventoVerificado) GetResultadoVerificacion() bool {
+	if x != nil {
+		return x.ResultadoVerificacion
+	}
+	return false
+}
+
+func (x *EventoVerificado) GetReferenciaBlockchain() string {
+	if x != nil {
+		return x.ReferenciaBlockchain
+	}
+	return ""
+}
+
+func (x *EventoVerificado) GetIpfsCid() string {
+	if x != nil {
+		return x.IpfsCid
+	}
+	return ""
+}
+
+func (x *EventoVerificado) GetActorEmisor() string {
+	if x != nil {
+		return x.ActorEmisor
+	}
+	return ""
+}
+
+func (x *EventoVerificado) GetSecuencia() int64 {
+	if x != nil {
+		return x.Secuencia
+	}
+	return 0
+}
+
+func (x *EventoVerificado) GetHashEventoAnterior() string {
+	if x != nil {
+		return x.HashEventoAnterior
+	}
+	return ""
+}
+
+func (x *EventoVerificado) GetErrorVerificacion() string {
+	if x != nil {
+		return x.ErrorVerificacion
+	}
+	return ""
+}
+
+type AnomaliaCadena struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tipo      string `protobuf:"bytes,1,opt,name=tipo,proto3" json:"tipo,omitempty"`
+	Secuencia int64  `protobuf:"varint,2,opt,name=secuencia,proto3" json:"secuencia,omitempty"`
+	IdEvento  string `protobuf:"bytes,3,opt,name=id_evento,json=idEvento,proto3" json:"id_evento,omitempty"`
+	Detalle   string `protobuf:"bytes,4,opt,name=detalle,proto3" json:"detalle,omitempty"`
+}
+
+func (x *AnomaliaCadena) Reset() {
+	*x = AnomaliaCadena{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnomaliaCadena) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomaliaCadena) ProtoMessage() {}
+
+func (x *AnomaliaCadena) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomaliaCadena.ProtoReflect.Descriptor instead.
+func (*AnomaliaCadena) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AnomaliaCadena) GetTipo() string {
+	if x != nil {
+		return x.Tipo
+	}
+	return ""
+}
+
+func (x *AnomaliaCadena) GetSecuencia() int64 {
+	if x != nil {
+		return x.Secuencia
+	}
+	return 0
+}
+
+func (x *AnomaliaCadena) GetIdEvento() string {
+	if x != nil {
+		return x.IdEvento
+	}
+	return ""
+}
+
+func (x *AnomaliaCadena) GetDetalle() string {
+	if x != nil {
+		return x.Detalle
+	}
+	return ""
+}
+
+type VerificacionCadena struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Integra             bool              `protobuf:"varint,1,opt,name=integra,proto3" json:"integra,omitempty"`
+	Longitud            int64             `protobuf:"varint,2,opt,name=longitud,proto3" json:"longitud,omitempty"`
+	EventosSinEncadenar int32             `protobuf:"varint,3,opt,name=eventos_sin_encadenar,json=eventosSinEncadenar,proto3" json:"eventos_sin_encadenar,omitempty"`
+	Anomalias           []*AnomaliaCadena `protobuf:"bytes,4,rep,name=anomalias,proto3" json:"anomalias,omitempty"`
+}
+
+func (x *VerificacionCadena) Reset() {
+	*x = VerificacionCadena{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerificacionCadena) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificacionCadena) ProtoMessage() {}
+
+func (x *VerificacionCadena) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificacionCadena.ProtoReflect.Descriptor instead.
+func (*VerificacionCadena) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *VerificacionCadena) GetIntegra() bool {
+	if x != nil {
+		return x.Integra
+	}
+	return false
+}
+
+func (x *VerificacionCadena) GetLongitud() int64 {
+	if x != nil {
+		return x.Longitud
+	}
+	return 0
+}
+
+func (x *VerificacionCadena) GetEventosSinEncadenar() int32 {
+	if x != nil {
+		return x.EventosSinEncadenar
+	}
+	return 0
+}
+
+func (x *VerificacionCadena) GetAnomalias() []*AnomaliaCadena {
+	if x != nil {
+		return x.Anomalias
+	}
+	return nil
+}
+
+// CabeceraHistorial abre el stream del historial.
+type CabeceraHistorial struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdProducto   string `protobuf:"bytes,1,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+	TotalEventos int32  `protobuf:"varint,2,opt,name=total_eventos,json=totalEventos,proto3" json:"total_eventos,omitempty"`
+	// Ausente si el producto no tiene eventos encadenados.
+	Cadena *VerificacionCadena `protobuf:"bytes,3,opt,name=cadena,proto3" json:"cadena,omitempty"`
+}
+
+func (x *CabeceraHistorial) Reset() {
+	*x = CabeceraHistorial{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CabeceraHistorial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CabeceraHistorial) ProtoMessage() {}
+
+func (x *CabeceraHistorial) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CabeceraHistorial.ProtoReflect.Descriptor instead.
+func (*CabeceraHistorial) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CabeceraHistorial) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+func (x *CabeceraHistorial) GetTotalEventos() int32 {
+	if x != nil {
+		return x.TotalEventos
+	}
+	return 0
+}
+
+func (x *CabeceraHistorial) GetCadena() *VerificacionCadena {
+	if x != nil {
+		return x.Cadena
+	}
+	return nil
+}
+
+type HistorialVerificado struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Contenido:
+	//	*HistorialVerificado_Cabecera
+	//	*HistorialVerificado_Evento
+	Contenido isHistorialVerificado_Contenido `protobuf_oneof:"contenido"`
+}
+
+func (x *HistorialVerificado) Reset() {
+	*x = HistorialVerificado{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HistorialVerificado) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistorialVerificado) ProtoMessage() {}
+
+func (x *HistorialVerificado) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistorialVerificado.ProtoReflect.Descriptor instead.
+func (*HistorialVerificado) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{16}
+}
+
+func (m *HistorialVerificado) GetContenido() isHistorialVerificado_Contenido {
+	if m != nil {
+		return m.Contenido
+	}
+	return nil
+}
+
+func (x *HistorialVerificado) GetCabecera() *CabeceraHistorial {
+	if x, ok := x.GetContenido().(*HistorialVerificado_Cabecera); ok {
+		return x.Cabecera
+	}
+	return nil
+}
+
+func (x *HistorialVerificado) GetEvento() *EventoVerificado {
+	if x, ok := x.GetContenido().(*HistorialVerificado_Evento); ok {
+		return x.Evento
+	}
+	return nil
+}
+
+type isHistorialVerificado_Contenido interface {
+	isHistorialVerificado_Contenido()
+}
+
+type HistorialVerificado_Cabecera struct {
+	Cabecera *CabeceraHistorial `protobuf:"bytes,1,opt,name=cabecera,proto3,oneof"`
+}
+
+type HistorialVerificado_Evento struct {
+	Evento *EventoVerificado `protobuf:"bytes,2,opt,name=evento,proto3,oneof"`
+}
+
+func (*HistorialVerificado_Cabecera) isHistorialVerificado_Contenido() {}
+
+func (*HistorialVerificado_Evento) isHistorialVerificado_Contenido() {}
+
+type DatosVerificados struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdProducto          string                 `protobuf:"bytes,1,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+	Estado              string                 `protobuf:"bytes,2,opt,name=estado,proto3" json:"estado,omitempty"`
+	UltimaActualizacion *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=ultima_actualizacion,json=ultimaActualizacion,proto3" json:"ultima_actualizacion,omitempty"`
+	CadenaVerificada    bool                   `protobuf:"varint,4,opt,name=cadena_verificada,json=cadenaVerificada,proto3" json:"cadena_verificada,omitempty"`
+	Historial           []*EventoVerificado    `protobuf:"bytes,5,rep,name=historial,proto3" json:"historial,omitempty"`
+	Metadata            map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *DatosVerificados) Reset() {
+	*x = DatosVerificados{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DatosVerificados) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatosVerificados) ProtoMessage() {}
+
+func (x *DatosVerificados) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatosVerificados.ProtoReflect.Descriptor instead.
+func (*DatosVerificados) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DatosVerificados) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+func (x *DatosVerificados) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+func (x *DatosVerificados) GetUltimaActualizacion() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UltimaActualizacion
+	}
+	return nil
+}
+
+func (x *DatosVerificados) GetCadenaVerificada() bool {
+	if x != nil {
+		return x.CadenaVerificada
+	}
+	return false
+}
+
+func (x *DatosVerificados) GetHistorial() []*EventoVerificado {
+	if x != nil {
+		return x.Historial
+	}
+	return nil
+}
+
+func (x *DatosVerificados) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ValidacionCadena struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdProducto        string   `protobuf:"bytes,1,opt,name=id_producto,json=idProducto,proto3" json:"id_producto,omitempty"`
+	CadenaValida      bool     `protobuf:"varint,2,opt,name=cadena_valida,json=cadenaValida,proto3" json:"cadena_valida,omitempty"`
+	ErroresDetectados []string `protobuf:"bytes,3,rep,name=errores_detectados,json=erroresDetectados,proto3" json:"errores_detectados,omitempty"`
+}
+
+func (x *ValidacionCadena) Reset() {
+	*x = ValidacionCadena{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidacionCadena) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidacionCadena) ProtoMessage() {}
+
+func (x *ValidacionCadena) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidacionCadena.ProtoReflect.Descriptor instead.
+func (*ValidacionCadena) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ValidacionCadena) GetIdProducto() string {
+	if x != nil {
+		return x.IdProducto
+	}
+	return ""
+}
+
+func (x *ValidacionCadena) GetCadenaValida() bool {
+	if x != nil {
+		return x.CadenaValida
+	}
+	return false
+}
+
+func (x *ValidacionCadena) GetErroresDetectados() []string {
+	if x != nil {
+		return x.ErroresDetectados
+	}
+	return nil
+}
+
+type ObtenerArchivoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cid string `protobuf:"bytes,1,opt,name=cid,proto3" json:"cid,omitempty"`
+}
+
+func (x *ObtenerArchivoRequest) Reset() {
+	*x = ObtenerArchivoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObtenerArchivoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObtenerArchivoRequest) ProtoMessage() {}
+
+func (x *ObtenerArchivoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObtenerArchivoRequest.ProtoReflect.Descriptor instead.
+func (*ObtenerArchivoRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ObtenerArchivoRequest) GetCid() string {
+	if x != nil {
+		return x.Cid
+	}
+	return ""
+}
+
+type Archivo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cid string `protobuf:"bytes,1,opt,name=cid,proto3" json:"cid,omitempty"`
+	// Documento JSON almacenado en IPFS.
+	Datos string `protobuf:"bytes,2,opt,name=datos,proto3" json:"datos,omitempty"`
+}
+
+func (x *Archivo) Reset() {
+	*x = Archivo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Archivo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Archivo) ProtoMessage() {}
+
+func (x *Archivo) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Archivo.ProtoReflect.Descriptor instead.
+func (*Archivo) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Archivo) GetCid() string {
+	if x != nil {
+		return x.Cid
+	}
+	return ""
+}
+
+func (x *Archivo) GetDatos() string {
+	if x != nil {
+		return x.Datos
+	}
+	return ""
+}
+
+type ObtenerEstadisticasRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ObtenerEstadisticasRequest) Reset() {
+	*x = ObtenerEstadisticasRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObtenerEstadisticasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObtenerEstadisticasRequest) ProtoMessage() {}
+
+func (x *ObtenerEstadisticasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObtenerEstadisticasRequest.ProtoReflect.Descriptor instead.
+func (*ObtenerEstadisticasRequest) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{21}
+}
+
+type EstadisticasIPFS struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Disponible        bool  `protobuf:"varint,1,opt,name=disponible,proto3" json:"disponible,omitempty"`
+	FactorReplicacion int32 `protobuf:"varint,2,opt,name=factor_replicacion,json=factorReplicacion,proto3" json:"factor_replicacion,omitempty"`
+}
+
+func (x *EstadisticasIPFS) Reset() {
+	*x = EstadisticasIPFS{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_medisupply_v1_medisupply_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EstadisticasIPFS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstadisticasIPFS) ProtoMessage() {}
+
+func (x *EstadisticasIPFS) ProtoReflect() protoreflect.Message {
+	mi := &file_medisupply_v1_medisupply_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstadisticasIPFS.ProtoReflect.Descriptor instead.
+func (*EstadisticasIPFS) Descriptor() ([]byte, []int) {
+	return file_medisupply_v1_medisupply_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *EstadisticasIPFS) GetDisponible() bool {
+	if x != nil {
+		return x.Disponible
+	}
+	return false
+}
+
+func (x *EstadisticasIPFS) GetFactorReplicacion() int32 {
+	if x != nil {
+		return x.FactorReplicacion
+	}
+	return 0
+}
+
+var File_medisupply_v1_medisupply_proto protoreflect.FileDescriptor
+
+var file_medisupply_v1_medisupply_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2f, 0x76, 0x31, 0x2f,
+	0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0d, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x9c, 0x01, 0x0a, 0x12, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x69, 0x70, 0x6f, 0x5f,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x69,
+	0x70, 0x6f, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x70,
+	0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69,
+	0x64, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74,
+	0x6f, 0x73, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x61, 0x74, 0x6f, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c,
+	0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x65, 0x6d, 0x69, 0x73, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x45, 0x6d, 0x69, 0x73, 0x6f, 0x72, 0x22,
+	0x53, 0x0a, 0x14, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x72, 0x4c, 0x6f, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x07, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73,
+	0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x63, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x6f, 0x73, 0x22, 0xa8, 0x01, 0x0a, 0x07, 0x41, 0x64, 0x6a, 0x75, 0x6e, 0x74, 0x6f,
+	0x12, 0x10, 0x0a, 0x03, 0x63, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63,
+	0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69,
+	0x70, 0x6f, 0x5f, 0x6d, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74,
+	0x69, 0x70, 0x6f, 0x4d, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x6d, 0x61, 0x6e,
+	0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x6d, 0x61, 0x6e, 0x6f, 0x12,
+	0x1f, 0x0a, 0x0b, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x53, 0x68, 0x61, 0x32, 0x35, 0x36,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x6f, 0x73, 0x5f, 0x69, 0x70, 0x66, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x6f, 0x73, 0x49, 0x70, 0x66, 0x73, 0x22,
+	0xe8, 0x05, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x12,
+	0x25, 0x0a, 0x0e, 0x69, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x69, 0x70, 0x6f, 0x5f, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x69, 0x70,
+	0x6f, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x70, 0x72,
+	0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64,
+	0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x12, 0x3d, 0x0a, 0x0c, 0x66, 0x65, 0x63, 0x68,
+	0x61, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x66, 0x65, 0x63, 0x68,
+	0x61, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x6f, 0x73,
+	0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x61, 0x74, 0x6f, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x61,
+	0x73, 0x68, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x68, 0x61, 0x73, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x68,
+	0x61, 0x73, 0x68, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x68, 0x61, 0x73, 0x68, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x30,
+	0x0a, 0x14, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x5f, 0x61, 0x6e,
+	0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x68, 0x61,
+	0x73, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x12, 0x31,
+	0x0a, 0x14, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x12, 0x28, 0x0a, 0x10, 0x65, 0x74, 0x68, 0x65, 0x72, 0x65, 0x75, 0x6d, 0x5f, 0x74, 0x78,
+	0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x65, 0x74, 0x68,
+	0x65, 0x72, 0x65, 0x75, 0x6d, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x69,
+	0x70, 0x66, 0x73, 0x5f, 0x63, 0x69, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69,
+	0x70, 0x66, 0x73, 0x43, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x6f, 0x73, 0x5f,
+	0x69, 0x70, 0x66, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x6f,
+	0x73, 0x49, 0x70, 0x66, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x65,
+	0x6d, 0x69, 0x73, 0x6f, 0x72, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x74,
+	0x6f, 0x72, 0x45, 0x6d, 0x69, 0x73, 0x6f, 0x72, 0x12, 0x32, 0x0a, 0x08, 0x61, 0x64, 0x6a, 0x75,
+	0x6e, 0x74, 0x6f, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x65, 0x64,
+	0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x6a, 0x75, 0x6e,
+	0x74, 0x6f, 0x52, 0x08, 0x61, 0x64, 0x6a, 0x75, 0x6e, 0x74, 0x6f, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73,
+	0x74, 0x61, 0x64, 0x6f, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xf7, 0x01, 0x0a, 0x0d, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x4c, 0x6f, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x69, 0x6e,
+	0x64, 0x69, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x25, 0x0a, 0x0e,
+	0x69, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63,
+	0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x50, 0x72, 0x6f, 0x64,
+	0x75, 0x63, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x70, 0x66, 0x73, 0x5f, 0x63, 0x69,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x70, 0x66, 0x73, 0x43, 0x69, 0x64,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x22, 0xa0, 0x01, 0x0a, 0x0c, 0x4c, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x72,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x64, 0x61, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0b, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x64, 0x61, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x66, 0x61, 0x6c, 0x6c, 0x69, 0x64, 0x61, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x66, 0x61, 0x6c, 0x6c, 0x69, 0x64, 0x61, 0x73, 0x12, 0x3c, 0x0a, 0x0a, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x4c, 0x6f, 0x74, 0x65, 0x52, 0x0a, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x73, 0x22, 0x42, 0x0a, 0x19, 0x4f, 0x62, 0x74, 0x65, 0x6e,
+	0x65, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x64,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x32, 0x0a, 0x1a, 0x4c,
+	0x69, 0x73, 0x74, 0x61, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22,
+	0x32, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x50, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x6f, 0x22, 0x6c, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12,
+	0x40, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70,
+	0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69,
+	0x6f, 0x6e, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65,
+	0x73, 0x22, 0xa8, 0x02, 0x0a, 0x14, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x64,
+	0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x69, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64,
+	0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x68, 0x61, 0x73, 0x68, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
+	0x12, 0x27, 0x0a, 0x0f, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x68, 0x61, 0x73, 0x68, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x34, 0x0a, 0x16, 0x64, 0x61, 0x74,
+	0x6f, 0x73, 0x5f, 0x69, 0x70, 0x66, 0x73, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x64, 0x6f, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x64, 0x61, 0x74, 0x6f, 0x73,
+	0x49, 0x70, 0x66, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x73, 0x12,
+	0x31, 0x0a, 0x14, 0x61, 0x64, 0x6a, 0x75, 0x6e, 0x74, 0x6f, 0x73, 0x5f, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x61,
+	0x64, 0x6a, 0x75, 0x6e, 0x74, 0x6f, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64,
+	0x6f, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x6e, 0x73, 0x61, 0x6a, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x6e, 0x73, 0x61, 0x6a, 0x65, 0x22, 0xa0, 0x02, 0x0a,
+	0x10, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x64, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61,
+	0x64, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f,
+	0x12, 0x38, 0x0a, 0x18, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x64, 0x6f, 0x5f, 0x65,
+	0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x16, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x64, 0x6f, 0x45, 0x6e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x31, 0x0a, 0x14, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61,
+	0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x28, 0x0a,
+	0x10, 0x65, 0x74, 0x68, 0x65, 0x72, 0x65, 0x75, 0x6d, 0x5f, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x65, 0x74, 0x68, 0x65, 0x72, 0x65, 0x75,
+	0x6d, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x6e, 0x73, 0x61,
+	0x6a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x6e, 0x73, 0x61, 0x6a,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22,
+	0xab, 0x03, 0x0a, 0x10, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x64, 0x6f, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x64, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x69, 0x70, 0x6f, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x69, 0x70, 0x6f, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x6f, 0x12, 0x30, 0x0a, 0x05, 0x66, 0x65, 0x63, 0x68, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x66,
+	0x65, 0x63, 0x68, 0x61, 0x12, 0x35, 0x0a, 0x16, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64,
+	0x6f, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x15, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x15, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63,
+	0x68, 0x61, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e,
+	0x12, 0x19, 0x0a, 0x08, 0x69, 0x70, 0x66, 0x73, 0x5f, 0x63, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x69, 0x70, 0x66, 0x73, 0x43, 0x69, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x61,
+	0x63, 0x74, 0x6f, 0x72, 0x5f, 0x65, 0x6d, 0x69, 0x73, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x45, 0x6d, 0x69, 0x73, 0x6f, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x12, 0x30, 0x0a, 0x14,
+	0x68, 0x61, 0x73, 0x68, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x5f, 0x61, 0x6e, 0x74, 0x65,
+	0x72, 0x69, 0x6f, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x68, 0x61, 0x73, 0x68,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x2d,
+	0x0a, 0x12, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x63, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x22, 0x79, 0x0a,
+	0x0e, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x61, 0x43, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x69, 0x70, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x69, 0x70, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69,
+	0x61, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x64, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x18,
+	0x0a, 0x07, 0x64, 0x65, 0x74, 0x61, 0x6c, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x64, 0x65, 0x74, 0x61, 0x6c, 0x6c, 0x65, 0x22, 0xbb, 0x01, 0x0a, 0x12, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x12,
+	0x18, 0x0a, 0x07, 0x69, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x69, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x6e,
+	0x67, 0x69, 0x74, 0x75, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x6f, 0x6e,
+	0x67, 0x69, 0x74, 0x75, 0x64, 0x12, 0x32, 0x0a, 0x15, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x73,
+	0x5f, 0x73, 0x69, 0x6e, 0x5f, 0x65, 0x6e, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x13, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x73, 0x53, 0x69, 0x6e,
+	0x45, 0x6e, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x72, 0x12, 0x3b, 0x0a, 0x09, 0x61, 0x6e, 0x6f,
+	0x6d, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d,
+	0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6f,
+	0x6d, 0x61, 0x6c, 0x69, 0x61, 0x43, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x52, 0x09, 0x61, 0x6e, 0x6f,
+	0x6d, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0x94, 0x01, 0x0a, 0x11, 0x43, 0x61, 0x62, 0x65, 0x63,
+	0x65, 0x72, 0x61, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0b,
+	0x69, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x69, 0x64, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x12, 0x23, 0x0a,
+	0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x73, 0x12, 0x39, 0x0a, 0x06, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x43,
+	0x61, 0x64, 0x65, 0x6e, 0x61, 0x52, 0x06, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x22, 0x9d, 0x01,
+	0x0a, 0x13, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x61, 0x6c, 0x56, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x64, 0x6f, 0x12, 0x3e, 0x0a, 0x08, 0x63, 0x61, 0x62, 0x65, 0x63, 0x65, 0x72,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75,
+	0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x62, 0x65, 0x63, 0x65, 0x72, 0x61,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x63, 0x61, 0x62,
+	0x65, 0x63, 0x65, 0x72, 0x61, 0x12, 0x39, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70,
+	0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x48, 0x00, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x6f,
+	0x42, 0x0b, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x69, 0x64, 0x6f, 0x22, 0x8e, 0x03,
+	0x0a, 0x10, 0x44, 0x61, 0x74, 0x6f, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64,
+	0x6f, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x50, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x4d, 0x0a, 0x14, 0x75,
+	0x6c, 0x74, 0x69, 0x6d, 0x61, 0x5f, 0x61, 0x63, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x13, 0x75, 0x6c, 0x74, 0x69, 0x6d, 0x61, 0x41, 0x63, 0x74,
+	0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x61,
+	0x64, 0x65, 0x6e, 0x61, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x61, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x61, 0x12, 0x3d, 0x0a, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x69, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6d, 0x65, 0x64,
+	0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x52, 0x09, 0x68, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x69, 0x61, 0x6c, 0x12, 0x49, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73,
+	0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61, 0x74, 0x6f, 0x73, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x73, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x87,
+	0x01, 0x0a, 0x10, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x64,
+	0x65, 0x6e, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63,
+	0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x50, 0x72, 0x6f, 0x64,
+	0x75, 0x63, 0x74, 0x6f, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x5f, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x63, 0x61, 0x64,
+	0x65, 0x6e, 0x61, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x12, 0x2d, 0x0a, 0x12, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x65, 0x73, 0x5f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x61, 0x64, 0x6f, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x65, 0x73, 0x44, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x61, 0x64, 0x6f, 0x73, 0x22, 0x29, 0x0a, 0x15, 0x4f, 0x62, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x63, 0x69, 0x64, 0x22, 0x31, 0x0a, 0x07, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x6f, 0x12, 0x10,
+	0x0a, 0x03, 0x63, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x69, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x64, 0x61, 0x74, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x64, 0x61, 0x74, 0x6f, 0x73, 0x22, 0x1c, 0x0a, 0x1a, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65,
+	0x72, 0x45, 0x73, 0x74, 0x61, 0x64, 0x69, 0x73, 0x74, 0x69, 0x63, 0x61, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x61, 0x0a, 0x10, 0x45, 0x73, 0x74, 0x61, 0x64, 0x69, 0x73, 0x74,
+	0x69, 0x63, 0x61, 0x73, 0x49, 0x50, 0x46, 0x53, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x73, 0x70,
+	0x6f, 0x6e, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x69,
+	0x73, 0x70, 0x6f, 0x6e, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x66, 0x61, 0x63, 0x74,
+	0x6f, 0x72, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x66, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x32, 0xe0, 0x03, 0x0a, 0x0d, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x55, 0x0a, 0x14, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x61, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f,
+	0x6e, 0x12, 0x21, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e,
+	0x12, 0x51, 0x0a, 0x0d, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x72, 0x4c, 0x6f, 0x74,
+	0x65, 0x12, 0x23, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x72, 0x4c, 0x6f, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70,
+	0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x12, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x2e, 0x6d, 0x65, 0x64, 0x69,
+	0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65,
+	0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79,
+	0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x12,
+	0x63, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x29, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70,
+	0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x72, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69,
+	0x6f, 0x6e, 0x65, 0x73, 0x12, 0x64, 0x0a, 0x1f, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73, 0x50, 0x6f, 0x72, 0x50,
+	0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x12, 0x1e, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75,
+	0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75,
+	0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x65, 0x73, 0x32, 0xdb, 0x01, 0x0a, 0x0c, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x65, 0x0a, 0x14, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63,
+	0x69, 0x6f, 0x6e, 0x12, 0x28, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79,
+	0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e,
+	0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x64, 0x0a, 0x17, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x45, 0x73, 0x74,
+	0x61, 0x64, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x28, 0x2e,
+	0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x63, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75,
+	0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x32, 0xa0, 0x02, 0x0a, 0x06, 0x4f, 0x72, 0x61,
+	0x63, 0x6c, 0x65, 0x12, 0x5a, 0x0a, 0x17, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x44, 0x61,
+	0x74, 0x6f, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x73, 0x12, 0x1e,
+	0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x61, 0x74, 0x6f, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x73, 0x12,
+	0x62, 0x0a, 0x1a, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x69, 0x61, 0x6c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x12, 0x1e, 0x2e,
+	0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x6f, 0x64, 0x75, 0x63, 0x74, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e,
+	0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x69, 0x61, 0x6c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64,
+	0x6f, 0x30, 0x01, 0x12, 0x56, 0x0a, 0x13, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x72, 0x43, 0x61,
+	0x64, 0x65, 0x6e, 0x61, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x12, 0x1e, 0x2e, 0x6d, 0x65, 0x64,
+	0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x6d, 0x65, 0x64,
+	0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x63, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x64, 0x65, 0x6e, 0x61, 0x32, 0xb9, 0x01, 0x0a, 0x04,
+	0x49, 0x50, 0x46, 0x53, 0x12, 0x4e, 0x0a, 0x0e, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x41,
+	0x72, 0x63, 0x68, 0x69, 0x76, 0x6f, 0x12, 0x24, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70,
+	0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x41, 0x72,
+	0x63, 0x68, 0x69, 0x76, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x6d,
+	0x65, 0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x72, 0x63,
+	0x68, 0x69, 0x76, 0x6f, 0x12, 0x61, 0x0a, 0x13, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x45,
+	0x73, 0x74, 0x61, 0x64, 0x69, 0x73, 0x74, 0x69, 0x63, 0x61, 0x73, 0x12, 0x29, 0x2e, 0x6d, 0x65,
+	0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x45, 0x73, 0x74, 0x61, 0x64, 0x69, 0x73, 0x74, 0x69, 0x63, 0x61, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x6d, 0x65, 0x64, 0x69, 0x73, 0x75, 0x70,
+	0x70, 0x6c, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x73, 0x74, 0x61, 0x64, 0x69, 0x73, 0x74, 0x69,
+	0x63, 0x61, 0x73, 0x49, 0x50, 0x46, 0x53, 0x42, 0x4f, 0x5a, 0x4d, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x64, 0x69, 0x6e, 0x66, 0x61, 0x6d, 0x6f, 0x75, 0x73,
+	0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2d, 0x6d, 0x65, 0x64, 0x69,
+	0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x2f, 0x6d, 0x65,
+	0x64, 0x69, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x2f, 0x76, 0x31, 0x3b, 0x6d, 0x65, 0x64, 0x69,
+	0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_medisupply_v1_medisupply_proto_rawDescOnce sync.Once
+	file_medisupply_v1_medisupply_proto_rawDescData = file_medisupply_v1_medisupply_proto_rawDesc
+)
+
+func file_medisupply_v1_medisupply_proto_rawDescGZIP() []byte {
+	file_medisupply_v1_medisupply_proto_rawDescOnce.Do(func() {
+		file_medisupply_v1_medisupply_proto_rawDescData = protoimpl.X.CompressGZIP(file_medisupply_v1_medisupply_proto_rawDescData)
+	})
+	return file_medisupply_v1_medisupply_proto_rawDescData
+}
+
+var file_medisupply_v1_medisupply_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_medisupply_v1_medisupply_proto_goTypes = []interface{}{
+	(*TransaccionRequest)(nil),         // 0: medisupply.v1.TransaccionRequest
+	(*RegistrarLoteRequest)(nil),       // 1: medisupply.v1.RegistrarLoteRequest
+	(*Adjunto)(nil),                    // 2: medisupply.v1.Adjunto
+	(*Transaccion)(nil),                // 3: medisupply.v1.Transaccion
+	(*ResultadoLote)(nil),              // 4: medisupply.v1.ResultadoLote
+	(*LoteResponse)(nil),               // 5: medisupply.v1.LoteResponse
+	(*ObtenerTransaccionRequest)(nil),  // 6: medisupply.v1.ObtenerTransaccionRequest
+	(*ListarTransaccionesRequest)(nil), // 7: medisupply.v1.ListarTransaccionesRequest
+	(*ProductoRequest)(nil),            // 8: medisupply.v1.ProductoRequest
+	(*ListaTransacciones)(nil),         // 9: medisupply.v1.ListaTransacciones
+	(*VerificacionResponse)(nil),       // 10: medisupply.v1.VerificacionResponse
+	(*EstadoBlockchain)(nil),           // 11: medisupply.v1.EstadoBlockchain
+	(*EventoVerificado)(nil),           // 12: medisupply.v1.EventoVerificado
+	(*AnomaliaCadena)(nil),             // 13: medisupply.v1.AnomaliaCadena
+	(*VerificacionCadena)(nil),         // 14: medisupply.v1.VerificacionCadena
+	(*CabeceraHistorial)(nil),          // 15: medisupply.v1.CabeceraHistorial
+	(*HistorialVerificado)(nil),        // 16: medisupply.v1.HistorialVerificado
+	(*DatosVerificados)(nil),           // 17: medisupply.v1.DatosVerificados
+	(*ValidacionCadena)(nil),           // 18: medisupply.v1.ValidacionCadena
+	(*ObtenerArchivoRequest)(nil),      // 19: medisupply.v1.ObtenerArchivoRequest
+	(*Archivo)(nil),                    // 20: medisupply.v1.Archivo
+	(*ObtenerEstadisticasRequest)(nil), // 21: medisupply.v1.ObtenerEstadisticasRequest
+	(*EstadisticasIPFS)(nil),           // 22: medisupply.v1.EstadisticasIPFS
+	nil,                                // 23: medisupply.v1.DatosVerificados.MetadataEntry
+	(*timestamppb.Timestamp)(nil),      // 24: google.protobuf.Timestamp
+}
+var file_medisupply_v1_medisupply_proto_depIdxs = []int32{
+	0,  // 0: medisupply.v1.RegistrarLoteRequest.eventos:type_name -> medisupply.v1.TransaccionRequest
+	24, // 1: medisupply.v1.Transaccion.fecha_evento:type_name -> google.protobuf.Timestamp
+	2,  // 2: medisupply.v1.Transaccion.adjuntos:type_name -> medisupply.v1.Adjunto
+	24, // 3: medisupply.v1.Transaccion.created_at:type_name -> google.protobuf.Timestamp
+	24, // 4: medisupply.v1.Transaccion.updated_at:type_name -> google.protobuf.Timestamp
+	4,  // 5: medisupply.v1.LoteResponse.resultados:type_name -> medisupply.v1.ResultadoLote
+	3,  // 6: medisupply.v1.ListaTransacciones.transacciones:type_name -> medisupply.v1.Transaccion
+	24, // 7: medisupply.v1.EventoVerificado.fecha:type_name -> google.protobuf.Timestamp
+	13, // 8: medisupply.v1.VerificacionCadena.anomalias:type_name -> medisupply.v1.AnomaliaCadena
+	14, // 9: medisupply.v1.CabeceraHistorial.cadena:type_name -> medisupply.v1.VerificacionCadena
+	15, // 10: medisupply.v1.HistorialVerificado.cabecera:type_name -> medisupply.v1.CabeceraHistorial
+	12, // 11: medisupply.v1.HistorialVerificado.evento:type_name -> medisupply.v1.EventoVerificado
+	24, // 12: medisupply.v1.DatosVerificados.ultima_actualizacion:type_name -> google.protobuf.Timestamp
+	12, // 13: medisupply.v1.DatosVerificados.historial:type_name -> medisupply.v1.EventoVerificado
+	23, // 14: medisupply.v1.DatosVerificados.metadata:type_name -> medisupply.v1.DatosVerificados.MetadataEntry
+	0,  // 15: medisupply.v1.Transacciones.RegistrarTransaccion:input_type -> medisupply.v1.TransaccionRequest
+	1,  // 16: medisupply.v1.Transacciones.RegistrarLote:input_type -> medisupply.v1.RegistrarLoteRequest
+	6,  // 17: medisupply.v1.Transacciones.ObtenerTransaccion:input_type -> medisupply.v1.ObtenerTransaccionRequest
+	7,  // 18: medisupply.v1.Transacciones.ListarTransacciones:input_type -> medisupply.v1.ListarTransaccionesRequest
+	8,  // 19: medisupply.v1.Transacciones.ObtenerTransaccionesPorProducto:input_type -> medisupply.v1.ProductoRequest
+	6,  // 20: medisupply.v1.Verificacion.VerificarTransaccion:input_type -> medisupply.v1.ObtenerTransaccionRequest
+	6,  // 21: medisupply.v1.Verificacion.ObtenerEstadoBlockchain:input_type -> medisupply.v1.ObtenerTransaccionRequest
+	8,  // 22: medisupply.v1.Oracle.ObtenerDatosVerificados:input_type -> medisupply.v1.ProductoRequest
+	8,  // 23: medisupply.v1.Oracle.ObtenerHistorialVerificado:input_type -> medisupply.v1.ProductoRequest
+	8,  // 24: medisupply.v1.Oracle.ValidarCadenaSupply:input_type -> medisupply.v1.ProductoRequest
+	19, // 25: medisupply.v1.IPFS.ObtenerArchivo:input_type -> medisupply.v1.ObtenerArchivoRequest
+	21, // 26: medisupply.v1.IPFS.ObtenerEstadisticas:input_type -> medisupply.v1.ObtenerEstadisticasRequest
+	3,  // 27: medisupply.v1.Transacciones.RegistrarTransaccion:output_type -> medisupply.v1.Transaccion
+	5,  // 28: medisupply.v1.Transacciones.RegistrarLote:output_type -> medisupply.v1.LoteResponse
+	3,  // 29: medisupply.v1.Transacciones.ObtenerTransaccion:output_type -> medisupply.v1.Transaccion
+	9,  // 30: medisupply.v1.Transacciones.ListarTransacciones:output_type -> medisupply.v1.ListaTransacciones
+	9,  // 31: medisupply.v1.Transacciones.ObtenerTransaccionesPorProducto:output_type -> medisupply.v1.ListaTransacciones
+	10, // 32: medisupply.v1.Verificacion.VerificarTransaccion:output_type -> medisupply.v1.VerificacionResponse
+	11, // 33: medisupply.v1.Verificacion.ObtenerEstadoBlockchain:output_type -> medisupply.v1.EstadoBlockchain
+	17, // 34: medisupply.v1.Oracle.ObtenerDatosVerificados:output_type -> medisupply.v1.DatosVerificados
+	16, // 35: medisupply.v1.Oracle.ObtenerHistorialVerificado:output_type -> medisupply.v1.HistorialVerificado
+	18, // 36: medisupply.v1.Oracle.ValidarCadenaSupply:output_type -> medisupply.v1.ValidacionCadena
+	20, // 37: medisupply.v1.IPFS.ObtenerArchivo:output_type -> medisupply.v1.Archivo
+	22, // 38: medisupply.v1.IPFS.ObtenerEstadisticas:output_type -> medisupply.v1.EstadisticasIPFS
+	27, // [27:39] is the sub-list for method output_type
+	15, // [15:27] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_medisupply_v1_medisupply_proto_init() }
+func file_medisupply_v1_medisupply_proto_init() {
+	if File_medisupply_v1_medisupply_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_medisupply_v1_medisupply_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransaccionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistrarLoteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Adjunto); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Transaccion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResultadoLote); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoteResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ObtenerTransaccionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListarTransaccionesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProductoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListaTransacciones); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerificacionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EstadoBlockchain); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EventoVerificado); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnomaliaCadena); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerificacionCadena); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CabeceraHistorial); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HistorialVerificado); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatosVerificados); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidacionCadena); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ObtenerArchivoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Archivo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ObtenerEstadisticasRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_medisupply_v1_medisupply_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EstadisticasIPFS); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_medisupply_v1_medisupply_proto_msgTypes[16].OneofWrappers = []interface{}{
+		(*HistorialVerificado_Cabecera)(nil),
+		(*HistorialVerificado_Evento)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_medisupply_v1_medisupply_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_medisupply_v1_medisupply_proto_goTypes,
+		DependencyIndexes: file_medisupply_v1_medisupply_proto_depIdxs,
+		MessageInfos:      file_medisupply_v1_medisupply_proto_msgTypes,
+	}.Build()
+	File_medisupply_v1_medisupply_proto = out.File
+	file_medisupply_v1_medisupply_proto_rawDesc = nil
+	file_medisupply_v1_medisupply_proto_goTypes = nil
+	file_medisupply_v1_medisupply_proto_depIdxs = nil
+}
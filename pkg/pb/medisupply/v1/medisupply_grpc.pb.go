@@ -0,0 +1,737 @@
+// API gRPC de MediSupply. Comparte servicios, autenticación, política de autorización y rate limiting
+// con la API REST; cada RPC se autoriza con la regla de su ruta REST equivalente.
+//
+// Credenciales en metadata: "x-api-key: <clave>" o "authorization: Bearer <jwt | msk_...>",
+// o un certificado de cliente verificado por mTLS.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: medisupply/v1/medisupply.proto
+
+package medisupplyv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Transacciones_RegistrarTransaccion_FullMethodName            = "/medisupply.v1.Transacciones/RegistrarTransaccion"
+	Transacciones_RegistrarLote_FullMethodName                   = "/medisupply.v1.Transacciones/RegistrarLote"
+	Transacciones_ObtenerTransaccion_FullMethodName              = "/medisupply.v1.Transacciones/ObtenerTransaccion"
+	Transacciones_ListarTransacciones_FullMethodName             = "/medisupply.v1.Transacciones/ListarTransacciones"
+	Transacciones_ObtenerTransaccionesPorProducto_FullMethodName = "/medisupply.v1.Transacciones/ObtenerTransaccionesPorProducto"
+)
+
+// TransaccionesClient is the client API for Transacciones service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TransaccionesClient interface {
+	// RegistrarTransaccion equivale a POST /api/v1/transaccion/registrar.
+	RegistrarTransaccion(ctx context.Context, in *TransaccionRequest, opts ...grpc.CallOption) (*Transaccion, error)
+	// RegistrarLote equivale a POST /api/v1/transaccion/lote. Un lote inválido se rechaza completo
+	// con INVALID_ARGUMENT; los fallos de almacenamiento se informan por elemento.
+	RegistrarLote(ctx context.Context, in *RegistrarLoteRequest, opts ...grpc.CallOption) (*LoteResponse, error)
+	// ObtenerTransaccion equivale a GET /api/v1/transaccion/{id}.
+	ObtenerTransaccion(ctx context.Context, in *ObtenerTransaccionRequest, opts ...grpc.CallOption) (*Transaccion, error)
+	// ListarTransacciones equivale a GET /api/v1/transaccion.
+	ListarTransacciones(ctx context.Context, in *ListarTransaccionesRequest, opts ...grpc.CallOption) (*ListaTransacciones, error)
+	// ObtenerTransaccionesPorProducto equivale a GET /api/v1/transaccion/producto/{id}.
+	ObtenerTransaccionesPorProducto(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (*ListaTransacciones, error)
+}
+
+type transaccionesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTransaccionesClient(cc grpc.ClientConnInterface) TransaccionesClient {
+	return &transaccionesClient{cc}
+}
+
+func (c *transaccionesClient) RegistrarTransaccion(ctx context.Context, in *TransaccionRequest, opts ...grpc.CallOption) (*Transaccion, error) {
+	out := new(Transaccion)
+	err := c.cc.Invoke(ctx, Transacciones_RegistrarTransaccion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transaccionesClient) RegistrarLote(ctx context.Context, in *RegistrarLoteRequest, opts ...grpc.CallOption) (*LoteResponse, error) {
+	out := new(LoteResponse)
+	err := c.cc.Invoke(ctx, Transacciones_RegistrarLote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transaccionesClient) ObtenerTransaccion(ctx context.Context, in *ObtenerTransaccionRequest, opts ...grpc.CallOption) (*Transaccion, error) {
+	out := new(Transaccion)
+	err := c.cc.Invoke(ctx, Transacciones_ObtenerTransaccion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transaccionesClient) ListarTransacciones(ctx context.Context, in *ListarTransaccionesRequest, opts ...grpc.CallOption) (*ListaTransacciones, error) {
+	out := new(ListaTransacciones)
+	err := c.cc.Invoke(ctx, Transacciones_ListarTransacciones_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transaccionesClient) ObtenerTransaccionesPorProducto(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (*ListaTransacciones, error) {
+	out := new(ListaTransacciones)
+	err := c.cc.Invoke(ctx, Transacciones_ObtenerTransaccionesPorProducto_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransaccionesServer is the server API for Transacciones service.
+// All implementations must embed UnimplementedTransaccionesServer
+// for forward compatibility
+type TransaccionesServer interface {
+	// RegistrarTransaccion equivale a POST /api/v1/transaccion/registrar.
+	RegistrarTransaccion(context.Context, *TransaccionRequest) (*Transaccion, error)
+	// RegistrarLote equivale a POST /api/v1/transaccion/lote. Un lote inválido se rechaza completo
+	// con INVALID_ARGUMENT; los fallos de almacenamiento se informan por elemento.
+	RegistrarLote(context.Context, *RegistrarLoteRequest) (*LoteResponse, error)
+	// ObtenerTransaccion equivale a GET /api/v1/transaccion/{id}.
+	ObtenerTransaccion(context.Context, *ObtenerTransaccionRequest) (*Transaccion, error)
+	// ListarTransacciones equivale a GET /api/v1/transaccion.
+	ListarTransacciones(context.Context, *ListarTransaccionesRequest) (*ListaTransacciones, error)
+	// ObtenerTransaccionesPorProducto equivale a GET /api/v1/transaccion/producto/{id}.
+	ObtenerTransaccionesPorProducto(context.Context, *ProductoRequest) (*ListaTransacciones, error)
+	mustEmbedUnimplementedTransaccionesServer()
+}
+
+// UnimplementedTransaccionesServer must be embedded to have forward compatible implementations.
+type UnimplementedTransaccionesServer struct {
+}
+
+func (UnimplementedTransaccionesServer) RegistrarTransaccion(context.Context, *TransaccionRequest) (*Transaccion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegistrarTransaccion not implemented")
+}
+func (UnimplementedTransaccionesServer) RegistrarLote(context.Context, *RegistrarLoteRequest) (*LoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegistrarLote not implemented")
+}
+func (UnimplementedTransaccionesServer) ObtenerTransaccion(context.Context, *ObtenerTransaccionRequest) (*Transaccion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerTransaccion not implemented")
+}
+func (UnimplementedTransaccionesServer) ListarTransacciones(context.Context, *ListarTransaccionesRequest) (*ListaTransacciones, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListarTransacciones not implemented")
+}
+func (UnimplementedTransaccionesServer) ObtenerTransaccionesPorProducto(context.Context, *ProductoRequest) (*ListaTransacciones, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerTransaccionesPorProducto not implemented")
+}
+func (UnimplementedTransaccionesServer) mustEmbedUnimplementedTransaccionesServer() {}
+
+// UnsafeTransaccionesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransaccionesServer will
+// result in compilation errors.
+type UnsafeTransaccionesServer interface {
+	mustEmbedUnimplementedTransaccionesServer()
+}
+
+func RegisterTransaccionesServer(s grpc.ServiceRegistrar, srv TransaccionesServer) {
+	s.RegisterService(&Transacciones_ServiceDesc, srv)
+}
+
+func _Transacciones_RegistrarTransaccion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransaccionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransaccionesServer).RegistrarTransaccion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transacciones_RegistrarTransaccion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransaccionesServer).RegistrarTransaccion(ctx, req.(*TransaccionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transacciones_RegistrarLote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegistrarLoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransaccionesServer).RegistrarLote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transacciones_RegistrarLote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransaccionesServer).RegistrarLote(ctx, req.(*RegistrarLoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transacciones_ObtenerTransaccion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObtenerTransaccionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransaccionesServer).ObtenerTransaccion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transacciones_ObtenerTransaccion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransaccionesServer).ObtenerTransaccion(ctx, req.(*ObtenerTransaccionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transacciones_ListarTransacciones_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListarTransaccionesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransaccionesServer).ListarTransacciones(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transacciones_ListarTransacciones_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransaccionesServer).ListarTransacciones(ctx, req.(*ListarTransaccionesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transacciones_ObtenerTransaccionesPorProducto_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransaccionesServer).ObtenerTransaccionesPorProducto(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transacciones_ObtenerTransaccionesPorProducto_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransaccionesServer).ObtenerTransaccionesPorProducto(ctx, req.(*ProductoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Transacciones_ServiceDesc is the grpc.ServiceDesc for Transacciones service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Transacciones_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "medisupply.v1.Transacciones",
+	HandlerType: (*TransaccionesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegistrarTransaccion",
+			Handler:    _Transacciones_RegistrarTransaccion_Handler,
+		},
+		{
+			MethodName: "RegistrarLote",
+			Handler:    _Transacciones_RegistrarLote_Handler,
+		},
+		{
+			MethodName: "ObtenerTransaccion",
+			Handler:    _Transacciones_ObtenerTransaccion_Handler,
+		},
+		{
+			MethodName: "ListarTransacciones",
+			Handler:    _Transacciones_ListarTransacciones_Handler,
+		},
+		{
+			MethodName: "ObtenerTransaccionesPorProducto",
+			Handler:    _Transacciones_ObtenerTransaccionesPorProducto_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "medisupply/v1/medisupply.proto",
+}
+
+const (
+	Verificacion_VerificarTransaccion_FullMethodName    = "/medisupply.v1.Verificacion/VerificarTransaccion"
+	Verificacion_ObtenerEstadoBlockchain_FullMethodName = "/medisupply.v1.Verificacion/ObtenerEstadoBlockchain"
+)
+
+// VerificacionClient is the client API for Verificacion service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VerificacionClient interface {
+	// VerificarTransaccion equivale a GET /api/v1/transaccion/verificar/{id}.
+	VerificarTransaccion(ctx context.Context, in *ObtenerTransaccionRequest, opts ...grpc.CallOption) (*VerificacionResponse, error)
+	// ObtenerEstadoBlockchain equivale a GET /api/v1/transaccion/estado-blockchain/{id}.
+	ObtenerEstadoBlockchain(ctx context.Context, in *ObtenerTransaccionRequest, opts ...grpc.CallOption) (*EstadoBlockchain, error)
+}
+
+type verificacionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVerificacionClient(cc grpc.ClientConnInterface) VerificacionClient {
+	return &verificacionClient{cc}
+}
+
+func (c *verificacionClient) VerificarTransaccion(ctx context.Context, in *ObtenerTransaccionRequest, opts ...grpc.CallOption) (*VerificacionResponse, error) {
+	out := new(VerificacionResponse)
+	err := c.cc.Invoke(ctx, Verificacion_VerificarTransaccion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *verificacionClient) ObtenerEstadoBlockchain(ctx context.Context, in *ObtenerTransaccionRequest, opts ...grpc.CallOption) (*EstadoBlockchain, error) {
+	out := new(EstadoBlockchain)
+	err := c.cc.Invoke(ctx, Verificacion_ObtenerEstadoBlockchain_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VerificacionServer is the server API for Verificacion service.
+// All implementations must embed UnimplementedVerificacionServer
+// for forward compatibility
+type VerificacionServer interface {
+	// VerificarTransaccion equivale a GET /api/v1/transaccion/verificar/{id}.
+	VerificarTransaccion(context.Context, *ObtenerTransaccionRequest) (*VerificacionResponse, error)
+	// ObtenerEstadoBlockchain equivale a GET /api/v1/transaccion/estado-blockchain/{id}.
+	ObtenerEstadoBlockchain(context.Context, *ObtenerTransaccionRequest) (*EstadoBlockchain, error)
+	mustEmbedUnimplementedVerificacionServer()
+}
+
+// UnimplementedVerificacionServer must be embedded to have forward compatible implementations.
+type UnimplementedVerificacionServer struct {
+}
+
+func (UnimplementedVerificacionServer) VerificarTransaccion(context.Context, *ObtenerTransaccionRequest) (*VerificacionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerificarTransaccion not implemented")
+}
+func (UnimplementedVerificacionServer) ObtenerEstadoBlockchain(context.Context, *ObtenerTransaccionRequest) (*EstadoBlockchain, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerEstadoBlockchain not implemented")
+}
+func (UnimplementedVerificacionServer) mustEmbedUnimplementedVerificacionServer() {}
+
+// UnsafeVerificacionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VerificacionServer will
+// result in compilation errors.
+type UnsafeVerificacionServer interface {
+	mustEmbedUnimplementedVerificacionServer()
+}
+
+func RegisterVerificacionServer(s grpc.ServiceRegistrar, srv VerificacionServer) {
+	s.RegisterService(&Verificacion_ServiceDesc, srv)
+}
+
+func _Verificacion_VerificarTransaccion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObtenerTransaccionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VerificacionServer).VerificarTransaccion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Verificacion_VerificarTransaccion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VerificacionServer).VerificarTransaccion(ctx, req.(*ObtenerTransaccionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Verificacion_ObtenerEstadoBlockchain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObtenerTransaccionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VerificacionServer).ObtenerEstadoBlockchain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Verificacion_ObtenerEstadoBlockchain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VerificacionServer).ObtenerEstadoBlockchain(ctx, req.(*ObtenerTransaccionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Verificacion_ServiceDesc is the grpc.ServiceDesc for Verificacion service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Verificacion_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "medisupply.v1.Verificacion",
+	HandlerType: (*VerificacionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "VerificarTransaccion",
+			Handler:    _Verificacion_VerificarTransaccion_Handler,
+		},
+		{
+			MethodName: "ObtenerEstadoBlockchain",
+			Handler:    _Verificacion_ObtenerEstadoBlockchain_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "medisupply/v1/medisupply.proto",
+}
+
+const (
+	Oracle_ObtenerDatosVerificados_FullMethodName    = "/medisupply.v1.Oracle/ObtenerDatosVerificados"
+	Oracle_ObtenerHistorialVerificado_FullMethodName = "/medisupply.v1.Oracle/ObtenerHistorialVerificado"
+	Oracle_ValidarCadenaSupply_FullMethodName        = "/medisupply.v1.Oracle/ValidarCadenaSupply"
+)
+
+// OracleClient is the client API for Oracle service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OracleClient interface {
+	// ObtenerDatosVerificados equivale a GET /api/v1/oracle/datos/{id}.
+	ObtenerDatosVerificados(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (*DatosVerificados, error)
+	// ObtenerHistorialVerificado equivale a GET /api/v1/oracle/historial/{id}. El primer mensaje
+	// es la verificación de la cadena; después llega cada evento en cuanto se verifica.
+	ObtenerHistorialVerificado(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (Oracle_ObtenerHistorialVerificadoClient, error)
+	// ValidarCadenaSupply equivale a GET /api/v1/oracle/validar/{id}.
+	ValidarCadenaSupply(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (*ValidacionCadena, error)
+}
+
+type oracleClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOracleClient(cc grpc.ClientConnInterface) OracleClient {
+	return &oracleClient{cc}
+}
+
+func (c *oracleClient) ObtenerDatosVerificados(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (*DatosVerificados, error) {
+	out := new(DatosVerificados)
+	err := c.cc.Invoke(ctx, Oracle_ObtenerDatosVerificados_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oracleClient) ObtenerHistorialVerificado(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (Oracle_ObtenerHistorialVerificadoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Oracle_ServiceDesc.Streams[0], Oracle_ObtenerHistorialVerificado_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oracleObtenerHistorialVerificadoClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Oracle_ObtenerHistorialVerificadoClient interface {
+	Recv() (*HistorialVerificado, error)
+	grpc.ClientStream
+}
+
+type oracleObtenerHistorialVerificadoClient struct {
+	grpc.ClientStream
+}
+
+func (x *oracleObtenerHistorialVerificadoClient) Recv() (*HistorialVerificado, error) {
+	m := new(HistorialVerificado)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oracleClient) ValidarCadenaSupply(ctx context.Context, in *ProductoRequest, opts ...grpc.CallOption) (*ValidacionCadena, error) {
+	out := new(ValidacionCadena)
+	err := c.cc.Invoke(ctx, Oracle_ValidarCadenaSupply_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OracleServer is the server API for Oracle service.
+// All implementations must embed UnimplementedOracleServer
+// for forward compatibility
+type OracleServer interface {
+	// ObtenerDatosVerificados equivale a GET /api/v1/oracle/datos/{id}.
+	ObtenerDatosVerificados(context.Context, *ProductoRequest) (*DatosVerificados, error)
+	// ObtenerHistorialVerificado equivale a GET /api/v1/oracle/historial/{id}. El primer mensaje
+	// es la verificación de la cadena; después llega cada evento en cuanto se verifica.
+	ObtenerHistorialVerificado(*ProductoRequest, Oracle_ObtenerHistorialVerificadoServer) error
+	// ValidarCadenaSupply equivale a GET /api/v1/oracle/validar/{id}.
+	ValidarCadenaSupply(context.Context, *ProductoRequest) (*ValidacionCadena, error)
+	mustEmbedUnimplementedOracleServer()
+}
+
+// UnimplementedOracleServer must be embedded to have forward compatible implementations.
+type UnimplementedOracleServer struct {
+}
+
+func (UnimplementedOracleServer) ObtenerDatosVerificados(context.Context, *ProductoRequest) (*DatosVerificados, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerDatosVerificados not implemented")
+}
+func (UnimplementedOracleServer) ObtenerHistorialVerificado(*ProductoRequest, Oracle_ObtenerHistorialVerificadoServer) error {
+	return status.Errorf(codes.Unimplemented, "method ObtenerHistorialVerificado not implemented")
+}
+func (UnimplementedOracleServer) ValidarCadenaSupply(context.Context, *ProductoRequest) (*ValidacionCadena, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidarCadenaSupply not implemented")
+}
+func (UnimplementedOracleServer) mustEmbedUnimplementedOracleServer() {}
+
+// UnsafeOracleServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OracleServer will
+// result in compilation errors.
+type UnsafeOracleServer interface {
+	mustEmbedUnimplementedOracleServer()
+}
+
+func RegisterOracleServer(s grpc.ServiceRegistrar, srv OracleServer) {
+	s.RegisterService(&Oracle_ServiceDesc, srv)
+}
+
+func _Oracle_ObtenerDatosVerificados_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OracleServer).ObtenerDatosVerificados(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Oracle_ObtenerDatosVerificados_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OracleServer).ObtenerDatosVerificados(ctx, req.(*ProductoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Oracle_ObtenerHistorialVerificado_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ProductoRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OracleServer).ObtenerHistorialVerificado(m, &oracleObtenerHistorialVerificadoServer{stream})
+}
+
+type Oracle_ObtenerHistorialVerificadoServer interface {
+	Send(*HistorialVerificado) error
+	grpc.ServerStream
+}
+
+type oracleObtenerHistorialVerificadoServer struct {
+	grpc.ServerStream
+}
+
+func (x *oracleObtenerHistorialVerificadoServer) Send(m *HistorialVerificado) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Oracle_ValidarCadenaSupply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OracleServer).ValidarCadenaSupply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Oracle_ValidarCadenaSupply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OracleServer).ValidarCadenaSupply(ctx, req.(*ProductoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Oracle_ServiceDesc is the grpc.ServiceDesc for Oracle service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Oracle_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "medisupply.v1.Oracle",
+	HandlerType: (*OracleServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ObtenerDatosVerificados",
+			Handler:    _Oracle_ObtenerDatosVerificados_Handler,
+		},
+		{
+			MethodName: "ValidarCadenaSupply",
+			Handler:    _Oracle_ValidarCadenaSupply_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ObtenerHistorialVerificado",
+			Handler:       _Oracle_ObtenerHistorialVerificado_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "medisupply/v1/medisupply.proto",
+}
+
+const (
+	IPFS_ObtenerArchivo_FullMethodName      = "/medisupply.v1.IPFS/ObtenerArchivo"
+	IPFS_ObtenerEstadisticas_FullMethodName = "/medisupply.v1.IPFS/ObtenerEstadisticas"
+)
+
+// IPFSClient is the client API for IPFS service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IPFSClient interface {
+	// ObtenerArchivo equivale a GET /api/v1/ipfs/archivo/{cid}.
+	ObtenerArchivo(ctx context.Context, in *ObtenerArchivoRequest, opts ...grpc.CallOption) (*Archivo, error)
+	// ObtenerEstadisticas equivale a GET /api/v1/ipfs/estadisticas.
+	ObtenerEstadisticas(ctx context.Context, in *ObtenerEstadisticasRequest, opts ...grpc.CallOption) (*EstadisticasIPFS, error)
+}
+
+type iPFSClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIPFSClient(cc grpc.ClientConnInterface) IPFSClient {
+	return &iPFSClient{cc}
+}
+
+func (c *iPFSClient) ObtenerArchivo(ctx context.Context, in *ObtenerArchivoRequest, opts ...grpc.CallOption) (*Archivo, error) {
+	out := new(Archivo)
+	err := c.cc.Invoke(ctx, IPFS_ObtenerArchivo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPFSClient) ObtenerEstadisticas(ctx context.Context, in *ObtenerEstadisticasRequest, opts ...grpc.CallOption) (*EstadisticasIPFS, error) {
+	out := new(EstadisticasIPFS)
+	err := c.cc.Invoke(ctx, IPFS_ObtenerEstadisticas_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IPFSServer is the server API for IPFS service.
+// All implementations must embed UnimplementedIPFSServer
+// for forward compatibility
+type IPFSServer interface {
+	// ObtenerArchivo equivale a GET /api/v1/ipfs/archivo/{cid}.
+	ObtenerArchivo(context.Context, *ObtenerArchivoRequest) (*Archivo, error)
+	// ObtenerEstadisticas equivale a GET /api/v1/ipfs/estadisticas.
+	ObtenerEstadisticas(context.Context, *ObtenerEstadisticasRequest) (*EstadisticasIPFS, error)
+	mustEmbedUnimplementedIPFSServer()
+}
+
+// UnimplementedIPFSServer must be embedded to have forward compatible implementations.
+type UnimplementedIPFSServer struct {
+}
+
+func (UnimplementedIPFSServer) ObtenerArchivo(context.Context, *ObtenerArchivoRequest) (*Archivo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerArchivo not implemented")
+}
+func (UnimplementedIPFSServer) ObtenerEstadisticas(context.Context, *ObtenerEstadisticasRequest) (*EstadisticasIPFS, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerEstadisticas not implemented")
+}
+func (UnimplementedIPFSServer) mustEmbedUnimplementedIPFSServer() {}
+
+// UnsafeIPFSServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IPFSServer will
+// result in compilation errors.
+type UnsafeIPFSServer interface {
+	mustEmbedUnimplementedIPFSServer()
+}
+
+func RegisterIPFSServer(s grpc.ServiceRegistrar, srv IPFSServer) {
+	s.RegisterService(&IPFS_ServiceDesc, srv)
+}
+
+func _IPFS_ObtenerArchivo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObtenerArchivoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPFSServer).ObtenerArchivo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPFS_ObtenerArchivo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPFSServer).ObtenerArchivo(ctx, req.(*ObtenerArchivoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPFS_ObtenerEstadisticas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObtenerEstadisticasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPFSServer).ObtenerEstadisticas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPFS_ObtenerEstadisticas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPFSServer).ObtenerEstadisticas(ctx, req.(*ObtenerEstadisticasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IPFS_ServiceDesc is the grpc.ServiceDesc for IPFS service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IPFS_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "medisupply.v1.IPFS",
+	HandlerType: (*IPFSServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ObtenerArchivo",
+			Handler:    _IPFS_ObtenerArchivo_Handler,
+		},
+		{
+			MethodName: "ObtenerEstadisticas",
+			Handler:    _IPFS_ObtenerEstadisticas_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "medisupply/v1/medisupply.proto",
+}
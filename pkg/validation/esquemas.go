@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+)
+
+// esquemasEmbebidos contiene un JSON Schema por tipo de evento (<tipoEvento>.json)
+//
+//go:embed esquemas/*.json
+var esquemasEmbebidos embed.FS
+
+var (
+	esquemas   map[string]*jsonschema.Schema
+	esquemasMu sync.RWMutex
+)
+
+func init() {
+	compilados, err := compilarEsquemas(nil)
+	if err != nil {
+		panic(fmt.Sprintf("esquemas de eventos embebidos inválidos: %v", err))
+	}
+	esquemas = compilados
+}
+
+// CargarEsquemas reemplaza los esquemas embebidos por los archivos <tipoEvento>.json del directorio.
+// Los tipos de evento sin archivo en el directorio conservan el esquema embebido.
+func CargarEsquemas(dir string) error {
+	archivos, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("error listando esquemas en %s: %w", dir, err)
+	}
+
+	externos := make(map[string][]byte, len(archivos))
+	for _, archivo := range archivos {
+		contenido, err := os.ReadFile(archivo)
+		if err != nil {
+			return fmt.Errorf("error leyendo esquema %s: %w", archivo, err)
+		}
+		externos[strings.TrimSuffix(filepath.Base(archivo), ".json")] = contenido
+	}
+
+	compilados, err := compilarEsquemas(externos)
+	if err != nil {
+		return err
+	}
+
+	esquemasMu.Lock()
+	esquemas = compilados
+	esquemasMu.Unlock()
+	return nil
+}
+
+// ValidarDatosEvento valida que datosEvento sea un documento JSON que cumpla el esquema de su tipo de evento
+func ValidarDatosEvento(tipoEvento, datosEvento string) error {
+	esquemasMu.RLock()
+	esquema, ok := esquemas[tipoEvento]
+	esquemasMu.RUnlock()
+	if !ok {
+		return i18n.NuevoError("esquema.tipo-desconocido", tipoEvento)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(datosEvento))
+	dec.UseNumber()
+	var documento interface{}
+	if err := dec.Decode(&documento); err != nil {
+		return i18n.NuevoError("esquema.json-invalido", err)
+	}
+
+	if err := esquema.Validate(documento); err != nil {
+		if validationErr, ok := err.(*jsonschema.ValidationError); ok {
+			return formatSchemaErrors(tipoEvento, validationErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// compilarEsquemas compila los esquemas embebidos, reemplazando los que vengan en externos
+func compilarEsquemas(externos map[string][]byte) (map[string]*jsonschema.Schema, error) {
+	fuentes := make(map[string][]byte)
+
+	embebidos, err := esquemasEmbebidos.ReadDir("esquemas")
+	if err != nil {
+		return nil, err
+	}
+	for _, entrada := range embebidos {
+		contenido, err := esquemasEmbebidos.ReadFile("esquemas/" + entrada.Name())
+		if err != nil {
+			return nil, err
+		}
+		fuentes[strings.TrimSuffix(entrada.Name(), ".json")] = contenido
+	}
+	for tipoEvento, contenido := range externos {
+		fuentes[tipoEvento] = contenido
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+
+	compilados := make(map[string]*jsonschema.Schema, len(fuentes))
+	for tipoEvento, contenido := range fuentes {
+		url := "esquemas/" + tipoEvento + ".json"
+		if err := compiler.AddResource(url, bytes.NewReader(contenido)); err != nil {
+			return nil, fmt.Errorf("esquema de '%s' inválido: %w", tipoEvento, err)
+		}
+		esquema, err := compiler.Compile(url)
+		if err != nil {
+			return nil, fmt.Errorf("esquema de '%s' inválido: %w", tipoEvento, err)
+		}
+		compilados[tipoEvento] = esquema
+	}
+
+	return compilados, nil
+}
+
+// formatSchemaErrors formatea los errores de JSON Schema con la ruta del dato que falló. Los mensajes
+// de cada error son los de la librería de JSON Schema, en inglés.
+func formatSchemaErrors(tipoEvento string, err *jsonschema.ValidationError) error {
+	var errMsg string
+	for _, causa := range hojasValidacion(err) {
+		ruta := causa.InstanceLocation
+		if ruta == "" {
+			ruta = "/"
+		}
+		errMsg += fmt.Sprintf("DatosEvento%s: %s. ", strings.TrimSuffix(ruta, "/"), causa.Message)
+	}
+	return i18n.NuevoError("esquema.no-cumple", tipoEvento, strings.TrimSpace(errMsg))
+}
+
+// hojasValidacion retorna los errores concretos, sin los nodos intermedios del árbol de causas
+func hojasValidacion(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var hojas []*jsonschema.ValidationError
+	for _, causa := range err.Causes {
+		hojas = append(hojas, hojasValidacion(causa)...)
+	}
+	return hojas
+}
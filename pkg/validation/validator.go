@@ -1,13 +1,27 @@
 package validation
 
 import (
-	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+)
+
+var (
+	validate    *validator.Validate
+	traductores map[i18n.Idioma]ut.Translator
 )
 
-var validate *validator.Validate
+// etiquetasPropias son las validaciones cuyo mensaje viene del catálogo de i18n (validacion.<etiqueta>)
+// en vez de las traducciones por defecto del validador
+var etiquetasPropias = []string{"required", "oneof", "ethereum_address", "ipfs_cid"}
 
 func init() {
 	validate = validator.New()
@@ -15,6 +29,50 @@ func init() {
 	// Registrar validaciones personalizadas
 	_ = validate.RegisterValidation("ethereum_address", validateEthereumAddress)
 	_ = validate.RegisterValidation("ipfs_cid", validateIPFSCid)
+
+	// Un traductor por idioma del catálogo, con las traducciones por defecto del validador
+	universal := ut.New(es.New(), es.New(), en.New())
+	traductores = make(map[i18n.Idioma]ut.Translator, len(i18n.Idiomas))
+	for _, idioma := range i18n.Idiomas {
+		traductor, _ := universal.GetTranslator(string(idioma))
+		traductores[idioma] = traductor
+	}
+	_ = es_translations.RegisterDefaultTranslations(validate, traductores[i18n.Espanol])
+	_ = en_translations.RegisterDefaultTranslations(validate, traductores[i18n.Ingles])
+
+	for _, etiqueta := range etiquetasPropias {
+		for _, traductor := range traductores {
+			_ = validate.RegisterTranslation(etiqueta, traductor, registrarNada, traducirDesdeCatalogo)
+		}
+	}
+}
+
+// ErrorValidacion reúne los errores de validación de una estructura; se muestra en el idioma por defecto
+// y se puede traducir a cualquier idioma del catálogo
+type ErrorValidacion struct {
+	errores validator.ValidationErrors
+}
+
+func (e *ErrorValidacion) Error() string {
+	return e.Traducir(i18n.IdiomaPorDefecto)
+}
+
+// Traducir muestra los errores en el idioma indicado
+func (e *ErrorValidacion) Traducir(idioma i18n.Idioma) string {
+	traductor, ok := traductores[idioma]
+	if !ok {
+		traductor = traductores[i18n.IdiomaPorDefecto]
+	}
+	mensajes := make([]string, 0, len(e.errores))
+	for _, err := range e.errores {
+		mensaje := err.Translate(traductor)
+		if mensaje == err.Error() {
+			// El validador no tiene traducción para la etiqueta y retornó su mensaje interno
+			mensaje = i18n.Traducir(idioma, "validacion.fallo", err.Field(), err.Tag())
+		}
+		mensajes = append(mensajes, mensaje)
+	}
+	return strings.Join(mensajes, " ")
 }
 
 // ValidateStruct valida una estructura
@@ -22,31 +80,21 @@ func ValidateStruct(s interface{}) error {
 	err := validate.Struct(s)
 	if err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			return formatValidationErrors(validationErrors)
+			return &ErrorValidacion{errores: validationErrors}
 		}
 		return err
 	}
 	return nil
 }
 
-// formatValidationErrors formatea los errores de validación
-func formatValidationErrors(errors validator.ValidationErrors) error {
-	var errMsg string
-	for _, err := range errors {
-		switch err.Tag() {
-		case "required":
-			errMsg += fmt.Sprintf("El campo '%s' es requerido. ", err.Field())
-		case "oneof":
-			errMsg += fmt.Sprintf("El campo '%s' debe ser uno de: %s. ", err.Field(), err.Param())
-		case "ethereum_address":
-			errMsg += fmt.Sprintf("El campo '%s' debe ser una dirección Ethereum válida. ", err.Field())
-		case "ipfs_cid":
-			errMsg += fmt.Sprintf("El campo '%s' debe ser un CID de IPFS válido. ", err.Field())
-		default:
-			errMsg += fmt.Sprintf("El campo '%s' falló la validación '%s'. ", err.Field(), err.Tag())
-		}
-	}
-	return fmt.Errorf(errMsg)
+// registrarNada cumple la firma de RegisterTranslation: los mensajes propios ya están en el catálogo
+func registrarNada(ut.Translator) error {
+	return nil
+}
+
+// traducirDesdeCatalogo formatea validacion.<etiqueta> del catálogo con el campo y el parámetro
+func traducirDesdeCatalogo(traductor ut.Translator, err validator.FieldError) string {
+	return i18n.Traducir(i18n.Idioma(traductor.Locale()), "validacion."+err.Tag(), err.Field(), err.Param())
 }
 
 // validateEthereumAddress valida una dirección Ethereum
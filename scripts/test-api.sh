@@ -4,6 +4,10 @@
 
 API_URL="http://localhost:8080"
 
+# API key para las rutas /api/v1 (vacía si AUTH_ENABLED=false)
+API_KEY="${API_KEY:-}"
+AUTH_HEADER="X-API-Key: $API_KEY"
+
 echo "🧪 Probando API de Transacción Blockchain"
 echo "========================================"
 
@@ -20,7 +24,7 @@ curl -s "$API_URL/ready" | jq '.'
 # Registrar transacción de fabricación
 echo ""
 echo "3️⃣ Registrando transacción de fabricación..."
-RESPONSE=$(curl -s -X POST "$API_URL/api/v1/transaccion/registrar" \
+RESPONSE=$(curl -s -X POST -H "$AUTH_HEADER" "$API_URL/api/v1/transaccion/registrar" \
   -H "Content-Type: application/json" \
   -d '{
     "tipoEvento": "fabricacion",
@@ -44,17 +48,17 @@ if [ "$TX_ID" != "null" ] && [ -n "$TX_ID" ]; then
   # Obtener transacción
   echo ""
   echo "4️⃣ Obteniendo transacción $TX_ID..."
-  curl -s "$API_URL/api/v1/transaccion/$TX_ID" | jq '.'
+  curl -s -H "$AUTH_HEADER" "$API_URL/api/v1/transaccion/$TX_ID" | jq '.'
   
   # Verificar integridad (puede fallar si blockchain no está configurado)
   echo ""
   echo "5️⃣ Verificando integridad de transacción..."
-  curl -s "$API_URL/api/v1/transaccion/verificar/$TX_ID" | jq '.'
+  curl -s -H "$AUTH_HEADER" "$API_URL/api/v1/transaccion/verificar/$TX_ID" | jq '.'
   
   # Registrar más transacciones para el mismo producto
   echo ""
   echo "6️⃣ Registrando transacción de distribución..."
-  curl -s -X POST "$API_URL/api/v1/transaccion/registrar" \
+  curl -s -X POST -H "$AUTH_HEADER" "$API_URL/api/v1/transaccion/registrar" \
     -H "Content-Type: application/json" \
     -d '{
       "tipoEvento": "distribucion",
@@ -68,17 +72,17 @@ if [ "$TX_ID" != "null" ] && [ -n "$TX_ID" ]; then
   # Consultar Oracle
   echo ""
   echo "7️⃣ Consultando Oracle para producto PROD-TEST-001..."
-  curl -s "$API_URL/api/v1/oracle/datos/PROD-TEST-001" | jq '.'
+  curl -s -H "$AUTH_HEADER" "$API_URL/api/v1/oracle/datos/PROD-TEST-001" | jq '.'
   
   # Historial verificado
   echo ""
   echo "8️⃣ Obteniendo historial verificado..."
-  curl -s "$API_URL/api/v1/oracle/historial/PROD-TEST-001" | jq '.'
+  curl -s -H "$AUTH_HEADER" "$API_URL/api/v1/oracle/historial/PROD-TEST-001" | jq '.'
   
   # Validar cadena de suministro
   echo ""
   echo "9️⃣ Validando cadena de suministro..."
-  curl -s "$API_URL/api/v1/oracle/validar/PROD-TEST-001" | jq '.'
+  curl -s -H "$AUTH_HEADER" "$API_URL/api/v1/oracle/validar/PROD-TEST-001" | jq '.'
   
 else
   echo "❌ Error: No se pudo crear la transacción"
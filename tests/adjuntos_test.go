@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+)
+
+// pdfMinimo es un documento con la firma de un PDF, suficiente para la detección de tipo
+var pdfMinimo = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+
+func TestAlmacenarAdjunto(t *testing.T) {
+	ctx := context.Background()
+	kubo := NewMockKubo()
+	defer kubo.Close()
+
+	host, port := kubo.HostPort()
+	transaccionService := services.NewTransaccionService(nil, services.NewIPFSService(host, port), nil)
+	transaccionService.ConfigurarAdjuntos(services.AdjuntosConfig{
+		TamanoMaximo:   1024,
+		MaximoArchivos: 2,
+		TiposMIME:      []string{"application/pdf", "text/csv"},
+		TiposEvento:    []string{"fabricacion"},
+	})
+
+	t.Run("Almacena PDF con hash y tamaño", func(t *testing.T) {
+		adjunto, err := transaccionService.AlmacenarAdjunto(ctx, "certificado.pdf", "application/octet-stream", bytes.NewReader(pdfMinimo))
+		require.NoError(t, err)
+
+		suma := sha256.Sum256(pdfMinimo)
+		assert.Equal(t, "application/pdf", adjunto.TipoMIME, "El tipo se detecta por contenido")
+		assert.Equal(t, int64(len(pdfMinimo)), adjunto.Tamano)
+		assert.Equal(t, hex.EncodeToString(suma[:]), adjunto.HashSHA256)
+		assert.NotEmpty(t, adjunto.CID)
+	})
+
+	t.Run("Acepta CSV declarado", func(t *testing.T) {
+		adjunto, err := transaccionService.AlmacenarAdjunto(ctx, "temperaturas.csv", "text/csv", strings.NewReader("hora,temp\n08:00,4.1\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv", adjunto.TipoMIME)
+	})
+
+	t.Run("Rechaza tipo no permitido", func(t *testing.T) {
+		png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+		_, err := transaccionService.AlmacenarAdjunto(ctx, "foto.pdf", "application/pdf", bytes.NewReader(png))
+		assert.True(t, errors.Is(err, services.ErrAdjuntoNoPermitido))
+	})
+
+	t.Run("Rechaza archivo que excede el tamaño", func(t *testing.T) {
+		grande := append(append([]byte{}, pdfMinimo...), bytes.Repeat([]byte("x"), 2048)...)
+		_, err := transaccionService.AlmacenarAdjunto(ctx, "grande.pdf", "application/pdf", bytes.NewReader(grande))
+		assert.True(t, errors.Is(err, services.ErrAdjuntoDemasiadoGrande))
+	})
+
+	t.Run("Rechaza adjuntos para tipos de evento no habilitados", func(t *testing.T) {
+		req := GetMockTransaccionRequest()
+		req.TipoEvento = "recepcion"
+		req.DatosEvento = `{"receptor": "Farmacia Central"}`
+		err := transaccionService.ValidarSolicitudConAdjuntos(ctx, req)
+		assert.True(t, errors.Is(err, services.ErrAdjuntoNoPermitido))
+	})
+}
+
+func TestHashTransaccionConAdjuntos(t *testing.T) {
+	transaccion := GetMockTransaccion()
+	hashSinAdjuntos := utils.CalcularHashTransaccion(transaccion)
+
+	transaccion.Adjuntos = []models.Adjunto{{CID: "QmAdjunto", HashSHA256: "aa"}}
+	hashConAdjunto := utils.CalcularHashTransaccion(transaccion)
+	assert.NotEqual(t, hashSinAdjuntos, hashConAdjunto, "Los adjuntos deben formar parte del hash")
+
+	transaccion.Adjuntos[0].HashSHA256 = "bb"
+	assert.NotEqual(t, hashConAdjunto, utils.CalcularHashTransaccion(transaccion), "Cambiar el contenido del adjunto cambia el hash")
+}
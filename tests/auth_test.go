@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+const secretoJWTPrueba = "secreto-hmac-de-pruebas-con-32-caracteres"
+
+// firmarJWT firma un token HS256 con los claims dados
+func firmarJWT(t *testing.T, claims jwt.MapClaims, secreto string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secreto))
+	require.NoError(t, err)
+	return token
+}
+
+// routerAutenticado expone una ruta que retorna el principal autenticado
+func routerAutenticado(cfg middleware.AuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api", middleware.AuthMiddleware(cfg))
+	api.GET("/yo", func(c *gin.Context) {
+		c.JSON(http.StatusOK, middleware.PrincipalDesdeContexto(c))
+	})
+	api.GET("/admin", middleware.RequerirRol(middleware.RolAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// peticion ejecuta un GET con las cabeceras dadas
+func peticion(router *gin.Engine, ruta string, cabeceras map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, ruta, nil)
+	for clave, valor := range cabeceras {
+		req.Header.Set(clave, valor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyService(t *testing.T) {
+	ctx := context.Background()
+	apiKeyService := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+
+	clave, apiKey, err := apiKeyService.CrearAPIKey(ctx, &models.APIKeyRequest{
+		Nombre: "Integración laboratorio",
+		Actor:  "Laboratorio ABC",
+		Roles:  []string{"fabricante"},
+	})
+	require.NoError(t, err)
+	assert.True(t, services.EsAPIKey(clave))
+	assert.NotContains(t, apiKey.HashClave, clave, "Solo se almacena el hash")
+
+	t.Run("Autentica la clave emitida", func(t *testing.T) {
+		principal, err := apiKeyService.Autenticar(ctx, clave)
+		require.NoError(t, err)
+		assert.Equal(t, "Laboratorio ABC", principal.Actor)
+		assert.Equal(t, []string{"fabricante"}, principal.Roles)
+		assert.Equal(t, models.MetodoAPIKey, principal.Metodo)
+	})
+
+	t.Run("Rechaza un secreto alterado", func(t *testing.T) {
+		_, err := apiKeyService.Autenticar(ctx, clave+"x")
+		assert.True(t, errors.Is(err, services.ErrCredencialesInvalidas))
+
+		_, err = apiKeyService.Autenticar(ctx, "msk_inexistente_secreto")
+		assert.True(t, errors.Is(err, services.ErrCredencialesInvalidas))
+	})
+
+	t.Run("Rechaza la clave revocada", func(t *testing.T) {
+		_, err := apiKeyService.RevocarAPIKey(ctx, apiKey.ID)
+		require.NoError(t, err)
+
+		_, err = apiKeyService.Autenticar(ctx, clave)
+		assert.True(t, errors.Is(err, services.ErrCredencialesInvalidas))
+	})
+}
+
+func TestValidadorJWT(t *testing.T) {
+	validador, err := auth.NewValidadorJWT(auth.ConfigJWT{
+		SecretoHMAC: secretoJWTPrueba,
+		Emisor:      "https://idp.medisupply.test",
+		ClaimActor:  "actor",
+	})
+	require.NoError(t, err)
+
+	claimsValidos := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "usuario-1",
+			"actor": "Distribuidora XYZ",
+			"roles": []string{"distribuidor"},
+			"iss":   "https://idp.medisupply.test",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	t.Run("Token HMAC válido", func(t *testing.T) {
+		principal, err := validador.Validar(firmarJWT(t, claimsValidos(), secretoJWTPrueba))
+		require.NoError(t, err)
+		assert.Equal(t, "usuario-1", principal.ID)
+		assert.Equal(t, "Distribuidora XYZ", principal.Actor)
+		assert.Equal(t, []string{"distribuidor"}, principal.Roles)
+	})
+
+	t.Run("Rechaza firma con otro secreto", func(t *testing.T) {
+		_, err := validador.Validar(firmarJWT(t, claimsValidos(), "otro-secreto-de-32-caracteres-xxxxx"))
+		assert.True(t, errors.Is(err, auth.ErrTokenInvalido))
+	})
+
+	t.Run("Rechaza token expirado o sin expiración", func(t *testing.T) {
+		claims := claimsValidos()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := validador.Validar(firmarJWT(t, claims, secretoJWTPrueba))
+		assert.True(t, errors.Is(err, auth.ErrTokenInvalido))
+
+		delete(claims, "exp")
+		_, err = validador.Validar(firmarJWT(t, claims, secretoJWTPrueba))
+		assert.True(t, errors.Is(err, auth.ErrTokenInvalido))
+	})
+
+	t.Run("Rechaza otro emisor", func(t *testing.T) {
+		claims := claimsValidos()
+		claims["iss"] = "https://otro.test"
+		_, err := validador.Validar(firmarJWT(t, claims, secretoJWTPrueba))
+		assert.True(t, errors.Is(err, auth.ErrTokenInvalido))
+	})
+
+	t.Run("Valida tokens RS256 contra un archivo JWKS", func(t *testing.T) {
+		clave, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		jwks, err := json.Marshal(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "clave-1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(clave.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(clave.E)).Bytes()),
+			}},
+		})
+		require.NoError(t, err)
+		archivo := filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(archivo, jwks, 0o600))
+
+		validadorRSA, err := auth.NewValidadorJWT(auth.ConfigJWT{ArchivoJWKS: archivo})
+		require.NoError(t, err)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub": "Farmacia Central",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "clave-1"
+		firmado, err := token.SignedString(clave)
+		require.NoError(t, err)
+
+		principal, err := validadorRSA.Validar(firmado)
+		require.NoError(t, err)
+		assert.Equal(t, "Farmacia Central", principal.Actor)
+
+		// Un token HMAC no debe aceptarse cuando solo hay JWKS configurado
+		_, err = validadorRSA.Validar(firmarJWT(t, jwt.MapClaims{"sub": "x", "exp": time.Now().Add(time.Hour).Unix()}, secretoJWTPrueba))
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	ctx := context.Background()
+	apiKeyService := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+	clave, _, err := apiKeyService.CrearAPIKey(ctx, &models.APIKeyRequest{Nombre: "lab", Actor: "Laboratorio ABC", Roles: []string{"fabricante"}})
+	require.NoError(t, err)
+
+	validador, err := auth.NewValidadorJWT(auth.ConfigJWT{SecretoHMAC: secretoJWTPrueba})
+	require.NoError(t, err)
+
+	router := routerAutenticado(middleware.AuthConfig{
+		APIKeys:       apiKeyService,
+		JWT:           validador,
+		ClaveArranque: "clave-de-arranque-con-al-menos-32-caracteres",
+	})
+
+	t.Run("Sin credenciales responde 401", func(t *testing.T) {
+		w := peticion(router, "/api/yo", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+	})
+
+	t.Run("API key en X-API-Key y en Bearer", func(t *testing.T) {
+		for _, cabeceras := range []map[string]string{
+			{"X-API-Key": clave},
+			{"Authorization": "Bearer " + clave},
+		} {
+			w := peticion(router, "/api/yo", cabeceras)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var principal models.Principal
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &principal))
+			assert.Equal(t, "Laboratorio ABC", principal.Actor)
+		}
+	})
+
+	t.Run("JWT bearer", func(t *testing.T) {
+		token := firmarJWT(t, jwt.MapClaims{"sub": "Distribuidora XYZ", "exp": time.Now().Add(time.Hour).Unix()}, secretoJWTPrueba)
+		w := peticion(router, "/api/yo", map[string]string{"Authorization": "Bearer " + token})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Distribuidora XYZ")
+	})
+
+	t.Run("Credenciales inválidas responden 401", func(t *testing.T) {
+		w := peticion(router, "/api/yo", map[string]string{"Authorization": "Bearer no-es-un-jwt"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Rutas de administración exigen rol admin", func(t *testing.T) {
+		w := peticion(router, "/api/admin", map[string]string{"X-API-Key": clave})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = peticion(router, "/api/admin", map[string]string{"X-API-Key": "clave-de-arranque-con-al-menos-32-caracteres"})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestActorAutenticadoReemplazaActorEmisor(t *testing.T) {
+	transaccionService := services.NewTransaccionService(nil, nil, nil)
+	principal := &models.Principal{ID: "k1", Actor: "Laboratorio ABC", Metodo: models.MetodoAPIKey}
+	ctx := auth.ConPrincipal(context.Background(), principal)
+
+	req := GetMockTransaccionRequest()
+	req.ActorEmisor = "Actor Suplantado"
+	require.NoError(t, transaccionService.ValidarSolicitudConAdjuntos(ctx, req))
+	assert.Equal(t, "Laboratorio ABC", req.ActorEmisor)
+
+	// Sin principal se conserva el actor enviado por el cliente
+	req.ActorEmisor = "Actor Cliente"
+	require.NoError(t, transaccionService.ValidarSolicitudConAdjuntos(context.Background(), req))
+	assert.Equal(t, "Actor Cliente", req.ActorEmisor)
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+)
+
+// construirCadena genera n eventos encadenados de un producto y la cabeza correspondiente
+func construirCadena(idProducto string, n int) ([]*models.Transaccion, *models.CabezaCadena) {
+	fecha := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transacciones := make([]*models.Transaccion, 0, n)
+	anterior := ""
+
+	for i := 1; i <= n; i++ {
+		transaccion := GetMockTransaccionFabricacion(idProducto)
+		transaccion.IDTransaction = fmt.Sprintf("tx-%d", i)
+		transaccion.FechaEvento = fecha.Add(time.Duration(i) * time.Hour)
+		transaccion.HashVersion = utils.HashVersionCadena
+		transaccion.Secuencia = int64(i)
+		transaccion.HashEventoAnterior = anterior
+		transaccion.HashEvento = utils.CalcularHashTransaccion(transaccion)
+		anterior = transaccion.HashEvento
+		transacciones = append(transacciones, transaccion)
+	}
+
+	ultima := transacciones[n-1]
+	return transacciones, &models.CabezaCadena{
+		IDProducto:    idProducto,
+		Secuencia:     ultima.Secuencia,
+		HashEvento:    ultima.HashEvento,
+		IDTransaction: ultima.IDTransaction,
+	}
+}
+
+// tiposAnomalia retorna los tipos de anomalía detectados
+func tiposAnomalia(cadena *models.VerificacionCadena) []string {
+	tipos := make([]string, 0, len(cadena.Anomalias))
+	for _, anomalia := range cadena.Anomalias {
+		tipos = append(tipos, anomalia.Tipo)
+	}
+	return tipos
+}
+
+func TestVerificarCadenaProducto(t *testing.T) {
+	t.Run("Cadena íntegra", func(t *testing.T) {
+		transacciones, cabeza := construirCadena("PROD-001", 4)
+
+		cadena := services.VerificarCadenaProducto(transacciones, cabeza)
+		assert.True(t, cadena.Integra)
+		assert.Equal(t, int64(4), cadena.Longitud)
+		assert.Empty(t, cadena.Anomalias)
+	})
+
+	t.Run("Detecta un evento intermedio eliminado", func(t *testing.T) {
+		transacciones, cabeza := construirCadena("PROD-001", 4)
+		transacciones = append(transacciones[:1], transacciones[2:]...)
+
+		cadena := services.VerificarCadenaProducto(transacciones, cabeza)
+		assert.False(t, cadena.Integra)
+		assert.Contains(t, tiposAnomalia(cadena), models.AnomaliaHueco)
+		assert.Equal(t, int64(2), cadena.Anomalias[0].Secuencia)
+	})
+
+	t.Run("Detecta eventos eliminados al final", func(t *testing.T) {
+		transacciones, cabeza := construirCadena("PROD-001", 4)
+
+		cadena := services.VerificarCadenaProducto(transacciones[:2], cabeza)
+		assert.False(t, cadena.Integra)
+		assert.Equal(t, []string{models.AnomaliaEliminacion}, tiposAnomalia(cadena))
+		assert.Equal(t, int64(4), cadena.Longitud)
+	})
+
+	t.Run("Detecta la eliminación de la cabeza de cadena", func(t *testing.T) {
+		transacciones, _ := construirCadena("PROD-001", 2)
+
+		cadena := services.VerificarCadenaProducto(transacciones, nil)
+		assert.Equal(t, []string{models.AnomaliaEliminacion}, tiposAnomalia(cadena))
+	})
+
+	t.Run("Detecta bifurcaciones", func(t *testing.T) {
+		transacciones, cabeza := construirCadena("PROD-001", 3)
+
+		// Un segundo evento enlazado al mismo anterior, escrito sin avanzar la cabeza
+		rama := GetMockTransaccionDistribucion("PROD-001")
+		rama.IDTransaction = "tx-rama"
+		rama.HashVersion = utils.HashVersionCadena
+		rama.Secuencia = 2
+		rama.HashEventoAnterior = transacciones[0].HashEvento
+		rama.HashEvento = utils.CalcularHashTransaccion(rama)
+
+		cadena := services.VerificarCadenaProducto(append(transacciones, rama), cabeza)
+		assert.False(t, cadena.Integra)
+		assert.Contains(t, tiposAnomalia(cadena), models.AnomaliaBifurcacion)
+	})
+
+	t.Run("Detecta enlaces alterados", func(t *testing.T) {
+		transacciones, cabeza := construirCadena("PROD-001", 3)
+		transacciones[1].HashEventoAnterior = "hash-falso"
+
+		cadena := services.VerificarCadenaProducto(transacciones, cabeza)
+		assert.False(t, cadena.Integra)
+		assert.Contains(t, tiposAnomalia(cadena), models.AnomaliaRuptura)
+	})
+
+	t.Run("Eventos anteriores al encadenamiento no rompen la cadena", func(t *testing.T) {
+		transacciones, cabeza := construirCadena("PROD-001", 2)
+		legacy := GetMockTransaccionFabricacion("PROD-001")
+		legacy.HashEvento = utils.CalcularHashTransaccion(legacy)
+
+		cadena := services.VerificarCadenaProducto(append([]*models.Transaccion{legacy}, transacciones...), cabeza)
+		require.True(t, cadena.Integra)
+		assert.Equal(t, 1, cadena.EventosSinEncadenar)
+	})
+
+	t.Run("El hash cubre la posición en la cadena", func(t *testing.T) {
+		transacciones, _ := construirCadena("PROD-001", 2)
+		original := transacciones[1].HashEvento
+
+		transacciones[1].HashEventoAnterior = ""
+		assert.NotEqual(t, original, utils.CalcularHashTransaccion(transacciones[1]))
+
+		transacciones[1].HashEventoAnterior = transacciones[0].HashEvento
+		transacciones[1].Secuencia = 3
+		assert.NotEqual(t, original, utils.CalcularHashTransaccion(transacciones[1]))
+	})
+}
+
+func TestOracleService_HistorialEliminado(t *testing.T) {
+	ctx := context.Background()
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	dynamo := NewMockDynamo()
+	defer dynamo.Close()
+
+	host, port := kubo.HostPort()
+	transaccionService := services.NewTransaccionService(nil, services.NewIPFSService(host, port), dynamo.Servicio())
+	oracle := services.NewOracleService(transaccionService, dynamo.Servicio())
+	request := GetMockTransaccionRequest()
+	for i := 0; i < 2; i++ {
+		_, err := transaccionService.RegistrarTransaccion(ctx, request)
+		require.NoError(t, err)
+	}
+
+	t.Run("Un producto sin eventos ni cabeza no existe", func(t *testing.T) {
+		_, err := oracle.ObtenerDatosVerificados(ctx, "PROD-INEXISTENTE")
+		assert.ErrorIs(t, err, services.ErrProductoSinEventos)
+	})
+
+	t.Run("Un producto con cabeza pero sin eventos reporta la eliminación", func(t *testing.T) {
+		dynamo.EliminarEventos(request.IDProducto)
+
+		datos, err := oracle.ObtenerDatosVerificados(ctx, request.IDProducto)
+		require.NoError(t, err)
+		assert.False(t, datos.CadenaVerificada)
+		assert.Equal(t, "no_verificado", datos.Estado)
+		assert.Empty(t, datos.Historial)
+		assert.Equal(t, "2", datos.Metadata["longitud_cadena"])
+		assert.Equal(t, "1", datos.Metadata["anomalias_cadena"])
+	})
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/pkg/canonical"
+)
+
+func TestCanonicalizar(t *testing.T) {
+	t.Run("Ejemplo de RFC 8785", func(t *testing.T) {
+		entrada := `{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`
+		esperado := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+
+		canonico, err := canonical.CanonicalizarString(entrada)
+		require.NoError(t, err)
+		assert.Equal(t, esperado, canonico)
+	})
+
+	t.Run("Orden de propiedades por unidades UTF-16", func(t *testing.T) {
+		entrada := `{"\u20ac":"Euro","\r":"CR","\ufb33":"Hebrew","1":"One","\ud83d\ude00":"Emoji","\u0080":"Control","\u00f6":"Latin"}`
+		esperado := "{\"\\r\":\"CR\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin\",\"€\":\"Euro\",\"😀\":\"Emoji\",\"\ufb33\":\"Hebrew\"}"
+
+		canonico, err := canonical.CanonicalizarString(entrada)
+		require.NoError(t, err)
+		assert.Equal(t, esperado, canonico)
+	})
+
+	t.Run("Formato de números ECMAScript", func(t *testing.T) {
+		casos := map[uint64]string{
+			0x0000000000000000: "0",
+			0x8000000000000000: "0",
+			0x0000000000000001: "5e-324",
+			0x8000000000000001: "-5e-324",
+			0x7fefffffffffffff: "1.7976931348623157e+308",
+			0x4340000000000000: "9007199254740992",
+			0x4430000000000000: "295147905179352830000",
+			0x44b52d02c7e14af5: "9.999999999999997e+22",
+			0x44b52d02c7e14af6: "1e+23",
+			0x3eb0c6f7a0b5ed8d: "0.000001",
+			0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+			0x41b3de4355555555: "333333333.3333333",
+			0x444b1ae4d6e2ef50: "1e+21",
+			0x444b1ae4d6e2ef4f: "999999999999999900000",
+		}
+
+		for bits, esperado := range casos {
+			entrada := strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64)
+			canonico, err := canonical.CanonicalizarString(entrada)
+			require.NoError(t, err, entrada)
+			assert.Equal(t, esperado, canonico, "entrada %s", entrada)
+		}
+	})
+
+	t.Run("JSON equivalente produce los mismos bytes", func(t *testing.T) {
+		a, err := canonical.CanonicalizarString(`{"lote": "12345", "cantidad": 1000}`)
+		require.NoError(t, err)
+		b, err := canonical.CanonicalizarString("{\n  \"cantidad\": 1.0e3,\n  \"lote\":\"12345\"\n}")
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("Rechaza propiedades duplicadas y JSON inválido", func(t *testing.T) {
+		_, err := canonical.CanonicalizarString(`{"lote": "1", "lote": "2"}`)
+		assert.Error(t, err)
+
+		_, err = canonical.CanonicalizarString(`{"lote": "1"} {}`)
+		assert.Error(t, err)
+
+		_, err = canonical.CanonicalizarString(`no es json`)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/pkg/client"
+)
+
+const claveArranqueCliente = "clave-de-arranque-con-al-menos-32-caracteres"
+
+// servidorCliente levanta el router real con almacenes en memoria y sin IPFS, blockchain ni DynamoDB
+func servidorCliente(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+	transacciones := services.NewTransaccionService(nil, nil, nil)
+	transacciones.ConfigurarPolitica(motor)
+	transacciones.ConfigurarLote(services.LoteConfig{MaximoElementos: 10, Concurrencia: 2})
+	apiKeys := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+
+	engine := router.Configurar(&config.Config{AuthEnabled: true, RateLimitRequests: 1000, RateLimitWindow: 60}, router.Dependencias{
+		TransaccionHandler: handlers.NewTransaccionHandler(transacciones),
+		APIKeyHandler:      handlers.NewAPIKeyHandler(apiKeys),
+		CertificadoHandler: handlers.NewCertificadoHandler(services.NewCertificadoService(services.NewAlmacenCertificadosMemoria())),
+		AuthConfig:         middleware.AuthConfig{APIKeys: apiKeys, ClaveArranque: claveArranqueCliente},
+		Politica:           motor,
+		Idempotencia:       services.NewIdempotenciaService(services.NewAlmacenIdempotenciaMemoria(), services.IdempotenciaConfig{}),
+	})
+	servidor := httptest.NewServer(engine)
+	t.Cleanup(servidor.Close)
+	return servidor
+}
+
+func TestClient(t *testing.T) {
+	servidor := servidorCliente(t)
+	ctx := context.Background()
+	admin := client.NewClient(servidor.URL, client.ConAPIKey(claveArranqueCliente))
+
+	creada, err := admin.CrearAPIKey(ctx, client.APIKeyRequest{Nombre: "ERP", Actor: "Distribuidora Norte", Roles: []string{"distribuidor"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, creada.Clave)
+	assert.Equal(t, "Distribuidora Norte", creada.APIKey.Actor)
+
+	distribuidor := client.NewClient(servidor.URL, client.ConToken(creada.Clave))
+
+	t.Run("Administración de API keys", func(t *testing.T) {
+		claves, err := admin.ListarAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, claves, 1)
+		assert.Equal(t, creada.APIKey.ID, claves[0].ID)
+	})
+
+	t.Run("Administración de certificados", func(t *testing.T) {
+		identidad, err := admin.RegistrarIdentidadCertificado(ctx, client.IdentidadCertificadoRequest{
+			Identidad: "spiffe://medisupply/farmacia", Nombre: "Farmacia", Actor: "Farmacia Central", Roles: []string{"farmacia"},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, identidad.RevocadaEn)
+
+		revocada, err := admin.RevocarIdentidadCertificado(ctx, "spiffe://medisupply/farmacia")
+		require.NoError(t, err)
+		assert.NotNil(t, revocada.RevocadaEn)
+
+		identidades, err := admin.ListarIdentidadesCertificado(ctx)
+		require.NoError(t, err)
+		assert.Len(t, identidades, 1)
+	})
+
+	t.Run("Denegación de la política", func(t *testing.T) {
+		_, err := distribuidor.ListarAPIKeys(ctx)
+		var errAPI *client.Error
+		require.True(t, errors.As(err, &errAPI))
+		assert.Equal(t, http.StatusForbidden, errAPI.StatusCode)
+		assert.Equal(t, "administrar-actores", errAPI.Regla)
+		assert.Equal(t, middleware.CodigoAccesoDenegado, client.CodigoError(err))
+	})
+
+	t.Run("Lote rechazado con resultados por elemento", func(t *testing.T) {
+		_, err := distribuidor.RegistrarLote(ctx, []client.TransaccionRequest{
+			{TipoEvento: "distribucion", IDProducto: "PROD-001", DatosEvento: `{"destino":"Farmacia Central"}`},
+			{TipoEvento: "distribucion", IDProducto: "PROD-002", DatosEvento: `{}`},
+		}, client.ConIdempotencyKey("lote-1"))
+		var errAPI *client.Error
+		require.True(t, errors.As(err, &errAPI))
+		assert.Equal(t, http.StatusBadRequest, errAPI.StatusCode)
+		assert.Equal(t, middleware.CodigoLoteInvalido, errAPI.Codigo)
+		require.Len(t, errAPI.Resultados, 2)
+		assert.Equal(t, models.LoteInvalida, errAPI.Resultados[1].Estado)
+	})
+
+	t.Run("Sin credenciales", func(t *testing.T) {
+		_, err := client.NewClient(servidor.URL).ObtenerTransaccion(ctx, "TX-1")
+		var errAPI *client.Error
+		require.True(t, errors.As(err, &errAPI))
+		assert.Equal(t, http.StatusUnauthorized, errAPI.StatusCode)
+		assert.Equal(t, middleware.CodigoNoAutenticado, errAPI.Codigo)
+	})
+
+	t.Run("Revocar API key", func(t *testing.T) {
+		revocada, err := admin.RevocarAPIKey(ctx, creada.APIKey.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, revocada.RevocadaEn)
+	})
+}
+
+// TestClient_TiposCoincidenConOpenAPI evita que pkg/client se desvíe de la especificación
+func TestClient_TiposCoincidenConOpenAPI(t *testing.T) {
+	doc := leerOpenAPI(t)
+	tipos := []any{
+		client.TransaccionRequest{}, client.Transaccion{}, client.TransaccionResponse{}, client.Adjunto{},
+		client.ResultadoLote{}, client.LoteResponse{}, client.VerificacionResponse{}, client.EstadoBlockchainResponse{},
+		client.EventoVerificado{}, client.AnomaliaCadena{}, client.VerificacionCadena{}, client.HistorialVerificado{},
+		client.OracleDataResponse{}, client.ValidacionCadenaResponse{}, client.APIKeyRequest{}, client.APIKey{},
+		client.APIKeyCreadaResponse{}, client.IdentidadCertificadoRequest{}, client.IdentidadCertificado{},
+		client.Notificacion{}, client.MensajeStream{}, client.WebhookRequest{}, client.Webhook{},
+		client.WebhookCreadoResponse{}, client.IntentoEntrega{}, client.EntregaWebhook{}, client.EventoWebhook{},
+		client.EstadoFondos{},
+	}
+	for _, tipo := range tipos {
+		nombre := reflect.TypeOf(tipo).Name()
+		esquema, ok := doc.Components.Schemas[nombre]
+		if !assert.True(t, ok, "falta el esquema %s", nombre) {
+			continue
+		}
+		documentados := make([]string, 0, len(esquema.Properties))
+		for propiedad := range esquema.Properties {
+			documentados = append(documentados, propiedad)
+		}
+		sort.Strings(documentados)
+		assert.Equal(t, documentados, camposJSON(reflect.TypeOf(tipo)), "campos de client.%s", nombre)
+	}
+}
@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+)
+
+// claveCifrado cumple la longitud exigida por ENCRYPTION_KEY
+var claveCifrado = strings.Repeat("k", 32)
+
+// entornoConfig elimina las variables que usan las pruebas de configuración; Cargar no ignora las vacías
+func entornoConfig(t *testing.T) {
+	for _, nombre := range []string{
+		"CONFIG_FILE", "ENCRYPTION_KEY", "ENCRYPTION_KEY_FILE", "LOG_LEVEL", "SERVER_PORT", "HTTP_ENABLED",
+		"RATE_LIMIT_REQUESTS", "RATE_LIMIT_WINDOW", "RATE_LIMIT_ROLES", "IPFS_NODES", "CORS_ALLOWED_ORIGINS",
+		"IPFS_HOST", "IPFS_HOST_FILE", "AUTH_JWT_HMAC_SECRET", "AUTH_JWT_HMAC_SECRET_FILE",
+	} {
+		sinVariable(t, nombre)
+	}
+}
+
+// sinVariable elimina una variable de entorno durante la prueba y restaura su valor al terminar
+func sinVariable(t *testing.T, nombre string) {
+	t.Helper()
+	if anterior, ok := os.LookupEnv(nombre); ok {
+		t.Cleanup(func() { _ = os.Setenv(nombre, anterior) })
+	}
+	require.NoError(t, os.Unsetenv(nombre))
+}
+
+// escribirArchivo crea un archivo temporal con el contenido dado
+func escribirArchivo(t *testing.T, nombre, contenido string) string {
+	ruta := filepath.Join(t.TempDir(), nombre)
+	require.NoError(t, os.WriteFile(ruta, []byte(contenido), 0o600))
+	return ruta
+}
+
+func TestConfig_ArchivoYAML(t *testing.T) {
+	entornoConfig(t)
+	archivo := escribirArchivo(t, "config.yaml", `
+encryption_key: `+claveCifrado+`
+log_level: debug
+ipfs_nodes: [kubo-1:5001, kubo-2:5001]
+rate_limit:
+  requests: 50
+  window: 30
+  roles: admin=500
+`)
+
+	cfg, err := config.Cargar(archivo)
+	require.NoError(t, err)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, []string{"kubo-1:5001", "kubo-2:5001"}, cfg.IPFSNodes)
+	assert.Equal(t, 50, cfg.RateLimitRequests)
+	assert.Equal(t, 30, cfg.RateLimitWindow)
+	assert.Equal(t, "admin=500", cfg.RateLimitRoles)
+	assert.Equal(t, "8080", cfg.ServerPort, "los valores ausentes conservan el valor por defecto")
+}
+
+func TestConfig_ArchivoTOMLYEntorno(t *testing.T) {
+	entornoConfig(t)
+	archivo := escribirArchivo(t, "config.toml", `
+encryption_key = "`+claveCifrado+`"
+log_level = "debug"
+http_enabled = true
+
+[rate_limit]
+requests = 50
+`)
+	t.Setenv("CONFIG_FILE", archivo)
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("RATE_LIMIT_REQUESTS", "75")
+
+	cfg, err := config.Cargar("")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.LogLevel, "el entorno tiene prioridad sobre el archivo")
+	assert.Equal(t, 75, cfg.RateLimitRequests)
+	assert.True(t, cfg.HTTPEnabled)
+}
+
+func TestConfig_EntornoVacioReemplazaArchivo(t *testing.T) {
+	entornoConfig(t)
+	archivo := escribirArchivo(t, "config.yaml", `
+encryption_key: `+claveCifrado+`
+ipfs_nodes: [kubo-1:5001, kubo-2:5001]
+`)
+	t.Setenv("IPFS_NODES", "")
+
+	cfg, err := config.Cargar(archivo)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.IPFSNodes, "una variable definida aunque vacía reemplaza el valor del archivo")
+
+	// La plantilla .env deja los secretos en blanco: la variable vacía no choca con NOMBRE_FILE
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("ENCRYPTION_KEY_FILE", escribirArchivo(t, "clave", claveCifrado))
+	cfg, err = config.Cargar(archivo)
+	require.NoError(t, err)
+	assert.Equal(t, claveCifrado, cfg.EncryptionKey)
+}
+
+func TestConfig_ErroresReunidos(t *testing.T) {
+	entornoConfig(t)
+	archivo := escribirArchivo(t, "config.yaml", `
+encryption_key: corta
+log_leve: debug
+`)
+	t.Setenv("RATE_LIMIT_REQUESTS", "cien")
+	t.Setenv("HTTP_ENABLED", "quizas")
+
+	cfg, err := config.Cargar(archivo)
+	require.Error(t, err)
+	require.NotNil(t, cfg, "la configuración inválida se retorna para --print-config")
+	for _, esperado := range []string{
+		"RATE_LIMIT_REQUESTS: 'cien' no es un número entero",
+		"HTTP_ENABLED: 'quizas' no es true ni false",
+		"clave 'log_leve' desconocida",
+		"ENCRYPTION_KEY debe tener exactamente 32 caracteres",
+	} {
+		assert.ErrorContains(t, err, esperado)
+	}
+
+	_, err = config.Cargar(escribirArchivo(t, "config.json", "{}"))
+	assert.ErrorContains(t, err, "extensión no soportada")
+}
+
+func TestConfig_SecretosDesdeArchivo(t *testing.T) {
+	entornoConfig(t)
+	t.Setenv("ENCRYPTION_KEY_FILE", escribirArchivo(t, "clave", claveCifrado+"\n"))
+
+	cfg, err := config.Cargar("")
+	require.NoError(t, err)
+	assert.Equal(t, claveCifrado, cfg.EncryptionKey, "se descarta el salto de línea final")
+
+	t.Setenv("ENCRYPTION_KEY", claveCifrado)
+	_, err = config.Cargar("")
+	assert.ErrorContains(t, err, "ENCRYPTION_KEY y ENCRYPTION_KEY_FILE son excluyentes")
+
+	sinVariable(t, "ENCRYPTION_KEY_FILE")
+	t.Setenv("IPFS_HOST_FILE", escribirArchivo(t, "host", "kubo"))
+	_, err = config.Cargar("")
+	assert.ErrorContains(t, err, "IPFS_HOST_FILE: solo los secretos se pueden leer de un archivo")
+
+	sinVariable(t, "IPFS_HOST_FILE")
+	t.Setenv("AUTH_JWT_HMAC_SECRET_FILE", filepath.Join(t.TempDir(), "no-existe"))
+	_, err = config.Cargar("")
+	assert.ErrorContains(t, err, "AUTH_JWT_HMAC_SECRET_FILE")
+}
+
+func TestConfig_Imprimir(t *testing.T) {
+	entornoConfig(t)
+	t.Setenv("ENCRYPTION_KEY", claveCifrado)
+	t.Setenv("AUTH_JWT_HMAC_SECRET", "secreto-hmac-de-prueba-de-32-caracteres")
+
+	cfg, err := config.Cargar("")
+	require.NoError(t, err)
+	var salida bytes.Buffer
+	require.NoError(t, cfg.Imprimir(&salida))
+
+	impreso := salida.String()
+	assert.Contains(t, impreso, "encryption_key: '"+redaccion.Marcador+"'")
+	assert.Contains(t, impreso, "auth_jwt_hmac_secret: '"+redaccion.Marcador+"'")
+	assert.Contains(t, impreso, "auth_bootstrap_api_key: \"\"", "los secretos vacíos no se marcan")
+	assert.Contains(t, impreso, "server_port: \"8080\"")
+	assert.NotContains(t, impreso, claveCifrado)
+	assert.NotContains(t, impreso, "secreto-hmac-de-prueba-de-32-caracteres")
+}
+
+func TestConfig_Cambios(t *testing.T) {
+	vigente := configuracionValida()
+	nueva := configuracionValida()
+	nueva.LogLevel = "debug"
+	nueva.RateLimitRequests = 500
+	nueva.ServerPort = "8081"
+
+	recargables, requierenReinicio := vigente.Cambios(nueva)
+	assert.Equal(t, []string{"LOG_LEVEL", "RATE_LIMIT_REQUESTS"}, recargables)
+	assert.Equal(t, []string{"SERVER_PORT"}, requierenReinicio)
+
+	aplicada := vigente.ConRecargables(nueva)
+	assert.Equal(t, "debug", aplicada.LogLevel)
+	assert.Equal(t, 500, aplicada.RateLimitRequests)
+	assert.Equal(t, "8080", aplicada.ServerPort, "lo que requiere reiniciar conserva el valor vigente")
+	assert.Equal(t, "info", vigente.LogLevel, "la configuración vigente no se modifica")
+}
+
+func TestConfig_RecargaRateLimitYCORS(t *testing.T) {
+	limitador := limitadorPrueba(ratelimit.NewMemoria(), 1)
+	ctx := context.Background()
+	assert.True(t, limitador.Admitir(ctx, "10.0.0.1", nil, http.MethodGet, "/health").Permitida)
+	assert.False(t, limitador.Admitir(ctx, "10.0.0.1", nil, http.MethodGet, "/health").Permitida)
+
+	limitador.ActualizarCuotas(ratelimit.Cuotas{PorDefecto: ratelimit.Cuota{Limite: 3, Ventana: time.Hour}})
+	assert.True(t, limitador.Admitir(ctx, "10.0.0.1", nil, http.MethodGet, "/health").Permitida, "la nueva cuota aplica en la ventana en curso")
+
+	politica, err := middleware.NewCORS(middleware.ConfigCORS{Origenes: []string{"https://app.medisupply.com"}})
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(politica.Middleware())
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	origenPermitido := func(origen string) string {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Origin", origen)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	assert.Equal(t, "https://app.medisupply.com", origenPermitido("https://app.medisupply.com"))
+	assert.Empty(t, origenPermitido("https://panel.medisupply.com"))
+
+	require.NoError(t, politica.Actualizar(middleware.ConfigCORS{Origenes: []string{"https://panel.medisupply.com"}}))
+	assert.Equal(t, "https://panel.medisupply.com", origenPermitido("https://panel.medisupply.com"))
+	assert.Empty(t, origenPermitido("https://app.medisupply.com"))
+
+	assert.Error(t, politica.Actualizar(middleware.ConfigCORS{}))
+	assert.Equal(t, "https://panel.medisupply.com", origenPermitido("https://panel.medisupply.com"), "un cambio inválido conserva la política vigente")
+}
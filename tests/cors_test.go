@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+)
+
+// routerCORS crea un router con la política CORS de la configuración dada
+func routerCORS(t *testing.T, cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	politica, err := middleware.NewCORS(router.ConfigCORS(cfg))
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(politica.Middleware())
+	r.GET("/api/v1/transaccion/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+// peticionCORS envía una petición con Origin; preflight la convierte en OPTIONS con Access-Control-Request-Method
+func peticionCORS(r *gin.Engine, origen string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transaccion/TX-1", nil)
+	if preflight {
+		req = httptest.NewRequest(http.MethodOptions, "/api/v1/transaccion/TX-1", nil)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+	req.Header.Set("Origin", origen)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_PorDefecto(t *testing.T) {
+	entornoConfig(t)
+	t.Setenv("ENCRYPTION_KEY", claveCifrado)
+	cfg, err := config.Cargar("")
+	require.NoError(t, err)
+	assert.False(t, cfg.CORSAllowCredentials, "sin credenciales por defecto, ya que se permite cualquier origen")
+
+	r := routerCORS(t, cfg)
+	w := peticionCORS(r, "https://cualquiera.example.com", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	expuestas := w.Header().Get("Access-Control-Expose-Headers")
+	for _, cabecera := range []string{middleware.CabeceraRequestID, middleware.CabeceraRateLimitLimit, middleware.CabeceraRateLimitRemaining, middleware.CabeceraRateLimitReset, middleware.CabeceraRetryAfter} {
+		assert.Contains(t, expuestas, http.CanonicalHeaderKey(cabecera))
+	}
+
+	w = peticionCORS(r, "https://cualquiera.example.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), http.CanonicalHeaderKey(middleware.CabeceraIdempotencia))
+	assert.Equal(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_OrigenesConComodin(t *testing.T) {
+	cfg := configuracionValida()
+	cfg.CORSAllowedOrigins = []string{"https://*.medisupply.com", "http://localhost:3000"}
+	cfg.CORSAllowedMethods = []string{"GET", "POST"}
+	cfg.CORSAllowedHeaders = []string{"Authorization", "Content-Type"}
+	cfg.CORSExposedHeaders = []string{"X-Request-ID"}
+	cfg.CORSAllowCredentials = true
+	cfg.CORSMaxAge = 600
+	require.NoError(t, cfg.Validate())
+	r := routerCORS(t, cfg)
+
+	for _, origen := range []string{"https://app.medisupply.com", "https://panel.eu.medisupply.com", "https://APP.medisupply.com", "http://localhost:3000"} {
+		w := peticionCORS(r, origen, false)
+		assert.Equal(t, http.StatusOK, w.Code, origen)
+		assert.Equal(t, origen, w.Header().Get("Access-Control-Allow-Origin"), origen)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), origen)
+	}
+
+	for _, origen := range []string{
+		"https://medisupply.com",          // El comodín no incluye el propio dominio
+		"http://app.medisupply.com",       // Otro esquema
+		"https://app.medisupply.com:8443", // Otro puerto
+		"https://medisupply.com.atacante.com",
+		"https://atacantemedisupply.com",
+		"http://localhost:3001",
+	} {
+		w := peticionCORS(r, origen, false)
+		assert.Equal(t, http.StatusForbidden, w.Code, origen)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origen)
+	}
+
+	w := peticionCORS(r, "https://app.medisupply.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET,POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization,Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_Validacion(t *testing.T) {
+	cfg := configuracionValida()
+	cfg.CORSAllowCredentials = true
+	assert.ErrorContains(t, cfg.Validate(), "CORS_ALLOW_CREDENTIALS=true no se admite con CORS_ALLOWED_ORIGINS=*")
+	_, err := middleware.NewCORS(middleware.ConfigCORS{Origenes: []string{"*"}, Credenciales: true})
+	assert.Error(t, err, "el middleware también rechaza la combinación")
+
+	for _, origen := range []string{"https://*.*.medisupply.com", "https://app.medisupply.com/ruta", "ftp://medisupply.com", "https://*.", "medisupply.com", "https:app.medisupply.com"} {
+		cfg := configuracionValida()
+		cfg.CORSAllowedOrigins = []string{origen}
+		assert.ErrorContains(t, cfg.Validate(), "CORS_ALLOWED_ORIGINS", origen)
+		_, err := middleware.NewCORS(middleware.ConfigCORS{Origenes: []string{origen}})
+		assert.Error(t, err, origen)
+	}
+
+	cfg = configuracionValida()
+	cfg.CORSAllowedMethods = []string{"GET", "FETCH"}
+	cfg.CORSExposedHeaders = []string{"X-Request-ID: 1"}
+	cfg.CORSMaxAge = -1
+	err = cfg.Validate()
+	assert.ErrorContains(t, err, "CORS_ALLOWED_METHODS: método 'FETCH' no soportado")
+	assert.ErrorContains(t, err, "'X-Request-ID: 1' no es un nombre de cabecera válido")
+	assert.ErrorContains(t, err, "CORS_MAX_AGE no puede ser negativo")
+}
@@ -0,0 +1,317 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// atributo es un valor de DynamoDB en el protocolo JSON ({"S": "..."}, {"N": "..."}, ...)
+type atributo map[string]any
+
+// MockDynamo simula en memoria las tablas de transacciones y de control de DynamoDB: lecturas por clave,
+// cabezas de cadena, escrituras transaccionales que se aplican completas o se cancelan si la condición de
+// la cabeza no se cumple, actualizaciones SET/REMOVE, la reserva condicional del anclaje, el Scan de
+// pendientes o de un producto y el Query del índice de entregas de webhook pendientes
+type MockDynamo struct {
+	Server *httptest.Server
+
+	mu            sync.Mutex
+	cabezas       map[string]map[string]atributo
+	eventos       []map[string]atributo // En orden de escritura; comparten el mapa con transacciones
+	transacciones map[string]map[string]atributo
+	rechazos      int
+	fallar        string // Los UpdateItem cuya expresión contiene este fragmento fallan
+}
+
+// NewMockDynamo inicia un DynamoDB simulado
+func NewMockDynamo() *MockDynamo {
+	m := &MockDynamo{
+		cabezas:       make(map[string]map[string]atributo),
+		transacciones: make(map[string]map[string]atributo),
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+// Close detiene el servidor simulado
+func (m *MockDynamo) Close() {
+	m.Server.Close()
+}
+
+// Servicio retorna un DynamoDBService conectado al servidor simulado
+func (m *MockDynamo) Servicio() *services.DynamoDBService {
+	return services.NewDynamoDBService(dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("id", "secreto", ""),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(m.Server.URL),
+	}), "transacciones", "control")
+}
+
+// Estados cuenta las transacciones guardadas por estado
+func (m *MockDynamo) Estados() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	estados := make(map[string]int)
+	for _, transaccion := range m.eventos {
+		estados[fmt.Sprint(transaccion["estado"]["S"])]++
+	}
+	return estados
+}
+
+// FallarActualizaciones hace fallar los UpdateItem cuya expresión contiene fragmento; "" deja de fallar
+func (m *MockDynamo) FallarActualizaciones(fragmento string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallar = fragmento
+}
+
+// VencerReservas simula que pasó la duración de las reservas de anclaje
+func (m *MockDynamo) VencerReservas() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, transaccion := range m.transacciones {
+		if _, ok := transaccion["anclajeHasta"]; ok {
+			transaccion["anclajeHasta"] = atributo{"N": "0"}
+		}
+	}
+}
+
+// EliminarEventos borra las transacciones de un producto sin tocar su cabeza de cadena, como lo haría
+// quien manipula la tabla directamente
+func (m *MockDynamo) EliminarEventos(idProducto string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	eventos := m.eventos[:0]
+	for _, transaccion := range m.eventos {
+		if transaccion["idProducto"]["S"] == idProducto {
+			delete(m.transacciones, fmt.Sprint(transaccion["idTransaction"]["S"]))
+			continue
+		}
+		eventos = append(eventos, transaccion)
+	}
+	m.eventos = eventos
+}
+
+func (m *MockDynamo) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	destino := r.Header.Get("X-Amz-Target")
+	switch {
+	case strings.HasSuffix(destino, ".GetItem"):
+		var entrada struct{ Key map[string]atributo }
+		_ = json.NewDecoder(r.Body).Decode(&entrada)
+		m.mu.Lock()
+		item, ok := m.cabezas[fmt.Sprint(entrada.Key["pk"]["S"])]
+		if id, esTransaccion := entrada.Key["idTransaction"]; esTransaccion {
+			item, ok = m.transacciones[fmt.Sprint(id["S"])]
+		}
+		respuesta := []byte(`{}`)
+		if ok {
+			respuesta, _ = json.Marshal(map[string]any{"Item": item})
+		}
+		m.mu.Unlock()
+		// Ensancha la ventana entre leer la cabeza y escribir, para que los registros compitan
+		time.Sleep(time.Millisecond)
+		_, _ = w.Write(respuesta)
+	case strings.HasSuffix(destino, ".TransactWriteItems"):
+		m.transaccion(w, r)
+	case strings.HasSuffix(destino, ".UpdateItem"):
+		m.actualizar(w, r)
+	case strings.HasSuffix(destino, ".Scan"):
+		m.recorrer(w, r)
+	case strings.HasSuffix(destino, ".PutItem"):
+		m.guardar(w, r)
+	case strings.HasSuffix(destino, ".Query"):
+		m.consultar(w, r)
+	default:
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+func (m *MockDynamo) transaccion(w http.ResponseWriter, r *http.Request) {
+	var entrada struct {
+		TransactItems []struct {
+			Put struct {
+				TableName                 string
+				Item                      map[string]atributo
+				ConditionExpression       string
+				ExpressionAttributeValues map[string]atributo
+			}
+		}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&entrada)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, elemento := range entrada.TransactItems {
+		put := elemento.Put
+		if put.TableName != "control" {
+			continue
+		}
+		cabeza, existe := m.cabezas[fmt.Sprint(put.Item["pk"]["S"])]
+		cumple := !existe
+		if put.ConditionExpression != "attribute_not_exists(pk)" {
+			cumple = existe && cabeza["secuencia"]["N"] == put.ExpressionAttributeValues[":anterior"]["N"]
+		}
+		if !cumple {
+			m.rechazos++
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","message":"Transaction cancelled","CancellationReasons":[{"Code":"ConditionalCheckFailed"}]}`))
+			return
+		}
+	}
+	for _, elemento := range entrada.TransactItems {
+		if elemento.Put.TableName == "control" {
+			m.cabezas[fmt.Sprint(elemento.Put.Item["pk"]["S"])] = elemento.Put.Item
+		} else {
+			m.eventos = append(m.eventos, elemento.Put.Item)
+			m.transacciones[fmt.Sprint(elemento.Put.Item["idTransaction"]["S"])] = elemento.Put.Item
+		}
+	}
+	_, _ = w.Write([]byte(`{}`))
+}
+
+// actualizar aplica un UpdateItem sobre la tabla de transacciones; solo evalúa la condición de la
+// reserva de anclaje, las demás se dan por cumplidas, y entre los ReturnValues solo entiende ALL_NEW
+func (m *MockDynamo) actualizar(w http.ResponseWriter, r *http.Request) {
+	var entrada struct {
+		TableName                 string
+		Key                       map[string]atributo
+		UpdateExpression          string
+		ConditionExpression       string
+		ExpressionAttributeValues map[string]atributo
+		ReturnValues              string
+	}
+	_ = json.NewDecoder(r.Body).Decode(&entrada)
+	if entrada.TableName != "transacciones" {
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+	valores := entrada.ExpressionAttributeValues
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fallar != "" && strings.Contains(entrada.UpdateExpression, m.fallar) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"fallo simulado"}`))
+		return
+	}
+	item := m.transacciones[fmt.Sprint(entrada.Key["idTransaction"]["S"])]
+	if strings.Contains(entrada.ConditionExpression, "anclajeHasta") {
+		cumple := item != nil && item["estado"]["S"] == valores[":pendiente"]["S"]
+		if reserva, ok := item["anclajeHasta"]; cumple && ok {
+			cumple = numero(reserva) < numero(valores[":ahoraMs"])
+		}
+		if !cumple {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`))
+			return
+		}
+	}
+	if item == nil {
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+
+	if atributos, ok := strings.CutPrefix(entrada.UpdateExpression, "REMOVE "); ok {
+		for _, nombre := range strings.Split(atributos, ",") {
+			delete(item, strings.TrimSpace(nombre))
+		}
+	} else if asignaciones, ok := strings.CutPrefix(entrada.UpdateExpression, "SET "); ok {
+		for _, asignacion := range strings.Split(asignaciones, ",") {
+			nombre, valor, _ := strings.Cut(asignacion, "=")
+			item[strings.TrimSpace(nombre)] = valores[strings.TrimSpace(valor)]
+		}
+	}
+	if entrada.ReturnValues == "ALL_NEW" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"Attributes": item})
+		return
+	}
+	_, _ = w.Write([]byte(`{}`))
+}
+
+// guardar aplica un PutItem sin condición sobre la tabla de control
+func (m *MockDynamo) guardar(w http.ResponseWriter, r *http.Request) {
+	var entrada struct {
+		TableName string
+		Item      map[string]atributo
+	}
+	_ = json.NewDecoder(r.Body).Decode(&entrada)
+	if entrada.TableName == "control" {
+		m.mu.Lock()
+		m.cabezas[fmt.Sprint(entrada.Item["pk"]["S"])] = entrada.Item
+		m.mu.Unlock()
+	}
+	_, _ = w.Write([]byte(`{}`))
+}
+
+// consultar responde un Query del índice de entregas pendientes de la tabla de control en una sola
+// página; como el índice real, solo ve los items que tienen colaEntrega
+func (m *MockDynamo) consultar(w http.ResponseWriter, r *http.Request) {
+	var entrada struct {
+		IndexName                 string
+		ExpressionAttributeValues map[string]atributo
+	}
+	_ = json.NewDecoder(r.Body).Decode(&entrada)
+	if entrada.IndexName != "entregas-pendientes" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"The table does not have the specified index"}`))
+		return
+	}
+	valores := entrada.ExpressionAttributeValues
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items := []map[string]atributo{}
+	for _, item := range m.cabezas {
+		cola, ok := item["colaEntrega"]
+		if ok && cola["S"] == valores[":pendiente"]["S"] && numero(item["proximoIntento"]) <= numero(valores[":ahora"]) &&
+			numero(item["bloqueoHasta"]) < numero(valores[":ahoraMs"]) {
+			items = append(items, item)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"Items": items, "Count": len(items)})
+}
+
+// recorrer responde un Scan de la tabla de transacciones en una sola página; solo entiende los filtros
+// por estado y por producto
+func (m *MockDynamo) recorrer(w http.ResponseWriter, r *http.Request) {
+	var entrada struct {
+		FilterExpression          string
+		ExpressionAttributeValues map[string]atributo
+	}
+	_ = json.NewDecoder(r.Body).Decode(&entrada)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items := []map[string]atributo{}
+	for _, transaccion := range m.eventos {
+		if !filtra(transaccion, entrada.FilterExpression, entrada.ExpressionAttributeValues) {
+			items = append(items, transaccion)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"Items": items, "Count": len(items), "ScannedCount": len(m.eventos)})
+}
+
+// filtra indica si el filtro "atributo = :valor" de un Scan descarta la transacción
+func filtra(transaccion map[string]atributo, filtro string, valores map[string]atributo) bool {
+	for _, nombre := range []string{"estado", "idProducto"} {
+		if _, valor, ok := strings.Cut(filtro, nombre+" = "); ok && transaccion[nombre]["S"] != valores[valor]["S"] {
+			return true
+		}
+	}
+	return false
+}
+
+func numero(valor atributo) int64 {
+	n, _ := strconv.ParseInt(fmt.Sprint(valor["N"]), 10, 64)
+	return n
+}
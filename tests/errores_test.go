@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+)
+
+func TestProblemaDesdeError(t *testing.T) {
+	errRed := errors.New("dial tcp 10.0.0.5:5001: connection refused")
+
+	casos := []struct {
+		nombre  string
+		err     error
+		status  int
+		codigo  string
+		detalle string // "" = el problema no debe traer detalle
+	}{
+		{"No encontrado", fmt.Errorf("error obteniendo transacción: %w", services.ErrTransaccionNoEncontrada), http.StatusNotFound, "transaccion-no-encontrada", "error obteniendo transacción: transacción no encontrada"},
+		{"Validación con causa", services.ErrSolicitudInvalida.Con(errors.New("falta idProducto")), http.StatusBadRequest, "solicitud-invalida", "validación fallida: falta idProducto"},
+		{"Conflicto", services.ErrEntregaNoReenviable, http.StatusConflict, "entrega-no-reenviable", ""},
+		{"No procesable", services.ErrIdempotenciaCuerpoDistinto, http.StatusUnprocessableEntity, "idempotencia-cuerpo-distinto", ""},
+		{"Adjunto no permitido", fmt.Errorf("%w: tipo image/gif", services.ErrAdjuntoNoPermitido), http.StatusUnsupportedMediaType, "adjunto-no-permitido", "adjunto no permitido: tipo image/gif"},
+		{"Dependencia caída", services.ErrorDependencia(services.DependenciaIPFS, errRed), http.StatusServiceUnavailable, "ipfs-no-disponible", ""},
+		{"Dependencia sin respuesta", services.ErrorDependencia(services.DependenciaDynamoDB, fmt.Errorf("guardando: %w", context.DeadlineExceeded)), http.StatusGatewayTimeout, "dynamodb-timeout", ""},
+		{"Cuerpo que supera el límite", services.ErrorDependencia(services.DependenciaIPFS, &http.MaxBytesError{Limit: 1024}), http.StatusRequestEntityTooLarge, middleware.CodigoCuerpoDemasiadoGrande, "el cuerpo de la petición supera 1024 bytes"},
+		{"Denegación", &policy.Denegacion{Regla: "consultar-oracle", Motivo: "rol insuficiente"}, http.StatusForbidden, middleware.CodigoAccesoDenegado, "rol insuficiente"},
+		{"Error sin clasificar", errRed, http.StatusInternalServerError, middleware.CodigoErrorInterno, ""},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			problema := middleware.ProblemaDesdeError(caso.err, i18n.IdiomaPorDefecto)
+			assert.Equal(t, caso.status, problema.Status)
+			assert.Equal(t, caso.codigo, problema.Codigo)
+			assert.Equal(t, "urn:medisupply:problema:"+caso.codigo, problema.Tipo)
+			assert.NotEmpty(t, problema.Titulo)
+			assert.Equal(t, caso.detalle, problema.Detalle)
+		})
+	}
+
+	t.Run("El dominio se conserva al envolver y al agregar causa", func(t *testing.T) {
+		err := fmt.Errorf("registrando: %w", services.ErrorDependencia(services.DependenciaDynamoDB, services.ErrConflictoCadena))
+		assert.True(t, errors.Is(err, services.ErrConflictoCadena))
+		assert.Equal(t, "conflicto-cadena", middleware.ProblemaDesdeError(err, i18n.IdiomaPorDefecto).Codigo)
+		assert.True(t, errors.Is(services.ErrSolicitudInvalida.Con(errors.New("x")), services.ErrSolicitudInvalida))
+	})
+}
+
+func TestErroresMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.ErroresMiddleware())
+	engine.GET("/caido", func(c *gin.Context) {
+		c.Error(services.ErrorDependencia(services.DependenciaIPFS, errors.New("dial tcp 10.0.0.5:5001: connection refused")))
+	})
+	engine.GET("/escrito", func(c *gin.Context) {
+		c.Error(errors.New("registrado solo para el log"))
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/caido", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, models.TipoContenidoProblema, w.Header().Get("Content-Type"))
+	assert.NotContains(t, w.Body.String(), "10.0.0.5", "los errores del servidor no exponen su causa")
+
+	var problema models.Problema
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problema))
+	assert.Equal(t, "ipfs-no-disponible", problema.Codigo)
+	assert.Equal(t, "/caido", problema.Instancia)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/escrito", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "una respuesta ya escrita no se reemplaza")
+}
+
+// TestErrores_IPFSNoEncontradoVsCaido comprueba que un CID inexistente y un nodo caído llegan al cliente con códigos distintos
+func TestErrores_IPFSNoEncontradoVsCaido(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	kubo := NewMockKubo()
+	host, port := kubo.HostPort()
+	engine := router.Configurar(&config.Config{RateLimitRequests: 1000, RateLimitWindow: 60}, router.Dependencias{
+		IPFSHandler: handlers.NewIPFSHandler(services.NewIPFSService(host, port)),
+	})
+
+	consultar := func() (int, models.Problema) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/ipfs/archivo/QmInexistente", nil))
+		var problema models.Problema
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problema))
+		return w.Code, problema
+	}
+
+	status, problema := consultar()
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "cid-no-encontrado", problema.Codigo)
+
+	kubo.Close()
+	status, problema = consultar()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "ipfs-no-disponible", problema.Codigo)
+	assert.NotContains(t, problema.Detalle, port)
+}
+
+// TestVerificarIntegridad_ErrorSinDetalle comprueba que un fallo de la blockchain no expone su causa
+// (que incluye la URL del RPC) en el mensaje de la verificación
+func TestVerificarIntegridad_ErrorSinDetalle(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	dynamo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		_, _ = w.Write([]byte(`{"Item":{"idTransaction":{"S":"tx-1"},"estado":{"S":"confirmado"},` +
+			`"hashVersion":{"N":"` + strconv.Itoa(utils.HashVersionActual) + `"},` +
+			`"directionBlockchain":{"S":"0x1111111111111111111111111111111111111111111111111111111111111111"}}}`))
+	}))
+	defer dynamo.Close()
+
+	servicio := services.NewTransaccionService(blockchainSimple(t, ethereum), nil, services.NewDynamoDBService(dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("id", "secreto", ""),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(dynamo.URL),
+	}), "transacciones", "control"))
+	ethereum.Close()
+
+	respuesta, err := servicio.VerificarIntegridad(context.Background(), "tx-1")
+	require.NoError(t, err)
+	assert.False(t, respuesta.Verificado)
+	assert.Equal(t, i18n.Traducir(i18n.IdiomaPorDefecto, "verificacion.error-blockchain"), respuesta.Mensaje)
+	assert.NotContains(t, respuesta.Mensaje, ethereum.URL())
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ClavePruebaEthereum es la primera cuenta de desarrollo de Hardhat/Anvil; nunca tiene fondos reales
+const ClavePruebaEthereum = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// ContratoPrueba es la dirección del primer contrato desplegado por esa cuenta en una red local
+const ContratoPrueba = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+
+// MockEthereum simula los métodos JSON-RPC de un nodo Ethereum: los de solo lectura, el envío de
+// transacciones firmadas, que descuenta su costo máximo del saldo o las rechaza por fondos insuficientes,
+// y la consulta de las enviadas, que quedan pendientes
+type MockEthereum struct {
+	Server *httptest.Server
+
+	mu       sync.Mutex
+	chainID  *big.Int
+	codigo   []byte
+	balance  *big.Int
+	gasPrice *big.Int
+	nonce    uint64
+	enviadas int
+	mempool  map[common.Hash]*types.Transaction // Transacciones aceptadas; nunca se minan
+}
+
+// NewMockEthereum inicia un nodo simulado de Sepolia, con el contrato desplegado, 1 ETH de saldo y
+// un gas price de 1 gwei
+func NewMockEthereum() *MockEthereum {
+	m := &MockEthereum{
+		chainID:  big.NewInt(11155111),
+		codigo:   []byte{0x60, 0x80, 0x60, 0x40},
+		balance:  new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil),
+		gasPrice: big.NewInt(1_000_000_000),
+		mempool:  make(map[common.Hash]*types.Transaction),
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+// Close detiene el nodo simulado
+func (m *MockEthereum) Close() {
+	m.Server.Close()
+}
+
+// URL retorna la URL del RPC
+func (m *MockEthereum) URL() string {
+	return m.Server.URL
+}
+
+// CambiarRed simula que el endpoint pasa a apuntar a otra red
+func (m *MockEthereum) CambiarRed(chainID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chainID = big.NewInt(chainID)
+}
+
+// QuitarContrato simula una dirección de contrato sin código desplegado
+func (m *MockEthereum) QuitarContrato() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codigo = nil
+}
+
+// FijarBalance establece el saldo en wei de cualquier cuenta
+func (m *MockEthereum) FijarBalance(wei *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.balance = new(big.Int).Set(wei)
+}
+
+// FijarGasPrice establece el gas price sugerido en wei
+func (m *MockEthereum) FijarGasPrice(wei *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gasPrice = new(big.Int).Set(wei)
+}
+
+// Balance retorna el saldo actual en wei
+func (m *MockEthereum) Balance() *big.Int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return new(big.Int).Set(m.balance)
+}
+
+// Enviadas retorna el número de transacciones aceptadas
+func (m *MockEthereum) Enviadas() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enviadas
+}
+
+func (m *MockEthereum) handle(w http.ResponseWriter, r *http.Request) {
+	var peticion struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	var resultado any
+	var errorRPC string
+	switch peticion.Method {
+	case "eth_chainId":
+		resultado = (*hexutil.Big)(m.chainID)
+	case "eth_blockNumber":
+		resultado = hexutil.Uint64(1)
+	case "eth_getCode":
+		resultado = hexutil.Bytes(m.codigo)
+	case "eth_getBalance":
+		resultado = (*hexutil.Big)(m.balance)
+	case "eth_gasPrice":
+		resultado = (*hexutil.Big)(m.gasPrice)
+	case "eth_getTransactionCount":
+		resultado = hexutil.Uint64(m.nonce)
+	case "eth_sendRawTransaction":
+		resultado, errorRPC = m.enviar(peticion.Params)
+	case "eth_getTransactionByHash":
+		var hash common.Hash
+		if len(peticion.Params) == 1 && json.Unmarshal(peticion.Params[0], &hash) == nil && m.mempool[hash] != nil {
+			resultado = m.mempool[hash]
+		}
+	default:
+		errorRPC = "método no soportado: " + peticion.Method
+	}
+	m.mu.Unlock()
+
+	respuesta := map[string]any{"jsonrpc": "2.0", "id": peticion.ID}
+	if errorRPC != "" {
+		respuesta["error"] = map[string]any{"code": -32000, "message": errorRPC}
+	} else {
+		respuesta["result"] = resultado
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(respuesta)
+}
+
+// enviar acepta una transacción firmada si el saldo cubre su costo máximo, como hace un nodo real; requiere m.mu
+func (m *MockEthereum) enviar(params []json.RawMessage) (any, string) {
+	var crudo hexutil.Bytes
+	if len(params) != 1 || json.Unmarshal(params[0], &crudo) != nil {
+		return nil, "parámetros inválidos"
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(crudo); err != nil {
+		return nil, err.Error()
+	}
+	if m.balance.Cmp(tx.Cost()) < 0 {
+		return nil, "insufficient funds for gas * price + value: balance " + m.balance.String() + ", tx cost " + tx.Cost().String()
+	}
+	m.balance.Sub(m.balance, tx.Cost())
+	m.nonce++
+	m.enviadas++
+	m.mempool[tx.Hash()] = tx
+	return tx.Hash(), ""
+}
@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/eventos"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/pkg/client"
+)
+
+func notificacion(tipo, idTransaccion, idProducto string) models.Notificacion {
+	return models.NuevaNotificacion(tipo, &models.Transaccion{
+		IDTransaction: idTransaccion,
+		IDProducto:    idProducto,
+		TipoEvento:    "distribucion",
+		ActorEmisor:   "Distribuidora Norte",
+		Estado:        "pendiente",
+	})
+}
+
+// recibir espera la siguiente notificación de la suscripción
+func recibir(t *testing.T, suscripcion *eventos.Suscripcion) models.Notificacion {
+	t.Helper()
+	select {
+	case n, ok := <-suscripcion.Notificaciones():
+		require.True(t, ok, "suscripción cerrada: %v", suscripcion.Err())
+		return n
+	case <-time.After(2 * time.Second):
+		t.Fatal("no llegó la notificación")
+		return models.Notificacion{}
+	}
+}
+
+func TestBusEventos(t *testing.T) {
+	t.Run("Filtra por transacción, producto o actor", func(t *testing.T) {
+		bus := eventos.NewBus(eventos.Config{})
+		suscripcion, err := bus.Suscribir(eventos.Filtro{Productos: []string{"PROD-1"}, Transacciones: []string{"TX-9"}}, "")
+		require.NoError(t, err)
+		defer suscripcion.Cancelar()
+
+		bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-1", "PROD-1"))
+		bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-2", "PROD-2"))
+		bus.Publicar(notificacion(models.NotificacionAnclada, "TX-9", "PROD-3"))
+
+		assert.Equal(t, "TX-1", recibir(t, suscripcion).IDTransaction)
+		assert.Equal(t, "TX-9", recibir(t, suscripcion).IDTransaction)
+		assert.Empty(t, suscripcion.Notificaciones())
+	})
+
+	t.Run("Reanuda después del último ID", func(t *testing.T) {
+		bus := eventos.NewBus(eventos.Config{Historial: 3})
+		espia, err := bus.Suscribir(eventos.Filtro{}, "")
+		require.NoError(t, err)
+		for _, id := range []string{"TX-1", "TX-2", "TX-3"} {
+			bus.Publicar(notificacion(models.NotificacionRegistrada, id, "PROD-1"))
+		}
+		primera := recibir(t, espia)
+
+		suscripcion, err := bus.Suscribir(eventos.Filtro{}, primera.ID)
+		require.NoError(t, err)
+		assert.False(t, suscripcion.Incompleta)
+		require.Len(t, suscripcion.Pendientes, 2)
+		assert.Equal(t, "TX-2", suscripcion.Pendientes[0].IDTransaction)
+		assert.Equal(t, "TX-3", suscripcion.Pendientes[1].IDTransaction)
+
+		// Con el historial lleno, la primera notificación se descarta al publicar otra
+		bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-4", "PROD-1"))
+		bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-5", "PROD-1"))
+		tardia, err := bus.Suscribir(eventos.Filtro{}, primera.ID)
+		require.NoError(t, err)
+		assert.True(t, tardia.Incompleta)
+		require.Len(t, tardia.Pendientes, 3)
+		assert.Equal(t, "TX-3", tardia.Pendientes[0].IDTransaction)
+	})
+
+	t.Run("ID de otra instancia o inválido", func(t *testing.T) {
+		bus := eventos.NewBus(eventos.Config{})
+		bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-1", "PROD-1"))
+
+		suscripcion, err := bus.Suscribir(eventos.Filtro{}, "otraepoca-1")
+		require.NoError(t, err)
+		assert.True(t, suscripcion.Incompleta)
+		assert.Len(t, suscripcion.Pendientes, 1)
+
+		_, err = bus.Suscribir(eventos.Filtro{}, "sin-numero")
+		assert.ErrorIs(t, err, eventos.ErrUltimoIDInvalido)
+	})
+
+	t.Run("Un suscriptor lento se desconecta sin bloquear", func(t *testing.T) {
+		bus := eventos.NewBus(eventos.Config{BufferSuscriptor: 2})
+		lento, err := bus.Suscribir(eventos.Filtro{}, "")
+		require.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-1", "PROD-1"))
+		}
+
+		recibidas := 0
+		for range lento.Notificaciones() {
+			recibidas++
+		}
+		assert.Equal(t, 2, recibidas)
+		assert.ErrorIs(t, lento.Err(), eventos.ErrSuscriptorLento)
+		assert.Equal(t, 0, bus.Suscriptores())
+	})
+
+	t.Run("Máximo de suscriptores y cierre", func(t *testing.T) {
+		bus := eventos.NewBus(eventos.Config{MaximoSuscriptores: 1})
+		suscripcion, err := bus.Suscribir(eventos.Filtro{}, "")
+		require.NoError(t, err)
+		_, err = bus.Suscribir(eventos.Filtro{}, "")
+		assert.ErrorIs(t, err, eventos.ErrDemasiadosSuscriptores)
+
+		bus.Cerrar()
+		_, abierto := <-suscripcion.Notificaciones()
+		assert.False(t, abierto)
+		assert.ErrorIs(t, suscripcion.Err(), eventos.ErrBusCerrado)
+		_, err = bus.Suscribir(eventos.Filtro{}, "")
+		assert.ErrorIs(t, err, eventos.ErrBusCerrado)
+	})
+}
+
+// servidorEventos levanta el router real con el bus y la clave de arranque (rol admin)
+func servidorEventos(t *testing.T, bus *eventos.Bus) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+
+	engine := router.Configurar(&config.Config{AuthEnabled: true, RateLimitRequests: 1000, RateLimitWindow: 60}, router.Dependencias{
+		EventosHandler: handlers.NewEventosHandler(bus, time.Second),
+		AuthConfig:     middleware.AuthConfig{ClaveArranque: claveArranqueCliente},
+		Politica:       motor,
+	})
+	servidor := httptest.NewServer(engine)
+	t.Cleanup(servidor.Close)
+	return servidor
+}
+
+// esperarSuscriptores espera a que el handler registre la suscripción antes de publicar
+func esperarSuscriptores(t *testing.T, bus *eventos.Bus, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return bus.Suscriptores() == n }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestEventos_SSE(t *testing.T) {
+	bus := eventos.NewBus(eventos.Config{})
+	servidor := servidorEventos(t, bus)
+	cliente := client.NewClient(servidor.URL, client.ConAPIKey(claveArranqueCliente))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := cliente.SuscribirEventos(ctx, client.FiltroEventos{Productos: []string{"PROD-1"}})
+	require.NoError(t, err)
+	esperarSuscriptores(t, bus, 1)
+
+	bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-1", "PROD-1"))
+	bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-2", "PROD-2"))
+	bus.Publicar(notificacion(models.NotificacionAnclada, "TX-1", "PROD-1"))
+
+	registrada, err := stream.Siguiente()
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificacionRegistrada, registrada.Tipo)
+	assert.Equal(t, registrada.ID, stream.UltimoID())
+	require.NoError(t, stream.Cerrar())
+	esperarSuscriptores(t, bus, 0)
+
+	t.Run("Reanuda con Last-Event-ID", func(t *testing.T) {
+		reanudado, err := cliente.SuscribirEventos(ctx, client.FiltroEventos{Productos: []string{"PROD-1"}, UltimoID: registrada.ID})
+		require.NoError(t, err)
+		defer reanudado.Cerrar()
+
+		anclada, err := reanudado.Siguiente()
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificacionAnclada, anclada.Tipo)
+		assert.Equal(t, "TX-1", anclada.IDTransaction)
+	})
+
+	t.Run("Last-Event-ID de otra instancia", func(t *testing.T) {
+		reanudado, err := cliente.SuscribirEventos(ctx, client.FiltroEventos{Transacciones: []string{"TX-2"}, UltimoID: "otraepoca-7"})
+		require.NoError(t, err)
+		defer reanudado.Cerrar()
+
+		_, err = reanudado.Siguiente()
+		assert.ErrorIs(t, err, client.ErrHistorialIncompleto)
+		siguiente, err := reanudado.Siguiente()
+		require.NoError(t, err)
+		assert.Equal(t, "TX-2", siguiente.IDTransaction)
+	})
+
+	t.Run("Apagado del servidor", func(t *testing.T) {
+		stream, err := cliente.SuscribirEventos(ctx, client.FiltroEventos{})
+		require.NoError(t, err)
+		defer stream.Cerrar()
+		esperarSuscriptores(t, bus, 1)
+
+		bus.Cerrar()
+		var desconexion *client.Desconexion
+		for {
+			_, err = stream.Siguiente()
+			if err != nil {
+				break
+			}
+		}
+		require.True(t, errors.As(err, &desconexion), "error inesperado: %v", err)
+		assert.Contains(t, desconexion.Mensaje, "cerrado")
+	})
+}
+
+func TestEventos_PoliticaYValidacion(t *testing.T) {
+	servidor := servidorEventos(t, eventos.NewBus(eventos.Config{}))
+
+	t.Run("Sin credenciales", func(t *testing.T) {
+		_, err := client.NewClient(servidor.URL).SuscribirEventos(context.Background(), client.FiltroEventos{})
+		var errAPI *client.Error
+		require.True(t, errors.As(err, &errAPI))
+		assert.Equal(t, http.StatusUnauthorized, errAPI.StatusCode)
+	})
+
+	t.Run("Last-Event-ID mal formado", func(t *testing.T) {
+		_, err := client.NewClient(servidor.URL, client.ConAPIKey(claveArranqueCliente)).
+			SuscribirEventos(context.Background(), client.FiltroEventos{UltimoID: "basura"})
+		var errAPI *client.Error
+		require.True(t, errors.As(err, &errAPI))
+		assert.Equal(t, http.StatusBadRequest, errAPI.StatusCode)
+	})
+
+	t.Run("La política cubre los streams", func(t *testing.T) {
+		motor, err := policy.NewMotor("")
+		require.NoError(t, err)
+		for _, ruta := range []string{"/api/v1/eventos", "/api/v1/eventos/ws"} {
+			assert.NoError(t, motor.AutorizarRuta(principalConRol("Farmacia Central", "farmacia"), http.MethodGet, ruta))
+			err := motor.AutorizarRuta(principalConRol("Auditoría", "auditor"), http.MethodGet, ruta)
+			var denegacion *policy.Denegacion
+			require.True(t, errors.As(err, &denegacion))
+			assert.Equal(t, "suscribir-eventos", denegacion.Regla)
+		}
+	})
+}
+
+func TestEventos_WebSocket(t *testing.T) {
+	bus := eventos.NewBus(eventos.Config{})
+	servidor := servidorEventos(t, bus)
+	url := "ws" + strings.TrimPrefix(servidor.URL, "http") + "/api/v1/eventos/ws?transaccion=TX-1"
+	cabeceras := http.Header{"X-API-Key": []string{claveArranqueCliente}}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, cabeceras)
+	require.NoError(t, err)
+	defer conn.Close()
+	esperarSuscriptores(t, bus, 1)
+
+	bus.Publicar(notificacion(models.NotificacionRegistrada, "TX-1", "PROD-1"))
+	var recibida models.Notificacion
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, conn.ReadJSON(&recibida))
+	assert.Equal(t, "TX-1", recibida.IDTransaction)
+	assert.Equal(t, models.NotificacionRegistrada, recibida.Tipo)
+
+	t.Run("Reanuda con ultimoId", func(t *testing.T) {
+		bus.Publicar(notificacion(models.NotificacionConfirmada, "TX-1", "PROD-1"))
+		reanudada, _, err := websocket.DefaultDialer.Dial(url+"&ultimoId="+recibida.ID, cabeceras)
+		require.NoError(t, err)
+		defer reanudada.Close()
+
+		var confirmada models.Notificacion
+		require.NoError(t, reanudada.SetReadDeadline(time.Now().Add(2*time.Second)))
+		require.NoError(t, reanudada.ReadJSON(&confirmada))
+		assert.Equal(t, models.NotificacionConfirmada, confirmada.Tipo)
+	})
+
+	t.Run("Cierre al apagar", func(t *testing.T) {
+		bus.Cerrar()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		for {
+			if _, _, err = conn.ReadMessage(); err != nil {
+				break
+			}
+		}
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "error inesperado: %v", err)
+	})
+}
@@ -0,0 +1,341 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+	"github.com/edinfamous/blockchain-medisupply/pkg/client"
+)
+
+// costoAnclajeSimple es el costo máximo de un anclaje en modo transacción simple al gas price por
+// defecto de MockEthereum: 100000 de gas a 1 gwei
+var costoAnclajeSimple = big.NewInt(100_000 * 1_000_000_000)
+
+func ether(t *testing.T, cantidad string) *big.Int {
+	t.Helper()
+	wei, err := utils.ParsearEther(cantidad)
+	require.NoError(t, err)
+	return wei
+}
+
+// blockchainSimple conecta con el nodo simulado en modo transacción simple, el único que no espera recibos
+func blockchainSimple(t *testing.T, ethereum *MockEthereum) *services.BlockchainService {
+	t.Helper()
+	blockchain, err := services.NewBlockchainService(ethereum.URL(), ClavePruebaEthereum, "")
+	require.NoError(t, err)
+	t.Cleanup(blockchain.Close)
+	return blockchain
+}
+
+func TestFondos_NivelesYAlertas(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	webhook, alertas := receptor(t, siempre(http.StatusOK))
+	monitor := services.NewMonitorFondos(blockchainSimple(t, ethereum), services.FondosConfig{
+		SaldoAlerta:    ether(t, "0.5"),
+		WebhookURL:     webhook.URL,
+		WebhookSecreto: "secreto-alertas",
+	})
+	ctx := context.Background()
+
+	recibirAlerta := func(nivel string) models.AlertaFondos {
+		t.Helper()
+		select {
+		case recibida := <-alertas:
+			timestamp, err := strconv.ParseInt(recibida.cabeceras.Get(services.CabeceraWebhookTimestamp), 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, "v1="+services.FirmarWebhook("secreto-alertas", timestamp, recibida.cuerpo), recibida.cabeceras.Get(services.CabeceraWebhookFirma))
+			var alerta models.AlertaFondos
+			require.NoError(t, json.Unmarshal(recibida.cuerpo, &alerta))
+			assert.Equal(t, nivel, alerta.Nivel)
+			return alerta
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no llegó la alerta de nivel %s", nivel)
+			return models.AlertaFondos{}
+		}
+	}
+
+	// 1 ETH sin gasto reciente: sin alerta, proyección con el costo máximo al gas price actual
+	require.NoError(t, monitor.Actualizar(ctx))
+	estado := monitor.Estado()
+	assert.Equal(t, services.NivelFondosOK, estado.Nivel)
+	assert.Equal(t, "1", estado.SaldoEth)
+	assert.Equal(t, costoAnclajeSimple.String(), estado.CostoAnclajeWei)
+	assert.Equal(t, int64(10000), estado.AnclajesRestantes)
+	assert.Nil(t, estado.AutonomiaHoras)
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_blockchain_saldo_eth", nil))
+	select {
+	case <-alertas:
+		t.Fatal("no se alerta con fondos suficientes")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Por debajo del umbral de alerta
+	bajas := valorMetrica(t, "medisupply_blockchain_alertas_fondos_total", map[string]string{"nivel": services.NivelFondosBajo})
+	ethereum.FijarBalance(ether(t, "0.3"))
+	require.NoError(t, monitor.Actualizar(ctx))
+	alerta := recibirAlerta(services.NivelFondosBajo)
+	assert.Equal(t, services.NivelFondosOK, alerta.NivelAnterior)
+	assert.Contains(t, alerta.Motivo, "0.3 ETH")
+	assert.Equal(t, "0.3", alerta.Fondos.SaldoEth)
+	assert.False(t, monitor.Pausado())
+	assert.Equal(t, bajas+1, valorMetrica(t, "medisupply_blockchain_alertas_fondos_total", map[string]string{"nivel": services.NivelFondosBajo}))
+
+	// Sin cambio de nivel no se repite la alerta
+	require.NoError(t, monitor.Actualizar(ctx))
+	select {
+	case <-alertas:
+		t.Fatal("la alerta solo se envía al cambiar de nivel")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Menos que el costo de un anclaje: se pausa
+	ethereum.FijarBalance(new(big.Int).Sub(costoAnclajeSimple, big.NewInt(1)))
+	require.NoError(t, monitor.Actualizar(ctx))
+	recibirAlerta(services.NivelFondosInsuficiente)
+	estado = monitor.Estado()
+	assert.True(t, estado.AnclajePausado)
+	assert.Zero(t, estado.AnclajesRestantes)
+	assert.True(t, monitor.Pausado())
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_anclaje_pausado", nil))
+
+	// Al recargar se reanuda y se avisa
+	ethereum.FijarBalance(ether(t, "2"))
+	require.NoError(t, monitor.Actualizar(ctx))
+	alerta = recibirAlerta(services.NivelFondosOK)
+	assert.Equal(t, services.NivelFondosInsuficiente, alerta.NivelAnterior)
+	assert.False(t, monitor.Pausado())
+	assert.Equal(t, 0.0, valorMetrica(t, "medisupply_anclaje_pausado", nil))
+}
+
+func TestFondos_ProyeccionDeGasto(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	blockchain := blockchainSimple(t, ethereum)
+	monitor := services.NewMonitorFondos(blockchain, services.FondosConfig{AutonomiaMinima: 300 * time.Hour})
+	ctx := context.Background()
+
+	for _, hash := range []string{strings.Repeat("a", 64), strings.Repeat("b", 64)} {
+		_, _, err := blockchain.RegistrarEnBlockchain(ctx, hash, "QmPrueba")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, ethereum.Enviadas())
+
+	// Dos anclajes en las últimas 24 horas: 2 × 0.0001 ETH de gasto diario; el saldo dura
+	// 0.002 / 0.0002 días = 240 horas, por debajo del mínimo de 300
+	ethereum.FijarBalance(ether(t, "0.002"))
+	require.NoError(t, monitor.Actualizar(ctx))
+	estado := monitor.Estado()
+	assert.Equal(t, 2, estado.AnclajesUltimoDia)
+	assert.Equal(t, "0.0002", estado.GastoDiarioEth)
+	assert.Equal(t, "0.0001", estado.CostoAnclajeEth)
+	assert.Equal(t, int64(20), estado.AnclajesRestantes)
+	require.NotNil(t, estado.AutonomiaHoras)
+	assert.InDelta(t, 240, *estado.AutonomiaHoras, 0.01)
+	assert.Equal(t, services.NivelFondosBajo, estado.Nivel)
+	assert.InDelta(t, 240, valorMetrica(t, "medisupply_blockchain_autonomia_horas", nil), 0.01)
+	assert.Equal(t, 20.0, valorMetrica(t, "medisupply_blockchain_anclajes_restantes", nil))
+
+	// Un error del RPC conserva la última proyección y lo informa
+	ethereum.Close()
+	require.Error(t, monitor.Actualizar(ctx))
+	estado = monitor.Estado()
+	assert.Equal(t, "0.002", estado.SaldoEth)
+	assert.NotEmpty(t, estado.Error)
+}
+
+func TestFondos_AnclajePausadoSinFondos(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	host, puerto := kubo.HostPort()
+	dynamo := NewMockDynamo()
+	defer dynamo.Close()
+
+	blockchain := blockchainSimple(t, ethereum)
+	monitor := services.NewMonitorFondos(blockchain, services.FondosConfig{})
+	servicio := services.NewTransaccionService(blockchain, services.NewIPFSService(host, puerto), dynamo.Servicio())
+	servicio.ConfigurarFondos(monitor)
+	ctx := context.Background()
+	sinAnclajesEnCurso := func() bool { return servicio.AnclajesPendientes() == 0 }
+
+	// La última consulta vio saldo, pero el gas price subió: el nodo rechaza el anclaje y se pausa
+	// en lugar de marcar la transacción como fallida
+	ethereum.FijarBalance(ether(t, "0.001"))
+	require.NoError(t, monitor.Actualizar(ctx))
+	require.False(t, monitor.Pausado())
+	ethereum.FijarGasPrice(big.NewInt(100_000_000_000))
+
+	_, err := servicio.RegistrarTransaccion(ctx, GetMockTransaccionRequest())
+	require.NoError(t, err)
+	require.Eventually(t, monitor.Pausado, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, services.NivelFondosInsuficiente, monitor.Estado().Nivel)
+	require.Eventually(t, sinAnclajesEnCurso, 2*time.Second, 10*time.Millisecond, "el anclaje no espera en una goroutine")
+
+	// Otro registro durante la pausa queda pendiente sin llegar al nodo
+	_, err = servicio.RegistrarTransaccion(ctx, GetMockTransaccionRequest())
+	require.NoError(t, err)
+	require.Eventually(t, sinAnclajesEnCurso, 2*time.Second, 10*time.Millisecond)
+	assert.Zero(t, ethereum.Enviadas())
+	assert.Equal(t, map[string]int{"pendiente": 2}, dynamo.Estados())
+
+	// Con el saldo recargado la siguiente consulta reencola los anclajes pendientes
+	ethereum.FijarBalance(ether(t, "1"))
+	require.NoError(t, monitor.Actualizar(ctx))
+	require.Eventually(t, func() bool { return dynamo.Estados()["confirmado"] == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"confirmado": 2}, dynamo.Estados())
+	assert.Equal(t, 2, ethereum.Enviadas())
+	require.Eventually(t, sinAnclajesEnCurso, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, monitor.Actualizar(ctx))
+	assert.Equal(t, 2, monitor.Estado().AnclajesUltimoDia, "el anclaje rechazado no cuenta como gasto")
+}
+
+func TestFondos_ReanclarPendientesUnaVezEntreReplicas(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	host, puerto := kubo.HostPort()
+	dynamo := NewMockDynamo()
+	defer dynamo.Close()
+	ctx := context.Background()
+
+	// Eventos registrados por un proceso que se detuvo antes de anclarlos
+	sinBlockchain := services.NewTransaccionService(nil, services.NewIPFSService(host, puerto), dynamo.Servicio())
+	for i := 0; i < 6; i++ {
+		_, err := sinBlockchain.RegistrarTransaccion(ctx, GetMockTransaccionRequest())
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"pendiente": 6}, dynamo.Estados())
+
+	// Dos réplicas arrancan a la vez: la reserva del anclaje evita enviar un evento dos veces
+	replicas := make([]*services.TransaccionService, 2)
+	for i := range replicas {
+		replicas[i] = services.NewTransaccionService(blockchainSimple(t, ethereum), services.NewIPFSService(host, puerto), dynamo.Servicio())
+	}
+	errs := make(chan error, len(replicas))
+	for _, replica := range replicas {
+		go func(replica *services.TransaccionService) { errs <- replica.ReanclarPendientes(ctx) }(replica)
+	}
+	for range replicas {
+		require.NoError(t, <-errs)
+	}
+
+	require.Eventually(t, func() bool { return dynamo.Estados()["confirmado"] == 6 }, 5*time.Second, 10*time.Millisecond)
+	for _, replica := range replicas {
+		require.Eventually(t, func() bool { return replica.AnclajesPendientes() == 0 }, 2*time.Second, 10*time.Millisecond)
+	}
+	assert.Equal(t, 6, ethereum.Enviadas())
+}
+
+func TestAnclaje_ConfirmacionFallidaNoReenvia(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	host, puerto := kubo.HostPort()
+	dynamo := NewMockDynamo()
+	defer dynamo.Close()
+
+	servicio := services.NewTransaccionService(blockchainSimple(t, ethereum), services.NewIPFSService(host, puerto), dynamo.Servicio())
+	ctx := context.Background()
+	sinAnclajesEnCurso := func() bool { return servicio.AnclajesPendientes() == 0 }
+
+	// El anclaje llega a la red, pero DynamoDB rechaza guardar sus hashes en todos los reintentos
+	dynamo.FallarActualizaciones("ethereumTxHash =")
+	_, err := servicio.RegistrarTransaccion(ctx, GetMockTransaccionRequest())
+	require.NoError(t, err)
+	require.Eventually(t, sinAnclajesEnCurso, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, ethereum.Enviadas())
+	assert.Equal(t, map[string]int{"pendiente": 1}, dynamo.Estados())
+
+	// Mientras la reserva sigue vigente, un reanclaje no lo toca
+	require.NoError(t, servicio.ReanclarPendientes(ctx))
+	require.Eventually(t, sinAnclajesEnCurso, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"pendiente": 1}, dynamo.Estados())
+
+	// Vencida la reserva (o tras un reinicio), el reanclaje encuentra el envío guardado y solo confirma
+	dynamo.FallarActualizaciones("")
+	dynamo.VencerReservas()
+	require.NoError(t, servicio.ReanclarPendientes(ctx))
+	require.Eventually(t, func() bool { return dynamo.Estados()["confirmado"] == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, ethereum.Enviadas(), "el evento no se ancla dos veces")
+}
+
+func TestFondos_SinMonitorElAnclajeFalla(t *testing.T) {
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	ethereum.FijarBalance(big.NewInt(0))
+
+	_, _, err := blockchainSimple(t, ethereum).RegistrarEnBlockchain(context.Background(), strings.Repeat("a", 64), "QmPrueba")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, services.ErrFondosInsuficientes), err.Error())
+	assert.Zero(t, ethereum.Enviadas())
+}
+
+func TestFondos_EndpointAdministracion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ethereum := NewMockEthereum()
+	defer ethereum.Close()
+	monitor := services.NewMonitorFondos(blockchainSimple(t, ethereum), services.FondosConfig{})
+	require.NoError(t, monitor.Actualizar(context.Background()))
+
+	servidor := func(monitor *services.MonitorFondos) (*client.Client, *client.Client) {
+		motor, err := policy.NewMotor("")
+		require.NoError(t, err)
+		apiKeys := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+		engine := router.Configurar(&config.Config{AuthEnabled: true, RateLimitRequests: 1000, RateLimitWindow: 60}, router.Dependencias{
+			APIKeyHandler: handlers.NewAPIKeyHandler(apiKeys),
+			FondosHandler: handlers.NewFondosHandler(monitor),
+			AuthConfig:    middleware.AuthConfig{APIKeys: apiKeys, ClaveArranque: claveArranqueCliente},
+			Politica:      motor,
+		})
+		srv := httptest.NewServer(engine)
+		t.Cleanup(srv.Close)
+		admin := client.NewClient(srv.URL, client.ConAPIKey(claveArranqueCliente))
+		creada, err := admin.CrearAPIKey(context.Background(), client.APIKeyRequest{Nombre: "ERP", Actor: "Distribuidora Norte", Roles: []string{"distribuidor"}})
+		require.NoError(t, err)
+		return admin, client.NewClient(srv.URL, client.ConToken(creada.Clave))
+	}
+
+	admin, socio := servidor(monitor)
+	fondos, err := admin.ObtenerFondos(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, services.NivelFondosOK, fondos.Nivel)
+	assert.Equal(t, "1", fondos.SaldoEth)
+	assert.Equal(t, monitor.Estado().Cuenta, fondos.Cuenta)
+	assert.Equal(t, int64(10000), fondos.AnclajesRestantes)
+
+	var errorAPI *client.Error
+	_, err = socio.ObtenerFondos(context.Background())
+	require.ErrorAs(t, err, &errorAPI)
+	assert.Equal(t, http.StatusForbidden, errorAPI.StatusCode)
+
+	// Modo sin blockchain: no hay cuenta firmante
+	admin, _ = servidor(nil)
+	_, err = admin.ObtenerFondos(context.Background())
+	require.ErrorAs(t, err, &errorAPI)
+	assert.Equal(t, http.StatusServiceUnavailable, errorAPI.StatusCode)
+	assert.Equal(t, "blockchain-no-disponible", errorAPI.Codigo)
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/grpcserver"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	"github.com/edinfamous/blockchain-medisupply/internal/salud"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// preparacionFija simula el resultado de las verificaciones de GET /ready
+type preparacionFija bool
+
+func (p preparacionFija) VerificarDependencias(context.Context) (map[string]string, bool) {
+	return nil, bool(p)
+}
+
+// conectarGRPC levanta el servidor sobre un listener en memoria y retorna una conexión cliente
+func conectarGRPC(t *testing.T, deps grpcserver.Dependencias, credencialesCliente credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	servidor := grpcserver.NewServidor(deps)
+	go func() { _ = servidor.Servir(lis) }()
+
+	if credencialesCliente == nil {
+		credencialesCliente = insecure.NewCredentials()
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(credencialesCliente))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		servidor.Detener(context.Background())
+	})
+	return conn
+}
+
+// dependenciasGRPC usa servicios sin IPFS, blockchain ni DynamoDB: solo se ejercita lo previo al almacenamiento
+func dependenciasGRPC(t *testing.T, apiKeys *services.APIKeyService) grpcserver.Dependencias {
+	t.Helper()
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+	transacciones := services.NewTransaccionService(nil, nil, nil)
+	transacciones.ConfigurarPolitica(motor)
+	return grpcserver.Dependencias{
+		Transacciones: transacciones,
+		AuthEnabled:   true,
+		AuthConfig:    middleware.AuthConfig{APIKeys: apiKeys},
+		Politica:      motor,
+		RateLimiter:   limitadorPrueba(ratelimit.NewMemoria(), 1000),
+		Preparacion:   preparacionFija(true),
+	}
+}
+
+func conAPIKey(clave string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", clave)
+}
+
+// detallesError retorna el ErrorInfo y el BadRequest de un status gRPC, si los tiene
+func detallesError(err error) (*errdetails.ErrorInfo, *errdetails.BadRequest) {
+	var (
+		info      *errdetails.ErrorInfo
+		solicitud *errdetails.BadRequest
+	)
+	for _, detalle := range status.Convert(err).Details() {
+		switch d := detalle.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			solicitud = d
+		}
+	}
+	return info, solicitud
+}
+
+func TestGRPC(t *testing.T) {
+	apiKeys := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+	claveDistribuidor, _, err := apiKeys.CrearAPIKey(context.Background(), &models.APIKeyRequest{Nombre: "ERP", Actor: "Distribuidora Norte", Roles: []string{"distribuidor"}})
+	require.NoError(t, err)
+	claveAuditor, _, err := apiKeys.CrearAPIKey(context.Background(), &models.APIKeyRequest{Nombre: "Auditoría", Actor: "Auditor", Roles: []string{"auditor"}})
+	require.NoError(t, err)
+
+	conn := conectarGRPC(t, dependenciasGRPC(t, apiKeys), nil)
+	transacciones := pb.NewTransaccionesClient(conn)
+
+	t.Run("Sin credenciales", func(t *testing.T) {
+		_, err := transacciones.ListarTransacciones(context.Background(), &pb.ListarTransaccionesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("API key inválida", func(t *testing.T) {
+		_, err := transacciones.ListarTransacciones(conAPIKey("msk_no-existe"), &pb.ListarTransaccionesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("La política de rutas se aplica con la ruta REST equivalente", func(t *testing.T) {
+		_, err := transacciones.ListarTransacciones(conAPIKey(claveAuditor), &pb.ListarTransaccionesRequest{})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		info, _ := detallesError(err)
+		require.NotNil(t, info)
+		assert.Equal(t, "consultar-transacciones", info.Metadata["regla"])
+	})
+
+	t.Run("Tipo de evento no autorizado", func(t *testing.T) {
+		_, err := transacciones.RegistrarTransaccion(conAPIKey(claveDistribuidor), &pb.TransaccionRequest{
+			TipoEvento:  "recepcion",
+			IdProducto:  "PROD-001",
+			DatosEvento: `{"receptor":"Farmacia Central"}`,
+		})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		info, _ := detallesError(err)
+		require.NotNil(t, info)
+		assert.Equal(t, "recepcion-por-farmacias", info.Metadata["regla"])
+	})
+
+	t.Run("Evento inválido", func(t *testing.T) {
+		_, err := transacciones.RegistrarTransaccion(conAPIKey(claveDistribuidor), &pb.TransaccionRequest{
+			TipoEvento:  "distribucion",
+			IdProducto:  "PROD-001",
+			DatosEvento: `{}`,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Lote inválido con violaciones por elemento", func(t *testing.T) {
+		_, err := transacciones.RegistrarLote(conAPIKey(claveDistribuidor), &pb.RegistrarLoteRequest{Eventos: []*pb.TransaccionRequest{
+			{TipoEvento: "distribucion", IdProducto: "PROD-001", DatosEvento: `{"destino":"Farmacia Central"}`},
+			{TipoEvento: "distribucion", IdProducto: "PROD-002", DatosEvento: `{}`},
+		}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, solicitud := detallesError(err)
+		require.NotNil(t, solicitud)
+		require.Len(t, solicitud.FieldViolations, 1)
+		assert.Equal(t, "eventos[1]", solicitud.FieldViolations[0].Field)
+	})
+
+	t.Run("Health no requiere credenciales", func(t *testing.T) {
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{
+			Service: pb.Transacciones_ServiceDesc.ServiceName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	})
+}
+
+func TestGRPC_Salud(t *testing.T) {
+	t.Run("Dependencias no disponibles", func(t *testing.T) {
+		deps := dependenciasGRPC(t, nil)
+		deps.Preparacion = preparacionFija(false)
+		resp, err := grpc_health_v1.NewHealthClient(conectarGRPC(t, deps, nil)).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+	})
+
+	t.Run("Un pánico en las verificaciones no tumba el servidor", func(t *testing.T) {
+		deps := dependenciasGRPC(t, nil)
+		deps.Preparacion = handlers.NewHealthHandler(nil, nil, nil, nil, handlers.PreparacionConfig{Config: salud.Config{Criticas: salud.Nombres}})
+		resp, err := grpc_health_v1.NewHealthClient(conectarGRPC(t, deps, nil)).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+	})
+
+	t.Run("Servicio desconocido", func(t *testing.T) {
+		_, err := grpc_health_v1.NewHealthClient(conectarGRPC(t, dependenciasGRPC(t, nil), nil)).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "otro.Servicio"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestGRPC_RateLimit(t *testing.T) {
+	deps := dependenciasGRPC(t, services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria()))
+	deps.RateLimiter = limitadorPrueba(ratelimit.NewMemoria(), 1)
+	transacciones := pb.NewTransaccionesClient(conectarGRPC(t, deps, nil))
+
+	_, err := transacciones.ListarTransacciones(context.Background(), &pb.ListarTransaccionesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = transacciones.ListarTransacciones(context.Background(), &pb.ListarTransaccionesRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestGRPC_CertificadoCliente(t *testing.T) {
+	caServidor := emitirCA(t, "CA Servidor")
+	caClientes := emitirCA(t, "CA Clientes")
+	servidor := emitirServidor(t, caServidor, "medisupply")
+	cliente := emitirCliente(t, caClientes, pkix.Name{CommonName: "auditor"}, "spiffe://medisupply/auditor")
+
+	recargador, err := auth.NewRecargadorTLS(escribirMaterial(t, t.TempDir(), servidor, caClientes))
+	require.NoError(t, err)
+
+	certificados := services.NewCertificadoService(services.NewAlmacenCertificadosMemoria())
+	_, err = certificados.RegistrarIdentidad(context.Background(), &models.IdentidadCertificadoRequest{
+		Identidad: "spiffe://medisupply/auditor", Nombre: "Auditoría", Actor: "Auditor", Roles: []string{"auditor"},
+	})
+	require.NoError(t, err)
+
+	deps := dependenciasGRPC(t, services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria()))
+	deps.AuthConfig.Certificados = certificados
+	deps.TLS = recargador.ConfigServidor()
+
+	raices := x509.NewCertPool()
+	raices.AddCert(caServidor.cert)
+
+	t.Run("Con certificado registrado", func(t *testing.T) {
+		conn := conectarGRPC(t, deps, credentials.NewTLS(&tls.Config{RootCAs: raices, ServerName: "localhost", Certificates: []tls.Certificate{cliente.tls}}))
+		_, err := pb.NewTransaccionesClient(conn).ListarTransacciones(context.Background(), &pb.ListarTransaccionesRequest{})
+		// Autenticado como auditor, que no puede consultar transacciones
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Sin certificado", func(t *testing.T) {
+		conn := conectarGRPC(t, deps, credentials.NewTLS(&tls.Config{RootCAs: raices, ServerName: "localhost"}))
+		_, err := pb.NewTransaccionesClient(conn).ListarTransacciones(context.Background(), &pb.ListarTransaccionesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+// TestGRPC_RutasEquivalentes falla si un RPC no tiene ruta REST equivalente registrada en el router
+func TestGRPC_RutasEquivalentes(t *testing.T) {
+	registradas := map[string]bool{}
+	for _, ruta := range routerCompleto().Routes() {
+		registradas[ruta.Method+" "+ruta.Path] = true
+	}
+
+	servicios := pb.File_medisupply_v1_medisupply_proto.Services()
+	for i := 0; i < servicios.Len(); i++ {
+		metodos := servicios.Get(i).Methods()
+		for j := 0; j < metodos.Len(); j++ {
+			completo := "/" + string(servicios.Get(i).FullName()) + "/" + string(metodos.Get(j).Name())
+			metodo, ruta, ok := grpcserver.RutaEquivalente(completo)
+			if assert.True(t, ok, "%s sin ruta equivalente", completo) {
+				assert.True(t, registradas[metodo+" "+ruta], "%s: la ruta %s %s no existe en el router", completo, metodo, ruta)
+			}
+		}
+	}
+}
@@ -80,3 +80,120 @@ func TestVerificarHash(t *testing.T) {
 		assert.False(t, verificado)
 	})
 }
+
+func TestCalcularHashTransaccion_Versiones(t *testing.T) {
+	t.Run("Transacciones sin versión conservan el hash legacy", func(t *testing.T) {
+		transaccion := GetMockTransaccion()
+		transaccion.HashVersion = 0
+		sinVersion := utils.CalcularHashTransaccion(transaccion)
+
+		transaccion.HashVersion = utils.HashVersionLegacy
+		assert.Equal(t, sinVersion, utils.CalcularHashTransaccion(transaccion))
+
+		// Con legacy, reordenar el JSON cambia el hash
+		transaccion.DatosEvento = `{"fecha_fabricacion": "2024-01-15", "lote": "12345"}`
+		assert.NotEqual(t, sinVersion, utils.CalcularHashTransaccion(transaccion))
+	})
+
+	t.Run("JCS produce el mismo hash para JSON equivalente", func(t *testing.T) {
+		a := GetMockTransaccion()
+		a.HashVersion = utils.HashVersionJCS
+		a.DatosEvento = `{"lote": "12345", "cantidad": 1000}`
+
+		b := GetMockTransaccion()
+		b.FechaEvento = a.FechaEvento
+		b.HashVersion = utils.HashVersionJCS
+		b.DatosEvento = "{\n  \"cantidad\": 1000.0,\n  \"lote\": \"12345\"\n}"
+
+		assert.Equal(t, utils.CalcularHashTransaccion(a), utils.CalcularHashTransaccion(b))
+	})
+}
+
+func TestCalcularHashTransaccion_Estructurado(t *testing.T) {
+	base := func() *models.Transaccion {
+		transaccion := GetMockTransaccion()
+		transaccion.HashVersion = utils.HashVersionEstructurado
+		transaccion.IPFSCid = "QmTest123"
+		return transaccion
+	}
+
+	t.Run("Mover caracteres entre campos no colisiona", func(t *testing.T) {
+		a := base()
+		a.TipoEvento = "fabricacion"
+		a.IDProducto = "PROD-001"
+
+		b := base()
+		b.FechaEvento = a.FechaEvento
+		b.TipoEvento = "fabricacionPROD"
+		b.IDProducto = "-001"
+
+		// Con el algoritmo legacy ambas transacciones producen el mismo hash
+		a.HashVersion, b.HashVersion = utils.HashVersionLegacy, utils.HashVersionLegacy
+		assert.Equal(t, utils.CalcularHashTransaccion(a), utils.CalcularHashTransaccion(b))
+
+		a.HashVersion, b.HashVersion = utils.HashVersionEstructurado, utils.HashVersionEstructurado
+		assert.NotEqual(t, utils.CalcularHashTransaccion(a), utils.CalcularHashTransaccion(b))
+	})
+
+	t.Run("Mover caracteres entre ID y tipo de evento no colisiona", func(t *testing.T) {
+		a := base()
+		a.IDTransaction = "tx-1f"
+		a.TipoEvento = "abricacion"
+
+		b := base()
+		b.FechaEvento = a.FechaEvento
+		b.IDTransaction = "tx-1"
+		b.TipoEvento = "fabricacion"
+
+		assert.NotEqual(t, utils.CalcularHashTransaccion(a), utils.CalcularHashTransaccion(b))
+	})
+
+	t.Run("Cubre actor emisor y CID", func(t *testing.T) {
+		transaccion := base()
+		original := utils.CalcularHashTransaccion(transaccion)
+
+		transaccion.ActorEmisor = "Otro Laboratorio"
+		assert.NotEqual(t, original, utils.CalcularHashTransaccion(transaccion))
+
+		transaccion = base()
+		transaccion.IPFSCid = "QmOtroCid"
+		assert.NotEqual(t, original, utils.CalcularHashTransaccion(transaccion))
+	})
+
+	t.Run("Adjuntos no colisionan al reagrupar sus campos", func(t *testing.T) {
+		a := base()
+		a.Adjuntos = []models.Adjunto{{CID: "QmA", HashSHA256: "bb"}, {CID: "QmC", HashSHA256: "dd"}}
+
+		b := base()
+		b.FechaEvento = a.FechaEvento
+		b.Adjuntos = []models.Adjunto{{CID: "QmAbb", HashSHA256: "QmCdd"}}
+
+		assert.NotEqual(t, utils.CalcularHashTransaccion(a), utils.CalcularHashTransaccion(b))
+
+		tamano := base()
+		tamano.FechaEvento = a.FechaEvento
+		tamano.Adjuntos = []models.Adjunto{{CID: "QmA", HashSHA256: "bb", Tamano: 10}, {CID: "QmC", HashSHA256: "dd"}}
+		assert.NotEqual(t, utils.CalcularHashTransaccion(a), utils.CalcularHashTransaccion(tamano))
+	})
+
+	t.Run("Independiente de la zona horaria de la fecha", func(t *testing.T) {
+		transaccion := base()
+		utc := utils.CalcularHashTransaccion(transaccion)
+
+		transaccion.FechaEvento = transaccion.FechaEvento.In(time.FixedZone("COT", -5*3600))
+		assert.Equal(t, utc, utils.CalcularHashTransaccion(transaccion))
+	})
+
+	t.Run("Versiones distintas producen hashes distintos", func(t *testing.T) {
+		transaccion := base()
+		estructurado := utils.CalcularHashTransaccion(transaccion)
+		transaccion.HashVersion = utils.HashVersionJCS
+		assert.NotEqual(t, estructurado, utils.CalcularHashTransaccion(transaccion))
+	})
+
+	t.Run("Versiones soportadas", func(t *testing.T) {
+		assert.True(t, utils.HashVersionSoportada(0))
+		assert.True(t, utils.HashVersionSoportada(utils.HashVersionActual))
+		assert.False(t, utils.HashVersionSoportada(utils.HashVersionActual+1))
+	})
+}
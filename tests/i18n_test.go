@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/i18n"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/pkg/validation"
+)
+
+// verboFormato reconoce los verbos de fmt de un mensaje del catálogo (%s, %d, %[2]s, ...)
+var verboFormato = regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+
+func TestCatalogo_IdiomasCompletos(t *testing.T) {
+	espanol := i18n.Claves(i18n.Espanol)
+	assert.ElementsMatch(t, espanol, i18n.Claves(i18n.Ingles), "todas las claves deben existir en todos los idiomas")
+
+	for _, clave := range espanol {
+		formatoES, _ := i18n.Buscar(i18n.Espanol, clave)
+		formatoEN, _ := i18n.Buscar(i18n.Ingles, clave)
+		assert.ElementsMatch(t, verboFormato.FindAllString(formatoES, -1), verboFormato.FindAllString(formatoEN, -1),
+			"la clave %s usa argumentos distintos según el idioma", clave)
+	}
+
+	t.Run("Los errores de dominio tienen mensaje en cada idioma", func(t *testing.T) {
+		centinelas := []*services.ErrorDominio{
+			services.ErrTransaccionNoEncontrada, services.ErrProductoSinEventos, services.ErrCIDNoEncontrado,
+			services.ErrSolicitudInvalida, services.ErrConflictoCadena, services.ErrLoteVacio, services.ErrLoteDemasiadoGrande,
+			services.ErrAdjuntoDemasiadoGrande, services.ErrAdjuntoNoPermitido, services.ErrDemasiadosAdjuntos,
+			services.ErrIdempotenciaEnCurso, services.ErrIdempotenciaCuerpoDistinto, services.ErrAPIKeyNoEncontrada,
+			services.ErrIdentidadCertificadoNoEncontrada, services.ErrWebhookNoEncontrado, services.ErrEntregaNoEncontrada,
+			services.ErrEntregaNoReenviable, services.ErrURLWebhookInvalida, services.ErrDestinoNoPermitido,
+		}
+		for _, centinela := range centinelas {
+			mensaje, ok := i18n.Buscar(i18n.Espanol, centinela.Codigo)
+			assert.True(t, ok, "falta %s en el catálogo", centinela.Codigo)
+			assert.Equal(t, centinela.Mensaje, mensaje, "el catálogo en español debe coincidir con el mensaje de %s", centinela.Codigo)
+			assert.NotEqual(t, centinela.Mensaje, centinela.MensajeEn(i18n.Ingles), "%s no está traducido", centinela.Codigo)
+		}
+	})
+}
+
+func TestDesdeAcceptLanguage(t *testing.T) {
+	casos := map[string]i18n.Idioma{
+		"":                       i18n.Espanol,
+		"en":                     i18n.Ingles,
+		"en-US,en;q=0.9":         i18n.Ingles,
+		"es-CO":                  i18n.Espanol,
+		"fr":                     i18n.Espanol,
+		"fr, en;q=0.5":           i18n.Ingles,
+		"es;q=0.2, en-GB;q=0.8":  i18n.Ingles,
+		"no es una cabecera;;;=": i18n.Espanol,
+	}
+	for cabecera, esperado := range casos {
+		assert.Equal(t, esperado, i18n.DesdeAcceptLanguage(cabecera), "Accept-Language: %q", cabecera)
+	}
+}
+
+func TestValidacion_Traducida(t *testing.T) {
+	err := validation.ValidateStruct(&models.TransaccionRequest{TipoEvento: "otro", DatosEvento: "{}", ActorEmisor: "Lab"})
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "El campo 'IDProducto' es requerido.")
+	assert.Contains(t, err.Error(), "El campo 'TipoEvento' debe ser uno de: fabricacion distribucion recepcion verificacion.")
+
+	envuelto := services.ErrSolicitudInvalida.Con(err)
+	ingles := i18n.TraducirError(envuelto, i18n.Ingles)
+	assert.Contains(t, ingles, "validation failed: ")
+	assert.Contains(t, ingles, "The field 'IDProducto' is required.")
+	assert.Contains(t, ingles, "The field 'TipoEvento' must be one of: fabricacion distribucion recepcion verificacion.")
+
+	t.Run("Etiquetas sin mensaje propio usan las traducciones del validador", func(t *testing.T) {
+		type conEmail struct {
+			Correo string `validate:"email"`
+		}
+		err := validation.ValidateStruct(&conEmail{Correo: "no-es-un-correo"})
+		require.Error(t, err)
+		assert.Contains(t, i18n.TraducirError(err, i18n.Ingles), "Correo must be a valid email address")
+		assert.Contains(t, err.Error(), "Correo debe ser una dirección de correo electrónico válida")
+	})
+}
+
+func TestProblema_SegunAcceptLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.IdiomaMiddleware(), middleware.ErroresMiddleware())
+	engine.GET("/invalida", func(c *gin.Context) {
+		c.Error(services.ErrSolicitudInvalida.Con(validation.ValidateStruct(&models.TransaccionRequest{TipoEvento: "fabricacion", DatosEvento: "{}", ActorEmisor: "Lab"})))
+	})
+	engine.GET("/no-encontrada", func(c *gin.Context) {
+		c.Error(errors.Join(errors.New("contexto interno"), services.ErrTransaccionNoEncontrada))
+	})
+
+	consultar := func(ruta, idioma string) (*httptest.ResponseRecorder, models.Problema) {
+		peticion := httptest.NewRequest(http.MethodGet, ruta, nil)
+		if idioma != "" {
+			peticion.Header.Set("Accept-Language", idioma)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, peticion)
+		var problema models.Problema
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problema))
+		return w, problema
+	}
+
+	w, problema := consultar("/invalida", "")
+	assert.Equal(t, "es", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Validación fallida", problema.Titulo)
+	assert.Equal(t, "validación fallida: El campo 'IDProducto' es requerido.", problema.Detalle)
+
+	w, problema = consultar("/invalida", "en-US,en;q=0.9")
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Equal(t, "solicitud-invalida", problema.Codigo, "el código no depende del idioma")
+	assert.Equal(t, "Validation failed", problema.Titulo)
+	assert.Equal(t, "validation failed: The field 'IDProducto' is required.", problema.Detalle)
+
+	_, problema = consultar("/no-encontrada", "en")
+	assert.Equal(t, "Transaction not found", problema.Titulo)
+	assert.Empty(t, problema.Detalle, "el contexto interno en español no se muestra a un cliente en inglés")
+
+	t.Run("Errores de los middlewares", func(t *testing.T) {
+		engine := router.Configurar(&config.Config{RateLimitRequests: 1000, RateLimitWindow: 60}, router.Dependencias{})
+		w := httptest.NewRecorder()
+		peticion := httptest.NewRequest(http.MethodGet, "/api/v1/no-existe", nil)
+		peticion.Header.Set("Accept-Language", "en")
+		engine.ServeHTTP(w, peticion)
+
+		var problema models.Problema
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problema))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "Route not found", problema.Titulo)
+		assert.Equal(t, "check that the route and the HTTP method GET are correct", problema.Detalle)
+	})
+}
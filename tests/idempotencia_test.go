@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// maximoCuerpoIdempotente es el límite del cuerpo en routerIdempotente
+const maximoCuerpoIdempotente = 1 << 10
+
+// routerIdempotente registra un handler que cuenta sus ejecuciones y responde un ID nuevo en cada una
+func routerIdempotente(config services.IdempotenciaConfig, demora time.Duration, status *atomic.Int32) (*gin.Engine, *atomic.Int32) {
+	gin.SetMode(gin.TestMode)
+	ejecuciones := &atomic.Int32{}
+	servicio := services.NewIdempotenciaService(services.NewAlmacenIdempotenciaMemoria(), config)
+
+	router := gin.New()
+	router.POST("/registrar", func(c *gin.Context) {
+		c.Set(middleware.ClavePrincipal, principalConRol(c.GetHeader("X-Actor"), "fabricante"))
+	}, middleware.LimiteCuerpoMiddleware(func() int64 { return maximoCuerpoIdempotente }), middleware.IdempotenciaMiddleware(servicio), func(c *gin.Context) {
+		ejecuciones.Add(1)
+		time.Sleep(demora)
+		codigo := http.StatusCreated
+		if status != nil && status.Load() != 0 {
+			codigo = int(status.Load())
+		}
+		c.JSON(codigo, gin.H{"data": gin.H{"idTransaction": uuid.New().String()}})
+	})
+	return router, ejecuciones
+}
+
+func registrarIdempotente(router *gin.Engine, actor, clave, cuerpo string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/registrar", strings.NewReader(cuerpo))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Actor", actor)
+	if clave != "" {
+		req.Header.Set(middleware.CabeceraIdempotencia, clave)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencia(t *testing.T) {
+	cuerpo := `{"tipoEvento":"fabricacion","idProducto":"PROD-001"}`
+
+	t.Run("El reintento devuelve la respuesta original", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 0, nil)
+
+		primera := registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		segunda := registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+
+		require.Equal(t, http.StatusCreated, primera.Code)
+		assert.Equal(t, http.StatusCreated, segunda.Code)
+		assert.Equal(t, primera.Body.String(), segunda.Body.String())
+		assert.Equal(t, "true", segunda.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, int32(1), ejecuciones.Load())
+	})
+
+	t.Run("La clave se aplica por llamador", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 0, nil)
+
+		primera := registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		otra := registrarIdempotente(router, "Laboratorio XYZ", "clave-1", cuerpo)
+
+		assert.NotEqual(t, primera.Body.String(), otra.Body.String())
+		assert.Empty(t, otra.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, int32(2), ejecuciones.Load())
+	})
+
+	t.Run("Sin cabecera cada petición se ejecuta", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 0, nil)
+		registrarIdempotente(router, "Laboratorio ABC", "", cuerpo)
+		registrarIdempotente(router, "Laboratorio ABC", "", cuerpo)
+		assert.Equal(t, int32(2), ejecuciones.Load())
+	})
+
+	t.Run("Misma clave con otro cuerpo", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 0, nil)
+		registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		w := registrarIdempotente(router, "Laboratorio ABC", "clave-1", `{"tipoEvento":"fabricacion","idProducto":"PROD-002"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, int32(1), ejecuciones.Load())
+	})
+
+	t.Run("Cuerpo que supera el límite", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 0, nil)
+		grande := `{"datosEvento":"` + strings.Repeat("x", maximoCuerpoIdempotente) + `"}`
+
+		w := registrarIdempotente(router, "Laboratorio ABC", "clave-1", grande)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), middleware.CodigoCuerpoDemasiadoGrande)
+		assert.Equal(t, int32(0), ejecuciones.Load())
+
+		assert.Equal(t, http.StatusCreated, registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo).Code, "el rechazo no reserva la clave")
+	})
+
+	t.Run("Los errores del servidor liberan la clave", func(t *testing.T) {
+		status := &atomic.Int32{}
+		status.Store(http.StatusInternalServerError)
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 0, status)
+
+		assert.Equal(t, http.StatusInternalServerError, registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo).Code)
+		status.Store(0)
+		assert.Equal(t, http.StatusCreated, registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo).Code)
+		assert.Equal(t, int32(2), ejecuciones.Load())
+	})
+
+	t.Run("Duplicado concurrente sin espera recibe 409", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{}, 200*time.Millisecond, nil)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		w := registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		wg.Wait()
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.Equal(t, int32(1), ejecuciones.Load())
+	})
+
+	t.Run("Duplicado concurrente espera el resultado original", func(t *testing.T) {
+		router, ejecuciones := routerIdempotente(services.IdempotenciaConfig{Espera: 2 * time.Second}, 200*time.Millisecond, nil)
+
+		var primera *httptest.ResponseRecorder
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			primera = registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		segunda := registrarIdempotente(router, "Laboratorio ABC", "clave-1", cuerpo)
+		wg.Wait()
+
+		assert.Equal(t, http.StatusCreated, segunda.Code)
+		assert.Equal(t, primera.Body.String(), segunda.Body.String())
+		assert.Equal(t, int32(1), ejecuciones.Load())
+	})
+}
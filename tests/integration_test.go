@@ -42,7 +42,7 @@ func TestHealthEndpoint(t *testing.T) {
 	router := gin.New()
 
 	ipfsService := services.NewIPFSService("localhost", "5001")
-	healthHandler := handlers.NewHealthHandler(ipfsService, nil)
+	healthHandler := handlers.NewHealthHandler(ipfsService, nil, nil, nil, handlers.PreparacionConfig{})
 
 	router.GET("/health", healthHandler.HealthCheck)
 
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// MockKubo simula la API HTTP /api/v0 de un nodo Kubo en memoria
+type MockKubo struct {
+	Server *httptest.Server
+
+	mu             sync.Mutex
+	bloques        map[string][]byte
+	pines          map[string]bool
+	FallarPin      bool
+	FallarConsulta bool // pin/ls responde 503, como un nodo que no se puede consultar
+}
+
+// NewMockKubo inicia un nodo Kubo simulado
+func NewMockKubo() *MockKubo {
+	m := &MockKubo{
+		bloques: make(map[string][]byte),
+		pines:   make(map[string]bool),
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+// HostPort retorna host y puerto del nodo simulado
+func (m *MockKubo) HostPort() (string, string) {
+	u, _ := url.Parse(m.Server.URL)
+	return u.Hostname(), u.Port()
+}
+
+// Direccion retorna el nodo en formato host:puerto
+func (m *MockKubo) Direccion() string {
+	host, port := m.HostPort()
+	return host + ":" + port
+}
+
+// Pineado indica si el CID está pineado en el nodo
+func (m *MockKubo) Pineado(cid string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pines[cid]
+}
+
+// Despinear simula la pérdida de un CID en el nodo
+func (m *MockKubo) Despinear(cid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pines, cid)
+}
+
+// Close detiene el servidor
+func (m *MockKubo) Close() {
+	m.Server.Close()
+}
+
+func (m *MockKubo) handle(w http.ResponseWriter, r *http.Request) {
+	cid := r.URL.Query().Get("arg")
+
+	switch r.URL.Path {
+	case "/api/v0/version":
+		_ = json.NewEncoder(w).Encode(map[string]string{"Version": "mock"})
+	case "/api/v0/add":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		sum := sha256.Sum256(data)
+		cid := "Qm" + hex.EncodeToString(sum[:])[:44]
+		m.mu.Lock()
+		m.bloques[cid] = data
+		m.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": "data.json", "Hash": cid, "Size": fmt.Sprint(len(data))})
+	case "/api/v0/cat":
+		m.mu.Lock()
+		data, ok := m.bloques[cid]
+		m.mu.Unlock()
+		if !ok {
+			http.Error(w, "not found", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(data)
+	case "/api/v0/pin/add":
+		if m.FallarPin {
+			http.Error(w, "pin failed", http.StatusInternalServerError)
+			return
+		}
+		m.mu.Lock()
+		m.pines[cid] = true
+		m.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string][]string{"Pins": {cid}})
+	case "/api/v0/pin/ls":
+		if m.FallarConsulta {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if !m.Pineado(cid) {
+			http.Error(w, fmt.Sprintf(`{"Message":"path '%s' is not pinned"}`, cid), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Keys": map[string]interface{}{cid: map[string]string{"Type": "recursive"}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// MockPinningService simula un servicio de la IPFS Pinning Service API
+type MockPinningService struct {
+	Server *httptest.Server
+	Token  string
+
+	mu    sync.Mutex
+	pines map[string]bool
+}
+
+// NewMockPinningService inicia un servicio de pinning simulado
+func NewMockPinningService(token string) *MockPinningService {
+	m := &MockPinningService{Token: token, pines: make(map[string]bool)}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+// Pineado indica si el CID fue pineado en el servicio
+func (m *MockPinningService) Pineado(cid string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pines[cid]
+}
+
+// Close detiene el servidor
+func (m *MockPinningService) Close() {
+	m.Server.Close()
+}
+
+func (m *MockPinningService) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+m.Token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/pins":
+		var body struct {
+			CID string `json:"cid"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		m.mu.Lock()
+		m.pines[body.CID] = true
+		m.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]string{"requestid": "req-" + body.CID, "status": "queued"})
+	case r.Method == http.MethodGet && r.URL.Path == "/pins":
+		var results []map[string]string
+		for _, cid := range strings.Split(r.URL.Query().Get("cid"), ",") {
+			if m.Pineado(cid) {
+				results = append(results, map[string]string{"requestid": "req-" + cid, "status": "pinned"})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"count": len(results), "results": results})
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+func TestIPFSService_Replicacion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Pinea en tantos nodos como el factor de replicación", func(t *testing.T) {
+		primario, secundario, terciario := NewMockKubo(), NewMockKubo(), NewMockKubo()
+		defer primario.Close()
+		defer secundario.Close()
+		defer terciario.Close()
+
+		host, port := primario.HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			Nodos:             []string{secundario.Direccion(), terciario.Direccion()},
+			FactorReplicacion: 2,
+		})
+
+		cid, nodos, err := ipfsService.AlmacenarJSON(ctx, `{"lote": "12345"}`)
+		require.NoError(t, err)
+
+		assert.True(t, primario.Pineado(cid))
+		assert.True(t, secundario.Pineado(cid))
+		assert.False(t, terciario.Pineado(cid), "No debe exceder el factor de replicación")
+		assert.Len(t, nodos, 2)
+	})
+
+	t.Run("Usa el siguiente destino cuando un nodo falla", func(t *testing.T) {
+		primario, caido, respaldo := NewMockKubo(), NewMockKubo(), NewMockKubo()
+		defer primario.Close()
+		defer caido.Close()
+		defer respaldo.Close()
+		caido.FallarPin = true
+
+		host, port := primario.HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			Nodos:               []string{caido.Direccion(), respaldo.Direccion()},
+			FactorReplicacion:   2,
+			MinimoPinesRegistro: 2,
+		})
+
+		cid, nodos, err := ipfsService.AlmacenarJSON(ctx, `{"lote": "67890"}`)
+		require.NoError(t, err)
+		assert.True(t, respaldo.Pineado(cid))
+		assert.NotContains(t, nodos, "kubo:"+caido.Direccion())
+	})
+
+	t.Run("Falla si no se alcanza el mínimo de pines", func(t *testing.T) {
+		primario, caido := NewMockKubo(), NewMockKubo()
+		defer primario.Close()
+		defer caido.Close()
+		caido.FallarPin = true
+
+		host, port := primario.HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			Nodos:               []string{caido.Direccion()},
+			FactorReplicacion:   2,
+			MinimoPinesRegistro: 2,
+		})
+
+		_, _, err := ipfsService.AlmacenarJSON(ctx, `{"lote": "11111"}`)
+		assert.Error(t, err)
+	})
+
+	t.Run("Replica en un servicio de pinning", func(t *testing.T) {
+		primario := NewMockKubo()
+		defer primario.Close()
+		psa := NewMockPinningService("token-secreto")
+		defer psa.Close()
+
+		host, port := primario.HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			PinningServiceURL:   psa.Server.URL,
+			PinningServiceToken: "token-secreto",
+			FactorReplicacion:   2,
+			MinimoPinesRegistro: 2,
+		})
+
+		cid, nodos, err := ipfsService.AlmacenarJSON(ctx, `{"lote": "22222"}`)
+		require.NoError(t, err)
+		assert.True(t, psa.Pineado(cid))
+		assert.Contains(t, nodos, "psa:"+psa.Server.URL)
+	})
+
+	t.Run("Lee de una réplica si el nodo principal cae", func(t *testing.T) {
+		primario, secundario := NewMockKubo(), NewMockKubo()
+		defer primario.Close()
+		defer secundario.Close()
+
+		host, port := primario.HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			Nodos:             []string{secundario.Direccion()},
+			FactorReplicacion: 2,
+		})
+		// El nodo simulado no trae el contenido al pinear: se sube también a la réplica
+		cid, _, err := ipfsService.AlmacenarJSON(ctx, `{"lote": "44444"}`)
+		require.NoError(t, err)
+		hostReplica, portReplica := secundario.HostPort()
+		_, _, err = services.NewIPFSService(hostReplica, portReplica).AlmacenarJSON(ctx, `{"lote": "44444"}`)
+		require.NoError(t, err)
+
+		primario.Close()
+		datos, err := ipfsService.RecuperarJSON(ctx, cid)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"lote": "44444"}`, datos)
+
+		assert.ErrorIs(t, ipfsService.VerificarConexion(ctx), services.ErrIPFSDegradado)
+		assert.NoError(t, ipfsService.VerificarLectura(ctx))
+		_, err = ipfsService.RecuperarJSON(ctx, "QmInexistente")
+		assert.ErrorIs(t, err, services.ErrCIDNoEncontrado)
+
+		secundario.Close()
+		assert.Error(t, ipfsService.VerificarLectura(ctx))
+	})
+
+	t.Run("Replicar restaura copias perdidas", func(t *testing.T) {
+		primario, secundario := NewMockKubo(), NewMockKubo()
+		defer primario.Close()
+		defer secundario.Close()
+
+		host, port := primario.HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			Nodos:             []string{secundario.Direccion()},
+			FactorReplicacion: 2,
+		})
+
+		cid, _, err := ipfsService.AlmacenarJSON(ctx, `{"lote": "33333"}`)
+		require.NoError(t, err)
+
+		secundario.Despinear(cid)
+		nodos, err := ipfsService.Replicar(ctx, cid)
+		require.NoError(t, err)
+		assert.Len(t, nodos, 2)
+		assert.True(t, secundario.Pineado(cid))
+	})
+}
+
+func TestReplicacionService_Reparar(t *testing.T) {
+	ctx := context.Background()
+
+	// entorno registra un evento pineado en el nodo principal y en el secundario; el terciario queda libre
+	entorno := func(t *testing.T) (*services.ReplicacionService, *services.DynamoDBService, string, []*MockKubo) {
+		kubos := []*MockKubo{NewMockKubo(), NewMockKubo(), NewMockKubo()}
+		for _, kubo := range kubos {
+			t.Cleanup(kubo.Close)
+		}
+		dynamo := NewMockDynamo()
+		t.Cleanup(dynamo.Close)
+
+		host, port := kubos[0].HostPort()
+		ipfsService := services.NewIPFSService(host, port)
+		ipfsService.ConfigurarReplicacion(services.ReplicacionConfig{
+			Nodos:             []string{kubos[1].Direccion(), kubos[2].Direccion()},
+			FactorReplicacion: 2,
+		})
+		transaccion, err := services.NewTransaccionService(nil, ipfsService, dynamo.Servicio()).RegistrarTransaccion(ctx, GetMockTransaccionRequest())
+		require.NoError(t, err)
+		require.Len(t, transaccion.NodosIPFS, 2)
+		return services.NewReplicacionService(ipfsService, dynamo.Servicio(), 0), dynamo.Servicio(), transaccion.IDTransaction, kubos
+	}
+
+	t.Run("Conserva los nodos registrados si no se pueden consultar los pines", func(t *testing.T) {
+		replicacion, dynamo, id, kubos := entorno(t)
+		antes, err := dynamo.ObtenerTransaccion(ctx, id)
+		require.NoError(t, err)
+		for _, kubo := range kubos {
+			kubo.FallarConsulta = true
+			kubo.FallarPin = true
+		}
+
+		reparados, err := replicacion.Reparar(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, reparados)
+		despues, err := dynamo.ObtenerTransaccion(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, antes.NodosIPFS, despues.NodosIPFS)
+	})
+
+	t.Run("Reemplaza un nodo que perdió el CID", func(t *testing.T) {
+		replicacion, dynamo, id, kubos := entorno(t)
+		antes, err := dynamo.ObtenerTransaccion(ctx, id)
+		require.NoError(t, err)
+		kubos[1].Despinear(antes.IPFSCid)
+		kubos[1].FallarPin = true
+
+		reparados, err := replicacion.Reparar(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, reparados)
+		despues, err := dynamo.ObtenerTransaccion(ctx, id)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"kubo:" + kubos[0].Direccion(), "kubo:" + kubos[2].Direccion()}, despues.NodosIPFS)
+	})
+}
@@ -29,7 +29,7 @@ func TestIPFSService_AlmacenarYRecuperar(t *testing.T) {
 		testData := `{"producto": "Medicamento A", "lote": "12345", "fabricante": "Laboratorio XYZ"}`
 
 		// Almacenar
-		cid, err := ipfsService.AlmacenarJSON(ctx, testData)
+		cid, _, err := ipfsService.AlmacenarJSON(ctx, testData)
 		require.NoError(t, err)
 		require.NotEmpty(t, cid)
 
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/logging"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// bufferLogs acumula los logs JSON escritos desde varias goroutines
+type bufferLogs struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *bufferLogs) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// registros decodifica cada línea JSON escrita hasta el momento
+func (b *bufferLogs) registros(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var registros []map[string]any
+	lineas := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	for lineas.Scan() {
+		var registro map[string]any
+		require.NoError(t, json.Unmarshal(lineas.Bytes(), &registro), "cada línea debe ser JSON: %s", lineas.Text())
+		registros = append(registros, registro)
+	}
+	return registros
+}
+
+// capturarLogs reemplaza el logger por defecto por uno JSON en memoria mientras dura el test
+func capturarLogs(t *testing.T, nivel slog.Level) *bufferLogs {
+	t.Helper()
+	salida := &bufferLogs{}
+	logger, err := logging.Nuevo(salida, logging.FormatoJSON, nivel)
+	require.NoError(t, err)
+	anterior := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(anterior) })
+	return salida
+}
+
+func TestLoggerMiddleware_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := capturarLogs(t, slog.LevelDebug)
+
+	engine := gin.New()
+	engine.Use(middleware.LoggerMiddleware())
+	engine.GET("/eco", func(c *gin.Context) {
+		slog.InfoContext(c.Request.Context(), "dentro del handler")
+		c.Status(http.StatusNoContent)
+	})
+
+	consultar := func(requestID string) *httptest.ResponseRecorder {
+		peticion := httptest.NewRequest(http.MethodGet, "/eco", nil)
+		if requestID != "" {
+			peticion.Header.Set(middleware.CabeceraRequestID, requestID)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, peticion)
+		return w
+	}
+
+	t.Run("Sin cabecera se genera uno", func(t *testing.T) {
+		w := consultar("")
+		generado := w.Header().Get(middleware.CabeceraRequestID)
+		assert.True(t, logging.RequestIDValido(generado), "request ID generado: %q", generado)
+		assert.NotEqual(t, generado, consultar("").Header().Get(middleware.CabeceraRequestID), "cada petición tiene su propio request ID")
+	})
+
+	t.Run("Se reutiliza el del cliente y aparece en todos los logs de la petición", func(t *testing.T) {
+		w := consultar("pedido-42:reintento_1")
+		assert.Equal(t, "pedido-42:reintento_1", w.Header().Get(middleware.CabeceraRequestID))
+
+		var mensajes []string
+		for _, registro := range logs.registros(t) {
+			if registro[logging.ClaveRequestID] == "pedido-42:reintento_1" {
+				mensajes = append(mensajes, registro["msg"].(string))
+			}
+		}
+		assert.Equal(t, []string{"dentro del handler", "petición HTTP"}, mensajes)
+	})
+
+	t.Run("Un request ID inválido se reemplaza", func(t *testing.T) {
+		for _, invalido := range []string{"con espacios y\nsaltos", strings.Repeat("a", 129), `"comillas"`} {
+			w := consultar(invalido)
+			recibido := w.Header().Get(middleware.CabeceraRequestID)
+			assert.NotEqual(t, invalido, recibido)
+			assert.True(t, logging.RequestIDValido(recibido))
+		}
+	})
+}
+
+func TestLogging_ContextoDesacoplado(t *testing.T) {
+	logs := capturarLogs(t, slog.LevelInfo)
+
+	ctx, cancelar := context.WithCancel(logging.ConRequestID(context.Background(), "req-anclaje"))
+	segundoPlano := logging.Desacoplar(ctx)
+	cancelar()
+
+	assert.NoError(t, segundoPlano.Err(), "el trabajo en segundo plano sobrevive a la petición")
+	assert.Equal(t, "req-anclaje", logging.RequestID(segundoPlano))
+
+	slog.InfoContext(segundoPlano, "anclando")
+	slog.DebugContext(segundoPlano, "no se registra por debajo del nivel")
+	registros := logs.registros(t)
+	require.Len(t, registros, 1)
+	assert.Equal(t, "req-anclaje", registros[0][logging.ClaveRequestID])
+	assert.Equal(t, "INFO", registros[0]["level"])
+}
+
+func TestLogging_Configuracion(t *testing.T) {
+	for _, nivel := range []string{"debug", "INFO", "warn", "error"} {
+		_, err := logging.ParsearNivel(nivel)
+		assert.NoError(t, err, nivel)
+	}
+	_, err := logging.ParsearNivel("verbose")
+	assert.Error(t, err)
+
+	_, err = logging.Nuevo(&bytes.Buffer{}, "xml", slog.LevelInfo)
+	assert.Error(t, err)
+
+	var salida bytes.Buffer
+	logger, err := logging.Nuevo(&salida, logging.FormatoTexto, slog.LevelInfo)
+	require.NoError(t, err)
+	logger.InfoContext(logging.ConRequestID(context.Background(), "abc"), "hola", "clave", "valor")
+	assert.Contains(t, salida.String(), "msg=hola clave=valor request_id=abc")
+
+	cfg := configuracionValida()
+	cfg.LogLevel = "verbose"
+	assert.ErrorContains(t, cfg.Validate(), "LOG_LEVEL")
+	cfg = configuracionValida()
+	cfg.LogFormat = "xml"
+	assert.ErrorContains(t, cfg.Validate(), "LOG_FORMAT")
+}
+
+func TestGRPC_RequestID(t *testing.T) {
+	logs := capturarLogs(t, slog.LevelInfo)
+	conn := conectarGRPC(t, dependenciasGRPC(t, services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())), nil)
+
+	var cabeceras metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "grpc-123")
+	_, err := pb.NewTransaccionesClient(conn).ListarTransacciones(ctx, &pb.ListarTransaccionesRequest{}, grpc.Header(&cabeceras))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, []string{"grpc-123"}, cabeceras.Get("x-request-id"))
+
+	var registrado bool
+	for _, registro := range logs.registros(t) {
+		if registro["msg"] == "petición gRPC" && registro[logging.ClaveRequestID] == "grpc-123" {
+			registrado = true
+			assert.Equal(t, codes.Unauthenticated.String(), registro["codigo"])
+			assert.Equal(t, "WARN", registro["level"])
+		}
+	}
+	assert.True(t, registrado, "el RPC se registra con su request ID")
+}
+
+// configuracionValida retorna una configuración que supera Validate con los valores por defecto
+func configuracionValida() *config.Config {
+	return &config.Config{
+		EncryptionKey:            strings.Repeat("k", 32),
+		DynamoDBTableName:        "transacciones",
+		DynamoDBControlTableName: "control",
+		IPFSHost:                 "localhost",
+		AttachmentsMaxBytes:      1 << 20,
+		AttachmentsMaxFiles:      1,
+		BatchMaxItems:            1,
+		BatchConcurrency:         1,
+		IPFSReplicationFactor:    1,
+		IdempotencyTTL:           60,
+		StreamHistorySize:        1,
+		StreamSubscriberBuffer:   1,
+		StreamHeartbeat:          1,
+		WebhookMaxAttempts:       1,
+		WebhookRetryBase:         1,
+		WebhookRetryMax:          1,
+		WebhookTimeout:           1,
+		WebhookConcurrency:       1,
+		WebhookRetentionDays:     1,
+		TLSClientAuth:            "optional",
+		HTTPEnabled:              true,
+		ServerPort:               "8080",
+		GRPCPort:                 "9090",
+		LogLevel:                 "info",
+		LogFormat:                "json",
+		ReadinessCheckTimeout:    1,
+		ReadinessMinBalance:      "0",
+		FundsCheckInterval:       1,
+		RateLimitRequests:        1,
+		RateLimitWindow:          1,
+		CORSAllowedOrigins:       []string{"*"},
+	}
+}
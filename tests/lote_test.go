@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+const eventoDistribucion = `{"tipoEvento":"distribucion","idProducto":"PROD-001","datosEvento":"{\"destino\":\"Farmacia Central\"}","actorEmisor":"Distribuidora Norte"}`
+
+// routerLote expone POST /lote con un servicio sin IPFS ni DynamoDB: solo se ejercita la validación previa
+func routerLote(servicio *services.TransaccionService, principal *models.Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErroresMiddleware())
+	router.POST("/lote", func(c *gin.Context) {
+		if principal != nil {
+			c.Set(middleware.ClavePrincipal, principal)
+			c.Request = c.Request.WithContext(auth.ConPrincipal(c.Request.Context(), principal))
+		}
+	}, handlers.NewTransaccionHandler(servicio).RegistrarLote)
+	return router
+}
+
+func enviarLote(router *gin.Engine, tipoContenido, cuerpo string) (*httptest.ResponseRecorder, map[string]any) {
+	req := httptest.NewRequest(http.MethodPost, "/lote", strings.NewReader(cuerpo))
+	req.Header.Set("Content-Type", tipoContenido)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var respuesta map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &respuesta)
+	return w, respuesta
+}
+
+func TestRegistrarLote_Validacion(t *testing.T) {
+	servicio := services.NewTransaccionService(nil, nil, nil)
+	servicio.ConfigurarLote(services.LoteConfig{MaximoElementos: 3, Concurrencia: 2})
+	router := routerLote(servicio, nil)
+
+	t.Run("Un elemento inválido rechaza todo el lote", func(t *testing.T) {
+		invalido := `{"tipoEvento":"distribucion","idProducto":"PROD-002","datosEvento":"{}","actorEmisor":"Distribuidora Norte"}`
+		w, respuesta := enviarLote(router, "application/json", "["+eventoDistribucion+","+invalido+"]")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		assert.Equal(t, middleware.CodigoLoteInvalido, respuesta["codigo"])
+		resultados := respuesta["resultados"].([]any)
+		require.Len(t, resultados, 2)
+		assert.Empty(t, resultados[0].(map[string]any)["error"])
+		segundo := resultados[1].(map[string]any)
+		assert.Equal(t, float64(1), segundo["indice"])
+		assert.Equal(t, models.LoteInvalida, segundo["estado"])
+		assert.Contains(t, segundo["error"], "destino")
+	})
+
+	t.Run("NDJSON con una línea mal formada", func(t *testing.T) {
+		w, respuesta := enviarLote(router, "application/x-ndjson", eventoDistribucion+"\n{no es json\n")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "solicitud-invalida", respuesta["codigo"])
+		assert.Contains(t, respuesta["detail"], "evento 1")
+	})
+
+	t.Run("Lote que supera el máximo", func(t *testing.T) {
+		lote := strings.Repeat(eventoDistribucion+"\n", 4)
+		w, _ := enviarLote(router, "application/x-ndjson", lote)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = enviarLote(router, "application/json", "["+strings.TrimSuffix(strings.Repeat(eventoDistribucion+",", 4), ",")+"]")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Lote vacío", func(t *testing.T) {
+		w, _ := enviarLote(router, "application/json", "[]")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRegistrarLote_TipoEventoNoAutorizado(t *testing.T) {
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+
+	servicio := services.NewTransaccionService(nil, nil, nil)
+	servicio.ConfigurarPolitica(motor)
+	router := routerLote(servicio, principalConRol("Distribuidora Norte", "distribuidor"))
+
+	recepcion := `{"tipoEvento":"recepcion","idProducto":"PROD-001","datosEvento":"{\"receptor\":\"Farmacia Central\"}","actorEmisor":"Distribuidora Norte"}`
+	w, respuesta := enviarLote(router, "application/json", "["+eventoDistribucion+","+recepcion+"]")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	segundo := respuesta["resultados"].([]any)[1].(map[string]any)
+	assert.Contains(t, segundo["error"], "recepcion-por-farmacias")
+}
+
+func TestRegistrarLote_Vacio(t *testing.T) {
+	servicio := services.NewTransaccionService(nil, nil, nil)
+	_, err := servicio.RegistrarLote(context.Background(), nil)
+	assert.ErrorIs(t, err, services.ErrLoteVacio)
+}
+
+func TestRegistrarLote_ConcurrenteConRegistroIndividual(t *testing.T) {
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	host, puerto := kubo.HostPort()
+	dynamo := NewMockDynamo()
+	defer dynamo.Close()
+
+	servicio := services.NewTransaccionService(nil, services.NewIPFSService(host, puerto), dynamo.Servicio())
+	servicio.ConfigurarLote(services.LoteConfig{MaximoElementos: 250, Concurrencia: 8})
+
+	var solicitud models.TransaccionRequest
+	require.NoError(t, json.Unmarshal([]byte(eventoDistribucion), &solicitud))
+	lote := make([]*models.TransaccionRequest, 2*services.MaxEventosEncadenados+10)
+	for i := range lote {
+		copia := solicitud
+		lote[i] = &copia
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	var respuesta *models.LoteResponse
+	var errLote error
+	individuales := make([]error, 10)
+	wg.Add(1 + len(individuales))
+	go func() {
+		defer wg.Done()
+		respuesta, errLote = servicio.RegistrarLote(ctx, lote)
+	}()
+	for i := range individuales {
+		go func(i int) {
+			defer wg.Done()
+			copia := solicitud
+			_, individuales[i] = servicio.RegistrarTransaccion(ctx, &copia)
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, errLote)
+
+	registrados := respuesta.Registradas
+	for _, err := range individuales {
+		if err == nil {
+			registrados++
+		}
+	}
+
+	dynamo.mu.Lock()
+	defer dynamo.mu.Unlock()
+	require.Len(t, dynamo.eventos, registrados, "solo se guardan los eventos que se reportan como registrados")
+	assert.Positive(t, dynamo.rechazos, "el lote y los registros individuales deben haber competido por la cabeza")
+
+	porSecuencia := make(map[int64]map[string]atributo)
+	for _, evento := range dynamo.eventos {
+		secuencia, err := strconv.ParseInt(fmt.Sprint(evento["secuencia"]["N"]), 10, 64)
+		require.NoError(t, err)
+		_, repetida := porSecuencia[secuencia]
+		require.False(t, repetida, "secuencia %d duplicada", secuencia)
+		porSecuencia[secuencia] = evento
+	}
+	for secuencia := int64(2); secuencia <= int64(registrados); secuencia++ {
+		evento, anterior := porSecuencia[secuencia], porSecuencia[secuencia-1]
+		require.NotNil(t, evento, "falta la secuencia %d", secuencia)
+		assert.Equal(t, anterior["hashEvento"]["S"], evento["hashEventoAnterior"]["S"], "secuencia %d", secuencia)
+	}
+	assert.Equal(t, strconv.Itoa(registrados), dynamo.cabezas["CADENA#PROD-001"]["secuencia"]["N"])
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/metricas"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// valorMetrica retorna el valor de un contador o gauge, o el número de observaciones de un histograma,
+// de la serie con exactamente esas etiquetas; 0 si la serie todavía no existe. Las métricas son globales,
+// así que los tests comparan diferencias.
+func valorMetrica(t *testing.T, nombre string, etiquetas map[string]string) float64 {
+	t.Helper()
+	familias, err := metricas.Registro.Gather()
+	require.NoError(t, err)
+	for _, familia := range familias {
+		if familia.GetName() != nombre {
+			continue
+		}
+	series:
+		for _, serie := range familia.GetMetric() {
+			if len(serie.GetLabel()) != len(etiquetas) {
+				continue
+			}
+			for _, etiqueta := range serie.GetLabel() {
+				if etiquetas[etiqueta.GetName()] != etiqueta.GetValue() {
+					continue series
+				}
+			}
+			switch {
+			case serie.Counter != nil:
+				return serie.Counter.GetValue()
+			case serie.Gauge != nil:
+				return serie.Gauge.GetValue()
+			case serie.Histogram != nil:
+				return float64(serie.Histogram.GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricas_HTTPYRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	capturarLogs(t, slog.LevelError)
+
+	engine := gin.New()
+	engine.Use(middleware.LoggerMiddleware(), middleware.ErroresMiddleware(), middleware.RateLimitMiddleware(1, 60))
+	engine.GET("/api/v1/transaccion/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	ruta := map[string]string{"metodo": "GET", "ruta": "/api/v1/transaccion/:id", "estado": "200"}
+	limitada := map[string]string{"metodo": "GET", "ruta": "/api/v1/transaccion/:id", "estado": "429"}
+	sinRuta := map[string]string{"metodo": "GET", "ruta": metricas.RutaSinCoincidencia, "estado": "404"}
+	rechazos := map[string]string{"protocolo": "http"}
+	antes := map[string]float64{
+		"ok":       valorMetrica(t, "medisupply_http_peticiones_total", ruta),
+		"limitada": valorMetrica(t, "medisupply_http_peticiones_total", limitada),
+		"sin_ruta": valorMetrica(t, "medisupply_http_peticiones_total", sinRuta),
+		"rechazos": valorMetrica(t, "medisupply_rate_limit_rechazos_total", rechazos),
+		"latencia": valorMetrica(t, "medisupply_http_duracion_segundos", map[string]string{"metodo": "GET", "ruta": "/api/v1/transaccion/:id"}),
+	}
+
+	for _, url := range []string{"/api/v1/transaccion/tx-1", "/api/v1/transaccion/tx-2"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	}
+	peticion := httptest.NewRequest(http.MethodGet, "/no/existe/123", nil)
+	peticion.RemoteAddr = "10.9.9.9:1234"
+	engine.ServeHTTP(httptest.NewRecorder(), peticion)
+
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_http_peticiones_total", ruta)-antes["ok"], "la ruta se etiqueta con su plantilla, no con el ID")
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_http_peticiones_total", limitada)-antes["limitada"])
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_http_peticiones_total", sinRuta)-antes["sin_ruta"])
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_rate_limit_rechazos_total", rechazos)-antes["rechazos"])
+	assert.Equal(t, 2.0, valorMetrica(t, "medisupply_http_duracion_segundos", map[string]string{"metodo": "GET", "ruta": "/api/v1/transaccion/:id"})-antes["latencia"])
+}
+
+func TestMetricas_IPFS(t *testing.T) {
+	capturarLogs(t, slog.LevelError)
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	host, puerto := kubo.HostPort()
+	ipfs := services.NewIPFSService(host, puerto)
+	ctx := context.Background()
+
+	operacion := func(nombre string) map[string]string { return map[string]string{"operacion": nombre} }
+	antes := map[string]float64{}
+	for _, nombre := range []string{metricas.IPFSAdd, metricas.IPFSCat, metricas.IPFSPin} {
+		antes[nombre] = valorMetrica(t, "medisupply_ipfs_duracion_segundos", operacion(nombre))
+		antes["error_"+nombre] = valorMetrica(t, "medisupply_ipfs_errores_total", operacion(nombre))
+	}
+
+	cid, _, err := ipfs.AlmacenarJSON(ctx, `{"lote":"L-1"}`)
+	require.NoError(t, err)
+	_, err = ipfs.RecuperarJSON(ctx, cid)
+	require.NoError(t, err)
+	// Un pin fallido cuenta como error aunque el registro continúe (sin mínimo de pines configurado)
+	kubo.FallarPin = true
+	_, _, _ = ipfs.AlmacenarJSON(ctx, `{"lote":"L-2"}`)
+
+	assert.Equal(t, 2.0, valorMetrica(t, "medisupply_ipfs_duracion_segundos", operacion(metricas.IPFSAdd))-antes[metricas.IPFSAdd])
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_ipfs_duracion_segundos", operacion(metricas.IPFSCat))-antes[metricas.IPFSCat])
+	assert.Equal(t, 2.0, valorMetrica(t, "medisupply_ipfs_duracion_segundos", operacion(metricas.IPFSPin))-antes[metricas.IPFSPin])
+	assert.Equal(t, 0.0, valorMetrica(t, "medisupply_ipfs_errores_total", operacion(metricas.IPFSAdd))-antes["error_"+metricas.IPFSAdd])
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_ipfs_errores_total", operacion(metricas.IPFSPin))-antes["error_"+metricas.IPFSPin])
+}
+
+func TestMetricas_DynamoDB(t *testing.T) {
+	var fallar atomic.Bool
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if fallar.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"tabla inexistente"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Table":{"TableName":"transacciones","TableStatus":"ACTIVE"}}`))
+	}))
+	defer servidor.Close()
+
+	cliente := dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("id", "secreto", ""),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(servidor.URL),
+	}, services.InstrumentarDynamoDB)
+
+	ok := map[string]string{"operacion": "DescribeTable", "resultado": metricas.ResultadoOK}
+	fallida := map[string]string{"operacion": "DescribeTable", "resultado": metricas.ResultadoError}
+	antesOK, antesError := valorMetrica(t, "medisupply_dynamodb_duracion_segundos", ok), valorMetrica(t, "medisupply_dynamodb_duracion_segundos", fallida)
+
+	_, err := cliente.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String("transacciones")})
+	require.NoError(t, err)
+	fallar.Store(true)
+	_, err = cliente.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String("transacciones")})
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_dynamodb_duracion_segundos", ok)-antesOK)
+	assert.Equal(t, 1.0, valorMetrica(t, "medisupply_dynamodb_duracion_segundos", fallida)-antesError)
+}
+
+func TestMetricas_Exposicion(t *testing.T) {
+	metricas.TransaccionRegistrada("fabricacion")
+	metricas.Verificacion(metricas.VerificacionDiscrepancia)
+	metricas.AnclajesEncolados(2)
+	metricas.AnclajeTerminado()
+	defer metricas.AnclajeTerminado()
+
+	w := httptest.NewRecorder()
+	metricas.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	cuerpo := w.Body.String()
+	for _, esperado := range []string{
+		`medisupply_transacciones_registradas_total{tipo_evento="fabricacion"}`,
+		`medisupply_verificaciones_total{resultado="discrepancia"}`,
+		"# TYPE medisupply_anclajes_pendientes gauge",
+		"# TYPE medisupply_blockchain_gas_usado histogram",
+		"go_goroutines",
+	} {
+		assert.Contains(t, cuerpo, esperado)
+	}
+	assert.GreaterOrEqual(t, valorMetrica(t, "medisupply_anclajes_pendientes", map[string]string{}), 1.0)
+}
@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// certificadoPrueba es un certificado generado para los tests junto con su clave
+type certificadoPrueba struct {
+	cert *x509.Certificate
+	tls  tls.Certificate
+	pem  []byte
+	key  []byte
+}
+
+// emitirCertificado genera un certificado firmado por ca (autofirmado si ca es nil)
+func emitirCertificado(t *testing.T, ca *certificadoPrueba, plantilla *x509.Certificate) *certificadoPrueba {
+	t.Helper()
+	clave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serie, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	plantilla.SerialNumber = serie
+	plantilla.NotBefore = time.Now().Add(-time.Hour)
+	plantilla.NotAfter = time.Now().Add(time.Hour)
+
+	padre, clavePadre := plantilla, any(clave)
+	if ca != nil {
+		padre, clavePadre = ca.cert, ca.tls.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, padre, &clave.PublicKey, clavePadre)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	claveDER, err := x509.MarshalECPrivateKey(clave)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	clavePEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: claveDER})
+	par, err := tls.X509KeyPair(certPEM, clavePEM)
+	require.NoError(t, err)
+
+	return &certificadoPrueba{cert: cert, tls: par, pem: certPEM, key: clavePEM}
+}
+
+func emitirCA(t *testing.T, nombre string) *certificadoPrueba {
+	return emitirCertificado(t, nil, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: nombre},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+}
+
+func emitirServidor(t *testing.T, ca *certificadoPrueba, nombre string) *certificadoPrueba {
+	return emitirCertificado(t, ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: nombre},
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+func emitirCliente(t *testing.T, ca *certificadoPrueba, subject pkix.Name, uris ...string) *certificadoPrueba {
+	plantilla := &x509.Certificate{
+		Subject:     subject,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, u := range uris {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		plantilla.URIs = append(plantilla.URIs, parsed)
+	}
+	return emitirCertificado(t, ca, plantilla)
+}
+
+// escribirMaterial guarda certificado y clave del servidor y el bundle de CAs en archivos
+func escribirMaterial(t *testing.T, dir string, servidor, caClientes *certificadoPrueba) auth.ConfigTLS {
+	t.Helper()
+	cfg := auth.ConfigTLS{
+		ArchivoCertificado: filepath.Join(dir, "servidor.crt"),
+		ArchivoClave:       filepath.Join(dir, "servidor.key"),
+		ArchivoCAClientes:  filepath.Join(dir, "clientes-ca.pem"),
+		ModoClientes:       auth.ClientesOpcional,
+	}
+	require.NoError(t, os.WriteFile(cfg.ArchivoCertificado, servidor.pem, 0o600))
+	require.NoError(t, os.WriteFile(cfg.ArchivoClave, servidor.key, 0o600))
+	require.NoError(t, os.WriteFile(cfg.ArchivoCAClientes, caClientes.pem, 0o600))
+	return cfg
+}
+
+// clienteTLS crea un cliente HTTPS que confía en caServidor y presenta certificado si no es nil
+func clienteTLS(caServidor, certificado *certificadoPrueba) *http.Client {
+	raices := x509.NewCertPool()
+	raices.AddCert(caServidor.cert)
+	config := &tls.Config{RootCAs: raices, ServerName: "localhost"}
+	if certificado != nil {
+		config.Certificates = []tls.Certificate{certificado.tls}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+}
+
+func TestMTLS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	caServidor := emitirCA(t, "CA servidores")
+	caClientes := emitirCA(t, "CA socios")
+	servidor := emitirServidor(t, caServidor, "medisupply-1")
+	cfg := escribirMaterial(t, t.TempDir(), servidor, caClientes)
+
+	recargador, err := auth.NewRecargadorTLS(cfg)
+	require.NoError(t, err)
+
+	certificados := services.NewCertificadoService(services.NewAlmacenCertificadosMemoria())
+	ctx := context.Background()
+	_, err = certificados.RegistrarIdentidad(ctx, &models.IdentidadCertificadoRequest{
+		Identidad: "spiffe://socios.medisupply/laboratorio-abc",
+		Nombre:    "ERP Laboratorio ABC",
+		Actor:     "Laboratorio ABC",
+		Roles:     []string{"fabricante"},
+	})
+	require.NoError(t, err)
+	_, err = certificados.RegistrarIdentidad(ctx, &models.IdentidadCertificadoRequest{
+		Identidad: "CN=erp.farmacia-central,O=Farmacia Central",
+		Nombre:    "Farmacia Central",
+		Actor:     "Farmacia Central",
+		Roles:     []string{"farmacia"},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(middleware.AuthConfig{
+		APIKeys:      services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria()),
+		Certificados: certificados,
+	}))
+	router.GET("/quien", func(c *gin.Context) {
+		c.JSON(http.StatusOK, middleware.PrincipalDesdeContexto(c))
+	})
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.TLS = recargador.ConfigServidor()
+	srv.StartTLS()
+	defer srv.Close()
+
+	obtener := func(cliente *http.Client) (*http.Response, models.Principal) {
+		resp, err := cliente.Get(srv.URL + "/quien")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var principal models.Principal
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&principal))
+		}
+		return resp, principal
+	}
+
+	t.Run("SAN URI registrado", func(t *testing.T) {
+		cliente := emitirCliente(t, caClientes, pkix.Name{CommonName: "erp"}, "spiffe://socios.medisupply/laboratorio-abc")
+		resp, principal := obtener(clienteTLS(caServidor, cliente))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Laboratorio ABC", principal.Actor)
+		assert.Equal(t, models.MetodoCertificado, principal.Metodo)
+	})
+
+	t.Run("Subject registrado", func(t *testing.T) {
+		cliente := emitirCliente(t, caClientes, pkix.Name{CommonName: "erp.farmacia-central", Organization: []string{"Farmacia Central"}})
+		resp, principal := obtener(clienteTLS(caServidor, cliente))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Farmacia Central", principal.Actor)
+	})
+
+	t.Run("Certificado sin identidad registrada", func(t *testing.T) {
+		cliente := emitirCliente(t, caClientes, pkix.Name{CommonName: "desconocido"})
+		resp, _ := obtener(clienteTLS(caServidor, cliente))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Certificado de otra CA no autentica", func(t *testing.T) {
+		// El cliente no lo presenta porque no coincide con las CAs anunciadas, o el handshake falla
+		intruso := emitirCliente(t, emitirCA(t, "CA ajena"), pkix.Name{CommonName: "erp"}, "spiffe://socios.medisupply/laboratorio-abc")
+		resp, err := clienteTLS(caServidor, intruso).Get(srv.URL + "/quien")
+		if err == nil {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("Sin certificado ni credencial", func(t *testing.T) {
+		resp, _ := obtener(clienteTLS(caServidor, nil))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Identidad revocada", func(t *testing.T) {
+		_, err := certificados.RevocarIdentidad(ctx, "CN=erp.farmacia-central,O=Farmacia Central")
+		require.NoError(t, err)
+		cliente := emitirCliente(t, caClientes, pkix.Name{CommonName: "erp.farmacia-central", Organization: []string{"Farmacia Central"}})
+		resp, _ := obtener(clienteTLS(caServidor, cliente))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("SAN revocado antes de otro SAN activo", func(t *testing.T) {
+		_, err := certificados.RegistrarIdentidad(ctx, &models.IdentidadCertificadoRequest{
+			Identidad: "spiffe://socios.medisupply/erp-retirado",
+			Nombre:    "ERP retirado",
+			Actor:     "Laboratorio ABC",
+			Roles:     []string{"fabricante"},
+		})
+		require.NoError(t, err)
+		_, err = certificados.RevocarIdentidad(ctx, "spiffe://socios.medisupply/erp-retirado")
+		require.NoError(t, err)
+
+		// La identidad activa del segundo SAN no rescata un certificado con un SAN revocado
+		cliente := emitirCliente(t, caClientes, pkix.Name{CommonName: "erp"},
+			"spiffe://socios.medisupply/erp-retirado", "spiffe://socios.medisupply/laboratorio-abc")
+		resp, _ := obtener(clienteTLS(caServidor, cliente))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Recarga de certificados sin cortar conexiones", func(t *testing.T) {
+		cliente := clienteTLS(caServidor, emitirCliente(t, caClientes, pkix.Name{CommonName: "erp"}, "spiffe://socios.medisupply/laboratorio-abc"))
+		resp, err := cliente.Get(srv.URL + "/quien")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "medisupply-1", resp.TLS.PeerCertificates[0].Subject.CommonName)
+
+		// Un archivo inválido no reemplaza el material vigente
+		require.NoError(t, os.WriteFile(cfg.ArchivoCertificado, []byte("no es PEM"), 0o600))
+		assert.Error(t, recargador.Recargar())
+
+		nuevo := emitirServidor(t, caServidor, "medisupply-2")
+		escribirMaterial(t, filepath.Dir(cfg.ArchivoCertificado), nuevo, caClientes)
+		require.NoError(t, recargador.Recargar())
+
+		// La conexión abierta sigue sirviendo con el certificado con el que se negoció
+		resp, err = cliente.Get(srv.URL + "/quien")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "medisupply-1", resp.TLS.PeerCertificates[0].Subject.CommonName)
+
+		// Los handshakes nuevos usan el certificado recargado
+		resp, _ = obtener(clienteTLS(caServidor, emitirCliente(t, caClientes, pkix.Name{CommonName: "erp"}, "spiffe://socios.medisupply/laboratorio-abc")))
+		assert.Equal(t, "medisupply-2", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+}
+
+func TestNewRecargadorTLS_RequiereCAParaModoRequire(t *testing.T) {
+	_, err := auth.NewRecargadorTLS(auth.ConfigTLS{
+		ArchivoCertificado: "servidor.crt",
+		ArchivoClave:       "servidor.key",
+		ModoClientes:       auth.ClientesRequeridos,
+	})
+	assert.Error(t, err)
+}
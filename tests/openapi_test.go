@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/api"
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+)
+
+// documentoOpenAPI es la parte del documento que verifican los tests de contrato
+type documentoOpenAPI struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func leerOpenAPI(t *testing.T) documentoOpenAPI {
+	t.Helper()
+	var doc documentoOpenAPI
+	require.NoError(t, json.Unmarshal(api.OpenAPI, &doc))
+	return doc
+}
+
+// routerCompleto registra todas las rutas con autenticación habilitada; los handlers no se ejecutan
+func routerCompleto() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return router.Configurar(&config.Config{AuthEnabled: true, MetricsEnabled: true, RateLimitRequests: 100, RateLimitWindow: 60}, router.Dependencias{})
+}
+
+var parametroGin = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// TestOpenAPI_RutasCoinciden falla si se agrega, elimina o renombra una ruta sin actualizar api/openapi.json
+func TestOpenAPI_RutasCoinciden(t *testing.T) {
+	doc := leerOpenAPI(t)
+
+	registradas := map[string]bool{}
+	for _, ruta := range routerCompleto().Routes() {
+		registradas[ruta.Method+" "+parametroGin.ReplaceAllString(ruta.Path, "{$1}")] = true
+	}
+
+	documentadas := map[string]bool{}
+	for ruta, operaciones := range doc.Paths {
+		for metodo := range operaciones {
+			if metodo == "parameters" {
+				continue
+			}
+			documentadas[strings.ToUpper(metodo)+" "+ruta] = true
+		}
+	}
+
+	var sinDocumentar, inexistentes []string
+	for ruta := range registradas {
+		if !documentadas[ruta] {
+			sinDocumentar = append(sinDocumentar, ruta)
+		}
+	}
+	for ruta := range documentadas {
+		if !registradas[ruta] {
+			inexistentes = append(inexistentes, ruta)
+		}
+	}
+	sort.Strings(sinDocumentar)
+	sort.Strings(inexistentes)
+	assert.Empty(t, sinDocumentar, "rutas registradas que no están en api/openapi.json")
+	assert.Empty(t, inexistentes, "rutas de api/openapi.json que el router no registra")
+}
+
+func TestOpenAPI_Documento(t *testing.T) {
+	doc := leerOpenAPI(t)
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+
+	t.Run("Cada operación tiene operationId único", func(t *testing.T) {
+		vistos := map[string]string{}
+		for ruta, operaciones := range doc.Paths {
+			for metodo, crudo := range operaciones {
+				var operacion struct {
+					OperationID string `json:"operationId"`
+				}
+				require.NoError(t, json.Unmarshal(crudo, &operacion))
+				require.NotEmpty(t, operacion.OperationID, "%s %s sin operationId", metodo, ruta)
+				previo, repetido := vistos[operacion.OperationID]
+				assert.False(t, repetido, "operationId %s repetido en %s y %s %s", operacion.OperationID, previo, metodo, ruta)
+				vistos[operacion.OperationID] = metodo + " " + ruta
+			}
+		}
+	})
+
+	t.Run("Todas las referencias existen", func(t *testing.T) {
+		referencias := regexp.MustCompile(`"\$ref":\s*"#/components/(schemas|responses)/([^"]+)"`).FindAllStringSubmatch(string(api.OpenAPI), -1)
+		require.NotEmpty(t, referencias)
+
+		var componentes struct {
+			Components map[string]map[string]json.RawMessage `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(api.OpenAPI, &componentes))
+		for _, ref := range referencias {
+			assert.Contains(t, componentes.Components[ref[1]], ref[2], "referencia rota: %s", ref[0])
+		}
+	})
+
+	t.Run("Los esquemas coinciden con los modelos", func(t *testing.T) {
+		modelos := map[string]any{
+			"Transaccion":                 models.Transaccion{},
+			"TransaccionRequest":          models.TransaccionRequest{},
+			"TransaccionResponse":         models.TransaccionResponse{},
+			"Adjunto":                     models.Adjunto{},
+			"VerificacionResponse":        models.VerificacionResponse{},
+			"EstadoBlockchainResponse":    models.EstadoBlockchainResponse{},
+			"HistorialVerificado":         models.HistorialVerificado{},
+			"EventoVerificado":            models.EventoVerificado{},
+			"VerificacionCadena":          models.VerificacionCadena{},
+			"AnomaliaCadena":              models.AnomaliaCadena{},
+			"OracleDataResponse":          models.OracleDataResponse{},
+			"ResultadoLote":               models.ResultadoLote{},
+			"LoteResponse":                models.LoteResponse{},
+			"APIKey":                      models.APIKey{},
+			"APIKeyRequest":               models.APIKeyRequest{},
+			"APIKeyCreadaResponse":        models.APIKeyCreadaResponse{},
+			"IdentidadCertificado":        models.IdentidadCertificado{},
+			"IdentidadCertificadoRequest": models.IdentidadCertificadoRequest{},
+			"Notificacion":                models.Notificacion{},
+			"Webhook":                     models.Webhook{},
+			"WebhookRequest":              models.WebhookRequest{},
+			"WebhookCreadoResponse":       models.WebhookCreadoResponse{},
+			"EntregaWebhook":              models.EntregaWebhook{},
+			"IntentoEntrega":              models.IntentoEntrega{},
+			"EventoWebhook":               models.EventoWebhook{},
+			"EstadoFondos":                models.EstadoFondos{},
+			"AlertaFondos":                models.AlertaFondos{},
+			"Problema":                    models.Problema{},
+		}
+		for nombre, modelo := range modelos {
+			esquema, ok := doc.Components.Schemas[nombre]
+			if !assert.True(t, ok, "falta el esquema %s", nombre) {
+				continue
+			}
+			campos := camposJSON(reflect.TypeOf(modelo))
+			documentados := make([]string, 0, len(esquema.Properties))
+			for propiedad := range esquema.Properties {
+				documentados = append(documentados, propiedad)
+			}
+			sort.Strings(documentados)
+			assert.Equal(t, campos, documentados, "propiedades del esquema %s", nombre)
+			for _, requerido := range esquema.Required {
+				_, existe := esquema.Properties[requerido]
+				assert.True(t, existe, "%s requiere la propiedad inexistente %s", nombre, requerido)
+			}
+		}
+	})
+}
+
+// camposJSON lista los nombres JSON serializados de un struct
+func camposJSON(tipo reflect.Type) []string {
+	var campos []string
+	for i := 0; i < tipo.NumField(); i++ {
+		campo := tipo.Field(i)
+		if !campo.IsExported() {
+			continue
+		}
+		nombre := strings.Split(campo.Tag.Get("json"), ",")[0]
+		if nombre == "-" {
+			continue
+		}
+		if nombre == "" {
+			nombre = campo.Name
+		}
+		campos = append(campos, nombre)
+	}
+	sort.Strings(campos)
+	return campos
+}
+
+func TestOpenAPI_EsPublico(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	routerCompleto().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.JSONEq(t, string(api.OpenAPI), w.Body.String())
+}
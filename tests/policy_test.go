@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/auth"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+)
+
+// principalConRol crea un principal de prueba
+func principalConRol(actor string, roles ...string) *models.Principal {
+	return &models.Principal{ID: actor, Actor: actor, Roles: roles, Metodo: models.MetodoAPIKey}
+}
+
+// reglaDenegada retorna el nombre de la regla que denegó, o "" si err no es una denegación
+func reglaDenegada(err error) string {
+	var denegacion *policy.Denegacion
+	if errors.As(err, &denegacion) {
+		return denegacion.Regla
+	}
+	return ""
+}
+
+func TestMotorPolitica(t *testing.T) {
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err, "La política embebida debe ser válida")
+
+	fabricante := principalConRol("Laboratorio ABC", "fabricante")
+	farmacia := principalConRol("Farmacia Central", "farmacia")
+	auditor := principalConRol("Auditoría", "auditor")
+	admin := principalConRol("admin", "admin")
+
+	t.Run("Rutas", func(t *testing.T) {
+		assert.NoError(t, motor.AutorizarRuta(fabricante, "POST", "/api/v1/transaccion/registrar"))
+		assert.NoError(t, motor.AutorizarRuta(auditor, "GET", "/api/v1/oracle/historial/:id"))
+		assert.NoError(t, motor.AutorizarRuta(admin, "DELETE", "/api/v1/admin/api-keys/:id"))
+		assert.NoError(t, motor.AutorizarRuta(farmacia, "GET", "/api/v1/transaccion"), "/x/* cubre también /x")
+
+		assert.Equal(t, "registrar-eventos", reglaDenegada(motor.AutorizarRuta(auditor, "POST", "/api/v1/transaccion/registrar")))
+		assert.Equal(t, "consultar-transacciones", reglaDenegada(motor.AutorizarRuta(auditor, "GET", "/api/v1/transaccion/:id")))
+		assert.Equal(t, "administrar-actores", reglaDenegada(motor.AutorizarRuta(fabricante, "POST", "/api/v1/admin/api-keys")))
+		assert.Equal(t, policy.ReglaPorDefecto, reglaDenegada(motor.AutorizarRuta(admin, "PUT", "/api/v1/transaccion/:id")))
+	})
+
+	t.Run("Tipos de evento", func(t *testing.T) {
+		assert.NoError(t, motor.AutorizarEvento(fabricante, "fabricacion"))
+		assert.NoError(t, motor.AutorizarEvento(farmacia, "recepcion"))
+		assert.NoError(t, motor.AutorizarEvento(admin, "distribucion"))
+
+		assert.Equal(t, "recepcion-por-farmacias", reglaDenegada(motor.AutorizarEvento(fabricante, "recepcion")))
+		assert.Equal(t, "fabricacion-por-fabricantes", reglaDenegada(motor.AutorizarEvento(farmacia, "fabricacion")))
+	})
+
+	t.Run("Propiedad del producto", func(t *testing.T) {
+		assert.NoError(t, motor.AutorizarPropiedad(fabricante, "fabricacion", "PROD-001", ""), "Un producto nuevo puede reclamarse")
+		assert.NoError(t, motor.AutorizarPropiedad(fabricante, "fabricacion", "PROD-001", "Laboratorio ABC"))
+		assert.NoError(t, motor.AutorizarPropiedad(admin, "fabricacion", "PROD-001", "Laboratorio ABC"), "Los roles sin regla de propiedad están exentos")
+
+		err := motor.AutorizarPropiedad(fabricante, "fabricacion", "PROD-001", "Otro Laboratorio")
+		assert.Equal(t, "fabricante-propietario", reglaDenegada(err))
+	})
+}
+
+func TestParsearPolitica(t *testing.T) {
+	_, err := policy.ParsearPolitica([]byte("rutas:\n  - nombre: a\n    metodos: [GET]\n    rutas: [/x]\n    roles: [r]\n  - nombre: a\n    metodos: [GET]\n    rutas: [/y]\n    roles: [r]\n"))
+	assert.ErrorContains(t, err, "duplicada")
+
+	_, err = policy.ParsearPolitica([]byte("eventos:\n  - nombre: sin-roles\n    tiposEvento: [fabricacion]\n"))
+	assert.ErrorContains(t, err, "no tiene roles")
+
+	_, err = policy.ParsearPolitica([]byte("rutass: []\n"))
+	assert.Error(t, err, "Los campos desconocidos se rechazan")
+}
+
+func TestMotorPolitica_Recarga(t *testing.T) {
+	archivo := filepath.Join(t.TempDir(), "politica.yaml")
+	escribir := func(roles string) {
+		contenido := "eventos:\n  - nombre: fabricacion\n    tiposEvento: [fabricacion]\n    roles: [" + roles + "]\n"
+		require.NoError(t, os.WriteFile(archivo, []byte(contenido), 0o600))
+	}
+	escribir("fabricante")
+
+	motor, err := policy.NewMotor(archivo)
+	require.NoError(t, err)
+	farmacia := principalConRol("Farmacia Central", "farmacia")
+	require.Error(t, motor.AutorizarEvento(farmacia, "fabricacion"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go motor.Vigilar(ctx, 10*time.Millisecond)
+
+	// Un archivo inválido no reemplaza la política vigente
+	require.NoError(t, os.WriteFile(archivo, []byte("no: [es, una politica"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	assert.Error(t, motor.AutorizarEvento(farmacia, "fabricacion"))
+
+	escribir("fabricante, farmacia")
+	require.NoError(t, os.Chtimes(archivo, time.Now(), time.Now().Add(time.Second)))
+	assert.Eventually(t, func() bool {
+		return motor.AutorizarEvento(farmacia, "fabricacion") == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestAutorizacionMiddleware(t *testing.T) {
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set(middleware.ClavePrincipal, principalConRol("Auditoría", "auditor"))
+	}, middleware.AutorizacionMiddleware(motor))
+	api.GET("/oracle/datos/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.GET("/transaccion/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/oracle/datos/PROD-001", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/transaccion/tx-1", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	var problema models.Problema
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problema))
+	assert.Equal(t, middleware.CodigoAccesoDenegado, problema.Codigo)
+	assert.Equal(t, "consultar-transacciones", problema.Regla)
+}
+
+func TestRegistrarTransaccion_TipoEventoNoAutorizado(t *testing.T) {
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+
+	transaccionService := services.NewTransaccionService(nil, nil, nil)
+	transaccionService.ConfigurarPolitica(motor)
+
+	ctx := auth.ConPrincipal(context.Background(), principalConRol("Farmacia Central", "farmacia"))
+	_, err = transaccionService.RegistrarTransaccion(ctx, GetMockTransaccionRequest())
+	assert.Equal(t, "fabricacion-por-fabricantes", reglaDenegada(err))
+}
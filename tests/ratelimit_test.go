@@ -0,0 +1,334 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/config"
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/models"
+	"github.com/edinfamous/blockchain-medisupply/internal/policy"
+	"github.com/edinfamous/blockchain-medisupply/internal/ratelimit"
+	"github.com/edinfamous/blockchain-medisupply/internal/router"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// limitadorPrueba usa ventanas de una hora para que ningún test cruce el cambio de ventana
+func limitadorPrueba(limitador ratelimit.Limitador, limite int) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(limitador, ratelimit.Cuotas{PorDefecto: ratelimit.Cuota{Limite: limite, Ventana: time.Hour}})
+}
+
+func TestRateLimit_Memoria(t *testing.T) {
+	ctx := context.Background()
+	memoria := ratelimit.NewMemoria()
+	cuota := ratelimit.Cuota{Limite: 2, Ventana: time.Hour}
+
+	t.Run("Ventana fija con consulta sin consumo", func(t *testing.T) {
+		decision, err := memoria.Consumir(ctx, "a", cuota, 0)
+		require.NoError(t, err)
+		assert.True(t, decision.Permitida)
+		assert.Equal(t, 2, decision.Restantes, "consultar no consume")
+
+		for _, restantes := range []int{1, 0} {
+			decision, err = memoria.Consumir(ctx, "a", cuota, 1)
+			require.NoError(t, err)
+			assert.True(t, decision.Permitida)
+			assert.Equal(t, restantes, decision.Restantes)
+		}
+		decision, _ = memoria.Consumir(ctx, "a", cuota, 0)
+		assert.False(t, decision.Permitida, "sin cupo la consulta también rechaza")
+		decision, _ = memoria.Consumir(ctx, "a", cuota, 1)
+		assert.False(t, decision.Permitida)
+		assert.Equal(t, 0, decision.Restantes)
+		assert.LessOrEqual(t, decision.Reinicio, time.Hour)
+
+		decision, _ = memoria.Consumir(ctx, "b", cuota, 1)
+		assert.True(t, decision.Permitida, "cada clave tiene su propio cupo")
+	})
+
+	t.Run("Las claves inactivas se descartan", func(t *testing.T) {
+		efimera := ratelimit.Cuota{Limite: 1, Ventana: 20 * time.Millisecond}
+		for i := 0; i < 50; i++ {
+			_, err := memoria.Consumir(ctx, "ip:10.0.0."+strconv.Itoa(i), efimera, 1)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 52, memoria.Entradas())
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, 50, memoria.Limpiar())
+		assert.Equal(t, 2, memoria.Entradas(), "las ventanas vigentes se conservan")
+	})
+}
+
+func TestRateLimit_Cuotas(t *testing.T) {
+	cuotas, err := ratelimit.NewCuotas(100, time.Minute, "admin=1000, auditor=50", "post /api/v1/transaccion/lote=10")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1000, cuotas.DeRoles([]string{"auditor", "admin"}).Limite, "con varios roles se aplica el mayor")
+	assert.Equal(t, 50, cuotas.DeRoles([]string{"auditor"}).Limite)
+	assert.Equal(t, 100, cuotas.DeRoles([]string{"farmacia"}).Limite)
+	ruta, ok := cuotas.DeRuta(http.MethodPost, "/api/v1/transaccion/lote")
+	require.True(t, ok)
+	assert.Equal(t, ratelimit.Cuota{Limite: 10, Ventana: time.Minute}, ruta)
+	_, ok = cuotas.DeRuta(http.MethodGet, "/api/v1/transaccion/lote")
+	assert.False(t, ok)
+
+	for _, invalida := range []struct{ roles, rutas string }{
+		{roles: "admin"},
+		{roles: "admin=0"},
+		{roles: "admin=10,admin=20"},
+		{rutas: "/api/v1/transaccion/lote=10"},
+		{rutas: "POST api/v1=10"},
+	} {
+		_, err := ratelimit.NewCuotas(100, time.Minute, invalida.roles, invalida.rutas)
+		assert.Error(t, err, "%+v", invalida)
+	}
+
+	cfg := configuracionValida()
+	cfg.RateLimitRoutes = "POST /lote"
+	assert.ErrorContains(t, cfg.Validate(), "RATE_LIMIT_ROUTES")
+	cfg = configuracionValida()
+	cfg.RateLimitStore = "redis"
+	assert.ErrorContains(t, cfg.Validate(), "RATE_LIMIT_STORE")
+}
+
+func TestRateLimit_HTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	capturarLogs(t, slog.LevelError)
+
+	ctx := context.Background()
+	motor, err := policy.NewMotor("")
+	require.NoError(t, err)
+	apiKeys := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+	norte, _, err := apiKeys.CrearAPIKey(ctx, &models.APIKeyRequest{Nombre: "ERP", Actor: "Distribuidora Norte", Roles: []string{"distribuidor"}})
+	require.NoError(t, err)
+	sur, _, err := apiKeys.CrearAPIKey(ctx, &models.APIKeyRequest{Nombre: "ERP", Actor: "Distribuidora Sur", Roles: []string{"distribuidor"}})
+	require.NoError(t, err)
+
+	cuotas, err := ratelimit.NewCuotas(2, time.Hour, "admin=5", "GET /api/v1/admin/certificados=1")
+	require.NoError(t, err)
+	engine := router.Configurar(&config.Config{AuthEnabled: true}, router.Dependencias{
+		APIKeyHandler:      handlers.NewAPIKeyHandler(apiKeys),
+		CertificadoHandler: handlers.NewCertificadoHandler(services.NewCertificadoService(services.NewAlmacenCertificadosMemoria())),
+		AuthConfig:         middleware.AuthConfig{APIKeys: apiKeys, ClaveArranque: claveArranqueCliente},
+		Politica:           motor,
+		RateLimiter:        middleware.NewRateLimiter(ratelimit.NewMemoria(), cuotas),
+	})
+
+	// Todas las peticiones salen de la misma IP salvo que se indique otra
+	consultar := func(ruta, clave, ip string) *httptest.ResponseRecorder {
+		peticion := httptest.NewRequest(http.MethodGet, ruta, nil)
+		peticion.RemoteAddr = ip + ":4321"
+		if clave != "" {
+			peticion.Header.Set("X-API-Key", clave)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, peticion)
+		return w
+	}
+
+	t.Run("Por principal con cabeceras RateLimit-*", func(t *testing.T) {
+		w := consultar("/api/v1/admin/api-keys", norte, "192.0.2.1")
+		assert.Equal(t, http.StatusForbidden, w.Code, "la autorización se evalúa después del rate limiting")
+		assert.Equal(t, "2", w.Header().Get(middleware.CabeceraRateLimitLimit))
+		assert.Equal(t, "1", w.Header().Get(middleware.CabeceraRateLimitRemaining))
+		assert.Equal(t, "2;w=3600", w.Header().Get(middleware.CabeceraRateLimitPolicy))
+		reinicio, err := strconv.Atoi(w.Header().Get(middleware.CabeceraRateLimitReset))
+		require.NoError(t, err)
+		assert.True(t, reinicio > 0 && reinicio <= 3600, "reinicio %d", reinicio)
+		assert.Empty(t, w.Header().Get(middleware.CabeceraRetryAfter))
+
+		consultar("/api/v1/admin/api-keys", norte, "192.0.2.1")
+		w = consultar("/api/v1/admin/api-keys", norte, "192.0.2.1")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get(middleware.CabeceraRateLimitRemaining))
+		assert.Equal(t, w.Header().Get(middleware.CabeceraRateLimitReset), w.Header().Get(middleware.CabeceraRetryAfter))
+		assert.Contains(t, w.Body.String(), middleware.CodigoDemasiadasPeticiones)
+
+		w = consultar("/api/v1/admin/api-keys", sur, "192.0.2.1")
+		assert.Equal(t, http.StatusForbidden, w.Code, "otro principal detrás de la misma IP conserva su cupo")
+	})
+
+	t.Run("Cuotas por rol y por ruta", func(t *testing.T) {
+		w := consultar("/api/v1/admin/certificados", claveArranqueCliente, "192.0.2.1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get(middleware.CabeceraRateLimitLimit), "se informa la cuota más restrictiva")
+		assert.Equal(t, "0", w.Header().Get(middleware.CabeceraRateLimitRemaining))
+		assert.Equal(t, http.StatusTooManyRequests, consultar("/api/v1/admin/certificados", claveArranqueCliente, "192.0.2.1").Code)
+
+		for restantes := 2; restantes >= 0; restantes-- {
+			w = consultar("/api/v1/admin/api-keys", claveArranqueCliente, "192.0.2.1")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "5", w.Header().Get(middleware.CabeceraRateLimitLimit), "el rol admin tiene su propia cuota")
+			assert.Equal(t, strconv.Itoa(restantes), w.Header().Get(middleware.CabeceraRateLimitRemaining))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, consultar("/api/v1/admin/api-keys", claveArranqueCliente, "192.0.2.1").Code)
+	})
+
+	t.Run("Las credenciales rechazadas consumen el cupo de la IP", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, consultar("/api/v1/admin/api-keys", "msk_no-existe", "192.0.2.1").Code,
+			"las peticiones autenticadas no consumieron el cupo de la IP")
+		assert.Equal(t, http.StatusUnauthorized, consultar("/api/v1/admin/api-keys", "msk_no-existe", "192.0.2.1").Code)
+		w := consultar("/api/v1/admin/api-keys", "msk_no-existe", "192.0.2.1")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get(middleware.CabeceraRetryAfter))
+	})
+
+	t.Run("Las rutas públicas se limitan por IP", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, consultar("/", "", "198.51.100.7").Code)
+		assert.Equal(t, http.StatusOK, consultar("/api/v1/openapi.json", "", "198.51.100.7").Code)
+		assert.Equal(t, http.StatusTooManyRequests, consultar("/", "", "198.51.100.7").Code)
+		assert.Equal(t, http.StatusOK, consultar("/", "", "198.51.100.8").Code)
+	})
+}
+
+func TestRateLimit_GRPC(t *testing.T) {
+	apiKeys := services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())
+	norte, _, err := apiKeys.CrearAPIKey(context.Background(), &models.APIKeyRequest{Nombre: "Auditoría", Actor: "Auditor Norte", Roles: []string{"auditor"}})
+	require.NoError(t, err)
+	sur, _, err := apiKeys.CrearAPIKey(context.Background(), &models.APIKeyRequest{Nombre: "Auditoría", Actor: "Auditor Sur", Roles: []string{"auditor"}})
+	require.NoError(t, err)
+
+	deps := dependenciasGRPC(t, apiKeys)
+	deps.RateLimiter = limitadorPrueba(ratelimit.NewMemoria(), 1)
+	transacciones := pb.NewTransaccionesClient(conectarGRPC(t, deps, nil))
+
+	var cabeceras metadata.MD
+	// La política rechaza a los auditores después del rate limiting, sin llegar al almacenamiento
+	_, err = transacciones.ListarTransacciones(conAPIKey(norte), &pb.ListarTransaccionesRequest{}, grpc.Header(&cabeceras))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []string{"1"}, cabeceras.Get("ratelimit-limit"))
+	assert.Equal(t, []string{"0"}, cabeceras.Get("ratelimit-remaining"))
+
+	_, err = transacciones.ListarTransacciones(conAPIKey(norte), &pb.ListarTransaccionesRequest{}, grpc.Header(&cabeceras))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(t, cabeceras.Get("retry-after"))
+
+	_, err = transacciones.ListarTransacciones(conAPIKey(sur), &pb.ListarTransaccionesRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "el cupo es por principal, no por conexión")
+}
+
+// dynamoContadores simula los UpdateItem ADD de DynamoDB sobre la tabla de control
+type dynamoContadores struct {
+	*httptest.Server
+	mu         sync.Mutex
+	cuentas    map[string]int64
+	expiraEn   map[string]string
+	fallar     atomic.Bool
+	peticiones atomic.Int64
+}
+
+func nuevoDynamoContadores(t *testing.T) *dynamoContadores {
+	t.Helper()
+	d := &dynamoContadores{cuentas: make(map[string]int64), expiraEn: make(map[string]string)}
+	d.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.peticiones.Add(1)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if d.fallar.Load() || !strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".UpdateItem") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+			return
+		}
+		var entrada struct {
+			Key                       map[string]map[string]string
+			ExpressionAttributeValues map[string]map[string]string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n, _ := strconv.ParseInt(entrada.ExpressionAttributeValues[":n"]["N"], 10, 64)
+		clave := entrada.Key["pk"]["S"]
+
+		d.mu.Lock()
+		d.cuentas[clave] += n
+		if _, ok := d.expiraEn[clave]; !ok {
+			d.expiraEn[clave] = entrada.ExpressionAttributeValues[":expiraEn"]["N"]
+		}
+		cuenta := d.cuentas[clave]
+		d.mu.Unlock()
+		_, _ = w.Write([]byte(`{"Attributes":{"cuenta":{"N":"` + strconv.FormatInt(cuenta, 10) + `"}}}`))
+	}))
+	t.Cleanup(d.Close)
+	return d
+}
+
+// replica crea un DynamoDBService propio, como el de cada réplica del servicio
+func (d *dynamoContadores) replica() *services.DynamoDBService {
+	return services.NewDynamoDBService(dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("id", "secreto", ""),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(d.URL),
+	}), "transacciones", "control")
+}
+
+func TestRateLimit_Compartido(t *testing.T) {
+	ctx := context.Background()
+	dynamo := nuevoDynamoContadores(t)
+	replicaA := limitadorPrueba(ratelimit.NewCompartido(dynamo.replica()), 3)
+	replicaB := limitadorPrueba(ratelimit.NewCompartido(dynamo.replica()), 3)
+	principal := &models.Principal{ID: "key-1", Metodo: models.MetodoAPIKey, Roles: []string{"distribuidor"}}
+
+	t.Run("El cupo se comparte entre réplicas", func(t *testing.T) {
+		assert.True(t, replicaA.Admitir(ctx, "10.0.0.1", principal, http.MethodGet, "/api/v1/transaccion").Permitida)
+		assert.True(t, replicaB.Admitir(ctx, "10.0.0.2", principal, http.MethodGet, "/api/v1/transaccion").Permitida)
+		decision := replicaA.Admitir(ctx, "10.0.0.3", principal, http.MethodGet, "/api/v1/transaccion")
+		assert.True(t, decision.Permitida)
+		assert.Equal(t, 0, decision.Restantes)
+		assert.False(t, replicaB.Admitir(ctx, "10.0.0.1", principal, http.MethodGet, "/api/v1/transaccion").Permitida)
+
+		dynamo.mu.Lock()
+		defer dynamo.mu.Unlock()
+		require.Len(t, dynamo.cuentas, 1)
+		for clave, cuenta := range dynamo.cuentas {
+			assert.True(t, strings.HasPrefix(clave, "RATELIMIT#principal:api_key:key-1#"), clave)
+			assert.Equal(t, int64(4), cuenta)
+			expira, err := strconv.ParseInt(dynamo.expiraEn[clave], 10, 64)
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), time.Unix(expira, 0), time.Hour, "el contador expira al terminar la ventana")
+		}
+	})
+
+	t.Run("Sin almacén se limita por réplica y se avisa una vez", func(t *testing.T) {
+		logs := capturarLogs(t, slog.LevelWarn)
+		dynamo.fallar.Store(true)
+		otro := &models.Principal{ID: "key-2", Metodo: models.MetodoAPIKey}
+
+		antes := dynamo.peticiones.Load()
+		for i := 0; i < 3; i++ {
+			assert.True(t, replicaA.Admitir(ctx, "10.0.0.1", otro, http.MethodGet, "/api/v1/transaccion").Permitida)
+		}
+		assert.False(t, replicaA.Admitir(ctx, "10.0.0.1", otro, http.MethodGet, "/api/v1/transaccion").Permitida, "el cupo local sigue aplicándose")
+		assert.True(t, replicaB.Admitir(ctx, "10.0.0.1", otro, http.MethodGet, "/api/v1/transaccion").Permitida, "cada réplica cuenta por separado")
+		assert.Greater(t, dynamo.peticiones.Load(), antes, "se sigue intentando el almacén")
+
+		avisos := 0
+		for _, registro := range logs.registros(t) {
+			if strings.Contains(registro["msg"].(string), "almacén compartido no disponible") {
+				avisos++
+			}
+		}
+		assert.Equal(t, 2, avisos, "un aviso por réplica")
+	})
+}
@@ -0,0 +1,284 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/handlers"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/salud"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	"github.com/edinfamous/blockchain-medisupply/internal/utils"
+)
+
+// entornoPreparacion reúne las dependencias simuladas de GET /ready
+type entornoPreparacion struct {
+	kubo             *MockKubo
+	replica          *MockKubo // Nodo de IPFS_NODES
+	ethereum         *MockEthereum
+	dynamo           *httptest.Server
+	tablaInexistente atomic.Bool
+	describeTable    atomic.Int32
+}
+
+func nuevoEntornoPreparacion(t *testing.T) *entornoPreparacion {
+	t.Helper()
+	e := &entornoPreparacion{kubo: NewMockKubo(), replica: NewMockKubo(), ethereum: NewMockEthereum()}
+	e.dynamo = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e.describeTable.Add(1)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if e.tablaInexistente.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Table":{"TableName":"transacciones","TableStatus":"ACTIVE"}}`))
+	}))
+	t.Cleanup(func() {
+		e.kubo.Close()
+		e.replica.Close()
+		e.ethereum.Close()
+		e.dynamo.Close()
+	})
+	return e
+}
+
+// router monta GET /ready; conBlockchain=false reproduce el modo sin blockchain
+func (e *entornoPreparacion) router(t *testing.T, conBlockchain bool, cfg handlers.PreparacionConfig) *gin.Engine {
+	t.Helper()
+	host, puerto := e.kubo.HostPort()
+	ipfs := services.NewIPFSService(host, puerto)
+	ipfs.ConfigurarReplicacion(services.ReplicacionConfig{Nodos: []string{e.replica.Direccion()}})
+	dynamo := services.NewDynamoDBService(dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("id", "secreto", ""),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(e.dynamo.URL),
+	}), "transacciones", "control")
+	var blockchain *services.BlockchainService
+	if conBlockchain {
+		var err error
+		blockchain, err = services.NewBlockchainService(e.ethereum.URL(), ClavePruebaEthereum, ContratoPrueba)
+		require.NoError(t, err)
+		t.Cleanup(blockchain.Close)
+	}
+	transacciones := services.NewTransaccionService(blockchain, ipfs, dynamo)
+
+	engine := gin.New()
+	engine.GET("/ready", handlers.NewHealthHandler(ipfs, dynamo, blockchain, transacciones, cfg).ReadinessCheck)
+	return engine
+}
+
+// preparacionPorDefecto usa la clasificación por defecto de READINESS_CRITICAL y sin caché
+func preparacionPorDefecto(t *testing.T) handlers.PreparacionConfig {
+	t.Helper()
+	minimo, err := utils.ParsearEther("0.5")
+	require.NoError(t, err)
+	return handlers.PreparacionConfig{
+		Config: salud.Config{
+			Timeout:  2 * time.Second,
+			Criticas: []string{salud.VerificacionDynamoDB, salud.VerificacionIPFS, salud.VerificacionBlockchain, salud.VerificacionContrato},
+		},
+		BalanceMinimo:            minimo,
+		MaximoAnclajesPendientes: 100,
+	}
+}
+
+type respuestaPreparacion struct {
+	Status   string                     `json:"status"`
+	Checks   map[string]string          `json:"checks"`
+	Detalles map[string]salud.Resultado `json:"detalles"`
+}
+
+func consultarPreparacion(t *testing.T, router *gin.Engine) (int, respuestaPreparacion) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	var respuesta respuestaPreparacion
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &respuesta), w.Body.String())
+	return w.Code, respuesta
+}
+
+func TestReadiness_Dependencias(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	capturarLogs(t, slog.LevelError)
+
+	t.Run("Todas sanas", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		codigo, respuesta := consultarPreparacion(t, e.router(t, true, preparacionPorDefecto(t)))
+		assert.Equal(t, http.StatusOK, codigo)
+		assert.Equal(t, "ready", respuesta.Status)
+		for _, nombre := range salud.Nombres {
+			assert.Equal(t, salud.EstadoOK, respuesta.Checks[nombre], nombre)
+		}
+		assert.True(t, respuesta.Detalles[salud.VerificacionContrato].Critica)
+		assert.False(t, respuesta.Detalles[salud.VerificacionBalance].Critica)
+	})
+
+	t.Run("Saldo bajo degrada sin sacar de servicio", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		e.ethereum.FijarBalance(big.NewInt(100_000_000_000_000_000))
+		codigo, respuesta := consultarPreparacion(t, e.router(t, true, preparacionPorDefecto(t)))
+		assert.Equal(t, http.StatusOK, codigo)
+		assert.Equal(t, "degraded", respuesta.Status)
+		assert.Equal(t, "degraded: saldo de 0.1 ETH por debajo del mínimo de 0.5 ETH", respuesta.Checks[salud.VerificacionBalance])
+	})
+
+	t.Run("Contrato sin código", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		e.ethereum.QuitarContrato()
+		codigo, respuesta := consultarPreparacion(t, e.router(t, true, preparacionPorDefecto(t)))
+		assert.Equal(t, http.StatusServiceUnavailable, codigo)
+		assert.Equal(t, "not_ready", respuesta.Status)
+		assert.Contains(t, respuesta.Checks[salud.VerificacionContrato], "unhealthy: la dirección "+ContratoPrueba+" no tiene un contrato desplegado")
+	})
+
+	t.Run("RPC en otra red", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		cfg := preparacionPorDefecto(t)
+		cfg.ChainIDEsperado = big.NewInt(11155111)
+		router := e.router(t, true, cfg)
+		e.ethereum.CambiarRed(1)
+		codigo, respuesta := consultarPreparacion(t, router)
+		assert.Equal(t, http.StatusServiceUnavailable, codigo)
+		assert.Contains(t, respuesta.Checks[salud.VerificacionBlockchain], "chain ID 1; se esperaba 11155111")
+	})
+
+	t.Run("Tabla de DynamoDB inexistente", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		e.tablaInexistente.Store(true)
+		codigo, respuesta := consultarPreparacion(t, e.router(t, true, preparacionPorDefecto(t)))
+		assert.Equal(t, http.StatusServiceUnavailable, codigo)
+		assert.Contains(t, respuesta.Checks[salud.VerificacionDynamoDB], "unhealthy: tabla transacciones")
+		assert.Contains(t, respuesta.Checks[salud.VerificacionDynamoDB], "ResourceNotFoundException")
+	})
+
+	t.Run("DynamoDB no crítico solo degrada", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		e.tablaInexistente.Store(true)
+		cfg := preparacionPorDefecto(t)
+		cfg.Criticas = []string{salud.VerificacionIPFS}
+		codigo, respuesta := consultarPreparacion(t, e.router(t, true, cfg))
+		assert.Equal(t, http.StatusOK, codigo)
+		assert.Equal(t, "degraded", respuesta.Status)
+	})
+
+	t.Run("IPFS principal caído con una réplica sana degrada", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		router := e.router(t, true, preparacionPorDefecto(t))
+		e.kubo.Close()
+		codigo, respuesta := consultarPreparacion(t, router)
+		assert.Equal(t, http.StatusOK, codigo)
+		assert.Equal(t, "degraded", respuesta.Status)
+		assert.Equal(t, salud.EstadoDegradado, respuesta.Detalles[salud.VerificacionIPFS].Estado)
+		assert.Contains(t, respuesta.Checks[salud.VerificacionIPFS], "kubo:"+e.replica.Direccion()+" responde")
+
+		e.replica.Close()
+		codigo, respuesta = consultarPreparacion(t, router)
+		assert.Equal(t, http.StatusServiceUnavailable, codigo)
+		assert.Equal(t, salud.EstadoFallido, respuesta.Detalles[salud.VerificacionIPFS].Estado)
+	})
+
+	t.Run("Modo sin blockchain", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		codigo, respuesta := consultarPreparacion(t, e.router(t, false, preparacionPorDefecto(t)))
+		assert.Equal(t, http.StatusOK, codigo)
+		assert.Equal(t, "ready", respuesta.Status)
+		for _, nombre := range []string{salud.VerificacionBlockchain, salud.VerificacionContrato, salud.VerificacionBalance} {
+			assert.Equal(t, salud.EstadoDeshabilitado, respuesta.Detalles[nombre].Estado, nombre)
+		}
+		assert.Equal(t, salud.EstadoOK, respuesta.Checks[salud.VerificacionAnclajes])
+	})
+
+	t.Run("El resultado se reutiliza durante el TTL", func(t *testing.T) {
+		e := nuevoEntornoPreparacion(t)
+		cfg := preparacionPorDefecto(t)
+		cfg.TTL = time.Minute
+		router := e.router(t, true, cfg)
+		for i := 0; i < 5; i++ {
+			codigo, _ := consultarPreparacion(t, router)
+			require.Equal(t, http.StatusOK, codigo)
+		}
+		// Una evaluación: un DescribeTable por tabla
+		assert.Equal(t, int32(2), e.describeTable.Load())
+		// Mientras el resultado esté en caché, un fallo nuevo no se ve
+		e.tablaInexistente.Store(true)
+		codigo, _ := consultarPreparacion(t, router)
+		assert.Equal(t, http.StatusOK, codigo)
+	})
+}
+
+func TestReadiness_Verificador(t *testing.T) {
+	cfg := configuracionConSecretos()
+	configurarRedaccion(t, redaccion.Config{Secretos: redaccion.SecretosDe(cfg)})
+
+	verificador := salud.NewVerificador(salud.Config{
+		Timeout:  50 * time.Millisecond,
+		Criticas: []string{salud.VerificacionDynamoDB},
+	},
+		salud.Verificacion{Nombre: salud.VerificacionDynamoDB, Verificar: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		salud.Verificacion{Nombre: salud.VerificacionBlockchain, Verificar: func(context.Context) error {
+			return errors.New("dial " + cfg.BlockchainRPCURL + ": conexión rechazada")
+		}},
+		salud.Verificacion{Nombre: salud.VerificacionAnclajes, Verificar: func(context.Context) error {
+			panic("cola sin inicializar")
+		}},
+	)
+
+	inicio := time.Now()
+	informe := verificador.Verificar(context.Background())
+	assert.Less(t, time.Since(inicio), time.Second, "una dependencia colgada no bloquea más que su timeout")
+	assert.False(t, informe.Listo)
+	assert.Equal(t, "not_ready", informe.Estado())
+
+	dynamo := informe.Resultados[salud.VerificacionDynamoDB]
+	assert.Equal(t, salud.EstadoFallido, dynamo.Estado)
+	assert.Contains(t, dynamo.Mensaje, "timeout (50ms)")
+
+	blockchain := informe.Resultados[salud.VerificacionBlockchain]
+	assert.Equal(t, salud.EstadoDegradado, blockchain.Estado)
+	assert.NotContains(t, blockchain.Mensaje, cfg.AlchemyAPIKey, "la causa se enmascara")
+
+	anclajes := informe.Resultados[salud.VerificacionAnclajes]
+	assert.Equal(t, salud.EstadoDegradado, anclajes.Estado)
+	assert.Contains(t, anclajes.Mensaje, "pánico: cola sin inicializar")
+}
+
+func TestEther_ParsearYFormatear(t *testing.T) {
+	for cantidad, wei := range map[string]string{
+		"0":                     "0",
+		"1":                     "1000000000000000000",
+		"0.05":                  "50000000000000000",
+		".5":                    "500000000000000000",
+		"12.000000000000000001": "12000000000000000001",
+	} {
+		valor, err := utils.ParsearEther(cantidad)
+		require.NoError(t, err, cantidad)
+		assert.Equal(t, wei, valor.String(), cantidad)
+	}
+	for _, invalida := range []string{"", "-1", "abc", "1.0000000000000000001", "1e18"} {
+		_, err := utils.ParsearEther(invalida)
+		assert.Error(t, err, invalida)
+	}
+
+	wei, _ := new(big.Int).SetString("1500000000000000000", 10)
+	assert.Equal(t, "1.5", utils.FormatearEther(wei))
+	assert.Equal(t, "0.000000000000000001", utils.FormatearEther(big.NewInt(1)))
+	assert.Equal(t, "0", utils.FormatearEther(big.NewInt(0)))
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/edinfamous/blockchain-medisupply/internal/middleware"
+	"github.com/edinfamous/blockchain-medisupply/internal/redaccion"
+	"github.com/edinfamous/blockchain-medisupply/internal/services"
+	pb "github.com/edinfamous/blockchain-medisupply/pkg/pb/medisupply/v1"
+)
+
+// Traza y span del cliente que envía la petición (cabecera traceparent de W3C Trace Context)
+const (
+	traceIDCliente = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanIDCliente  = "00f067aa0ba902b7"
+)
+
+// capturarSpans instala un proveedor de spans que exporta de forma síncrona a memoria mientras dura el test
+func capturarSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exportador := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exportador)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return exportador
+}
+
+// buscarSpan retorna el span terminado con ese nombre, o nil
+func buscarSpan(exportador *tracetest.InMemoryExporter, nombre string) *tracetest.SpanStub {
+	for _, span := range exportador.GetSpans() {
+		if span.Name == nombre {
+			return &span
+		}
+	}
+	return nil
+}
+
+func TestTrazas_RegistroHTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := capturarLogs(t, slog.LevelInfo)
+	spans := capturarSpans(t)
+
+	kubo := NewMockKubo()
+	defer kubo.Close()
+	host, puerto := kubo.HostPort()
+	dynamo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer dynamo.Close()
+	cliente := dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("id", "secreto", ""),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(dynamo.URL),
+	}, services.InstrumentarDynamoDB)
+	// Sin blockchain: el anclaje asíncrono se omite, pero su span se registra igual
+	servicio := services.NewTransaccionService(nil, services.NewIPFSService(host, puerto), services.NewDynamoDBService(cliente, "transacciones", "control"))
+
+	engine := gin.New()
+	engine.Use(middleware.TrazasMiddleware(), middleware.LoggerMiddleware(), middleware.ErroresMiddleware())
+	engine.POST("/api/v1/transaccion/registrar", func(c *gin.Context) {
+		if _, err := servicio.RegistrarTransaccion(c.Request.Context(), GetMockTransaccionRequest()); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	peticion := httptest.NewRequest(http.MethodPost, "/api/v1/transaccion/registrar", nil)
+	peticion.Header.Set("traceparent", "00-"+traceIDCliente+"-"+spanIDCliente+"-01")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, peticion)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// El span de servidor continúa la traza del cliente
+	servidor := buscarSpan(spans, "POST /api/v1/transaccion/registrar")
+	require.NotNil(t, servidor)
+	assert.Equal(t, trace.SpanKindServer, servidor.SpanKind)
+	assert.Equal(t, traceIDCliente, servidor.SpanContext.TraceID().String())
+	assert.Equal(t, spanIDCliente, servidor.Parent.SpanID().String())
+	assert.True(t, servidor.Parent.IsRemote())
+
+	// IPFS y DynamoDB cuelgan de la petición, en la misma traza
+	for _, nombre := range []string{"ipfs.version", "ipfs.add", "dynamodb.TransactWriteItems"} {
+		span := buscarSpan(spans, nombre)
+		if assert.NotNil(t, span, nombre) {
+			assert.Equal(t, traceIDCliente, span.SpanContext.TraceID().String(), nombre)
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind, nombre)
+		}
+	}
+
+	// El anclaje termina después de responder: traza propia, enlazada al span de la petición
+	var anclaje *tracetest.SpanStub
+	require.Eventually(t, func() bool {
+		anclaje = buscarSpan(spans, "blockchain.anclar")
+		return anclaje != nil
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NotEqual(t, traceIDCliente, anclaje.SpanContext.TraceID().String())
+	assert.False(t, anclaje.Parent.IsValid())
+	require.Len(t, anclaje.Links, 1)
+	assert.Equal(t, servidor.SpanContext.SpanID(), anclaje.Links[0].SpanContext.SpanID())
+	assert.Equal(t, servidor.SpanContext.TraceID(), anclaje.Links[0].SpanContext.TraceID())
+
+	// Los logs de la petición llevan el trace ID para saltar del log a la traza
+	var registrada map[string]any
+	for _, registro := range logs.registros(t) {
+		if registro["msg"] == "transacción registrada" {
+			registrada = registro
+		}
+	}
+	require.NotNil(t, registrada)
+	assert.Equal(t, traceIDCliente, registrada["trace_id"])
+	assert.NotEmpty(t, registrada["span_id"])
+}
+
+func TestTrazas_ErrorRedactado(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	capturarLogs(t, slog.LevelError)
+	spans := capturarSpans(t)
+	cfg := configuracionConSecretos()
+	configurarRedaccion(t, redaccion.Config{Secretos: redaccion.SecretosDe(cfg)})
+
+	// El error del cliente de Ethereum incluye la URL del RPC con la API key
+	engine := gin.New()
+	engine.Use(middleware.TrazasMiddleware(), middleware.ErroresMiddleware())
+	engine.GET("/api/v1/transaccion/verificar/:id", func(c *gin.Context) {
+		_ = c.Error(services.ErrorDependencia(services.DependenciaBlockchain, fmt.Errorf("Post %q: dial tcp: connection refused", cfg.BlockchainRPCURL)))
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/transaccion/verificar/tx-1", nil))
+
+	servidor := buscarSpan(spans, "GET /api/v1/transaccion/verificar/:id")
+	require.NotNil(t, servidor)
+	assert.False(t, servidor.Parent.IsValid(), "sin traceparent el span inicia una traza nueva")
+	assert.Equal(t, codes.Error, servidor.Status.Code)
+	assert.NotContains(t, servidor.Status.Description, cfg.AlchemyAPIKey)
+	require.NotEmpty(t, servidor.Events, "el error se registra como evento del span")
+	for _, evento := range servidor.Events {
+		for _, atributo := range evento.Attributes {
+			assert.NotContains(t, atributo.Value.Emit(), cfg.AlchemyAPIKey)
+		}
+	}
+}
+
+func TestTrazas_GRPC(t *testing.T) {
+	capturarLogs(t, slog.LevelError)
+	spans := capturarSpans(t)
+	conn := conectarGRPC(t, dependenciasGRPC(t, services.NewAPIKeyService(services.NewAlmacenAPIKeysMemoria())), nil)
+
+	// traceparent viaja como metadata, igual que la cabecera HTTP
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-"+traceIDCliente+"-"+spanIDCliente+"-01")
+	_, err := pb.NewTransaccionesClient(conn).ListarTransacciones(ctx, &pb.ListarTransaccionesRequest{})
+	require.Equal(t, grpccodes.Unauthenticated, status.Code(err))
+
+	span := buscarSpan(spans, "medisupply.v1.Transacciones/ListarTransacciones")
+	require.NotNil(t, span)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, traceIDCliente, span.SpanContext.TraceID().String())
+	assert.Equal(t, spanIDCliente, span.Parent.SpanID().String())
+	assert.NotEqual(t, codes.Error, span.Status.Code, "un rechazo del cliente no es una falla del servidor")
+	atributos := map[string]string{}
+	for _, atributo := range span.Attributes {
+		atributos[string(atributo.Key)] = atributo.Value.Emit()
+	}
+	assert.Equal(t, "grpc", atributos["rpc.system"])
+	assert.Equal(t, "ListarTransacciones", atributos["rpc.method"])
+	assert.Equal(t, "16", atributos["rpc.grpc.status_code"])
+}
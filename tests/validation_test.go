@@ -47,6 +47,39 @@ func TestValidateStruct(t *testing.T) {
 	})
 }
 
+func TestValidarDatosEvento(t *testing.T) {
+	t.Run("Datos de fabricación válidos", func(t *testing.T) {
+		err := validation.ValidarDatosEvento("fabricacion", `{"lote": "LOT-001", "cantidad": 1000, "fecha_fabricacion": "2024-01-15"}`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Falta un campo requerido por el esquema", func(t *testing.T) {
+		err := validation.ValidarDatosEvento("fabricacion", `{"cantidad": 1000}`)
+		assert.Error(t, err)
+	})
+
+	t.Run("Tipo de dato incorrecto", func(t *testing.T) {
+		err := validation.ValidarDatosEvento("fabricacion", `{"lote": "LOT-001", "cantidad": "mil"}`)
+		assert.ErrorContains(t, err, "/cantidad")
+	})
+
+	t.Run("Formato de fecha inválido", func(t *testing.T) {
+		err := validation.ValidarDatosEvento("fabricacion", `{"lote": "LOT-001", "fecha_fabricacion": "15/01/2024"}`)
+		assert.Error(t, err)
+	})
+
+	t.Run("DatosEvento no es JSON", func(t *testing.T) {
+		err := validation.ValidarDatosEvento("recepcion", `receptor=Farmacia`)
+		assert.Error(t, err)
+	})
+
+	t.Run("Datos de los mocks cumplen sus esquemas", func(t *testing.T) {
+		assert.NoError(t, validation.ValidarDatosEvento("fabricacion", GetMockTransaccionFabricacion("P").DatosEvento))
+		assert.NoError(t, validation.ValidarDatosEvento("distribucion", GetMockTransaccionDistribucion("P").DatosEvento))
+		assert.NoError(t, validation.ValidarDatosEvento("recepcion", GetMockTransaccionRecepcion("P").DatosEvento))
+	})
+}
+
 func TestValidateEthereumAddress(t *testing.T) {
 	tests := []struct {
 		name     string